@@ -0,0 +1,166 @@
+package apollo
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// encoder writes the protobuf wire format. Only the types used by the usage reporting protocol are
+// supported.
+type encoder struct {
+	b []byte
+}
+
+const (
+	wireVarint = 0
+	wireBytes  = 2
+)
+
+func (e *encoder) tag(field int, wireType int) {
+	e.varint(uint64(field)<<3 | uint64(wireType))
+}
+
+func (e *encoder) varint(v uint64) {
+	for v >= 0x80 {
+		e.b = append(e.b, byte(v)|0x80)
+		v >>= 7
+	}
+	e.b = append(e.b, byte(v))
+}
+
+func (e *encoder) uint64Field(field int, v uint64) {
+	if v == 0 {
+		return
+	}
+	e.tag(field, wireVarint)
+	e.varint(v)
+}
+
+func (e *encoder) boolField(field int, v bool) {
+	if !v {
+		return
+	}
+	e.tag(field, wireVarint)
+	e.varint(1)
+}
+
+func (e *encoder) stringField(field int, s string) {
+	if s == "" {
+		return
+	}
+	e.tag(field, wireBytes)
+	e.varint(uint64(len(s)))
+	e.b = append(e.b, s...)
+}
+
+// packedSint64Field writes a packed repeated sint64 field with zigzag encoding.
+func (e *encoder) packedSint64Field(field int, values []int64) {
+	if len(values) == 0 {
+		return
+	}
+	var p encoder
+	for _, v := range values {
+		p.varint(uint64(v<<1) ^ uint64(v>>63))
+	}
+	e.tag(field, wireBytes)
+	e.varint(uint64(len(p.b)))
+	e.b = append(e.b, p.b...)
+}
+
+// messageField writes the message encoded by f. Empty messages are written as well, since their
+// presence may be significant.
+func (e *encoder) messageField(field int, f func(e *encoder)) {
+	var m encoder
+	f(&m)
+	e.tag(field, wireBytes)
+	e.varint(uint64(len(m.b)))
+	e.b = append(e.b, m.b...)
+}
+
+// mapEntry writes an entry of a map<string, Message> field.
+func (e *encoder) mapEntry(field int, key string, value func(e *encoder)) {
+	e.messageField(field, func(e *encoder) {
+		e.stringField(1, key)
+		e.messageField(2, value)
+	})
+}
+
+func (e *encoder) timestampField(field int, t time.Time) {
+	e.messageField(field, func(e *encoder) {
+		e.uint64Field(1, uint64(t.Unix()))
+		e.uint64Field(2, uint64(t.Nanosecond()))
+	})
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// histogram counts durations in the exponential buckets used by Apollo: bucket i holds durations
+// of up to 1.1^i microseconds.
+type histogram []int64
+
+const histogramBuckets = 384
+
+var bucketBase = math.Log(1.1)
+
+func (h *histogram) add(d time.Duration) {
+	if *h == nil {
+		*h = make(histogram, histogramBuckets)
+	}
+	(*h)[durationBucket(d)]++
+}
+
+func (h histogram) merge(other histogram) histogram {
+	if other == nil {
+		return h
+	}
+	if h == nil {
+		h = make(histogram, histogramBuckets)
+	}
+	for i, n := range other {
+		h[i] += n
+	}
+	return h
+}
+
+func durationBucket(d time.Duration) int {
+	bucket := math.Ceil(math.Log(float64(d.Nanoseconds())/1000) / bucketBase)
+	switch {
+	case math.IsNaN(bucket) || bucket <= 0:
+		return 0
+	case bucket >= histogramBuckets:
+		return histogramBuckets - 1
+	default:
+		return int(bucket)
+	}
+}
+
+// encode returns the counts of the buckets, with runs of empty buckets replaced by their negated
+// length and trailing empty buckets removed.
+func (h histogram) encode() []int64 {
+	var out []int64
+	zeros := int64(0)
+	for _, n := range h {
+		if n == 0 {
+			zeros++
+			continue
+		}
+		switch zeros {
+		case 0:
+		case 1:
+			out = append(out, 0)
+		default:
+			out = append(out, -zeros)
+		}
+		out = append(out, n)
+		zeros = 0
+	}
+	return out
+}
@@ -0,0 +1,401 @@
+// Package apollo reports usage statistics of a schema to Apollo Studio.
+//
+// The Reporter is a trace.Tracer that aggregates the latency, errors and field usage of every
+// operation and periodically sends them in the usage reporting format of Apollo:
+//
+//	reporter, err := apollo.NewReporter(apollo.Config{
+//		APIKey:   os.Getenv("APOLLO_KEY"),
+//		GraphRef: "my-graph@production",
+//		Schema:   schemaString,
+//	})
+//	schema := graphql.MustParseSchema(schemaString, resolver, graphql.Tracer(reporter))
+//	reporter.Start()
+//	defer reporter.Stop(context.Background())
+package apollo
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/introspection"
+	"github.com/graph-gophers/graphql-go/trace"
+)
+
+// DefaultEndpoint is the usage reporting endpoint of Apollo Studio.
+const DefaultEndpoint = "https://usage-reporting.api.apollographql.com/api/ingress/traces"
+
+const agentVersion = "graphql-go-apollo"
+
+// Config configures a Reporter.
+type Config struct {
+	// APIKey is the graph API key sent in the X-Api-Key header.
+	APIKey string
+
+	// GraphRef identifies the graph and variant, e.g. "my-graph@production".
+	GraphRef string
+
+	// Schema is the schema definition the server executes. It is used to identify the schema and to
+	// report the return types of fields.
+	Schema string
+
+	// Endpoint defaults to DefaultEndpoint.
+	Endpoint string
+
+	// Interval is the time between two reports sent by Start. It defaults to 20 seconds.
+	Interval time.Duration
+
+	// HTTPClient defaults to http.DefaultClient.
+	HTTPClient *http.Client
+
+	// ServiceVersion is the version of the server, e.g. a commit hash.
+	ServiceVersion string
+
+	// Tracer is called for every query and field as well, so the Reporter can be combined with
+	// another tracer. It defaults to trace.NoopTracer.
+	Tracer trace.Tracer
+
+	// OnError is called with the errors of reports sent by Start. Errors are ignored by default.
+	OnError func(error)
+}
+
+// Reporter aggregates usage statistics and reports them to Apollo Studio. It implements
+// trace.Tracer.
+type Reporter struct {
+	cfg         Config
+	schemaID    string
+	returnTypes map[string]string
+	hostname    string
+
+	mu      sync.Mutex
+	queries map[string]*queryStats
+	count   uint64
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+var _ trace.Tracer = (*Reporter)(nil)
+
+type queryStats struct {
+	contexts map[graphql.ClientInfo]*contextStats
+}
+
+type contextStats struct {
+	latency            histogram
+	requestCount       uint64
+	requestsWithErrors uint64
+	types              map[string]map[string]*fieldStats
+}
+
+type fieldStats struct {
+	errors  uint64
+	count   uint64
+	latency histogram
+}
+
+// NewReporter returns a Reporter for the given configuration. The schema is parsed to validate it.
+func NewReporter(cfg Config) (*Reporter, error) {
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = DefaultEndpoint
+	}
+	if cfg.Interval == 0 {
+		cfg.Interval = 20 * time.Second
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+	if cfg.Tracer == nil {
+		cfg.Tracer = trace.NoopTracer{}
+	}
+
+	s, err := graphql.ParseSchema(cfg.Schema, nil)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256([]byte(cfg.Schema))
+	r := &Reporter{
+		cfg:         cfg,
+		schemaID:    hex.EncodeToString(sum[:]),
+		returnTypes: make(map[string]string),
+		queries:     make(map[string]*queryStats),
+	}
+	r.hostname, _ = os.Hostname()
+	for _, t := range s.Inspect().Types() {
+		if fields := t.Fields(&struct{ IncludeDeprecated bool }{true}); fields != nil {
+			for _, f := range *fields {
+				r.returnTypes[*t.Name()+"."+f.Name()] = typeString(f.Type())
+			}
+		}
+	}
+	return r, nil
+}
+
+func typeString(t *introspection.Type) string {
+	switch t.Kind() {
+	case "NON_NULL":
+		return typeString(t.OfType()) + "!"
+	case "LIST":
+		return "[" + typeString(t.OfType()) + "]"
+	default:
+		return *t.Name()
+	}
+}
+
+type requestKey struct{}
+
+// request collects the field statistics of a single request.
+type request struct {
+	mu     sync.Mutex
+	fields map[string]map[string]*fieldStats
+}
+
+func (r *Reporter) TraceQuery(ctx context.Context, queryString string, operationName string, variables map[string]interface{}, varTypes map[string]*introspection.Type) (context.Context, trace.TraceQueryFinishFunc) {
+	ctx, finish := r.cfg.Tracer.TraceQuery(ctx, queryString, operationName, variables, varTypes)
+	req := &request{fields: make(map[string]map[string]*fieldStats)}
+	ctx = context.WithValue(ctx, requestKey{}, req)
+	start := time.Now()
+
+	var client graphql.ClientInfo
+	if info := graphql.OperationInfoFromContext(ctx); info != nil {
+		client = info.Client
+	}
+
+	return ctx, func(errs []*errors.QueryError) {
+		finish(errs)
+		r.record(statsKey(queryString, operationName), client, time.Since(start), len(errs) != 0, req)
+	}
+}
+
+func (r *Reporter) TraceField(ctx context.Context, label, typeName, fieldName string, trivial bool, args map[string]interface{}) (context.Context, trace.TraceFieldFinishFunc) {
+	ctx, finish := r.cfg.Tracer.TraceField(ctx, label, typeName, fieldName, trivial, args)
+	req, ok := ctx.Value(requestKey{}).(*request)
+	if !ok {
+		return ctx, finish
+	}
+	start := time.Now()
+	return ctx, func(err *errors.QueryError) {
+		finish(err)
+		d := time.Since(start)
+
+		req.mu.Lock()
+		defer req.mu.Unlock()
+		fields, ok := req.fields[typeName]
+		if !ok {
+			fields = make(map[string]*fieldStats)
+			req.fields[typeName] = fields
+		}
+		fs, ok := fields[fieldName]
+		if !ok {
+			fs = &fieldStats{}
+			fields[fieldName] = fs
+		}
+		fs.count++
+		if err != nil {
+			fs.errors++
+		}
+		fs.latency.add(d)
+	}
+}
+
+func (r *Reporter) record(key string, client graphql.ClientInfo, d time.Duration, failed bool, req *request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.count++
+	qs, ok := r.queries[key]
+	if !ok {
+		qs = &queryStats{contexts: make(map[graphql.ClientInfo]*contextStats)}
+		r.queries[key] = qs
+	}
+	cs, ok := qs.contexts[client]
+	if !ok {
+		cs = &contextStats{types: make(map[string]map[string]*fieldStats)}
+		qs.contexts[client] = cs
+	}
+	cs.requestCount++
+	if failed {
+		cs.requestsWithErrors++
+	}
+	cs.latency.add(d)
+
+	for typeName, fields := range req.fields {
+		typeStats, ok := cs.types[typeName]
+		if !ok {
+			typeStats = make(map[string]*fieldStats)
+			cs.types[typeName] = typeStats
+		}
+		for fieldName, fs := range fields {
+			total, ok := typeStats[fieldName]
+			if !ok {
+				total = &fieldStats{}
+				typeStats[fieldName] = total
+			}
+			total.count += fs.count
+			total.errors += fs.errors
+			total.latency = total.latency.merge(fs.latency)
+		}
+	}
+}
+
+var whitespace = regexp.MustCompile(`\s+`)
+
+// statsKey returns the key of an operation in the report. The signature of the operation is the
+// document with insignificant whitespace collapsed.
+func statsKey(queryString, operationName string) string {
+	if operationName == "" {
+		operationName = "-"
+	}
+	return "# " + operationName + "\n" + strings.TrimSpace(whitespace.ReplaceAllString(queryString, " "))
+}
+
+// Start sends a report every Config.Interval until Stop is called.
+func (r *Reporter) Start() {
+	r.stop = make(chan struct{})
+	r.done = make(chan struct{})
+	go func() {
+		defer close(r.done)
+		ticker := time.NewTicker(r.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := r.Flush(context.Background()); err != nil && r.cfg.OnError != nil {
+					r.cfg.OnError(err)
+				}
+			case <-r.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the reports started by Start and sends the remaining statistics.
+func (r *Reporter) Stop(ctx context.Context) error {
+	if r.stop != nil {
+		close(r.stop)
+		<-r.done
+		r.stop = nil
+	}
+	return r.Flush(ctx)
+}
+
+// Flush sends the statistics collected since the last report. Nothing is sent if no operation was
+// executed.
+func (r *Reporter) Flush(ctx context.Context) error {
+	r.mu.Lock()
+	queries, count := r.queries, r.count
+	r.queries, r.count = make(map[string]*queryStats), 0
+	r.mu.Unlock()
+
+	if count == 0 {
+		return nil
+	}
+
+	var body bytes.Buffer
+	zw := gzip.NewWriter(&body)
+	zw.Write(r.encodeReport(queries, count, time.Now()))
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, r.cfg.Endpoint, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/protobuf")
+	req.Header.Set("Content-Encoding", "gzip")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-Api-Key", r.cfg.APIKey)
+	resp, err := r.cfg.HTTPClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("apollo: usage report rejected with status %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// encodeReport encodes the Report message of Apollo's reports.proto.
+func (r *Reporter) encodeReport(queries map[string]*queryStats, count uint64, end time.Time) []byte {
+	var e encoder
+	e.messageField(1, func(e *encoder) {
+		e.stringField(5, r.hostname)
+		e.stringField(6, agentVersion)
+		e.stringField(7, r.cfg.ServiceVersion)
+		e.stringField(8, runtime.Version())
+		e.stringField(11, r.schemaID)
+		e.stringField(12, r.cfg.GraphRef)
+	})
+	e.timestampField(2, end)
+
+	keys := make(map[string]bool, len(queries))
+	for k := range queries {
+		keys[k] = true
+	}
+	for _, key := range sortedKeys(keys) {
+		qs := queries[key]
+		e.mapEntry(5, key, func(e *encoder) {
+			for client, cs := range qs.contexts {
+				e.messageField(2, func(e *encoder) {
+					r.encodeContextStats(e, client, cs)
+				})
+			}
+		})
+	}
+	e.uint64Field(6, count)
+	return e.b
+}
+
+// encodeContextStats encodes the ContextualizedStats message.
+func (r *Reporter) encodeContextStats(e *encoder, client graphql.ClientInfo, cs *contextStats) {
+	e.messageField(1, func(e *encoder) {
+		e.stringField(2, client.Name)
+		e.stringField(3, client.Version)
+	})
+	e.messageField(2, func(e *encoder) {
+		e.uint64Field(2, cs.requestCount)
+		e.uint64Field(8, cs.requestsWithErrors)
+		e.packedSint64Field(13, cs.latency.encode())
+	})
+
+	typeNames := make(map[string]bool, len(cs.types))
+	for t := range cs.types {
+		typeNames[t] = true
+	}
+	for _, typeName := range sortedKeys(typeNames) {
+		fields := cs.types[typeName]
+		e.mapEntry(3, typeName, func(e *encoder) {
+			fieldNames := make(map[string]bool, len(fields))
+			for f := range fields {
+				fieldNames[f] = true
+			}
+			for _, fieldName := range sortedKeys(fieldNames) {
+				fs := fields[fieldName]
+				e.mapEntry(3, fieldName, func(e *encoder) {
+					e.stringField(3, r.returnTypes[typeName+"."+fieldName])
+					e.uint64Field(4, fs.errors)
+					e.uint64Field(5, fs.count)
+					e.uint64Field(10, fs.count)
+					e.packedSint64Field(9, fs.latency.encode())
+				})
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package apollo
+
+import (
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/example/starwars"
+)
+
+func TestHistogram(t *testing.T) {
+	var h histogram
+	h.add(0)
+	h.add(time.Microsecond)
+	h.add(2 * time.Microsecond)
+	h.add(2 * time.Microsecond)
+	h.add(time.Hour)
+
+	if got := durationBucket(2 * time.Microsecond); got != 8 {
+		t.Fatalf("bucket of 2µs: got %d, want 8", got)
+	}
+	want := []int64{2, -7, 2, -222, 1}
+	if got := h.encode(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestReporter(t *testing.T) {
+	reports := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != "key" || r.Header.Get("Content-Type") != "application/protobuf" {
+			t.Errorf("unexpected headers: %v", r.Header)
+		}
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		body, err := ioutil.ReadAll(zr)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		reports <- body
+	}))
+	defer server.Close()
+
+	reporter, err := NewReporter(Config{
+		APIKey:   "key",
+		GraphRef: "starwars@current",
+		Schema:   starwars.Schema,
+		Endpoint: server.URL,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}, graphql.Tracer(reporter))
+
+	ctx := graphql.WithClientInfo(context.Background(), graphql.ClientInfo{Name: "web", Version: "1.0"})
+	query := `
+		query Hero {
+			hero {
+				name
+			}
+		}
+	`
+	for i := 0; i < 2; i++ {
+		if resp := schema.Exec(ctx, query, "", nil); len(resp.Errors) != 0 {
+			t.Fatal(resp.Errors)
+		}
+	}
+	if err := reporter.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	report := decode(t, <-reports)
+	header := decode(t, report.bytes(1))
+	if header.string(12) != "starwars@current" || len(header.string(11)) != 64 {
+		t.Fatalf("unexpected header: %v", header)
+	}
+	if report.uint(6) != 2 {
+		t.Fatalf("operation count: got %d, want 2", report.uint(6))
+	}
+
+	entry := decode(t, report.bytes(5))
+	if key := entry.string(1); key != "# Hero\nquery Hero { hero { name } }" {
+		t.Fatalf("unexpected stats key %q", key)
+	}
+	stats := decode(t, decode(t, entry.bytes(2)).bytes(2))
+	statsContext := decode(t, stats.bytes(1))
+	if statsContext.string(2) != "web" || statsContext.string(3) != "1.0" {
+		t.Fatalf("unexpected client: %v", statsContext)
+	}
+	if n := decode(t, stats.bytes(2)).uint(2); n != 2 {
+		t.Fatalf("request count: got %d, want 2", n)
+	}
+
+	queryStats := decode(t, stats.entry(t, 3, "Query"))
+	field := decode(t, queryStats.entry(t, 3, "hero"))
+	if field.string(3) != "Character" || field.uint(5) != 2 {
+		t.Fatalf("unexpected field stats: %v", field)
+	}
+	characterStats := decode(t, stats.entry(t, 3, "Character"))
+	field = decode(t, characterStats.entry(t, 3, "name"))
+	if field.string(3) != "String!" || field.uint(5) != 2 {
+		t.Fatalf("unexpected field stats: %v", field)
+	}
+
+	if err := reporter.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-reports:
+		t.Fatal("empty report was sent")
+	default:
+	}
+}
+
+// message holds the values of each field of a decoded protobuf message.
+type message map[int][]interface{}
+
+func (m message) bytes(field int) []byte {
+	if len(m[field]) == 0 {
+		return nil
+	}
+	b, _ := m[field][0].([]byte)
+	return b
+}
+
+func (m message) string(field int) string {
+	return string(m.bytes(field))
+}
+
+func (m message) uint(field int) uint64 {
+	if len(m[field]) == 0 {
+		return 0
+	}
+	v, _ := m[field][0].(uint64)
+	return v
+}
+
+// entry returns the value of the entry of a map field with the given key.
+func (m message) entry(t *testing.T, field int, key string) []byte {
+	t.Helper()
+	for _, v := range m[field] {
+		b, _ := v.([]byte)
+		if e := decode(t, b); e.string(1) == key {
+			return e.bytes(2)
+		}
+	}
+	t.Fatalf("no entry %q in field %d", key, field)
+	return nil
+}
+
+func decode(t *testing.T, b []byte) message {
+	t.Helper()
+	m := make(message)
+	varint := func() uint64 {
+		var v uint64
+		for shift := uint(0); ; shift += 7 {
+			if len(b) == 0 {
+				t.Fatal("truncated message")
+			}
+			c := b[0]
+			b = b[1:]
+			v |= uint64(c&0x7f) << shift
+			if c < 0x80 {
+				return v
+			}
+		}
+	}
+	for len(b) > 0 {
+		tag := varint()
+		field := int(tag >> 3)
+		var value interface{}
+		switch tag & 7 {
+		case wireVarint:
+			value = varint()
+		case wireBytes:
+			n := varint()
+			value = b[:n]
+			b = b[n:]
+		default:
+			t.Fatalf("unexpected wire type %d", tag&7)
+		}
+		m[field] = append(m[field], value)
+	}
+	return m
+}
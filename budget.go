@@ -0,0 +1,134 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/graph-gophers/graphql-go/errors"
+)
+
+// BudgetStore tracks the complexity points that callers may spend, e.g. per API key and month or
+// minute like the rate limit of the GitHub GraphQL API. The caller is identified by the context of
+// the request, e.g. by an API key stored in it by the HTTP handler.
+type BudgetStore interface {
+	// Spend deducts cost from the budget of the caller. It returns a non-nil error without
+	// deducting anything if the remaining budget does not cover the cost. The error is returned to
+	// the client as the only error of the response. A *errors.QueryError is returned as is.
+	Spend(ctx context.Context, cost int) error
+}
+
+// ComplexityBudget spends the OperationInfo.Complexity of every operation from the budget of its
+// caller before the operation is executed. The operation is rejected if the budget is exhausted.
+// The budget is consulted after the RateLimiter, so rejected operations cost nothing.
+func ComplexityBudget(store BudgetStore) SchemaOpt {
+	return func(s *Schema) {
+		s.budgetStore = store
+	}
+}
+
+// spendBudget spends the complexity of the operation from the budget of the caller.
+func (s *Schema) spendBudget(ctx context.Context, info *OperationInfo) *errors.QueryError {
+	if s.budgetStore == nil {
+		return nil
+	}
+	err := s.budgetStore.Spend(ctx, info.Complexity)
+	if err == nil {
+		return nil
+	}
+	if qErr, ok := err.(*errors.QueryError); ok {
+		return qErr
+	}
+	return &errors.QueryError{
+		Message:       err.Error(),
+		ResolverError: err,
+		Extensions:    map[string]interface{}{"code": "BUDGET_EXCEEDED", "cost": info.Complexity},
+	}
+}
+
+// WindowBudget is an in-memory BudgetStore that grants every caller the same number of points per
+// fixed time window, e.g. 5000 points per hour. Budgets are not shared between processes.
+type WindowBudget struct {
+	points int
+	window time.Duration
+	caller func(ctx context.Context) string
+	now    func() time.Time
+
+	mu        sync.Mutex
+	budgets   map[string]*windowBudget
+	nextSweep time.Time
+}
+
+type windowBudget struct {
+	reset time.Time
+	spent int
+}
+
+// NewWindowBudget returns a WindowBudget granting the given points per window to each caller,
+// which is identified by the given function, e.g. by the API key of the request.
+func NewWindowBudget(points int, window time.Duration, caller func(ctx context.Context) string) *WindowBudget {
+	return &WindowBudget{
+		points:  points,
+		window:  window,
+		caller:  caller,
+		now:     time.Now,
+		budgets: make(map[string]*windowBudget),
+	}
+}
+
+// Spend deducts cost from the points of the current window of the caller. The error reports the
+// remaining points and the time the window resets.
+func (b *WindowBudget) Spend(ctx context.Context, cost int) error {
+	key := b.caller(ctx)
+	now := b.now()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !now.Before(b.nextSweep) {
+		b.evict(now)
+		b.nextSweep = now.Add(b.window)
+	}
+	budget, ok := b.budgets[key]
+	if !ok || !now.Before(budget.reset) {
+		budget = &windowBudget{reset: now.Add(b.window)}
+		b.budgets[key] = budget
+	}
+	if budget.spent+cost > b.points {
+		remaining := b.points - budget.spent
+		return &errors.QueryError{
+			Message: fmt.Sprintf("operation costs %d points, which exceeds the remaining %d points of the budget", cost, remaining),
+			Extensions: map[string]interface{}{
+				"code":      "BUDGET_EXCEEDED",
+				"cost":      cost,
+				"remaining": remaining,
+				"resetAt":   budget.reset.UTC().Format(time.RFC3339),
+			},
+		}
+	}
+	budget.spent += cost
+	return nil
+}
+
+// Remaining returns the points left to the caller in the current window.
+func (b *WindowBudget) Remaining(ctx context.Context) int {
+	key := b.caller(ctx)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	budget, ok := b.budgets[key]
+	if !ok || !b.now().Before(budget.reset) {
+		return b.points
+	}
+	return b.points - budget.spent
+}
+
+// evict removes the budgets of expired windows. It runs at most once per window, so that Spend does
+// not scan the budgets of all callers on every request. The expired budget of a caller is replaced
+// when it spends again, and budgets are kept for at most two windows.
+func (b *WindowBudget) evict(now time.Time) {
+	for key, budget := range b.budgets {
+		if !now.Before(budget.reset) {
+			delete(b.budgets, key)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package graphql
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/graph-gophers/graphql-go/internal/query"
+)
+
+// Version returns the hex encoded SHA-256 hash of the introspection result of the schema, which
+// changes whenever a type, field, argument or directive of the schema changes. It is computed once,
+// without the limits of the schema on the operations of clients. An error is returned if the
+// schema cannot be introspected.
+func (s *Schema) Version() (string, error) {
+	s.versionOnce.Do(func() {
+		b, err := s.ToJSON()
+		if err != nil {
+			s.versionErr = err
+			return
+		}
+		hash := sha256.Sum256(b)
+		s.version = hex.EncodeToString(hash[:])
+	})
+	return s.version, s.versionErr
+}
+
+// CacheKey returns a stable hash of the schema version, the normalized query document, the
+// operation name and the variables of a request, e.g. to cache responses or to deduplicate
+// identical requests. Documents that only differ in whitespace, commas and comments have the same
+// key. An error is returned if the query contains invalid tokens or the version of the schema
+// cannot be computed; the query is not validated otherwise.
+func (s *Schema) CacheKey(queryString string, operationName string, variables map[string]interface{}) (string, error) {
+	normalized, qErr := query.Normalize(queryString)
+	if qErr != nil {
+		return "", qErr
+	}
+	version, err := s.Version()
+	if err != nil {
+		return "", err
+	}
+	// Variables are encoded with sorted keys, so their order does not matter.
+	vars, err := json.Marshal(variables)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	for _, part := range []string{version, normalized, operationName, string(vars)} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ResponseHash returns the hex encoded SHA-256 hash of the JSON encoding of the response, e.g. to
+// generate an ETag. Responses are only byte-identical if their fields are written in the same
+// order, see ResponseFieldOrder.
+func ResponseHash(resp *Response) (string, error) {
+	b, err := json.Marshal(resp)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(b)
+	return hex.EncodeToString(hash[:]), nil
+}
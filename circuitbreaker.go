@@ -0,0 +1,145 @@
+package graphql
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CircuitBreaker is consulted around every call of the method resolver of a field, keyed by the
+// schema coordinate of the field, e.g. "Query.user". Fields that are resolved without calling
+// their resolver, e.g. because they are redacted or the request was cancelled, are neither failed
+// nor reported to it. While a downstream service fails persistently, it fails the fields
+// depending on it immediately instead of letting every request wait for timeouts while holding a
+// slot of the limiter.
+type CircuitBreaker interface {
+	// Allow returns a non-nil error to fail the field without calling its resolver. The error is
+	// returned to the client like an error of the resolver.
+	Allow(coordinate string) error
+
+	// Done reports the outcome of a call allowed by Allow: nil on success, or the error returned
+	// by the resolver.
+	Done(coordinate string, err error)
+}
+
+// UseCircuitBreaker consults the given CircuitBreaker around the resolution of fields, e.g. one
+// returned by NewCircuitBreaker.
+func UseCircuitBreaker(breaker CircuitBreaker) SchemaOpt {
+	return func(s *Schema) {
+		s.circuitBreaker = breaker
+	}
+}
+
+// CircuitBreakerConfig configures the CircuitBreaker returned by NewCircuitBreaker.
+type CircuitBreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures that opens a circuit. It defaults to
+	// 5.
+	FailureThreshold int
+
+	// OpenTimeout is the time a circuit stays open before a single trial call is allowed, which
+	// closes the circuit if it succeeds. It defaults to 30 seconds.
+	OpenTimeout time.Duration
+
+	// PerType shares one circuit among all fields of a type instead of using one per field.
+	PerType bool
+
+	// IsFailure reports whether an error of a resolver counts as a failure. All errors do if it is
+	// nil.
+	IsFailure func(err error) bool
+}
+
+// CircuitOpenError is returned for fields whose circuit is open.
+type CircuitOpenError struct {
+	// Circuit is the coordinate of the field, or the name of the type if the circuit is shared by
+	// the fields of the type.
+	Circuit string
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker of %q is open", e.Circuit)
+}
+
+// Extensions adds the code "CIRCUIT_OPEN" to the error returned to the client.
+func (e *CircuitOpenError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": "CIRCUIT_OPEN"}
+}
+
+// NewCircuitBreaker returns an in-memory CircuitBreaker that opens the circuit of a field after
+// consecutive failures. A circuit is closed initially. An open circuit fails the field with a
+// *CircuitOpenError until the open timeout elapsed; then a single trial call is allowed, which
+// closes the circuit on success and opens it again on failure.
+func NewCircuitBreaker(cfg CircuitBreakerConfig) CircuitBreaker {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 5
+	}
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = 30 * time.Second
+	}
+	return &circuitBreaker{
+		cfg:      cfg,
+		now:      time.Now,
+		circuits: make(map[string]*circuit),
+	}
+}
+
+type circuitBreaker struct {
+	cfg CircuitBreakerConfig
+	now func() time.Time
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+type circuit struct {
+	failures  int
+	openUntil time.Time
+	trial     bool
+}
+
+func (b *circuitBreaker) key(coordinate string) string {
+	if b.cfg.PerType {
+		if i := strings.IndexByte(coordinate, '.'); i >= 0 {
+			return coordinate[:i]
+		}
+	}
+	return coordinate
+}
+
+func (b *circuitBreaker) Allow(coordinate string) error {
+	key := b.key(coordinate)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c, ok := b.circuits[key]
+	if !ok || c.failures < b.cfg.FailureThreshold {
+		return nil
+	}
+	if c.trial || b.now().Before(c.openUntil) {
+		return &CircuitOpenError{Circuit: key}
+	}
+	c.trial = true
+	return nil
+}
+
+func (b *circuitBreaker) Done(coordinate string, err error) {
+	key := b.key(coordinate)
+	failed := err != nil && (b.cfg.IsFailure == nil || b.cfg.IsFailure(err))
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c, ok := b.circuits[key]
+	if !failed {
+		if ok && (c.trial || c.failures < b.cfg.FailureThreshold) {
+			delete(b.circuits, key)
+		}
+		return
+	}
+	if !ok {
+		c = &circuit{}
+		b.circuits[key] = c
+	}
+	c.failures++
+	c.trial = false
+	if c.failures >= b.cfg.FailureThreshold {
+		c.openUntil = b.now().Add(b.cfg.OpenTimeout)
+	}
+}
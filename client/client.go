@@ -0,0 +1,123 @@
+// Package client is a minimal GraphQL client. Operations are sent as JSON over HTTP, subscriptions
+// use the graphql-ws protocol over a WebSocket connection.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/graph-gophers/graphql-go/errors"
+)
+
+// Client executes GraphQL operations against a single endpoint.
+type Client struct {
+	// Endpoint is the URL of the GraphQL server. Subscriptions connect to the same URL with the
+	// "ws" or "wss" scheme.
+	Endpoint string
+
+	// HTTPClient is used for queries and mutations. If nil, http.DefaultClient is used.
+	HTTPClient *http.Client
+
+	// Header is added to every HTTP request and to the WebSocket handshake.
+	Header http.Header
+}
+
+// New returns a client for the given endpoint.
+func New(endpoint string) *Client {
+	return &Client{Endpoint: endpoint}
+}
+
+// Request is the body of a GraphQL request.
+type Request struct {
+	Query         string      `json:"query"`
+	OperationName string      `json:"operationName,omitempty"`
+	Variables     interface{} `json:"variables,omitempty"`
+}
+
+// Response is a GraphQL response as returned by the server.
+type Response struct {
+	Errors     []*errors.QueryError   `json:"errors,omitempty"`
+	Data       json.RawMessage        `json:"data,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// Errors are the errors of a GraphQL response. They are returned as a single error by Execute.
+type Errors []*errors.QueryError
+
+func (errs Errors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Execute sends the query to the endpoint with a default client and decodes the data of the
+// response into out. See (*Client).Execute.
+func Execute(ctx context.Context, endpoint string, query string, variables interface{}, out interface{}) error {
+	return New(endpoint).Execute(ctx, query, "", variables, out)
+}
+
+// Execute sends the query and decodes the data of the response into out, which may be nil. If the
+// response contains errors, the data is still decoded and the errors are returned as Errors.
+func (c *Client) Execute(ctx context.Context, query string, operationName string, variables interface{}, out interface{}) error {
+	resp, err := c.Do(ctx, &Request{Query: query, OperationName: operationName, Variables: variables})
+	if err != nil {
+		return err
+	}
+	if out != nil && len(resp.Data) != 0 {
+		if err := json.Unmarshal(resp.Data, out); err != nil {
+			return fmt.Errorf("decoding data: %s", err)
+		}
+	}
+	if len(resp.Errors) != 0 {
+		return Errors(resp.Errors)
+	}
+	return nil
+}
+
+// Do sends the request and returns the response of the server. Errors in the response are not
+// returned as an error.
+func (c *Client) Do(ctx context.Context, req *Request) (*Response, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequest(http.MethodPost, c.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range c.Header {
+		httpReq.Header[k] = v
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "application/json")
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	httpResp, err := httpClient.Do(httpReq.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	b, err := ioutil.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, err
+	}
+	resp := &Response{}
+	if err := json.Unmarshal(b, resp); err != nil {
+		if httpResp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected status %s: %s", httpResp.Status, bytes.TrimSpace(b))
+		}
+		return nil, fmt.Errorf("decoding response: %s", err)
+	}
+	return resp, nil
+}
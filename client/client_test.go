@@ -0,0 +1,210 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+)
+
+const schemaString = `
+	schema {
+		query: Query
+		subscription: Subscription
+	}
+
+	type Query {
+		greeting(name: String!): String!
+		fail: String
+	}
+
+	type Subscription {
+		counter(to: Int!): Int!
+	}
+`
+
+type resolver struct{}
+
+func (r *resolver) Greeting(args struct{ Name string }) string {
+	return "Hello, " + args.Name + "!"
+}
+
+func (r *resolver) Fail() (*string, error) {
+	return nil, errFail
+}
+
+var errFail = errors.New("failed")
+
+func (r *resolver) Counter(ctx context.Context, args struct{ To int32 }) <-chan int32 {
+	c := make(chan int32)
+	go func() {
+		defer close(c)
+		for i := int32(1); i <= args.To; i++ {
+			select {
+			case c <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return c
+}
+
+func newSchema() *graphql.Schema {
+	return graphql.MustParseSchema(schemaString, &resolver{})
+}
+
+func TestExecute(t *testing.T) {
+	srv := httptest.NewServer(&relay.Handler{Schema: newSchema()})
+	defer srv.Close()
+
+	var out struct {
+		Greeting string
+	}
+	err := Execute(context.Background(), srv.URL, `query($name: String!) { greeting(name: $name) }`, map[string]interface{}{"name": "Gopher"}, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Greeting != "Hello, Gopher!" {
+		t.Errorf("got %q, want %q", out.Greeting, "Hello, Gopher!")
+	}
+}
+
+func TestExecute_errors(t *testing.T) {
+	srv := httptest.NewServer(&relay.Handler{Schema: newSchema()})
+	defer srv.Close()
+
+	var out struct {
+		Greeting string
+		Fail     *string
+	}
+	err := New(srv.URL).Execute(context.Background(), `query Q { greeting(name: "a") fail }`, "Q", nil, &out)
+	errs, ok := err.(Errors)
+	if !ok {
+		t.Fatalf("expected Errors, got %#v", err)
+	}
+	if len(errs) != 1 || errs[0].Message != "failed" || !reflect.DeepEqual(errs[0].Path, []interface{}{"fail"}) {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+	if out.Greeting != "Hello, a!" {
+		t.Errorf("partial data was not decoded: %+v", out)
+	}
+}
+
+func TestExecute_status(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	if err := Execute(context.Background(), srv.URL, `{ fail }`, nil, nil); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	srv := httptest.NewServer(&wsHandler{t: t, schema: newSchema()})
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c, err := New(srv.URL).Subscribe(ctx, `subscription($to: Int!) { counter(to: $to) }`, "", map[string]interface{}{"to": 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []int32
+	for resp := range c {
+		if len(resp.Errors) != 0 {
+			t.Fatalf("unexpected errors: %v", resp.Errors)
+		}
+		var data struct{ Counter int32 }
+		if err := json.Unmarshal(resp.Data, &data); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, data.Counter)
+	}
+	if want := []int32{1, 2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestSubscribe_invalid(t *testing.T) {
+	srv := httptest.NewServer(&wsHandler{t: t, schema: newSchema()})
+	defer srv.Close()
+
+	c, err := New(srv.URL).Subscribe(context.Background(), `subscription { unknown }`, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, ok := <-c
+	if !ok || len(resp.Errors) == 0 {
+		t.Fatalf("expected an error response, got %+v", resp)
+	}
+}
+
+// wsHandler is a minimal graphql-ws server for a single subscription.
+type wsHandler struct {
+	t      *testing.T
+	schema *graphql.Schema
+}
+
+func (h *wsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get(subprotocolHeader) != Subprotocol {
+		http.Error(w, "unexpected subprotocol", http.StatusBadRequest)
+		return
+	}
+	netConn, brw, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		h.t.Error(err)
+		return
+	}
+	brw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n")
+	brw.WriteString("Sec-WebSocket-Accept: " + acceptKey(r.Header.Get("Sec-WebSocket-Key")) + "\r\n")
+	brw.WriteString(subprotocolHeader + ": " + Subprotocol + "\r\n\r\n")
+	brw.Flush()
+
+	conn := newWSConn(netConn, brw.Reader, false)
+	defer conn.Close()
+
+	if msg, err := readOperationMessage(conn); err != nil || msg.Type != msgConnectionInit {
+		h.t.Errorf("expected %q, got %+v (%v)", msgConnectionInit, msg, err)
+		return
+	}
+	writeOperationMessage(conn, &operationMessage{Type: msgConnectionAck})
+
+	msg, err := readOperationMessage(conn)
+	if err != nil || msg.Type != msgStart {
+		h.t.Errorf("expected %q, got %+v (%v)", msgStart, msg, err)
+		return
+	}
+	var req struct {
+		Query         string                 `json:"query"`
+		OperationName string                 `json:"operationName"`
+		Variables     map[string]interface{} `json:"variables"`
+	}
+	if err := json.Unmarshal(msg.Payload, &req); err != nil {
+		h.t.Error(err)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c, err := h.schema.Subscribe(ctx, req.Query, req.OperationName, req.Variables)
+	if err != nil {
+		h.t.Error(err)
+		return
+	}
+	for resp := range c {
+		payload, _ := json.Marshal(resp)
+		writeOperationMessage(conn, &operationMessage{ID: msg.ID, Type: msgData, Payload: payload})
+	}
+	writeOperationMessage(conn, &operationMessage{ID: msg.ID, Type: msgComplete})
+}
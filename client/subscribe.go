@@ -0,0 +1,155 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/graph-gophers/graphql-go/errors"
+)
+
+// Subprotocol is the WebSocket subprotocol spoken by Subscribe.
+const Subprotocol = "graphql-ws"
+
+// Message types of the graphql-ws protocol.
+const (
+	msgConnectionInit      = "connection_init"
+	msgConnectionAck       = "connection_ack"
+	msgConnectionError     = "connection_error"
+	msgConnectionTerminate = "connection_terminate"
+	msgKeepAlive           = "ka"
+	msgStart               = "start"
+	msgStop                = "stop"
+	msgData                = "data"
+	msgError               = "error"
+	msgComplete            = "complete"
+)
+
+type operationMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+const subscriptionID = "1"
+
+// Subscribe starts the subscription on a new WebSocket connection to the endpoint. Every event is
+// sent on the returned channel. The channel is closed when the server completes the subscription,
+// when the connection fails or when ctx is done, which also stops the subscription on the server.
+func (c *Client) Subscribe(ctx context.Context, query string, operationName string, variables interface{}) (<-chan *Response, error) {
+	conn, err := dialWebsocket(ctx, c.Endpoint, Subprotocol, c.Header)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.start(conn, &Request{Query: query, OperationName: operationName, Variables: variables}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	c2 := make(chan *Response)
+	done := make(chan struct{})
+	go func() {
+		defer close(c2)
+		defer close(done)
+		for {
+			msg, err := readOperationMessage(conn)
+			if err != nil {
+				return
+			}
+			var resp *Response
+			switch msg.Type {
+			case msgData:
+				resp = &Response{}
+				if err := json.Unmarshal(msg.Payload, resp); err != nil {
+					resp = &Response{Errors: []*errors.QueryError{errors.Errorf("decoding data: %s", err)}}
+				}
+			case msgError:
+				resp = &Response{Errors: payloadErrors(msg.Payload)}
+			case msgComplete:
+				return
+			default:
+				continue
+			}
+			select {
+			case c2 <- resp:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			writeOperationMessage(conn, &operationMessage{ID: subscriptionID, Type: msgStop})
+			writeOperationMessage(conn, &operationMessage{Type: msgConnectionTerminate})
+		case <-done:
+		}
+		conn.Close()
+	}()
+
+	return c2, nil
+}
+
+// start initializes the connection and starts the subscription.
+func (c *Client) start(conn *wsConn, req *Request) error {
+	if err := writeOperationMessage(conn, &operationMessage{Type: msgConnectionInit}); err != nil {
+		return err
+	}
+	for {
+		msg, err := readOperationMessage(conn)
+		if err != nil {
+			return err
+		}
+		if msg.Type == msgKeepAlive {
+			continue
+		}
+		if msg.Type == msgConnectionError {
+			return fmt.Errorf("connection rejected: %s", msg.Payload)
+		}
+		if msg.Type != msgConnectionAck {
+			return fmt.Errorf("unexpected message %q before %q", msg.Type, msgConnectionAck)
+		}
+		break
+	}
+
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	return writeOperationMessage(conn, &operationMessage{ID: subscriptionID, Type: msgStart, Payload: payload})
+}
+
+// payloadErrors decodes the payload of an error message, which is either a list of errors or a
+// single error.
+func payloadErrors(payload json.RawMessage) []*errors.QueryError {
+	var errs []*errors.QueryError
+	if err := json.Unmarshal(payload, &errs); err == nil {
+		return errs
+	}
+	qErr := &errors.QueryError{}
+	if err := json.Unmarshal(payload, qErr); err != nil || qErr.Message == "" {
+		qErr = errors.Errorf("%s", payload)
+	}
+	return []*errors.QueryError{qErr}
+}
+
+func readOperationMessage(conn *wsConn) (*operationMessage, error) {
+	b, err := conn.readMessage()
+	if err != nil {
+		return nil, err
+	}
+	msg := &operationMessage{}
+	if err := json.Unmarshal(b, msg); err != nil {
+		return nil, fmt.Errorf("decoding message: %s", err)
+	}
+	return msg, nil
+}
+
+func writeOperationMessage(conn *wsConn, msg *operationMessage) error {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return conn.writeMessage(opText, b)
+}
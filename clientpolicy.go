@@ -0,0 +1,94 @@
+package graphql
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/graph-gophers/graphql-go/errors"
+)
+
+// ClientPolicy restricts the operations of a client, see ClientPolicies. Zero values disable a
+// restriction.
+type ClientPolicy struct {
+	// MaxComplexity is the maximum OperationInfo.Complexity of the operations of the client.
+	MaxComplexity int
+
+	// RejectDeprecated rejects the operations of the client using deprecated schema members, like
+	// EnforceDeprecations with DeprecationReject.
+	RejectDeprecated bool
+
+	// PersistedOnly rejects the operations of the client whose document was not registered with
+	// PersistedOperations.
+	PersistedOnly bool
+}
+
+// ClientPolicies applies a ClientPolicy to the operations of each client, identified by the name
+// of its ClientInfo. The policy with the empty name applies to all other clients, including
+// requests without a ClientInfo. The policies are checked after validation and before the
+// RateLimiter is consulted.
+func ClientPolicies(policies map[string]ClientPolicy) SchemaOpt {
+	return func(s *Schema) {
+		s.clientPolicies = policies
+	}
+}
+
+// PersistedOperations registers the query documents accepted from clients with a PersistedOnly
+// policy. Documents are compared by their SHA-256 hash, see OperationInfo.DocumentHash, so they
+// must match byte for byte.
+func PersistedOperations(documents ...string) SchemaOpt {
+	return func(s *Schema) {
+		if s.persisted == nil {
+			s.persisted = make(map[string]bool)
+		}
+		for _, doc := range documents {
+			hash := sha256.Sum256([]byte(doc))
+			s.persisted[hex.EncodeToString(hash[:])] = true
+		}
+	}
+}
+
+// clientPolicy returns the policy of a client.
+func (s *Schema) clientPolicy(client ClientInfo) ClientPolicy {
+	if p, ok := s.clientPolicies[client.Name]; ok {
+		return p
+	}
+	return s.clientPolicies[""]
+}
+
+// clientDeprecationPolicy returns the deprecation policy rejecting the operations of the clients
+// whose ClientPolicy has RejectDeprecated set, or nil if there are none.
+func (s *Schema) clientDeprecationPolicy() *deprecationPolicy {
+	for _, p := range s.clientPolicies {
+		if p.RejectDeprecated {
+			return &deprecationPolicy{
+				mode: DeprecationReject,
+				match: func(client ClientInfo) bool {
+					return s.clientPolicy(client).RejectDeprecated
+				},
+			}
+		}
+	}
+	return nil
+}
+
+// checkClientPolicy applies the policy of the client of an operation.
+func (s *Schema) checkClientPolicy(info *OperationInfo) *errors.QueryError {
+	if s.clientPolicies == nil {
+		return nil
+	}
+	p := s.clientPolicy(info.Client)
+	if p.PersistedOnly && !s.persisted[info.DocumentHash] {
+		return &errors.QueryError{
+			Message:    fmt.Sprintf("client %q may only execute persisted operations", info.Client.Name),
+			Extensions: map[string]interface{}{"code": "PERSISTED_OPERATION_REQUIRED"},
+		}
+	}
+	if p.MaxComplexity > 0 && info.Complexity > p.MaxComplexity {
+		return &errors.QueryError{
+			Message:    fmt.Sprintf("operation has complexity %d, which exceeds the max complexity %d of client %q", info.Complexity, p.MaxComplexity, info.Client.Name),
+			Extensions: map[string]interface{}{"code": "COMPLEXITY_EXCEEDED"},
+		}
+	}
+	return nil
+}
@@ -0,0 +1,377 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/internal/common"
+	"github.com/graph-gophers/graphql-go/internal/query"
+	"github.com/graph-gophers/graphql-go/introspection"
+)
+
+// clientScalars maps the predeclared GraphQL scalars to the Go types used by generated clients.
+var clientScalars = map[string]string{
+	"Int":     "int32",
+	"Float":   "float64",
+	"String":  "string",
+	"Boolean": "bool",
+	"ID":      "string",
+}
+
+// Operations is a set of client operation documents keyed by their file name.
+type Operations map[string]string
+
+type clientGen struct {
+	schema *introspection.Schema
+	types  map[string]*introspection.Type
+	buf    bytes.Buffer
+
+	enums   map[string]bool
+	inputs  map[string]bool
+	imports map[string]bool
+}
+
+type goStruct struct {
+	name   string
+	fields []*goField
+	byName map[string]*goField
+}
+
+type goField struct {
+	name     string
+	jsonName string
+	typ      *introspection.Type
+	typename bool
+	optional bool
+	sub      *goStruct
+}
+
+// GenerateClient generates typed Go functions that execute the named operations of the given
+// documents. The documents are validated against the schema first.
+func GenerateClient(s *graphql.Schema, ops Operations, pkg string) ([]byte, error) {
+	g := &clientGen{
+		schema:  s.Inspect(),
+		types:   make(map[string]*introspection.Type),
+		enums:   make(map[string]bool),
+		inputs:  make(map[string]bool),
+		imports: map[string]bool{"context": true},
+	}
+	for _, t := range g.schema.Types() {
+		g.types[*t.Name()] = t
+	}
+
+	files := make([]string, 0, len(ops))
+	for name := range ops {
+		files = append(files, name)
+	}
+	sort.Strings(files)
+
+	var body bytes.Buffer
+	for _, file := range files {
+		src := ops[file]
+		// The operations are validated without the values of their variables.
+		if _, errs := s.ParseDocument(src); len(errs) != 0 {
+			return nil, fmt.Errorf("%s: %s", file, errs[0])
+		}
+		doc, err := query.Parse(src)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", file, err)
+		}
+		docConst := ""
+		for _, op := range doc.Operations {
+			if op.Name.Name == "" {
+				return nil, fmt.Errorf("%s: anonymous operations are not supported", file)
+			}
+			g.buf.Reset()
+			if err := g.operation(doc, op, src, docConst); err != nil {
+				return nil, fmt.Errorf("%s: %s", file, err)
+			}
+			body.Write(g.buf.Bytes())
+			if docConst == "" {
+				docConst = goName(op.Name.Name) + "Query"
+			}
+		}
+	}
+
+	g.buf.Reset()
+	g.namedTypes()
+	body.Write(g.buf.Bytes())
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by graphql-gen. DO NOT EDIT.\n\npackage %s\n\n", pkg)
+	writeImports(&src, g.imports)
+	src.WriteString(executorDecl)
+	src.Write(body.Bytes())
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %s", err)
+	}
+	return formatted, nil
+}
+
+const executorDecl = `// Executor executes a GraphQL operation with the given variables and decodes the "data" of the
+// response into data. Errors in the response should be returned as an error.
+type Executor interface {
+	Execute(ctx context.Context, query string, operationName string, variables interface{}, data interface{}) error
+}
+
+`
+
+func (g *clientGen) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *clientGen) operation(doc *query.Document, op *query.Operation, src string, docConst string) error {
+	name := goName(op.Name.Name)
+
+	var root *introspection.Type
+	switch op.Type {
+	case query.Query:
+		root = g.schema.QueryType()
+	case query.Mutation:
+		root = g.schema.MutationType()
+	case query.Subscription:
+		return fmt.Errorf("operation %q: subscriptions are not supported", op.Name.Name)
+	}
+
+	resp := newGoStruct(name + "Response")
+	g.collect(doc, op.Selections, root, resp, false)
+
+	// The whole document is sent, the server selects the operation by its name.
+	g.printf("// %sQuery is the document containing the %s operation.\n", name, op.Name.Name)
+	if docConst != "" {
+		g.printf("const %sQuery = %s\n\n", name, docConst)
+	} else {
+		g.printf("const %sQuery = %s\n\n", name, backquote(src))
+	}
+
+	varsType := "interface{}"
+	if len(op.Vars) > 0 {
+		varsType = "*" + name + "Variables"
+		g.printf("// %sVariables are the variables of the %s operation.\n", name, op.Name.Name)
+		g.printf("type %sVariables struct {\n", name)
+		for _, v := range op.Vars {
+			t := g.inputType(v.Type)
+			tag := v.Name.Name
+			if _, ok := v.Type.(*common.NonNull); !ok {
+				tag += ",omitempty"
+			}
+			g.printf("%s %s `json:%q`\n", goName(v.Name.Name), t, tag)
+		}
+		g.printf("}\n\n")
+	}
+
+	g.structDecl(resp, fmt.Sprintf("is the data of the %s operation.", op.Name.Name))
+
+	g.printf("// %s executes the %s operation.\n", name, op.Name.Name)
+	g.printf("func %s(ctx context.Context, e Executor, vars %s) (*%s, error) {\n", name, varsType, resp.name)
+	g.printf("var data %s\n", resp.name)
+	g.printf("if err := e.Execute(ctx, %sQuery, %q, vars, &data); err != nil {\nreturn nil, err\n}\n", name, op.Name.Name)
+	g.printf("return &data, nil\n}\n\n")
+	return nil
+}
+
+func newGoStruct(name string) *goStruct {
+	return &goStruct{name: name, byName: make(map[string]*goField)}
+}
+
+// collect merges the selections into st. Fields of fragments on a more specific type are optional,
+// since they are only present for some of the possible types.
+func (g *clientGen) collect(doc *query.Document, sels []query.Selection, t *introspection.Type, st *goStruct, optional bool) {
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *query.Field:
+			alias := sel.Alias.Name
+			f, ok := st.byName[alias]
+			if !ok {
+				f = &goField{name: goName(alias), jsonName: alias, optional: optional}
+				if sel.Name.Name == "__typename" {
+					f.typename = true
+				} else {
+					f.typ = field(t, sel.Name.Name).Type()
+				}
+				st.byName[alias] = f
+				st.fields = append(st.fields, f)
+			}
+			f.optional = f.optional && optional
+			if sel.Selections != nil {
+				if f.sub == nil {
+					f.sub = newGoStruct(st.name + f.name)
+				}
+				g.collect(doc, sel.Selections, g.types[*unwrap(f.typ).Name()], f.sub, false)
+			}
+
+		case *query.InlineFragment:
+			ft := t
+			if sel.On.Name != "" {
+				ft = g.types[sel.On.Name]
+			}
+			g.collect(doc, sel.Selections, ft, st, optional || *ft.Name() != *t.Name())
+
+		case *query.FragmentSpread:
+			frag := doc.Fragments.Get(sel.Name.Name)
+			ft := g.types[frag.On.Name]
+			g.collect(doc, frag.Selections, ft, st, optional || *ft.Name() != *t.Name())
+		}
+	}
+}
+
+func (g *clientGen) structDecl(st *goStruct, doc string) {
+	g.printf("// %s %s\n", st.name, doc)
+	g.printf("type %s struct {\n", st.name)
+	for _, f := range st.fields {
+		if f.typename {
+			g.printf("%s string `json:%q`\n", f.name, f.jsonName)
+			continue
+		}
+		g.printf("%s %s `json:%q`\n", f.name, g.outputType(f.typ, f.sub, f.optional), f.jsonName)
+	}
+	g.printf("}\n\n")
+	for _, f := range st.fields {
+		if f.sub != nil {
+			g.structDecl(f.sub, fmt.Sprintf("is the selection of the %q field.", f.jsonName))
+		}
+	}
+}
+
+func (g *clientGen) outputType(t *introspection.Type, sub *goStruct, optional bool) string {
+	nonNull := !optional && t.Kind() == "NON_NULL"
+	if t.Kind() == "NON_NULL" {
+		t = t.OfType()
+	}
+	var typ string
+	switch t.Kind() {
+	case "LIST":
+		typ = "[]" + g.outputType(t.OfType(), sub, false)
+	case "OBJECT", "INTERFACE", "UNION":
+		typ = sub.name
+	default:
+		typ = g.leafType(t)
+	}
+	if !nonNull {
+		typ = "*" + typ
+	}
+	return typ
+}
+
+func (g *clientGen) inputType(t common.Type) string {
+	nonNull := false
+	if nn, ok := t.(*common.NonNull); ok {
+		nonNull = true
+		t = nn.OfType
+	}
+	var typ string
+	switch t := t.(type) {
+	case *common.List:
+		typ = "[]" + g.inputType(t.OfType)
+	case *common.TypeName:
+		typ = g.leafType(g.types[t.Name])
+	}
+	if !nonNull {
+		typ = "*" + typ
+	}
+	return typ
+}
+
+func (g *clientGen) introspectionInputType(t *introspection.Type) string {
+	nonNull := false
+	if t.Kind() == "NON_NULL" {
+		nonNull = true
+		t = t.OfType()
+	}
+	var typ string
+	if t.Kind() == "LIST" {
+		typ = "[]" + g.introspectionInputType(t.OfType())
+	} else {
+		typ = g.leafType(t)
+	}
+	if !nonNull {
+		typ = "*" + typ
+	}
+	return typ
+}
+
+func (g *clientGen) leafType(t *introspection.Type) string {
+	name := *t.Name()
+	switch t.Kind() {
+	case "ENUM":
+		g.enums[name] = true
+		return name
+	case "INPUT_OBJECT":
+		if !g.inputs[name] {
+			g.inputs[name] = true
+			for _, v := range *t.InputFields() {
+				g.introspectionInputType(v.Type())
+			}
+		}
+		return name
+	}
+	if typ, ok := clientScalars[name]; ok {
+		return typ
+	}
+	// Custom scalars are passed through without interpretation.
+	g.imports["encoding/json"] = true
+	return "json.RawMessage"
+}
+
+func (g *clientGen) namedTypes() {
+	for _, name := range sortedKeys(g.inputs) {
+		t := g.types[name]
+		g.printf("// %s is the %s input object.\n", name, name)
+		g.printf("type %s struct {\n", name)
+		for _, v := range *t.InputFields() {
+			tag := v.Name()
+			if v.Type().Kind() != "NON_NULL" {
+				tag += ",omitempty"
+			}
+			g.printf("%s %s `json:%q`\n", goName(v.Name()), g.introspectionInputType(v.Type()), tag)
+		}
+		g.printf("}\n\n")
+	}
+	for _, name := range sortedKeys(g.enums) {
+		t := g.types[name]
+		g.printf("// %s is the %s enum.\n", name, name)
+		g.printf("type %s string\n\n", name)
+		g.printf("// Values of the %s enum.\n", name)
+		g.printf("const (\n")
+		for _, v := range *t.EnumValues(includeDeprecated) {
+			g.printf("%s%s %s = %q\n", name, enumValueName(v.Name()), name, v.Name())
+		}
+		g.printf(")\n\n")
+	}
+}
+
+// backquote quotes src as a raw string literal.
+func backquote(src string) string {
+	return "`" + strings.Replace(src, "`", "` + \"`\" + `", -1) + "`"
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func field(t *introspection.Type, name string) *introspection.Field {
+	for _, f := range *t.Fields(includeDeprecated) {
+		if f.Name() == name {
+			return f
+		}
+	}
+	return nil
+}
+
+func unwrap(t *introspection.Type) *introspection.Type {
+	for t.OfType() != nil {
+		t = t.OfType()
+	}
+	return t
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+func TestGenerateClient(t *testing.T) {
+	b, err := ioutil.ReadFile("../../example/starwars/introspect.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sdl, err := introspectionToSDL(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := graphql.ParseSchema(sdl, nil, graphql.UseStringDescriptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := GenerateClient(s, Operations{"hero.graphql": `
+		query HeroName($episode: Episode) {
+			hero(episode: $episode) {
+				__typename
+				name
+				... on Human {
+					height
+				}
+			}
+		}
+
+		mutation CreateReview($review: ReviewInput!) {
+			createReview(episode: JEDI, review: $review) {
+				stars
+			}
+		}
+	`}, "client")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Collapse the alignment of struct fields and constants.
+	got := strings.Join(strings.Fields(string(src)), " ")
+	for _, want := range []string{
+		"package client",
+		"func HeroName(ctx context.Context, e Executor, vars *HeroNameVariables) (*HeroNameResponse, error)",
+		"Episode *Episode `json:\"episode,omitempty\"`",
+		"Hero *HeroNameResponseHero `json:\"hero\"`",
+		"Typename string `json:\"__typename\"`",
+		"Height *float64 `json:\"height\"`",
+		"const CreateReviewQuery = HeroNameQuery",
+		"Review ReviewInput `json:\"review\"`",
+		"type ReviewInput struct {",
+		"EpisodeJedi Episode = \"JEDI\"",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("generated code does not contain %q", want)
+		}
+	}
+}
+
+func TestGenerateClient_anonymousOperation(t *testing.T) {
+	s := graphql.MustParseSchema(`schema { query: Query } type Query { hello: String! }`, nil)
+	if _, err := GenerateClient(s, Operations{"a.graphql": `{ hello }`}, "client"); err == nil {
+		t.Error("expected an error for an anonymous operation")
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/graph-gophers/graphql-go/introspection"
+)
+
+// goDocs are the doc comments of a Go type, its methods and its struct fields.
+type goDocs struct {
+	doc     string
+	members map[string]string // by normalized name
+}
+
+// GenerateDescriptions generates the Go source of a map of the descriptions of the schema members
+// without a description, taken from the doc comments of the Go types resolving them, to be passed
+// to graphql.Descriptions. A Go type resolves the GraphQL type of the same name, optionally with a
+// "Resolver" suffix, and the type named "Resolver" resolves the query type. Fields are described by
+// the methods and struct fields binding them, input fields by the struct fields of the Go type of
+// the input object.
+func GenerateDescriptions(s *introspection.Schema, files []*ast.File, pkg, varName string) ([]byte, error) {
+	descriptions := collectDescriptions(s, files)
+	coordinates := make([]string, 0, len(descriptions))
+	for coordinate := range descriptions {
+		coordinates = append(coordinates, coordinate)
+	}
+	sort.Strings(coordinates)
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by graphql-gen. DO NOT EDIT.\n\npackage %s\n\n", pkg)
+	fmt.Fprintf(&src, "// %s are the descriptions of the schema members taken from the doc comments of their\n// resolvers, see graphql.Descriptions.\n", varName)
+	fmt.Fprintf(&src, "var %s = map[string]string{\n", varName)
+	for _, coordinate := range coordinates {
+		fmt.Fprintf(&src, "%s: %s,\n", strconv.Quote(coordinate), strconv.Quote(descriptions[coordinate]))
+	}
+	src.WriteString("}\n")
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %s", err)
+	}
+	return formatted, nil
+}
+
+// collectDescriptions returns the descriptions of the schema members without a description by
+// their schema coordinates, as described for GenerateDescriptions.
+func collectDescriptions(s *introspection.Schema, files []*ast.File) map[string]string {
+	docs := collectDocs(files)
+	goTypes := make([]string, 0, len(docs))
+	for goType := range docs {
+		goTypes = append(goTypes, goType)
+	}
+	sort.Strings(goTypes)
+	queryType := ""
+	if t := s.QueryType(); t != nil {
+		queryType = *t.Name()
+	}
+
+	descriptions := make(map[string]string)
+	for _, t := range s.Types() {
+		name := *t.Name()
+		if strings.HasPrefix(name, "__") {
+			continue
+		}
+		var matches []*goDocs
+		for _, goType := range goTypes {
+			resolves := strings.TrimSuffix(goType, "resolver")
+			if resolves == normalizeName(name) || resolves == "" && name == queryType {
+				matches = append(matches, docs[goType])
+			}
+		}
+		for _, d := range matches {
+			if d.doc != "" && emptyDescription(t.Description()) {
+				descriptions[name] = d.doc
+				break
+			}
+		}
+
+		if fields := t.Fields(includeDeprecated); fields != nil {
+			for _, f := range *fields {
+				if doc := memberDoc(matches, f.Name()); doc != "" && emptyDescription(f.Description()) {
+					descriptions[name+"."+f.Name()] = doc
+				}
+			}
+		}
+		if inputFields := t.InputFields(); inputFields != nil {
+			for _, f := range *inputFields {
+				if doc := memberDoc(matches, f.Name()); doc != "" && emptyDescription(f.Description()) {
+					descriptions[name+"."+f.Name()] = doc
+				}
+			}
+		}
+	}
+
+	return descriptions
+}
+
+// collectDocs returns the doc comments of the types declared in the files by their normalized
+// names.
+func collectDocs(files []*ast.File) map[string]*goDocs {
+	docs := make(map[string]*goDocs)
+	get := func(name string) *goDocs {
+		key := normalizeName(name)
+		if docs[key] == nil {
+			docs[key] = &goDocs{members: make(map[string]string)}
+		}
+		return docs[key]
+	}
+
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.GenDecl:
+				if decl.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range decl.Specs {
+					spec := spec.(*ast.TypeSpec)
+					d := get(spec.Name.Name)
+					doc := spec.Doc
+					if doc == nil && len(decl.Specs) == 1 {
+						doc = decl.Doc
+					}
+					if text := docText(doc); text != "" {
+						d.doc = text
+					}
+					var members *ast.FieldList
+					switch t := spec.Type.(type) {
+					case *ast.StructType:
+						members = t.Fields
+					case *ast.InterfaceType:
+						members = t.Methods
+					}
+					if members == nil {
+						continue
+					}
+					for _, m := range members.List {
+						for _, name := range m.Names {
+							if text := docText(m.Doc); text != "" {
+								d.members[normalizeName(name.Name)] = text
+							}
+						}
+					}
+				}
+
+			case *ast.FuncDecl:
+				if decl.Recv == nil || len(decl.Recv.List) == 0 {
+					continue
+				}
+				recv := decl.Recv.List[0].Type
+				if star, ok := recv.(*ast.StarExpr); ok {
+					recv = star.X
+				}
+				ident, ok := recv.(*ast.Ident)
+				if !ok {
+					continue
+				}
+				if text := docText(decl.Doc); text != "" {
+					get(ident.Name).members[normalizeName(decl.Name.Name)] = text
+				}
+			}
+		}
+	}
+	return docs
+}
+
+// memberDoc returns the doc comment of the method or struct field binding a field.
+func memberDoc(matches []*goDocs, field string) string {
+	for _, d := range matches {
+		if doc, ok := d.members[normalizeName(field)]; ok {
+			return doc
+		}
+	}
+	return ""
+}
+
+func docText(doc *ast.CommentGroup) string {
+	if doc == nil {
+		return ""
+	}
+	return strings.TrimSpace(doc.Text())
+}
+
+// normalizeName lowercases a name and removes its underscores, the way resolver methods are
+// matched to fields.
+func normalizeName(name string) string {
+	return strings.ToLower(strings.Replace(name, "_", "", -1))
+}
+
+func emptyDescription(desc *string) bool {
+	return desc == nil || *desc == ""
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+const resolversSrc = `package resolvers
+
+// Resolver is the root resolver.
+type Resolver struct{}
+
+// User returns the signed in user.
+func (r *Resolver) User() *userResolver { return nil }
+
+// A user of the shop.
+type userResolver struct {
+	// The login name of the user.
+	Login string
+}
+
+// FullName is the name shown to other users.
+func (u *userResolver) FullName() string { return "" }
+
+// Ignored is not part of the schema.
+func (u *userResolver) Ignored() string { return "" }
+
+type UserInput struct {
+	// The new login name.
+	Login string
+}
+`
+
+func TestGenerateDescriptions(t *testing.T) {
+	s := graphql.MustParseSchema(`
+		type Query {
+			user: User
+			updateUser(input: UserInput!): User
+		}
+
+		type User {
+			login: String!
+			fullName: String!
+			"The email address."
+			email: String!
+		}
+
+		input UserInput {
+			login: String
+		}
+	`, nil, graphql.UseStringDescriptions())
+	file, err := parser.ParseFile(token.NewFileSet(), "resolvers.go", resolversSrc, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := GenerateDescriptions(s.Inspect(), []*ast.File{file}, "resolvers", "schemaDescriptions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `var schemaDescriptions = map[string]string{
+	"Query":           "Resolver is the root resolver.",
+	"Query.user":      "User returns the signed in user.",
+	"User":            "A user of the shop.",
+	"User.fullName":   "FullName is the name shown to other users.",
+	"User.login":      "The login name of the user.",
+	"UserInput.login": "The new login name.",
+}
+`
+	if !strings.Contains(string(src), want) {
+		t.Errorf("unexpected generated code:\n%s", src)
+	}
+}
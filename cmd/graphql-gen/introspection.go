@@ -0,0 +1,200 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// introspectionResult is the result of the introspection query used by (*graphql.Schema).ToJSON.
+type introspectionResult struct {
+	Schema struct {
+		QueryType        *typeRef            `json:"queryType"`
+		MutationType     *typeRef            `json:"mutationType"`
+		SubscriptionType *typeRef            `json:"subscriptionType"`
+		Types            []introspectionType `json:"types"`
+	} `json:"__schema"`
+}
+
+type introspectionType struct {
+	Kind          string               `json:"kind"`
+	Name          string               `json:"name"`
+	Description   *string              `json:"description"`
+	Fields        []introspectionField `json:"fields"`
+	InputFields   []introspectionValue `json:"inputFields"`
+	Interfaces    []typeRef            `json:"interfaces"`
+	EnumValues    []introspectionEnum  `json:"enumValues"`
+	PossibleTypes []typeRef            `json:"possibleTypes"`
+}
+
+type introspectionField struct {
+	Name              string               `json:"name"`
+	Description       *string              `json:"description"`
+	Args              []introspectionValue `json:"args"`
+	Type              typeRef              `json:"type"`
+	IsDeprecated      bool                 `json:"isDeprecated"`
+	DeprecationReason *string              `json:"deprecationReason"`
+}
+
+type introspectionValue struct {
+	Name         string  `json:"name"`
+	Description  *string `json:"description"`
+	Type         typeRef `json:"type"`
+	DefaultValue *string `json:"defaultValue"`
+}
+
+type introspectionEnum struct {
+	Name              string  `json:"name"`
+	Description       *string `json:"description"`
+	IsDeprecated      bool    `json:"isDeprecated"`
+	DeprecationReason *string `json:"deprecationReason"`
+}
+
+type typeRef struct {
+	Kind   string   `json:"kind"`
+	Name   *string  `json:"name"`
+	OfType *typeRef `json:"ofType"`
+}
+
+func (t typeRef) String() string {
+	switch t.Kind {
+	case "NON_NULL":
+		return t.OfType.String() + "!"
+	case "LIST":
+		return "[" + t.OfType.String() + "]"
+	default:
+		return *t.Name
+	}
+}
+
+// introspectionToSDL converts the JSON result of an introspection query, with or without the
+// surrounding "data" object, into a schema definition that can be parsed with string descriptions.
+func introspectionToSDL(data []byte) (string, error) {
+	var wrapped struct {
+		Data *introspectionResult `json:"data"`
+	}
+	if err := json.Unmarshal(data, &wrapped); err != nil {
+		return "", err
+	}
+	res := wrapped.Data
+	if res == nil {
+		res = &introspectionResult{}
+		if err := json.Unmarshal(data, res); err != nil {
+			return "", err
+		}
+	}
+	if res.Schema.QueryType == nil {
+		return "", fmt.Errorf("introspection result does not contain a query type")
+	}
+
+	var b strings.Builder
+	b.WriteString("schema {\n")
+	fmt.Fprintf(&b, "\tquery: %s\n", *res.Schema.QueryType.Name)
+	if t := res.Schema.MutationType; t != nil {
+		fmt.Fprintf(&b, "\tmutation: %s\n", *t.Name)
+	}
+	if t := res.Schema.SubscriptionType; t != nil {
+		fmt.Fprintf(&b, "\tsubscription: %s\n", *t.Name)
+	}
+	b.WriteString("}\n\n")
+
+	types := res.Schema.Types
+	sort.Slice(types, func(i, j int) bool { return types[i].Name < types[j].Name })
+	for _, t := range types {
+		if strings.HasPrefix(t.Name, "__") || isBuiltinScalar(t) {
+			continue
+		}
+		writeDescription(&b, "", t.Description)
+		switch t.Kind {
+		case "SCALAR":
+			fmt.Fprintf(&b, "scalar %s\n\n", t.Name)
+
+		case "OBJECT", "INTERFACE":
+			keyword := "type"
+			if t.Kind == "INTERFACE" {
+				keyword = "interface"
+			}
+			fmt.Fprintf(&b, "%s %s", keyword, t.Name)
+			if len(t.Interfaces) > 0 {
+				names := make([]string, len(t.Interfaces))
+				for i, intf := range t.Interfaces {
+					names[i] = *intf.Name
+				}
+				fmt.Fprintf(&b, " implements %s", strings.Join(names, " & "))
+			}
+			b.WriteString(" {\n")
+			for _, f := range t.Fields {
+				writeDescription(&b, "\t", f.Description)
+				fmt.Fprintf(&b, "\t%s", f.Name)
+				if len(f.Args) > 0 {
+					args := make([]string, len(f.Args))
+					for i, a := range f.Args {
+						args[i] = inputValueSDL(a)
+					}
+					fmt.Fprintf(&b, "(%s)", strings.Join(args, ", "))
+				}
+				fmt.Fprintf(&b, ": %s%s\n", f.Type, deprecatedSDL(f.IsDeprecated, f.DeprecationReason))
+			}
+			b.WriteString("}\n\n")
+
+		case "UNION":
+			names := make([]string, len(t.PossibleTypes))
+			for i, pt := range t.PossibleTypes {
+				names[i] = *pt.Name
+			}
+			fmt.Fprintf(&b, "union %s = %s\n\n", t.Name, strings.Join(names, " | "))
+
+		case "ENUM":
+			fmt.Fprintf(&b, "enum %s {\n", t.Name)
+			for _, v := range t.EnumValues {
+				writeDescription(&b, "\t", v.Description)
+				fmt.Fprintf(&b, "\t%s%s\n", v.Name, deprecatedSDL(v.IsDeprecated, v.DeprecationReason))
+			}
+			b.WriteString("}\n\n")
+
+		case "INPUT_OBJECT":
+			fmt.Fprintf(&b, "input %s {\n", t.Name)
+			for _, v := range t.InputFields {
+				writeDescription(&b, "\t", v.Description)
+				fmt.Fprintf(&b, "\t%s\n", inputValueSDL(v))
+			}
+			b.WriteString("}\n\n")
+		}
+	}
+	return b.String(), nil
+}
+
+func isBuiltinScalar(t introspectionType) bool {
+	switch t.Name {
+	case "Int", "Float", "String", "Boolean", "ID":
+		return t.Kind == "SCALAR"
+	}
+	return false
+}
+
+func inputValueSDL(v introspectionValue) string {
+	s := v.Name + ": " + v.Type.String()
+	if v.DefaultValue != nil {
+		s += " = " + *v.DefaultValue
+	}
+	return s
+}
+
+func deprecatedSDL(deprecated bool, reason *string) string {
+	if !deprecated {
+		return ""
+	}
+	if reason == nil {
+		return " @deprecated"
+	}
+	return " @deprecated(reason: " + strconv.Quote(*reason) + ")"
+}
+
+func writeDescription(b *strings.Builder, indent string, desc *string) {
+	if desc == nil || *desc == "" {
+		return
+	}
+	fmt.Fprintf(b, "%s%s\n", indent, strconv.Quote(*desc))
+}
@@ -0,0 +1,330 @@
+// Command graphql-gen generates Go code from a GraphQL schema.
+//
+// Usage:
+//
+//	graphql-gen resolvers -schema schema.graphql -package resolvers -o resolvers_gen.go
+//
+//	graphql-gen client -schema schema.json -operations ./queries -package client -o client_gen.go
+//
+//	graphql-gen descriptions -schema schema.graphql -dir ./resolvers -o descriptions_gen.go
+//
+//	graphql-gen artifacts -schema schema.graphql -resolvers ./resolvers -dir ./artifacts -typescript
+//
+// The "resolvers" command emits resolver interfaces, argument structs, enum types and input structs
+// that follow the method binding conventions of graphql-go.
+//
+// The "client" command emits a typed Go function for every named operation, with structs for its
+// variables and response data. The schema may be given as SDL or as the JSON result of an
+// introspection query, as produced by (*graphql.Schema).ToJSON.
+//
+// The "descriptions" command emits a map of descriptions for graphql.Descriptions, taken from the
+// doc comments of the resolvers in a Go package, for the schema members not described in the
+// schema itself.
+//
+// The "artifacts" command writes the files to publish to client teams into a directory: the
+// canonical SDL of the schema (schema.graphql), the result of the introspection query
+// (schema.json) and optionally TypeScript declarations of its types (schema.ts). If the package of
+// the resolvers is given, the artifacts include the descriptions taken from its doc comments like
+// the "descriptions" command.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+var commands = map[string]func(args []string) error{
+	"resolvers":    runResolvers,
+	"client":       runClient,
+	"descriptions": runDescriptions,
+	"artifacts":    runArtifacts,
+}
+
+func main() {
+	if len(os.Args) < 2 || commands[os.Args[1]] == nil {
+		usage()
+		os.Exit(2)
+	}
+	if err := commands[os.Args[1]](os.Args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "graphql-gen: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: graphql-gen <command> [flags]\n\ncommands:\n")
+	fmt.Fprintf(os.Stderr, "\tresolvers\tgenerate resolver interfaces from a schema\n")
+	fmt.Fprintf(os.Stderr, "\tclient\t\tgenerate typed client functions for operations\n")
+	fmt.Fprintf(os.Stderr, "\tdescriptions\tgenerate schema descriptions from Go doc comments\n")
+	fmt.Fprintf(os.Stderr, "\tartifacts\tgenerate SDL, introspection JSON and TypeScript files for clients\n")
+}
+
+func runResolvers(args []string) error {
+	fs := flag.NewFlagSet("resolvers", flag.ExitOnError)
+	schemaFile := fs.String("schema", "", "path of the GraphQL schema `file`")
+	pkg := fs.String("package", "resolvers", "name of the generated Go package")
+	out := fs.String("o", "", "output `file` (default stdout)")
+	descriptions := fs.Bool("string-descriptions", false, "parse string descriptions instead of comments")
+	fs.Parse(args)
+
+	s, err := loadSchema(*schemaFile, *descriptions)
+	if err != nil {
+		return err
+	}
+
+	src, err := GenerateResolvers(s.Inspect(), *pkg)
+	if err != nil {
+		return err
+	}
+	return writeOutput(*out, src)
+}
+
+func runClient(args []string) error {
+	fs := flag.NewFlagSet("client", flag.ExitOnError)
+	schemaFile := fs.String("schema", "", "path of the GraphQL schema `file`, either SDL or an introspection result in JSON")
+	operations := fs.String("operations", "", "`path` of an operation document or a directory of documents")
+	pkg := fs.String("package", "client", "name of the generated Go package")
+	out := fs.String("o", "", "output `file` (default stdout)")
+	descriptions := fs.Bool("string-descriptions", false, "parse string descriptions instead of comments")
+	fs.Parse(args)
+
+	s, err := loadSchema(*schemaFile, *descriptions)
+	if err != nil {
+		return err
+	}
+	if *operations == "" {
+		return fmt.Errorf("missing -operations flag")
+	}
+	ops, err := loadOperations(*operations)
+	if err != nil {
+		return err
+	}
+
+	src, err := GenerateClient(s, ops, *pkg)
+	if err != nil {
+		return err
+	}
+	return writeOutput(*out, src)
+}
+
+func runDescriptions(args []string) error {
+	fs := flag.NewFlagSet("descriptions", flag.ExitOnError)
+	schemaFile := fs.String("schema", "", "path of the GraphQL schema `file`")
+	dir := fs.String("dir", ".", "`directory` of the Go package of the resolvers")
+	pkg := fs.String("package", "", "name of the generated Go package (default the package in -dir)")
+	varName := fs.String("var", "schemaDescriptions", "`name` of the generated variable")
+	out := fs.String("o", "", "output `file` (default stdout)")
+	descriptions := fs.Bool("string-descriptions", false, "parse string descriptions instead of comments")
+	fs.Parse(args)
+
+	s, err := loadSchema(*schemaFile, *descriptions)
+	if err != nil {
+		return err
+	}
+	pkgName, files, err := parseGoPackage(*dir, *out)
+	if err != nil {
+		return err
+	}
+	if *pkg == "" {
+		*pkg = pkgName
+	}
+
+	src, err := GenerateDescriptions(s.Inspect(), files, *pkg, *varName)
+	if err != nil {
+		return err
+	}
+	return writeOutput(*out, src)
+}
+
+func runArtifacts(args []string) error {
+	fs := flag.NewFlagSet("artifacts", flag.ExitOnError)
+	schemaFile := fs.String("schema", "", "path of the GraphQL schema `file`")
+	resolvers := fs.String("resolvers", "", "`directory` of the Go package of the resolvers to take descriptions from")
+	dir := fs.String("dir", ".", "output `directory`")
+	typescript := fs.Bool("typescript", false, "also generate TypeScript declarations")
+	descriptions := fs.Bool("string-descriptions", false, "parse string descriptions instead of comments")
+	fs.Parse(args)
+
+	s, err := loadSchema(*schemaFile, *descriptions)
+	if err != nil {
+		return err
+	}
+	if *resolvers != "" {
+		_, files, err := parseGoPackage(*resolvers, "")
+		if err != nil {
+			return err
+		}
+		if s, err = loadSchema(*schemaFile, *descriptions, graphql.Descriptions(collectDescriptions(s.Inspect(), files))); err != nil {
+			return err
+		}
+	}
+
+	artifacts, err := GenerateArtifacts(s, *typescript)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(*dir, 0755); err != nil {
+		return err
+	}
+	for _, name := range []string{"schema.graphql", "schema.json", "schema.ts"} {
+		if src, ok := artifacts[name]; ok {
+			if err := writeOutput(filepath.Join(*dir, name), src); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// GenerateArtifacts returns the files to publish to client teams by their names: the SDL of the
+// schema, the result of the introspection query and, if typescript is set, TypeScript declarations.
+func GenerateArtifacts(s *graphql.Schema, typescript bool) (map[string][]byte, error) {
+	introspection, err := s.ToJSON()
+	if err != nil {
+		return nil, err
+	}
+	artifacts := map[string][]byte{
+		"schema.graphql": []byte(s.SDL()),
+		"schema.json":    append(introspection, '\n'),
+	}
+	if typescript {
+		artifacts["schema.ts"] = GenerateTypeScript(s.Inspect())
+	}
+	return artifacts, nil
+}
+
+// parseGoPackage parses the Go files in dir with their comments, except for tests and the output
+// file.
+func parseGoPackage(dir, out string) (string, []*ast.File, error) {
+	filter := func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go") && (out == "" || info.Name() != filepath.Base(out))
+	}
+	pkgs, err := parser.ParseDir(token.NewFileSet(), dir, filter, parser.ParseComments)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(pkgs) != 1 {
+		return "", nil, fmt.Errorf("%s: expected one Go package, found %d", dir, len(pkgs))
+	}
+	for name, p := range pkgs {
+		names := make([]string, 0, len(p.Files))
+		for file := range p.Files {
+			names = append(names, file)
+		}
+		sort.Strings(names)
+		files := make([]*ast.File, len(names))
+		for i, file := range names {
+			files[i] = p.Files[file]
+		}
+		return name, files, nil
+	}
+	return "", nil, nil
+}
+
+// loadSchema parses the schema in file. Files with a ".json" extension are expected to contain the
+// result of an introspection query.
+func loadSchema(file string, useStringDescriptions bool, opts ...graphql.SchemaOpt) (*graphql.Schema, error) {
+	if file == "" {
+		return nil, fmt.Errorf("missing -schema flag")
+	}
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	sdl := string(b)
+	if strings.EqualFold(filepath.Ext(file), ".json") {
+		if sdl, err = introspectionToSDL(b); err != nil {
+			return nil, fmt.Errorf("%s: %s", file, err)
+		}
+		useStringDescriptions = true
+	}
+
+	if useStringDescriptions {
+		opts = append(opts, graphql.UseStringDescriptions())
+	}
+	return graphql.ParseSchema(sdl, nil, opts...)
+}
+
+// loadOperations reads the operation document at path, or all ".graphql" and ".gql" documents in
+// the directory at path.
+func loadOperations(path string) (Operations, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	ops := make(Operations)
+	if !info.IsDir() {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		ops[path] = string(b)
+		return ops, nil
+	}
+	files, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		ext := strings.ToLower(filepath.Ext(f.Name()))
+		if f.IsDir() || (ext != ".graphql" && ext != ".gql") {
+			continue
+		}
+		b, err := ioutil.ReadFile(filepath.Join(path, f.Name()))
+		if err != nil {
+			return nil, err
+		}
+		ops[f.Name()] = string(b)
+	}
+	return ops, nil
+}
+
+func writeOutput(file string, src []byte) error {
+	if file == "" {
+		_, err := os.Stdout.Write(src)
+		return err
+	}
+	return ioutil.WriteFile(file, src, 0644)
+}
+
+// goName converts a GraphQL name into an exported Go identifier.
+func goName(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if r == '_' {
+			upper = true
+			continue
+		}
+		if upper {
+			b.WriteString(strings.ToUpper(string(r)))
+			upper = false
+			continue
+		}
+		b.WriteRune(r)
+	}
+	s := b.String()
+	if initialism := strings.ToUpper(s); commonInitialisms[initialism] {
+		return initialism
+	}
+	return s
+}
+
+var commonInitialisms = map[string]bool{
+	"ID":   true,
+	"URL":  true,
+	"URI":  true,
+	"API":  true,
+	"HTML": true,
+	"JSON": true,
+}
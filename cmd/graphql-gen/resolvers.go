@@ -0,0 +1,285 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+
+	"github.com/graph-gophers/graphql-go/introspection"
+)
+
+const graphqlPackage = "github.com/graph-gophers/graphql-go"
+
+var includeDeprecated = &struct{ IncludeDeprecated bool }{true}
+
+// builtinScalars maps the predeclared GraphQL scalars to their Go types.
+var builtinScalars = map[string]string{
+	"Int":     "int32",
+	"Float":   "float64",
+	"String":  "string",
+	"Boolean": "bool",
+	"ID":      "graphql.ID",
+	"Time":    "graphql.Time",
+}
+
+type resolverGen struct {
+	schema *introspection.Schema
+	buf    bytes.Buffer
+	types  []*introspection.Type
+
+	subscriptionType string
+	imports          map[string]bool
+}
+
+// GenerateResolvers generates the Go source of the resolver interfaces for the given schema.
+func GenerateResolvers(s *introspection.Schema, pkg string) ([]byte, error) {
+	g := &resolverGen{
+		schema:  s,
+		imports: make(map[string]bool),
+	}
+	if t := s.SubscriptionType(); t != nil {
+		g.subscriptionType = *t.Name()
+	}
+	for _, t := range s.Types() {
+		if !strings.HasPrefix(*t.Name(), "__") {
+			g.types = append(g.types, t)
+		}
+	}
+	sort.Slice(g.types, func(i, j int) bool { return *g.types[i].Name() < *g.types[j].Name() })
+
+	var body bytes.Buffer
+	for _, t := range g.types {
+		g.buf.Reset()
+		g.typeDecl(t)
+		body.Write(g.buf.Bytes())
+	}
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by graphql-gen. DO NOT EDIT.\n\npackage %s\n\n", pkg)
+	writeImports(&src, g.imports)
+	src.Write(body.Bytes())
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %s", err)
+	}
+	return formatted, nil
+}
+
+func writeImports(src *bytes.Buffer, imports map[string]bool) {
+	if len(imports) == 0 {
+		return
+	}
+	var paths []string
+	for p := range imports {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	src.WriteString("import (\n")
+	for i, p := range paths {
+		// Separate the standard library imports from the other packages.
+		if i > 0 && !strings.Contains(paths[i-1], ".") && strings.Contains(p, ".") {
+			src.WriteString("\n")
+		}
+		if p == graphqlPackage {
+			fmt.Fprintf(src, "\tgraphql %q\n", p)
+			continue
+		}
+		fmt.Fprintf(src, "\t%q\n", p)
+	}
+	src.WriteString(")\n\n")
+}
+
+func (g *resolverGen) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *resolverGen) comment(name string, desc *string, fallback string) {
+	if desc == nil {
+		g.printf("// %s %s\n", name, fallback)
+		return
+	}
+	for _, line := range strings.Split(*desc, "\n") {
+		g.printf("// %s\n", strings.TrimRight(line, " "))
+	}
+}
+
+func (g *resolverGen) typeDecl(t *introspection.Type) {
+	name := *t.Name()
+	switch t.Kind() {
+	case "OBJECT", "INTERFACE", "UNION":
+		g.resolverInterface(t)
+	case "ENUM":
+		g.enum(t)
+	case "INPUT_OBJECT":
+		g.inputStruct(t)
+	case "SCALAR":
+		if _, ok := builtinScalars[name]; !ok {
+			g.scalar(t)
+		}
+	}
+}
+
+func (g *resolverGen) resolverInterface(t *introspection.Type) {
+	name := *t.Name()
+	g.comment(name+"Resolver", t.Description(), fmt.Sprintf("resolves the %s %s type.", name, strings.ToLower(t.Kind())))
+	g.printf("type %sResolver interface {\n", name)
+
+	var argStructs []*introspection.Field
+	if fields := t.Fields(includeDeprecated); fields != nil {
+		for _, f := range *fields {
+			if f.Description() != nil {
+				g.comment(goName(f.Name()), f.Description(), "")
+			}
+			retType := g.outputType(f.Type())
+			if name == g.subscriptionType {
+				retType = "<-chan " + retType
+			}
+			g.imports["context"] = true
+			if len(f.Args()) == 0 {
+				g.printf("%s(ctx context.Context) (%s, error)\n", goName(f.Name()), retType)
+				continue
+			}
+			argStructs = append(argStructs, f)
+			g.printf("%s(ctx context.Context, args %s) (%s, error)\n", goName(f.Name()), argsName(name, f), retType)
+		}
+	}
+	if possible := t.PossibleTypes(); possible != nil {
+		for _, pt := range *possible {
+			g.printf("To%s() (%sResolver, bool)\n", *pt.Name(), *pt.Name())
+		}
+	}
+	g.printf("}\n\n")
+
+	for _, f := range argStructs {
+		g.printf("// %s are the arguments of %s.%s.\n", argsName(name, f), name, f.Name())
+		g.printf("type %s struct {\n", argsName(name, f))
+		for _, arg := range f.Args() {
+			g.inputField(arg)
+		}
+		g.printf("}\n\n")
+	}
+}
+
+func argsName(typeName string, f *introspection.Field) string {
+	return typeName + goName(f.Name()) + "Args"
+}
+
+func (g *resolverGen) inputStruct(t *introspection.Type) {
+	name := *t.Name()
+	g.comment(name, t.Description(), "is the "+name+" input object.")
+	g.printf("type %s struct {\n", name)
+	for _, v := range *t.InputFields() {
+		g.inputField(v)
+	}
+	g.printf("}\n\n")
+}
+
+func (g *resolverGen) inputField(v *introspection.InputValue) {
+	if v.Description() != nil {
+		g.comment(goName(v.Name()), v.Description(), "")
+	}
+	typ := v.Type()
+	// Input values with a default value are never null, see packer.MakeStructPacker.
+	if v.DefaultValue() != nil && typ.Kind() != "NON_NULL" {
+		g.printf("%s %s\n", goName(v.Name()), g.inputType(typ, true))
+		return
+	}
+	g.printf("%s %s\n", goName(v.Name()), g.inputType(typ, false))
+}
+
+func (g *resolverGen) enum(t *introspection.Type) {
+	name := *t.Name()
+	g.comment(name, t.Description(), "is the "+name+" enum.")
+	g.printf("type %s string\n\n", name)
+	g.printf("// Values of the %s enum.\n", name)
+	g.printf("const (\n")
+	for _, v := range *t.EnumValues(includeDeprecated) {
+		g.printf("%s%s %s = %q\n", name, enumValueName(v.Name()), name, v.Name())
+	}
+	g.printf(")\n\n")
+}
+
+func enumValueName(value string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(strings.ToLower(value), "_") {
+		if part == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+func (g *resolverGen) scalar(t *introspection.Type) {
+	name := *t.Name()
+	g.imports["encoding/json"] = true
+	g.comment(name, t.Description(), "is the Go representation of the "+name+" scalar.")
+	g.printf("type %s struct {\n\tValue interface{}\n}\n\n", name)
+	g.printf("// ImplementsGraphQLType maps %s to the %s scalar.\n", name, name)
+	g.printf("func (%s) ImplementsGraphQLType(name string) bool { return name == %q }\n\n", name, name)
+	g.printf("// UnmarshalGraphQL stores the input value of the %s scalar.\n", name)
+	g.printf("func (s *%s) UnmarshalGraphQL(input interface{}) error {\n\ts.Value = input\n\treturn nil\n}\n\n", name)
+	g.printf("// MarshalJSON encodes the value of the %s scalar.\n", name)
+	g.printf("func (s %s) MarshalJSON() ([]byte, error) { return json.Marshal(s.Value) }\n\n", name)
+}
+
+// outputType returns the Go type of a resolver result. Nullable values are pointers, objects are
+// resolved by their resolver interface.
+func (g *resolverGen) outputType(t *introspection.Type) string {
+	nonNull := false
+	if t.Kind() == "NON_NULL" {
+		nonNull = true
+		t = t.OfType()
+	}
+	var typ string
+	switch t.Kind() {
+	case "LIST":
+		typ = "[]" + g.outputType(t.OfType())
+	case "OBJECT", "INTERFACE", "UNION":
+		return *t.Name() + "Resolver"
+	default:
+		typ = g.namedType(t)
+	}
+	if !nonNull {
+		typ = "*" + typ
+	}
+	return typ
+}
+
+// inputType returns the Go type of an argument or input field.
+func (g *resolverGen) inputType(t *introspection.Type, nonNull bool) string {
+	if t.Kind() == "NON_NULL" {
+		nonNull = true
+		t = t.OfType()
+	}
+	var typ string
+	switch t.Kind() {
+	case "LIST":
+		typ = "[]" + g.inputType(t.OfType(), false)
+	case "INPUT_OBJECT":
+		// The packer keeps the pointer of nullable input objects.
+		if !nonNull {
+			return "*" + *t.Name()
+		}
+		return *t.Name()
+	default:
+		typ = g.namedType(t)
+	}
+	if !nonNull {
+		typ = "*" + typ
+	}
+	return typ
+}
+
+func (g *resolverGen) namedType(t *introspection.Type) string {
+	if typ, ok := builtinScalars[*t.Name()]; ok {
+		if strings.HasPrefix(typ, "graphql.") {
+			g.imports[graphqlPackage] = true
+		}
+		return typ
+	}
+	return *t.Name()
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/example/starwars"
+)
+
+func TestGenerateResolvers(t *testing.T) {
+	s := graphql.MustParseSchema(starwars.Schema, nil)
+	src, err := GenerateResolvers(s.Inspect(), "starwars")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"package starwars",
+		"type QueryResolver interface {",
+		"Hero(ctx context.Context, args QueryHeroArgs) (CharacterResolver, error)",
+		"Friends(ctx context.Context) (*[]CharacterResolver, error)",
+		"ToHuman() (HumanResolver, bool)",
+		"type QueryHeroArgs struct {\n\tEpisode Episode\n}",
+		"type HumanHeightArgs struct {\n\tUnit LengthUnit\n}",
+		"EpisodeNewhope Episode = \"NEWHOPE\"",
+		"type ReviewInput struct {",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("generated code does not contain %q", want)
+		}
+	}
+}
+
+func TestGoName(t *testing.T) {
+	for in, want := range map[string]string{
+		"id":               "ID",
+		"appearsIn":        "AppearsIn",
+		"primary_function": "PrimaryFunction",
+		"url":              "URL",
+	} {
+		if got := goName(in); got != want {
+			t.Errorf("goName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
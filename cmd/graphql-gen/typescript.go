@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/graph-gophers/graphql-go/introspection"
+)
+
+// tsScalars maps the predeclared GraphQL scalars to their TypeScript types.
+var tsScalars = map[string]string{
+	"Int":     "number",
+	"Float":   "number",
+	"String":  "string",
+	"Boolean": "boolean",
+	"ID":      "string",
+	"Time":    "string",
+}
+
+type tsGen struct {
+	buf bytes.Buffer
+}
+
+// GenerateTypeScript generates TypeScript declarations for the types of the given schema: a type
+// alias for every custom scalar and enum, an interface for every object, interface and input
+// object, a union type for every union and an interface for the arguments of every field with
+// arguments. Nullable fields may be null, nullable input fields and arguments may be omitted.
+func GenerateTypeScript(s *introspection.Schema) []byte {
+	var types []*introspection.Type
+	for _, t := range s.Types() {
+		if !strings.HasPrefix(*t.Name(), "__") {
+			types = append(types, t)
+		}
+	}
+	sort.Slice(types, func(i, j int) bool { return *types[i].Name() < *types[j].Name() })
+
+	g := &tsGen{}
+	g.printf("// Code generated by graphql-gen. DO NOT EDIT.\n")
+	for _, t := range types {
+		g.typeDecl(t)
+	}
+	return g.buf.Bytes()
+}
+
+func (g *tsGen) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *tsGen) comment(indent string, desc *string, deprecation *string) {
+	var lines []string
+	if desc != nil && *desc != "" {
+		lines = strings.Split(*desc, "\n")
+	}
+	if deprecation != nil {
+		lines = append(lines, "@deprecated "+*deprecation)
+	}
+	if len(lines) == 0 {
+		return
+	}
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(strings.Replace(line, "*/", "*\\/", -1), " ")
+	}
+	if len(lines) == 1 {
+		g.printf("%s/** %s */\n", indent, lines[0])
+		return
+	}
+	g.printf("%s/**\n", indent)
+	for _, line := range lines {
+		g.printf("%s * %s\n", indent, line)
+	}
+	g.printf("%s */\n", indent)
+}
+
+func (g *tsGen) typeDecl(t *introspection.Type) {
+	name := *t.Name()
+	switch t.Kind() {
+	case "SCALAR":
+		if _, ok := tsScalars[name]; ok {
+			return
+		}
+		g.printf("\n")
+		g.comment("", t.Description(), nil)
+		g.printf("export type %s = unknown;\n", name)
+
+	case "ENUM":
+		g.printf("\n")
+		g.comment("", t.Description(), nil)
+		var values []string
+		for _, v := range *t.EnumValues(includeDeprecated) {
+			values = append(values, fmt.Sprintf("%q", v.Name()))
+		}
+		g.printf("export type %s = %s;\n", name, strings.Join(values, " | "))
+
+	case "UNION":
+		g.printf("\n")
+		g.comment("", t.Description(), nil)
+		var members []string
+		for _, pt := range *t.PossibleTypes() {
+			members = append(members, *pt.Name())
+		}
+		g.printf("export type %s = %s;\n", name, strings.Join(members, " | "))
+
+	case "OBJECT", "INTERFACE":
+		g.printf("\n")
+		g.comment("", t.Description(), nil)
+		g.printf("export interface %s {\n", name)
+		if t.Kind() == "OBJECT" {
+			g.printf("  __typename?: %q;\n", name)
+		}
+		var withArgs []*introspection.Field
+		for _, f := range *t.Fields(includeDeprecated) {
+			g.comment("  ", f.Description(), f.DeprecationReason())
+			g.printf("  %s: %s;\n", f.Name(), g.outputType(f.Type()))
+			if len(f.Args()) != 0 {
+				withArgs = append(withArgs, f)
+			}
+		}
+		g.printf("}\n")
+		for _, f := range withArgs {
+			g.printf("\n/** The arguments of %s.%s. */\n", name, f.Name())
+			g.printf("export interface %s {\n", argsName(name, f))
+			for _, arg := range f.Args() {
+				g.inputValue(arg)
+			}
+			g.printf("}\n")
+		}
+
+	case "INPUT_OBJECT":
+		g.printf("\n")
+		g.comment("", t.Description(), nil)
+		g.printf("export interface %s {\n", name)
+		for _, v := range *t.InputFields() {
+			g.inputValue(v)
+		}
+		g.printf("}\n")
+	}
+}
+
+func (g *tsGen) inputValue(v *introspection.InputValue) {
+	g.comment("  ", v.Description(), nil)
+	if v.Type().Kind() == "NON_NULL" && v.DefaultValue() == nil {
+		g.printf("  %s: %s;\n", v.Name(), g.outputType(v.Type()))
+		return
+	}
+	g.printf("  %s?: %s;\n", v.Name(), g.outputType(v.Type()))
+}
+
+// outputType returns the TypeScript type of a value of the GraphQL type.
+func (g *tsGen) outputType(t *introspection.Type) string {
+	if t.Kind() == "NON_NULL" {
+		return g.nonNullType(t.OfType())
+	}
+	return g.nonNullType(t) + " | null"
+}
+
+func (g *tsGen) nonNullType(t *introspection.Type) string {
+	if t.Kind() == "LIST" {
+		return "Array<" + g.outputType(t.OfType()) + ">"
+	}
+	if typ, ok := tsScalars[*t.Name()]; ok {
+		return typ
+	}
+	return *t.Name()
+}
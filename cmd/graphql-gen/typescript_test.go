@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/example/starwars"
+)
+
+func TestGenerateTypeScript(t *testing.T) {
+	s := graphql.MustParseSchema(starwars.Schema, nil)
+	src := string(GenerateTypeScript(s.Inspect()))
+
+	for _, want := range []string{
+		"export interface Human {\n  __typename?: \"Human\";\n  /** The ID of the human */\n  id: string;",
+		"  friends: Array<Character | null> | null;",
+		"  appearsIn: Array<Episode>;",
+		"export interface QueryHeroArgs {\n  episode?: Episode | null;\n}",
+		"export type Episode = \"NEWHOPE\" | \"EMPIRE\" | \"JEDI\";",
+		"export type SearchResult = Human | Droid | Starship;",
+		"export interface ReviewInput {\n  /** 0-5 stars */\n  stars: number;\n  /** Comment about the movie, optional */\n  commentary?: string | null;\n}",
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("generated code does not contain %q", want)
+		}
+	}
+}
+
+func TestGenerateArtifacts(t *testing.T) {
+	s := graphql.MustParseSchema(starwars.Schema, nil)
+	artifacts, err := GenerateArtifacts(s, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := artifacts["schema.ts"]; ok {
+		t.Error("unexpected TypeScript artifact")
+	}
+	if string(artifacts["schema.graphql"]) != s.SDL() {
+		t.Errorf("unexpected SDL artifact:\n%s", artifacts["schema.graphql"])
+	}
+	var result introspectionResult
+	if err := json.Unmarshal(artifacts["schema.json"], &result); err != nil {
+		t.Fatal(err)
+	}
+	if name := result.Schema.QueryType.Name; name == nil || *name != "Query" {
+		t.Errorf("unexpected introspection artifact:\n%s", artifacts["schema.json"])
+	}
+}
@@ -0,0 +1,93 @@
+// Command graphql-lint checks a GraphQL schema against the rules of the lint package.
+//
+// Usage:
+//
+//	graphql-lint [-enable rule,...] [-disable rule,...] schema.graphql
+//
+// The command exits with status 1 if any problems are found. Use -list to print all rules.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/lint"
+)
+
+func main() {
+	enable := flag.String("enable", "", "comma separated `rules` to enable")
+	disable := flag.String("disable", "", "comma separated `rules` to disable")
+	list := flag.Bool("list", false, "list all rules and exit")
+	descriptions := flag.Bool("string-descriptions", false, "parse string descriptions instead of comments")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: graphql-lint [flags] schema.graphql\n\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *list {
+		for _, r := range lint.Rules {
+			state := "disabled"
+			if r.Default {
+				state = "enabled"
+			}
+			fmt.Printf("%-28s %-9s %s\n", r.Name, state, r.Description)
+		}
+		return
+	}
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg := &lint.Config{Rules: make(map[string]bool)}
+	for _, name := range splitList(*enable) {
+		cfg.Rules[name] = true
+	}
+	for _, name := range splitList(*disable) {
+		cfg.Rules[name] = false
+	}
+
+	problems, err := run(flag.Arg(0), cfg, *descriptions)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "graphql-lint: %s\n", err)
+		os.Exit(2)
+	}
+	for _, p := range problems {
+		fmt.Printf("%s: %s\n", flag.Arg(0), p)
+	}
+	if len(problems) != 0 {
+		os.Exit(1)
+	}
+}
+
+func run(file string, cfg *lint.Config, useStringDescriptions bool) ([]*lint.Problem, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var opts []graphql.SchemaOpt
+	if useStringDescriptions {
+		opts = append(opts, graphql.UseStringDescriptions())
+	}
+	s, err := graphql.ParseSchema(string(b), nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return lint.Lint(s, cfg)
+}
+
+func splitList(s string) []string {
+	var l []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			l = append(l, item)
+		}
+	}
+	return l
+}
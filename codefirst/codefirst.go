@@ -0,0 +1,450 @@
+// Package codefirst derives a GraphQL schema from Go resolvers, as an alternative to writing the
+// schema definition first. Object types, fields, arguments, input objects and nullability are
+// derived from the Go types by reflection, and can be adjusted with struct tags of the form
+// `graphql:"name,nonnull,desc=The description."`.
+package codefirst
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// Roots are the resolvers of the root operation types. Query is required.
+type Roots struct {
+	Query        interface{}
+	Mutation     interface{}
+	Subscription interface{}
+
+	// QueryName, MutationName and SubscriptionName name the root operation types. They default to
+	// "Query", "Mutation" and "Subscription"; other names are declared by a schema definition.
+	QueryName        string
+	MutationName     string
+	SubscriptionName string
+
+	// FieldNames maps the names of methods and struct fields to field names. It defaults to
+	// graphql.CamelCaseNames, and is passed to graphql.FieldNameMapping by ParseSchema if set.
+	FieldNames func(goName string) string
+}
+
+// ParseSchema derives the schema definition of the resolvers with SDL and parses it with the
+// resolvers merged into the root resolver. String descriptions and field resolvers are enabled,
+// in addition to opts.
+func ParseSchema(roots Roots, opts ...graphql.SchemaOpt) (*graphql.Schema, error) {
+	sdl, err := SDL(roots)
+	if err != nil {
+		return nil, err
+	}
+	var resolvers []interface{}
+	for _, r := range []interface{}{roots.Query, roots.Mutation, roots.Subscription} {
+		if r != nil {
+			resolvers = append(resolvers, r)
+		}
+	}
+	opts = append([]graphql.SchemaOpt{graphql.UseStringDescriptions(), graphql.UseFieldResolvers()}, opts...)
+	if roots.FieldNames != nil {
+		opts = append(opts, graphql.FieldNameMapping(roots.FieldNames))
+	}
+	return graphql.ParseSchema(sdl, graphql.MergeResolvers(resolvers...), opts...)
+}
+
+// MustParseSchema calls ParseSchema and panics on error.
+func MustParseSchema(roots Roots, opts ...graphql.SchemaOpt) *graphql.Schema {
+	s, err := ParseSchema(roots, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// SDL derives the schema definition of the resolvers:
+//
+// The exported methods and struct fields of a resolver are the fields of its object type, which is
+// named after the Go type without a "Resolver" suffix. Methods may take a context.Context and a
+// struct whose fields are the arguments of the field, and may return an error as second result.
+// The fields of subscriptions return channels.
+//
+// Pointers are nullable and other types are non-null, e.g. *[]*T is a nullable list of nullable T.
+// The Go types string, bool, int32, float64, graphql.ID and graphql.Time map to the scalars of the
+// same name; int also maps to Int for results. Structs used as arguments are input objects.
+//
+// The "graphql" tag of a struct field overrides its name, marks it as non-null with "nonnull" and
+// describes it with "desc=", which must be the last option. Fields tagged with "-" are omitted.
+func SDL(roots Roots) (string, error) {
+	if roots.Query == nil {
+		return "", fmt.Errorf("codefirst: missing query resolver")
+	}
+	b := &builder{
+		types:     make(map[string]*typeDef),
+		goType:    make(map[reflect.Type]string),
+		fieldName: roots.FieldNames,
+	}
+	if b.fieldName == nil {
+		b.fieldName = graphql.CamelCaseNames
+	}
+	for _, root := range []struct {
+		operation string
+		name      string
+		resolver  interface{}
+	}{
+		{"query", roots.QueryName, roots.Query},
+		{"mutation", roots.MutationName, roots.Mutation},
+		{"subscription", roots.SubscriptionName, roots.Subscription},
+	} {
+		if root.resolver == nil {
+			continue
+		}
+		if root.name == "" {
+			root.name = defaultRootNames[root.operation]
+		} else if root.name != defaultRootNames[root.operation] {
+			b.customRoots = true
+		}
+		if _, ok := b.types[root.name]; ok {
+			return "", fmt.Errorf("codefirst: root operation types must have distinct names, %q is used twice", root.name)
+		}
+		if err := b.object(root.name, reflect.TypeOf(root.resolver), root.operation == "subscription"); err != nil {
+			return "", fmt.Errorf("codefirst: %s", err)
+		}
+		b.roots = append(b.roots, root.operation+": "+root.name)
+	}
+	return b.sdl(), nil
+}
+
+var defaultRootNames = map[string]string{
+	"query":        "Query",
+	"mutation":     "Mutation",
+	"subscription": "Subscription",
+}
+
+type typeDef struct {
+	keyword string
+	fields  []*fieldDef
+}
+
+type fieldDef struct {
+	name string
+	typ  string
+	desc string
+	args []*fieldDef
+}
+
+type builder struct {
+	types     map[string]*typeDef
+	goType    map[reflect.Type]string
+	fieldName func(goName string) string
+	time      bool
+
+	// roots are the root operation types, declared by a schema definition if customRoots is set.
+	roots       []string
+	customRoots bool
+}
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+	idType      = reflect.TypeOf(graphql.ID(""))
+	timeType    = reflect.TypeOf(graphql.Time{})
+)
+
+// object adds the object type resolved by t.
+func (b *builder) object(name string, t reflect.Type, subscription bool) error {
+	def := &typeDef{keyword: "type"}
+	b.types[name] = def
+	b.goType[t] = name
+
+	seen := make(map[string]bool)
+	if st := unwrapPtr(t); st.Kind() == reflect.Struct {
+		if err := b.structFields(def, st, false, seen); err != nil {
+			return fmt.Errorf("%s: %s", t, err)
+		}
+	}
+	for i := 0; i < t.NumMethod(); i++ {
+		m := t.Method(i)
+		name := b.fieldName(m.Name)
+		if seen[strings.ToLower(name)] {
+			return fmt.Errorf("%s: method %s and a struct field both resolve %q", t, m.Name, name)
+		}
+		f, err := b.method(m, subscription)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %s", t, m.Name, err)
+		}
+		def.fields = append(def.fields, f)
+	}
+	if len(def.fields) == 0 {
+		return fmt.Errorf("%s has no exported methods or fields", t)
+	}
+	return nil
+}
+
+// structFields adds the exported fields of the struct, including those of embedded structs.
+func (b *builder) structFields(def *typeDef, t reflect.Type, input bool, seen map[string]bool) error {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.Anonymous && unwrapPtr(sf.Type).Kind() == reflect.Struct {
+			if err := b.structFields(def, unwrapPtr(sf.Type), input, seen); err != nil {
+				return err
+			}
+			continue
+		}
+		if sf.PkgPath != "" {
+			continue
+		}
+		tag := parseTag(sf, b.fieldName)
+		if tag.omit {
+			continue
+		}
+		typ, err := b.typeRef(sf.Type, input, tag.nonNull)
+		if err != nil {
+			return fmt.Errorf("field %s: %s", sf.Name, err)
+		}
+		seen[strings.ToLower(tag.name)] = true
+		def.fields = append(def.fields, &fieldDef{name: tag.name, typ: typ, desc: tag.desc})
+	}
+	return nil
+}
+
+// method returns the field resolved by the method.
+func (b *builder) method(m reflect.Method, subscription bool) (*fieldDef, error) {
+	f := &fieldDef{name: b.fieldName(m.Name)}
+
+	in := m.Type.NumIn()
+	i := 1 // receiver
+	if i < in && m.Type.In(i) == contextType {
+		i++
+	}
+	if i < in {
+		args := unwrapPtr(m.Type.In(i))
+		if args.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("arguments must be a struct, got %s", m.Type.In(i))
+		}
+		argDef := &typeDef{}
+		if err := b.structFields(argDef, args, true, make(map[string]bool)); err != nil {
+			return nil, err
+		}
+		f.args = argDef.fields
+		i++
+	}
+	if i < in {
+		return nil, fmt.Errorf("too many parameters")
+	}
+
+	out := m.Type.NumOut()
+	if out == 0 || out > 2 || out == 2 && m.Type.Out(1) != errorType {
+		return nil, fmt.Errorf("must return a value and optionally an error")
+	}
+	result := m.Type.Out(0)
+	if subscription {
+		if result.Kind() != reflect.Chan || result.ChanDir()&reflect.RecvDir == 0 {
+			return nil, fmt.Errorf("subscription fields must return a channel, got %s", result)
+		}
+		result = result.Elem()
+	}
+	typ, err := b.typeRef(result, false, false)
+	if err != nil {
+		return nil, err
+	}
+	f.typ = typ
+	return f, nil
+}
+
+// typeRef returns the GraphQL type of t, adding the named types it refers to.
+func (b *builder) typeRef(t reflect.Type, input bool, nonNull bool) (string, error) {
+	nullable := false
+	if t.Kind() == reflect.Ptr {
+		nullable = true
+		t = t.Elem()
+	}
+
+	var name string
+	switch {
+	case t == idType:
+		name = "ID"
+	case t == timeType:
+		name = "Time"
+		b.time = true
+	case t.Kind() == reflect.Slice:
+		elem, err := b.typeRef(t.Elem(), input, false)
+		if err != nil {
+			return "", err
+		}
+		name = "[" + elem + "]"
+	case t.Kind() == reflect.String:
+		name = "String"
+	case t.Kind() == reflect.Bool:
+		name = "Boolean"
+	case t.Kind() == reflect.Int32, t.Kind() == reflect.Int && !input:
+		name = "Int"
+	case t.Kind() == reflect.Float64:
+		name = "Float"
+	case t.Kind() == reflect.Struct:
+		var err error
+		if name, err = b.namedType(t, input); err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("unsupported type %s", t)
+	}
+
+	if nonNull || !nullable {
+		name += "!"
+	}
+	return name, nil
+}
+
+// namedType returns the name of the object or input object type of the struct, adding it if
+// necessary.
+func (b *builder) namedType(t reflect.Type, input bool) (string, error) {
+	for _, goType := range []reflect.Type{t, reflect.PtrTo(t)} {
+		if name, ok := b.goType[goType]; ok {
+			if (b.types[name].keyword == "input") != input {
+				return "", fmt.Errorf("%s is used both as an input and as a result", t)
+			}
+			return name, nil
+		}
+	}
+	if t.Name() == "" {
+		return "", fmt.Errorf("unsupported anonymous struct %s", t)
+	}
+
+	name := typeName(t.Name())
+	if _, ok := b.types[name]; ok {
+		return "", fmt.Errorf("%s and another Go type both map to type %q", t, name)
+	}
+	if !input {
+		// Methods with pointer receivers are only resolved through pointers.
+		return name, b.object(name, reflect.PtrTo(t), false)
+	}
+
+	def := &typeDef{keyword: "input"}
+	b.types[name] = def
+	b.goType[t] = name
+	if err := b.structFields(def, t, true, make(map[string]bool)); err != nil {
+		return "", fmt.Errorf("%s: %s", t, err)
+	}
+	return name, nil
+}
+
+func (b *builder) sdl() string {
+	names := make([]string, 0, len(b.types))
+	for name := range b.types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	if b.customRoots {
+		sb.WriteString("schema {\n")
+		for _, root := range b.roots {
+			sb.WriteString("\t" + root + "\n")
+		}
+		sb.WriteString("}\n\n")
+	}
+	if b.time {
+		sb.WriteString("scalar Time\n\n")
+	}
+	for _, name := range names {
+		def := b.types[name]
+		sb.WriteString(def.keyword + " " + name + " {\n")
+		for _, f := range def.fields {
+			writeDesc(&sb, "\t", f.desc)
+			sb.WriteString("\t" + f.name)
+			writeArgs(&sb, f.args)
+			sb.WriteString(": " + f.typ + "\n")
+		}
+		sb.WriteString("}\n\n")
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// writeArgs writes the arguments on one line, or one per line if any of them has a description.
+func writeArgs(sb *strings.Builder, args []*fieldDef) {
+	if len(args) == 0 {
+		return
+	}
+	multiline := false
+	for _, arg := range args {
+		if arg.desc != "" {
+			multiline = true
+		}
+	}
+	if !multiline {
+		sb.WriteString("(")
+		for i, arg := range args {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(arg.name + ": " + arg.typ)
+		}
+		sb.WriteString(")")
+		return
+	}
+	sb.WriteString("(\n")
+	for _, arg := range args {
+		writeDesc(sb, "\t\t", arg.desc)
+		sb.WriteString("\t\t" + arg.name + ": " + arg.typ + "\n")
+	}
+	sb.WriteString("\t)")
+}
+
+func writeDesc(sb *strings.Builder, indent, desc string) {
+	if desc != "" {
+		sb.WriteString(indent + strconv.Quote(desc) + "\n")
+	}
+}
+
+type tag struct {
+	name    string
+	nonNull bool
+	desc    string
+	omit    bool
+}
+
+// parseTag parses the "graphql" tag of a struct field.
+func parseTag(sf reflect.StructField, fieldName func(goName string) string) tag {
+	t := tag{name: fieldName(sf.Name)}
+	s, ok := sf.Tag.Lookup("graphql")
+	if !ok {
+		return t
+	}
+	if s == "-" {
+		t.omit = true
+		return t
+	}
+	for i, opt := range strings.Split(s, ",") {
+		switch {
+		case strings.HasPrefix(opt, "desc="):
+			t.desc = strings.TrimPrefix(s[strings.Index(s, "desc="):], "desc=")
+			return t
+		case i == 0:
+			if opt != "" {
+				t.name = opt
+			}
+		case opt == "nonnull":
+			t.nonNull = true
+		}
+	}
+	return t
+}
+
+// typeName converts a Go type name into a GraphQL type name, e.g. "userResolver" into "User".
+func typeName(name string) string {
+	if trimmed := strings.TrimSuffix(strings.TrimSuffix(name, "Resolver"), "resolver"); trimmed != "" {
+		name = trimmed
+	}
+	runes := []rune(name)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+func unwrapPtr(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		return t.Elem()
+	}
+	return t
+}
@@ -0,0 +1,184 @@
+package codefirst_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/codefirst"
+	"github.com/graph-gophers/graphql-go/gqltesting"
+)
+
+type user struct {
+	ID       graphql.ID
+	Username string `graphql:"login,desc=The login name, unique per shop."`
+	Email    *string
+	Tags     []string
+	Manager  *user `graphql:",nonnull"`
+	password string
+	Internal string `graphql:"-"`
+}
+
+func (u *user) FullName() string { return "Alice Liddell" }
+
+type Resolver struct {
+	users map[graphql.ID]*user
+}
+
+func (r *Resolver) User(args struct{ ID graphql.ID }) *user {
+	return r.users[args.ID]
+}
+
+func (r *Resolver) Users(ctx context.Context, args struct {
+	First int32   `graphql:",desc=The page size."`
+	After *string `graphql:"cursor"`
+}) (*[]*user, error) {
+	if args.After != nil && *args.After != "" {
+		return nil, nil
+	}
+	var users []*user
+	for _, u := range r.users {
+		users = append(users, u)
+	}
+	return &users, nil
+}
+
+type mutationResolver struct{}
+
+type UserInput struct {
+	Login string
+	Email *string
+}
+
+func (mutationResolver) CreateUser(args struct{ Input UserInput }) *user {
+	return &user{ID: "2", Username: args.Input.Login, Email: args.Input.Email}
+}
+
+const wantSDL = `type Mutation {
+	createUser(input: UserInput!): User
+}
+
+type Query {
+	user(id: ID!): User
+	users(
+		"The page size."
+		first: Int!
+		cursor: String
+	): [User]
+}
+
+type User {
+	id: ID!
+	"The login name, unique per shop."
+	login: String!
+	email: String
+	tags: [String!]!
+	manager: User!
+	fullName: String!
+}
+
+input UserInput {
+	login: String!
+	email: String
+}
+`
+
+func TestSDL(t *testing.T) {
+	sdl, err := codefirst.SDL(codefirst.Roots{Query: &Resolver{}, Mutation: mutationResolver{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sdl != wantSDL {
+		t.Errorf("unexpected SDL:\n%s", sdl)
+	}
+}
+
+func TestParseSchema(t *testing.T) {
+	email := "alice@example.com"
+	schema := codefirst.MustParseSchema(codefirst.Roots{
+		Query: &Resolver{users: map[graphql.ID]*user{
+			"1": {ID: "1", Username: "alice", Email: &email, Tags: []string{"admin"}, password: "secret"},
+		}},
+		Mutation: mutationResolver{},
+	})
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: schema,
+			Query: `
+				{
+					user(id: "1") { id login email tags fullName }
+					users(first: 10) { login }
+					next: users(first: 10, cursor: "1") { login }
+				}
+			`,
+			ExpectedResult: `
+				{
+					"user": { "id": "1", "login": "alice", "email": "alice@example.com", "tags": ["admin"], "fullName": "Alice Liddell" },
+					"users": [{ "login": "alice" }],
+					"next": null
+				}
+			`,
+		},
+		{
+			Schema: schema,
+			Query: `
+				mutation {
+					createUser(input: { login: "bob" }) { id login email }
+				}
+			`,
+			ExpectedResult: `
+				{
+					"createUser": { "id": "2", "login": "bob", "email": null }
+				}
+			`,
+		},
+	})
+}
+
+type badResolver struct{}
+
+func (badResolver) Ping(n int) string { return "" }
+
+func TestSDLErrors(t *testing.T) {
+	if _, err := codefirst.SDL(codefirst.Roots{}); err == nil || err.Error() != "codefirst: missing query resolver" {
+		t.Errorf("unexpected error %v", err)
+	}
+	_, err := codefirst.SDL(codefirst.Roots{Query: badResolver{}})
+	if want := "codefirst: codefirst_test.badResolver.Ping: arguments must be a struct, got int"; err == nil || err.Error() != want {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestCustomRootNames(t *testing.T) {
+	roots := codefirst.Roots{Query: &Resolver{}, Mutation: mutationResolver{}, QueryName: "RootQuery"}
+	sdl, err := codefirst.SDL(roots)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "schema {\n\tquery: RootQuery\n\tmutation: Mutation\n}\n\n"; !strings.HasPrefix(sdl, want) {
+		t.Errorf("unexpected SDL:\n%s", sdl)
+	}
+
+	gqltesting.RunTest(t, &gqltesting.Test{
+		Schema: codefirst.MustParseSchema(roots),
+		Query: `
+			{
+				__schema { queryType { name } mutationType { name } }
+				users(first: 1) { login }
+			}
+		`,
+		ExpectedResult: `
+			{
+				"__schema": { "queryType": { "name": "RootQuery" }, "mutationType": { "name": "Mutation" } },
+				"users": []
+			}
+		`,
+	})
+
+	roots.MutationName = "RootQuery"
+	if _, err := codefirst.SDL(roots); err == nil || err.Error() != `codefirst: root operation types must have distinct names, "RootQuery" is used twice` {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,193 @@
+package graphql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/graph-gophers/graphql-go/introspection"
+)
+
+// SchemaCoordinate identifies a member of a schema, as specified by the schema coordinates
+// proposal, e.g. "User", "User.friends", "User.friends(first:)", "@deprecated" or
+// "@deprecated(reason:)". It is used to refer to schema members in usage reports, deny-lists
+// and cost configurations.
+type SchemaCoordinate struct {
+	// Type is the name of the type, which is empty for directive coordinates.
+	Type string
+
+	// Member is the name of a field, an input field or an enum value of the type.
+	Member string
+
+	// Argument is the name of an argument of the field or the directive.
+	Argument string
+
+	// Directive is the name of the directive, without the "@".
+	Directive string
+}
+
+// ParseSchemaCoordinate parses a schema coordinate. It only checks the syntax, see
+// Schema.ResolveCoordinate to look up the coordinate in a schema.
+func ParseSchemaCoordinate(s string) (SchemaCoordinate, error) {
+	var c SchemaCoordinate
+	rest := s
+	if strings.HasPrefix(rest, "@") {
+		c.Directive, rest = coordinateName(rest[1:])
+		if c.Directive == "" {
+			return SchemaCoordinate{}, fmt.Errorf("invalid schema coordinate %q, expected a directive name", s)
+		}
+	} else {
+		c.Type, rest = coordinateName(rest)
+		if c.Type == "" {
+			return SchemaCoordinate{}, fmt.Errorf("invalid schema coordinate %q, expected a type name", s)
+		}
+		if strings.HasPrefix(rest, ".") {
+			c.Member, rest = coordinateName(rest[1:])
+			if c.Member == "" {
+				return SchemaCoordinate{}, fmt.Errorf("invalid schema coordinate %q, expected a field name", s)
+			}
+		}
+	}
+	if strings.HasPrefix(rest, "(") && (c.Directive != "" || c.Member != "") {
+		c.Argument, rest = coordinateName(rest[1:])
+		if c.Argument == "" || rest != ":)" {
+			return SchemaCoordinate{}, fmt.Errorf("invalid schema coordinate %q, expected an argument like %q", s, "(name:)")
+		}
+		rest = ""
+	}
+	if rest != "" {
+		return SchemaCoordinate{}, fmt.Errorf("invalid schema coordinate %q, unexpected %q", s, rest)
+	}
+	return c, nil
+}
+
+// MustParseSchemaCoordinate calls ParseSchemaCoordinate and panics on error.
+func MustParseSchemaCoordinate(s string) SchemaCoordinate {
+	c, err := ParseSchemaCoordinate(s)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// coordinateName splits s into the leading GraphQL name and the rest.
+func coordinateName(s string) (string, string) {
+	i := 0
+	for i < len(s) {
+		c := s[i]
+		if c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || i > 0 && '0' <= c && c <= '9' {
+			i++
+			continue
+		}
+		break
+	}
+	return s[:i], s[i:]
+}
+
+// String formats the coordinate, which is the inverse of ParseSchemaCoordinate.
+func (c SchemaCoordinate) String() string {
+	var b strings.Builder
+	if c.Directive != "" {
+		b.WriteString("@" + c.Directive)
+	} else {
+		b.WriteString(c.Type)
+		if c.Member != "" {
+			b.WriteString("." + c.Member)
+		}
+	}
+	if c.Argument != "" {
+		b.WriteString("(" + c.Argument + ":)")
+	}
+	return b.String()
+}
+
+// SchemaMember is the member of a schema a SchemaCoordinate refers to. Only the fields of the
+// member are set, e.g. Type, Field and Argument for "User.friends(first:)".
+type SchemaMember struct {
+	Type       *introspection.Type
+	Field      *introspection.Field
+	InputField *introspection.InputValue
+	EnumValue  *introspection.EnumValue
+	Directive  *introspection.Directive
+	Argument   *introspection.InputValue
+}
+
+// ResolveCoordinate looks up the member of the schema a coordinate refers to. It returns an error
+// if the schema has no such member.
+func (s *Schema) ResolveCoordinate(c SchemaCoordinate) (*SchemaMember, error) {
+	inspected := s.Inspect()
+	m := &SchemaMember{}
+
+	if c.Directive != "" {
+		for _, d := range inspected.Directives() {
+			if d.Name() == c.Directive {
+				m.Directive = d
+			}
+		}
+		if m.Directive == nil {
+			return nil, fmt.Errorf("directive %q is not defined", "@"+c.Directive)
+		}
+		if err := resolveArgument(m, c, m.Directive.Args()); err != nil {
+			return nil, err
+		}
+		return m, nil
+	}
+
+	for _, t := range inspected.Types() {
+		if *t.Name() == c.Type {
+			m.Type = t
+		}
+	}
+	if m.Type == nil {
+		return nil, fmt.Errorf("type %q is not defined", c.Type)
+	}
+	if c.Member == "" {
+		return m, nil
+	}
+
+	includeDeprecated := &struct{ IncludeDeprecated bool }{true}
+	if fields := m.Type.Fields(includeDeprecated); fields != nil {
+		for _, f := range *fields {
+			if f.Name() == c.Member {
+				m.Field = f
+			}
+		}
+		if m.Field != nil {
+			if err := resolveArgument(m, c, m.Field.Args()); err != nil {
+				return nil, err
+			}
+			return m, nil
+		}
+	} else if inputFields := m.Type.InputFields(); inputFields != nil {
+		for _, f := range *inputFields {
+			if f.Name() == c.Member {
+				m.InputField = f
+			}
+		}
+	} else if enumValues := m.Type.EnumValues(includeDeprecated); enumValues != nil {
+		for _, v := range *enumValues {
+			if v.Name() == c.Member {
+				m.EnumValue = v
+			}
+		}
+	}
+	if m.InputField == nil && m.EnumValue == nil {
+		return nil, fmt.Errorf("%q is not defined on type %q", c.Member, c.Type)
+	}
+	if c.Argument != "" {
+		return nil, fmt.Errorf("%q of type %q has no arguments", c.Member, c.Type)
+	}
+	return m, nil
+}
+
+func resolveArgument(m *SchemaMember, c SchemaCoordinate, args []*introspection.InputValue) error {
+	if c.Argument == "" {
+		return nil
+	}
+	for _, arg := range args {
+		if arg.Name() == c.Argument {
+			m.Argument = arg
+			return nil
+		}
+	}
+	return fmt.Errorf("argument %q of %q is not defined", c.Argument, SchemaCoordinate{Type: c.Type, Member: c.Member, Directive: c.Directive})
+}
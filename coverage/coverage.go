@@ -0,0 +1,229 @@
+// Package coverage validates client operations against a schema and reports which fields of the
+// schema are used by them. Fields that are never used are candidates for a safe deprecation.
+package coverage
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/internal/query"
+	"github.com/graph-gophers/graphql-go/introspection"
+)
+
+// Extensions are the file extensions of the operation documents loaded by AnalyzeDir.
+var Extensions = []string{".graphql", ".gql"}
+
+// Document is a single client operation document that was analyzed.
+type Document struct {
+	Name   string
+	Errors []*errors.QueryError
+}
+
+// Report is the result of analyzing a set of client operation documents.
+type Report struct {
+	Documents []*Document
+
+	// FieldUsage maps every field of the schema, formatted as "Type.field", to the number of times
+	// it is selected by the documents. Introspection types are not included.
+	FieldUsage map[string]int
+}
+
+// Valid reports whether all documents are valid against the schema.
+func (r *Report) Valid() bool {
+	for _, d := range r.Documents {
+		if len(d.Errors) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Used returns the sorted list of fields that are selected by at least one document.
+func (r *Report) Used() []string {
+	return r.fields(func(n int) bool { return n > 0 })
+}
+
+// Unused returns the sorted list of fields that are not selected by any document.
+func (r *Report) Unused() []string {
+	return r.fields(func(n int) bool { return n == 0 })
+}
+
+func (r *Report) fields(match func(int) bool) []string {
+	var l []string
+	for f, n := range r.FieldUsage {
+		if match(n) {
+			l = append(l, f)
+		}
+	}
+	sort.Strings(l)
+	return l
+}
+
+// AnalyzeDir analyzes all operation documents found in dir and its sub-directories.
+func AnalyzeDir(s *graphql.Schema, dir string) (*Report, error) {
+	docs := make(map[string]string)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !hasExtension(path) {
+			return nil
+		}
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		docs[path] = string(b)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return Analyze(s, docs), nil
+}
+
+func hasExtension(path string) bool {
+	for _, ext := range Extensions {
+		if strings.EqualFold(filepath.Ext(path), ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// Analyze validates the given documents, keyed by name, and collects the schema fields they use.
+// Invalid documents are reported with their errors and do not count towards the field usage.
+func Analyze(s *graphql.Schema, docs map[string]string) *Report {
+	a := newAnalyzer(s.Inspect())
+
+	names := make([]string, 0, len(docs))
+	for name := range docs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	r := &Report{FieldUsage: a.usage}
+	for _, name := range names {
+		d := &Document{Name: name, Errors: s.Validate(docs[name])}
+		r.Documents = append(r.Documents, d)
+		if len(d.Errors) != 0 {
+			continue
+		}
+		doc, err := query.Parse(docs[name])
+		if err != nil {
+			d.Errors = []*errors.QueryError{err}
+			continue
+		}
+		a.document(doc)
+	}
+	return r
+}
+
+type analyzer struct {
+	schema *introspection.Schema
+	types  map[string]*introspection.Type
+	usage  map[string]int
+}
+
+func newAnalyzer(s *introspection.Schema) *analyzer {
+	a := &analyzer{
+		schema: s,
+		types:  make(map[string]*introspection.Type),
+		usage:  make(map[string]int),
+	}
+	for _, t := range s.Types() {
+		name := *t.Name()
+		a.types[name] = t
+		if strings.HasPrefix(name, "__") {
+			continue
+		}
+		if fields := t.Fields(&struct{ IncludeDeprecated bool }{true}); fields != nil {
+			for _, f := range *fields {
+				a.usage[name+"."+f.Name()] = 0
+			}
+		}
+	}
+	return a
+}
+
+func (a *analyzer) document(doc *query.Document) {
+	for _, op := range doc.Operations {
+		var root *introspection.Type
+		switch op.Type {
+		case query.Query:
+			root = a.schema.QueryType()
+		case query.Mutation:
+			root = a.schema.MutationType()
+		case query.Subscription:
+			root = a.schema.SubscriptionType()
+		}
+		if root == nil {
+			continue
+		}
+		a.selections(doc, op.Selections, root, make(map[string]bool))
+	}
+}
+
+func (a *analyzer) selections(doc *query.Document, sels []query.Selection, t *introspection.Type, visited map[string]bool) {
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *query.Field:
+			name := sel.Name.Name
+			if strings.HasPrefix(name, "__") {
+				continue
+			}
+			f := field(t, name)
+			if f == nil {
+				continue
+			}
+			a.usage[*t.Name()+"."+name]++
+			if sel.Selections != nil {
+				a.selections(doc, sel.Selections, a.types[*unwrap(f.Type()).Name()], visited)
+			}
+
+		case *query.InlineFragment:
+			ft := t
+			if sel.On.Name != "" {
+				ft = a.types[sel.On.Name]
+			}
+			a.selections(doc, sel.Selections, ft, visited)
+
+		case *query.FragmentSpread:
+			frag := doc.Fragments.Get(sel.Name.Name)
+			if frag == nil || visited[frag.Name.Name] {
+				continue
+			}
+			visited[frag.Name.Name] = true
+			a.selections(doc, frag.Selections, a.types[frag.On.Name], visited)
+			delete(visited, frag.Name.Name)
+		}
+	}
+}
+
+func field(t *introspection.Type, name string) *introspection.Field {
+	if t == nil {
+		return nil
+	}
+	fields := t.Fields(&struct{ IncludeDeprecated bool }{true})
+	if fields == nil {
+		return nil
+	}
+	for _, f := range *fields {
+		if f.Name() == name {
+			return f
+		}
+	}
+	return nil
+}
+
+func unwrap(t *introspection.Type) *introspection.Type {
+	for t.OfType() != nil {
+		t = t.OfType()
+	}
+	return t
+}
@@ -0,0 +1,67 @@
+package coverage_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/coverage"
+	"github.com/graph-gophers/graphql-go/example/starwars"
+)
+
+func TestAnalyzeDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "coverage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"hero.graphql": `
+			query Hero {
+				hero {
+					name
+					... on Droid { primaryFunction }
+					...friends
+				}
+			}
+			fragment friends on Character { friends { id } }
+		`,
+		"invalid.gql": `{ hero { unknown } }`,
+		"README.md":   `not an operation`,
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := graphql.MustParseSchema(starwars.Schema, nil)
+	r, err := coverage.AnalyzeDir(s, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(r.Documents) != 2 {
+		t.Fatalf("got %d documents, want 2", len(r.Documents))
+	}
+	if r.Valid() {
+		t.Error("expected the report to contain an invalid document")
+	}
+
+	for _, f := range []string{"Query.hero", "Character.name", "Droid.primaryFunction", "Character.friends", "Character.id"} {
+		if r.FieldUsage[f] != 1 {
+			t.Errorf("got usage %d for %s, want 1", r.FieldUsage[f], f)
+		}
+	}
+	for _, f := range []string{"Query.reviews", "Human.height", "Mutation.createReview"} {
+		if n, ok := r.FieldUsage[f]; !ok || n != 0 {
+			t.Errorf("expected %s to be reported as unused", f)
+		}
+	}
+	if len(r.Used())+len(r.Unused()) != len(r.FieldUsage) {
+		t.Error("used and unused fields do not add up to all fields")
+	}
+}
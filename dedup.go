@@ -0,0 +1,131 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/graph-gophers/graphql-go/errors"
+)
+
+// DeduplicateRequests collapses concurrent identical query operations into a single execution.
+// Operations are identical if they have the same CacheKey and viewer, which is returned by the
+// given function for the context of a request, e.g. the ID of the authenticated user. Pass a nil
+// function only if the results do not depend on the viewer.
+//
+// Requests arriving while an identical operation is executing wait for it and receive a copy of
+// its response instead of executing again, so the resolvers run with the values of the context of
+// the first request. The execution is cancelled only when all requests waiting for it are
+// cancelled; a request whose context is done before the response is ready fails on its own.
+// Mutations and subscriptions are never deduplicated. The RateLimit option still applies to every
+// request.
+func DeduplicateRequests(viewer func(ctx context.Context) string) SchemaOpt {
+	return func(s *Schema) {
+		if viewer == nil {
+			viewer = func(context.Context) string { return "" }
+		}
+		s.deduplicate = viewer
+	}
+}
+
+// dedupKey returns the key identifying identical operations of the same viewer.
+func (s *Schema) dedupKey(ctx context.Context, queryString string, operationName string, variables map[string]interface{}) (string, bool) {
+	key, err := s.CacheKey(queryString, operationName, variables)
+	if err != nil {
+		return "", false
+	}
+	return key + "\x00" + s.deduplicate(ctx), true
+}
+
+// inflightGroup shares the response of an execution with the identical requests arriving while it
+// is in flight.
+type inflightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*inflightCall
+}
+
+type inflightCall struct {
+	done    chan struct{}
+	resp    *Response
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// do executes fn unless an execution with the same key is in flight, in which case it waits for
+// that execution. Every caller receives its own copy of the response. The execution runs with the
+// values of the context of the first caller, but is only cancelled once the contexts of all
+// callers are done, so a cancelled request does not fail the identical requests waiting for it.
+func (g *inflightGroup) do(ctx context.Context, key string, fn func(ctx context.Context) *Response) *Response {
+	g.mu.Lock()
+	c, ok := g.calls[key]
+	if !ok {
+		if g.calls == nil {
+			g.calls = make(map[string]*inflightCall)
+		}
+		execCtx, cancel := context.WithCancel(detachedContext{ctx})
+		c = &inflightCall{done: make(chan struct{}), cancel: cancel}
+		g.calls[key] = c
+		go func() {
+			defer close(c.done)
+			defer cancel()
+			defer g.forget(key, c)
+			c.resp = fn(execCtx)
+		}()
+	}
+	c.waiters++
+	g.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return c.resp.copy()
+	case <-ctx.Done():
+		g.mu.Lock()
+		c.waiters--
+		if c.waiters == 0 {
+			c.cancel()
+			if g.calls[key] == c {
+				delete(g.calls, key)
+			}
+		}
+		g.mu.Unlock()
+		return &Response{Errors: []*errors.QueryError{errors.Errorf("%s", ctx.Err())}}
+	}
+}
+
+// forget removes the call, unless it was already replaced by a new call with the same key.
+func (g *inflightGroup) forget(key string, c *inflightCall) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.calls[key] == c {
+		delete(g.calls, key)
+	}
+}
+
+// detachedContext carries the values of its parent without its deadline and cancellation.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool)         { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}               { return nil }
+func (detachedContext) Err() error                          { return nil }
+func (c detachedContext) Value(key interface{}) interface{} { return c.parent.Value(key) }
+
+// copy returns a copy of the response that can be modified without affecting r. The serialized
+// data is shared, as it is never modified in place.
+func (r *Response) copy() *Response {
+	if r == nil {
+		return &Response{Errors: []*errors.QueryError{errors.Errorf("deduplicated request failed")}}
+	}
+	resp := &Response{Data: r.Data}
+	if r.Errors != nil {
+		resp.Errors = append([]*errors.QueryError(nil), r.Errors...)
+	}
+	if r.Extensions != nil {
+		resp.Extensions = make(map[string]interface{}, len(r.Extensions))
+		for k, v := range r.Extensions {
+			resp.Extensions[k] = v
+		}
+	}
+	return resp
+}
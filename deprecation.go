@@ -0,0 +1,165 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/internal/common"
+	"github.com/graph-gophers/graphql-go/internal/schema"
+)
+
+// DeprecationMode is the action EnforceDeprecations takes for operations using deprecated fields,
+// arguments, input fields or enum values.
+type DeprecationMode int
+
+const (
+	// DeprecationWarn adds the deprecated schema members used by an operation to the
+	// "deprecations" extension of the response.
+	DeprecationWarn DeprecationMode = iota
+
+	// DeprecationLog logs the operations using deprecated schema members with the log package.
+	DeprecationLog
+
+	// DeprecationReject rejects operations using deprecated schema members before any resolver is
+	// called. The error lists them in its "deprecations" extension.
+	DeprecationReject
+)
+
+// DeprecatedUsage is a deprecated schema member used by an operation.
+type DeprecatedUsage struct {
+	// Coordinate is the schema coordinate of the member, e.g. "User.name" or "Episode.JEDI".
+	Coordinate string `json:"coordinate"`
+
+	// Reason is the reason of the @deprecated directive.
+	Reason string `json:"reason"`
+}
+
+// EnforceDeprecations applies the mode to the operations using deprecated schema members, or only
+// to those of the given clients, identified by the name of their ClientInfo. The option can be
+// passed multiple times, e.g. to warn all clients and reject the operations of clients that were
+// already migrated. The deprecated members used by an operation are always available as
+// OperationInfo.Deprecations if the option is set.
+func EnforceDeprecations(mode DeprecationMode, clients ...string) SchemaOpt {
+	return func(s *Schema) {
+		p := &deprecationPolicy{mode: mode}
+		if len(clients) != 0 {
+			p.clients = make(map[string]bool)
+			for _, c := range clients {
+				p.clients[c] = true
+			}
+		}
+		s.deprecationPolicies = append(s.deprecationPolicies, p)
+	}
+}
+
+type deprecationPolicy struct {
+	mode    DeprecationMode
+	clients map[string]bool
+
+	// match selects the clients instead of clients if set, see ClientPolicy.RejectDeprecated.
+	match func(client ClientInfo) bool
+}
+
+func (p *deprecationPolicy) applies(client ClientInfo) bool {
+	if p.match != nil {
+		return p.match(client)
+	}
+	return p.clients == nil || p.clients[client.Name]
+}
+
+// checkDeprecations sets the deprecated members used by an operation and applies the policies of
+// the schema to them.
+func (s *Schema) checkDeprecations(info *OperationInfo, usage map[string]int) *errors.QueryError {
+	for coordinate := range usage {
+		if reason, ok := s.deprecated[coordinate]; ok {
+			info.Deprecations = append(info.Deprecations, DeprecatedUsage{Coordinate: coordinate, Reason: reason})
+		}
+	}
+	if len(info.Deprecations) == 0 {
+		return nil
+	}
+	sort.Slice(info.Deprecations, func(i, j int) bool {
+		return info.Deprecations[i].Coordinate < info.Deprecations[j].Coordinate
+	})
+
+	for _, p := range s.deprecationPolicies {
+		if !p.applies(info.Client) {
+			continue
+		}
+		switch p.mode {
+		case DeprecationWarn:
+			info.warnDeprecations = true
+		case DeprecationLog:
+			coordinates := make([]string, len(info.Deprecations))
+			for i, d := range info.Deprecations {
+				coordinates[i] = d.Coordinate
+			}
+			log.Printf("graphql: operation %q of client %q uses deprecated %s", info.Name, info.Client.Name, strings.Join(coordinates, ", "))
+		case DeprecationReject:
+			d := info.Deprecations[0]
+			return &errors.QueryError{
+				Message:    fmt.Sprintf("%q is deprecated: %s", d.Coordinate, d.Reason),
+				Extensions: map[string]interface{}{"code": "DEPRECATED", "deprecations": info.Deprecations},
+			}
+		}
+	}
+	return nil
+}
+
+// deprecationExtension returns the response extensions warning about the deprecated members used
+// by the operation executed with ctx.
+func deprecationExtension(ctx context.Context) []DeprecatedUsage {
+	info := OperationInfoFromContext(ctx)
+	if info == nil || !info.warnDeprecations {
+		return nil
+	}
+	return info.Deprecations
+}
+
+// deprecatedCoordinates returns the reasons of the deprecated members of the schema by their
+// schema coordinates.
+func deprecatedCoordinates(s *schema.Schema) map[string]string {
+	deprecated := make(map[string]string)
+	add := func(coordinate string, directives common.DirectiveList) {
+		d := directives.Get("deprecated")
+		if d == nil {
+			return
+		}
+		reason := "No longer supported"
+		if arg, ok := d.Args.Get("reason"); ok {
+			if r, ok := arg.Value(nil).(string); ok {
+				reason = r
+			}
+		}
+		deprecated[coordinate] = reason
+	}
+	addFields := func(typeName string, fields schema.FieldList) {
+		for _, f := range fields {
+			add(typeName+"."+f.Name, f.Directives)
+			for _, arg := range f.Args {
+				add(typeName+"."+f.Name+"("+arg.Name.Name+":)", arg.Directives)
+			}
+		}
+	}
+	for name, t := range s.Types {
+		switch t := t.(type) {
+		case *schema.Object:
+			addFields(name, t.Fields)
+		case *schema.Interface:
+			addFields(name, t.Fields)
+		case *schema.InputObject:
+			for _, f := range t.Values {
+				add(name+"."+f.Name.Name, f.Directives)
+			}
+		case *schema.Enum:
+			for _, v := range t.Values {
+				add(name+"."+v.Name, v.Directives)
+			}
+		}
+	}
+	return deprecated
+}
@@ -0,0 +1,122 @@
+package graphql
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/graph-gophers/graphql-go/internal/common"
+	"github.com/graph-gophers/graphql-go/internal/schema"
+)
+
+// Descriptions sets the descriptions of schema members without a description in the schema
+// definition, keyed by their schema coordinates, e.g. "User", "User.name", "User.avatar(size:)",
+// "UserInput.name" or "Role.ADMIN". The map is typically generated from the Go doc comments of the
+// resolvers with "graphql-gen descriptions". ParseSchema fails if a coordinate does not refer to a
+// member of the schema.
+func Descriptions(descriptions map[string]string) SchemaOpt {
+	return func(s *Schema) {
+		if s.descriptions == nil {
+			s.descriptions = make(map[string]string)
+		}
+		for coordinate, desc := range descriptions {
+			s.descriptions[coordinate] = desc
+		}
+	}
+}
+
+// applyDescriptions sets the descriptions of the Descriptions option.
+func (s *Schema) applyDescriptions() error {
+	coordinates := make([]string, 0, len(s.descriptions))
+	for coordinate := range s.descriptions {
+		coordinates = append(coordinates, coordinate)
+	}
+	sort.Strings(coordinates)
+
+	for _, coordinate := range coordinates {
+		c, err := ParseSchemaCoordinate(coordinate)
+		if err != nil {
+			return err
+		}
+		desc := memberDescription(s.schema, c)
+		if desc == nil {
+			return fmt.Errorf("description of %q: no such member in the schema", coordinate)
+		}
+		if *desc == "" {
+			*desc = s.descriptions[coordinate]
+		}
+	}
+	return nil
+}
+
+// memberDescription returns the description of the member of the schema the coordinate refers to,
+// or nil if there is no such member.
+func memberDescription(s *schema.Schema, c SchemaCoordinate) *string {
+	if c.Directive != "" {
+		d := s.Directives[c.Directive]
+		if d == nil {
+			return nil
+		}
+		if c.Argument == "" {
+			return &d.Desc
+		}
+		return argumentDescription(d.Args, c.Argument)
+	}
+
+	t := s.Types[c.Type]
+	if t == nil {
+		return nil
+	}
+	var fields schema.FieldList
+	switch t := t.(type) {
+	case *schema.Scalar:
+		if c.Member == "" {
+			return &t.Desc
+		}
+	case *schema.Object:
+		if c.Member == "" {
+			return &t.Desc
+		}
+		fields = t.Fields
+	case *schema.Interface:
+		if c.Member == "" {
+			return &t.Desc
+		}
+		fields = t.Fields
+	case *schema.Union:
+		if c.Member == "" {
+			return &t.Desc
+		}
+	case *schema.Enum:
+		if c.Member == "" {
+			return &t.Desc
+		}
+		for _, v := range t.Values {
+			if v.Name == c.Member && c.Argument == "" {
+				return &v.Desc
+			}
+		}
+	case *schema.InputObject:
+		if c.Member == "" {
+			return &t.Desc
+		}
+		if c.Argument == "" {
+			return argumentDescription(t.Values, c.Member)
+		}
+	}
+
+	f := fields.Get(c.Member)
+	if f == nil {
+		return nil
+	}
+	if c.Argument == "" {
+		return &f.Desc
+	}
+	return argumentDescription(f.Args, c.Argument)
+}
+
+func argumentDescription(args common.InputValueList, name string) *string {
+	if arg := args.Get(name); arg != nil {
+		return &arg.Desc
+	}
+	return nil
+}
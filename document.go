@@ -0,0 +1,82 @@
+package graphql
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/internal/query"
+)
+
+// Document is a parsed and validated query document, e.g. a stored document with several named
+// operations, whose operations are executed with ExecOperation.
+type Document struct {
+	// Operations are the operations of the document in the order they are written.
+	Operations []DocumentOperation
+
+	queryString string
+	doc         *query.Document
+}
+
+// DocumentOperation describes an operation of a Document.
+type DocumentOperation struct {
+	// Name is the name of the operation, which is empty for an anonymous operation.
+	Name string
+
+	// Type is "query", "mutation" or "subscription".
+	Type string
+}
+
+// ParseDocument parses and validates a query document. The values of the variables are validated
+// when an operation is executed.
+func (s *Schema) ParseDocument(queryString string) (*Document, []*errors.QueryError) {
+	doc, qErr := s.parseQuery(queryString)
+	if qErr != nil {
+		return nil, []*errors.QueryError{qErr}
+	}
+	if errs := s.validateWithoutVariables(doc); len(errs) != 0 {
+		return nil, errs
+	}
+	d := &Document{
+		Operations:  make([]DocumentOperation, len(doc.Operations)),
+		queryString: queryString,
+		doc:         doc,
+	}
+	for i, op := range doc.Operations {
+		d.Operations[i] = DocumentOperation{Name: op.Name.Name, Type: strings.ToLower(string(op.Type))}
+	}
+	return d, nil
+}
+
+// Operation returns the operation with the given name, or the only operation of the document if the
+// name is empty. It reports false if there is no such operation.
+func (d *Document) Operation(name string) (DocumentOperation, bool) {
+	if name == "" {
+		if len(d.Operations) == 1 {
+			return d.Operations[0], true
+		}
+		return DocumentOperation{}, false
+	}
+	for _, op := range d.Operations {
+		if op.Name == name {
+			return op, true
+		}
+	}
+	return DocumentOperation{}, false
+}
+
+// ExecOperation executes the operation of the document with the given name like Exec. The name may
+// only be empty if the document has a single operation. The document is not parsed and validated
+// again, only the values of the variables are validated.
+func (s *Schema) ExecOperation(ctx context.Context, doc *Document, operationName string, variables map[string]interface{}) *Response {
+	if s.res.Resolver == (reflect.Value{}) {
+		panic("schema created without resolver, can not exec")
+	}
+	ctx, done, ok := s.beginOperation(ctx, false)
+	if !ok {
+		return shuttingDownResponse()
+	}
+	defer done()
+	return s.presentResponse(ctx, s.execDocument(ctx, doc.queryString, doc.doc, true, operationName, variables, s.res))
+}
@@ -0,0 +1,139 @@
+package graphql
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/graph-gophers/graphql-go/internal/exec"
+	"github.com/graph-gophers/graphql-go/internal/query"
+)
+
+// Entity is implemented by the resolvers of objects whose results may be cached by an EntityCache.
+// EntityKey identifies the data of the object, e.g. "User:42", and must change whenever the
+// results of its fields may differ for the same selection, e.g. if they depend on the viewer. An
+// empty key opts out of caching.
+type Entity interface {
+	EntityKey() string
+}
+
+// CacheEntities caches the result of the selection set of every resolved Entity in the given cache
+// and reuses it across requests for the same selection set, i.e. the same fields, arguments and
+// type conditions, instead of calling the resolvers of the selection set. Only queries use the
+// cache; mutations and subscriptions always call the resolvers. Results with errors are not cached. A cached result depends on the entities resolved within it, so invalidating a nested
+// entity also purges the results of the entities selecting it.
+func CacheEntities(cache *EntityCache) SchemaOpt {
+	return func(s *Schema) {
+		s.entityCache = cache
+	}
+}
+
+// requestEntityCache returns the entity cache of a request executing op, if any.
+func (s *Schema) requestEntityCache(op *query.Operation) exec.EntityCache {
+	if s.entityCache == nil || op.Type != query.Query {
+		return nil
+	}
+	return s.entityCache
+}
+
+// EntityCache is an in-memory cache of the results of entities, see CacheEntities. It holds up to a
+// fixed number of results and evicts the least recently used ones.
+type EntityCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	lru        *list.List
+	entries    map[entityCacheKey]*list.Element
+	byEntity   map[string]map[*list.Element]bool
+}
+
+type entityCacheKey struct {
+	entity    string
+	selection string
+}
+
+type entityCacheEntry struct {
+	key    entityCacheKey
+	result []byte
+	deps   []string
+}
+
+// NewEntityCache returns an empty EntityCache holding up to maxEntries results, or an unlimited
+// number if maxEntries is zero.
+func NewEntityCache(maxEntries int) *EntityCache {
+	return &EntityCache{
+		maxEntries: maxEntries,
+		lru:        list.New(),
+		entries:    make(map[entityCacheKey]*list.Element),
+		byEntity:   make(map[string]map[*list.Element]bool),
+	}
+}
+
+// Invalidate purges the cached results of the entities with the given keys and of the entities
+// whose results include them.
+func (c *EntityCache) Invalidate(keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		for e := range c.byEntity[key] {
+			c.remove(e)
+		}
+	}
+}
+
+// Len returns the number of cached results.
+func (c *EntityCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// Get returns the cached result of a selection set of an entity and the keys of the entities it
+// depends on. It is called by the executor.
+func (c *EntityCache) Get(entity, selection string) ([]byte, []string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[entityCacheKey{entity, selection}]
+	if !ok {
+		return nil, nil, false
+	}
+	c.lru.MoveToFront(e)
+	entry := e.Value.(*entityCacheEntry)
+	return entry.result, entry.deps, true
+}
+
+// Set caches the result of a selection set of an entity. It is called by the executor.
+func (c *EntityCache) Set(entity, selection string, result []byte, deps []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := entityCacheKey{entity, selection}
+	if e, ok := c.entries[key]; ok {
+		c.remove(e)
+	}
+	e := c.lru.PushFront(&entityCacheEntry{
+		key:    key,
+		result: append([]byte(nil), result...),
+		deps:   deps,
+	})
+	c.entries[key] = e
+	for _, dep := range deps {
+		if c.byEntity[dep] == nil {
+			c.byEntity[dep] = make(map[*list.Element]bool)
+		}
+		c.byEntity[dep][e] = true
+	}
+	if c.maxEntries > 0 && c.lru.Len() > c.maxEntries {
+		c.remove(c.lru.Back())
+	}
+}
+
+// remove removes a cached result. The caller must hold c.mu.
+func (c *EntityCache) remove(e *list.Element) {
+	entry := e.Value.(*entityCacheEntry)
+	c.lru.Remove(e)
+	delete(c.entries, entry.key)
+	for _, dep := range entry.deps {
+		delete(c.byEntity[dep], e)
+		if len(c.byEntity[dep]) == 0 {
+			delete(c.byEntity, dep)
+		}
+	}
+}
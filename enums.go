@@ -0,0 +1,95 @@
+package graphql
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/graph-gophers/graphql-go/internal/schema"
+)
+
+// EnumValues maps the values of an enum to Go values of one type, e.g.
+// map[string]interface{}{"ADMIN": RoleAdmin, "USER": RoleUser} for a Go type Role based on int.
+// Results of that type are written as the names of the enum values they are mapped to, and
+// arguments and input fields of that type receive the Go values of the given enum values, so the
+// Go type does not need to implement fmt.Stringer or match the names of the enum values.
+// ParseSchema fails unless every value of the enum is mapped to a distinct Go value of the same
+// type.
+func EnumValues(enum string, values map[string]interface{}) SchemaOpt {
+	return func(s *Schema) {
+		if s.enumValues == nil {
+			s.enumValues = make(map[string]map[string]interface{})
+		}
+		s.enumValues[enum] = values
+	}
+}
+
+// applyEnumValues validates the mappings of the EnumValues option and sets them in the schema.
+func (s *Schema) applyEnumValues() error {
+	if len(s.enumValues) == 0 {
+		return nil
+	}
+	enums := make(map[string]*schema.Enum)
+	for _, t := range s.schema.Types {
+		if e, ok := t.(*schema.Enum); ok {
+			enums[s.schema.OriginalName(e.Name)] = e
+		}
+	}
+	names := make([]string, 0, len(s.enumValues))
+	for name := range s.enumValues {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	s.schema.EnumMappings = make(map[string]*schema.EnumMapping)
+	for _, name := range names {
+		e, ok := enums[name]
+		if !ok {
+			return fmt.Errorf("enum values of %q: no such enum in the schema", name)
+		}
+		m, err := enumMapping(e, s.enumValues[name])
+		if err != nil {
+			return fmt.Errorf("enum values of %q: %s", name, err)
+		}
+		s.schema.EnumMappings[e.Name] = m
+	}
+	return nil
+}
+
+func enumMapping(e *schema.Enum, values map[string]interface{}) (*schema.EnumMapping, error) {
+	m := &schema.EnumMapping{
+		Values: make(map[string]reflect.Value),
+		Names:  make(map[interface{}]string),
+	}
+	for _, v := range e.Values {
+		value, ok := values[v.Name]
+		if !ok {
+			return nil, fmt.Errorf("missing Go value of %s", v.Name)
+		}
+		rv := reflect.ValueOf(value)
+		if !rv.IsValid() {
+			return nil, fmt.Errorf("Go value of %s is nil", v.Name)
+		}
+		if m.Type == nil {
+			m.Type = rv.Type()
+			if !m.Type.Comparable() {
+				return nil, fmt.Errorf("Go type %s is not comparable", m.Type)
+			}
+		} else if rv.Type() != m.Type {
+			return nil, fmt.Errorf("Go value of %s has type %s instead of %s", v.Name, rv.Type(), m.Type)
+		}
+		if other, ok := m.Names[value]; ok {
+			return nil, fmt.Errorf("%v is the Go value of both %s and %s", value, other, v.Name)
+		}
+		m.Values[v.Name] = rv
+		m.Names[value] = v.Name
+	}
+	if len(values) != len(e.Values) {
+		for name := range values {
+			if _, ok := m.Values[name]; !ok {
+				return nil, fmt.Errorf("%s is not a value of the enum", name)
+			}
+		}
+	}
+	return m, nil
+}
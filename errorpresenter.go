@@ -0,0 +1,90 @@
+package graphql
+
+import (
+	"context"
+
+	"github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/requestcontext"
+)
+
+// AllowErrorExtensions removes all keys but the given ones from the extensions of the errors of a
+// response, e.g. AllowErrorExtensions("code", "retryAfter"), so diagnostic fields added by
+// libraries are not exposed to clients. Errors without any allowed key have no extensions.
+func AllowErrorExtensions(keys ...string) SchemaOpt {
+	return func(s *Schema) {
+		s.allowedErrorExtensions = make(map[string]bool, len(keys))
+		for _, k := range keys {
+			s.allowedErrorExtensions[k] = true
+		}
+	}
+}
+
+// LocalizeErrors replaces the messages of errors with a code, see errors.QueryError.Code, by the
+// message the catalog returns for the locale of the request and the code, so clients do not need
+// to parse English messages. The locale is read with requestcontext.Locale and is set by
+// relay.Handler from the Accept-Language header. Messages are unchanged if the catalog returns
+// false.
+func LocalizeErrors(catalog func(locale, code string) (string, bool)) SchemaOpt {
+	return func(s *Schema) {
+		s.errorCatalog = catalog
+	}
+}
+
+// presentErrors prepares the errors of a response for the client. Errors are copied before they
+// are changed, as they may be shared, e.g. by deduplicated requests.
+func (s *Schema) presentErrors(ctx context.Context, errs []*errors.QueryError) []*errors.QueryError {
+	if s.errorCatalog == nil && s.allowedErrorExtensions == nil {
+		return errs
+	}
+	locale := requestcontext.Locale(ctx)
+	for i, err := range errs {
+		if len(err.Extensions) == 0 {
+			continue
+		}
+		e := *err
+		if s.errorCatalog != nil {
+			if code := err.Code(); code != "" {
+				if msg, ok := s.errorCatalog(locale, code); ok {
+					e.Message = msg
+				}
+			}
+		}
+		if s.allowedErrorExtensions != nil {
+			e.Extensions = allowedExtensions(err.Extensions, s.allowedErrorExtensions)
+		}
+		errs[i] = &e
+	}
+	return errs
+}
+
+func allowedExtensions(extensions map[string]interface{}, allowed map[string]bool) map[string]interface{} {
+	var m map[string]interface{}
+	for k, v := range extensions {
+		if !allowed[k] {
+			continue
+		}
+		if m == nil {
+			m = make(map[string]interface{})
+		}
+		m[k] = v
+	}
+	return m
+}
+
+// presentResponses applies presentResponse to the responses of a subscription.
+func (s *Schema) presentResponses(ctx context.Context, responses <-chan interface{}) <-chan interface{} {
+	if s.errorCatalog == nil && s.allowedErrorExtensions == nil && len(s.responseTransformers) == 0 {
+		return responses
+	}
+	c := make(chan interface{})
+	go func() {
+		defer close(c)
+		forwardResponses(ctx, responses, c, func(resp interface{}) interface{} {
+			if r, ok := resp.(*Response); ok {
+				return s.presentResponse(ctx, r)
+			}
+			return resp
+		})
+	}()
+	return c
+}
@@ -2,6 +2,8 @@ package errors
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 )
 
 type QueryError struct {
@@ -40,3 +42,69 @@ func (err *QueryError) Error() string {
 }
 
 var _ error = &QueryError{}
+
+// PathSegment is an element of the path of an error: the response key of a field, or the index of
+// an element of a list if Field is empty.
+type PathSegment struct {
+	Field string
+	Index int
+}
+
+// IsIndex reports whether the segment is the index of a list element.
+func (s PathSegment) IsIndex() bool {
+	return s.Field == ""
+}
+
+func (s PathSegment) String() string {
+	if s.IsIndex() {
+		return strconv.Itoa(s.Index)
+	}
+	return s.Field
+}
+
+// TypedPath returns the path of the error as segments.
+func (err *QueryError) TypedPath() []PathSegment {
+	if len(err.Path) == 0 {
+		return nil
+	}
+	segments := make([]PathSegment, len(err.Path))
+	for i, p := range err.Path {
+		switch p := p.(type) {
+		case string:
+			segments[i] = PathSegment{Field: p}
+		case int:
+			segments[i] = PathSegment{Index: p}
+		case float64:
+			// Paths decoded from JSON contain numbers as float64.
+			segments[i] = PathSegment{Index: int(p)}
+		}
+	}
+	return segments
+}
+
+// PathString returns the path of the error joined by dots, e.g. "hero.friends.0.name".
+func (err *QueryError) PathString() string {
+	segments := err.TypedPath()
+	parts := make([]string, len(segments))
+	for i, s := range segments {
+		parts[i] = s.String()
+	}
+	return strings.Join(parts, ".")
+}
+
+// Code returns the "code" extension of the error, or "" if it has none.
+func (err *QueryError) Code() string {
+	code, _ := err.Extensions["code"].(string)
+	return code
+}
+
+// List is a list of errors returned as a single error.
+type List []*QueryError
+
+func (l List) Error() string {
+	messages := make([]string, len(l))
+	for i, err := range l {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
@@ -0,0 +1,85 @@
+package gqltesting
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/graph-gophers/graphql-go/errors"
+)
+
+// ErrorMatcher matches a query error by its most relevant properties. Empty fields match any
+// value.
+type ErrorMatcher struct {
+	// Message must be contained in the message of the error.
+	Message string
+
+	// Path must be equal to the path of the error. Use []interface{}{} to match errors without a
+	// path.
+	Path []interface{}
+
+	// Code must be equal to the "code" extension of the error.
+	Code string
+}
+
+// Match reports whether err is matched by m.
+func (m *ErrorMatcher) Match(err *errors.QueryError) bool {
+	if m.Message != "" && !strings.Contains(err.Message, m.Message) {
+		return false
+	}
+	if m.Path != nil && !reflect.DeepEqual(normalizePath(m.Path), normalizePath(err.Path)) {
+		return false
+	}
+	if m.Code != "" && fmt.Sprint(err.Extensions["code"]) != m.Code {
+		return false
+	}
+	return true
+}
+
+func (m *ErrorMatcher) String() string {
+	return fmt.Sprintf("{Message: %q, Path: %v, Code: %q}", m.Message, m.Path, m.Code)
+}
+
+// normalizePath converts the list indices of a path to int, so paths decoded from JSON can be
+// compared with paths written in Go.
+func normalizePath(path []interface{}) []interface{} {
+	normalized := make([]interface{}, len(path))
+	for i, p := range path {
+		switch p := p.(type) {
+		case float64:
+			normalized[i] = int(p)
+		case int32:
+			normalized[i] = int(p)
+		case int64:
+			normalized[i] = int(p)
+		default:
+			normalized[i] = p
+		}
+	}
+	return normalized
+}
+
+func matchErrors(t *testing.T, matchers []*ErrorMatcher, errs []*errors.QueryError) {
+	t.Helper()
+
+	if len(matchers) != len(errs) {
+		t.Fatalf("unexpected number of errors: want %d, got %d: %v", len(matchers), len(errs), errs)
+	}
+	used := make([]bool, len(matchers))
+outer:
+	for _, err := range errs {
+		for i, m := range matchers {
+			if !used[i] && m.Match(err) {
+				used[i] = true
+				continue outer
+			}
+		}
+		t.Errorf("unexpected error: %+v", err)
+	}
+	for i, m := range matchers {
+		if !used[i] {
+			t.Errorf("no error matches %s", m)
+		}
+	}
+}
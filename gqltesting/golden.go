@@ -0,0 +1,64 @@
+package gqltesting
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// update is namespaced, so that it does not clash with an -update flag of the test binary.
+var update = flag.Bool("gqltesting.update", false, "update the golden files of gqltesting")
+
+// checkGolden compares data with the contents of the golden file. Both are compared in a
+// normalized form with sorted object keys, so the key order of the response does not matter.
+func checkGolden(t *testing.T, file string, data json.RawMessage) {
+	t.Helper()
+
+	got, err := snapshot(data)
+	if err != nil {
+		t.Fatalf("got: invalid JSON: %s", err)
+	}
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(file, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatalf("%s (run the tests with -gqltesting.update to create the golden file)", err)
+	}
+	want, err := snapshot(b)
+	if err != nil {
+		t.Fatalf("%s: invalid JSON: %s", file, err)
+	}
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("result does not match %s (run the tests with -gqltesting.update to update it)\ngot:\n%s\nwant:\n%s", file, got, want)
+	}
+}
+
+// snapshot formats data as indented JSON. Object keys are sorted by encoding/json.
+func snapshot(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		data = []byte("null")
+	}
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
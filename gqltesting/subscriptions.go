@@ -20,22 +20,30 @@ type TestResponse struct {
 // TestSubscription is a GraphQL test case to be used with RunSubscribe.
 type TestSubscription struct {
 	Name            string
+	Context         context.Context
 	Schema          *graphql.Schema
 	Query           string
 	OperationName   string
 	Variables       map[string]interface{}
 	ExpectedResults []TestResponse
 	ExpectedErr     error
+
+	// Parallel runs the subtest created by RunSubscribes in parallel with the other parallel tests.
+	Parallel bool
 }
 
 // RunSubscribes runs the given GraphQL subscription test cases as subtests.
 func RunSubscribes(t *testing.T, tests []*TestSubscription) {
 	for i, test := range tests {
+		test := test
 		if test.Name == "" {
 			test.Name = strconv.Itoa(i + 1)
 		}
 
 		t.Run(test.Name, func(t *testing.T) {
+			if test.Parallel {
+				t.Parallel()
+			}
 			RunSubscribe(t, test)
 		})
 	}
@@ -43,12 +51,16 @@ func RunSubscribes(t *testing.T, tests []*TestSubscription) {
 
 // RunSubscribe runs a single GraphQL subscription test case.
 func RunSubscribe(t *testing.T, test *TestSubscription) {
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx := test.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	c, err := test.Schema.Subscribe(ctx, test.Query, test.OperationName, test.Variables)
 	if err != nil {
-		if err.Error() != test.ExpectedErr.Error() {
+		if test.ExpectedErr == nil || err.Error() != test.ExpectedErr.Error() {
 			t.Fatalf("unexpected error: got %+v, want %+v", err, test.ExpectedErr)
 		}
 
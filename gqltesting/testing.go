@@ -16,6 +16,8 @@ import (
 
 // Test is a GraphQL test case to be used with RunTest(s).
 type Test struct {
+	// Name is the name of the subtest created by RunTests. It defaults to the index of the test.
+	Name           string
 	Context        context.Context
 	Schema         *graphql.Schema
 	Query          string
@@ -23,17 +25,36 @@ type Test struct {
 	Variables      map[string]interface{}
 	ExpectedResult string
 	ExpectedErrors []*errors.QueryError
+
+	// Golden is the path of a file containing the expected result. It is used instead of
+	// ExpectedResult and is rewritten with the actual result when the tests run with -gqltesting.update.
+	Golden string
+
+	// MatchErrors is used instead of ExpectedErrors to compare errors by their message, path and
+	// code only. Every error must be matched by exactly one matcher.
+	MatchErrors []*ErrorMatcher
+
+	// Parallel runs the subtest created by RunTests in parallel with the other parallel tests.
+	Parallel bool
 }
 
 // RunTests runs the given GraphQL test cases as subtests.
 func RunTests(t *testing.T, tests []*Test) {
-	if len(tests) == 1 {
+	if len(tests) == 1 && tests[0].Name == "" && !tests[0].Parallel {
 		RunTest(t, tests[0])
 		return
 	}
 
 	for i, test := range tests {
-		t.Run(strconv.Itoa(i+1), func(t *testing.T) {
+		test := test
+		name := test.Name
+		if name == "" {
+			name = strconv.Itoa(i + 1)
+		}
+		t.Run(name, func(t *testing.T) {
+			if test.Parallel {
+				t.Parallel()
+			}
 			RunTest(t, test)
 		})
 	}
@@ -41,12 +62,22 @@ func RunTests(t *testing.T, tests []*Test) {
 
 // RunTest runs a single GraphQL test case.
 func RunTest(t *testing.T, test *Test) {
-	if test.Context == nil {
-		test.Context = context.Background()
+	ctx := test.Context
+	if ctx == nil {
+		ctx = context.Background()
 	}
-	result := test.Schema.Exec(test.Context, test.Query, test.OperationName, test.Variables)
+	result := test.Schema.Exec(ctx, test.Query, test.OperationName, test.Variables)
 
-	checkErrors(t, test.ExpectedErrors, result.Errors)
+	if test.MatchErrors != nil {
+		matchErrors(t, test.MatchErrors, result.Errors)
+	} else {
+		checkErrors(t, test.ExpectedErrors, result.Errors)
+	}
+
+	if test.Golden != "" {
+		checkGolden(t, test.Golden, result.Data)
+		return
+	}
 
 	if test.ExpectedResult == "" {
 		if result.Data != nil {
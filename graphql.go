@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"sync"
 	"time"
 
 	"github.com/graph-gophers/graphql-go/errors"
@@ -31,26 +32,76 @@ func ParseSchema(schemaString string, resolver interface{}, opts ...SchemaOpt) (
 		validationTracer: trace.NoopValidationTracer{},
 		logger:           &log.DefaultLogger{},
 	}
+	s.schema.RawJSONTypes = []reflect.Type{reflect.TypeOf(RawJSON(nil))}
+	s.schema.MarshalerType = marshalerType
 	for _, opt := range opts {
 		opt(s)
 	}
+	if s.slowOperationLog != nil {
+		s.tracer = trace.Multi(s.tracer, slowOperationTracer{s.slowOperationLog})
+	}
+	if s.profileOperations {
+		s.tracer = trace.Multi(s.tracer, profilingTracer{})
+	}
 
 	if err := s.schema.Parse(schemaString, s.useStringDescriptions); err != nil {
 		return nil, err
 	}
+	if err := s.applyDescriptions(); err != nil {
+		return nil, err
+	}
+	if err := s.applyTransformers(); err != nil {
+		return nil, err
+	}
 	if err := s.validateSchema(); err != nil {
 		return nil, err
 	}
+	if err := s.validateInputValidators(); err != nil {
+		return nil, err
+	}
+	if err := s.validateSerialFields(); err != nil {
+		return nil, err
+	}
+	if err := s.applyEnumValues(); err != nil {
+		return nil, err
+	}
+	if err := s.validateLiveQueries(); err != nil {
+		return nil, err
+	}
+	if err := s.applyRetryPolicies(); err != nil {
+		return nil, err
+	}
+	if p := s.clientDeprecationPolicy(); p != nil {
+		s.deprecationPolicies = append(s.deprecationPolicies, p)
+	}
+	if len(s.deprecationPolicies) != 0 {
+		s.deprecated = deprecatedCoordinates(s.schema)
+	}
 
 	r, err := resolvable.ApplyResolver(s.schema, resolver)
 	if err != nil {
 		return nil, err
 	}
 	s.res = r
+	if err := s.applySerialQueryFields(); err != nil {
+		return nil, err
+	}
+	if resolver != nil {
+		if err := s.validateRedactions(); err != nil {
+			return nil, err
+		}
+	}
 
 	return s, nil
 }
 
+// MergeResolvers combines several resolvers into one root resolver, e.g. to keep the resolvers of
+// each domain in their own struct. Every field of the root operation types must be resolved by
+// exactly one of them. The result can only be passed to ParseSchema as the root resolver.
+func MergeResolvers(resolvers ...interface{}) interface{} {
+	return resolvable.NewMerged(resolvers)
+}
+
 // MustParseSchema calls ParseSchema and panics on error.
 func MustParseSchema(schemaString string, resolver interface{}, opts ...SchemaOpt) *Schema {
 	s, err := ParseSchema(schemaString, resolver, opts...)
@@ -66,13 +117,62 @@ type Schema struct {
 	res    *resolvable.Schema
 
 	maxDepth                 int
+	maxFragmentDepth         int
+	maxInputDepth            int
+	maxIntrospectionDepth    int
+	maxOfTypeDepth           int
+	maxTokens                int
+	maxQueryBytes            int
+	maxResponseBytes         int
 	maxParallelism           int
+	limiter                  Limiter
+	scheduler                SchedulerFunc
+	concurrency              ConcurrencyPolicy
+	maxListConcurrency       int
+	maxListLength            int
+	traceSkippedFields       bool
+	documentStatsExtension   bool
+	sourceMapExtension       bool
+	usageSinks               []UsageSink
+	deprecationPolicies      []*deprecationPolicy
+	deprecated               map[string]string
+	clientPolicies           map[string]ClientPolicy
+	persisted                map[string]bool
+	fieldOrder               FieldOrder
 	tracer                   trace.Tracer
 	validationTracer         trace.ValidationTracer
 	logger                   log.Logger
 	useStringDescriptions    bool
+	descriptions             map[string]string
+	enumValues               map[string]map[string]interface{}
 	disableIntrospection     bool
 	subscribeResolverTimeout time.Duration
+	requestTimeout           time.Duration
+	serialQueryFields        []string
+	rateLimiter              RateLimiter
+	budgetStore              BudgetStore
+	redactionPredicates      map[string]func(ctx context.Context) bool
+	resolverFactory          func(ctx context.Context) (interface{}, error)
+	deduplicate              func(ctx context.Context) string
+	variablesHook            VariablesHook
+	liveQueryStore           LiveQueryStore
+	entityCache              *EntityCache
+	retryPolicies            map[string]RetryPolicy
+	circuitBreaker           CircuitBreaker
+	reportPanic              func(ctx context.Context, p *log.Panic)
+	panicStackTraces         bool
+	slowOperationLog         *SlowOperationLog
+	profileOperations        bool
+	inflight                 inflightGroup
+	shutdown                 shutdownState
+	allowedErrorExtensions   map[string]bool
+	errorCatalog             func(locale, code string) (string, bool)
+	transformers             []*Transformer
+	responseTransformers     []ResponseTransformer
+
+	versionOnce sync.Once
+	version     string
+	versionErr  error
 }
 
 // SchemaOpt is an option to pass to ParseSchema or MustParseSchema.
@@ -95,6 +195,158 @@ func UseFieldResolvers() SchemaOpt {
 	}
 }
 
+// PreferFieldResolvers uses struct field resolvers like UseFieldResolvers, but resolves a field by a
+// struct field even if the resolver also has a method of the same name. Resolver types implementing
+// MethodResolvers are resolved by their methods first, and struct fields tagged `graphql:"-"` are
+// never used as resolvers.
+func PreferFieldResolvers() SchemaOpt {
+	return func(s *Schema) {
+		s.schema.UseFieldResolvers = true
+		s.schema.PreferFieldResolvers = true
+	}
+}
+
+// MethodResolvers is implemented by resolver types whose methods take precedence over their struct
+// fields with PreferFieldResolvers.
+type MethodResolvers interface {
+	PreferMethodResolvers()
+}
+
+// NilSlicesAsNull writes nil slices resolved for nullable list fields as null. By default they are
+// written as empty lists. Nullable lists may be resolved by slices as well as pointers to slices.
+// Single fields may override the option with a "@nilSlice(as: NilSlice!)" directive declared with
+// "directive @nilSlice(as: NilSlice!) on FIELD_DEFINITION" and "enum NilSlice { NULL EMPTY }", and
+// struct field resolvers with a "nilnull" or "nilempty" option of their "graphql" tag, e.g.
+// `graphql:",nilnull"`.
+func NilSlicesAsNull() SchemaOpt {
+	return func(s *Schema) {
+		s.schema.NilSlicesAsNull = true
+	}
+}
+
+// StrictResolverTypes checks the result types of resolvers more thoroughly when the schema is
+// parsed, instead of failing when a query is executed: enums have to be resolved by strings or
+// fmt.Stringer implementations and scalars by types encoding/json can marshal. Mismatches between
+// the nesting of lists and non-null types and the Go type of a resolver are reported with the
+// expected Go type, e.g. "[][]int32" for "[[Int!]!]!".
+func StrictResolverTypes() SchemaOpt {
+	return func(s *Schema) {
+		s.schema.StrictResolverTypes = true
+	}
+}
+
+// LegacyNumericCoercion restores the lenient coercion of numbers: Float values passed for Int
+// arguments and variables are truncated, and Int results outside of the 32-bit range are written
+// to the response as is. By default such values are rejected with an error, as required by the
+// specification.
+func LegacyNumericCoercion() SchemaOpt {
+	return func(s *Schema) {
+		s.schema.LegacyNumericCoercion = true
+	}
+}
+
+// Int64Policy decides how results of the 64-bit integer types int, int64, uint, uint64 and uintptr
+// are handled. Their values beyond 2^53 lose precision as JSON numbers in JavaScript clients. Types
+// implementing json.Marshaler and fields of type ID, which are written as strings, are not
+// affected.
+type Int64Policy int
+
+const (
+	// Int64Default allows 64-bit integers to resolve Int fields, failing the field if a value is
+	// outside of the 32-bit range, and Float fields.
+	Int64Default Int64Policy = Int64Policy(schema.Int64Default)
+
+	// Int64Reject fails ParseSchema if a 64-bit integer type resolves a field other than ID.
+	Int64Reject Int64Policy = Int64Policy(schema.Int64Reject)
+
+	// Int64AsFloat only allows 64-bit integers to resolve Float fields, and writes them as floating
+	// point numbers, making the loss of precision explicit.
+	Int64AsFloat Int64Policy = Int64Policy(schema.Int64AsFloat)
+
+	// Int64AsString allows 64-bit integers to resolve String and custom scalar fields instead of Int
+	// and Float fields, and writes them as strings.
+	Int64AsString Int64Policy = Int64Policy(schema.Int64AsString)
+
+	// Int64BigInt only allows 64-bit integers to resolve fields of a "BigInt" scalar, which has to
+	// be declared in the schema, and writes them as strings.
+	Int64BigInt Int64Policy = Int64Policy(schema.Int64BigInt)
+)
+
+// NonFiniteFloatPolicy decides how NaN and infinite float results, which can not be represented
+// in JSON, are written to the response. It applies to Float fields and to custom scalars resolved
+// by floats. Results of non-null fields always fail with an error, as with NonFiniteError.
+type NonFiniteFloatPolicy int
+
+const (
+	// NonFiniteError writes null and adds an error with the path of the field. This is the default.
+	NonFiniteError NonFiniteFloatPolicy = NonFiniteFloatPolicy(schema.NonFiniteError)
+
+	// NonFiniteNull writes null without an error.
+	NonFiniteNull NonFiniteFloatPolicy = NonFiniteFloatPolicy(schema.NonFiniteNull)
+
+	// NonFiniteString writes the strings "NaN", "Infinity" and "-Infinity", e.g. for clients that
+	// parse them like JavaScript's Number.
+	NonFiniteString NonFiniteFloatPolicy = NonFiniteFloatPolicy(schema.NonFiniteString)
+)
+
+// NonFiniteFloats sets the policy for NaN and infinite float results, see NonFiniteFloatPolicy.
+func NonFiniteFloats(policy NonFiniteFloatPolicy) SchemaOpt {
+	return func(s *Schema) {
+		s.schema.NonFiniteFloats = schema.NonFiniteFloatPolicy(policy)
+	}
+}
+
+// Int64Results sets the policy for results of 64-bit integer types, see Int64Policy.
+func Int64Results(policy Int64Policy) SchemaOpt {
+	return func(s *Schema) {
+		s.schema.Int64Policy = schema.Int64Policy(policy)
+	}
+}
+
+// RequirePagination rejects queries selecting a list field that declares one of the given
+// pagination arguments without giving one of them, or with a value greater than max. The arguments
+// default to "first", "last" and "limit". List fields declaring none of them are not affected.
+func RequirePagination(max int, arguments ...string) SchemaOpt {
+	return func(s *Schema) {
+		s.schema.Pagination = newPagination(max, arguments, false)
+	}
+}
+
+// CapPagination is like RequirePagination, but caps the page size instead of rejecting queries:
+// greater values are replaced by max, and if none of the arguments is given, the first one
+// declared by the field is set to max.
+func CapPagination(max int, arguments ...string) SchemaOpt {
+	return func(s *Schema) {
+		s.schema.Pagination = newPagination(max, arguments, true)
+	}
+}
+
+func newPagination(max int, arguments []string, capped bool) *schema.Pagination {
+	if len(arguments) == 0 {
+		arguments = []string{"first", "last", "limit"}
+	}
+	return &schema.Pagination{MaxPageSize: max, Arguments: arguments, Cap: capped}
+}
+
+// SemanticNonNullIntrospection reports the positions of field types marked by a
+// "@semanticNonNull(levels: [Int] = [0])" directive as non-null in introspection, e.g. for clients
+// that handle errors per field and generate non-null types for such fields. The directive has to be
+// declared with "directive @semanticNonNull(levels: [Int] = [0]) on FIELD_DEFINITION". Regardless of
+// this option, the marked positions stay nullable during execution: a null value without error is
+// reported as an error, but does not null the parent.
+func SemanticNonNullIntrospection() SchemaOpt {
+	return func(s *Schema) {
+		s.schema.SemanticNonNullIntrospection = true
+	}
+}
+
+// UseRawJSONMessages allows resolvers to return json.RawMessage, which is then handled like RawJSON.
+func UseRawJSONMessages() SchemaOpt {
+	return func(s *Schema) {
+		s.schema.RawJSONTypes = append(s.schema.RawJSONTypes, reflect.TypeOf(json.RawMessage(nil)))
+	}
+}
+
 // MaxDepth specifies the maximum field nesting depth in a query. The default is 0 which disables max depth checking.
 func MaxDepth(n int) SchemaOpt {
 	return func(s *Schema) {
@@ -102,6 +354,70 @@ func MaxDepth(n int) SchemaOpt {
 	}
 }
 
+// MaxFragmentDepth specifies the maximum nesting depth of fragment spreads in a query, e.g. 2 if a
+// fragment spreads another fragment. The default is 0 which disables the limit.
+func MaxFragmentDepth(n int) SchemaOpt {
+	return func(s *Schema) {
+		s.maxFragmentDepth = n
+	}
+}
+
+// MaxInputDepth specifies the maximum nesting depth of the lists and input objects of the argument
+// values and variables of a query. The default is 0 which disables the limit.
+func MaxInputDepth(n int) SchemaOpt {
+	return func(s *Schema) {
+		s.maxInputDepth = n
+	}
+}
+
+// MaxIntrospectionDepth specifies the maximum field nesting depth within the __schema and __type
+// fields of a query, counting them as depth 1, so deeply nested introspection queries are rejected
+// before they are executed. The introspection query of GraphiQL needs a depth of 13. The limit does
+// not apply to ToJSON. The default is 0 which disables the limit.
+func MaxIntrospectionDepth(n int) SchemaOpt {
+	return func(s *Schema) {
+		s.maxIntrospectionDepth = n
+	}
+}
+
+// MaxOfTypeDepth specifies the maximum number of nested ofType fields within the __schema and
+// __type fields of a query. The introspection query of GraphiQL nests 7 of them. The limit does not
+// apply to ToJSON. The default is 0 which disables the limit.
+func MaxOfTypeDepth(n int) SchemaOpt {
+	return func(s *Schema) {
+		s.maxOfTypeDepth = n
+	}
+}
+
+// MaxTokens specifies the maximum number of tokens in a query document. Larger documents are
+// rejected while lexing, before a syntax tree is built. The limit does not apply to the
+// introspection query of ToJSON. The default is 0 which disables the limit.
+func MaxTokens(n int) SchemaOpt {
+	return func(s *Schema) {
+		s.maxTokens = n
+	}
+}
+
+// MaxQueryBytes specifies the maximum size of a query document in bytes. Larger documents are
+// rejected without being parsed. The limit does not apply to the introspection query of ToJSON.
+// The default is 0 which disables the limit.
+func MaxQueryBytes(n int) SchemaOpt {
+	return func(s *Schema) {
+		s.maxQueryBytes = n
+	}
+}
+
+// MaxResponseBytes limits the size of the data of responses. Once the values and keys written
+// exceed n bytes, no further resolvers are called and the response has no data and a single error
+// with the code "RESPONSE_TOO_LARGE", so a query multiplying nested lists cannot exhaust the memory
+// of the server. It only applies to Exec, not to operations sent with Subscribe. The default is 0
+// which disables the limit.
+func MaxResponseBytes(n int) SchemaOpt {
+	return func(s *Schema) {
+		s.maxResponseBytes = n
+	}
+}
+
 // MaxParallelism specifies the maximum number of resolvers per request allowed to run in parallel. The default is 10.
 func MaxParallelism(n int) SchemaOpt {
 	return func(s *Schema) {
@@ -109,6 +425,141 @@ func MaxParallelism(n int) SchemaOpt {
 	}
 }
 
+// Limiter limits the number of resolvers running in parallel, e.g. with a weighted semaphore or a
+// quota per tenant identified by the context. Acquire is called before a resolver runs and should
+// return an error once the context is cancelled. A field whose Acquire fails resolves to null with
+// the error. Release is called after the resolver returned.
+type Limiter interface {
+	Acquire(ctx context.Context) error
+	Release()
+}
+
+// UseLimiter replaces the semaphore of each request that allows MaxParallelism resolvers to run in
+// parallel with the given Limiter, which is shared by all requests. MaxParallelism still limits the
+// number of list elements resolved concurrently.
+func UseLimiter(limiter Limiter) SchemaOpt {
+	return func(s *Schema) {
+		s.limiter = limiter
+	}
+}
+
+// ConcurrencyPolicy determines how the goroutines resolving the fields and list entries of a
+// request are accounted for.
+type ConcurrencyPolicy int
+
+const (
+	// PerListConcurrency starts a goroutine for each field resolved in parallel and lets each list
+	// resolve up to MaxParallelism entries concurrently. Only the resolvers are bounded by the
+	// limiter, so deeply nested lists may start many goroutines. This is the default.
+	PerListConcurrency ConcurrencyPolicy = iota
+
+	// SharedConcurrency bounds all goroutines of a request by MaxParallelism. When all of them are
+	// busy, fields and list entries are resolved in the goroutine of their parent instead of
+	// waiting, so nested selections can neither deadlock nor oversubscribe.
+	SharedConcurrency
+)
+
+// Concurrency sets the ConcurrencyPolicy of the schema. It defaults to PerListConcurrency.
+func Concurrency(policy ConcurrencyPolicy) SchemaOpt {
+	return func(s *Schema) {
+		s.concurrency = policy
+	}
+}
+
+func (s *Schema) requestWorkers() chan struct{} {
+	if s.concurrency != SharedConcurrency {
+		return nil
+	}
+	return make(chan struct{}, s.maxParallelism)
+}
+
+// ScheduledField is a field that is resolved in parallel with the other fields of its selection
+// set.
+type ScheduledField struct {
+	// TypeName is the name of the object type the field is resolved on.
+	TypeName string
+	Name     string
+	Alias    string
+
+	// Arguments are the arguments of the field, with variables replaced by their values and
+	// default values for omitted arguments.
+	Arguments map[string]interface{}
+}
+
+// SchedulerFunc returns the cost of a field, e.g. as declared by a directive of the schema or as
+// measured by a tracer.
+type SchedulerFunc func(field *ScheduledField) float64
+
+// Scheduler orders the fields of a selection set that are resolved in parallel by the cost returned
+// by the given function, so cheap fields acquire the limiter before expensive siblings and are not
+// starved by them when the limiter is saturated. Fields of the same cost keep their document
+// order.
+func Scheduler(f SchedulerFunc) SchemaOpt {
+	return func(s *Schema) {
+		s.scheduler = f
+	}
+}
+
+func (s *Schema) fieldCost() func(typeName, fieldName, alias string, args map[string]interface{}) float64 {
+	if s.scheduler == nil {
+		return nil
+	}
+	return func(typeName, fieldName, alias string, args map[string]interface{}) float64 {
+		return s.scheduler(&ScheduledField{TypeName: typeName, Name: fieldName, Alias: alias, Arguments: args})
+	}
+}
+
+func (s *Schema) requestLimiter() exec.Limiter {
+	if s.limiter != nil {
+		return s.limiter
+	}
+	return exec.NewSemaphore(s.maxParallelism)
+}
+
+// FieldOrder is the order of the fields of objects in the response.
+type FieldOrder int
+
+const (
+	// SelectionOrder writes the fields in the order they are selected in the query. This is the
+	// default.
+	SelectionOrder FieldOrder = iota
+
+	// SchemaOrder writes the fields in the order they are defined in the schema. Aliases of the
+	// same field are sorted by their response keys and __typename comes first.
+	SchemaOrder
+
+	// SortedOrder writes the fields sorted by their response keys.
+	SortedOrder
+)
+
+// ResponseFieldOrder sets the order of the fields of objects in the response, e.g. to get
+// byte-identical responses for queries selecting the same fields in different orders.
+func ResponseFieldOrder(order FieldOrder) SchemaOpt {
+	return func(s *Schema) {
+		s.fieldOrder = order
+	}
+}
+
+// MaxListConcurrency limits the number of entries of a list that are resolved concurrently. The
+// entries are resolved in chunks of n, so a large list does not create a goroutine and a buffer for
+// each entry at once. Single list fields can be limited with a "@listConcurrency(max: Int!)"
+// directive in the schema. By default, lists are only limited by MaxParallelism.
+func MaxListConcurrency(n int) SchemaOpt {
+	return func(s *Schema) {
+		s.maxListConcurrency = n
+	}
+}
+
+// MaxListLength limits the number of entries of the lists resolved for a field. A longer list
+// resolves to null with an error with the code "LIST_TOO_LONG" at its path, instead of writing
+// every entry. Single list fields can be limited differently with a "@maxListLength(max: Int!)"
+// directive in the schema. The default is 0 which disables the limit.
+func MaxListLength(n int) SchemaOpt {
+	return func(s *Schema) {
+		s.maxListLength = n
+	}
+}
+
 // Tracer is used to trace queries and fields. It defaults to trace.OpenTracingTracer.
 func Tracer(tracer trace.Tracer) SchemaOpt {
 	return func(s *Schema) {
@@ -116,6 +567,14 @@ func Tracer(tracer trace.Tracer) SchemaOpt {
 	}
 }
 
+// TraceSkippedFields reports the fields skipped by a @skip or @include directive to the tracer, if
+// it implements trace.SkippedFieldTracer, e.g. to audit the whole requested shape of queries.
+func TraceSkippedFields() SchemaOpt {
+	return func(s *Schema) {
+		s.traceSkippedFields = true
+	}
+}
+
 // ValidationTracer is used to trace validation errors. It defaults to trace.NoopValidationTracer.
 func ValidationTracer(tracer trace.ValidationTracer) SchemaOpt {
 	return func(s *Schema) {
@@ -123,13 +582,33 @@ func ValidationTracer(tracer trace.ValidationTracer) SchemaOpt {
 	}
 }
 
-// Logger is used to log panics during query execution. It defaults to exec.DefaultLogger.
+// Logger is used to log panics during query execution. It defaults to exec.DefaultLogger. Loggers
+// implementing log.PanicLogger receive the stack trace, the path of the field and the name of the
+// operation of each panic.
 func Logger(logger log.Logger) SchemaOpt {
 	return func(s *Schema) {
 		s.logger = logger
 	}
 }
 
+// ReportPanics passes every panic during query execution to the given function in addition to the
+// logger, e.g. to ship it with its stack trace, the path of the field and the name of the
+// operation to a collector like Sentry. The function is called in the goroutine of the panic.
+func ReportPanics(reporter func(ctx context.Context, p *log.Panic)) SchemaOpt {
+	return func(s *Schema) {
+		s.reportPanic = reporter
+	}
+}
+
+// PanicStackTraces adds the stack trace of a panic to the "stacktrace" extension of its error, one
+// line per entry. Stack traces reveal the internals of the server, so only enable this for
+// debugging.
+func PanicStackTraces() SchemaOpt {
+	return func(s *Schema) {
+		s.panicStackTraces = true
+	}
+}
+
 // DisableIntrospection disables introspection queries.
 func DisableIntrospection() SchemaOpt {
 	return func(s *Schema) {
@@ -146,6 +625,93 @@ func SubscribeResolverTimeout(timeout time.Duration) SchemaOpt {
 	}
 }
 
+// RequestTimeout limits the amount of time a single query or mutation may take to execute. Fields
+// that are not resolved in time resolve to null and a timeout error is added to the response, while
+// the data of the fields that did complete is still returned. It also applies to queries and
+// mutations sent with Subscribe and to every execution of a live query, but not to the events of
+// subscriptions. The default is 0 which disables the timeout. A single field can be limited with a
+// "@deadline(timeout: String!)" directive on its definition, e.g. `@deadline(timeout: "250ms")`,
+// which has to be declared in the schema with
+// "directive @deadline(timeout: String!) on FIELD_DEFINITION".
+func RequestTimeout(timeout time.Duration) SchemaOpt {
+	return func(s *Schema) {
+		s.requestTimeout = timeout
+	}
+}
+
+// OneOfConstructor registers a constructor for a field of a "@oneOf" input object, which allows
+// resolvers to receive the input as a Go interface instead of a struct with one pointer per field.
+// The constructor is a func taking the Go value of the field and returning a member of the
+// interface, e.g. func(card CardInput) PaymentMethod, so resolvers can switch on the type of the
+// given member. A constructor has to be registered for every field of the input object.
+func OneOfConstructor(inputType, field string, constructor interface{}) SchemaOpt {
+	return func(s *Schema) {
+		if s.schema.OneOfConstructors == nil {
+			s.schema.OneOfConstructors = make(map[string]map[string]reflect.Value)
+		}
+		if s.schema.OneOfConstructors[inputType] == nil {
+			s.schema.OneOfConstructors[inputType] = make(map[string]reflect.Value)
+		}
+		s.schema.OneOfConstructors[inputType][field] = reflect.ValueOf(constructor)
+	}
+}
+
+// WithInputValidator registers a validator of the input object with the given name, e.g.
+// func(ctx context.Context, in *CreateUserInput) error, for validations across fields that can not
+// be expressed in the schema. It is called with the context of the operation and the unpacked
+// value wherever the input object is passed as an argument, before any resolver of the operation
+// is called. An error rejects the field like an invalid argument, with the "INVALID_ARGUMENT" code
+// and the JSON Pointer of the input object within the arguments in the "pointer" extension.
+func WithInputValidator(typeName string, validator interface{}) SchemaOpt {
+	return func(s *Schema) {
+		if s.schema.InputValidators == nil {
+			s.schema.InputValidators = make(map[string]reflect.Value)
+		}
+		s.schema.InputValidators[typeName] = reflect.ValueOf(validator)
+	}
+}
+
+// ResolverFactory creates a new root resolver for every operation, so per-request dependencies
+// like the authenticated user, a database session or data loaders can be fields of the resolver
+// instead of values of the context. The factory is called with the context of the operation after
+// it passed the RateLimiter. The resolver passed to ParseSchema only determines the Go types bound
+// to the schema and may be a nil pointer, e.g. (*Resolver)(nil). The factory has to return a value
+// of the same type. If it returns an error, the operation is not executed and the error is
+// returned in the response.
+func ResolverFactory(factory func(ctx context.Context) (interface{}, error)) SchemaOpt {
+	return func(s *Schema) {
+		s.resolverFactory = factory
+	}
+}
+
+// SerialQueryFields forces the given fields of the query root type to be resolved one after
+// another, in the order they appear in the query, instead of in parallel. The remaining root fields
+// are still executed in parallel. The same behavior can be requested in the schema by annotating a
+// field definition of the query root type with a "@serial" directive, which has to be declared in
+// the schema with "directive @serial on FIELD_DEFINITION". The directive is rejected on the fields
+// of other types.
+func SerialQueryFields(names ...string) SchemaOpt {
+	return func(s *Schema) {
+		s.serialQueryFields = append(s.serialQueryFields, names...)
+	}
+}
+
+// RedactionPredicate registers a predicate for the "@redact(when: String!, placeholder: String)"
+// directive, which has to be declared in the schema with
+// "directive @redact(when: String!, placeholder: String) on FIELD_DEFINITION". A field annotated
+// with `@redact(when: "anonymous")` is not resolved if the predicate named "anonymous" reports true
+// for the context of the request, e.g. because the user lacks a role stored in it. The field
+// resolves to null instead, or to the placeholder, which is only supported on String and ID fields
+// and is required on non-null fields.
+func RedactionPredicate(name string, predicate func(ctx context.Context) bool) SchemaOpt {
+	return func(s *Schema) {
+		if s.redactionPredicates == nil {
+			s.redactionPredicates = make(map[string]func(ctx context.Context) bool)
+		}
+		s.redactionPredicates[name] = predicate
+	}
+}
+
 // Response represents a typical response of a GraphQL server. It may be encoded to JSON directly or
 // it may be further processed to a custom response type, for example to include custom error data.
 // Errors are intentionally serialized first based on the advice in https://github.com/facebook/graphql/commit/7b40390d48680b15cb93e02d46ac5eb249689876#diff-757cea6edf0288677a9eea4cfc801d87R107
@@ -155,19 +721,78 @@ type Response struct {
 	Extensions map[string]interface{} `json:"extensions,omitempty"`
 }
 
-// Validate validates the given query with the schema.
+// UnmarshalData unmarshals the data of the response into v. It leaves v unchanged if the response
+// has no data, e.g. because the query was invalid.
+func (r *Response) UnmarshalData(v interface{}) error {
+	if len(r.Data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(r.Data, v)
+}
+
+// Validate validates the given query with the schema. The query is never executed, so it may be
+// used to check stored operations against a schema that was parsed without a resolver.
 func (s *Schema) Validate(queryString string) []*errors.QueryError {
 	return s.ValidateWithVariables(queryString, nil)
 }
 
-// ValidateWithVariables validates the given query with the schema and the input variables.
+// ValidateWithVariables validates the given query with the schema and the input variables. Like
+// Validate, it does not execute the query.
 func (s *Schema) ValidateWithVariables(queryString string, variables map[string]interface{}) []*errors.QueryError {
-	doc, qErr := query.Parse(queryString)
+	doc, qErr := s.parseQuery(queryString)
 	if qErr != nil {
 		return []*errors.QueryError{qErr}
 	}
 
-	return validation.Validate(s.schema, doc, variables, s.maxDepth)
+	return s.validate(doc, "", variables)
+}
+
+// ValidateDocument validates a parsed query document with the schema like ValidateWithVariables,
+// e.g. a document of the parser package. Unlike ValidateWithVariables, the values of the variables
+// are not validated if variables is nil, e.g. for stored operations.
+func (s *Schema) ValidateDocument(doc *query.Document, variables map[string]interface{}) []*errors.QueryError {
+	if variables == nil {
+		return s.validateWithoutVariables(doc)
+	}
+	return s.validate(doc, "", variables)
+}
+
+// validate validates the document with the variables of the operation with the given name, or of
+// all operations if the name is empty.
+func (s *Schema) validate(doc *query.Document, operationName string, variables map[string]interface{}) []*errors.QueryError {
+	return validation.ValidateOperation(s.schema, doc, operationName, variables, s.validationLimits())
+}
+
+// validateVariables validates the values of the variables of the operation with the given name
+// of a document validated by validateWithoutVariables.
+func (s *Schema) validateVariables(doc *query.Document, operationName string, variables map[string]interface{}) []*errors.QueryError {
+	op, err := getOperation(doc, operationName)
+	if err != nil {
+		return nil // reported when the operation is executed
+	}
+	return validation.ValidateVariables(s.schema, doc, op, variables, s.validationLimits())
+}
+
+// validateWithoutVariables validates the document without the values of its variables.
+func (s *Schema) validateWithoutVariables(doc *query.Document) []*errors.QueryError {
+	return validation.ValidateDocument(s.schema, doc, s.validationLimits())
+}
+
+func (s *Schema) validationLimits() validation.Limits {
+	return validation.Limits{
+		MaxDepth:              s.maxDepth,
+		MaxFragmentDepth:      s.maxFragmentDepth,
+		MaxInputDepth:         s.maxInputDepth,
+		MaxIntrospectionDepth: s.maxIntrospectionDepth,
+		MaxOfTypeDepth:        s.maxOfTypeDepth,
+	}
+}
+
+func (s *Schema) parseQuery(queryString string) (*query.Document, *errors.QueryError) {
+	return query.ParseWithLimits(queryString, query.Limits{
+		MaxBytes:  s.maxQueryBytes,
+		MaxTokens: s.maxTokens,
+	})
 }
 
 // Exec executes the given query with the schema's resolver. It panics if the schema was created
@@ -177,17 +802,79 @@ func (s *Schema) Exec(ctx context.Context, queryString string, operationName str
 	if s.res.Resolver == (reflect.Value{}) {
 		panic("schema created without resolver, can not exec")
 	}
-	return s.exec(ctx, queryString, operationName, variables, s.res)
+	ctx, done, ok := s.beginOperation(ctx, false)
+	if !ok {
+		return shuttingDownResponse()
+	}
+	defer done()
+	return s.presentResponse(ctx, s.exec(ctx, queryString, operationName, variables, s.res))
+}
+
+// ExecInto executes the given query like Exec and stores its data in out, which must be a pointer,
+// e.g. to a struct mirroring the selection set. It is meant for callers that embed the schema in
+// process: the data is decoded as written by the executor, without building a Response. The
+// returned errors include an error if the data could not be stored in out.
+func (s *Schema) ExecInto(ctx context.Context, out interface{}, queryString string, operationName string, variables map[string]interface{}) []*errors.QueryError {
+	resp := s.Exec(ctx, queryString, operationName, variables)
+	if err := resp.UnmarshalData(out); err != nil {
+		qErr := errors.Errorf("could not unmarshal data: %s", err)
+		qErr.ResolverError = err
+		return append(resp.Errors, qErr)
+	}
+	return resp.Errors
+}
+
+// Query executes the given query like ExecInto, taking the variables from vars, which may be nil,
+// a map or a struct encoded as a JSON object, e.g. with fields tagged `json:"episode"`. It is the
+// typed counterpart of Exec for callers that embed the schema in process. The returned errors
+// include an error if vars is not encoded as a JSON object.
+func (s *Schema) Query(ctx context.Context, out interface{}, queryString string, vars interface{}) []*errors.QueryError {
+	variables, ok := vars.(map[string]interface{})
+	if !ok && vars != nil {
+		b, err := json.Marshal(vars)
+		if err == nil {
+			err = json.Unmarshal(b, &variables)
+		}
+		if err != nil {
+			qErr := errors.Errorf("could not marshal variables: %s", err)
+			qErr.ResolverError = err
+			return []*errors.QueryError{qErr}
+		}
+	}
+	return s.ExecInto(ctx, out, queryString, "", variables)
 }
 
 func (s *Schema) exec(ctx context.Context, queryString string, operationName string, variables map[string]interface{}, res *resolvable.Schema) *Response {
-	doc, qErr := query.Parse(queryString)
+	doc, qErr := s.parseQuery(queryString)
+	if qErr != nil {
+		return &Response{Errors: []*errors.QueryError{qErr}}
+	}
+	return s.execDocument(ctx, queryString, doc, false, operationName, variables, res)
+}
+
+// execDocument executes an operation of a parsed document. If the document was already validated
+// by ParseDocument, only the values of the variables are validated.
+func (s *Schema) execDocument(ctx context.Context, queryString string, doc *query.Document, validated bool, operationName string, variables map[string]interface{}, res *resolvable.Schema) *Response {
+	if variables == nil {
+		variables = make(map[string]interface{})
+	}
+	variables, qErr := s.transformVariables(ctx, doc, operationName, variables)
 	if qErr != nil {
 		return &Response{Errors: []*errors.QueryError{qErr}}
 	}
 
 	validationFinish := s.validationTracer.TraceValidation()
-	errs := validation.Validate(s.schema, doc, variables, s.maxDepth)
+	var errs []*errors.QueryError
+	switch {
+	case res != s.res:
+		// The introspection query of ToJSON is not a document of a client, so the limits of the
+		// schema do not apply to it.
+		errs = validation.ValidateOperation(s.schema, doc, operationName, variables, validation.Limits{})
+	case validated:
+		errs = s.validateVariables(doc, operationName, variables)
+	default:
+		errs = s.validate(doc, operationName, variables)
+	}
 	validationFinish(errs)
 	if len(errs) != 0 {
 		return &Response{Errors: errs}
@@ -214,10 +901,39 @@ func (s *Schema) exec(ctx context.Context, queryString string, operationName str
 		}
 	}
 
-	// Fill in variables with the defaults from the operation
-	if variables == nil {
-		variables = make(map[string]interface{}, len(op.Vars))
+	// The introspection query of ToJSON uses its own resolvable schema and is not an operation of a
+	// client.
+	if res == s.res {
+		var err *errors.QueryError
+		if ctx, err = s.startOperation(ctx, queryString, doc, op, variables); err != nil {
+			return &Response{Errors: []*errors.QueryError{err}}
+		}
+		if s.deduplicate != nil && op.Type == query.Query {
+			if key, ok := s.dedupKey(ctx, queryString, operationName, variables); ok {
+				return s.inflight.do(ctx, key, func(ctx context.Context) *Response {
+					return s.execResolved(ctx, queryString, operationName, variables, doc, op)
+				})
+			}
+		}
+		return s.execResolved(ctx, queryString, operationName, variables, doc, op)
+	}
+	return s.execOperation(ctx, queryString, operationName, variables, doc, op, res, false)
+}
+
+// execResolved executes an operation of a client with the resolvable schema of the request.
+func (s *Schema) execResolved(ctx context.Context, queryString string, operationName string, variables map[string]interface{}, doc *query.Document, op *query.Operation) *Response {
+	res, err := s.requestResolver(ctx)
+	if err != nil {
+		return &Response{Errors: []*errors.QueryError{err}}
 	}
+	return s.execOperation(ctx, queryString, operationName, variables, doc, op, res, true)
+}
+
+// execOperation executes an operation with the resolvable schema. The limits of the schema on
+// responses only apply to the operations of clients.
+func (s *Schema) execOperation(ctx context.Context, queryString string, operationName string, variables map[string]interface{}, doc *query.Document, op *query.Operation, res *resolvable.Schema, client bool) *Response {
+
+	// Fill in variables with the defaults from the operation
 	for _, v := range op.Vars {
 		if _, ok := variables[v.Name.Name]; !ok && v.Default != nil {
 			variables[v.Name.Name] = v.Default.Value(nil)
@@ -231,9 +947,27 @@ func (s *Schema) exec(ctx context.Context, queryString string, operationName str
 			Schema:               s.schema,
 			DisableIntrospection: s.disableIntrospection,
 		},
-		Limiter: make(chan struct{}, s.maxParallelism),
-		Tracer:  s.tracer,
-		Logger:  s.logger,
+		Limiter:             s.requestLimiter(),
+		EntityCache:         s.requestEntityCache(op),
+		CircuitBreaker:      s.circuitBreaker,
+		ReportPanic:         s.reportPanic,
+		PanicStacks:         s.panicStackTraces,
+		MaxResponseBytes:    int64(s.maxResponseBytes),
+		MaxParallelism:      s.maxParallelism,
+		FieldCost:           s.fieldCost(),
+		Workers:             s.requestWorkers(),
+		MaxListConcurrency:  s.maxListConcurrency,
+		MaxListLength:       s.maxListLength,
+		TraceSkippedFields:  s.traceSkippedFields,
+		FieldOrder:          exec.FieldOrder(s.fieldOrder),
+		Tracer:              s.tracer,
+		Logger:              s.logger,
+		Timeout:             s.requestTimeout,
+		RedactionPredicates: s.redactionPredicates,
+		MarshalGraphQL:      marshalGraphQL,
+	}
+	if !client {
+		r.MaxResponseBytes, r.MaxListLength, r.Timeout = 0, 0, 0
 	}
 	varTypes := make(map[string]*introspection.Type)
 	for _, v := range op.Vars {
@@ -247,10 +981,76 @@ func (s *Schema) exec(ctx context.Context, queryString string, operationName str
 	data, errs := r.Execute(traceCtx, res, op)
 	finish(errs)
 
-	return &Response{
+	resp := &Response{
 		Data:   data,
 		Errors: errs,
 	}
+	if s.documentStatsExtension {
+		resp.Extensions = documentStatsExtension(ctx)
+	}
+	if deprecations := deprecationExtension(ctx); len(deprecations) != 0 {
+		if resp.Extensions == nil {
+			resp.Extensions = make(map[string]interface{})
+		}
+		resp.Extensions["deprecations"] = deprecations
+	}
+	if s.sourceMapExtension {
+		if positions := s.errorSourcePositions(doc, op, errs); len(positions) != 0 {
+			if resp.Extensions == nil {
+				resp.Extensions = make(map[string]interface{})
+			}
+			resp.Extensions["sourceMap"] = positions
+		}
+	}
+	return resp
+}
+
+// requestResolver returns the resolvable schema executing an operation, with the root resolver
+// created by the ResolverFactory if one is set.
+func (s *Schema) requestResolver(ctx context.Context) (*resolvable.Schema, *errors.QueryError) {
+	if s.resolverFactory == nil {
+		return s.res, nil
+	}
+	resolver, err := s.resolverFactory(ctx)
+	if err != nil {
+		if qErr, ok := err.(*errors.QueryError); ok {
+			return nil, qErr
+		}
+		return nil, &errors.QueryError{Message: err.Error(), ResolverError: err}
+	}
+	v := reflect.ValueOf(resolver)
+	if !sameResolverType(v, s.res.Resolver) {
+		return nil, errors.Errorf("resolver factory returned %s, expected %s", typeString(v), typeString(s.res.Resolver))
+	}
+	res := *s.res
+	res.Resolver = v
+	return &res, nil
+}
+
+func sameResolverType(v, want reflect.Value) bool {
+	if !v.IsValid() || v.Type() != want.Type() {
+		return false
+	}
+	if v.Type() != resolvable.MergedType {
+		return true
+	}
+	parts, wantParts := v.Interface().(*resolvable.Merged).Resolvers, want.Interface().(*resolvable.Merged).Resolvers
+	if len(parts) != len(wantParts) {
+		return false
+	}
+	for i := range parts {
+		if !sameResolverType(parts[i], wantParts[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func typeString(v reflect.Value) string {
+	if !v.IsValid() {
+		return "nil"
+	}
+	return v.Type().String()
 }
 
 func (s *Schema) validateSchema() error {
@@ -272,6 +1072,77 @@ func (s *Schema) validateSchema() error {
 	return nil
 }
 
+func (s *Schema) applySerialQueryFields() error {
+	if len(s.serialQueryFields) == 0 {
+		return nil
+	}
+	t, ok := s.schema.EntryPoints["query"].(*schema.Object)
+	if !ok {
+		return fmt.Errorf("root operation %q must be an OBJECT", "query")
+	}
+	for _, name := range s.serialQueryFields {
+		if t.Fields.Get(name) == nil {
+			return fmt.Errorf("serial query field %q is not defined on type %q", name, t.Name)
+		}
+		if q, ok := s.res.Query.(*resolvable.Object); ok {
+			q.Fields[name].Serial = true
+		}
+	}
+	return nil
+}
+
+// validateSerialFields checks that only fields of the query root type are annotated with @serial.
+// The fields of other types are resolved in parallel regardless.
+func (s *Schema) validateSerialFields() error {
+	query := s.schema.EntryPoints["query"]
+	for _, t := range s.schema.Types {
+		obj, ok := t.(*schema.Object)
+		if !ok || t == query {
+			continue
+		}
+		for _, f := range obj.Fields {
+			if f.Directives.Get("serial") != nil {
+				return fmt.Errorf("field %q has a @serial directive, which is only supported on fields of the query root type", obj.Name+"."+f.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// validateRedactions checks that the predicates of all @redact directives are registered. Schemas
+// without a resolver are not executed and may omit the predicates.
+func (s *Schema) validateRedactions() error {
+	for _, t := range s.schema.Types {
+		obj, ok := t.(*schema.Object)
+		if !ok {
+			continue
+		}
+		for _, f := range obj.Fields {
+			d := f.Directives.Get("redact")
+			if d == nil {
+				continue
+			}
+			if lit, ok := d.Args.Get("when"); ok && lit != nil {
+				if when, ok := lit.Value(nil).(string); ok && s.redactionPredicates[when] == nil {
+					return fmt.Errorf("redaction predicate %q of field %q is not registered", when, obj.Name+"."+f.Name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// validateInputValidators checks that the validators registered with WithInputValidator are
+// registered for input objects. Their signatures are checked when the resolver is bound.
+func (s *Schema) validateInputValidators() error {
+	for name := range s.schema.InputValidators {
+		if _, ok := s.schema.Types[name].(*schema.InputObject); !ok {
+			return fmt.Errorf("input validator registered for %q, which is not an input object", name)
+		}
+	}
+	return nil
+}
+
 func validateRootOp(s *schema.Schema, name string, mandatory bool) error {
 	t, ok := s.EntryPoints[name]
 	if !ok {
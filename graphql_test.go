@@ -1,9 +1,22 @@
 package graphql_test
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"runtime/pprof"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -11,6 +24,10 @@ import (
 	gqlerrors "github.com/graph-gophers/graphql-go/errors"
 	"github.com/graph-gophers/graphql-go/example/starwars"
 	"github.com/graph-gophers/graphql-go/gqltesting"
+	"github.com/graph-gophers/graphql-go/introspection"
+	"github.com/graph-gophers/graphql-go/log"
+	"github.com/graph-gophers/graphql-go/requestcontext"
+	"github.com/graph-gophers/graphql-go/trace"
 )
 
 type helloWorldResolver1 struct{}
@@ -385,6 +402,34 @@ func TestRootOperations_invalidSchema(t *testing.T) {
 			},
 			Want: want{Error: `graphql: type "Mutation" not found`},
 		},
+		"Unknown root operation": {
+			Args: args{
+				Schema: `
+					schema {
+						query: Query
+						queries: Query
+					}
+					type Query {
+						hello: String!
+					}
+				`,
+			},
+			Want: want{Error: `graphql: syntax error: unexpected "queries", expecting "query", "mutation" or "subscription" (line 4, column 14)`},
+		},
+		"Root operation declared twice": {
+			Args: args{
+				Schema: `
+					schema {
+						query: Query
+						query: OtherQuery
+					}
+					type Query {
+						hello: String!
+					}
+				`,
+			},
+			Want: want{Error: `graphql: syntax error: root operation "query" is defined more than once (line 4, column 12)`},
+		},
 	}
 
 	for name, tt := range testTable {
@@ -715,6 +760,7 @@ func TestNilInterface(t *testing.T) {
 				&gqlerrors.QueryError{
 					Message:       "x",
 					Path:          []interface{}{"b"},
+					Locations:     []gqlerrors.Location{{Line: 4, Column: 6}},
 					ResolverError: errors.New("x"),
 				},
 			},
@@ -753,6 +799,7 @@ func TestErrorPropagationInLists(t *testing.T) {
 				&gqlerrors.QueryError{
 					Message:       droidNotFoundError.Error(),
 					Path:          []interface{}{"findDroids", 1, "name"},
+					Locations:     []gqlerrors.Location{{Line: 4, Column: 7}},
 					ResolverError: droidNotFoundError,
 					Extensions:    map[string]interface{}{"code": droidNotFoundError.Code, "message": droidNotFoundError.Message},
 				},
@@ -795,6 +842,7 @@ func TestErrorPropagationInLists(t *testing.T) {
 				&gqlerrors.QueryError{
 					Message:       droidNotFoundError.Error(),
 					Path:          []interface{}{"findDroids", 1, "name"},
+					Locations:     []gqlerrors.Location{{Line: 4, Column: 7}},
 					ResolverError: droidNotFoundError,
 					Extensions:    map[string]interface{}{"code": droidNotFoundError.Code, "message": droidNotFoundError.Message},
 				},
@@ -827,8 +875,9 @@ func TestErrorPropagationInLists(t *testing.T) {
 			`,
 			ExpectedErrors: []*gqlerrors.QueryError{
 				&gqlerrors.QueryError{
-					Message: `graphql: got nil for non-null "Droid"`,
-					Path:    []interface{}{"findNilDroids", 1},
+					Message:   `graphql: got nil for non-null "Droid"`,
+					Path:      []interface{}{"findNilDroids", 1},
+					Locations: []gqlerrors.Location{{Line: 3, Column: 6}},
 				},
 			},
 		},
@@ -907,6 +956,7 @@ func TestErrorPropagationInLists(t *testing.T) {
 					Message:       quoteError.Error(),
 					ResolverError: quoteError,
 					Path:          []interface{}{"findDroids", 0, "quotes"},
+					Locations:     []gqlerrors.Location{{Line: 4, Column: 7}},
 				},
 			},
 		},
@@ -942,10 +992,12 @@ func TestErrorPropagationInLists(t *testing.T) {
 					Message:       quoteError.Error(),
 					ResolverError: quoteError,
 					Path:          []interface{}{"findNilDroids", 0, "quotes"},
+					Locations:     []gqlerrors.Location{{Line: 5, Column: 7}},
 				},
 				&gqlerrors.QueryError{
-					Message: `graphql: got nil for non-null "Droid"`,
-					Path:    []interface{}{"findNilDroids", 1},
+					Message:   `graphql: got nil for non-null "Droid"`,
+					Path:      []interface{}{"findNilDroids", 1},
+					Locations: []gqlerrors.Location{{Line: 3, Column: 6}},
 				},
 			},
 		},
@@ -985,6 +1037,7 @@ func TestErrorWithExtensions(t *testing.T) {
 				&gqlerrors.QueryError{
 					Message:       droidNotFoundError.Error(),
 					Path:          []interface{}{"FindDroid"},
+					Locations:     []gqlerrors.Location{{Line: 3, Column: 6}},
 					ResolverError: droidNotFoundError,
 					Extensions:    map[string]interface{}{"code": droidNotFoundError.Code, "message": droidNotFoundError.Message},
 				},
@@ -993,6 +1046,60 @@ func TestErrorWithExtensions(t *testing.T) {
 	})
 }
 
+func TestGQLTestingHarness(t *testing.T) {
+	t.Parallel()
+
+	findDroidSchema := graphql.MustParseSchema(`
+		schema {
+			query: Query
+		}
+
+		type Query {
+			FindDroid: Droid!
+			FindHuman: String
+		}
+		type Droid {
+			Name: String!
+		}
+	`, &findDroidOrHumanResolver{})
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Name:     "Golden",
+			Parallel: true,
+			Schema:   starwarsSchema,
+			Query: `
+				query HeroFriends($episode: Episode) {
+					hero(episode: $episode) {
+						name
+						friends {
+							name
+						}
+					}
+				}
+			`,
+			Variables: map[string]interface{}{"episode": "EMPIRE"},
+			Golden:    "testdata/hero_friends.json",
+		},
+		{
+			Name:     "MatchErrors",
+			Parallel: true,
+			Schema:   findDroidSchema,
+			Query: `
+				{
+					FindDroid {
+						Name
+					}
+				}
+			`,
+			ExpectedResult: `null`,
+			MatchErrors: []*gqltesting.ErrorMatcher{
+				{Code: droidNotFoundError.Code, Path: []interface{}{"FindDroid"}},
+			},
+		},
+	})
+}
+
 func TestErrorWithNoExtensions(t *testing.T) {
 	t.Parallel()
 
@@ -1021,6 +1128,7 @@ func TestErrorWithNoExtensions(t *testing.T) {
 				&gqlerrors.QueryError{
 					Message:       err.Error(),
 					Path:          []interface{}{"DismissVader"},
+					Locations:     []gqlerrors.Location{{Line: 3, Column: 6}},
 					ResolverError: err,
 					Extensions:    nil,
 				},
@@ -1577,9 +1685,10 @@ func TestEnums(t *testing.T) {
 			Variables: map[string]interface{}{"episode": "FINAL_FRONTIER"},
 			ExpectedErrors: []*gqlerrors.QueryError{
 				{
-					Message:   "Variable \"episode\" has invalid value FINAL_FRONTIER.\nExpected type \"Episode\", found FINAL_FRONTIER.",
-					Locations: []gqlerrors.Location{{Column: 26, Line: 2}},
-					Rule:      "VariablesOfCorrectType",
+					Message:    "Variable \"episode\" has invalid value FINAL_FRONTIER.\nExpected type \"Episode\", found FINAL_FRONTIER.",
+					Locations:  []gqlerrors.Location{{Column: 26, Line: 2}},
+					Rule:       "VariablesOfCorrectType",
+					Extensions: map[string]interface{}{"pointer": "/episode", "expectedType": "Episode"},
 				},
 			},
 		},
@@ -1638,8 +1747,9 @@ func TestEnums(t *testing.T) {
 			}`,
 			ExpectedErrors: []*gqlerrors.QueryError{
 				{
-					Message: "Invalid value STAR_TREK.\nExpected type Episode, found STAR_TREK.",
-					Path:    []interface{}{"hero", "appearsIn", 0},
+					Message:   "Invalid value STAR_TREK.\nExpected type Episode, found STAR_TREK.",
+					Path:      []interface{}{"hero", "appearsIn", 0},
+					Locations: []gqlerrors.Location{{Line: 5, Column: 7}},
 				},
 			},
 		},
@@ -2204,7 +2314,7 @@ func TestIntrospection(t *testing.T) {
 					"b": {
 						"name": "Character",
 						"kind": "INTERFACE",
-						"interfaces": null,
+						"interfaces": [],
 						"possibleTypes": [
 							{
 								"name": "Human"
@@ -2394,6 +2504,8 @@ func TestIntrospection(t *testing.T) {
 									"description": "Marks an element of a GraphQL schema as no longer supported.",
 									"locations": [
 										"FIELD_DEFINITION",
+										"ARGUMENT_DEFINITION",
+										"INPUT_FIELD_DEFINITION",
 										"ENUM_VALUE"
 									],
 									"args": [
@@ -2429,6 +2541,79 @@ func TestIntrospection(t *testing.T) {
 										}
 									]
 								},
+								{
+									"name": "length",
+									"description": "Limits the number of characters of a string or the number of entries of a list.",
+									"locations": [
+										"ARGUMENT_DEFINITION",
+										"INPUT_FIELD_DEFINITION"
+									],
+									"args": [
+										{
+											"name": "min",
+											"description": "The minimum length, if any.",
+											"type": {
+												"kind": "SCALAR",
+												"ofType": null
+											}
+										},
+										{
+											"name": "max",
+											"description": "The maximum length, if any.",
+											"type": {
+												"kind": "SCALAR",
+												"ofType": null
+											}
+										}
+									]
+								},
+								{
+									"name": "pattern",
+									"description": "Requires a string, or every string of a list, to match a Go regular expression.",
+									"locations": [
+										"ARGUMENT_DEFINITION",
+										"INPUT_FIELD_DEFINITION"
+									],
+									"args": [
+										{
+											"name": "regex",
+											"description": "The regular expression, e.g. \"^[a-z]+$\".",
+											"type": {
+												"kind": "NON_NULL",
+												"ofType": {
+													"kind": "SCALAR",
+													"name": "String"
+												}
+											}
+										}
+									]
+								},
+								{
+									"name": "range",
+									"description": "Limits a number, or every number of a list.",
+									"locations": [
+										"ARGUMENT_DEFINITION",
+										"INPUT_FIELD_DEFINITION"
+									],
+									"args": [
+										{
+											"name": "min",
+											"description": "The minimum value, if any.",
+											"type": {
+												"kind": "SCALAR",
+												"ofType": null
+											}
+										},
+										{
+											"name": "max",
+											"description": "The maximum value, if any.",
+											"type": {
+												"kind": "SCALAR",
+												"ofType": null
+											}
+										}
+									]
+								},
 								{
 									"name": "skip",
 									"description": "Directs the executor to skip this field or fragment when the ` + "`" + `if` + "`" + ` argument is true.",
@@ -2666,6 +2851,188 @@ func TestMutationOrder(t *testing.T) {
 	})
 }
 
+type serialResolver struct {
+	mu    sync.Mutex
+	order []string
+}
+
+func (r *serialResolver) record(name string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.order = append(r.order, name)
+	return name
+}
+
+func (r *serialResolver) Slow(ctx context.Context) string {
+	time.Sleep(20 * time.Millisecond)
+	return r.record("slow")
+}
+
+func (r *serialResolver) Fast(ctx context.Context) string {
+	return r.record("fast")
+}
+
+func TestSerialQueryFields(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name   string
+		schema string
+		opts   []graphql.SchemaOpt
+	}{
+		{
+			name: "directive",
+			schema: `
+				directive @serial on FIELD_DEFINITION
+
+				type Query {
+					slow: String! @serial
+					fast: String! @serial
+				}
+			`,
+		},
+		{
+			name: "option",
+			schema: `
+				type Query {
+					slow: String!
+					fast: String!
+				}
+			`,
+			opts: []graphql.SchemaOpt{graphql.SerialQueryFields("slow", "fast")},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			r := &serialResolver{}
+			gqltesting.RunTest(t, &gqltesting.Test{
+				Schema:         graphql.MustParseSchema(tt.schema, r, tt.opts...),
+				Query:          `{ slow fast }`,
+				ExpectedResult: `{ "slow": "slow", "fast": "fast" }`,
+			})
+			if want := []string{"slow", "fast"}; !reflect.DeepEqual(r.order, want) {
+				t.Errorf("got resolver order %v, want %v", r.order, want)
+			}
+		})
+	}
+
+	if _, err := graphql.ParseSchema(`type Query { slow: String! }`, &serialResolver{}, graphql.SerialQueryFields("missing")); err == nil {
+		t.Error("expected an error for an unknown serial query field")
+	}
+	if _, err := graphql.ParseSchema(`
+		directive @serial on FIELD_DEFINITION
+		type Query { hero: Hero }
+		type Hero { name: String! @serial }
+	`, nil); err == nil || !strings.Contains(err.Error(), `"Hero.name"`) {
+		t.Errorf("expected an error for a serial field outside of the query root type, got %v", err)
+	}
+}
+
+type deadlineResolver struct{}
+
+func (r *deadlineResolver) Fast(ctx context.Context) string {
+	return "fast"
+}
+
+func (r *deadlineResolver) Slow(ctx context.Context) (*string, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestRequestTimeout(t *testing.T) {
+	t.Parallel()
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: graphql.MustParseSchema(`
+				type Query {
+					fast: String!
+					slow: String
+				}
+			`, &deadlineResolver{}, graphql.RequestTimeout(10*time.Millisecond)),
+			Query:          `{ fast slow }`,
+			ExpectedResult: `{ "fast": "fast", "slow": null }`,
+			ExpectedErrors: []*gqlerrors.QueryError{
+				{
+					Message:       context.DeadlineExceeded.Error(),
+					Path:          []interface{}{"slow"},
+					Locations:     []gqlerrors.Location{{Line: 1, Column: 8}},
+					ResolverError: context.DeadlineExceeded,
+				},
+			},
+		},
+		{
+			Schema: graphql.MustParseSchema(`
+				directive @deadline(timeout: String!) on FIELD_DEFINITION
+
+				type Query {
+					fast: String!
+					slow: String @deadline(timeout: "10ms")
+				}
+			`, &deadlineResolver{}),
+			Query:          `{ fast slow }`,
+			ExpectedResult: `{ "fast": "fast", "slow": null }`,
+			ExpectedErrors: []*gqlerrors.QueryError{
+				{
+					Message:       context.DeadlineExceeded.Error(),
+					Path:          []interface{}{"slow"},
+					Locations:     []gqlerrors.Location{{Line: 1, Column: 8}},
+					ResolverError: context.DeadlineExceeded,
+				},
+			},
+		},
+	})
+
+	_, err := graphql.ParseSchema(`
+		directive @deadline(timeout: String!) on FIELD_DEFINITION
+
+		type Query {
+			fast: String! @deadline(timeout: "soon")
+			slow: String
+		}
+	`, &deadlineResolver{})
+	if err == nil {
+		t.Error("expected an error for an invalid @deadline timeout")
+	}
+}
+
+type queuedResolver struct {
+	calls int32
+}
+
+func (r *queuedResolver) Wait(ctx context.Context) string {
+	atomic.AddInt32(&r.calls, 1)
+	time.Sleep(30 * time.Millisecond)
+	return "done"
+}
+
+func TestCancellationSkipsQueuedFields(t *testing.T) {
+	t.Parallel()
+
+	r := &queuedResolver{}
+	schema := graphql.MustParseSchema(`
+		type Query {
+			wait: String!
+		}
+	`, r, graphql.MaxParallelism(1), graphql.RequestTimeout(10*time.Millisecond))
+
+	resp := schema.Exec(context.Background(), `{ a: wait b: wait c: wait }`, "", nil)
+	if calls := atomic.LoadInt32(&r.calls); calls != 1 {
+		t.Errorf("got %d resolver calls, want 1", calls)
+	}
+	if len(resp.Errors) != 2 {
+		t.Fatalf("got %d errors, want 2: %v", len(resp.Errors), resp.Errors)
+	}
+	for _, err := range resp.Errors {
+		if want := "skipped due to cancellation: context deadline exceeded"; err.Message != want {
+			t.Errorf("got error %q, want %q", err.Message, want)
+		}
+		if len(err.Path) != 1 {
+			t.Errorf("got error path %v, want a single field alias", err.Path)
+		}
+	}
+}
+
 func TestTime(t *testing.T) {
 	t.Parallel()
 
@@ -2997,7 +3364,7 @@ func TestInput(t *testing.T) {
 	})
 }
 
-type inputArgumentsHello struct {}
+type inputArgumentsHello struct{}
 
 type inputArgumentsScalarMismatch1 struct{}
 
@@ -3276,6 +3643,7 @@ func TestErrorPropagation(t *testing.T) {
 					Message:       exampleError.Error(),
 					ResolverError: exampleError,
 					Path:          []interface{}{"triggerError"},
+					Locations:     []gqlerrors.Location{{Line: 4, Column: 6}},
 				},
 			},
 		},
@@ -3315,6 +3683,7 @@ func TestErrorPropagation(t *testing.T) {
 					Message:       exampleError.Error(),
 					ResolverError: exampleError,
 					Path:          []interface{}{"child", "triggerError"},
+					Locations:     []gqlerrors.Location{{Line: 6, Column: 7}},
 				},
 			},
 		},
@@ -3358,6 +3727,7 @@ func TestErrorPropagation(t *testing.T) {
 					Message:       exampleError.Error(),
 					ResolverError: exampleError,
 					Path:          []interface{}{"child", "child", "triggerError"},
+					Locations:     []gqlerrors.Location{{Line: 8, Column: 8}},
 				},
 			},
 		},
@@ -3404,6 +3774,7 @@ func TestErrorPropagation(t *testing.T) {
 					Message:       exampleError.Error(),
 					ResolverError: exampleError,
 					Path:          []interface{}{"child", "child", "triggerError"},
+					Locations:     []gqlerrors.Location{{Line: 8, Column: 8}},
 				},
 			},
 		},
@@ -3438,8 +3809,9 @@ func TestErrorPropagation(t *testing.T) {
 			`,
 			ExpectedErrors: []*gqlerrors.QueryError{
 				{
-					Message: nilChildErrorString,
-					Path:    []interface{}{"child", "nilChild"},
+					Message:   nilChildErrorString,
+					Path:      []interface{}{"child", "nilChild"},
+					Locations: []gqlerrors.Location{{Line: 5, Column: 7}},
 				},
 			},
 		},
@@ -3478,8 +3850,9 @@ func TestErrorPropagation(t *testing.T) {
 			`,
 			ExpectedErrors: []*gqlerrors.QueryError{
 				{
-					Message: nilChildErrorString,
-					Path:    []interface{}{"child", "nilChild"},
+					Message:   nilChildErrorString,
+					Path:      []interface{}{"child", "nilChild"},
+					Locations: []gqlerrors.Location{{Line: 6, Column: 7}},
 				},
 			},
 		},
@@ -3522,13 +3895,15 @@ func TestErrorPropagation(t *testing.T) {
 			`,
 			ExpectedErrors: []*gqlerrors.QueryError{
 				{
-					Message: nilChildErrorString,
-					Path:    []interface{}{"child", "child", "child", "nilChild"},
+					Message:   nilChildErrorString,
+					Path:      []interface{}{"child", "child", "child", "nilChild"},
+					Locations: []gqlerrors.Location{{Line: 7, Column: 9}},
 				},
 				{
 					Message:       exampleError.Error(),
 					ResolverError: exampleError,
 					Path:          []interface{}{"child", "child", "triggerError"},
+					Locations:     []gqlerrors.Location{{Line: 5, Column: 8}},
 				},
 			},
 		},
@@ -3566,8 +3941,9 @@ func TestErrorPropagation(t *testing.T) {
 			`,
 			ExpectedErrors: []*gqlerrors.QueryError{
 				{
-					Message: nilChildErrorString,
-					Path:    []interface{}{"child", "child", "nilChild"},
+					Message:   nilChildErrorString,
+					Path:      []interface{}{"child", "child", "nilChild"},
+					Locations: []gqlerrors.Location{{Line: 5, Column: 8}},
 				},
 			},
 		},
@@ -3677,6 +4053,39 @@ func (r *subscriptionsInExecResolver) AppUpdated() <-chan string {
 	return make(chan string)
 }
 
+func TestSchema_Validate_without_resolver(t *testing.T) {
+	t.Parallel()
+
+	s := graphql.MustParseSchema(starwars.Schema, nil)
+
+	if errs := s.Validate(`{ hero { name } }`); len(errs) != 0 {
+		t.Errorf("got errors for a valid query: %v", errs)
+	}
+
+	errs := s.Validate(`{ hero { unknownField } }`)
+	if len(errs) != 1 || errs[0].Rule != "FieldsOnCorrectType" {
+		t.Errorf("got errors %v, want a single FieldsOnCorrectType error", errs)
+	}
+
+	query := `query($episode: Episode) { hero(episode: $episode) { name } }`
+	if errs := s.ValidateWithVariables(query, map[string]interface{}{"episode": "JEDI"}); len(errs) != 0 {
+		t.Errorf("got errors for valid variables: %v", errs)
+	}
+	errs = s.ValidateWithVariables(query, map[string]interface{}{"episode": "UNKNOWN"})
+	if len(errs) != 1 || errs[0].Rule != "VariablesOfCorrectType" {
+		t.Errorf("got errors %v, want a single VariablesOfCorrectType error", errs)
+	}
+
+	// Without variables, required variables are missing.
+	errs = s.Validate(`query($id: ID!) { human(id: $id) { name } }`)
+	if len(errs) != 1 || errs[0].Rule != "VariablesOfCorrectType" {
+		t.Errorf("got errors %v, want a single VariablesOfCorrectType error", errs)
+	}
+	if _, errs := s.ParseDocument(`query($id: ID!) { human(id: $id) { name } }`); len(errs) != 0 {
+		t.Errorf("got errors for a valid document: %v", errs)
+	}
+}
+
 func TestSubscriptions_In_Exec(t *testing.T) {
 	r := &struct {
 		*helloResolver
@@ -3748,10 +4157,6118 @@ func TestPointerReturnForNonNull(t *testing.T) {
 			`,
 			ExpectedErrors: []*gqlerrors.QueryError{
 				{
-					Message: `graphql: got nil for non-null "Hello"`,
-					Path:    []interface{}{"pointerReturn", "value"},
+					Message:   `graphql: got nil for non-null "Hello"`,
+					Path:      []interface{}{"pointerReturn", "value"},
+					Locations: []gqlerrors.Location{{Line: 4, Column: 7}},
+				},
+			},
+		},
+	})
+}
+
+func TestQuerySizeLimits(t *testing.T) {
+	t.Parallel()
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema:         graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}, graphql.MaxTokens(6)),
+			Query:          `{ hero { name } }`,
+			ExpectedResult: `{ "hero": { "name": "R2-D2" } }`,
+		},
+		{
+			Schema: graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}, graphql.MaxTokens(5)),
+			Query:  `{ hero { name } }`,
+			ExpectedErrors: []*gqlerrors.QueryError{
+				{
+					Message:   "document exceeds the maximum of 5 tokens",
+					Locations: []gqlerrors.Location{{Line: 1, Column: 17}},
 				},
 			},
 		},
+		{
+			Schema: graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}, graphql.MaxQueryBytes(16)),
+			Query:  `{ hero { name } }`,
+			ExpectedErrors: []*gqlerrors.QueryError{
+				{Message: "document exceeds the maximum size of 16 bytes"},
+			},
+		},
 	})
+
+	// The limits apply to the documents of clients, not to the introspection query of ToJSON.
+	schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{},
+		graphql.MaxQueryBytes(1000),
+		graphql.MaxTokens(100),
+		graphql.DeduplicateRequests(nil),
+	)
+	if _, err := schema.ToJSON(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := schema.CacheKey(`{ hero { name } }`, "", nil); err != nil {
+		t.Fatal(err)
+	}
+	gqltesting.RunTest(t, &gqltesting.Test{
+		Schema:         schema,
+		Query:          `{ hero { name } }`,
+		ExpectedResult: `{ "hero": { "name": "R2-D2" } }`,
+	})
+}
+
+func TestValidationDepthLimits(t *testing.T) {
+	t.Parallel()
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}, graphql.MaxFragmentDepth(2)),
+			Query: `
+				{ hero { ...characterName } }
+				fragment characterName on Character { ...name }
+				fragment name on Character { name }
+			`,
+			ExpectedResult: `{ "hero": { "name": "R2-D2" } }`,
+		},
+		{
+			Schema: graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}, graphql.MaxFragmentDepth(1)),
+			Query: `
+				{ hero { ...characterName } }
+				fragment characterName on Character { ...name }
+				fragment name on Character { name }
+			`,
+			ExpectedErrors: []*gqlerrors.QueryError{
+				{
+					Message:   `Fragment spread "characterName" has nesting depth 2 that exceeds max fragment depth 1`,
+					Locations: []gqlerrors.Location{{Line: 2, Column: 14}, {Line: 3, Column: 43}},
+					Rule:      "MaxFragmentDepthExceeded",
+				},
+			},
+		},
+		{
+			// The cycle is reported by NoFragmentCycles, not by the depth limits.
+			Schema: graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}, graphql.MaxDepth(5), graphql.MaxFragmentDepth(2)),
+			Query: `
+				{ hero { ...a } }
+				fragment a on Character { ...b }
+				fragment b on Character { ...a }
+			`,
+			ExpectedErrors: []*gqlerrors.QueryError{
+				{
+					Message:   `Cannot spread fragment "a" within itself via b.`,
+					Locations: []gqlerrors.Location{{Line: 3, Column: 31}, {Line: 4, Column: 31}},
+					Rule:      "NoFragmentCycles",
+				},
+			},
+		},
+		{
+			Schema: graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}, graphql.MaxInputDepth(1)),
+			Query: `
+				mutation {
+					createReview(episode: JEDI, review: {stars: 5, commentary: {text: ["great"]}}) {
+						stars
+					}
+				}
+			`,
+			ExpectedErrors: []*gqlerrors.QueryError{
+				{
+					Message:   "Value is nested deeper than max input depth 1",
+					Locations: []gqlerrors.Location{{Line: 3, Column: 65}},
+					Rule:      "MaxInputDepthExceeded",
+				},
+			},
+		},
+		{
+			Schema: graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}, graphql.MaxInputDepth(1)),
+			Query: `
+				mutation($review: ReviewInput!) {
+					createReview(episode: JEDI, review: $review) {
+						stars
+					}
+				}
+			`,
+			Variables: map[string]interface{}{
+				"review": map[string]interface{}{
+					"stars":      5,
+					"commentary": []interface{}{"great"},
+				},
+			},
+			ExpectedErrors: []*gqlerrors.QueryError{
+				{
+					Message:   `Variable "$review" has a value nested deeper than max input depth 1`,
+					Locations: []gqlerrors.Location{{Line: 2, Column: 14}},
+					Rule:      "MaxInputDepthExceeded",
+				},
+			},
+		},
+	})
+}
+
+type complexityLimiter struct {
+	max int
+
+	mu  sync.Mutex
+	ops []graphql.OperationInfo
+}
+
+func (l *complexityLimiter) Allow(ctx context.Context, op *graphql.OperationInfo) error {
+	l.mu.Lock()
+	l.ops = append(l.ops, graphql.OperationInfo{Name: op.Name, Type: op.Type, Complexity: op.Complexity})
+	l.mu.Unlock()
+	if op.Complexity > l.max {
+		return &gqlerrors.QueryError{
+			Message:    "rate limit exceeded",
+			Extensions: map[string]interface{}{"code": "RATE_LIMITED"},
+		}
+	}
+	return nil
+}
+
+func TestRateLimit(t *testing.T) {
+	t.Parallel()
+
+	limiter := &complexityLimiter{max: 4}
+	schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}, graphql.RateLimit(limiter))
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: schema,
+			Query: `
+				query Cheap {
+					hero {
+						name
+						...friends
+					}
+				}
+
+				fragment friends on Character {
+					friends {
+						name
+					}
+				}
+			`,
+			ExpectedResult: `
+				{
+					"hero": {
+						"name": "R2-D2",
+						"friends": [
+							{ "name": "Luke Skywalker" },
+							{ "name": "Han Solo" },
+							{ "name": "Leia Organa" }
+						]
+					}
+				}
+			`,
+		},
+		{
+			Schema: schema,
+			Query: `
+				mutation Expensive {
+					createReview(episode: JEDI, review: {stars: 5}) {
+						stars
+						commentary
+						stars2: stars
+						commentary2: commentary
+					}
+				}
+			`,
+			ExpectedErrors: []*gqlerrors.QueryError{
+				{
+					Message:    "rate limit exceeded",
+					Extensions: map[string]interface{}{"code": "RATE_LIMITED"},
+				},
+			},
+		},
+	})
+
+	want := []graphql.OperationInfo{
+		{Name: "Cheap", Type: "query", Complexity: 4},
+		{Name: "Expensive", Type: "mutation", Complexity: 5},
+	}
+	if !reflect.DeepEqual(limiter.ops, want) {
+		t.Errorf("unexpected operations: got %+v, want %+v", limiter.ops, want)
+	}
+}
+
+type operationInfoResolver struct {
+	mu   sync.Mutex
+	info *graphql.OperationInfo
+}
+
+func (r *operationInfoResolver) Hello(ctx context.Context) string {
+	r.mu.Lock()
+	r.info = graphql.OperationInfoFromContext(ctx)
+	r.mu.Unlock()
+	return "Hello world!"
+}
+
+func TestOperationInfo(t *testing.T) {
+	t.Parallel()
+
+	r := &operationInfoResolver{}
+	schema := graphql.MustParseSchema(`
+		type Query {
+			hello: String!
+		}
+	`, r)
+
+	query := `
+		query Info($v: Boolean!) {
+			hello @include(if: $v)
+			...fields
+		}
+
+		fragment fields on Query {
+			alias: hello
+		}
+	`
+	gqltesting.RunTest(t, &gqltesting.Test{
+		Context:        graphql.WithRequestExtensions(graphql.WithClientInfo(context.Background(), graphql.ClientInfo{Name: "web", Version: "1.2.3"}), map[string]interface{}{"traceId": "abc"}),
+		Schema:         schema,
+		Query:          query,
+		Variables:      map[string]interface{}{"v": true},
+		ExpectedResult: `{ "hello": "Hello world!", "alias": "Hello world!" }`,
+	})
+
+	hash := sha256.Sum256([]byte(query))
+	want := &graphql.OperationInfo{
+		Name:          "Info",
+		Type:          "query",
+		DocumentHash:  hex.EncodeToString(hash[:]),
+		Complexity:    2,
+		VariablesSize: len(`{"v":true}`),
+		Client:        graphql.ClientInfo{Name: "web", Version: "1.2.3"},
+		Extensions:    map[string]interface{}{"traceId": "abc"},
+		Stats:         graphql.DocumentStats{Fragments: 1, MaxDepth: 1, Fields: 2, Aliases: 1},
+	}
+	if !reflect.DeepEqual(r.info, want) {
+		t.Errorf("unexpected operation info: got %+v, want %+v", r.info, want)
+	}
+}
+
+func TestDocumentStatsExtension(t *testing.T) {
+	t.Parallel()
+
+	schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}, graphql.DocumentStatsExtension())
+	resp := schema.Exec(context.Background(), `
+		{
+			hero {
+				...names
+				friends {
+					...names
+					buddies: friends {
+						...names
+					}
+				}
+			}
+		}
+
+		fragment names on Character {
+			name
+			id
+		}
+	`, "", nil)
+	if len(resp.Errors) != 0 {
+		t.Fatal(resp.Errors)
+	}
+
+	want := map[string]interface{}{
+		"documentStats": map[string]interface{}{
+			"fragments":  1,
+			"maxDepth":   4,
+			"fields":     5,
+			"aliases":    1,
+			"complexity": 9,
+		},
+	}
+	if !reflect.DeepEqual(resp.Extensions, want) {
+		t.Errorf("unexpected extensions %v", resp.Extensions)
+	}
+
+	resp = graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}).Exec(context.Background(), `{ hero { name } }`, "", nil)
+	if resp.Extensions != nil {
+		t.Errorf("unexpected extensions %v", resp.Extensions)
+	}
+}
+
+func TestSourcePosition(t *testing.T) {
+	t.Parallel()
+
+	schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{})
+	queryString := `
+		query Hero {
+			hero {
+				...names
+				buddies: friends {
+					... on Droid { function: primaryFunction }
+					...names
+				}
+			}
+		}
+
+		fragment names on Character {
+			name
+		}
+	`
+
+	for _, tc := range []struct {
+		path []interface{}
+		want *graphql.SourcePosition
+		err  string
+	}{
+		{
+			path: []interface{}{"hero", "name"},
+			want: &graphql.SourcePosition{
+				Locations:  []gqlerrors.Location{{Line: 13, Column: 4}},
+				Coordinate: "Character.name",
+			},
+		},
+		{
+			path: []interface{}{"hero", "buddies", float64(2), "function"},
+			want: &graphql.SourcePosition{
+				Locations:  []gqlerrors.Location{{Line: 6, Column: 21}},
+				Coordinate: "Droid.primaryFunction",
+			},
+		},
+		{
+			path: []interface{}{"hero", "buddies"},
+			want: &graphql.SourcePosition{
+				Locations:  []gqlerrors.Location{{Line: 5, Column: 5}},
+				Coordinate: "Character.friends",
+			},
+		},
+		{
+			path: []interface{}{"hero", "friends"},
+			err:  `no field "friends" at path [hero friends]`,
+		},
+	} {
+		pos, err := schema.SourcePosition(queryString, "Hero", tc.path)
+		if tc.err != "" {
+			if err == nil || err.Error() != tc.err {
+				t.Errorf("%v: expected error %q, got %v", tc.path, tc.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%v: %s", tc.path, err)
+		}
+		tc.want.Path = tc.path
+		if !reflect.DeepEqual(pos, tc.want) {
+			t.Errorf("%v: unexpected position %+v", tc.path, pos)
+		}
+	}
+}
+
+func TestSourceMapExtension(t *testing.T) {
+	t.Parallel()
+
+	schema := graphql.MustParseSchema(`
+		schema {
+			query: Query
+		}
+
+		type Query {
+			findDroids: [Droid]!
+		}
+		type Droid {
+			name: String!
+		}
+	`, &findDroidsResolver{}, graphql.SourceMapExtension())
+
+	resp := schema.Exec(context.Background(), `{ findDroids { name } }`, "", nil)
+	want := map[string]interface{}{
+		"sourceMap": []*graphql.SourcePosition{
+			{
+				Path:       []interface{}{"findDroids", 1, "name"},
+				Locations:  []gqlerrors.Location{{Line: 1, Column: 16}},
+				Coordinate: "Droid.name",
+			},
+		},
+	}
+	if !reflect.DeepEqual(resp.Extensions, want) {
+		t.Errorf("unexpected extensions %+v", resp.Extensions)
+	}
+}
+
+func TestSchemaCoordinates(t *testing.T) {
+	t.Parallel()
+
+	schema := graphql.MustParseSchema(starwars.Schema, nil)
+
+	for _, tc := range []struct {
+		coordinate string
+		want       graphql.SchemaCoordinate
+		member     func(m *graphql.SchemaMember) string
+		wantMember string
+		err        string
+	}{
+		{
+			coordinate: "Human",
+			want:       graphql.SchemaCoordinate{Type: "Human"},
+			member:     func(m *graphql.SchemaMember) string { return *m.Type.Name() },
+			wantMember: "Human",
+		},
+		{
+			coordinate: "Query.hero(episode:)",
+			want:       graphql.SchemaCoordinate{Type: "Query", Member: "hero", Argument: "episode"},
+			member:     func(m *graphql.SchemaMember) string { return m.Field.Name() + "/" + m.Argument.Name() },
+			wantMember: "hero/episode",
+		},
+		{
+			coordinate: "Episode.JEDI",
+			want:       graphql.SchemaCoordinate{Type: "Episode", Member: "JEDI"},
+			member:     func(m *graphql.SchemaMember) string { return m.EnumValue.Name() },
+			wantMember: "JEDI",
+		},
+		{
+			coordinate: "ReviewInput.stars",
+			want:       graphql.SchemaCoordinate{Type: "ReviewInput", Member: "stars"},
+			member:     func(m *graphql.SchemaMember) string { return m.InputField.Name() },
+			wantMember: "stars",
+		},
+		{
+			coordinate: "@deprecated(reason:)",
+			want:       graphql.SchemaCoordinate{Directive: "deprecated", Argument: "reason"},
+			member:     func(m *graphql.SchemaMember) string { return m.Directive.Name() + "/" + m.Argument.Name() },
+			wantMember: "deprecated/reason",
+		},
+		{
+			coordinate: "Query.hero(episode)",
+			err:        `invalid schema coordinate "Query.hero(episode)", expected an argument like "(name:)"`,
+		},
+		{
+			coordinate: "Human(id:)",
+			err:        `invalid schema coordinate "Human(id:)", unexpected "(id:)"`,
+		},
+		{
+			coordinate: "Query.hero(first:)",
+			err:        `argument "first" of "Query.hero" is not defined`,
+		},
+		{
+			coordinate: "Droid.height",
+			err:        `"height" is not defined on type "Droid"`,
+		},
+	} {
+		c, err := graphql.ParseSchemaCoordinate(tc.coordinate)
+		if err == nil {
+			var m *graphql.SchemaMember
+			m, err = schema.ResolveCoordinate(c)
+			if err == nil {
+				if c != tc.want || c.String() != tc.coordinate {
+					t.Errorf("%s: unexpected coordinate %+v", tc.coordinate, c)
+				}
+				if got := tc.member(m); got != tc.wantMember {
+					t.Errorf("%s: unexpected member %s", tc.coordinate, got)
+				}
+			}
+		}
+		if tc.err == "" && err != nil {
+			t.Errorf("%s: %s", tc.coordinate, err)
+		}
+		if tc.err != "" && (err == nil || err.Error() != tc.err) {
+			t.Errorf("%s: expected error %q, got %v", tc.coordinate, tc.err, err)
+		}
+	}
+}
+
+func TestFieldUsage(t *testing.T) {
+	t.Parallel()
+
+	snapshot := &graphql.UsageSnapshot{}
+	var statsd bytes.Buffer
+	var ops []string
+	schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}, graphql.FieldUsage(
+		snapshot,
+		&graphql.StatsdUsage{Writer: &statsd, Prefix: "usage."},
+		graphql.UsageFunc(func(ctx context.Context, op *graphql.OperationInfo, usage map[string]int) {
+			ops = append(ops, op.Name)
+		}),
+	))
+
+	queryString := `
+		query Heroes($episode: Episode, $withFriends: Boolean!) {
+			hero(episode: $episode) {
+				...names
+				friends @include(if: $withFriends) {
+					...names
+				}
+			}
+			empire: hero(episode: EMPIRE) {
+				__typename
+				... on Human { height(unit: FOOT) }
+			}
+		}
+
+		fragment names on Character {
+			name
+		}
+	`
+	resp := schema.Exec(context.Background(), queryString, "", map[string]interface{}{"episode": "JEDI", "withFriends": true})
+	if len(resp.Errors) != 0 {
+		t.Fatal(resp.Errors)
+	}
+	if resp := schema.Exec(context.Background(), `{ unknown }`, "", nil); len(resp.Errors) == 0 {
+		t.Fatal("expected validation error")
+	}
+
+	want := map[string]int64{
+		"Query.hero":           2,
+		"Query.hero(episode:)": 2,
+		"Episode.JEDI":         1,
+		"Episode.EMPIRE":       1,
+		"Character.name":       1,
+		"Character.friends":    1,
+		"@include":             1,
+		"@include(if:)":        1,
+		"Human.height":         1,
+		"Human.height(unit:)":  1,
+		"LengthUnit.FOOT":      1,
+	}
+	if got := snapshot.Snapshot(); !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected usage %v", got)
+	}
+	if !reflect.DeepEqual(ops, []string{"Heroes"}) {
+		t.Errorf("unexpected operations %v", ops)
+	}
+	if !strings.Contains(statsd.String(), "usage.Query.hero.episode:2|c\n") || !strings.Contains(statsd.String(), "usage.directive.include.if:1|c\n") {
+		t.Errorf("unexpected statsd packet %q", statsd.String())
+	}
+
+	if got := snapshot.Reset(); len(got) != len(want) || len(snapshot.Snapshot()) != 0 {
+		t.Errorf("unexpected reset %v", got)
+	}
+}
+
+type deprecatedResolver struct{}
+
+func (deprecatedResolver) User() deprecatedUserResolver { return deprecatedUserResolver{} }
+
+type deprecatedUserResolver struct{}
+
+func (deprecatedUserResolver) Name() string     { return "Alice" }
+func (deprecatedUserResolver) FullName() string { return "Alice Liddell" }
+func (deprecatedUserResolver) Avatar(args struct {
+	Size   *int32
+	Pixels *int32
+}) string {
+	return "alice.png"
+}
+func (deprecatedUserResolver) Role() string { return "ADMIN" }
+
+func TestEnforceDeprecations(t *testing.T) {
+	t.Parallel()
+
+	const schemaString = `
+		schema {
+			query: Query
+		}
+
+		type Query {
+			user: User!
+		}
+
+		type User {
+			name: String! @deprecated(reason: "Use fullName.")
+			fullName: String!
+			avatar(size: Int @deprecated, pixels: Int): String!
+			role: Role!
+		}
+
+		enum Role {
+			ADMIN @deprecated(reason: "Use OWNER.")
+			OWNER
+		}
+	`
+	schema := graphql.MustParseSchema(schemaString, &deprecatedResolver{},
+		graphql.EnforceDeprecations(graphql.DeprecationWarn),
+		graphql.EnforceDeprecations(graphql.DeprecationReject, "ios"),
+	)
+
+	resp := schema.Exec(context.Background(), `{ user { name fullName avatar(size: 32) } }`, "", nil)
+	if len(resp.Errors) != 0 {
+		t.Fatal(resp.Errors)
+	}
+	want := map[string]interface{}{
+		"deprecations": []graphql.DeprecatedUsage{
+			{Coordinate: "User.avatar(size:)", Reason: "No longer supported"},
+			{Coordinate: "User.name", Reason: "Use fullName."},
+		},
+	}
+	if !reflect.DeepEqual(resp.Extensions, want) {
+		t.Errorf("unexpected extensions %v", resp.Extensions)
+	}
+
+	resp = schema.Exec(context.Background(), `{ user { fullName avatar(pixels: 32) role } }`, "", nil)
+	if len(resp.Errors) != 0 || resp.Extensions != nil {
+		t.Errorf("unexpected response %+v", resp)
+	}
+
+	ctx := graphql.WithClientInfo(context.Background(), graphql.ClientInfo{Name: "ios"})
+	gqltesting.RunTest(t, &gqltesting.Test{
+		Context: ctx,
+		Schema:  schema,
+		Query: `
+			{
+				user { fullName }
+				admin: user @skip(if: true) { role }
+				filtered: user @include(if: false) { fullName }
+				...F
+			}
+			fragment F on Query { user { fullName } }
+		`,
+		ExpectedResult: `{ "user": { "fullName": "Alice Liddell" } }`,
+	})
+
+	resp = schema.Exec(ctx, `query($size: Int) { user { avatar(size: $size) name } }`, "", nil)
+	wantErr := &gqlerrors.QueryError{
+		Message: `"User.avatar(size:)" is deprecated: No longer supported`,
+		Extensions: map[string]interface{}{
+			"code": "DEPRECATED",
+			"deprecations": []graphql.DeprecatedUsage{
+				{Coordinate: "User.avatar(size:)", Reason: "No longer supported"},
+				{Coordinate: "User.name", Reason: "Use fullName."},
+			},
+		},
+	}
+	if len(resp.Errors) != 1 || !reflect.DeepEqual(resp.Errors[0], wantErr) || resp.Data != nil {
+		t.Errorf("unexpected response %+v", resp)
+	}
+
+	resp = schema.Exec(context.Background(), `{ user { name } }`, "", nil)
+	if len(resp.Errors) != 0 {
+		t.Errorf("unexpected errors %v", resp.Errors)
+	}
+}
+
+func TestClientPolicies(t *testing.T) {
+	t.Parallel()
+
+	const schemaString = `
+		type Query {
+			user: User!
+		}
+
+		type User {
+			name: String! @deprecated(reason: "Use fullName.")
+			fullName: String!
+			avatar(size: Int @deprecated, pixels: Int): String!
+			role: Role!
+		}
+
+		enum Role {
+			ADMIN @deprecated(reason: "Use OWNER.")
+			OWNER
+		}
+	`
+	const persisted = `{ user { fullName avatar role } }`
+	schema := graphql.MustParseSchema(schemaString, &deprecatedResolver{},
+		graphql.PersistedOperations(persisted),
+		graphql.ClientPolicies(map[string]graphql.ClientPolicy{
+			"":    {MaxComplexity: 3},
+			"web": {RejectDeprecated: true},
+			"ios": {PersistedOnly: true},
+		}),
+	)
+	web := graphql.WithClientInfo(context.Background(), graphql.ClientInfo{Name: "web"})
+	ios := graphql.WithClientInfo(context.Background(), graphql.ClientInfo{Name: "ios"})
+
+	for _, tt := range []struct {
+		name    string
+		ctx     context.Context
+		query   string
+		wantErr *gqlerrors.QueryError
+	}{
+		{
+			name:  "anonymous within complexity",
+			ctx:   context.Background(),
+			query: `{ user { name fullName } }`,
+		},
+		{
+			name:  "anonymous exceeding complexity",
+			ctx:   context.Background(),
+			query: `{ user { name fullName role } }`,
+			wantErr: &gqlerrors.QueryError{
+				Message:    `operation has complexity 4, which exceeds the max complexity 3 of client ""`,
+				Extensions: map[string]interface{}{"code": "COMPLEXITY_EXCEEDED"},
+			},
+		},
+		{
+			name:  "web without deprecations",
+			ctx:   web,
+			query: `{ user { fullName avatar(pixels: 32) role } }`,
+		},
+		{
+			name:  "web with deprecations",
+			ctx:   web,
+			query: `{ user { name } }`,
+			wantErr: &gqlerrors.QueryError{
+				Message: `"User.name" is deprecated: Use fullName.`,
+				Extensions: map[string]interface{}{
+					"code":         "DEPRECATED",
+					"deprecations": []graphql.DeprecatedUsage{{Coordinate: "User.name", Reason: "Use fullName."}},
+				},
+			},
+		},
+		{
+			name:  "ios persisted",
+			ctx:   ios,
+			query: persisted,
+		},
+		{
+			name:  "ios not persisted",
+			ctx:   ios,
+			query: `{ user { fullName } }`,
+			wantErr: &gqlerrors.QueryError{
+				Message:    `client "ios" may only execute persisted operations`,
+				Extensions: map[string]interface{}{"code": "PERSISTED_OPERATION_REQUIRED"},
+			},
+		},
+	} {
+		resp := schema.Exec(tt.ctx, tt.query, "", nil)
+		if tt.wantErr == nil {
+			if len(resp.Errors) != 0 {
+				t.Errorf("%s: unexpected errors %v", tt.name, resp.Errors)
+			}
+			continue
+		}
+		if len(resp.Errors) != 1 || !reflect.DeepEqual(resp.Errors[0], tt.wantErr) || resp.Data != nil {
+			t.Errorf("%s: unexpected response %+v", tt.name, resp)
+		}
+	}
+}
+
+type roleKey struct{}
+
+type redactionResolver struct {
+	emailCalls int32
+}
+
+func (r *redactionResolver) User() *redactionUserResolver {
+	return &redactionUserResolver{r}
+}
+
+type redactionUserResolver struct {
+	r *redactionResolver
+}
+
+func (u *redactionUserResolver) Name() string {
+	return "Alice"
+}
+
+func (u *redactionUserResolver) Email() *string {
+	atomic.AddInt32(&u.r.emailCalls, 1)
+	email := "alice@example.com"
+	return &email
+}
+
+func (u *redactionUserResolver) Ssn() string {
+	return "123-45-6789"
+}
+
+func TestRedaction(t *testing.T) {
+	t.Parallel()
+
+	schemaString := `
+		directive @redact(when: String!, placeholder: String) on FIELD_DEFINITION
+
+		type Query {
+			user: User!
+		}
+
+		type User {
+			name: String!
+			email: String @redact(when: "notAdmin")
+			ssn: String! @redact(when: "notAdmin", placeholder: "***")
+		}
+	`
+	notAdmin := graphql.RedactionPredicate("notAdmin", func(ctx context.Context) bool {
+		return ctx.Value(roleKey{}) != "admin"
+	})
+	r := &redactionResolver{}
+	schema := graphql.MustParseSchema(schemaString, r, notAdmin)
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema:         schema,
+			Query:          `{ user { name email ssn } }`,
+			ExpectedResult: `{ "user": { "name": "Alice", "email": null, "ssn": "***" } }`,
+		},
+		{
+			Context:        context.WithValue(context.Background(), roleKey{}, "admin"),
+			Schema:         schema,
+			Query:          `{ user { name email ssn } }`,
+			ExpectedResult: `{ "user": { "name": "Alice", "email": "alice@example.com", "ssn": "123-45-6789" } }`,
+		},
+	})
+	if n := atomic.LoadInt32(&r.emailCalls); n != 1 {
+		t.Errorf("expected the redacted resolver to be called once, got %d calls", n)
+	}
+
+	for _, test := range []struct {
+		schema string
+		opts   []graphql.SchemaOpt
+		err    string
+	}{
+		{
+			schema: schemaString,
+			err:    `redaction predicate "notAdmin" of field "User.email" is not registered`,
+		},
+		{
+			schema: strings.Replace(schemaString, `, placeholder: "***"`, "", 1),
+			opts:   []graphql.SchemaOpt{notAdmin},
+			err:    `directive @redact on non-null field "ssn" requires a placeholder`,
+		},
+		{
+			schema: strings.Replace(schemaString, `name: String!`, `name: Int @redact(when: "notAdmin", placeholder: "0")`, 1),
+			opts:   []graphql.SchemaOpt{notAdmin},
+			err:    `directive @redact on field "name": a placeholder is only supported on String and ID fields`,
+		},
+	} {
+		if _, err := graphql.ParseSchema(test.schema, r, test.opts...); err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("expected error %q, got %v", test.err, err)
+		}
+	}
+}
+
+type lookaheadResolver struct {
+	mu        sync.Mutex
+	selected  map[string][]*graphql.SelectedField
+	typeNames map[string]string
+}
+
+func (r *lookaheadResolver) record(ctx context.Context, field string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.selected[field] = graphql.SelectedFieldsFromContext(ctx)
+	r.typeNames[field] = graphql.TypeNameFromContext(ctx)
+}
+
+func (r *lookaheadResolver) Node(ctx context.Context) *lookaheadNode {
+	r.record(ctx, "node")
+	return &lookaheadNode{&lookaheadUser{r}}
+}
+
+type lookaheadNode struct {
+	user *lookaheadUser
+}
+
+func (n *lookaheadNode) ID() graphql.ID {
+	return n.user.ID()
+}
+
+func (n *lookaheadNode) Friends(ctx context.Context, args friendsArgs) []*lookaheadNode {
+	return n.user.Friends(ctx, args)
+}
+
+func (n *lookaheadNode) ToUser() (*lookaheadUser, bool) {
+	return n.user, true
+}
+
+type lookaheadUser struct {
+	r *lookaheadResolver
+}
+
+func (u *lookaheadUser) ID() graphql.ID {
+	return "1"
+}
+
+func (u *lookaheadUser) Name() string {
+	return "Alice"
+}
+
+type friendsArgs struct {
+	First int32
+	After *graphql.ID
+}
+
+func (u *lookaheadUser) Friends(ctx context.Context, args friendsArgs) []*lookaheadNode {
+	u.r.record(ctx, "friends")
+	return []*lookaheadNode{}
+}
+
+func TestSelectedFieldsFromContext(t *testing.T) {
+	t.Parallel()
+
+	schemaString := `
+		type Query {
+			node: Node!
+		}
+
+		interface Node {
+			id: ID!
+			friends(first: Int = 10, after: ID): [Node!]!
+		}
+
+		type User implements Node {
+			id: ID!
+			name: String!
+			friends(first: Int = 10, after: ID): [Node!]!
+		}
+	`
+
+	for _, test := range []struct {
+		name      string
+		query     string
+		variables map[string]interface{}
+		selected  map[string][]*graphql.SelectedField
+		typeNames map[string]string
+	}{
+		{
+			name: "fragments and aliases",
+			query: `
+				{
+					node {
+						id
+						... on User {
+							name
+							friends {
+								id
+							}
+						}
+						alias: id
+						__typename
+					}
+				}
+			`,
+			selected: map[string][]*graphql.SelectedField{
+				"node": {
+					{Name: "id", Alias: "id"},
+					{Name: "name", Alias: "name", TypeCondition: "User"},
+					{
+						Name:           "friends",
+						Alias:          "friends",
+						Arguments:      map[string]interface{}{"first": int32(10)},
+						TypeCondition:  "User",
+						SelectedFields: []*graphql.SelectedField{{Name: "id", Alias: "id"}},
+					},
+					{Name: "id", Alias: "alias"},
+					{Name: "__typename", Alias: "__typename"},
+				},
+				"friends": {{Name: "id", Alias: "id"}},
+			},
+			typeNames: map[string]string{"node": "Query", "friends": "User"},
+		},
+		{
+			name:      "interface field with arguments",
+			query:     `query($after: ID) { node { friends(first: 2, after: $after) { __typename } } }`,
+			variables: map[string]interface{}{"after": "5"},
+			selected: map[string][]*graphql.SelectedField{
+				"node": {
+					{
+						Name:           "friends",
+						Alias:          "friends",
+						Arguments:      map[string]interface{}{"first": int32(2), "after": "5"},
+						SelectedFields: []*graphql.SelectedField{{Name: "__typename", Alias: "__typename"}},
+					},
+				},
+				"friends": {{Name: "__typename", Alias: "__typename"}},
+			},
+			typeNames: map[string]string{"node": "Query", "friends": "User"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			r := &lookaheadResolver{selected: make(map[string][]*graphql.SelectedField), typeNames: make(map[string]string)}
+			schema := graphql.MustParseSchema(schemaString, r)
+			if resp := schema.Exec(context.Background(), test.query, "", test.variables); len(resp.Errors) != 0 {
+				t.Fatal(resp.Errors)
+			}
+			if !reflect.DeepEqual(r.selected, test.selected) {
+				t.Errorf("unexpected selected fields: %s", toJSON(r.selected))
+			}
+			if !reflect.DeepEqual(r.typeNames, test.typeNames) {
+				t.Errorf("got type names %v, want %v", r.typeNames, test.typeNames)
+			}
+		})
+	}
+
+	if graphql.SelectedFieldsFromContext(context.Background()) != nil || graphql.TypeNameFromContext(context.Background()) != "" {
+		t.Error("expected no field info outside of resolvers")
+	}
+}
+
+type requestedResolver struct {
+	selected  []*graphql.SelectedField
+	requested []*graphql.SelectedField
+}
+
+func (r *requestedResolver) Node(ctx context.Context) *lookaheadNode {
+	r.selected = graphql.SelectedFieldsFromContext(ctx)
+	r.requested = graphql.RequestedFieldsFromContext(ctx)
+	return &lookaheadNode{&lookaheadUser{&lookaheadResolver{selected: make(map[string][]*graphql.SelectedField), typeNames: make(map[string]string)}}}
+}
+
+type skippedTracer struct {
+	trace.NoopTracer
+	skipped []string
+}
+
+func (t *skippedTracer) TraceSkippedField(ctx context.Context, typeName, fieldName string, args map[string]interface{}) {
+	t.skipped = append(t.skipped, fmt.Sprintf("%s.%s %v", typeName, fieldName, args))
+}
+
+func TestRequestedFieldsFromContext(t *testing.T) {
+	t.Parallel()
+
+	schemaString := `
+		type Query {
+			node: Node!
+		}
+
+		interface Node {
+			id: ID!
+			friends(first: Int = 10, after: ID): [Node!]!
+		}
+
+		type User implements Node {
+			id: ID!
+			name: String!
+			friends(first: Int = 10, after: ID): [Node!]!
+		}
+	`
+	query := `
+		query($skip: Boolean!) {
+			node {
+				id
+				friends(first: 2) @skip(if: $skip) {
+					id
+				}
+				... on User @include(if: false) {
+					name
+				}
+				id @skip(if: true)
+			}
+		}
+	`
+
+	r := &requestedResolver{}
+	tracer := &skippedTracer{}
+	schema := graphql.MustParseSchema(schemaString, r, graphql.Tracer(tracer), graphql.TraceSkippedFields())
+	gqltesting.RunTest(t, &gqltesting.Test{
+		Schema:         schema,
+		Query:          query,
+		Variables:      map[string]interface{}{"skip": true},
+		ExpectedResult: `{"node": {"id": "1"}}`,
+	})
+
+	if want := []*graphql.SelectedField{{Name: "id", Alias: "id"}}; !reflect.DeepEqual(r.selected, want) {
+		t.Errorf("unexpected selected fields: %s", toJSON(r.selected))
+	}
+	want := []*graphql.SelectedField{
+		{Name: "id", Alias: "id"},
+		{
+			Name:           "friends",
+			Alias:          "friends",
+			Arguments:      map[string]interface{}{"first": int32(2)},
+			SelectedFields: []*graphql.SelectedField{{Name: "id", Alias: "id", Skipped: true}},
+			Skipped:        true,
+		},
+		{Name: "name", Alias: "name", TypeCondition: "User", Skipped: true},
+	}
+	if !reflect.DeepEqual(r.requested, want) {
+		t.Errorf("unexpected requested fields: %s", toJSON(r.requested))
+	}
+	if want := []string{"Node.friends map[first:2]", "Node.name map[]", "Node.id map[]"}; !reflect.DeepEqual(tracer.skipped, want) {
+		t.Errorf("unexpected skipped fields %q", tracer.skipped)
+	}
+}
+
+func toJSON(v interface{}) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+type rawJSONResolver struct{}
+
+func (r *rawJSONResolver) User() graphql.RawJSON {
+	return graphql.RawJSON(`{"name":"Alice","friends":[{"name":"Bob"}]}`)
+}
+
+func (r *rawJSONResolver) Users() json.RawMessage {
+	return json.RawMessage(`[{"name":"Bob"}]`)
+}
+
+func (r *rawJSONResolver) Missing() graphql.RawJSON {
+	return nil
+}
+
+func TestRawJSON(t *testing.T) {
+	t.Parallel()
+
+	schemaString := `
+		type Query {
+			user: User
+			users: [User!]!
+			missing: User!
+		}
+
+		type User {
+			name: String!
+			friends: [User!]!
+		}
+	`
+	schema := graphql.MustParseSchema(schemaString, &rawJSONResolver{}, graphql.UseRawJSONMessages())
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema:         schema,
+			Query:          `{ user { name friends { name } } users { name } }`,
+			ExpectedResult: `{ "user": { "name": "Alice", "friends": [{ "name": "Bob" }] }, "users": [{ "name": "Bob" }] }`,
+		},
+		{
+			Schema:         schema,
+			Query:          `{ missing { name } }`,
+			ExpectedResult: `null`,
+			ExpectedErrors: []*gqlerrors.QueryError{
+				{
+					Message:   `graphql: got nil for non-null "User"`,
+					Path:      []interface{}{"missing"},
+					Locations: []gqlerrors.Location{{Line: 1, Column: 3}},
+				},
+			},
+		},
+	})
+
+	if _, err := graphql.ParseSchema(schemaString, &rawJSONResolver{}); err == nil {
+		t.Error("expected an error for json.RawMessage without UseRawJSONMessages")
+	}
+}
+
+type matrix [][]float64
+
+func (m matrix) MarshalGraphQL(w io.Writer, sels graphql.SelectionInfo) error {
+	b := []byte{'['}
+	for i, row := range m {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = append(b, '[')
+		for j, v := range row {
+			if j > 0 {
+				b = append(b, ',')
+			}
+			b = strconv.AppendFloat(b, v, 'g', -1, 64)
+		}
+		b = append(b, ']')
+	}
+	b = append(b, ']')
+	_, err := w.Write(b)
+	return err
+}
+
+type point struct {
+	x, y int
+}
+
+func (p *point) MarshalGraphQL(w io.Writer, sels graphql.SelectionInfo) error {
+	if p.x < 0 {
+		return errors.New("invalid point")
+	}
+	fmt.Fprint(w, "{")
+	for i, f := range sels.Fields {
+		if i > 0 {
+			fmt.Fprint(w, ",")
+		}
+		v := p.x
+		if f.Name == "y" {
+			v = p.y
+		}
+		fmt.Fprintf(w, "%q:%d", f.Alias, v)
+	}
+	fmt.Fprint(w, "}")
+	return nil
+}
+
+type marshalerResolver struct{}
+
+func (r *marshalerResolver) Matrix() matrix {
+	return matrix{{1, 2.5}, {3, 4}}
+}
+
+func (r *marshalerResolver) Point() *point {
+	return &point{1, 2}
+}
+
+func (r *marshalerResolver) Invalid() *point {
+	return &point{-1, 0}
+}
+
+func TestMarshaler(t *testing.T) {
+	t.Parallel()
+
+	schema := graphql.MustParseSchema(`
+		type Query {
+			matrix: [[Float!]!]!
+			point: Point
+			invalid: Point
+		}
+
+		type Point {
+			x: Int!
+			y: Int!
+		}
+	`, &marshalerResolver{})
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema:         schema,
+			Query:          `{ matrix point { y horizontal: x } }`,
+			ExpectedResult: `{ "matrix": [[1, 2.5], [3, 4]], "point": { "y": 2, "horizontal": 1 } }`,
+		},
+		{
+			Schema:         schema,
+			Query:          `{ invalid { x } }`,
+			ExpectedResult: `{ "invalid": null }`,
+			ExpectedErrors: []*gqlerrors.QueryError{
+				{
+					Message:       "invalid point",
+					Path:          []interface{}{"invalid"},
+					Locations:     []gqlerrors.Location{{Line: 1, Column: 3}},
+					ResolverError: errors.New("invalid point"),
+				},
+			},
+		},
+	})
+}
+
+type strictMatrixResolver struct{}
+
+func (r *strictMatrixResolver) Matrix() [][]string {
+	return nil
+}
+
+type strictEnumResolver struct{}
+
+func (r *strictEnumResolver) Episode() int {
+	return 0
+}
+
+func TestStrictResolverTypes(t *testing.T) {
+	t.Parallel()
+
+	_, err := graphql.ParseSchema(`
+		type Query {
+			matrix: [[Int!]!]!
+		}
+	`, &strictMatrixResolver{}, graphql.StrictResolverTypes())
+	if err == nil || !strings.Contains(err.Error(), "result type [][]string does not match [[Int!]!]!, expected [][]int32: can not use string as Int") {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	enumSchema := `
+		enum Episode {
+			NEWHOPE
+		}
+
+		type Query {
+			episode: Episode!
+		}
+	`
+	if _, err := graphql.ParseSchema(enumSchema, &strictEnumResolver{}); err != nil {
+		t.Errorf("unexpected error without strict mode: %s", err)
+	}
+	_, err = graphql.ParseSchema(enumSchema, &strictEnumResolver{}, graphql.StrictResolverTypes())
+	if err == nil || !strings.Contains(err.Error(), "int can not be used as enum Episode, it is neither a string nor a fmt.Stringer") {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	if _, err := graphql.ParseSchema(starwars.Schema, &starwars.Resolver{}, graphql.StrictResolverTypes()); err != nil {
+		t.Errorf("unexpected error for valid resolvers: %s", err)
+	}
+}
+
+type reportResolver struct {
+	Version string
+}
+
+func (r *reportResolver) Hello(ctx context.Context, args struct{ Name string }) (string, error) {
+	return "Hello " + args.Name, nil
+}
+
+func (r *reportResolver) Viewer() *reportUser {
+	return &reportUser{}
+}
+
+type reportUser struct{}
+
+func (u *reportUser) Name() string {
+	return "Alice"
+}
+
+func TestCheckResolvers(t *testing.T) {
+	t.Parallel()
+
+	schema := graphql.MustParseSchema(`
+		type Query {
+			hello(name: String!): String!
+			version: String!
+			viewer: User
+		}
+
+		type User {
+			name: String!
+		}
+	`, &reportResolver{}, graphql.UseFieldResolvers(), graphql.SerialQueryFields("hello"))
+
+	report, err := schema.CheckResolvers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `Query.hello: (*graphql_test.reportResolver).Hello(ctx, args) (string, error) async serial
+Query.version: (*graphql_test.reportResolver).Version string
+Query.viewer: (*graphql_test.reportResolver).Viewer() *graphql_test.reportUser
+User.name: (*graphql_test.reportUser).Name() string
+`
+	if got := report.String(); got != want {
+		t.Errorf("got report:\n%s\nwant:\n%s", got, want)
+	}
+
+	if _, err := graphql.MustParseSchema(`type Query { version: String! }`, nil).CheckResolvers(); err == nil {
+		t.Error("expected an error for a schema without resolver")
+	}
+}
+
+type userResolvers struct{}
+
+func (r *userResolvers) User(args struct{ ID graphql.ID }) *reportUser {
+	return &reportUser{}
+}
+
+func (r *userResolvers) RenameUser(ctx context.Context, args struct{ Name string }) (string, error) {
+	return args.Name, nil
+}
+
+type productResolvers struct {
+	Products []string
+}
+
+func (r *productResolvers) Product() string {
+	return "Keyboard"
+}
+
+func TestMergeResolvers(t *testing.T) {
+	t.Parallel()
+
+	schemaString := `
+		schema {
+			query: Query
+			mutation: Mutation
+		}
+
+		type Query {
+			user(id: ID!): User
+			product: String!
+			products: [String!]!
+		}
+
+		type Mutation {
+			renameUser(name: String!): String!
+		}
+
+		type User {
+			name: String!
+		}
+	`
+	schema := graphql.MustParseSchema(schemaString,
+		graphql.MergeResolvers(&userResolvers{}, &productResolvers{Products: []string{"Mouse"}}),
+		graphql.UseFieldResolvers())
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: schema,
+			Query: `
+				{
+					user(id: "1") {
+						name
+					}
+					product
+					products
+				}
+			`,
+			ExpectedResult: `
+				{
+					"user": {
+						"name": "Alice"
+					},
+					"product": "Keyboard",
+					"products": ["Mouse"]
+				}
+			`,
+		},
+		{
+			Schema: schema,
+			Query: `
+				mutation {
+					renameUser(name: "Bob")
+				}
+			`,
+			ExpectedResult: `
+				{
+					"renameUser": "Bob"
+				}
+			`,
+		},
+	})
+
+	report, err := schema.CheckResolvers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `Mutation.renameUser: (*graphql_test.userResolvers).RenameUser(ctx, args) (string, error) async
+Query.product: (*graphql_test.productResolvers).Product() string
+Query.products: (*graphql_test.productResolvers).Products []string
+Query.user: (*graphql_test.userResolvers).User(args) *graphql_test.reportUser async
+User.name: (*graphql_test.reportUser).Name() string
+`
+	if got := report.String(); got != want {
+		t.Errorf("got report:\n%s\nwant:\n%s", got, want)
+	}
+
+	_, err = graphql.ParseSchema(schemaString, graphql.MergeResolvers(&userResolvers{}, &productResolvers{}, &productResolvers{}), graphql.UseFieldResolvers())
+	if err == nil || !strings.Contains(err.Error(), `*graphql_test.productResolvers and *graphql_test.productResolvers both resolve field "product" of "Query"`) {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	_, err = graphql.ParseSchema(schemaString, graphql.MergeResolvers(&userResolvers{}), graphql.UseFieldResolvers())
+	if err == nil || !strings.Contains(err.Error(), `none of the resolvers *graphql_test.userResolvers resolves field "product" of "Query"`) {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+type viewerKey struct{}
+
+type requestResolver struct {
+	viewer string
+}
+
+func (r *requestResolver) Viewer() string {
+	return r.viewer
+}
+
+func TestResolverFactory(t *testing.T) {
+	t.Parallel()
+
+	schemaString := `
+		type Query {
+			viewer: String!
+		}
+	`
+	schema := graphql.MustParseSchema(schemaString, (*requestResolver)(nil),
+		graphql.ResolverFactory(func(ctx context.Context) (interface{}, error) {
+			viewer, ok := ctx.Value(viewerKey{}).(string)
+			if !ok {
+				return nil, fmt.Errorf("not authenticated")
+			}
+			return &requestResolver{viewer: viewer}, nil
+		}))
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Context: context.WithValue(context.Background(), viewerKey{}, "Alice"),
+			Schema:  schema,
+			Query: `
+				{
+					viewer
+				}
+			`,
+			ExpectedResult: `
+				{
+					"viewer": "Alice"
+				}
+			`,
+		},
+		{
+			Context: context.WithValue(context.Background(), viewerKey{}, "Bob"),
+			Schema:  schema,
+			Query: `
+				{
+					viewer
+				}
+			`,
+			ExpectedResult: `
+				{
+					"viewer": "Bob"
+				}
+			`,
+		},
+		{
+			Schema: schema,
+			Query: `
+				{
+					viewer
+				}
+			`,
+			ExpectedErrors: []*gqlerrors.QueryError{
+				{Message: "not authenticated", ResolverError: fmt.Errorf("not authenticated")},
+			},
+		},
+	})
+
+	schema = graphql.MustParseSchema(schemaString, (*requestResolver)(nil),
+		graphql.ResolverFactory(func(ctx context.Context) (interface{}, error) {
+			return &helloWorldResolver1{}, nil
+		}))
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: schema,
+			Query: `
+				{
+					viewer
+				}
+			`,
+			ExpectedErrors: []*gqlerrors.QueryError{
+				{Message: "resolver factory returned *graphql_test.helloWorldResolver1, expected *graphql_test.requestResolver"},
+			},
+		},
+	})
+}
+
+type requestContextResolver struct {
+	mu  sync.Mutex
+	ctx context.Context
+}
+
+func (r *requestContextResolver) Hello(ctx context.Context) string {
+	r.mu.Lock()
+	r.ctx = ctx
+	r.mu.Unlock()
+	return "Hello world!"
+}
+
+func TestRequestContext(t *testing.T) {
+	t.Parallel()
+
+	r := &requestContextResolver{}
+	schema := graphql.MustParseSchema(`
+		type Query {
+			hello: String!
+		}
+	`, r)
+
+	query := `
+		query Greeting($v: Boolean!) {
+			hello @include(if: $v)
+		}
+	`
+	before := time.Now()
+	gqltesting.RunTest(t, &gqltesting.Test{
+		Context:        requestcontext.WithClient(context.Background(), requestcontext.ClientInfo{Name: "web", Version: "1.2.3"}),
+		Schema:         schema,
+		Query:          query,
+		Variables:      map[string]interface{}{"v": true},
+		ExpectedResult: `{ "hello": "Hello world!" }`,
+	})
+
+	if name := requestcontext.OperationName(r.ctx); name != "Greeting" {
+		t.Errorf("unexpected operation name %q", name)
+	}
+	if q := requestcontext.Query(r.ctx); q != query {
+		t.Errorf("unexpected query %q", q)
+	}
+	if vars := requestcontext.Variables(r.ctx); !reflect.DeepEqual(vars, map[string]interface{}{"v": true}) {
+		t.Errorf("unexpected variables %v", vars)
+	}
+	if start, ok := requestcontext.StartTime(r.ctx); !ok || start.Before(before) {
+		t.Errorf("unexpected start time %v", start)
+	}
+	if client, _ := requestcontext.Client(r.ctx); client != (requestcontext.ClientInfo{Name: "web", Version: "1.2.3"}) {
+		t.Errorf("unexpected client %+v", client)
+	}
+	if info := graphql.OperationInfoFromContext(r.ctx); info.Client.Name != "web" {
+		t.Errorf("unexpected operation client %+v", info.Client)
+	}
+}
+
+type directiveResolver struct{}
+
+func (r *directiveResolver) Name(ctx context.Context) string {
+	name := "Keyboard"
+	for _, d := range graphql.DirectivesFromContext(ctx) {
+		if d.Name == "lowercase" {
+			name = strings.ToLower(name)
+		}
+	}
+	return name
+}
+
+func (r *directiveResolver) Price(ctx context.Context) string {
+	var formats []string
+	for _, d := range graphql.DirectivesFromContext(ctx) {
+		if d.Name == "currency" {
+			formats = append(formats, fmt.Sprintf("%v(%v)", d.Arguments["format"], d.Definition))
+		}
+	}
+	return "42 " + strings.Join(formats, " ")
+}
+
+func TestDirectivesFromContext(t *testing.T) {
+	t.Parallel()
+
+	schema := graphql.MustParseSchema(`
+		directive @lowercase on FIELD_DEFINITION
+		directive @currency(format: String = "USD") on FIELD | FIELD_DEFINITION
+
+		type Query {
+			name: String! @lowercase
+			price: String! @currency(format: "EUR")
+		}
+	`, &directiveResolver{})
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: schema,
+			Query: `
+				query($format: String) {
+					name
+					price
+					inDollars: price @currency
+					converted: price @currency(format: $format)
+				}
+			`,
+			Variables: map[string]interface{}{"format": "GBP"},
+			ExpectedResult: `
+				{
+					"name": "keyboard",
+					"price": "42 EUR(true)",
+					"inDollars": "42 USD(false) EUR(true)",
+					"converted": "42 GBP(false) EUR(true)"
+				}
+			`,
+		},
+	})
+}
+
+type paymentMethod interface {
+	describe() string
+}
+
+type cardPayment struct {
+	Number string
+}
+
+func (p cardPayment) describe() string {
+	return "card " + p.Number
+}
+
+type voucherPayment string
+
+func (p voucherPayment) describe() string {
+	return "voucher " + string(p)
+}
+
+type paymentResolver struct{}
+
+func (r *paymentResolver) Pay(args struct{ Input paymentMethod }) string {
+	switch m := args.Input.(type) {
+	case cardPayment, voucherPayment:
+		return m.describe()
+	default:
+		return "unknown"
+	}
+}
+
+func (r *paymentResolver) Refund(args struct{ Input paymentMethod }) string {
+	if args.Input == nil {
+		return "nothing"
+	}
+	return args.Input.describe()
+}
+
+func TestOneOfInput(t *testing.T) {
+	t.Parallel()
+
+	schemaString := `
+		directive @oneOf on INPUT_OBJECT
+
+		input CardInput {
+			number: String!
+		}
+
+		input PaymentInput @oneOf {
+			card: CardInput
+			voucher: String
+		}
+
+		type Query {
+			pay(input: PaymentInput!): String!
+			refund(input: PaymentInput): String!
+		}
+	`
+	opts := []graphql.SchemaOpt{
+		graphql.OneOfConstructor("PaymentInput", "card", func(card struct{ Number string }) paymentMethod {
+			return cardPayment{Number: card.Number}
+		}),
+		graphql.OneOfConstructor("PaymentInput", "voucher", func(code string) paymentMethod {
+			return voucherPayment(code)
+		}),
+	}
+	schema := graphql.MustParseSchema(schemaString, &paymentResolver{}, opts...)
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: schema,
+			Query: `
+				query($voucher: PaymentInput!) {
+					card: pay(input: {card: {number: "4242"}})
+					voucher: pay(input: $voucher)
+					refund(input: null)
+				}
+			`,
+			Variables: map[string]interface{}{"voucher": map[string]interface{}{"voucher": "SPRING"}},
+			ExpectedResult: `
+				{
+					"card": "card 4242",
+					"voucher": "voucher SPRING",
+					"refund": "nothing"
+				}
+			`,
+		},
+		{
+			Schema: schema,
+			Query: `
+				{
+					pay(input: {card: {number: "4242"}, voucher: "SPRING"})
+				}
+			`,
+			ExpectedErrors: []*gqlerrors.QueryError{{
+				Message:   "Argument \"input\" has invalid value {card: {number: \"4242\"}, voucher: \"SPRING\"}.\nExpected exactly one field of oneOf input \"PaymentInput\", found 2.",
+				Locations: []gqlerrors.Location{{Line: 3, Column: 17}},
+				Rule:      "ArgumentsOfCorrectType",
+			}},
+		},
+		{
+			Schema: schema,
+			Query: `
+				query($input: PaymentInput!) {
+					pay(input: $input)
+				}
+			`,
+			Variables: map[string]interface{}{"input": map[string]interface{}{"voucher": nil}},
+			ExpectedErrors: []*gqlerrors.QueryError{{
+				Message:    "Variable \"input\" has invalid value.\nExpected exactly one non-null field of oneOf input \"PaymentInput\".",
+				Locations:  []gqlerrors.Location{{Line: 2, Column: 11}},
+				Rule:       "VariablesOfCorrectType",
+				Extensions: map[string]interface{}{"pointer": "/input", "expectedType": "PaymentInput"},
+			}},
+		},
+	})
+
+	if _, err := graphql.ParseSchema(schemaString, &paymentResolver{}, opts[0]); err == nil || !strings.Contains(err.Error(), `no constructor registered for field "voucher" of oneOf input "PaymentInput"`) {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	_, err := graphql.ParseSchema(`
+		directive @oneOf on INPUT_OBJECT
+
+		input PaymentInput @oneOf {
+			voucher: String!
+		}
+
+		type Query {
+			pay(input: PaymentInput!): String!
+		}
+	`, &paymentResolver{}, opts...)
+	if err == nil || !strings.Contains(err.Error(), `field "voucher" of oneOf input "PaymentInput" must be nullable and have no default value`) {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+type coordinates struct {
+	Lat, Lng float64
+}
+
+func (coordinates) ImplementsGraphQLType(name string) bool {
+	return name == "Coordinates"
+}
+
+func (c *coordinates) UnmarshalGraphQL(input interface{}) error {
+	toFloat := func(v interface{}) (float64, bool) {
+		switch v := v.(type) {
+		case int32:
+			return float64(v), true
+		case float64:
+			return v, true
+		}
+		return 0, false
+	}
+	var lat, lng interface{}
+	switch input := input.(type) {
+	case []interface{}:
+		if len(input) != 2 {
+			return fmt.Errorf("expected 2 coordinates, got %d", len(input))
+		}
+		lat, lng = input[0], input[1]
+	case map[string]interface{}:
+		lat, lng = input["lat"], input["lng"]
+	default:
+		return fmt.Errorf("invalid coordinates %v", input)
+	}
+	var ok1, ok2 bool
+	c.Lat, ok1 = toFloat(lat)
+	c.Lng, ok2 = toFloat(lng)
+	if !ok1 || !ok2 {
+		return fmt.Errorf("invalid coordinates %v", input)
+	}
+	return nil
+}
+
+type jsonScalar struct {
+	Value interface{}
+}
+
+func (jsonScalar) ImplementsGraphQLType(name string) bool {
+	return name == "JSON"
+}
+
+func (j *jsonScalar) UnmarshalGraphQL(input interface{}) error {
+	j.Value = input
+	return nil
+}
+
+type literalResolver struct{}
+
+func (r *literalResolver) Distance(args struct{ From, To coordinates }) float64 {
+	return math.Abs(args.To.Lat-args.From.Lat) + math.Abs(args.To.Lng-args.From.Lng)
+}
+
+func (r *literalResolver) Echo(args struct{ Value jsonScalar }) string {
+	return fmt.Sprintf("%#v", args.Value.Value)
+}
+
+func TestCustomScalarLiterals(t *testing.T) {
+	t.Parallel()
+
+	schema := graphql.MustParseSchema(`
+		scalar Coordinates
+		scalar JSON
+
+		type Query {
+			distance(from: Coordinates!, to: Coordinates!): Float!
+			echo(value: JSON!): String!
+		}
+	`, &literalResolver{})
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: schema,
+			Query: `
+				query($to: Coordinates!) {
+					distance(from: [1, 2.5], to: $to)
+					objects: distance(from: {lat: 1, lng: 2.5}, to: {lat: 2, lng: 1})
+					enum: echo(value: NORTH)
+					nested: echo(value: {list: [1, "a", null], flag: true})
+				}
+			`,
+			Variables: map[string]interface{}{"to": []interface{}{2.0, 1.0}},
+			ExpectedResult: `
+				{
+					"distance": 2.5,
+					"objects": 2.5,
+					"enum": "\"NORTH\"",
+					"nested": "map[string]interface {}{\"flag\":true, \"list\":[]interface {}{1, \"a\", interface {}(nil)}}"
+				}
+			`,
+		},
+		{
+			Schema: schema,
+			Query: `
+				{
+					distance(from: [1, 2.5],
+						to: [1])
+				}
+			`,
+			ExpectedResult: "{}",
+			ExpectedErrors: []*gqlerrors.QueryError{{
+				Message:   "expected 2 coordinates, got 1",
+				Locations: []gqlerrors.Location{{Line: 4, Column: 11}},
+			}},
+		},
+	})
+}
+
+type numberResolver struct{}
+
+func (r *numberResolver) Small() int64 {
+	return 42
+}
+
+func (r *numberResolver) Large() *int64 {
+	n := int64(math.MaxInt32 + 1)
+	return &n
+}
+
+func (r *numberResolver) Unsigned() *uint64 {
+	n := uint64(math.MaxUint64)
+	return &n
+}
+
+func (r *numberResolver) Ratio() int {
+	return 3
+}
+
+func (r *numberResolver) Invalid() *float64 {
+	f := math.NaN()
+	return &f
+}
+
+func (r *numberResolver) Double(args struct{ N int32 }) int32 {
+	return 2 * args.N
+}
+
+func TestNumericCoercion(t *testing.T) {
+	t.Parallel()
+
+	schemaString := `
+		type Query {
+			small: Int!
+			large: Int
+			unsigned: Int
+			ratio: Float!
+			invalid: Float
+			double(n: Int!): Int!
+		}
+	`
+	schema := graphql.MustParseSchema(schemaString, &numberResolver{})
+	legacySchema := graphql.MustParseSchema(schemaString, &numberResolver{}, graphql.LegacyNumericCoercion())
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: schema,
+			Query: `
+				{
+					small
+					large
+					unsigned
+					ratio
+					invalid
+				}
+			`,
+			ExpectedResult: `
+				{
+					"small": 42,
+					"large": null,
+					"unsigned": null,
+					"ratio": 3,
+					"invalid": null
+				}
+			`,
+			ExpectedErrors: []*gqlerrors.QueryError{
+				{Message: "Int cannot represent non 32-bit signed integer value: 2147483648", Path: []interface{}{"large"}, Locations: []gqlerrors.Location{{Line: 4, Column: 6}}},
+				{Message: "Int cannot represent non 32-bit signed integer value: 18446744073709551615", Path: []interface{}{"unsigned"}, Locations: []gqlerrors.Location{{Line: 5, Column: 6}}},
+				{Message: "Float cannot represent non numeric value: NaN", Path: []interface{}{"invalid"}, Locations: []gqlerrors.Location{{Line: 7, Column: 6}}},
+			},
+		},
+		{
+			Schema: legacySchema,
+			Query: `
+				{
+					large
+				}
+			`,
+			ExpectedResult: `
+				{
+					"large": 2147483648
+				}
+			`,
+		},
+		{
+			Schema: schema,
+			Query: `
+				query($n: Int!) {
+					double(n: $n)
+				}
+			`,
+			Variables: map[string]interface{}{"n": 2.0},
+			ExpectedResult: `
+				{
+					"double": 4
+				}
+			`,
+		},
+		{
+			Schema: schema,
+			Query: `
+				query($n: Int!) {
+					double(n: $n)
+				}
+			`,
+			Variables: map[string]interface{}{"n": 2.5},
+			ExpectedErrors: []*gqlerrors.QueryError{{
+				Message:    "Variable \"n\" has invalid value 2.5.\nExpected type \"Int\", found 2.5.",
+				Locations:  []gqlerrors.Location{{Line: 2, Column: 11}},
+				Rule:       "VariablesOfCorrectType",
+				Extensions: map[string]interface{}{"pointer": "/n", "expectedType": "Int"},
+			}},
+		},
+		{
+			Schema: schema,
+			Query: `
+				query($n: Int!) {
+					double(n: $n)
+				}
+			`,
+			Variables: map[string]interface{}{"n": 3e9},
+			ExpectedErrors: []*gqlerrors.QueryError{{
+				Message:    "Variable \"n\" has invalid value 3e+09.\nExpected type \"Int\", found 3e+09.",
+				Locations:  []gqlerrors.Location{{Line: 2, Column: 11}},
+				Rule:       "VariablesOfCorrectType",
+				Extensions: map[string]interface{}{"pointer": "/n", "expectedType": "Int"},
+			}},
+		},
+		{
+			Schema: legacySchema,
+			Query: `
+				query($n: Int!) {
+					double(n: $n)
+				}
+			`,
+			Variables: map[string]interface{}{"n": 2.5},
+			ExpectedResult: `
+				{
+					"double": 4
+				}
+			`,
+		},
+	})
+}
+
+type compositeKey struct {
+	Tenant string
+	ID     int
+}
+
+func (k compositeKey) MarshalGraphQLID() string {
+	return k.Tenant + ":" + strconv.Itoa(k.ID)
+}
+
+type idResolver struct{}
+
+func (r *idResolver) Product(args struct{ ID int64 }) *idProductResolver {
+	return &idProductResolver{id: args.ID}
+}
+
+func (r *idResolver) User(args struct{ ID string }) string {
+	return args.ID
+}
+
+func (r *idResolver) Key() compositeKey {
+	return compositeKey{Tenant: "acme", ID: 7}
+}
+
+type idProductResolver struct {
+	id int64
+}
+
+func (p *idProductResolver) ID() int64 {
+	return p.id
+}
+
+func (p *idProductResolver) Sku() uint32 {
+	return 1234
+}
+
+func TestIDBinding(t *testing.T) {
+	t.Parallel()
+
+	schema := graphql.MustParseSchema(`
+		type Query {
+			product(id: ID!): Product!
+			user(id: ID!): ID!
+			key: ID!
+		}
+
+		type Product {
+			id: ID!
+			sku: ID!
+		}
+	`, &idResolver{})
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: schema,
+			Query: `
+				query($id: ID!) {
+					literal: product(id: "42") {
+						id
+						sku
+					}
+					variable: product(id: $id) {
+						id
+					}
+					user(id: 5)
+					key
+				}
+			`,
+			Variables: map[string]interface{}{"id": 43.0},
+			ExpectedResult: `
+				{
+					"literal": {
+						"id": "42",
+						"sku": "1234"
+					},
+					"variable": {
+						"id": "43"
+					},
+					"user": "5",
+					"key": "acme:7"
+				}
+			`,
+		},
+		{
+			Schema: schema,
+			Query: `
+				{
+					product(id: "abc") {
+						id
+					}
+				}
+			`,
+			ExpectedResult: "{}",
+			ExpectedErrors: []*gqlerrors.QueryError{{
+				Message:   "could not unmarshal ID \"abc\" into int64",
+				Locations: []gqlerrors.Location{{Line: 3, Column: 18}},
+			}},
+		},
+	})
+}
+
+type semanticResolver struct{}
+
+func (r *semanticResolver) Name() (*string, error) {
+	return nil, nil
+}
+
+func (r *semanticResolver) Nickname() (*string, error) {
+	return nil, errors.New("nickname unavailable")
+}
+
+func (r *semanticResolver) Tags() *[]*string {
+	tag := "new"
+	return &[]*string{&tag, nil}
+}
+
+func TestSemanticNonNull(t *testing.T) {
+	t.Parallel()
+
+	schemaString := `
+		directive @semanticNonNull(levels: [Int] = [0]) on FIELD_DEFINITION
+
+		type Query {
+			name: String @semanticNonNull
+			nickname: String @semanticNonNull
+			tags: [String] @semanticNonNull(levels: [1])
+		}
+	`
+	schema := graphql.MustParseSchema(schemaString, &semanticResolver{})
+	introspectionQuery := `
+		{
+			__type(name: "Query") {
+				fields {
+					name
+					type {
+						kind
+						ofType {
+							kind
+							ofType {
+								kind
+							}
+						}
+					}
+				}
+			}
+		}
+	`
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: schema,
+			Query: `
+				{
+					name
+					nickname
+					tags
+				}
+			`,
+			ExpectedResult: `
+				{
+					"name": null,
+					"nickname": null,
+					"tags": ["new", null]
+				}
+			`,
+			ExpectedErrors: []*gqlerrors.QueryError{
+				{Message: "graphql: got nil for semantically non-null \"String\"", Path: []interface{}{"name"}, Locations: []gqlerrors.Location{{Line: 3, Column: 6}}},
+				{Message: "nickname unavailable", Path: []interface{}{"nickname"}, Locations: []gqlerrors.Location{{Line: 4, Column: 6}}, ResolverError: errors.New("nickname unavailable")},
+				{Message: "graphql: got nil for semantically non-null \"String\"", Path: []interface{}{"tags", 1}, Locations: []gqlerrors.Location{{Line: 5, Column: 6}}},
+			},
+		},
+		{
+			Schema: schema,
+			Query:  introspectionQuery,
+			ExpectedResult: `
+				{
+					"__type": {
+						"fields": [
+							{"name": "name", "type": {"kind": "SCALAR", "ofType": null}},
+							{"name": "nickname", "type": {"kind": "SCALAR", "ofType": null}},
+							{"name": "tags", "type": {"kind": "LIST", "ofType": {"kind": "SCALAR", "ofType": null}}}
+						]
+					}
+				}
+			`,
+		},
+		{
+			Schema: graphql.MustParseSchema(schemaString, &semanticResolver{}, graphql.SemanticNonNullIntrospection()),
+			Query:  introspectionQuery,
+			ExpectedResult: `
+				{
+					"__type": {
+						"fields": [
+							{"name": "name", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "ofType": null}}},
+							{"name": "nickname", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "ofType": null}}},
+							{"name": "tags", "type": {"kind": "LIST", "ofType": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR"}}}}
+						]
+					}
+				}
+			`,
+		},
+	})
+
+	_, err := graphql.ParseSchema(`
+		directive @semanticNonNull(levels: [Int] = [0]) on FIELD_DEFINITION
+
+		type Query {
+			name: String! @semanticNonNull
+		}
+	`, &semanticResolver{})
+	if err == nil || !strings.Contains(err.Error(), `level 0 of field "name" marked by @semanticNonNull is already non-null`) {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+type onErrorResolver struct{}
+
+func (r *onErrorResolver) User() *onErrorUserResolver {
+	return &onErrorUserResolver{}
+}
+
+type onErrorUserResolver struct{}
+
+func (u *onErrorUserResolver) Name() string {
+	return "Alice"
+}
+
+func (u *onErrorUserResolver) Email() (string, error) {
+	return "", errors.New("email unavailable")
+}
+
+func (u *onErrorUserResolver) Avatar() (*string, error) {
+	return nil, errors.New("avatar unavailable")
+}
+
+func (u *onErrorUserResolver) Balance() (*int32, error) {
+	return nil, errors.New("balance unavailable")
+}
+
+func TestOnError(t *testing.T) {
+	t.Parallel()
+
+	schema := graphql.MustParseSchema(`
+		enum OnErrorAction {
+			NULL
+			PROPAGATE
+			DROP
+		}
+
+		directive @onError(action: OnErrorAction!) on FIELD_DEFINITION
+
+		type Query {
+			user: User
+		}
+
+		type User {
+			name: String!
+			email: String! @onError(action: NULL)
+			avatar: String @onError(action: DROP)
+			balance: Int @onError(action: PROPAGATE)
+		}
+	`, &onErrorResolver{})
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: schema,
+			Query: `
+				{
+					user {
+						name
+						email
+						avatar
+					}
+				}
+			`,
+			ExpectedResult: `
+				{
+					"user": {
+						"name": "Alice",
+						"email": null
+					}
+				}
+			`,
+			ExpectedErrors: []*gqlerrors.QueryError{
+				{Message: "email unavailable", Path: []interface{}{"user", "email"}, Locations: []gqlerrors.Location{{Line: 5, Column: 7}}, ResolverError: errors.New("email unavailable")},
+				{Message: "avatar unavailable", Path: []interface{}{"user", "avatar"}, Locations: []gqlerrors.Location{{Line: 6, Column: 7}}, ResolverError: errors.New("avatar unavailable")},
+			},
+		},
+		{
+			Schema: schema,
+			Query: `
+				{
+					user {
+						name
+						balance
+					}
+				}
+			`,
+			ExpectedResult: `
+				{
+					"user": null
+				}
+			`,
+			ExpectedErrors: []*gqlerrors.QueryError{
+				{Message: "balance unavailable", Path: []interface{}{"user", "balance"}, Locations: []gqlerrors.Location{{Line: 5, Column: 7}}, ResolverError: errors.New("balance unavailable")},
+			},
+		},
+	})
+}
+
+func TestExecTyped(t *testing.T) {
+	t.Parallel()
+
+	schema := graphql.MustParseSchema(`
+		type Query {
+			user: User
+		}
+
+		type User {
+			name: String!
+			friends: [User!]!
+			email: String
+		}
+	`, &typedResolver{})
+
+	result := schema.ExecTyped(context.Background(), `{ user { name friends { name email } } }`, "", nil)
+
+	var data struct {
+		User struct {
+			Name    string
+			Friends []struct {
+				Name  string
+				Email *string
+			}
+		}
+	}
+	if err := result.Data(&data); err != nil {
+		t.Fatal(err)
+	}
+	if data.User.Name != "Alice" || len(data.User.Friends) != 2 || data.User.Friends[1].Name != "Carol" {
+		t.Errorf("unexpected data %+v", data)
+	}
+
+	if len(result.Errors) != 1 {
+		t.Fatalf("unexpected errors %v", result.Errors)
+	}
+	err := result.Errors[0]
+	want := []gqlerrors.PathSegment{{Field: "user"}, {Field: "friends"}, {Index: 1}, {Field: "email"}}
+	if !reflect.DeepEqual(err.TypedPath(), want) {
+		t.Errorf("unexpected path %v", err.TypedPath())
+	}
+	if err.PathString() != "user.friends.1.email" {
+		t.Errorf("unexpected path string %q", err.PathString())
+	}
+	if err.Code() != "FORBIDDEN" {
+		t.Errorf("unexpected code %q", err.Code())
+	}
+	if errs := result.ErrorsAt("user", "friends", 1); len(errs) != 1 {
+		t.Errorf("unexpected errors at path %v", errs)
+	}
+	if errs := result.ErrorsAt("user", "friends", 0); len(errs) != 0 {
+		t.Errorf("unexpected errors at path %v", errs)
+	}
+	if result.Err() == nil || result.Err().Error() != "graphql: email hidden (line 1, column 30)" {
+		t.Errorf("unexpected error %v", result.Err())
+	}
+	if !reflect.DeepEqual(result.Response().Data, result.RawData()) {
+		t.Error("unexpected response data")
+	}
+
+	invalid := schema.ExecTyped(context.Background(), `{ unknown }`, "", nil)
+	if invalid.Err() == nil || len(invalid.RawData()) != 0 {
+		t.Errorf("unexpected result %+v", invalid)
+	}
+	if err := invalid.Data(&data); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+type typedResolver struct{}
+
+func (r *typedResolver) Count() int32 {
+	return 3
+}
+
+func (r *typedResolver) User() *typedUserResolver {
+	return &typedUserResolver{name: "Alice"}
+}
+
+type typedUserResolver struct {
+	name string
+}
+
+func (u *typedUserResolver) Name() string {
+	return u.name
+}
+
+func (u *typedUserResolver) Friends() []*typedUserResolver {
+	return []*typedUserResolver{{name: "Bob"}, {name: "Carol"}}
+}
+
+func (u *typedUserResolver) Email() (*string, error) {
+	if u.name == "Carol" {
+		return nil, forbiddenError{}
+	}
+	email := strings.ToLower(u.name) + "@example.com"
+	return &email, nil
+}
+
+type forbiddenError struct{}
+
+func (forbiddenError) Error() string {
+	return "email hidden"
+}
+
+func (forbiddenError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": "FORBIDDEN"}
+}
+
+func TestExecInto(t *testing.T) {
+	t.Parallel()
+
+	schema := graphql.MustParseSchema(`
+		type Query {
+			user: User
+		}
+
+		type User {
+			name: String!
+			friends: [User!]!
+			email: String
+		}
+	`, &typedResolver{})
+
+	var out struct {
+		User struct {
+			Name    string
+			Friends []struct {
+				Name string `json:"name"`
+			}
+		}
+	}
+	if errs := schema.ExecInto(context.Background(), &out, `{ user { name friends { name } } }`, "", nil); len(errs) != 0 {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+	if out.User.Name != "Alice" || len(out.User.Friends) != 2 || out.User.Friends[0].Name != "Bob" {
+		t.Errorf("unexpected data %+v", out)
+	}
+
+	var wrong struct {
+		User struct {
+			Name int
+		}
+	}
+	errs := schema.ExecInto(context.Background(), &wrong, `{ user { name } }`, "", nil)
+	if len(errs) != 1 || !strings.HasPrefix(errs[0].Message, "could not unmarshal data") {
+		t.Errorf("unexpected errors %v", errs)
+	}
+
+	resp := schema.Exec(context.Background(), `{ unknown }`, "", nil)
+	if err := resp.UnmarshalData(&out); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	t.Parallel()
+
+	schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{})
+
+	var vars struct {
+		Episode string `json:"episode"`
+	}
+	vars.Episode = "EMPIRE"
+	var out struct {
+		Hero struct {
+			Name string
+		}
+	}
+	query := `query($episode: Episode) { hero(episode: $episode) { name } }`
+	if errs := schema.Query(context.Background(), &out, query, vars); len(errs) != 0 {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+	if out.Hero.Name != "Luke Skywalker" {
+		t.Errorf("unexpected data %+v", out)
+	}
+
+	if errs := schema.Query(context.Background(), &out, query, map[string]interface{}{"episode": "JEDI"}); len(errs) != 0 || out.Hero.Name != "R2-D2" {
+		t.Errorf("unexpected errors %v and data %+v", errs, out)
+	}
+	if errs := schema.Query(context.Background(), &out, `{ hero { name } }`, nil); len(errs) != 0 || out.Hero.Name != "R2-D2" {
+		t.Errorf("unexpected errors %v and data %+v", errs, out)
+	}
+
+	errs := schema.Query(context.Background(), &out, query, []string{"EMPIRE"})
+	if len(errs) != 1 || !strings.HasPrefix(errs[0].Message, "could not marshal variables") {
+		t.Errorf("unexpected errors %v", errs)
+	}
+}
+
+func TestExecToValue(t *testing.T) {
+	t.Parallel()
+
+	schema := graphql.MustParseSchema(`
+		type Query {
+			user: User
+			count: Int!
+		}
+
+		type User {
+			name: String!
+			friends: [User!]!
+			email: String
+		}
+	`, &typedResolver{})
+
+	data, errs := schema.ExecToValue(context.Background(), `{ count user { name friends { email } } }`, "", nil)
+	if len(errs) != 1 {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+	want := map[string]interface{}{
+		"count": json.Number("3"),
+		"user": map[string]interface{}{
+			"name": "Alice",
+			"friends": []interface{}{
+				map[string]interface{}{"email": "bob@example.com"},
+				map[string]interface{}{"email": nil},
+			},
+		},
+	}
+	if !reflect.DeepEqual(data, want) {
+		t.Errorf("unexpected data %#v", data)
+	}
+
+	data, errs = schema.ExecToValue(context.Background(), `{ unknown }`, "", nil)
+	if data != nil || len(errs) != 1 {
+		t.Errorf("unexpected result %v %v", data, errs)
+	}
+}
+
+type quotaKey struct{}
+
+type quotaLimiter struct {
+	mu       sync.Mutex
+	acquired int
+	released int
+}
+
+func (l *quotaLimiter) Acquire(ctx context.Context) error {
+	if ctx.Value(quotaKey{}) == "exhausted" {
+		return errors.New("quota exhausted")
+	}
+	l.mu.Lock()
+	l.acquired++
+	l.mu.Unlock()
+	return nil
+}
+
+func (l *quotaLimiter) Release() {
+	l.mu.Lock()
+	l.released++
+	l.mu.Unlock()
+}
+
+func TestUseLimiter(t *testing.T) {
+	t.Parallel()
+
+	limiter := &quotaLimiter{}
+	schema := graphql.MustParseSchema(`
+		type Query {
+			hello: String
+			world: String
+		}
+	`, &limitedResolver{}, graphql.UseLimiter(limiter))
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: schema,
+			Query:  `{ hello world }`,
+			ExpectedResult: `
+				{
+					"hello": "Hello",
+					"world": "World"
+				}
+			`,
+		},
+		{
+			Schema:  schema,
+			Context: context.WithValue(context.Background(), quotaKey{}, "exhausted"),
+			Query:   `{ hello }`,
+			ExpectedResult: `
+				{
+					"hello": null
+				}
+			`,
+			ExpectedErrors: []*gqlerrors.QueryError{
+				{
+					Message:       "quota exhausted",
+					Path:          []interface{}{"hello"},
+					Locations:     []gqlerrors.Location{{Line: 1, Column: 3}},
+					ResolverError: errors.New("quota exhausted"),
+				},
+			},
+		},
+	})
+
+	if limiter.acquired != 2 || limiter.released != 2 {
+		t.Errorf("unexpected number of acquired (%d) and released (%d) slots", limiter.acquired, limiter.released)
+	}
+}
+
+type limitedResolver struct{}
+
+func (r *limitedResolver) Hello(ctx context.Context) *string {
+	s := "Hello"
+	return &s
+}
+
+func (r *limitedResolver) World(ctx context.Context) *string {
+	s := "World"
+	return &s
+}
+
+type scheduledResolver struct {
+	mu    sync.Mutex
+	order []string
+}
+
+func (r *scheduledResolver) resolve(name string) string {
+	r.mu.Lock()
+	r.order = append(r.order, name)
+	r.mu.Unlock()
+	return name
+}
+
+func (r *scheduledResolver) Expensive(ctx context.Context) string {
+	return r.resolve("expensive")
+}
+
+func (r *scheduledResolver) Medium(ctx context.Context) string {
+	return r.resolve("medium")
+}
+
+func (r *scheduledResolver) Cheap(ctx context.Context) string {
+	return r.resolve("cheap")
+}
+
+func TestScheduler(t *testing.T) {
+	t.Parallel()
+
+	costs := map[string]float64{"expensive": 10, "medium": 5}
+	resolver := &scheduledResolver{}
+	schema := graphql.MustParseSchema(`
+		type Query {
+			expensive: String!
+			medium: String!
+			cheap: String!
+		}
+	`, resolver, graphql.MaxParallelism(1), graphql.Scheduler(func(f *graphql.ScheduledField) float64 {
+		if f.TypeName != "Query" {
+			t.Errorf("unexpected type %q", f.TypeName)
+		}
+		return costs[f.Name]
+	}))
+
+	gqltesting.RunTest(t, &gqltesting.Test{
+		Schema: schema,
+		Query:  `{ expensive medium cheap }`,
+		ExpectedResult: `
+			{
+				"expensive": "expensive",
+				"medium": "medium",
+				"cheap": "cheap"
+			}
+		`,
+	})
+
+	if want := []string{"cheap", "medium", "expensive"}; !reflect.DeepEqual(resolver.order, want) {
+		t.Errorf("unexpected order %q", resolver.order)
+	}
+}
+
+type treeNode struct {
+	id    int32
+	depth int
+}
+
+func (n *treeNode) ID(ctx context.Context) int32 {
+	return n.id
+}
+
+func (n *treeNode) Children(ctx context.Context) []*treeNode {
+	if n.depth == 3 {
+		return []*treeNode{}
+	}
+	children := make([]*treeNode, 3)
+	for i := range children {
+		children[i] = &treeNode{id: n.id*10 + int32(i), depth: n.depth + 1}
+	}
+	return children
+}
+
+type treeResolver struct{}
+
+func (r *treeResolver) Root(ctx context.Context) *treeNode {
+	return &treeNode{id: 1}
+}
+
+func TestSharedConcurrency(t *testing.T) {
+	t.Parallel()
+
+	schemaString := `
+		type Query {
+			root: Node!
+		}
+
+		type Node {
+			id: Int!
+			children: [Node!]!
+		}
+	`
+	query := `
+		{
+			root {
+				id
+				children {
+					id
+					children {
+						id
+						children {
+							id
+							children {
+								id
+							}
+						}
+					}
+				}
+			}
+		}
+	`
+	want := graphql.MustParseSchema(schemaString, &treeResolver{}).Exec(context.Background(), query, "", nil)
+	if len(want.Errors) != 0 {
+		t.Fatal(want.Errors)
+	}
+
+	for _, n := range []int{1, 2, 10} {
+		schema := graphql.MustParseSchema(schemaString, &treeResolver{}, graphql.MaxParallelism(n), graphql.Concurrency(graphql.SharedConcurrency))
+		gqltesting.RunTest(t, &gqltesting.Test{
+			Schema:         schema,
+			Query:          query,
+			ExpectedResult: string(want.Data),
+		})
+	}
+}
+
+type concurrencyCounter struct {
+	mu      sync.Mutex
+	current int
+	max     int
+}
+
+func (c *concurrencyCounter) enter() {
+	c.mu.Lock()
+	c.current++
+	if c.current > c.max {
+		c.max = c.current
+	}
+	c.mu.Unlock()
+	time.Sleep(5 * time.Millisecond)
+	c.mu.Lock()
+	c.current--
+	c.mu.Unlock()
+}
+
+type listItemResolver struct {
+	id      int32
+	counter *concurrencyCounter
+}
+
+func (r *listItemResolver) ID(ctx context.Context) int32 {
+	r.counter.enter()
+	return r.id
+}
+
+type listConcurrencyResolver struct {
+	limited   concurrencyCounter
+	unlimited concurrencyCounter
+}
+
+func (r *listConcurrencyResolver) items(counter *concurrencyCounter, ids []int32) []*listItemResolver {
+	items := make([]*listItemResolver, len(ids))
+	for i, id := range ids {
+		if id > 0 {
+			items[i] = &listItemResolver{id: id, counter: counter}
+		}
+	}
+	return items
+}
+
+func (r *listConcurrencyResolver) Limited() []*listItemResolver {
+	return r.items(&r.limited, []int32{1, 2, 3, 4, 5, 6, 7, 8})
+}
+
+func (r *listConcurrencyResolver) Unlimited() []*listItemResolver {
+	return r.items(&r.unlimited, []int32{1, 2, 3, 4, 5, 6, 7, 8})
+}
+
+func (r *listConcurrencyResolver) Broken() *[]*listItemResolver {
+	items := r.items(&concurrencyCounter{}, []int32{1, 2, 3, -1, 5})
+	return &items
+}
+
+func TestMaxListConcurrency(t *testing.T) {
+	t.Parallel()
+
+	schemaString := `
+		directive @listConcurrency(max: Int!) on FIELD_DEFINITION
+
+		type Query {
+			limited: [Item!]! @listConcurrency(max: 2)
+			unlimited: [Item!]!
+			broken: [Item!] @listConcurrency(max: 2)
+		}
+
+		type Item {
+			id: Int!
+		}
+	`
+	items := `[{"id":1},{"id":2},{"id":3},{"id":4},{"id":5},{"id":6},{"id":7},{"id":8}]`
+
+	resolver := &listConcurrencyResolver{}
+	gqltesting.RunTest(t, &gqltesting.Test{
+		Schema:         graphql.MustParseSchema(schemaString, resolver, graphql.MaxParallelism(8)),
+		Query:          `{ limited { id } unlimited { id } }`,
+		ExpectedResult: `{"limited":` + items + `,"unlimited":` + items + `}`,
+	})
+	if resolver.limited.max > 2 {
+		t.Errorf("%d entries of the limited list were resolved concurrently", resolver.limited.max)
+	}
+	if resolver.unlimited.max <= 2 {
+		t.Errorf("only %d entries of the unlimited list were resolved concurrently", resolver.unlimited.max)
+	}
+
+	resolver = &listConcurrencyResolver{}
+	gqltesting.RunTest(t, &gqltesting.Test{
+		Schema:         graphql.MustParseSchema(schemaString, resolver, graphql.MaxParallelism(8), graphql.MaxListConcurrency(3)),
+		Query:          `{ unlimited { id } }`,
+		ExpectedResult: `{"unlimited":` + items + `}`,
+	})
+	if resolver.unlimited.max > 3 {
+		t.Errorf("%d entries of the list were resolved concurrently", resolver.unlimited.max)
+	}
+
+	gqltesting.RunTest(t, &gqltesting.Test{
+		Schema: graphql.MustParseSchema(schemaString, &listConcurrencyResolver{}),
+		Query:  `{ broken { id } }`,
+		ExpectedResult: `
+			{
+				"broken": null
+			}
+		`,
+		ExpectedErrors: []*gqlerrors.QueryError{
+			{
+				Message:   `graphql: got nil for non-null "Item"`,
+				Path:      []interface{}{"broken", 3},
+				Locations: []gqlerrors.Location{{Line: 1, Column: 3}},
+			},
+		},
+	})
+
+	_, err := graphql.ParseSchema(`
+		directive @listConcurrency(max: Int!) on FIELD_DEFINITION
+
+		type Query {
+			item: Item @listConcurrency(max: 2)
+		}
+
+		type Item {
+			id: Int!
+		}
+	`, &struct{ Item *listItemResolver }{}, graphql.UseFieldResolvers())
+	if err == nil || !strings.Contains(err.Error(), "field must be a list") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+type paginatedResolver struct{}
+
+type paginationArgs struct {
+	First *int32
+	Last  *int32
+}
+
+func (r *paginatedResolver) Users(args paginationArgs) []string {
+	n := int32(100)
+	if args.First != nil {
+		n = *args.First
+	}
+	if args.Last != nil && *args.Last < n {
+		n = *args.Last
+	}
+	users := make([]string, n)
+	for i := range users {
+		users[i] = fmt.Sprintf("user%d", i+1)
+	}
+	return users
+}
+
+func (r *paginatedResolver) Page(args struct{ Limit int32 }) []string {
+	return r.Users(paginationArgs{First: &args.Limit})
+}
+
+func (r *paginatedResolver) Tags() []string {
+	return []string{"a", "b"}
+}
+
+func TestPagination(t *testing.T) {
+	t.Parallel()
+
+	schemaString := `
+		type Query {
+			users(first: Int, last: Int): [String!]!
+			page(limit: Int = 50): [String!]!
+			tags: [String!]!
+		}
+	`
+	required := graphql.MustParseSchema(schemaString, &paginatedResolver{}, graphql.RequirePagination(3))
+	capped := graphql.MustParseSchema(schemaString, &paginatedResolver{}, graphql.CapPagination(3))
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Name:   "RequireGiven",
+			Schema: required,
+			Query:  `{ users(first: 2) tags }`,
+			ExpectedResult: `
+				{
+					"users": ["user1", "user2"],
+					"tags": ["a", "b"]
+				}
+			`,
+		},
+		{
+			Name:   "RequireMissing",
+			Schema: required,
+			Query:  `{ users }`,
+			ExpectedErrors: []*gqlerrors.QueryError{
+				{
+					Message:   `Field "users" must be paginated with one of the arguments first, last.`,
+					Locations: []gqlerrors.Location{{Line: 1, Column: 3}},
+					Rule:      "Pagination",
+				},
+			},
+		},
+		{
+			Name:   "RequireExceeded",
+			Schema: required,
+			Query:  `query($n: Int) { users(last: 2, first: $n) page }`,
+			Variables: map[string]interface{}{
+				"n": 10,
+			},
+			ExpectedErrors: []*gqlerrors.QueryError{
+				{
+					Message:   `Argument "first" of field "users" must not exceed 3, got 10.`,
+					Locations: []gqlerrors.Location{{Line: 1, Column: 40}},
+					Rule:      "Pagination",
+				},
+				{
+					Message:   `Argument "limit" of field "page" must not exceed 3, got 50.`,
+					Locations: []gqlerrors.Location{{Line: 1, Column: 44}},
+					Rule:      "Pagination",
+				},
+			},
+		},
+		{
+			Name:   "Cap",
+			Schema: capped,
+			Query:  `{ users page(limit: 2) more: users(last: 10) }`,
+			ExpectedResult: `
+				{
+					"users": ["user1", "user2", "user3"],
+					"page": ["user1", "user2"],
+					"more": ["user1", "user2", "user3"]
+				}
+			`,
+		},
+	})
+}
+
+func TestResponseFieldOrder(t *testing.T) {
+	t.Parallel()
+
+	query := `{ hero { name __typename id b: name a: name } }`
+	for _, test := range []struct {
+		order graphql.FieldOrder
+		want  string
+	}{
+		{graphql.SelectionOrder, `{"hero":{"name":"R2-D2","__typename":"Droid","id":"2001","b":"R2-D2","a":"R2-D2"}}`},
+		{graphql.SchemaOrder, `{"hero":{"__typename":"Droid","id":"2001","a":"R2-D2","b":"R2-D2","name":"R2-D2"}}`},
+		{graphql.SortedOrder, `{"hero":{"__typename":"Droid","a":"R2-D2","b":"R2-D2","id":"2001","name":"R2-D2"}}`},
+	} {
+		schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}, graphql.ResponseFieldOrder(test.order))
+		resp := schema.Exec(context.Background(), query, "", nil)
+		if len(resp.Errors) != 0 {
+			t.Fatal(resp.Errors)
+		}
+		if string(resp.Data) != test.want {
+			t.Errorf("order %d: got %s, want %s", test.order, resp.Data, test.want)
+		}
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	t.Parallel()
+
+	schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{})
+	key := func(query string, variables map[string]interface{}) string {
+		k, err := schema.CacheKey(query, "", variables)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return k
+	}
+
+	base := key(`query($id: ID!) { human(id: $id) { name, height(unit: FOOT) } }`, map[string]interface{}{"id": "1000"})
+	same := key(`
+		# a comment
+		query ($id: ID!) {
+			human(id: $id) {
+				name
+				height(unit: FOOT)
+			}
+		}
+	`, map[string]interface{}{"id": "1000"})
+	if base != same {
+		t.Error("expected documents differing in whitespace, commas and comments to have the same key")
+	}
+	if key(`query($id: ID!) { human(id: $id) { name height(unit: METER) } }`, map[string]interface{}{"id": "1000"}) == base {
+		t.Error("expected different arguments to change the key")
+	}
+	if key(`query($id: ID!) { human(id: $id) { name height(unit: FOOT) } }`, map[string]interface{}{"id": "1001"}) == base {
+		t.Error("expected different variables to change the key")
+	}
+
+	other := graphql.MustParseSchema(strings.Replace(starwars.Schema, "mass: Float", "mass: Float\n\tweight: Float", 1), nil)
+	version, err := schema.Version()
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherVersion, err := other.Version()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version == otherVersion {
+		t.Error("expected a changed schema to have a different version")
+	}
+	if k, _ := other.CacheKey(`query($id: ID!) { human(id: $id) { name height(unit: FOOT) } }`, "", map[string]interface{}{"id": "1000"}); k == base {
+		t.Error("expected the schema version to change the key")
+	}
+
+	if _, err := schema.CacheKey(`{ hero(name: "R2) }`, "", nil); err == nil {
+		t.Error("expected an error for an invalid token")
+	}
+}
+
+type dedupResolver struct {
+	calls   int32
+	started chan struct{}
+	release chan struct{}
+}
+
+func (r *dedupResolver) Dashboard() int32 {
+	n := atomic.AddInt32(&r.calls, 1)
+	if n == 1 {
+		close(r.started)
+	}
+	<-r.release
+	return n
+}
+
+func (r *dedupResolver) Refresh() int32 {
+	return atomic.AddInt32(&r.calls, 1)
+}
+
+func TestDeduplicateRequests(t *testing.T) {
+	t.Parallel()
+
+	r := &dedupResolver{started: make(chan struct{}), release: make(chan struct{})}
+	schema := graphql.MustParseSchema(`
+		schema {
+			query: Query
+			mutation: Mutation
+		}
+		type Query {
+			dashboard: Int!
+		}
+		type Mutation {
+			refresh: Int!
+		}
+	`, r, graphql.DeduplicateRequests(func(ctx context.Context) string {
+		viewer, _ := ctx.Value(viewerKey{}).(string)
+		return viewer
+	}))
+
+	exec := func(viewer string, query string, out *string) {
+		ctx := context.WithValue(context.Background(), viewerKey{}, viewer)
+		resp := schema.Exec(ctx, query, "", nil)
+		if len(resp.Errors) != 0 {
+			t.Error(resp.Errors)
+		}
+		*out = string(resp.Data)
+	}
+
+	results := make([]string, 4)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		exec("alice", `{ dashboard }`, &results[0])
+	}()
+	<-r.started
+	for i, query := range []string{`{ dashboard }`, "{\n\tdashboard,\n}"} {
+		i, query := i, query
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			exec("alice", query, &results[i+1])
+		}()
+	}
+	// Give the identical requests time to join the execution in flight.
+	time.Sleep(50 * time.Millisecond)
+	close(r.release)
+	wg.Wait()
+
+	for _, result := range results[:3] {
+		if result != `{"dashboard":1}` {
+			t.Errorf("expected identical requests to share one execution, got %s", result)
+		}
+	}
+
+	exec("bob", `{ dashboard }`, &results[3])
+	if results[3] != `{"dashboard":2}` {
+		t.Errorf("expected a request of another viewer to execute, got %s", results[3])
+	}
+
+	var m1, m2 string
+	exec("alice", `mutation { refresh }`, &m1)
+	exec("alice", `mutation { refresh }`, &m2)
+	if m1 == m2 {
+		t.Errorf("expected mutations to execute every time, got %s twice", m1)
+	}
+}
+
+type cancelDedupResolver struct {
+	started   chan struct{}
+	release   chan struct{}
+	cancelled chan struct{}
+}
+
+func (r *cancelDedupResolver) Dashboard(ctx context.Context) (int32, error) {
+	r.started <- struct{}{}
+	select {
+	case <-r.release:
+		return 1, nil
+	case <-ctx.Done():
+		close(r.cancelled)
+		return 0, ctx.Err()
+	}
+}
+
+func TestDeduplicateRequests_cancellation(t *testing.T) {
+	t.Parallel()
+
+	r := &cancelDedupResolver{started: make(chan struct{}, 2), release: make(chan struct{}), cancelled: make(chan struct{})}
+	schema := graphql.MustParseSchema(`type Query { dashboard: Int! }`, r, graphql.DeduplicateRequests(nil))
+
+	leaderCtx, cancelLeader := context.WithCancel(context.Background())
+	leader := make(chan *graphql.Response)
+	go func() { leader <- schema.Exec(leaderCtx, `{ dashboard }`, "", nil) }()
+	<-r.started
+	follower := make(chan *graphql.Response)
+	go func() { follower <- schema.Exec(context.Background(), `{ dashboard }`, "", nil) }()
+	// Give the identical request time to join the execution in flight.
+	time.Sleep(50 * time.Millisecond)
+
+	cancelLeader()
+	if resp := <-leader; len(resp.Errors) != 1 || resp.Errors[0].Message != "context canceled" {
+		t.Errorf("expected the cancelled request to fail, got %s %v", resp.Data, resp.Errors)
+	}
+	close(r.release)
+	if resp := <-follower; string(resp.Data) != `{"dashboard":1}` || len(resp.Errors) != 0 {
+		t.Errorf("expected the waiting request to receive the response, got %s %v", resp.Data, resp.Errors)
+	}
+
+	// An execution whose requests are all cancelled is cancelled as well.
+	r.release = make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan *graphql.Response)
+	go func() { done <- schema.Exec(ctx, `{ dashboard }`, "", nil) }()
+	<-r.started
+	cancel()
+	<-done
+	select {
+	case <-r.cancelled:
+	case <-time.After(time.Second):
+		t.Error("expected the execution to be cancelled")
+	}
+}
+
+type diagnosticError struct {
+	extensions map[string]interface{}
+}
+
+func (e diagnosticError) Error() string {
+	return "unavailable"
+}
+
+func (e diagnosticError) Extensions() map[string]interface{} {
+	return e.extensions
+}
+
+type diagnosticResolver struct{}
+
+func (r *diagnosticResolver) Retryable() (*string, error) {
+	return nil, diagnosticError{map[string]interface{}{"code": "UNAVAILABLE", "retryAfter": 5, "stack": "db.go:42"}}
+}
+
+func (r *diagnosticResolver) Internal() (*string, error) {
+	return nil, diagnosticError{map[string]interface{}{"sql": "SELECT 1"}}
+}
+
+func TestAllowErrorExtensions(t *testing.T) {
+	t.Parallel()
+
+	schema := graphql.MustParseSchema(`
+		type Query {
+			retryable: String
+			internal: String
+		}
+	`, &diagnosticResolver{}, graphql.AllowErrorExtensions("code", "retryAfter"))
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: schema,
+			Query:  `{ retryable internal }`,
+			ExpectedResult: `
+				{
+					"retryable": null,
+					"internal": null
+				}
+			`,
+			ExpectedErrors: []*gqlerrors.QueryError{
+				{
+					Message:       "unavailable",
+					Path:          []interface{}{"retryable"},
+					Locations:     []gqlerrors.Location{{Line: 1, Column: 3}},
+					ResolverError: diagnosticError{map[string]interface{}{"code": "UNAVAILABLE", "retryAfter": 5, "stack": "db.go:42"}},
+					Extensions:    map[string]interface{}{"code": "UNAVAILABLE", "retryAfter": 5},
+				},
+				{
+					Message:       "unavailable",
+					Path:          []interface{}{"internal"},
+					Locations:     []gqlerrors.Location{{Line: 1, Column: 13}},
+					ResolverError: diagnosticError{map[string]interface{}{"sql": "SELECT 1"}},
+				},
+			},
+		},
+	})
+}
+
+func TestLocalizeErrors(t *testing.T) {
+	t.Parallel()
+
+	catalog := map[string]map[string]string{
+		"de": {"UNAVAILABLE": "nicht verfügbar"},
+	}
+	schema := graphql.MustParseSchema(`
+		type Query {
+			retryable: String
+			internal: String
+		}
+	`, &diagnosticResolver{}, graphql.LocalizeErrors(func(locale, code string) (string, bool) {
+		msg, ok := catalog[locale][code]
+		return msg, ok
+	}), graphql.AllowErrorExtensions("code"))
+
+	for locale, want := range map[string]string{"de": "nicht verfügbar", "fr": "unavailable", "": "unavailable"} {
+		ctx := requestcontext.WithLocale(context.Background(), locale)
+		resp := schema.Exec(ctx, `{ retryable internal }`, "", nil)
+		if len(resp.Errors) != 2 {
+			t.Fatalf("expected 2 errors, got %v", resp.Errors)
+		}
+		for _, err := range resp.Errors {
+			switch err.Path[0] {
+			case "retryable":
+				if err.Message != want || err.Code() != "UNAVAILABLE" {
+					t.Errorf("locale %q: unexpected error %q with code %q", locale, err.Message, err.Code())
+				}
+			case "internal":
+				if err.Message != "unavailable" || err.Extensions != nil {
+					t.Errorf("locale %q: unexpected error %q with extensions %v", locale, err.Message, err.Extensions)
+				}
+			}
+		}
+	}
+}
+
+type transformedResolver struct{}
+
+func (r *transformedResolver) Account() *accountResolver {
+	return &accountResolver{name: "alice"}
+}
+
+func (r *transformedResolver) Owner() *ownerResolver {
+	return &ownerResolver{&accountResolver{name: "bob"}}
+}
+
+func (r *transformedResolver) Secret() string {
+	return "secret"
+}
+
+type ownerResolver struct {
+	account *accountResolver
+}
+
+func (r *ownerResolver) Name() string {
+	return r.account.name
+}
+
+func (r *ownerResolver) ToAccount() (*accountResolver, bool) {
+	return r.account, true
+}
+
+type accountResolver struct {
+	name string
+}
+
+func (r *accountResolver) Name() string {
+	return r.name
+}
+
+func (r *accountResolver) Plan(args struct{ Tier *string }) *string {
+	return args.Tier
+}
+
+const transformedSchema = `
+	type Query {
+		account: Account
+		owner: Owner
+		secret: String!
+	}
+
+	interface Owner {
+		name: String!
+	}
+
+	type Account implements Owner {
+		name: String!
+		plan(tier: String): String
+	}
+`
+
+func TestTransform(t *testing.T) {
+	t.Parallel()
+
+	prefix := &graphql.Transformer{
+		RenameType: func(name string) string {
+			if name == "Query" {
+				return name
+			}
+			return "Billing" + name
+		},
+		RemoveField: func(typeName, fieldName string) bool {
+			return typeName == "Query" && fieldName == "secret"
+		},
+	}
+	var wrapped []string
+	var mu sync.Mutex
+	upper := &graphql.Transformer{
+		WrapResolver: func(typeName, fieldName string) graphql.ResolverWrapper {
+			mu.Lock()
+			wrapped = append(wrapped, typeName+"."+fieldName)
+			mu.Unlock()
+			if fieldName != "name" {
+				return nil
+			}
+			return func(ctx context.Context, next graphql.ResolverFunc) (interface{}, error) {
+				v, err := next(ctx)
+				if err != nil {
+					return nil, err
+				}
+				return strings.ToUpper(v.(string)), nil
+			}
+		},
+	}
+	schema := graphql.MustParseSchema(transformedSchema, &transformedResolver{}, graphql.Transform(prefix, upper))
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: schema,
+			Query: `
+				{
+					account { __typename name plan(tier: "pro") }
+					owner { __typename ... on BillingAccount { name } }
+				}
+			`,
+			ExpectedResult: `
+				{
+					"account": {"__typename": "BillingAccount", "name": "ALICE", "plan": "pro"},
+					"owner": {"__typename": "BillingAccount", "name": "BOB"}
+				}
+			`,
+		},
+		{
+			Schema: schema,
+			Query:  `{ secret }`,
+			ExpectedErrors: []*gqlerrors.QueryError{{
+				Message:   `Cannot query field "secret" on type "Query".`,
+				Locations: []gqlerrors.Location{{Line: 1, Column: 3}},
+				Rule:      "FieldsOnCorrectType",
+			}},
+		},
+	})
+
+	sort.Strings(wrapped)
+	if want := []string{"BillingAccount.name", "BillingAccount.plan", "BillingOwner.name", "Query.account", "Query.owner"}; !reflect.DeepEqual(wrapped, want) {
+		t.Errorf("unexpected wrapped fields %v, want %v", wrapped, want)
+	}
+
+	for _, tt := range []struct {
+		transformer *graphql.Transformer
+		err         string
+	}{
+		{&graphql.Transformer{RenameType: func(name string) string {
+			if name == "Account" {
+				return "Owner"
+			}
+			return name
+		}}, `can not rename type "Account" to "Owner", the type is already defined`},
+		{&graphql.Transformer{RenameType: func(name string) string {
+			if name == "Account" {
+				return "Billing Account"
+			}
+			return name
+		}}, `can not rename type "Account" to "Billing Account", the name is invalid`},
+		{&graphql.Transformer{RemoveField: func(typeName, fieldName string) bool {
+			return typeName == "Account" && fieldName == "name"
+		}}, `field "name" of type "Account" is required by interface "Owner"`},
+	} {
+		if _, err := graphql.ParseSchema(transformedSchema, &transformedResolver{}, graphql.Transform(tt.transformer)); err == nil || err.Error() != tt.err {
+			t.Errorf("expected error %q, got %v", tt.err, err)
+		}
+	}
+}
+
+func TestWalk(t *testing.T) {
+	t.Parallel()
+
+	schema := graphql.MustParseSchema(transformedSchema, nil)
+	var visited []string
+	schema.Walk(&graphql.Visitor{
+		EnterType: func(t *introspection.Type) bool {
+			if t.Kind() == "SCALAR" {
+				return false
+			}
+			visited = append(visited, *t.Name())
+			return true
+		},
+		LeaveType: func(t *introspection.Type) {
+			visited = append(visited, "/"+*t.Name())
+		},
+		EnterField: func(t *introspection.Type, f *introspection.Field) bool {
+			visited = append(visited, *t.Name()+"."+f.Name())
+			return f.Name() != "secret"
+		},
+		EnterArgument: func(arg *introspection.InputValue) {
+			visited = append(visited, "("+arg.Name()+")")
+		},
+		EnterDirective: func(d *introspection.Directive) bool {
+			return false
+		},
+	})
+
+	want := []string{
+		"Account", "Account.name", "Account.plan", "(tier)", "/Account",
+		"Owner", "Owner.name", "/Owner",
+		"Query", "Query.account", "Query.owner", "Query.secret", "/Query",
+	}
+	if !reflect.DeepEqual(visited, want) {
+		t.Errorf("unexpected visits\n got: %v\nwant: %v", visited, want)
+	}
+}
+
+type hoistResolver struct {
+	anonymous bool
+}
+
+func (r *hoistResolver) Viewer() *hoistViewerResolver {
+	if r.anonymous {
+		return nil
+	}
+	return &hoistViewerResolver{}
+}
+
+func (r *hoistResolver) Admin() string {
+	return "admin"
+}
+
+type hoistViewerResolver struct{}
+
+func (r *hoistViewerResolver) Account(ctx context.Context) *accountResolver {
+	return &accountResolver{name: "alice"}
+}
+
+const hoistSchema = `
+	type Query {
+		viewer: Viewer
+		admin: String!
+	}
+
+	type Viewer {
+		account: Account!
+	}
+
+	type Account {
+		name: String!
+		plan(tier: String): String
+	}
+`
+
+func TestPredefinedTransforms(t *testing.T) {
+	t.Parallel()
+
+	transforms := graphql.Transform(
+		graphql.PrefixTypes("Billing"),
+		graphql.RenameType("BillingViewer", "Me"),
+		graphql.FilterRootFields(func(operation, fieldName string) bool {
+			return fieldName != "admin"
+		}),
+		graphql.HoistField("Query", []string{"viewer", "account"}, "account"),
+		graphql.HoistField("Query", []string{"viewer", "account", "plan"}, "plan"),
+	)
+	schema := graphql.MustParseSchema(hoistSchema, &hoistResolver{}, transforms)
+	anonymous := graphql.MustParseSchema(hoistSchema, &hoistResolver{anonymous: true}, transforms)
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: schema,
+			Query: `
+				{
+					viewer { __typename account { __typename name } }
+					account { name }
+					plan(tier: "pro")
+				}
+			`,
+			ExpectedResult: `
+				{
+					"viewer": {"__typename": "Me", "account": {"__typename": "BillingAccount", "name": "alice"}},
+					"account": {"name": "alice"},
+					"plan": "pro"
+				}
+			`,
+		},
+		{
+			Schema: anonymous,
+			Query:  `{ account { name } plan }`,
+			ExpectedResult: `
+				{
+					"account": null,
+					"plan": null
+				}
+			`,
+		},
+		{
+			Schema: schema,
+			Query:  `{ admin }`,
+			ExpectedErrors: []*gqlerrors.QueryError{{
+				Message:   `Cannot query field "admin" on type "Query".`,
+				Locations: []gqlerrors.Location{{Line: 1, Column: 3}},
+				Rule:      "FieldsOnCorrectType",
+			}},
+		},
+		{
+			Schema: schema,
+			Query: `
+				{
+					__type(name: "Query") {
+						fields { name type { kind name } }
+					}
+				}
+			`,
+			ExpectedResult: `
+				{
+					"__type": {
+						"fields": [
+							{"name": "viewer", "type": {"kind": "OBJECT", "name": "Me"}},
+							{"name": "account", "type": {"kind": "OBJECT", "name": "BillingAccount"}},
+							{"name": "plan", "type": {"kind": "SCALAR", "name": "String"}}
+						]
+					}
+				}
+			`,
+		},
+	})
+
+	for _, tt := range []struct {
+		transformer *graphql.Transformer
+		err         string
+	}{
+		{graphql.HoistField("Query", []string{"admin", "name"}, "name"), `can not hoist through field "admin" of type "Query", its type String! is not an object`},
+		{graphql.HoistField("Query", []string{"viewer", "account", "plan", "name"}, "name"), `can not hoist through field "plan" of type "Account", it has arguments`},
+		{graphql.HoistField("Query", []string{"viewer", "email"}, "email"), `field "email" is not defined on type "Viewer"`},
+		{graphql.HoistField("Query", []string{"viewer", "account"}, "admin"), `can not hoist field "viewer.account" to type "Query", field "admin" is already defined`},
+		{graphql.RenameType("Int", "Integer"), `built-in type "Int" can not be renamed`},
+	} {
+		if _, err := graphql.ParseSchema(hoistSchema, &hoistResolver{}, graphql.Transform(tt.transformer)); err == nil || err.Error() != tt.err {
+			t.Errorf("expected error %q, got %v", tt.err, err)
+		}
+	}
+}
+
+type dependentUserResolver struct{}
+
+func (r *dependentUserResolver) FirstName() string {
+	return "Ada"
+}
+
+func (r *dependentUserResolver) LastName() (*string, error) {
+	s := "Lovelace"
+	return &s, nil
+}
+
+func (r *dependentUserResolver) Greeting(args struct{ Formal bool }) string {
+	if args.Formal {
+		return "Good day"
+	}
+	return "Hi"
+}
+
+func (r *dependentUserResolver) FullName(ctx context.Context) string {
+	required := graphql.RequiredFieldsFromContext(ctx)
+	return required["firstName"].(string) + " " + *required["lastName"].(*string)
+}
+
+func (r *dependentUserResolver) Initials(ctx context.Context) string {
+	required := graphql.RequiredFieldsFromContext(ctx)
+	return required["firstName"].(string)[:1] + "."
+}
+
+type dependentResolver struct{}
+
+func (r *dependentResolver) User() *dependentUserResolver {
+	return &dependentUserResolver{}
+}
+
+func TestDependsOn(t *testing.T) {
+	t.Parallel()
+
+	const sdl = `
+		directive @dependsOn(fields: [String!]!) on FIELD_DEFINITION
+
+		type Query {
+			user: User!
+		}
+
+		type User {
+			firstName: String!
+			lastName: String
+			greeting(formal: Boolean = false): String!
+			fullName: String! @dependsOn(fields: ["firstName", "lastName"])
+			initials: String! @dependsOn(fields: [%s])
+		}
+	`
+	schema := graphql.MustParseSchema(fmt.Sprintf(sdl, `"firstName"`), &dependentResolver{})
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: schema,
+			Query:  `{ user { fullName initials } }`,
+			ExpectedResult: `
+				{
+					"user": {"fullName": "Ada Lovelace", "initials": "A."}
+				}
+			`,
+		},
+	})
+
+	for deps, want := range map[string]string{
+		`"middleName"`: `directive @dependsOn on field "initials": field "middleName" is not defined on type "User"`,
+		`"initials"`:   `directive @dependsOn on field "initials": field can not depend on itself`,
+		`"greeting"`:   `directive @dependsOn on field "initials": field "greeting" has arguments`,
+		`"fullName"`:   `directive @dependsOn on field "initials": field "fullName" has dependencies itself`,
+	} {
+		_, err := graphql.ParseSchema(fmt.Sprintf(sdl, deps), &dependentResolver{})
+		if err == nil || !strings.HasPrefix(err.Error(), want) {
+			t.Errorf("expected error %q, got %v", want, err)
+		}
+	}
+}
+
+func TestSDL(t *testing.T) {
+	t.Parallel()
+
+	schema := graphql.MustParseSchema(`
+		schema { query: Root }
+
+		# Marks a field as expensive.
+		directive @cost(weight: Int = 1) on FIELD_DEFINITION
+
+		"""
+		The root.
+
+		Second paragraph.
+		"""
+		type Root {
+			# A user, documented with a comment.
+			user(
+				"The ID."
+				id: ID!
+				format: Format = SHORT
+			): User @cost(weight: 2)
+		}
+
+		type User {
+			name: String! @deprecated(reason: "Use fullName.")
+			"Says \"hi\""
+			fullName: String!
+		}
+
+		enum Format {
+			SHORT
+			LONG
+		}
+	`, nil, graphql.UseCommentDescriptions())
+
+	want := `schema {
+	query: Root
+}
+
+"""Marks a field as expensive."""
+directive @cost(weight: Int = 1) on FIELD_DEFINITION
+
+enum Format {
+	SHORT
+	LONG
+}
+
+"""
+The root.
+
+Second paragraph.
+"""
+type Root {
+	"""A user, documented with a comment."""
+	user(
+		"""The ID."""
+		id: ID!
+		format: Format = SHORT
+	): User @cost(weight: 2)
+}
+
+type User {
+	name: String! @deprecated(reason: "Use fullName.")
+	"Says \"hi\""
+	fullName: String!
+}
+`
+	sdl := schema.SDL()
+	if sdl != want {
+		t.Errorf("unexpected SDL:\n%s", sdl)
+	}
+	if got := graphql.MustParseSchema(sdl, nil, graphql.UseStringDescriptions()).SDL(); got != want {
+		t.Errorf("SDL does not round-trip with string descriptions:\n%s", got)
+	}
+
+	commented := schema.SDLWithOptions(graphql.SDLOptions{CommentDescriptions: true})
+	if !strings.Contains(commented, "# The root.\n#\n# Second paragraph.\ntype Root {") {
+		t.Errorf("unexpected SDL with comment descriptions:\n%s", commented)
+	}
+	if got := graphql.MustParseSchema(commented, nil).SDL(); got != want {
+		t.Errorf("SDL does not round-trip with comment descriptions:\n%s", got)
+	}
+
+	for _, typ := range schema.Inspect().Types() {
+		if *typ.Name() != "Root" {
+			continue
+		}
+		f := (*typ.Fields(nil))[0]
+		if desc := f.Description(); desc == nil || *desc != "A user, documented with a comment." {
+			t.Errorf("unexpected description %v", desc)
+		}
+	}
+}
+
+func TestDescriptions(t *testing.T) {
+	t.Parallel()
+
+	const schemaString = `
+		type Query {
+			"The signed in user."
+			user(size: Int): User
+		}
+
+		type User {
+			name: String!
+			role: Role!
+		}
+
+		enum Role {
+			ADMIN
+		}
+	`
+	schema := graphql.MustParseSchema(schemaString, nil, graphql.Descriptions(map[string]string{
+		"Query.user":        "Ignored, as the field is described by the schema.",
+		"Query.user(size:)": "The size of the avatar.",
+		"User":              "A user of the shop.",
+		"User.name":         "The name shown to other users.",
+		"Role.ADMIN":        "May change everything.",
+	}), graphql.UseStringDescriptions())
+
+	want := `type Query {
+	"""The signed in user."""
+	user(
+		"""The size of the avatar."""
+		size: Int
+	): User
+}
+
+enum Role {
+	"""May change everything."""
+	ADMIN
+}
+
+"""A user of the shop."""
+type User {
+	"""The name shown to other users."""
+	name: String!
+	role: Role!
+}
+`
+	if sdl := schema.SDL(); sdl != want {
+		t.Errorf("unexpected SDL:\n%s", sdl)
+	}
+
+	_, err := graphql.ParseSchema(schemaString, nil, graphql.Descriptions(map[string]string{"User.email": "The email address."}))
+	if err == nil || err.Error() != `description of "User.email": no such member in the schema` {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+type snakeCaseResolver struct{}
+
+func (snakeCaseResolver) User() *snakeCaseUser {
+	return &snakeCaseUser{UserID: "1", Login: "alice"}
+}
+
+func (snakeCaseResolver) Rename(args struct {
+	UserID  graphql.ID
+	NewName string `graphql:"name"`
+}) string {
+	return string(args.UserID) + ":" + args.NewName
+}
+
+type snakeCaseUser struct {
+	UserID graphql.ID
+	Login  string `graphql:"loginName"`
+}
+
+func (u *snakeCaseUser) FullName() string { return "Alice Liddell" }
+
+func TestFieldNameMapping(t *testing.T) {
+	t.Parallel()
+
+	for goName, want := range map[string][2]string{
+		"FullName":     {"fullName", "full_name"},
+		"ID":           {"id", "id"},
+		"UserID":       {"userId", "user_id"},
+		"HTMLBody":     {"htmlBody", "html_body"},
+		"Address2Line": {"address2Line", "address2_line"},
+		"Legacy_Name":  {"legacyName", "legacy_name"},
+	} {
+		if got := graphql.CamelCaseNames(goName); got != want[0] {
+			t.Errorf("CamelCaseNames(%q) = %q, want %q", goName, got, want[0])
+		}
+		if got := graphql.SnakeCaseNames(goName); got != want[1] {
+			t.Errorf("SnakeCaseNames(%q) = %q, want %q", goName, got, want[1])
+		}
+	}
+
+	schema := graphql.MustParseSchema(`
+		type Query {
+			user: User!
+			rename(user_id: ID!, name: String!): String!
+		}
+
+		type User {
+			user_id: ID!
+			loginName: String!
+			full_name: String!
+		}
+	`, &snakeCaseResolver{}, graphql.UseFieldResolvers(), graphql.FieldNameMapping(graphql.SnakeCaseNames))
+
+	gqltesting.RunTest(t, &gqltesting.Test{
+		Schema: schema,
+		Query: `
+			{
+				user { user_id loginName full_name }
+				rename(user_id: "1", name: "bob")
+			}
+		`,
+		ExpectedResult: `
+			{
+				"user": { "user_id": "1", "loginName": "alice", "full_name": "Alice Liddell" },
+				"rename": "1:bob"
+			}
+		`,
+	})
+
+	_, err := graphql.ParseSchema(`
+		type Query {
+			user: User!
+		}
+
+		type User {
+			fullName: String!
+		}
+	`, &snakeCaseResolver{}, graphql.FieldNameMapping(graphql.SnakeCaseNames))
+	if err == nil || !strings.Contains(err.Error(), `missing method for field "fullName"`) {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+type preferFieldsResolver struct {
+	Name   string
+	Secret string `graphql:"-"`
+	Post   *preferFieldsPost
+}
+
+func (r *preferFieldsResolver) NAME() string   { return "method " + r.Name }
+func (r *preferFieldsResolver) SECRET() string { return "redacted" }
+
+type preferFieldsPost struct {
+	Title string
+}
+
+func (p *preferFieldsPost) TITLE() string          { return "method " + p.Title }
+func (p *preferFieldsPost) PreferMethodResolvers() {}
+
+type preferFieldsAuthors struct{}
+
+func (preferFieldsAuthors) Author() *preferFieldsAuthor { return &preferFieldsAuthor{} }
+
+type preferFieldsAuthor struct {
+	Body   string
+	Hidden string `graphql:"-"`
+}
+
+func TestPreferFieldResolvers(t *testing.T) {
+	t.Parallel()
+
+	const schemaString = `
+		type Query {
+			name: String!
+			secret: String!
+			post: Post!
+		}
+
+		type Post {
+			title: String!
+		}
+	`
+	resolver := &preferFieldsResolver{Name: "alice", Secret: "s3cr3t", Post: &preferFieldsPost{Title: "hello"}}
+	query := `{ name secret post { title } }`
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: graphql.MustParseSchema(schemaString, resolver, graphql.UseFieldResolvers()),
+			Query:  query,
+			ExpectedResult: `
+				{"name": "method alice", "secret": "redacted", "post": {"title": "method hello"}}
+			`,
+		},
+		{
+			Schema: graphql.MustParseSchema(schemaString, resolver, graphql.PreferFieldResolvers()),
+			Query:  query,
+			ExpectedResult: `
+				{"name": "alice", "secret": "redacted", "post": {"title": "method hello"}}
+			`,
+		},
+	})
+
+	for _, tc := range []struct {
+		field string
+		opts  []graphql.SchemaOpt
+		want  string
+	}{
+		{"body", nil, `*graphql_test.preferFieldsAuthor does not resolve "Author": missing method for field "body" (hint: struct field Body resolves it with UseFieldResolvers)`},
+		{"hidden", []graphql.SchemaOpt{graphql.PreferFieldResolvers()}, `*graphql_test.preferFieldsAuthor does not resolve "Author": missing method or struct field for field "hidden" (hint: struct field Hidden is excluded by its graphql tag)`},
+		{"email", []graphql.SchemaOpt{graphql.PreferFieldResolvers()}, `*graphql_test.preferFieldsAuthor does not resolve "Author": missing method or struct field for field "email"`},
+	} {
+		_, err := graphql.ParseSchema(`
+			type Query {
+				author: Author!
+			}
+
+			type Author {
+				`+tc.field+`: String!
+			}
+		`, &preferFieldsAuthors{}, tc.opts...)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: unexpected error %v", tc.field, err)
+		}
+	}
+}
+
+type nilSliceResolver struct {
+	Tags      []string
+	Labels    []string `graphql:",nilnull"`
+	Topics    []string `graphql:",nilempty"`
+	Required  []string
+	Filled    []string
+	Pointer   *[]string
+	Annotated []string
+}
+
+func TestNilSlicesAsNull(t *testing.T) {
+	t.Parallel()
+
+	const schemaString = `
+		directive @nilSlice(as: NilSlice!) on FIELD_DEFINITION
+
+		enum NilSlice {
+			NULL
+			EMPTY
+		}
+
+		type Query {
+			tags: [String!]
+			labels: [String!]
+			topics: [String!]
+			required: [String!]!
+			filled: [String!]
+			pointer: [String!]
+			annotated: [String!] @nilSlice(as: EMPTY)
+		}
+	`
+	resolver := &nilSliceResolver{Filled: []string{"a"}, Pointer: new([]string)}
+	query := `{ tags labels topics required filled pointer annotated }`
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: graphql.MustParseSchema(schemaString, resolver, graphql.UseFieldResolvers()),
+			Query:  query,
+			ExpectedResult: `
+				{"tags": [], "labels": null, "topics": [], "required": [], "filled": ["a"], "pointer": [], "annotated": []}
+			`,
+		},
+		{
+			Schema: graphql.MustParseSchema(schemaString, resolver, graphql.UseFieldResolvers(), graphql.NilSlicesAsNull()),
+			Query:  query,
+			ExpectedResult: `
+				{"tags": null, "labels": null, "topics": [], "required": [], "filled": ["a"], "pointer": null, "annotated": []}
+			`,
+		},
+	})
+
+	_, err := graphql.ParseSchema(`
+		directive @nilSlice(as: NilSlice!) on FIELD_DEFINITION
+
+		enum NilSlice {
+			NULL
+			EMPTY
+		}
+
+		type Query {
+			required: [String!]! @nilSlice(as: NULL)
+		}
+	`, &nilSliceResolver{}, graphql.UseFieldResolvers())
+	if err == nil || !strings.Contains(err.Error(), `directive @nilSlice on field "required": field must be a nullable list`) {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+type int64Resolver struct{}
+
+func (int64Resolver) Big() int64     { return 1<<53 + 1 }
+func (int64Resolver) Count() uint64  { return 42 }
+func (int64Resolver) ID() int64      { return 1<<62 + 1 }
+func (int64Resolver) Small() int32   { return 7 }
+func (int64Resolver) Total() *int    { return nil }
+func (int64Resolver) Sizes() []int64 { return []int64{1, 1 << 60} }
+
+func TestInt64Results(t *testing.T) {
+	t.Parallel()
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: graphql.MustParseSchema(`
+				type Query {
+					big: Float!
+					count: Float!
+					id: ID!
+					small: Int!
+					sizes: [Float!]!
+				}
+			`, &int64Resolver{}, graphql.Int64Results(graphql.Int64AsFloat)),
+			Query: `{ big count id small sizes }`,
+			ExpectedResult: `
+				{"big": 9007199254740992, "count": 42, "id": "4611686018427387905", "small": 7, "sizes": [1, 1152921504606846976]}
+			`,
+		},
+		{
+			Schema: graphql.MustParseSchema(`
+				type Query {
+					big: String!
+					count: Long!
+					total: String
+					sizes: [String!]!
+				}
+
+				scalar Long
+			`, &int64Resolver{}, graphql.Int64Results(graphql.Int64AsString)),
+			Query: `{ big count total sizes }`,
+			ExpectedResult: `
+				{"big": "9007199254740993", "count": "42", "total": null, "sizes": ["1", "1152921504606846976"]}
+			`,
+		},
+		{
+			Schema: graphql.MustParseSchema(`
+				type Query {
+					big: BigInt!
+					id: ID!
+				}
+
+				scalar BigInt
+			`, &int64Resolver{}, graphql.Int64Results(graphql.Int64BigInt)),
+			Query: `{ big id }`,
+			ExpectedResult: `
+				{"big": "9007199254740993", "id": "4611686018427387905"}
+			`,
+		},
+	})
+
+	for _, tc := range []struct {
+		policy graphql.Int64Policy
+		field  string
+		want   string
+	}{
+		{graphql.Int64Reject, "big: Float!", `can not use int64 as Float, 64-bit integers may only resolve ID fields`},
+		{graphql.Int64AsFloat, "big: Int!", `can not use int64 as Int, 64-bit integers may only resolve Float and ID fields`},
+		{graphql.Int64AsString, "count: Int!", `can not use uint64 as Int, 64-bit integers may only resolve String, ID and custom scalar fields`},
+		{graphql.Int64BigInt, "big: String!", `can not use int64 as String, 64-bit integers may only resolve BigInt and ID fields`},
+	} {
+		_, err := graphql.ParseSchema(`
+			type Query {
+				`+tc.field+`
+			}
+		`, &int64Resolver{}, graphql.Int64Results(tc.policy))
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: unexpected error %v", tc.field, err)
+		}
+	}
+}
+
+type scoreScalar float64
+
+func (scoreScalar) ImplementsGraphQLType(name string) bool { return name == "Score" }
+
+func (s *scoreScalar) UnmarshalGraphQL(input interface{}) error {
+	f, ok := input.(float64)
+	if !ok {
+		return fmt.Errorf("wrong type for Score: %T", input)
+	}
+	*s = scoreScalar(f)
+	return nil
+}
+
+type nonFiniteResolver struct{}
+
+func (nonFiniteResolver) NaN() *float64 { f := math.NaN(); return &f }
+func (nonFiniteResolver) Max() *float32 { f := float32(math.Inf(1)); return &f }
+func (nonFiniteResolver) Min() float64  { return math.Inf(-1) }
+func (nonFiniteResolver) Score() *scoreScalar {
+	s := scoreScalar(math.NaN())
+	return &s
+}
+func (nonFiniteResolver) Scores() []*scoreScalar {
+	a, b := scoreScalar(1.5), scoreScalar(math.Inf(1))
+	return []*scoreScalar{&a, &b}
+}
+
+func TestNonFiniteFloats(t *testing.T) {
+	t.Parallel()
+
+	const schemaString = `
+		type Query {
+			nan: Float
+			max: Float
+			min: Float!
+			score: Score
+			scores: [Score]!
+		}
+
+		scalar Score
+	`
+	query := `{ nan max score scores }`
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: graphql.MustParseSchema(schemaString, &nonFiniteResolver{}),
+			Query:  query,
+			ExpectedResult: `
+				{"nan": null, "max": null, "score": null, "scores": [1.5, null]}
+			`,
+			ExpectedErrors: []*gqlerrors.QueryError{
+				{Message: "Float cannot represent non numeric value: NaN", Path: []interface{}{"nan"}, Locations: []gqlerrors.Location{{Line: 1, Column: 3}}},
+				{Message: "Float cannot represent non numeric value: +Inf", Path: []interface{}{"max"}, Locations: []gqlerrors.Location{{Line: 1, Column: 7}}},
+				{Message: "Score cannot represent non numeric value: NaN", Path: []interface{}{"score"}, Locations: []gqlerrors.Location{{Line: 1, Column: 11}}},
+				{Message: "Score cannot represent non numeric value: +Inf", Path: []interface{}{"scores", 1}, Locations: []gqlerrors.Location{{Line: 1, Column: 17}}},
+			},
+		},
+		{
+			Schema: graphql.MustParseSchema(schemaString, &nonFiniteResolver{}, graphql.NonFiniteFloats(graphql.NonFiniteNull)),
+			Query:  query,
+			ExpectedResult: `
+				{"nan": null, "max": null, "score": null, "scores": [1.5, null]}
+			`,
+		},
+		{
+			Schema: graphql.MustParseSchema(schemaString, &nonFiniteResolver{}, graphql.NonFiniteFloats(graphql.NonFiniteString)),
+			Query:  query,
+			ExpectedResult: `
+				{"nan": "NaN", "max": "Infinity", "score": "NaN", "scores": [1.5, "Infinity"]}
+			`,
+		},
+		{
+			// Non-null fields fail with an error regardless of the policy.
+			Schema:         graphql.MustParseSchema(schemaString, &nonFiniteResolver{}, graphql.NonFiniteFloats(graphql.NonFiniteString)),
+			Query:          `{ nan min }`,
+			ExpectedResult: `null`,
+			ExpectedErrors: []*gqlerrors.QueryError{
+				{Message: "Float cannot represent non numeric value: -Inf", Path: []interface{}{"min"}, Locations: []gqlerrors.Location{{Line: 1, Column: 7}}},
+			},
+		},
+		{
+			Schema:         graphql.MustParseSchema(schemaString, &nonFiniteResolver{}, graphql.NonFiniteFloats(graphql.NonFiniteNull)),
+			Query:          `{ min }`,
+			ExpectedResult: `null`,
+			ExpectedErrors: []*gqlerrors.QueryError{
+				{Message: "Float cannot represent non numeric value: -Inf", Path: []interface{}{"min"}, Locations: []gqlerrors.Location{{Line: 1, Column: 3}}},
+			},
+		},
+	})
+}
+
+type enumRole int
+
+const (
+	enumRoleUser enumRole = iota + 1
+	enumRoleAdmin
+	enumRoleGuest
+)
+
+type enumValuesResolver struct{}
+
+func (enumValuesResolver) Role() enumRole { return enumRoleAdmin }
+
+func (enumValuesResolver) Roles() []enumRole { return []enumRole{enumRoleUser, enumRoleAdmin} }
+
+func (enumValuesResolver) Unknown() *enumRole {
+	r := enumRole(42)
+	return &r
+}
+
+func (enumValuesResolver) Promote(args struct {
+	Role  enumRole
+	Input struct{ Roles []enumRole }
+}) string {
+	return fmt.Sprint(int(args.Role), args.Input.Roles)
+}
+
+func TestEnumValues(t *testing.T) {
+	t.Parallel()
+
+	const schemaString = `
+		type Query {
+			role: Role!
+			roles: [Role!]!
+			unknown: Role
+			promote(role: Role = USER, input: PromoteInput!): String!
+		}
+
+		input PromoteInput {
+			roles: [Role!]!
+		}
+
+		enum Role {
+			USER
+			ADMIN
+		}
+	`
+	roles := map[string]interface{}{"USER": enumRoleUser, "ADMIN": enumRoleAdmin}
+	schema := graphql.MustParseSchema(schemaString, &enumValuesResolver{}, graphql.EnumValues("Role", roles))
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: schema,
+			Query: `
+				query($roles: [Role!]!) {
+					role
+					roles
+					admin: promote(role: ADMIN, input: {roles: [USER]})
+					default: promote(input: {roles: $roles})
+				}
+			`,
+			Variables: map[string]interface{}{"roles": []interface{}{"ADMIN", "USER"}},
+			ExpectedResult: `
+				{"role": "ADMIN", "roles": ["USER", "ADMIN"], "admin": "2 [1]", "default": "1 [2 1]"}
+			`,
+		},
+		{
+			Schema: schema,
+			Query:  `{ unknown }`,
+			ExpectedResult: `
+				{"unknown": null}
+			`,
+			ExpectedErrors: []*gqlerrors.QueryError{
+				{Message: "Invalid value 42.\nExpected type Role, found 42.", Path: []interface{}{"unknown"}, Locations: []gqlerrors.Location{{Line: 1, Column: 3}}},
+			},
+		},
+	})
+
+	for _, tc := range []struct {
+		enum   string
+		values map[string]interface{}
+		want   string
+	}{
+		{"Rank", roles, `enum values of "Rank": no such enum in the schema`},
+		{"Role", map[string]interface{}{"USER": enumRoleUser}, `enum values of "Role": missing Go value of ADMIN`},
+		{"Role", map[string]interface{}{"USER": enumRoleUser, "ADMIN": 2}, `enum values of "Role": Go value of ADMIN has type int instead of graphql_test.enumRole`},
+		{"Role", map[string]interface{}{"USER": enumRoleUser, "ADMIN": enumRoleUser}, `enum values of "Role": 1 is the Go value of both USER and ADMIN`},
+		{"Role", map[string]interface{}{"USER": enumRoleUser, "ADMIN": enumRoleAdmin, "GUEST": enumRoleGuest}, `enum values of "Role": GUEST is not a value of the enum`},
+	} {
+		_, err := graphql.ParseSchema(schemaString, &enumValuesResolver{}, graphql.EnumValues(tc.enum, tc.values))
+		if err == nil || err.Error() != tc.want {
+			t.Errorf("unexpected error %v, want %s", err, tc.want)
+		}
+	}
+}
+
+type sumTypeBook struct{ Title string }
+
+type sumTypeAuthor struct{ Name string }
+
+// sumTypeResult is a sum type holding exactly one member of the SearchResult union.
+type sumTypeResult struct {
+	Book   *sumTypeBook
+	Writer *sumTypeAuthor `graphql:"Author"`
+}
+
+type sumTypeResolver struct{}
+
+func (sumTypeResolver) Search() []sumTypeResult {
+	return []sumTypeResult{
+		{Book: &sumTypeBook{Title: "Dune"}},
+		{Writer: &sumTypeAuthor{Name: "Frank Herbert"}},
+	}
+}
+
+func (sumTypeResolver) First() *sumTypeResult { return nil }
+
+func TestSumTypeUnions(t *testing.T) {
+	t.Parallel()
+
+	schema := graphql.MustParseSchema(`
+		type Query {
+			search: [SearchResult!]!
+			first: SearchResult
+		}
+
+		union SearchResult = Book | Author
+
+		type Book {
+			title: String!
+		}
+
+		type Author {
+			name: String!
+		}
+	`, &sumTypeResolver{}, graphql.UseFieldResolvers())
+
+	gqltesting.RunTest(t, &gqltesting.Test{
+		Schema: schema,
+		Query: `
+			{
+				search {
+					__typename
+					... on Book { title }
+					... on Author { name }
+				}
+				first { __typename }
+			}
+		`,
+		ExpectedResult: `
+			{
+				"search": [
+					{"__typename": "Book", "title": "Dune"},
+					{"__typename": "Author", "name": "Frank Herbert"}
+				],
+				"first": null
+			}
+		`,
+	})
+
+	_, err := graphql.ParseSchema(`
+		type Query {
+			search: [SearchResult!]!
+		}
+
+		union SearchResult = Book | Magazine
+
+		type Book {
+			title: String!
+		}
+
+		type Magazine {
+			title: String!
+		}
+	`, &sumTypeResolver{}, graphql.UseFieldResolvers())
+	if err == nil || !strings.Contains(err.Error(), `graphql_test.sumTypeResult does not resolve "SearchResult": missing method "ToMagazine" to convert to "Magazine"`) {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+type inheritanceResolver struct{}
+
+func (inheritanceResolver) Node() *inheritanceNode { return &inheritanceNode{&inheritanceUser{}} }
+
+type inheritanceNode struct{ user *inheritanceUser }
+
+func (n *inheritanceNode) ID() graphql.ID                     { return "1" }
+func (n *inheritanceNode) Name() string                       { return "alice" }
+func (n *inheritanceNode) ToUser() (*inheritanceUser, bool)   { return n.user, n.user != nil }
+func (n *inheritanceNode) ToGroup() (*inheritanceGroup, bool) { return nil, false }
+
+type inheritanceUser struct{}
+
+func (*inheritanceUser) ID() graphql.ID { return "1" }
+func (*inheritanceUser) Name() string   { return "alice" }
+func (*inheritanceUser) Email() string  { return "alice@example.com" }
+
+type inheritanceGroup struct{}
+
+func (*inheritanceGroup) ID() graphql.ID { return "2" }
+
+func TestInterfaceInheritance(t *testing.T) {
+	t.Parallel()
+
+	schema := graphql.MustParseSchema(`
+		type Query {
+			node: Entity
+		}
+
+		interface Entity {
+			id: ID!
+		}
+
+		interface Node implements Entity {
+			id: ID!
+			name: String!
+		}
+
+		type User implements Node & Entity {
+			id: ID!
+			name: String!
+			email: String!
+		}
+
+		type Group implements Entity {
+			id: ID!
+		}
+	`, &inheritanceResolver{})
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: schema,
+			Query: `
+				{
+					node {
+						__typename
+						id
+						... on Node { name }
+						... on User { email }
+					}
+				}
+			`,
+			ExpectedResult: `
+				{"node": {"__typename": "User", "id": "1", "name": "alice", "email": "alice@example.com"}}
+			`,
+		},
+		{
+			Schema: schema,
+			Query: `
+				{
+					entity: __type(name: "Entity") { interfaces { name } possibleTypes { name } }
+					node: __type(name: "Node") { interfaces { name } possibleTypes { name } }
+				}
+			`,
+			ExpectedResult: `
+				{
+					"entity": {"interfaces": [], "possibleTypes": [{"name": "User"}, {"name": "Group"}]},
+					"node": {"interfaces": [{"name": "Entity"}], "possibleTypes": [{"name": "User"}]}
+				}
+			`,
+		},
+	})
+
+	if sdl := schema.SDL(); !strings.Contains(sdl, "interface Node implements Entity {") {
+		t.Errorf("SDL does not declare the interfaces of Node:\n%s", sdl)
+	}
+
+	for _, tc := range []struct {
+		sdl  string
+		want string
+	}{
+		{`
+			interface Entity { id: ID! }
+			interface Node implements Entity { name: String! }
+		`, `interface "Entity" expects field "id" but "Node" does not provide it`},
+		{`
+			interface Entity { id: ID! }
+			interface Node implements Entity { id: ID! }
+			type User implements Node { id: ID! }
+		`, `"User" must implement "Entity", which is implemented by "Node"`},
+		{`
+			interface Node implements Node { id: ID! }
+		`, `interface "Node" can not implement itself`},
+		{`
+			interface Entity implements Node { id: ID! }
+			interface Node implements Entity { id: ID! }
+		`, `can not implement itself through`},
+	} {
+		_, err := graphql.ParseSchema(`type Query { id: ID! }`+tc.sdl, nil)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("unexpected error %v, want %s", err, tc.want)
+		}
+	}
+}
+
+func TestSchemaDirectives(t *testing.T) {
+	t.Parallel()
+
+	schema := graphql.MustParseSchema(`
+		directive @link(url: String!, import: [String!]) repeatable on SCHEMA
+		directive @contact(name: String!, url: String = "https://example.com") on SCHEMA
+
+		schema @contact(name: "accounts") {
+			query: Query
+		}
+
+		extend schema
+			@link(url: "https://specs.apollo.dev/federation/v2.3", import: ["@key"])
+			@link(url: "https://specs.apollo.dev/link/v1.0")
+
+		type Query {
+			hello: String!
+		}
+	`, &helloWorldResolver1{})
+
+	directives := schema.SchemaDirectives()
+	want := []*graphql.Directive{
+		{Name: "contact", Arguments: map[string]interface{}{"name": "accounts", "url": "https://example.com"}, Definition: true},
+		{Name: "link", Arguments: map[string]interface{}{"url": "https://specs.apollo.dev/federation/v2.3", "import": []interface{}{"@key"}}, Definition: true},
+		{Name: "link", Arguments: map[string]interface{}{"url": "https://specs.apollo.dev/link/v1.0", "import": nil}, Definition: true},
+	}
+	if !reflect.DeepEqual(directives, want) {
+		t.Errorf("unexpected schema directives %#v", directives)
+	}
+
+	sdl := schema.SDL()
+	for _, s := range []string{
+		`directive @link(url: String!, import: [String!]) repeatable on SCHEMA`,
+		`schema @contact(name: "accounts", url: "https://example.com") @link(url: "https://specs.apollo.dev/federation/v2.3", import: ["@key"]) @link(url: "https://specs.apollo.dev/link/v1.0") {`,
+	} {
+		if !strings.Contains(sdl, s) {
+			t.Errorf("SDL does not contain %s:\n%s", s, sdl)
+		}
+	}
+
+	gqltesting.RunTest(t, &gqltesting.Test{
+		Schema: schema,
+		Query: `
+			{
+				__schema {
+					directives { name isRepeatable }
+				}
+			}
+		`,
+		ExpectedResult: `
+			{
+				"__schema": {
+					"directives": [
+						{"name": "contact", "isRepeatable": false},
+						{"name": "deprecated", "isRepeatable": false},
+						{"name": "include", "isRepeatable": false},
+						{"name": "length", "isRepeatable": false},
+						{"name": "link", "isRepeatable": true},
+						{"name": "pattern", "isRepeatable": false},
+						{"name": "range", "isRepeatable": false},
+						{"name": "skip", "isRepeatable": false}
+					]
+				}
+			}
+		`,
+	})
+
+	for _, tc := range []struct {
+		sdl  string
+		want string
+	}{
+		{`extend schema @link(url: "a")`, `directive "link" not found`},
+		{`
+			directive @link(url: String!) on OBJECT
+			extend schema @link(url: "a")
+		`, `invalid location "SCHEMA" for directive "link"`},
+	} {
+		_, err := graphql.ParseSchema(`type Query { hello: String! }`+tc.sdl, nil)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("unexpected error %v, want %s", err, tc.want)
+		}
+	}
+}
+
+type cursorResolver struct{}
+
+func (r *cursorResolver) Page(args struct{ After int32 }) int32 {
+	return args.After
+}
+
+func TestTransformVariables(t *testing.T) {
+	t.Parallel()
+
+	schema := graphql.MustParseSchema(`type Query { page(after: Int!): Int! }`, &cursorResolver{},
+		graphql.TransformVariables(func(ctx context.Context, operationName string, variables map[string]interface{}) (map[string]interface{}, error) {
+			if operationName == "Broken" {
+				return nil, fmt.Errorf("hook failed")
+			}
+			cursor, ok := variables["after"].(string)
+			if !ok {
+				return variables, nil
+			}
+			if !strings.HasPrefix(cursor, "cursor:") {
+				return nil, &graphql.VariableError{Name: "after", Err: fmt.Errorf("malformed cursor")}
+			}
+			offset, err := strconv.Atoi(strings.TrimPrefix(cursor, "cursor:"))
+			if err != nil {
+				return nil, &graphql.VariableError{Name: "after", Err: err}
+			}
+			variables["after"] = offset
+			return variables, nil
+		}),
+	)
+
+	query := `query Page($after: Int!) { page(after: $after) }`
+	gqltesting.RunTest(t, &gqltesting.Test{
+		Schema:         schema,
+		Query:          query,
+		Variables:      map[string]interface{}{"after": "cursor:20"},
+		ExpectedResult: `{"page": 20}`,
+	})
+
+	resp := schema.Exec(context.Background(), query, "", map[string]interface{}{"after": "20"})
+	if len(resp.Errors) != 1 || resp.Data != nil {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+	err := resp.Errors[0]
+	if err.Message != `Variable "after" has invalid value: malformed cursor` {
+		t.Errorf("unexpected message %q", err.Message)
+	}
+	if !reflect.DeepEqual(err.Locations, []gqlerrors.Location{{Line: 1, Column: 12}}) {
+		t.Errorf("unexpected locations %v", err.Locations)
+	}
+	if !reflect.DeepEqual(err.Extensions, map[string]interface{}{"variable": "after"}) {
+		t.Errorf("unexpected extensions %v", err.Extensions)
+	}
+
+	resp = schema.Exec(context.Background(), `query Broken { page(after: 1) }`, "Broken", nil)
+	if len(resp.Errors) != 1 || resp.Errors[0].Message != "could not process variables: hook failed" {
+		t.Errorf("unexpected errors %v", resp.Errors)
+	}
+}
+
+type apiKey struct{}
+
+func TestComplexityBudget(t *testing.T) {
+	t.Parallel()
+
+	budget := graphql.NewWindowBudget(10, time.Hour, func(ctx context.Context) string {
+		key, _ := ctx.Value(apiKey{}).(string)
+		return key
+	})
+	schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}, graphql.ComplexityBudget(budget))
+
+	alice := context.WithValue(context.Background(), apiKey{}, "alice")
+	bob := context.WithValue(context.Background(), apiKey{}, "bob")
+	query := `{ hero { name friends { name } } }`
+	for i := 0; i < 2; i++ {
+		if resp := schema.Exec(alice, query, "", nil); len(resp.Errors) != 0 {
+			t.Fatalf("unexpected errors %v", resp.Errors)
+		}
+	}
+	if remaining := budget.Remaining(alice); remaining != 2 {
+		t.Errorf("unexpected remaining budget %d", remaining)
+	}
+
+	resp := schema.Exec(alice, query, "", nil)
+	if len(resp.Errors) != 1 || resp.Data != nil {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+	err := resp.Errors[0]
+	if err.Message != "operation costs 4 points, which exceeds the remaining 2 points of the budget" {
+		t.Errorf("unexpected message %q", err.Message)
+	}
+	if err.Extensions["code"] != "BUDGET_EXCEEDED" || err.Extensions["remaining"] != 2 || err.Extensions["resetAt"] == nil {
+		t.Errorf("unexpected extensions %v", err.Extensions)
+	}
+	if resp := schema.Exec(alice, `{ hero { name } }`, "", nil); len(resp.Errors) != 0 {
+		t.Errorf("unexpected errors %v", resp.Errors)
+	}
+	if resp := schema.Exec(bob, query, "", nil); len(resp.Errors) != 0 {
+		t.Errorf("unexpected errors %v", resp.Errors)
+	}
+	if remaining := budget.Remaining(alice); remaining != 0 {
+		t.Errorf("unexpected remaining budget %d", remaining)
+	}
+}
+
+type liveResolver struct {
+	mu     sync.Mutex
+	counts map[string]int32
+}
+
+func (r *liveResolver) Count(ctx context.Context, args struct{ Name string }) int32 {
+	graphql.RegisterInvalidationKeys(ctx, "Counter:"+args.Name)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.counts[args.Name]
+}
+
+func (r *liveResolver) increment(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.counts[name]++
+}
+
+func TestLiveQueries(t *testing.T) {
+	t.Parallel()
+
+	hub := graphql.NewLiveQueryHub()
+	resolver := &liveResolver{counts: make(map[string]int32)}
+	schema := graphql.MustParseSchema(`
+		directive @live on QUERY
+		type Query { count(name: String!): Int! }
+	`, resolver, graphql.LiveQueries(hub))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c, err := schema.Subscribe(ctx, `query @live { a: count(name: "a") }`, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := func() string {
+		select {
+		case resp := <-c:
+			r := resp.(*graphql.Response)
+			if len(r.Errors) != 0 {
+				t.Fatalf("unexpected errors %v", r.Errors)
+			}
+			return string(r.Data)
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for a live query result")
+			return ""
+		}
+	}
+
+	if data := next(); data != `{"a":0}` {
+		t.Errorf("unexpected data %s", data)
+	}
+	resolver.increment("b")
+	hub.Invalidate("Counter:b")
+	resolver.increment("a")
+	hub.Invalidate("Counter:a")
+	if data := next(); data != `{"a":1}` {
+		t.Errorf("unexpected data %s", data)
+	}
+	// Results that did not change are not sent.
+	hub.Invalidate("Counter:a")
+	resolver.increment("a")
+	hub.Invalidate("Counter:a")
+	if data := next(); data != `{"a":2}` {
+		t.Errorf("unexpected data %s", data)
+	}
+
+	cancel()
+	select {
+	case _, ok := <-c:
+		if ok {
+			t.Error("unexpected result after cancellation")
+		}
+	case <-time.After(time.Second):
+		t.Error("channel was not closed after cancellation")
+	}
+
+	resp := schema.Exec(context.Background(), `query @live { a: count(name: "a") }`, "", nil)
+	if len(resp.Errors) != 0 || string(resp.Data) != `{"a":2}` {
+		t.Errorf("unexpected response %+v", resp)
+	}
+
+	_, err = graphql.ParseSchema(`type Query { count(name: String!): Int! }`, nil, graphql.LiveQueries(hub))
+	if err == nil || err.Error() != `LiveQueries requires the declaration "directive @live on QUERY"` {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+type failingLiveResolver struct {
+	calls int32
+}
+
+func (r *failingLiveResolver) V(ctx context.Context) (*int32, error) {
+	graphql.RegisterInvalidationKeys(ctx, "V")
+	n := atomic.AddInt32(&r.calls, 1)
+	if n == 1 {
+		return nil, fmt.Errorf("first run fails")
+	}
+	return &n, nil
+}
+
+func TestLiveQueries_errorsOfPreviousExecution(t *testing.T) {
+	t.Parallel()
+
+	hub := graphql.NewLiveQueryHub()
+	schema := graphql.MustParseSchema(`
+		directive @live on QUERY
+		type Query { v: Int }
+	`, &failingLiveResolver{}, graphql.LiveQueries(hub))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c, err := schema.Subscribe(ctx, `query @live { v }`, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := func() *graphql.Response {
+		select {
+		case resp := <-c:
+			return resp.(*graphql.Response)
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for a live query result")
+			return nil
+		}
+	}
+
+	if resp := next(); len(resp.Errors) != 1 || resp.Errors[0].Message != "first run fails" {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+	hub.Invalidate("V")
+	if resp := next(); len(resp.Errors) != 0 || string(resp.Data) != `{"v":2}` {
+		t.Fatalf("unexpected response %s %v", resp.Data, resp.Errors)
+	}
+}
+
+type cachedUser struct {
+	id      string
+	name    string
+	friends []*cachedUser
+	calls   *int32
+}
+
+func (u *cachedUser) EntityKey() string {
+	return "User:" + u.id
+}
+
+func (u *cachedUser) Name() string {
+	atomic.AddInt32(u.calls, 1)
+	return u.name
+}
+
+func (u *cachedUser) Friends() []*cachedUser {
+	return u.friends
+}
+
+func (u *cachedUser) Greeting(args struct{ Prefix string }) (*string, error) {
+	if args.Prefix == "" {
+		return nil, fmt.Errorf("missing prefix")
+	}
+	greeting := args.Prefix + " " + u.name
+	return &greeting, nil
+}
+
+type entityCacheResolver struct {
+	users map[string]*cachedUser
+}
+
+func (r *entityCacheResolver) User(args struct{ ID string }) *cachedUser {
+	return r.users[args.ID]
+}
+
+func (r *entityCacheResolver) Rename(args struct{ ID, Name string }) *cachedUser {
+	u := r.users[args.ID]
+	u.name = args.Name
+	return u
+}
+
+func TestCacheEntities(t *testing.T) {
+	t.Parallel()
+
+	var calls int32
+	alice := &cachedUser{id: "1", name: "Alice", calls: &calls}
+	bob := &cachedUser{id: "2", name: "Bob", calls: &calls}
+	alice.friends = []*cachedUser{bob}
+	cache := graphql.NewEntityCache(0)
+	schema := graphql.MustParseSchema(`
+		schema { query: Query mutation: Mutation }
+		type Query { user(id: ID!): User }
+		type Mutation { rename(id: ID!, name: String!): User }
+		type User { name: String! friends: [User!]! greeting(prefix: String!): String }
+	`, &entityCacheResolver{users: map[string]*cachedUser{"1": alice, "2": bob}}, graphql.CacheEntities(cache))
+
+	exec := func(query string, wantCalls int32, want string) {
+		t.Helper()
+		atomic.StoreInt32(&calls, 0)
+		resp := schema.Exec(context.Background(), query, "", nil)
+		if string(resp.Data) != want {
+			t.Errorf("unexpected data %s, want %s", resp.Data, want)
+		}
+		if n := atomic.LoadInt32(&calls); n != wantCalls {
+			t.Errorf("%s: unexpected number of resolver calls %d, want %d", query, n, wantCalls)
+		}
+	}
+
+	query := `{ user(id: "1") { name friends { name } } }`
+	exec(query, 2, `{"user":{"name":"Alice","friends":[{"name":"Bob"}]}}`)
+	exec(query, 0, `{"user":{"name":"Alice","friends":[{"name":"Bob"}]}}`)
+	if n := cache.Len(); n != 2 {
+		t.Errorf("unexpected number of cached results %d", n)
+	}
+	// Bob's result is reused within another selection.
+	exec(`{ user(id: "1") { friends { name } } }`, 0, `{"user":{"friends":[{"name":"Bob"}]}}`)
+	exec(`{ user(id: "2") { name } }`, 0, `{"user":{"name":"Bob"}}`)
+	exec(`{ user(id: "2") { n: name } }`, 1, `{"user":{"n":"Bob"}}`)
+
+	// Invalidating Bob purges Alice's results, which include him.
+	cache.Invalidate("User:2")
+	if n := cache.Len(); n != 0 {
+		t.Errorf("unexpected number of cached results %d after invalidation", n)
+	}
+	exec(query, 2, `{"user":{"name":"Alice","friends":[{"name":"Bob"}]}}`)
+
+	// Results with errors are not cached.
+	exec(`{ user(id: "2") { name greeting(prefix: "") } }`, 1, `{"user":{"name":"Bob","greeting":null}}`)
+	exec(`{ user(id: "2") { name greeting(prefix: "") } }`, 1, `{"user":{"name":"Bob","greeting":null}}`)
+
+	// Mutations do not use the cache.
+	exec(`mutation { rename(id: "1", name: "Alicia") { name } }`, 1, `{"rename":{"name":"Alicia"}}`)
+	exec(query, 0, `{"user":{"name":"Alice","friends":[{"name":"Bob"}]}}`)
+	cache.Invalidate("User:1")
+	exec(query, 1, `{"user":{"name":"Alicia","friends":[{"name":"Bob"}]}}`)
+}
+
+var errTemporary = errors.New("temporary failure")
+
+type flakyResolver struct {
+	mu       sync.Mutex
+	calls    map[string]int
+	failures map[string]int
+	err      error
+}
+
+func (r *flakyResolver) call(field string) (*string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls[field]++
+	if r.calls[field] <= r.failures[field] {
+		return nil, r.err
+	}
+	result := field
+	return &result, nil
+}
+
+func (r *flakyResolver) Directive() (*string, error) { return r.call("directive") }
+func (r *flakyResolver) Option() (*string, error)    { return r.call("option") }
+func (r *flakyResolver) Plain() (*string, error)     { return r.call("plain") }
+
+func TestRetryField(t *testing.T) {
+	t.Parallel()
+
+	sdl := `
+		directive @retry(attempts: Int!, backoff: String) on FIELD_DEFINITION
+		type Query {
+			directive: String @retry(attempts: 3, backoff: "1ms")
+			option: String
+			plain: String
+		}
+	`
+	newSchema := func(r *flakyResolver) *graphql.Schema {
+		return graphql.MustParseSchema(sdl, r, graphql.RetryField("Query.option", graphql.RetryPolicy{
+			MaxAttempts: 2,
+			RetryOn:     func(err error) bool { return err == errTemporary },
+		}))
+	}
+
+	r := &flakyResolver{
+		calls:    make(map[string]int),
+		failures: map[string]int{"directive": 2, "option": 1, "plain": 1},
+		err:      errTemporary,
+	}
+	resp := newSchema(r).Exec(context.Background(), `{ directive option plain }`, "", nil)
+	if string(resp.Data) != `{"directive":"directive","option":"option","plain":null}` || len(resp.Errors) != 1 {
+		t.Errorf("unexpected response %s %v", resp.Data, resp.Errors)
+	}
+	if want := map[string]int{"directive": 3, "option": 2, "plain": 1}; !reflect.DeepEqual(r.calls, want) {
+		t.Errorf("unexpected calls %v, want %v", r.calls, want)
+	}
+
+	// The error of the last attempt is returned, and errors rejected by RetryOn are not retried.
+	r = &flakyResolver{
+		calls:    make(map[string]int),
+		failures: map[string]int{"directive": 5, "option": 5},
+		err:      errors.New("permanent failure"),
+	}
+	resp = newSchema(r).Exec(context.Background(), `{ directive option }`, "", nil)
+	if string(resp.Data) != `{"directive":null,"option":null}` || len(resp.Errors) != 2 {
+		t.Errorf("unexpected response %s %v", resp.Data, resp.Errors)
+	}
+	if want := map[string]int{"directive": 3, "option": 1}; !reflect.DeepEqual(r.calls, want) {
+		t.Errorf("unexpected calls %v, want %v", r.calls, want)
+	}
+
+	mutationSDL := sdl + `
+		schema { query: Query mutation: Mutation }
+		type Mutation { plain: String }
+	`
+	for _, tc := range []struct {
+		opt  graphql.SchemaOpt
+		sdl  string
+		want string
+	}{
+		{graphql.RetryField("Query.missing", graphql.RetryPolicy{MaxAttempts: 2}), sdl, `retry policy of "Query.missing": "missing" is not defined on type "Query"`},
+		{graphql.RetryField("Query", graphql.RetryPolicy{MaxAttempts: 2}), sdl, `retry policy of "Query": not a field`},
+		{graphql.RetryField("Query.plain", graphql.RetryPolicy{}), sdl, `retry policy of "Query.plain": MaxAttempts must be positive`},
+		{nil, strings.Replace(sdl, `attempts: 3`, `attempts: 0`, 1), `directive @retry on field "directive": attempts must be a positive integer`},
+		{nil, strings.Replace(sdl, `"1ms"`, `"soon"`, 1), `directive @retry on field "directive": time: invalid duration "soon"`},
+		{graphql.RetryField("Mutation.plain", graphql.RetryPolicy{MaxAttempts: 2}), mutationSDL, `retry policy of "Mutation.plain": fields of the mutation root type are not retried`},
+		{nil, strings.Replace(mutationSDL, `type Mutation { plain: String }`, `type Mutation { plain: String @retry(attempts: 2) }`, 1), `directive @retry on field "plain": fields of the mutation root type are not retried`},
+	} {
+		var opts []graphql.SchemaOpt
+		if tc.opt != nil {
+			opts = append(opts, tc.opt)
+		}
+		_, err := graphql.ParseSchema(tc.sdl, &flakyResolver{}, opts...)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("unexpected error %v, want %s", err, tc.want)
+		}
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	r := &flakyResolver{
+		calls:    make(map[string]int),
+		failures: map[string]int{"option": 3},
+		err:      errTemporary,
+	}
+	breaker := graphql.NewCircuitBreaker(graphql.CircuitBreakerConfig{FailureThreshold: 2, OpenTimeout: 50 * time.Millisecond})
+	schema := graphql.MustParseSchema(`type Query { directive: String option: String plain: String }`, r, graphql.UseCircuitBreaker(breaker))
+
+	exec := func(wantMessage string, wantCalls int) {
+		t.Helper()
+		resp := schema.Exec(context.Background(), `{ option plain }`, "", nil)
+		if wantMessage == "" {
+			if len(resp.Errors) != 0 {
+				t.Errorf("unexpected errors %v", resp.Errors)
+			}
+		} else if len(resp.Errors) != 1 || resp.Errors[0].Message != wantMessage {
+			t.Errorf("unexpected errors %v, want %s", resp.Errors, wantMessage)
+		}
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if r.calls["option"] != wantCalls {
+			t.Errorf("unexpected calls %d, want %d", r.calls["option"], wantCalls)
+		}
+	}
+
+	exec("temporary failure", 1)
+	exec("temporary failure", 2)
+	// The circuit is open, so the resolver is not called while other fields are resolved.
+	exec(`circuit breaker of "Query.option" is open`, 2)
+	resp := schema.Exec(context.Background(), `{ option }`, "", nil)
+	if len(resp.Errors) != 1 || !reflect.DeepEqual(resp.Errors[0].Extensions, map[string]interface{}{"code": "CIRCUIT_OPEN"}) {
+		t.Errorf("unexpected errors %v", resp.Errors)
+	}
+
+	// The failing trial call opens the circuit again, the succeeding one closes it.
+	time.Sleep(60 * time.Millisecond)
+	exec("temporary failure", 3)
+	exec(`circuit breaker of "Query.option" is open`, 3)
+	time.Sleep(60 * time.Millisecond)
+	exec("", 4)
+	exec("", 5)
+}
+
+// recordingBreaker fails the fields of open coordinates and records the outcomes reported to it.
+type recordingBreaker struct {
+	mu       sync.Mutex
+	open     map[string]bool
+	outcomes []string
+}
+
+func (b *recordingBreaker) Allow(coordinate string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.open[coordinate] {
+		return fmt.Errorf("circuit of %q is open", coordinate)
+	}
+	return nil
+}
+
+func (b *recordingBreaker) Done(coordinate string, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.outcomes = append(b.outcomes, fmt.Sprintf("%s: %v", coordinate, err))
+}
+
+func TestCircuitBreaker_onlyResolverCalls(t *testing.T) {
+	t.Parallel()
+
+	r := &flakyResolver{calls: make(map[string]int)}
+	breaker := &recordingBreaker{open: map[string]bool{"Query.option": true}}
+	schema := graphql.MustParseSchema(`
+		directive @redact(when: String!, placeholder: String) on FIELD_DEFINITION
+		type Query { directive: String option: String @redact(when: "always") plain: String }
+	`, r, graphql.UseCircuitBreaker(breaker), graphql.RedactionPredicate("always", func(ctx context.Context) bool {
+		return true
+	}))
+
+	// A redacted field does not call its resolver, so it neither fails on an open circuit nor
+	// reports an outcome.
+	resp := schema.Exec(context.Background(), `{ option }`, "", nil)
+	if string(resp.Data) != `{"option":null}` || len(resp.Errors) != 0 {
+		t.Errorf("unexpected response %s %v", resp.Data, resp.Errors)
+	}
+
+	// Fields skipped because the request was cancelled are not reported as failures.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	schema.Exec(ctx, `{ plain }`, "", nil)
+
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+	if len(breaker.outcomes) != 0 {
+		t.Errorf("unexpected outcomes %v", breaker.outcomes)
+	}
+	if len(r.calls) != 0 {
+		t.Errorf("unexpected calls %v", r.calls)
+	}
+}
+
+type panickingResolver struct{}
+
+func (r *panickingResolver) User() *panickingResolver { return r }
+
+func (r *panickingResolver) Name() string {
+	panic("name is broken")
+}
+
+type panicLogger struct {
+	mu     sync.Mutex
+	panics []*log.Panic
+}
+
+func (l *panicLogger) LogPanic(ctx context.Context, value interface{}) {
+	panic("LogPanicInfo should be used")
+}
+
+func (l *panicLogger) LogPanicInfo(ctx context.Context, p *log.Panic) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.panics = append(l.panics, p)
+}
+
+func TestReportPanics(t *testing.T) {
+	t.Parallel()
+
+	logger := &panicLogger{}
+	var reported []*log.Panic
+	schema := graphql.MustParseSchema(`
+		type Query { user: User }
+		type User { name: String! }
+	`, &panickingResolver{},
+		graphql.Logger(logger),
+		graphql.ReportPanics(func(ctx context.Context, p *log.Panic) {
+			reported = append(reported, p)
+		}),
+		graphql.PanicStackTraces(),
+	)
+
+	resp := schema.Exec(context.Background(), `query GetUser { user { name } }`, "", nil)
+	if len(resp.Errors) != 1 {
+		t.Fatalf("unexpected errors %v", resp.Errors)
+	}
+	err := resp.Errors[0]
+	if err.Message != "panic occurred: name is broken" || !reflect.DeepEqual(err.Path, []interface{}{"user", "name"}) {
+		t.Errorf("unexpected error %+v", err)
+	}
+	stack, _ := err.Extensions["stacktrace"].([]string)
+	if len(stack) == 0 || !strings.Contains(strings.Join(stack, "\n"), "panickingResolver).Name") {
+		t.Errorf("stack trace does not contain the resolver: %v", stack)
+	}
+
+	if len(reported) != 1 || len(logger.panics) != 1 || reported[0] != logger.panics[0] {
+		t.Fatalf("unexpected reported panics %v, logged %v", reported, logger.panics)
+	}
+	p := reported[0]
+	if p.Value != "name is broken" || p.OperationName != "GetUser" || !reflect.DeepEqual(p.Path, []interface{}{"user", "name"}) {
+		t.Errorf("unexpected panic %+v", p)
+	}
+	if !strings.Contains(string(p.Stack), "panickingResolver).Name") {
+		t.Errorf("stack trace does not contain the resolver:\n%s", p.Stack)
+	}
+}
+
+type slowResolver struct{}
+
+func (*slowResolver) Slow() int32 {
+	time.Sleep(20 * time.Millisecond)
+	return 1
+}
+
+func (*slowResolver) Fast() int32 {
+	return 2
+}
+
+func TestLogSlowOperations(t *testing.T) {
+	t.Parallel()
+
+	var logged []*graphql.SlowOperation
+	schema := graphql.MustParseSchema(`
+		type Query { slow: Int! fast: Int! }
+	`, &slowResolver{},
+		graphql.LogSlowOperations(graphql.SlowOperationLog{
+			Threshold: 10 * time.Millisecond,
+			Log: func(ctx context.Context, op *graphql.SlowOperation) {
+				logged = append(logged, op)
+			},
+		}),
+	)
+
+	schema.Exec(context.Background(), `query Fast { fast }`, "", nil)
+	if len(logged) != 0 {
+		t.Fatalf("fast operation logged: %+v", logged[0])
+	}
+
+	schema.Exec(context.Background(), `query Slow { fast slow }`, "", nil)
+	if len(logged) != 1 {
+		t.Fatalf("got %d logged operations, want 1", len(logged))
+	}
+	op := logged[0]
+	if op.OperationName != "Slow" || op.Duration < 20*time.Millisecond || len(op.Fields) != 2 {
+		t.Fatalf("unexpected slow operation %+v", op)
+	}
+	if f := op.Fields[0]; f.Coordinate != "Query.slow" || f.Count != 1 || f.Max < 20*time.Millisecond {
+		t.Errorf("unexpected slowest field %+v", f)
+	}
+}
+
+type profiledResolver struct{}
+
+func (*profiledResolver) Labels(ctx context.Context) ([]string, error) {
+	operation, _ := pprof.Label(ctx, "graphql.operation")
+	client, _ := pprof.Label(ctx, "graphql.client")
+	return []string{operation, client}, nil
+}
+
+func TestProfileOperations(t *testing.T) {
+	t.Parallel()
+
+	schema := graphql.MustParseSchema(`
+		type Query { labels: [String!]! }
+	`, &profiledResolver{}, graphql.ProfileOperations())
+
+	ctx := graphql.WithClientInfo(context.Background(), graphql.ClientInfo{Name: "web"})
+	resp := schema.Exec(ctx, `query GetLabels { labels }`, "", nil)
+	if len(resp.Errors) != 0 {
+		t.Fatal(resp.Errors)
+	}
+	if want := `{"labels":["GetLabels","web"]}`; string(resp.Data) != want {
+		t.Errorf("got %s, want %s", resp.Data, want)
+	}
+}
+
+type shutdownResolver struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (r *shutdownResolver) Slow(ctx context.Context) (string, error) {
+	r.started <- struct{}{}
+	select {
+	case <-r.release:
+		return "done", nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func (r *shutdownResolver) Ticks(ctx context.Context) <-chan int32 {
+	c := make(chan int32)
+	go func() {
+		<-ctx.Done()
+		close(c)
+	}()
+	return c
+}
+
+func (r *shutdownResolver) Counter(ctx context.Context) <-chan int32 {
+	c := make(chan int32)
+	go func() {
+		defer close(c)
+		for i := int32(0); ; i++ {
+			select {
+			case c <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return c
+}
+
+func TestShutdown(t *testing.T) {
+	t.Parallel()
+
+	const schemaString = `
+		type Query { slow: String! }
+		type Subscription { ticks: Int! counter: Int! }
+	`
+	newSchema := func() (*graphql.Schema, *shutdownResolver) {
+		r := &shutdownResolver{started: make(chan struct{}), release: make(chan struct{})}
+		return graphql.MustParseSchema(schemaString, r), r
+	}
+
+	t.Run("drain", func(t *testing.T) {
+		schema, r := newSchema()
+		sub, err := schema.Subscribe(context.Background(), `subscription { ticks }`, "", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result := make(chan *graphql.Response)
+		go func() { result <- schema.Exec(context.Background(), `{ slow }`, "", nil) }()
+		<-r.started
+
+		shutdown := make(chan error)
+		go func() { shutdown <- schema.Shutdown(context.Background()) }()
+		for range sub {
+		}
+		if _, err := schema.Subscribe(context.Background(), `subscription { ticks }`, "", nil); err != graphql.ErrShuttingDown {
+			t.Errorf("Subscribe after Shutdown: got %v", err)
+		}
+		resp := schema.Exec(context.Background(), `{ slow }`, "", nil)
+		if len(resp.Errors) != 1 || resp.Errors[0].Code() != graphql.ErrCodeShuttingDown {
+			t.Errorf("Exec after Shutdown: got %+v", resp.Errors)
+		}
+
+		close(r.release)
+		if resp := <-result; string(resp.Data) != `{"slow":"done"}` {
+			t.Errorf("in-flight operation: got %s %v", resp.Data, resp.Errors)
+		}
+		if err := <-shutdown; err != nil {
+			t.Errorf("Shutdown: got %v", err)
+		}
+	})
+
+	t.Run("grace period", func(t *testing.T) {
+		schema, r := newSchema()
+		result := make(chan *graphql.Response)
+		go func() { result <- schema.Exec(context.Background(), `{ slow }`, "", nil) }()
+		<-r.started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		if err := schema.Shutdown(ctx); err != context.DeadlineExceeded {
+			t.Errorf("Shutdown: got %v, want %v", err, context.DeadlineExceeded)
+		}
+		if resp := <-result; len(resp.Errors) != 1 {
+			t.Errorf("cancelled operation: got %s %v", resp.Data, resp.Errors)
+		}
+	})
+
+	t.Run("subscription not received from", func(t *testing.T) {
+		schema, _ := newSchema()
+		ctx, cancel := context.WithCancel(context.Background())
+		sub, err := schema.Subscribe(ctx, `subscription { counter }`, "", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		<-sub
+		time.Sleep(20 * time.Millisecond) // the next response is pending
+		// The transport stops receiving when its context is cancelled, so the subscription must
+		// finish without sending its remaining responses.
+		cancel()
+		time.Sleep(20 * time.Millisecond)
+		select {
+		case _, ok := <-sub:
+			if ok {
+				t.Error("got a response after the context was cancelled")
+			}
+		case <-time.After(time.Second):
+			t.Error("subscription was not finished")
+		}
+	})
+}
+
+type productsResolver struct {
+	resolved int32
+}
+
+func (r *productsResolver) Products(ctx context.Context) ([]*productResolver, error) {
+	products := make([]*productResolver, 100)
+	for i := range products {
+		products[i] = &productResolver{parent: r, id: int32(i)}
+	}
+	return products, nil
+}
+
+type productResolver struct {
+	parent *productsResolver
+	id     int32
+}
+
+func (p *productResolver) Name(ctx context.Context) (string, error) {
+	atomic.AddInt32(&p.parent.resolved, 1)
+	return fmt.Sprintf("product %d", p.id), nil
+}
+
+func TestMaxResponseBytes(t *testing.T) {
+	t.Parallel()
+
+	const schemaString = `
+		type Query { products: [Product!]! }
+		type Product { name: String! }
+	`
+
+	r := &productsResolver{}
+	schema := graphql.MustParseSchema(schemaString, r, graphql.MaxResponseBytes(500), graphql.MaxParallelism(1))
+	resp := schema.Exec(context.Background(), `{ products { name } }`, "", nil)
+	if resp.Data != nil || len(resp.Errors) != 1 {
+		t.Fatalf("unexpected response %s %v", resp.Data, resp.Errors)
+	}
+	if err := resp.Errors[0]; err.Message != "response exceeds the limit of 500 bytes" || err.Code() != "RESPONSE_TOO_LARGE" {
+		t.Errorf("unexpected error %+v", err)
+	}
+	if n := atomic.LoadInt32(&r.resolved); n >= 100 {
+		t.Errorf("all %d resolvers were called", n)
+	}
+
+	schema = graphql.MustParseSchema(schemaString, &productsResolver{}, graphql.MaxResponseBytes(5000))
+	resp = schema.Exec(context.Background(), `{ products { name } }`, "", nil)
+	if len(resp.Errors) != 0 || len(resp.Data) < 2000 {
+		t.Errorf("unexpected response %s %v", resp.Data, resp.Errors)
+	}
+}
+
+type listLengthResolver struct{}
+
+func (*listLengthResolver) Small() *[]int32 {
+	return &[]int32{1, 2, 3, 4, 5}
+}
+
+func (*listLengthResolver) Allowed() []int32 {
+	return []int32{1, 2, 3, 4, 5}
+}
+
+func (*listLengthResolver) Matrix() [][]int32 {
+	return [][]int32{{1, 2}, {1, 2, 3, 4}}
+}
+
+func TestMaxListLength(t *testing.T) {
+	t.Parallel()
+
+	schema := graphql.MustParseSchema(`
+		directive @maxListLength(max: Int!) on FIELD_DEFINITION
+
+		type Query {
+			small: [Int!]
+			allowed: [Int!]! @maxListLength(max: 10)
+			matrix: [[Int!]]!
+		}
+	`, &listLengthResolver{}, graphql.MaxListLength(3))
+
+	resp := schema.Exec(context.Background(), `{ small allowed matrix }`, "", nil)
+	if want := `{"small":null,"allowed":[1,2,3,4,5],"matrix":[[1,2],null]}`; string(resp.Data) != want {
+		t.Errorf("got %s, want %s", resp.Data, want)
+	}
+	if len(resp.Errors) != 2 {
+		t.Fatalf("unexpected errors %v", resp.Errors)
+	}
+	sort.Slice(resp.Errors, func(i, j int) bool { return resp.Errors[i].Path[0].(string) < resp.Errors[j].Path[0].(string) })
+	for i, want := range []struct {
+		message string
+		path    []interface{}
+	}{
+		{"list of 4 entries exceeds the limit of 3 entries", []interface{}{"matrix", 1}},
+		{"list of 5 entries exceeds the limit of 3 entries", []interface{}{"small"}},
+	} {
+		err := resp.Errors[i]
+		if err.Message != want.message || !reflect.DeepEqual(err.Path, want.path) || err.Code() != "LIST_TOO_LONG" {
+			t.Errorf("unexpected error %+v", err)
+		}
+	}
+
+	_, err := graphql.ParseSchema(`
+		directive @maxListLength(max: Int!) on FIELD_DEFINITION
+		type Query { slow: Int! @maxListLength(max: 1) fast: Int! }
+	`, &slowResolver{})
+	if err == nil || !strings.HasPrefix(err.Error(), `directive @maxListLength on field "slow": field must be a list`) {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestIntrospectionLimits(t *testing.T) {
+	t.Parallel()
+
+	schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{},
+		graphql.MaxIntrospectionDepth(13),
+		graphql.MaxOfTypeDepth(7),
+	)
+	if _, err := schema.ToJSON(); err != nil {
+		t.Fatal(err)
+	}
+
+	gqltesting.RunTest(t, &gqltesting.Test{
+		Schema: schema,
+		Query: `
+			{
+				__type(name: "Droid") {
+					fields { type { ofType { ofType { ofType { ofType { ofType { ofType { ofType { ofType { name } } } } } } } } } }
+				}
+			}
+		`,
+		ExpectedErrors: []*gqlerrors.QueryError{{
+			Message:   `Introspection field "__type" nests 8 ofType fields, which exceeds max ofType depth 7`,
+			Locations: []gqlerrors.Location{{Line: 3, Column: 5}},
+			Rule:      "MaxOfTypeDepthExceeded",
+		}},
+	})
+
+	gqltesting.RunTest(t, &gqltesting.Test{
+		Schema: schema,
+		Query: `
+			{
+				__schema { types { fields { type { fields { type { fields { type { fields { type { fields { type { fields { type { name } } } } } } } } } } } } } }
+			}
+		`,
+		ExpectedErrors: []*gqlerrors.QueryError{{
+			Message:   `Introspection field "__schema" has depth 15 that exceeds max introspection depth 13`,
+			Locations: []gqlerrors.Location{{Line: 3, Column: 5}},
+			Rule:      "MaxIntrospectionDepthExceeded",
+		}},
+	})
+
+	// The limits apply to the documents of clients, not to the introspection query of ToJSON.
+	limited := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{},
+		graphql.MaxDepth(3),
+		graphql.MaxIntrospectionDepth(5),
+		graphql.MaxOfTypeDepth(3),
+		graphql.MaxResponseBytes(100),
+		graphql.MaxListLength(1),
+		graphql.DeduplicateRequests(nil),
+	)
+	if _, err := limited.ToJSON(); err != nil {
+		t.Fatal(err)
+	}
+	version, err := schema.Version()
+	if err != nil {
+		t.Fatal(err)
+	}
+	limitedVersion, err := limited.Version()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if limitedVersion != version {
+		t.Error("expected the limits not to change the version of the schema")
+	}
+	gqltesting.RunTest(t, &gqltesting.Test{
+		Schema:         limited,
+		Query:          `{ hero { name } }`,
+		ExpectedResult: `{"hero": {"name": "R2-D2"}}`,
+	})
+}
+
+type customRootQuery struct{}
+
+func (r *customRootQuery) Hello() string { return "Hello world!" }
+
+type customRootMutation struct{}
+
+func (r *customRootMutation) SetGreeting(args struct{ Greeting string }) string {
+	return args.Greeting
+}
+
+type customRootResolver struct {
+	customRootQuery
+	customRootMutation
+}
+
+func TestCustomRootOperationTypes(t *testing.T) {
+	t.Parallel()
+
+	schema := graphql.MustParseSchema(`
+		schema {
+			query: MyQuery
+			mutation: MyMutation
+		}
+
+		type MyQuery {
+			hello: String!
+		}
+
+		type MyMutation {
+			setGreeting(greeting: String!): String!
+		}
+	`, &customRootResolver{})
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: schema,
+			Query: `
+				{
+					__typename
+					hello
+					__schema { queryType { name } mutationType { name } subscriptionType { name } }
+				}
+			`,
+			ExpectedResult: `
+				{
+					"__typename": "MyQuery",
+					"hello": "Hello world!",
+					"__schema": { "queryType": { "name": "MyQuery" }, "mutationType": { "name": "MyMutation" }, "subscriptionType": null }
+				}
+			`,
+		},
+		{
+			Schema: schema,
+			Query: `
+				mutation {
+					__typename
+					setGreeting(greeting: "Hi")
+				}
+			`,
+			ExpectedResult: `
+				{
+					"__typename": "MyMutation",
+					"setGreeting": "Hi"
+				}
+			`,
+		},
+	})
+}
+
+func TestExecOperation(t *testing.T) {
+	t.Parallel()
+
+	schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{})
+	doc, errs := schema.ParseDocument(`
+		query Hero {
+			hero { name }
+		}
+
+		query Droid($id: ID!) {
+			droid(id: $id) { name }
+		}
+
+		mutation Review {
+			createReview(episode: JEDI, review: { stars: 5 }) { stars }
+		}
+	`)
+	if len(errs) != 0 {
+		t.Fatal(errs)
+	}
+	want := []graphql.DocumentOperation{
+		{Name: "Hero", Type: "query"},
+		{Name: "Droid", Type: "query"},
+		{Name: "Review", Type: "mutation"},
+	}
+	if !reflect.DeepEqual(doc.Operations, want) {
+		t.Errorf("got operations %+v, want %+v", doc.Operations, want)
+	}
+	if op, ok := doc.Operation("Review"); !ok || op.Type != "mutation" {
+		t.Errorf("got operation %+v, %v", op, ok)
+	}
+	if _, ok := doc.Operation(""); ok {
+		t.Error("expected no default operation in a document with several operations")
+	}
+
+	resp := schema.ExecOperation(context.Background(), doc, "Droid", map[string]interface{}{"id": "2001"})
+	if len(resp.Errors) != 0 || string(resp.Data) != `{"droid":{"name":"R2-D2"}}` {
+		t.Errorf("unexpected response %s %v", resp.Data, resp.Errors)
+	}
+	// The variables of the other operations are not required.
+	resp = schema.ExecOperation(context.Background(), doc, "Hero", nil)
+	if len(resp.Errors) != 0 || string(resp.Data) != `{"hero":{"name":"R2-D2"}}` {
+		t.Errorf("unexpected response %s %v", resp.Data, resp.Errors)
+	}
+	resp = schema.ExecOperation(context.Background(), doc, "", map[string]interface{}{"id": "2001"})
+	if len(resp.Errors) != 1 || resp.Errors[0].Message != "more than one operation in query document and no operation name given" {
+		t.Errorf("unexpected errors %v", resp.Errors)
+	}
+
+	// The values of the variables are still validated.
+	resp = schema.ExecOperation(context.Background(), doc, "Droid", nil)
+	if len(resp.Errors) != 1 || resp.Errors[0].Rule != "VariablesOfCorrectType" {
+		t.Errorf("unexpected errors %v", resp.Errors)
+	}
+
+	// The parsed document is shared by concurrent executions.
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp := schema.ExecOperation(context.Background(), doc, "Droid", map[string]interface{}{"id": "2001"})
+			if len(resp.Errors) != 0 || string(resp.Data) != `{"droid":{"name":"R2-D2"}}` {
+				t.Errorf("unexpected response %s %v", resp.Data, resp.Errors)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if _, errs := schema.ParseDocument(`query { unknown }`); len(errs) != 1 {
+		t.Errorf("expected a validation error, got %v", errs)
+	}
+
+	paginated := graphql.MustParseSchema(`
+		type Query {
+			users(first: Int, last: Int): [String!]!
+		}
+	`, &paginatedResolver{}, graphql.RequirePagination(3))
+	doc, errs = paginated.ParseDocument(`query($n: Int) { ...Users } fragment Users on Query { users(first: $n) }`)
+	if len(errs) != 0 {
+		t.Fatal(errs)
+	}
+	resp = paginated.ExecOperation(context.Background(), doc, "", map[string]interface{}{"n": 10})
+	if len(resp.Errors) != 1 || resp.Errors[0].Message != `Argument "first" of field "users" must not exceed 3, got 10.` {
+		t.Errorf("unexpected errors %v", resp.Errors)
+	}
+	resp = paginated.ExecOperation(context.Background(), doc, "", map[string]interface{}{"n": 2})
+	if len(resp.Errors) != 0 || string(resp.Data) != `{"users":["user1","user2"]}` {
+		t.Errorf("unexpected response %s %v", resp.Data, resp.Errors)
+	}
+}
+
+type orderResolver struct{}
+
+type orderItemInput struct {
+	Name  string
+	Price float64
+}
+
+func (r *orderResolver) PlaceOrder(args struct {
+	Input struct {
+		Items []orderItemInput
+	}
+}) int32 {
+	return int32(len(args.Input.Items))
+}
+
+func TestVariableErrorPointers(t *testing.T) {
+	t.Parallel()
+
+	schema := graphql.MustParseSchema(`
+		type Query {
+			placeOrder(input: OrderInput!): Int!
+		}
+
+		input OrderInput {
+			items: [OrderItemInput!]!
+		}
+
+		input OrderItemInput {
+			name: String!
+			price: Float!
+		}
+	`, &orderResolver{})
+
+	item := map[string]interface{}{"name": "book", "price": 12.5}
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: schema,
+			Query: `
+				query($input: OrderInput!) {
+					placeOrder(input: $input)
+				}
+			`,
+			Variables: map[string]interface{}{"input": map[string]interface{}{"items": []interface{}{
+				item, item, item, map[string]interface{}{"name": "pen", "price": "free"},
+			}}},
+			ExpectedErrors: []*gqlerrors.QueryError{{
+				Message:    "Variable \"input\" at \"/input/items/3/price\" has invalid type string.\nExpected type \"Float\", found free.",
+				Locations:  []gqlerrors.Location{{Line: 2, Column: 11}},
+				Rule:       "VariablesOfCorrectType",
+				Extensions: map[string]interface{}{"pointer": "/input/items/3/price", "expectedType": "Float"},
+			}},
+		},
+		{
+			Schema: schema,
+			Query: `
+				query($input: OrderInput!) {
+					placeOrder(input: $input)
+				}
+			`,
+			Variables: map[string]interface{}{"input": map[string]interface{}{"items": []interface{}{
+				map[string]interface{}{"price": 1},
+			}}},
+			ExpectedErrors: []*gqlerrors.QueryError{{
+				Message:    "Variable \"input\" at \"/input/items/0/name\" has invalid value null.\nExpected type \"String!\", found null.",
+				Locations:  []gqlerrors.Location{{Line: 2, Column: 11}},
+				Rule:       "VariablesOfCorrectType",
+				Extensions: map[string]interface{}{"pointer": "/input/items/0/name", "expectedType": "String!"},
+			}},
+		},
+	})
+}
+
+type signupResolver struct{}
+
+type signupInput struct {
+	Login  string
+	Age    int32
+	Emails []string
+}
+
+func (r *signupResolver) Signup(args struct {
+	Input  signupInput
+	Scores *[]float64
+}) string {
+	return args.Input.Login
+}
+
+func TestInputValidationDirectives(t *testing.T) {
+	t.Parallel()
+
+	schema := graphql.MustParseSchema(`
+		type Query {
+			signup(input: SignupInput!, scores: [Float!] @range(min: 0, max: 1)): String!
+		}
+
+		input SignupInput {
+			login: String! @length(min: 3, max: 8) @pattern(regex: "^[a-z]+$")
+			age: Int! @range(min: 18)
+			emails: [String!]! @length(max: 2) @pattern(regex: "@")
+		}
+	`, &signupResolver{})
+
+	query := `
+		query($input: SignupInput!, $scores: [Float!]) {
+			signup(input: $input, scores: $scores)
+		}
+	`
+	input := func(login string, age int, emails ...interface{}) map[string]interface{} {
+		return map[string]interface{}{"login": login, "age": age, "emails": emails}
+	}
+	violation := func(message, pointer, directive string) []*gqlerrors.QueryError {
+		column := 18
+		if strings.HasPrefix(pointer, "/scores") {
+			column = 34
+		}
+		return []*gqlerrors.QueryError{{
+			Message:    message,
+			Locations:  []gqlerrors.Location{{Line: 3, Column: column}},
+			Extensions: map[string]interface{}{"code": "INVALID_ARGUMENT", "pointer": pointer, "directive": directive},
+		}}
+	}
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema:         schema,
+			Query:          query,
+			Variables:      map[string]interface{}{"input": input("alice", 30, "alice@example.com"), "scores": []interface{}{0.5}},
+			ExpectedResult: `{"signup": "alice"}`,
+		},
+		{
+			Schema:         schema,
+			Query:          query,
+			Variables:      map[string]interface{}{"input": input("al", 30)},
+			ExpectedResult: `{}`,
+			ExpectedErrors: violation(`Argument "input" at "/input/login" has invalid value: must have at least 3 characters, got 2`, "/input/login", "length"),
+		},
+		{
+			Schema:         schema,
+			Query:          query,
+			Variables:      map[string]interface{}{"input": input("Alice", 30)},
+			ExpectedResult: `{}`,
+			ExpectedErrors: violation(`Argument "input" at "/input/login" has invalid value: must match the pattern "^[a-z]+$"`, "/input/login", "pattern"),
+		},
+		{
+			Schema:         schema,
+			Query:          query,
+			Variables:      map[string]interface{}{"input": input("alice", 17)},
+			ExpectedResult: `{}`,
+			ExpectedErrors: violation(`Argument "input" at "/input/age" has invalid value: must be at least 18, got 17`, "/input/age", "range"),
+		},
+		{
+			Schema:         schema,
+			Query:          query,
+			Variables:      map[string]interface{}{"input": input("alice", 30, "a@example.com", "b@example.com", "c@example.com")},
+			ExpectedResult: `{}`,
+			ExpectedErrors: violation(`Argument "input" at "/input/emails" has invalid value: must have at most 2 entries, got 3`, "/input/emails", "length"),
+		},
+		{
+			Schema:         schema,
+			Query:          query,
+			Variables:      map[string]interface{}{"input": input("alice", 30, "a@example.com", "b")},
+			ExpectedResult: `{}`,
+			ExpectedErrors: violation(`Argument "input" at "/input/emails/1" has invalid value: must match the pattern "@"`, "/input/emails/1", "pattern"),
+		},
+		{
+			Schema:         schema,
+			Query:          query,
+			Variables:      map[string]interface{}{"input": input("alice", 30), "scores": []interface{}{0.5, 2}},
+			ExpectedResult: `{}`,
+			ExpectedErrors: violation(`Argument "scores" at "/scores/1" has invalid value: must be between 0 and 1, got 2`, "/scores/1", "range"),
+		},
+	})
+
+	for _, tt := range []struct {
+		sdl, err string
+	}{
+		{`type Query { f(s: String @length): String }`, `directive @length on "s": min or max is required`},
+		{`type Query { f(s: String @length(min: 3, max: 2)): String }`, `directive @length on "s": min must not exceed max`},
+		{`type Query { f(n: Int @length(max: 2)): String }`, `directive @length on "n": type must be a string or a list`},
+		{`type Query { f(s: String @range(max: 2)): String }`, `directive @range on "s": type must be Int or Float, or a list of them`},
+		{`type Query { f(s: String @pattern(regex: "(")): String }`, "directive @pattern on \"s\": error parsing regexp: missing closing ): `(`"},
+	} {
+		_, err := graphql.ParseSchema(tt.sdl, &inputValidationResolver{})
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("got error %v, want %q", err, tt.err)
+		}
+	}
+}
+
+type inputValidationResolver struct{}
+
+func (r *inputValidationResolver) F(args struct {
+	S *string
+	N *int32
+}) *string {
+	return nil
+}
+
+type createUserInput struct {
+	Password        string
+	ConfirmPassword string
+}
+
+type createUserResolver struct {
+	calls int32
+}
+
+func (r *createUserResolver) CreateUser(args struct {
+	Inputs []*createUserInput
+}) int32 {
+	atomic.AddInt32(&r.calls, 1)
+	return int32(len(args.Inputs))
+}
+
+func TestWithInputValidator(t *testing.T) {
+	t.Parallel()
+
+	const sdl = `
+		type Query {
+			createUser(inputs: [CreateUserInput!]!): Int!
+		}
+
+		input CreateUserInput {
+			password: String!
+			confirmPassword: String!
+		}
+	`
+	type tenantKey struct{}
+	resolver := &createUserResolver{}
+	schema := graphql.MustParseSchema(sdl, resolver, graphql.WithInputValidator("CreateUserInput", func(ctx context.Context, in *createUserInput) error {
+		if ctx.Value(tenantKey{}) == nil {
+			return errors.New("missing tenant")
+		}
+		if in.Password != in.ConfirmPassword {
+			return errors.New("passwords do not match")
+		}
+		return nil
+	}))
+
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Context: ctx,
+			Schema:  schema,
+			Query: `
+				{
+					createUser(inputs: [{ password: "a", confirmPassword: "a" }])
+				}
+			`,
+			ExpectedResult: `{"createUser": 1}`,
+		},
+		{
+			Context: ctx,
+			Schema:  schema,
+			Query: `
+				{
+					createUser(inputs: [{ password: "a", confirmPassword: "a" }, { password: "a", confirmPassword: "b" }])
+				}
+			`,
+			ExpectedResult: `{}`,
+			ExpectedErrors: []*gqlerrors.QueryError{{
+				Message:       `Argument "inputs" at "/inputs/1" has invalid value: passwords do not match`,
+				Locations:     []gqlerrors.Location{{Line: 3, Column: 25}},
+				Extensions:    map[string]interface{}{"code": "INVALID_ARGUMENT", "pointer": "/inputs/1"},
+				ResolverError: errors.New("passwords do not match"),
+			}},
+		},
+	})
+	if calls := atomic.LoadInt32(&resolver.calls); calls != 1 {
+		t.Errorf("resolver called %d times, want 1", calls)
+	}
+
+	for _, tt := range []struct {
+		typeName  string
+		validator interface{}
+		err       string
+	}{
+		{"Query", func(context.Context, *createUserInput) error { return nil }, `input validator registered for "Query", which is not an input object`},
+		{"CreateUserInput", func(*createUserInput) error { return nil }, `input validator of "CreateUserInput" must be a func(context.Context, *graphql_test.createUserInput) error, got func(*graphql_test.createUserInput) error`},
+	} {
+		_, err := graphql.ParseSchema(sdl, &createUserResolver{}, graphql.WithInputValidator(tt.typeName, tt.validator))
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("got error %v, want %q", err, tt.err)
+		}
+	}
 }
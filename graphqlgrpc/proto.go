@@ -0,0 +1,117 @@
+package graphqlgrpc
+
+import (
+	"fmt"
+)
+
+// encoder writes the protobuf wire format. Only the types used by graphql.proto are supported.
+type encoder struct {
+	b []byte
+}
+
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+func (e *encoder) tag(field int, wireType int) {
+	e.varint(uint64(field)<<3 | uint64(wireType))
+}
+
+func (e *encoder) varint(v uint64) {
+	for v >= 0x80 {
+		e.b = append(e.b, byte(v)|0x80)
+		v >>= 7
+	}
+	e.b = append(e.b, byte(v))
+}
+
+func (e *encoder) int32Field(field int, v int32) {
+	if v == 0 {
+		return
+	}
+	e.tag(field, wireVarint)
+	e.varint(uint64(v)) // negative values are sign extended
+}
+
+func (e *encoder) bytesField(field int, b []byte) {
+	if len(b) == 0 {
+		return
+	}
+	e.tag(field, wireBytes)
+	e.varint(uint64(len(b)))
+	e.b = append(e.b, b...)
+}
+
+func (e *encoder) stringField(field int, s string) {
+	e.bytesField(field, []byte(s))
+}
+
+// messageField writes the message encoded by f. Empty messages are written as well, since they
+// are entries of repeated fields.
+func (e *encoder) messageField(field int, f func(e *encoder)) {
+	var m encoder
+	f(&m)
+	e.tag(field, wireBytes)
+	e.varint(uint64(len(m.b)))
+	e.b = append(e.b, m.b...)
+}
+
+// decodeFields calls f with every field of a message. The value is the number of a varint field
+// and the data the content of a length-delimited field. Fields of other wire types are skipped.
+func decodeFields(b []byte, f func(field int, wireType int, value uint64, data []byte) error) error {
+	for len(b) != 0 {
+		tag, n := uvarint(b)
+		if n == 0 {
+			return fmt.Errorf("invalid tag")
+		}
+		b = b[n:]
+		field, wireType := int(tag>>3), int(tag&7)
+		var value uint64
+		var data []byte
+		switch wireType {
+		case wireVarint:
+			value, n = uvarint(b)
+			if n == 0 {
+				return fmt.Errorf("invalid varint of field %d", field)
+			}
+		case wireBytes:
+			var size uint64
+			size, n = uvarint(b)
+			if n == 0 || size > uint64(len(b)-n) {
+				return fmt.Errorf("invalid length of field %d", field)
+			}
+			data = b[n : n+int(size)]
+			n += int(size)
+		case wireFixed64, wireFixed32:
+			n = 8
+			if wireType == wireFixed32 {
+				n = 4
+			}
+			if len(b) < n {
+				return fmt.Errorf("truncated field %d", field)
+			}
+		default:
+			return fmt.Errorf("unsupported wire type %d of field %d", wireType, field)
+		}
+		b = b[n:]
+		if err := f(field, wireType, value, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// uvarint decodes a varint and returns the number of bytes read, or 0 if it is invalid.
+func uvarint(b []byte) (uint64, int) {
+	var v uint64
+	for i := 0; i < len(b) && i < 10; i++ {
+		v |= uint64(b[i]&0x7f) << (7 * uint(i))
+		if b[i] < 0x80 {
+			return v, i + 1
+		}
+	}
+	return 0, 0
+}
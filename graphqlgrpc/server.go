@@ -0,0 +1,336 @@
+// Package graphqlgrpc executes operations of a schema over gRPC, for service meshes where
+// HTTP/JSON is undesirable between services. The service is defined in graphql.proto:
+//
+//	service GraphQL {
+//	  rpc Execute(ExecuteRequest) returns (ExecuteResponse);
+//	}
+//
+// Server implements it without depending on a gRPC library. It is an http.Handler serving the
+// gRPC protocol over HTTP/2, e.g. with TLS:
+//
+//	mux := http.NewServeMux()
+//	mux.Handle(graphqlgrpc.ExecutePath, &graphqlgrpc.Server{Schema: schema})
+//	log.Fatal(http.ListenAndServeTLS(":8443", "cert.pem", "key.pem", mux))
+//
+// Servers registered with a gRPC library instead call Server.Execute from the method generated
+// for graphql.proto, copying the fields of the generated messages.
+package graphqlgrpc
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	qerrors "github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/relay"
+	"github.com/graph-gophers/graphql-go/requestcontext"
+)
+
+// ExecutePath is the path of the Execute method of the GraphQL service.
+const ExecutePath = "/graphql.GraphQL/Execute"
+
+// The gRPC status codes returned by Server.
+const (
+	CodeOK                = 0
+	CodeInvalidArgument   = 3
+	CodeResourceExhausted = 8
+	CodeUnimplemented     = 12
+	CodeInternal          = 13
+	CodeUnavailable       = 14
+)
+
+// Status is the error of a call that failed without executing the operation.
+type Status struct {
+	Code    int
+	Message string
+}
+
+func (s *Status) Error() string {
+	return fmt.Sprintf("graphqlgrpc: status %d: %s", s.Code, s.Message)
+}
+
+// ExecuteRequest is the ExecuteRequest message of graphql.proto.
+type ExecuteRequest struct {
+	Query         string
+	OperationName string
+
+	// Variables is the JSON object of the variables, if any.
+	Variables []byte
+}
+
+// ExecuteResponse is the ExecuteResponse message of graphql.proto.
+type ExecuteResponse struct {
+	// Data is the JSON encoded data, or nil if the operation was not executed.
+	Data   []byte
+	Errors []*Error
+}
+
+// Error is the Error message of graphql.proto. The path and extensions are JSON encoded.
+type Error struct {
+	Message    string
+	Locations  []qerrors.Location
+	Path       []byte
+	Extensions []byte
+}
+
+// Server serves the GraphQL service of graphql.proto with a schema.
+type Server struct {
+	Schema *graphql.Schema
+
+	// MaxMessageBytes limits the size of request messages. Zero defaults to 4 MiB, the default of
+	// gRPC servers.
+	MaxMessageBytes int64
+}
+
+// Execute executes the operation of the request. The error is a *Status if the variables are
+// invalid JSON or the schema is shutting down.
+func (s *Server) Execute(ctx context.Context, req *ExecuteRequest) (*ExecuteResponse, error) {
+	var variables map[string]interface{}
+	if len(req.Variables) != 0 {
+		if err := json.Unmarshal(req.Variables, &variables); err != nil {
+			return nil, &Status{Code: CodeInvalidArgument, Message: "invalid variables: " + err.Error()}
+		}
+	}
+	if _, ok := requestcontext.StartTime(ctx); !ok {
+		ctx = requestcontext.WithStartTime(ctx, time.Now())
+	}
+
+	response := s.Schema.Exec(ctx, req.Query, req.OperationName, variables)
+	if len(response.Errors) == 1 && response.Errors[0].Code() == graphql.ErrCodeShuttingDown {
+		// Make the client retry with another server.
+		return nil, &Status{Code: CodeUnavailable, Message: response.Errors[0].Message}
+	}
+
+	resp := &ExecuteResponse{Data: response.Data}
+	for _, qErr := range response.Errors {
+		e := &Error{Message: qErr.Message, Locations: qErr.Locations}
+		var err error
+		if qErr.Path != nil {
+			if e.Path, err = json.Marshal(qErr.Path); err != nil {
+				return nil, err
+			}
+		}
+		if qErr.Extensions != nil {
+			if e.Extensions, err = json.Marshal(qErr.Extensions); err != nil {
+				return nil, err
+			}
+		}
+		resp.Errors = append(resp.Errors, e)
+	}
+	return resp, nil
+}
+
+// ServeHTTP serves the Execute method with the gRPC protocol. The client is identified by the
+// "apollographql-client-name" and "apollographql-client-version" metadata, and the deadline of
+// the call is taken from the "grpc-timeout" header. Compressed messages are not supported.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/grpc" && r.Header.Get("Content-Type") != "application/grpc+proto" {
+		http.Error(w, "expected a gRPC request", http.StatusUnsupportedMediaType)
+		return
+	}
+	w.Header().Set("Content-Type", "application/grpc")
+	w.Header().Set("Trailer", "Grpc-Status, Grpc-Message")
+
+	ctx := requestcontext.WithStartTime(r.Context(), time.Now())
+	if timeout, ok := parseTimeout(r.Header.Get("Grpc-Timeout")); ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if client := relay.ApolloClientIdentity(r); client.Name != "" {
+		ctx = graphql.WithClientInfo(ctx, client)
+	}
+
+	msg, err := s.readMessage(r.Body)
+	if err != nil {
+		writeStatus(w, err)
+		return
+	}
+	req := &ExecuteRequest{}
+	if err := req.Unmarshal(msg); err != nil {
+		writeStatus(w, &Status{Code: CodeInvalidArgument, Message: err.Error()})
+		return
+	}
+	resp, err := s.Execute(ctx, req)
+	if err != nil {
+		writeStatus(w, err)
+		return
+	}
+
+	out := resp.Marshal()
+	var prefix [5]byte
+	binary.BigEndian.PutUint32(prefix[1:], uint32(len(out)))
+	w.Write(prefix[:])
+	w.Write(out)
+	writeStatus(w, nil)
+}
+
+// readMessage reads the length-prefixed message of a call.
+func (s *Server) readMessage(body io.Reader) ([]byte, error) {
+	var prefix [5]byte
+	if _, err := io.ReadFull(body, prefix[:]); err != nil {
+		return nil, &Status{Code: CodeInvalidArgument, Message: "missing request message"}
+	}
+	if prefix[0] != 0 {
+		return nil, &Status{Code: CodeUnimplemented, Message: "compressed messages are not supported"}
+	}
+	max := s.MaxMessageBytes
+	if max == 0 {
+		max = 4 << 20
+	}
+	size := int64(binary.BigEndian.Uint32(prefix[1:]))
+	if size > max {
+		return nil, &Status{Code: CodeResourceExhausted, Message: fmt.Sprintf("request message exceeds the maximum size of %d bytes", max)}
+	}
+	msg, err := ioutil.ReadAll(io.LimitReader(body, size))
+	if err != nil || int64(len(msg)) != size {
+		return nil, &Status{Code: CodeInvalidArgument, Message: "truncated request message"}
+	}
+	return msg, nil
+}
+
+// writeStatus writes the status of a call as trailers.
+func writeStatus(w http.ResponseWriter, err error) {
+	code, message := CodeOK, ""
+	if err != nil {
+		code, message = CodeInternal, err.Error()
+		if s, ok := err.(*Status); ok {
+			code, message = s.Code, s.Message
+		}
+	}
+	w.Header().Set("Grpc-Status", strconv.Itoa(code))
+	if message != "" {
+		w.Header().Set("Grpc-Message", percentEncode(message))
+	}
+}
+
+// percentEncode encodes the bytes of a status message that are not printable ASCII, as required
+// for the "grpc-message" header.
+func percentEncode(s string) string {
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c < ' ' || c > '~' || c == '%' {
+			b = append(b, fmt.Sprintf("%%%02X", c)...)
+		} else {
+			b = append(b, c)
+		}
+	}
+	return string(b)
+}
+
+// parseTimeout parses the "grpc-timeout" header, e.g. "100m" for 100 milliseconds.
+func parseTimeout(v string) (time.Duration, bool) {
+	if len(v) < 2 {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(v[:len(v)-1], 10, 64)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	unit, ok := map[byte]time.Duration{
+		'H': time.Hour,
+		'M': time.Minute,
+		'S': time.Second,
+		'm': time.Millisecond,
+		'u': time.Microsecond,
+		'n': time.Nanosecond,
+	}[v[len(v)-1]]
+	return time.Duration(n) * unit, ok
+}
+
+// Marshal encodes the request in the protobuf wire format.
+func (req *ExecuteRequest) Marshal() []byte {
+	var e encoder
+	e.stringField(1, req.Query)
+	e.stringField(2, req.OperationName)
+	e.bytesField(3, req.Variables)
+	return e.b
+}
+
+// Unmarshal decodes the request from the protobuf wire format.
+func (req *ExecuteRequest) Unmarshal(b []byte) error {
+	return decodeFields(b, func(field int, wireType int, value uint64, data []byte) error {
+		switch {
+		case field == 1 && wireType == wireBytes:
+			req.Query = string(data)
+		case field == 2 && wireType == wireBytes:
+			req.OperationName = string(data)
+		case field == 3 && wireType == wireBytes:
+			req.Variables = append([]byte(nil), data...)
+		}
+		return nil
+	})
+}
+
+// Marshal encodes the response in the protobuf wire format.
+func (resp *ExecuteResponse) Marshal() []byte {
+	var e encoder
+	e.bytesField(1, resp.Data)
+	for _, err := range resp.Errors {
+		e.messageField(2, func(e *encoder) {
+			e.stringField(1, err.Message)
+			for _, loc := range err.Locations {
+				e.messageField(2, func(e *encoder) {
+					e.int32Field(1, int32(loc.Line))
+					e.int32Field(2, int32(loc.Column))
+				})
+			}
+			e.bytesField(3, err.Path)
+			e.bytesField(4, err.Extensions)
+		})
+	}
+	return e.b
+}
+
+// Unmarshal decodes the response from the protobuf wire format.
+func (resp *ExecuteResponse) Unmarshal(b []byte) error {
+	return decodeFields(b, func(field int, wireType int, value uint64, data []byte) error {
+		switch {
+		case field == 1 && wireType == wireBytes:
+			resp.Data = append([]byte(nil), data...)
+		case field == 2 && wireType == wireBytes:
+			e := &Error{}
+			if err := e.unmarshal(data); err != nil {
+				return err
+			}
+			resp.Errors = append(resp.Errors, e)
+		}
+		return nil
+	})
+}
+
+func (e *Error) unmarshal(b []byte) error {
+	return decodeFields(b, func(field int, wireType int, value uint64, data []byte) error {
+		switch {
+		case field == 1 && wireType == wireBytes:
+			e.Message = string(data)
+		case field == 2 && wireType == wireBytes:
+			var loc qerrors.Location
+			err := decodeFields(data, func(field int, wireType int, value uint64, data []byte) error {
+				switch {
+				case field == 1 && wireType == wireVarint:
+					loc.Line = int(int32(value))
+				case field == 2 && wireType == wireVarint:
+					loc.Column = int(int32(value))
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			e.Locations = append(e.Locations, loc)
+		case field == 3 && wireType == wireBytes:
+			e.Path = append([]byte(nil), data...)
+		case field == 4 && wireType == wireBytes:
+			e.Extensions = append([]byte(nil), data...)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,126 @@
+package graphqlgrpc_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	qerrors "github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/example/starwars"
+	"github.com/graph-gophers/graphql-go/graphqlgrpc"
+)
+
+var starwarsSchema = graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{})
+
+func TestExecute(t *testing.T) {
+	s := &graphqlgrpc.Server{Schema: starwarsSchema}
+
+	resp, err := s.Execute(context.Background(), &graphqlgrpc.ExecuteRequest{
+		Query:         `query Hero($episode: Episode) { hero(episode: $episode) { name } } query Other { hero { id } }`,
+		OperationName: "Hero",
+		Variables:     []byte(`{"episode": "EMPIRE"}`),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(resp.Data) != `{"hero":{"name":"Luke Skywalker"}}` || len(resp.Errors) != 0 {
+		t.Fatalf("unexpected response %s %v", resp.Data, resp.Errors)
+	}
+
+	resp, err = s.Execute(context.Background(), &graphqlgrpc.ExecuteRequest{Query: `{ hero { nickname } }`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*graphqlgrpc.Error{{
+		Message:   `Cannot query field "nickname" on type "Character".`,
+		Locations: []qerrors.Location{{Line: 1, Column: 10}},
+	}}
+	if resp.Data != nil || !reflect.DeepEqual(resp.Errors, want) {
+		t.Fatalf("unexpected response %s %+v", resp.Data, resp.Errors[0])
+	}
+
+	_, err = s.Execute(context.Background(), &graphqlgrpc.ExecuteRequest{Query: `{ hero { name } }`, Variables: []byte(`[`)})
+	if status, ok := err.(*graphqlgrpc.Status); !ok || status.Code != graphqlgrpc.CodeInvalidArgument {
+		t.Fatalf("expected an invalid argument status, got %v", err)
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	s := &graphqlgrpc.Server{Schema: starwarsSchema, MaxMessageBytes: 64}
+
+	call := func(compressed byte, msg []byte) *httptest.ResponseRecorder {
+		body := append([]byte{compressed, 0, 0, 0, 0}, msg...)
+		binary.BigEndian.PutUint32(body[1:], uint32(len(msg)))
+		r := httptest.NewRequest("POST", graphqlgrpc.ExecutePath, bytes.NewReader(body))
+		r.Header.Set("Content-Type", "application/grpc")
+		r.Header.Set("Grpc-Timeout", "5S")
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, r)
+		return w
+	}
+
+	w := call(0, (&graphqlgrpc.ExecuteRequest{Query: `{ hero { name } }`}).Marshal())
+	trailer := w.Result().Trailer
+	if w.Code != 200 || trailer.Get("Grpc-Status") != "0" || w.Header().Get("Content-Type") != "application/grpc" {
+		t.Fatalf("unexpected status %d, trailer %v", w.Code, trailer)
+	}
+	body := w.Body.Bytes()
+	if len(body) < 5 || body[0] != 0 || int(binary.BigEndian.Uint32(body[1:5])) != len(body)-5 {
+		t.Fatalf("invalid response message %x", body)
+	}
+	resp := &graphqlgrpc.ExecuteResponse{}
+	if err := resp.Unmarshal(body[5:]); err != nil {
+		t.Fatal(err)
+	}
+	if string(resp.Data) != `{"hero":{"name":"R2-D2"}}` || len(resp.Errors) != 0 {
+		t.Fatalf("unexpected response %s %v", resp.Data, resp.Errors)
+	}
+
+	for _, test := range []struct {
+		name       string
+		compressed byte
+		msg        []byte
+		status     string
+		message    string
+	}{
+		{"compressed", 1, nil, "12", "compressed messages are not supported"},
+		{"too large", 0, make([]byte, 65), "8", "request message exceeds the maximum size of 64 bytes"},
+		{"invalid message", 0, []byte{0x0a, 0x10}, "3", "invalid length of field 1"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			trailer := call(test.compressed, test.msg).Result().Trailer
+			if trailer.Get("Grpc-Status") != test.status || trailer.Get("Grpc-Message") != test.message {
+				t.Fatalf("unexpected trailer %v", trailer)
+			}
+		})
+	}
+}
+
+func TestMessages(t *testing.T) {
+	resp := &graphqlgrpc.ExecuteResponse{
+		Data: []byte(`{"hero":null}`),
+		Errors: []*graphqlgrpc.Error{{
+			Message:    "error",
+			Locations:  []qerrors.Location{{Line: 1, Column: 3}, {Line: 200, Column: 1}},
+			Path:       []byte(`["hero"]`),
+			Extensions: []byte(`{"code":"NOT_FOUND"}`),
+		}},
+	}
+	decoded := &graphqlgrpc.ExecuteResponse{}
+	if err := decoded.Unmarshal(resp.Marshal()); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, resp) {
+		t.Fatalf("expected %+v, got %+v", resp, decoded)
+	}
+
+	req := &graphqlgrpc.ExecuteRequest{Query: "{ hero { name } }", OperationName: "Hero", Variables: []byte(`{"a":1}`)}
+	want := "\x0a\x11{ hero { name } }\x12\x04Hero\x1a\x07{\"a\":1}"
+	if got := req.Marshal(); string(got) != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
@@ -5,7 +5,9 @@ import (
 	"strconv"
 )
 
-// ID represents GraphQL's "ID" scalar type. A custom type may be used instead.
+// ID represents GraphQL's "ID" scalar type. A custom type may be used instead. ID fields and
+// arguments may also be bound to string and integer types, and results of ID fields to types
+// implementing IDMarshaler. IDs are always written to the response as strings.
 type ID string
 
 func (ID) ImplementsGraphQLType(name string) bool {
@@ -28,3 +30,9 @@ func (id *ID) UnmarshalGraphQL(input interface{}) error {
 func (id ID) MarshalJSON() ([]byte, error) {
 	return strconv.AppendQuote(nil, string(id)), nil
 }
+
+// IDMarshaler is implemented by types resolving ID fields that are neither strings nor integers,
+// e.g. composite keys of domain models.
+type IDMarshaler interface {
+	MarshalGraphQLID() string
+}
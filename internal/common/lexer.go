@@ -12,11 +12,17 @@ import (
 
 type syntaxError string
 
+// limitError aborts lexing when a limit of the lexer is exceeded.
+type limitError string
+
 type Lexer struct {
-	sc                    *scanner.Scanner
-	next                  rune
-	comment               bytes.Buffer
-	useStringDescriptions bool
+	sc                     *scanner.Scanner
+	next                   rune
+	comment                bytes.Buffer
+	useStringDescriptions  bool
+	useCommentDescriptions bool
+	tokens                 int
+	maxTokens              int
 }
 
 type Ident struct {
@@ -30,13 +36,24 @@ func NewLexer(s string, useStringDescriptions bool) *Lexer {
 	}
 	sc.Init(strings.NewReader(s))
 
-
 	l := Lexer{sc: sc, useStringDescriptions: useStringDescriptions}
 	l.sc.Error = l.CatchScannerError
 
 	return &l
 }
 
+// UseCommentDescriptions uses the comments preceding a definition as its description if it has no
+// string description. It only has an effect if string descriptions are used.
+func (l *Lexer) UseCommentDescriptions() {
+	l.useCommentDescriptions = true
+}
+
+// SetMaxTokens limits the number of tokens that may be consumed. Exceeding the limit aborts
+// lexing, so the rest of the document is not read. Zero disables the limit.
+func (l *Lexer) SetMaxTokens(n int) {
+	l.maxTokens = n
+}
+
 func (l *Lexer) CatchSyntaxError(f func()) (errRes *errors.QueryError) {
 	defer func() {
 		if err := recover(); err != nil {
@@ -45,6 +62,11 @@ func (l *Lexer) CatchSyntaxError(f func()) (errRes *errors.QueryError) {
 				errRes.Locations = []errors.Location{l.Location()}
 				return
 			}
+			if err, ok := err.(limitError); ok {
+				errRes = errors.Errorf("%s", err)
+				errRes.Locations = []errors.Location{l.Location()}
+				return
+			}
 			panic(err)
 		}
 	}()
@@ -88,6 +110,14 @@ func (l *Lexer) ConsumeWhitespace() {
 
 		break
 	}
+
+	if l.next == scanner.EOF {
+		return
+	}
+	l.tokens++
+	if l.maxTokens > 0 && l.tokens > l.maxTokens {
+		panic(limitError(fmt.Sprintf("document exceeds the maximum of %d tokens", l.maxTokens)))
+	}
 }
 
 // consumeDescription optionally consumes a description based on the June 2018 graphql spec if any are present.
@@ -126,6 +156,11 @@ func (l *Lexer) ConsumeIdentWithLoc() Ident {
 	return Ident{name, loc}
 }
 
+// PeekKeyword reports whether the next token is the keyword.
+func (l *Lexer) PeekKeyword(keyword string) bool {
+	return l.next == scanner.Ident && l.sc.TokenText() == keyword
+}
+
 func (l *Lexer) ConsumeKeyword(keyword string) {
 	if l.next != scanner.Ident || l.sc.TokenText() != keyword {
 		l.SyntaxError(fmt.Sprintf("unexpected %q, expecting %q", l.sc.TokenText(), keyword))
@@ -148,8 +183,9 @@ func (l *Lexer) ConsumeToken(expected rune) {
 
 func (l *Lexer) DescComment() string {
 	comment := l.comment.String()
+	hasDesc := l.next == scanner.String
 	desc := l.consumeDescription()
-	if l.useStringDescriptions {
+	if l.useStringDescriptions && (hasDesc || !l.useCommentDescriptions) {
 		return desc
 	}
 	return comment
@@ -0,0 +1,19 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+)
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// JSONPointer returns the JSON Pointer of a value from the tokens of its path, e.g.
+// "/input/items/3/price" for the tokens "input", "items", 3 and "price".
+func JSONPointer(tokens ...interface{}) string {
+	var sb strings.Builder
+	for _, token := range tokens {
+		sb.WriteString("/")
+		sb.WriteString(pointerEscaper.Replace(fmt.Sprint(token)))
+	}
+	return sb.String()
+}
@@ -0,0 +1,144 @@
+package exec
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/graph-gophers/graphql-go/internal/exec/resolvable"
+	"github.com/graph-gophers/graphql-go/internal/exec/selected"
+)
+
+// EntityCache stores the results of the selection sets of entities across requests. An entity is
+// the resolver of an object that implements EntityKey, e.g. returning "User:42".
+type EntityCache interface {
+	// Get returns the result of the selection set of the entity and the keys of the entities the
+	// result depends on, including the entity itself.
+	Get(entity, selection string) (result []byte, deps []string, ok bool)
+
+	// Set stores the result of the selection set of the entity.
+	Set(entity, selection string, result []byte, deps []string)
+}
+
+type entity interface {
+	EntityKey() string
+}
+
+type entityDepsKey struct{}
+
+// entityDeps collects the keys of the entities resolved in the selection set of an entity.
+type entityDeps struct {
+	mu   sync.Mutex
+	keys map[string]bool
+}
+
+func (d *entityDeps) add(keys []string) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, key := range keys {
+		d.keys[key] = true
+	}
+}
+
+func (d *entityDeps) list() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	keys := make([]string, 0, len(d.keys))
+	for key := range d.keys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// execEntity writes the result of the selection set of an entity from the entity cache, or executes
+// it and stores the result if no error occurred. It reports false if the resolver is not an entity.
+func (r *Request) execEntity(ctx context.Context, sels []selected.Selection, path *pathSegment, s *resolvable.Schema, resolver reflect.Value, out *bytes.Buffer) bool {
+	if !resolver.CanInterface() {
+		return false
+	}
+	e, ok := resolver.Interface().(entity)
+	if !ok {
+		return false
+	}
+	key := e.EntityKey()
+	if key == "" {
+		return false
+	}
+	parent, _ := ctx.Value(entityDepsKey{}).(*entityDeps)
+	selection := selectionKey(sels, r.Vars)
+	if result, deps, ok := r.EntityCache.Get(key, selection); ok {
+		parent.add(deps)
+		out.Write(result)
+		r.countBytes(len(result))
+		return true
+	}
+
+	deps := &entityDeps{keys: map[string]bool{key: true}}
+	errs := r.errorCount()
+	result := new(bytes.Buffer)
+	r.execSelections(context.WithValue(ctx, entityDepsKey{}, deps), sels, path, s, resolver, result, false)
+	keys := deps.list()
+	parent.add(keys)
+	if r.errorCount() == errs && !resolvedToNull(result) {
+		r.EntityCache.Set(key, selection, result.Bytes(), keys)
+	}
+	out.Write(result.Bytes())
+	return true
+}
+
+func (r *Request) errorCount() int {
+	r.Mu.Lock()
+	defer r.Mu.Unlock()
+	return len(r.Errs)
+}
+
+// selectionKey returns a hash identifying the fields, arguments and type conditions of a selection
+// set, with fragments and variables applied.
+func selectionKey(sels []selected.Selection, vars map[string]interface{}) string {
+	var b bytes.Buffer
+	writeSelectionKey(&b, sels, vars)
+	hash := sha256.Sum256(b.Bytes())
+	return hex.EncodeToString(hash[:])
+}
+
+func writeSelectionKey(b *bytes.Buffer, sels []selected.Selection, vars map[string]interface{}) {
+	b.WriteByte('{')
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *selected.SchemaField:
+			fmt.Fprintf(b, "%s:%s.%s", sel.Alias, sel.TypeName, sel.Name)
+			if len(sel.Args) != 0 {
+				// Maps are encoded with sorted keys.
+				args, _ := json.Marshal(sel.Args)
+				b.Write(args)
+			}
+			for _, d := range sel.Directives {
+				fmt.Fprintf(b, "@%s", d.Name.Name)
+				for _, arg := range d.Args {
+					value, _ := json.Marshal(arg.Value.Value(vars))
+					fmt.Fprintf(b, "(%s:%s)", arg.Name.Name, value)
+				}
+			}
+			writeSelectionKey(b, sel.Sels, vars)
+		case *selected.TypeAssertion:
+			if obj, ok := sel.TypeExec.(*resolvable.Object); ok {
+				fmt.Fprintf(b, "...%s", obj.Name)
+			}
+			writeSelectionKey(b, sel.Sels, vars)
+		case *selected.TypenameField:
+			fmt.Fprintf(b, "%s:__typename", sel.Alias)
+		}
+		b.WriteByte(' ')
+	}
+	b.WriteByte('}')
+}
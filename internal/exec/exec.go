@@ -5,7 +5,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"math"
 	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -16,22 +21,132 @@ import (
 	"github.com/graph-gophers/graphql-go/internal/query"
 	"github.com/graph-gophers/graphql-go/internal/schema"
 	"github.com/graph-gophers/graphql-go/log"
+	"github.com/graph-gophers/graphql-go/requestcontext"
 	"github.com/graph-gophers/graphql-go/trace"
 )
 
 type Request struct {
 	selected.Request
-	Limiter                  chan struct{}
+	Limiter                  Limiter
+	MaxParallelism           int
 	Tracer                   trace.Tracer
 	Logger                   log.Logger
 	SubscribeResolverTimeout time.Duration
+	Timeout                  time.Duration
+	RedactionPredicates      map[string]func(ctx context.Context) bool
+
+	// MarshalGraphQL writes a field result implementing the MarshalerType of the schema.
+	MarshalGraphQL func(w io.Writer, v interface{}, fields []*SelectedField) error
+
+	// MaxListConcurrency, if positive, limits the number of list entries resolved concurrently for
+	// lists without a limit of their own. The entries are then resolved in chunks.
+	MaxListConcurrency int
+
+	// MaxListLength, if positive, limits the number of entries of lists without a limit of their
+	// own. Longer lists resolve to null with an error.
+	MaxListLength int
+
+	// Workers, if set, bounds the number of goroutines of the request. Fields and list entries
+	// are resolved in the calling goroutine while all workers are busy.
+	Workers chan struct{}
+
+	// FieldOrder is the order of the fields of objects in the response.
+	FieldOrder FieldOrder
+
+	// TraceSkippedFields reports the fields skipped by a @skip or @include directive to the tracer,
+	// if it implements trace.SkippedFieldTracer.
+	TraceSkippedFields bool
+
+	// FieldCost, if set, returns the cost of a field resolved in parallel with its siblings. The
+	// limiter is acquired for cheaper fields first.
+	FieldCost func(typeName, fieldName, alias string, args map[string]interface{}) float64
+
+	// EntityCache, if set, caches the results of the selection sets of entities across requests.
+	EntityCache EntityCache
+
+	// CircuitBreaker, if set, is consulted around the calls of the method resolvers of fields.
+	CircuitBreaker CircuitBreaker
+
+	// ReportPanic, if set, receives every panic of the request with its stack trace.
+	ReportPanic func(ctx context.Context, p *log.Panic)
+
+	// PanicStacks adds the stack traces of panics to the "stacktrace" extension of their errors.
+	PanicStacks bool
+
+	// MaxResponseBytes, if positive, limits the size of the data written by Execute. A response
+	// exceeding it is replaced by an error.
+	MaxResponseBytes int64
+
+	size *responseSize
+}
+
+// CircuitBreaker fails the fields of a schema coordinate like "Query.user" immediately while their
+// resolvers fail persistently.
+type CircuitBreaker interface {
+	// Allow returns a non-nil error to fail the field without calling its resolver.
+	Allow(coordinate string) error
+
+	// Done reports the outcome of a call allowed by Allow, which is nil on success.
+	Done(coordinate string, err error)
 }
 
 func (r *Request) handlePanic(ctx context.Context) {
 	if value := recover(); value != nil {
+		r.AddError(r.panicError(ctx, value, nil))
+	}
+}
+
+// panicError logs and reports a recovered panic and returns its error. It must be called by the
+// deferred function recovering the panic, so that the stack trace includes the panic.
+func (r *Request) panicError(ctx context.Context, value interface{}, path *pathSegment) *errors.QueryError {
+	p := &log.Panic{
+		Value:         value,
+		Stack:         log.CaptureStack(),
+		Path:          path.toSlice(),
+		OperationName: requestcontext.OperationName(ctx),
+	}
+	if l, ok := r.Logger.(log.PanicLogger); ok {
+		l.LogPanicInfo(ctx, p)
+	} else {
 		r.Logger.LogPanic(ctx, value)
-		r.AddError(makePanicError(value))
 	}
+	if r.ReportPanic != nil {
+		r.ReportPanic(ctx, p)
+	}
+
+	err := makePanicError(value)
+	err.Path = p.Path
+	err.Locations = path.locations()
+	if r.PanicStacks {
+		err.Extensions = map[string]interface{}{"stacktrace": strings.Split(strings.TrimSpace(string(p.Stack)), "\n")}
+	}
+	return err
+}
+
+// Limiter limits the number of resolvers of a request running in parallel.
+type Limiter interface {
+	Acquire(ctx context.Context) error
+	Release()
+}
+
+// NewSemaphore returns a Limiter that allows n resolvers to run in parallel.
+func NewSemaphore(n int) Limiter {
+	return make(semaphore, n)
+}
+
+type semaphore chan struct{}
+
+func (s semaphore) Acquire(ctx context.Context) error {
+	select {
+	case s <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s semaphore) Release() {
+	<-s
 }
 
 type extensionser interface {
@@ -42,17 +157,41 @@ func makePanicError(value interface{}) *errors.QueryError {
 	return errors.Errorf("panic occurred: %v", value)
 }
 
+func makeCancelledError(ctx context.Context, path *pathSegment) *errors.QueryError {
+	err := errors.Errorf("skipped due to cancellation: %s", ctx.Err())
+	err.Path = path.toSlice()
+	err.Locations = path.locations()
+	return err
+}
+
 func (r *Request) Execute(ctx context.Context, s *resolvable.Schema, op *query.Operation) ([]byte, []*errors.QueryError) {
 	var out bytes.Buffer
 	func() {
 		defer r.handlePanic(ctx)
-		sels := selected.ApplyOperation(&r.Request, s, op)
-		r.execSelections(ctx, sels, nil, s, s.Resolver, &out, op.Type == query.Mutation)
+		// The request timeout only applies to the execution. Fields that did not complete in time
+		// resolve to null with an error, while the fields that completed are still returned.
+		execCtx := ctx
+		if r.Timeout > 0 {
+			var cancel context.CancelFunc
+			execCtx, cancel = context.WithTimeout(ctx, r.Timeout)
+			defer cancel()
+		}
+		if r.MaxResponseBytes > 0 {
+			var cancel context.CancelFunc
+			execCtx, cancel = context.WithCancel(execCtx)
+			defer cancel()
+			r.size = &responseSize{cancel: cancel}
+		}
+		sels := selected.ApplyOperation(execCtx, &r.Request, s, op)
+		r.execSelections(execCtx, sels, nil, s, s.Resolver, &out, op.Type == query.Mutation)
 	}()
 
 	if err := ctx.Err(); err != nil {
 		return nil, []*errors.QueryError{errors.Errorf("%s", err)}
 	}
+	if r.responseTooLarge() {
+		return nil, []*errors.QueryError{makeResponseTooLargeError(r.MaxResponseBytes)}
+	}
 
 	return out.Bytes(), r.Errs
 }
@@ -62,6 +201,75 @@ type fieldToExec struct {
 	sels     []selected.Selection
 	resolver reflect.Value
 	out      *bytes.Buffer
+
+	// failed is set if the resolver of the field returned an error.
+	failed bool
+
+	// acquired is set if the limiter was acquired for the field before it was dispatched.
+	acquired bool
+}
+
+// scheduleFields returns the fields to resolve in parallel sorted by their cost, or as given if the
+// request has no FieldCost. Fields of the same cost keep their document order.
+func (r *Request) scheduleFields(fields []*fieldToExec) []*fieldToExec {
+	if r.FieldCost == nil {
+		return fields
+	}
+	costs := make(map[*fieldToExec]float64, len(fields))
+	for _, f := range fields {
+		costs[f] = r.FieldCost(f.field.TypeName, f.field.Name, f.field.Alias, f.field.Args)
+	}
+	scheduled := make([]*fieldToExec, len(fields))
+	copy(scheduled, fields)
+	sort.SliceStable(scheduled, func(i, j int) bool {
+		return costs[scheduled[i]] < costs[scheduled[j]]
+	})
+	return scheduled
+}
+
+// acquireLimiter acquires the limiter for the field. If it fails, the field resolves to null with
+// an error.
+func (r *Request) acquireLimiter(ctx context.Context, f *fieldToExec, path *pathSegment) bool {
+	// Fields waiting for the limiter are skipped as soon as the context gets cancelled.
+	if err := r.Limiter.Acquire(ctx); err != nil {
+		qErr := makeCancelledError(ctx, path)
+		if ctx.Err() == nil {
+			qErr = errors.Errorf("%s", err)
+			qErr.Path = path.toSlice()
+			qErr.Locations = path.locations()
+			qErr.ResolverError = err
+		}
+		r.AddError(qErr)
+		f.out.WriteString("null")
+		return false
+	}
+	return true
+}
+
+// spawn runs fn in a new goroutine that is added to wg. If the request shares a pool of workers
+// between all selections and the pool is exhausted, fn runs in the calling goroutine instead, so
+// nested selections never wait for each other.
+func (r *Request) spawn(wg *sync.WaitGroup, fn func()) {
+	if r.Workers != nil {
+		select {
+		case r.Workers <- struct{}{}:
+		default:
+			fn()
+			return
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-r.Workers }()
+			fn()
+		}()
+		return
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		fn()
+	}()
 }
 
 func resolvedToNull(b *bytes.Buffer) bool {
@@ -73,49 +281,155 @@ func (r *Request) execSelections(ctx context.Context, sels []selected.Selection,
 
 	var fields []*fieldToExec
 	collectFieldsToResolve(sels, s, resolver, &fields, make(map[string]*fieldToExec))
+	if r.TraceSkippedFields {
+		if t, ok := r.Tracer.(trace.SkippedFieldTracer); ok {
+			traceSkippedFields(ctx, t, sels, resolver)
+		}
+	}
 
 	if async {
 		var wg sync.WaitGroup
-		wg.Add(len(fields))
-		for _, f := range fields {
-			go func(f *fieldToExec) {
-				defer wg.Done()
+		var serialFields []*fieldToExec
+		for _, f := range r.scheduleFields(fields) {
+			f.out = new(bytes.Buffer)
+			// Fields marked as serial keep their relative document order and are
+			// resolved one after another, alongside the remaining parallel fields.
+			if f.field.Serial {
+				serialFields = append(serialFields, f)
+				continue
+			}
+			// Scheduled fields acquire the limiter in the order of their cost, so cheap fields
+			// are not starved by expensive siblings when the limiter is saturated.
+			if r.FieldCost != nil {
+				if !r.acquireLimiter(ctx, f, fieldPath(path, f.field)) {
+					continue
+				}
+				f.acquired = true
+			}
+			f := f
+			r.spawn(&wg, func() {
 				defer r.handlePanic(ctx)
-				f.out = new(bytes.Buffer)
-				execFieldSelection(ctx, r, s, f, &pathSegment{path, f.field.Alias}, true)
-			}(f)
+				execFieldSelection(ctx, r, s, f, fieldPath(path, f.field), true)
+			})
+		}
+		if len(serialFields) > 0 {
+			r.spawn(&wg, func() {
+				for _, f := range serialFields {
+					func() {
+						defer r.handlePanic(ctx)
+						execFieldSelection(ctx, r, s, f, fieldPath(path, f.field), true)
+					}()
+				}
+			})
 		}
 		wg.Wait()
 	} else {
 		for _, f := range fields {
 			f.out = new(bytes.Buffer)
-			execFieldSelection(ctx, r, s, f, &pathSegment{path, f.field.Alias}, true)
+			execFieldSelection(ctx, r, s, f, fieldPath(path, f.field), true)
 		}
 	}
 
 	out.WriteByte('{')
-	for i, f := range fields {
+	written := 0
+	for _, f := range r.orderFields(fields) {
+		action := resolvable.OnErrorDefault
+		if f.failed {
+			action = f.field.OnError
+		}
+		if action == resolvable.OnErrorDrop {
+			continue
+		}
+
 		// If a non-nullable child resolved to null, an error was added to the
 		// "errors" list in the response, so this field resolves to null.
 		// If this field is non-nullable, the error is propagated to its parent.
-		if _, ok := f.field.Type.(*common.NonNull); ok && resolvedToNull(f.out) {
+		// An @onError directive overrides this for errors of the field's resolver.
+		_, nonNull := f.field.Type.(*common.NonNull)
+		if action == resolvable.OnErrorPropagate || (nonNull && action != resolvable.OnErrorNull && resolvedToNull(f.out)) {
 			out.Reset()
 			out.Write([]byte("null"))
 			return
 		}
 
-		if i > 0 {
+		if written > 0 {
 			out.WriteByte(',')
 		}
+		written++
 		out.WriteByte('"')
 		out.WriteString(f.field.Alias)
 		out.WriteByte('"')
 		out.WriteByte(':')
 		out.Write(f.out.Bytes())
+		r.countBytes(len(f.field.Alias) + 4)
 	}
 	out.WriteByte('}')
 }
 
+// FieldOrder is the order of the fields of objects in the response.
+type FieldOrder int
+
+const (
+	// SelectionOrder writes the fields in the order they are selected in the query.
+	SelectionOrder FieldOrder = iota
+
+	// SchemaOrder writes the fields in the order they are defined in the schema.
+	SchemaOrder
+
+	// SortedOrder writes the fields sorted by their response keys.
+	SortedOrder
+)
+
+// orderFields returns the fields in the order they are written to the response. Fields with the
+// same position in the schema, i.e. aliases of the same field, are sorted by their response keys.
+// Meta fields like __typename come before all fields defined in the schema.
+func (r *Request) orderFields(fields []*fieldToExec) []*fieldToExec {
+	switch r.FieldOrder {
+	case SchemaOrder:
+		positions := make(map[*fieldToExec]int, len(fields))
+		for _, f := range fields {
+			positions[f] = r.schemaPosition(f.field)
+		}
+		ordered := append([]*fieldToExec(nil), fields...)
+		sort.SliceStable(ordered, func(i, j int) bool {
+			pi, pj := positions[ordered[i]], positions[ordered[j]]
+			if pi != pj {
+				return pi < pj
+			}
+			return ordered[i].field.Alias < ordered[j].field.Alias
+		})
+		return ordered
+
+	case SortedOrder:
+		ordered := append([]*fieldToExec(nil), fields...)
+		sort.SliceStable(ordered, func(i, j int) bool {
+			return ordered[i].field.Alias < ordered[j].field.Alias
+		})
+		return ordered
+
+	default:
+		return fields
+	}
+}
+
+// schemaPosition returns the index of the field in the definition of its type, or -1 if the type
+// does not define it.
+func (r *Request) schemaPosition(f *selected.SchemaField) int {
+	var fields schema.FieldList
+	switch t := r.Schema.Types[f.TypeName].(type) {
+	case *schema.Object:
+		fields = t.Fields
+	case *schema.Interface:
+		fields = t.Fields
+	}
+	for i, field := range fields {
+		if field.Name == f.Name {
+			return i
+		}
+	}
+	return -1
+}
+
 func collectFieldsToResolve(sels []selected.Selection, s *resolvable.Schema, resolver reflect.Value, fields *[]*fieldToExec, fieldByAlias map[string]*fieldToExec) {
 	for _, sel := range sels {
 		switch sel := sel.(type) {
@@ -137,11 +451,14 @@ func collectFieldsToResolve(sels []selected.Selection, s *resolvable.Schema, res
 			*fields = append(*fields, &fieldToExec{field: sf, resolver: resolver})
 
 		case *selected.TypeAssertion:
-			out := resolver.Method(sel.MethodIndex).Call(nil)
-			if !out[1].Bool() {
+			member, ok := sel.Convert(resolver)
+			if !ok {
 				continue
 			}
-			collectFieldsToResolve(sel.Sels, s, out[0], fields, fieldByAlias)
+			collectFieldsToResolve(sel.Sels, s, member, fields, fieldByAlias)
+
+		case *selected.SkippedField:
+			// not executed
 
 		default:
 			panic("unreachable")
@@ -149,13 +466,26 @@ func collectFieldsToResolve(sels []selected.Selection, s *resolvable.Schema, res
 	}
 }
 
+// traceSkippedFields reports the skipped fields of the selections applying to the resolver.
+func traceSkippedFields(ctx context.Context, t trace.SkippedFieldTracer, sels []selected.Selection, resolver reflect.Value) {
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *selected.SkippedField:
+			t.TraceSkippedField(ctx, sel.TypeName, sel.Name, sel.Args)
+		case *selected.TypeAssertion:
+			if member, ok := sel.Convert(resolver); ok {
+				traceSkippedFields(ctx, t, sel.Sels, member)
+			}
+		}
+	}
+}
+
 func typeOf(tf *selected.TypenameField, resolver reflect.Value) string {
 	if len(tf.TypeAssertions) == 0 {
 		return tf.Name
 	}
 	for name, a := range tf.TypeAssertions {
-		out := resolver.Method(a.MethodIndex).Call(nil)
-		if out[1].Bool() {
+		if _, ok := a.Convert(resolver); ok {
 			return name
 		}
 	}
@@ -163,10 +493,16 @@ func typeOf(tf *selected.TypenameField, resolver reflect.Value) string {
 }
 
 func execFieldSelection(ctx context.Context, r *Request, s *resolvable.Schema, f *fieldToExec, path *pathSegment, applyLimiter bool) {
-	if applyLimiter {
-		r.Limiter <- struct{}{}
+	if applyLimiter && !f.acquired && !r.acquireLimiter(ctx, f, path) {
+		return
 	}
 
+	coordinate := ""
+	if r.CircuitBreaker != nil && f.field.UseMethodResolver() && !strings.HasPrefix(f.field.TypeName, "__") {
+		coordinate = f.field.TypeName + "." + f.field.Name
+	}
+	allowed := false
+
 	var result reflect.Value
 	var err *errors.QueryError
 
@@ -175,12 +511,16 @@ func execFieldSelection(ctx context.Context, r *Request, s *resolvable.Schema, f
 		finish(err)
 	}()
 
+	if f.field.Deadline > 0 {
+		var cancel context.CancelFunc
+		traceCtx, cancel = context.WithTimeout(traceCtx, f.field.Deadline)
+		defer cancel()
+	}
+
 	err = func() (err *errors.QueryError) {
 		defer func() {
 			if panicValue := recover(); panicValue != nil {
-				r.Logger.LogPanic(ctx, panicValue)
-				err = makePanicError(panicValue)
-				err.Path = path.toSlice()
+				err = r.panicError(ctx, panicValue, path)
 			}
 		}()
 
@@ -189,43 +529,41 @@ func execFieldSelection(ctx context.Context, r *Request, s *resolvable.Schema, f
 			return nil
 		}
 
-		if err := traceCtx.Err(); err != nil {
-			return errors.Errorf("%s", err) // don't execute any more resolvers if context got cancelled
+		if red := f.field.Redaction; red != nil && r.RedactionPredicates[red.When](traceCtx) {
+			result = red.Placeholder
+			return nil
 		}
 
-		res := f.resolver
-		if f.field.UseMethodResolver() {
-			var in []reflect.Value
-			if f.field.HasContext {
-				in = append(in, reflect.ValueOf(traceCtx))
-			}
-			if f.field.ArgsPacker != nil {
-				in = append(in, f.field.PackedArgs)
-			}
-			callOut := res.Method(f.field.MethodIndex).Call(in)
-			result = callOut[0]
-			if f.field.HasError && !callOut[1].IsNil() {
-				resolverErr := callOut[1].Interface().(error)
-				err := errors.Errorf("%s", resolverErr)
-				err.Path = path.toSlice()
-				err.ResolverError = resolverErr
-				if ex, ok := callOut[1].Interface().(extensionser); ok {
-					err.Extensions = ex.Extensions()
-				}
-				return err
-			}
-		} else {
-			// TODO extract out unwrapping ptr logic to a common place
-			if res.Kind() == reflect.Ptr {
-				res = res.Elem()
+		if traceCtx.Err() != nil {
+			return makeCancelledError(traceCtx, path) // don't execute any more resolvers if context got cancelled
+		}
+
+		// An open circuit fails the field without calling its resolver. Only calls of the
+		// resolver are reported to the circuit breaker.
+		if coordinate != "" {
+			if breakerErr := r.CircuitBreaker.Allow(coordinate); breakerErr != nil {
+				return resolverError(breakerErr, path)
 			}
-			result = res.FieldByIndex(f.field.FieldIndex)
+			allowed = true
 		}
-		return nil
+
+		result, err = r.resolveWithRetry(traceCtx, f, path)
+		return err
 	}()
 
+	if allowed {
+		var outcome error
+		if err != nil {
+			outcome = err
+			if err.ResolverError != nil {
+				outcome = err.ResolverError
+			}
+		}
+		r.CircuitBreaker.Done(coordinate, outcome)
+	}
+
 	if applyLimiter {
-		<-r.Limiter
+		r.Limiter.Release()
 	}
 
 	if err != nil {
@@ -233,23 +571,231 @@ func execFieldSelection(ctx context.Context, r *Request, s *resolvable.Schema, f
 		// returned null, and an error must be added to the "errors" list in the response.
 		r.AddError(err)
 		f.out.WriteString("null")
+		f.failed = true
 		return
 	}
 
-	r.execSelectionSet(traceCtx, f.sels, f.field.Type, path, s, result, f.out)
+	r.execFieldResult(traceCtx, f, path, s, result, f.out)
 }
 
-func (r *Request) execSelectionSet(ctx context.Context, sels []selected.Selection, typ common.Type, path *pathSegment, s *resolvable.Schema, resolver reflect.Value, out *bytes.Buffer) {
+// resolveWithRetry resolves a field, through its wrapper if any, and retries resolver errors
+// according to the retry policy of the field.
+func (r *Request) resolveWithRetry(ctx context.Context, f *fieldToExec, path *pathSegment) (reflect.Value, *errors.QueryError) {
+	policy := f.field.Retry
+	for retry := 1; ; retry++ {
+		var result reflect.Value
+		var err *errors.QueryError
+		if f.field.Wrapper != nil {
+			result, err = r.resolveWrapped(ctx, f, path)
+		} else {
+			result, err = r.resolveField(ctx, f, path)
+		}
+		if err == nil || policy == nil || retry >= policy.MaxAttempts || err.ResolverError == nil || ctx.Err() != nil {
+			return result, err
+		}
+		if policy.RetryOn != nil && !policy.RetryOn(err.ResolverError) {
+			return result, err
+		}
+		if policy.Backoff != nil {
+			timer := time.NewTimer(policy.Backoff(retry))
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return result, err
+			}
+		}
+	}
+}
+
+// resolveField calls the resolver of a field. Hoisted fields are resolved through the resolvers of
+// the fields they are hoisted through, and are null if any of them is.
+func (r *Request) resolveField(ctx context.Context, f *fieldToExec, path *pathSegment) (reflect.Value, *errors.QueryError) {
+	res := f.field.Resolver(f.resolver)
+	for _, via := range f.field.Via {
+		v, err := r.callResolver(ctx, f, via, res, reflect.Value{}, nil, path)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if !v.IsValid() || ((v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil()) {
+			return reflect.Value{}, nil
+		}
+		res = v
+	}
+	var required map[string]interface{}
+	if len(f.field.Requires) != 0 {
+		required = make(map[string]interface{}, len(f.field.Requires))
+		for _, dep := range f.field.Requires {
+			v, err := r.callResolver(ctx, f, dep, res, reflect.Value{}, nil, path)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			if v.IsValid() {
+				required[dep.Name] = v.Interface()
+			} else {
+				required[dep.Name] = nil
+			}
+		}
+	}
+	return r.callResolver(ctx, f, &f.field.Field, res, f.field.PackedArgs, required, path)
+}
+
+// callResolver calls the method or reads the struct field resolving field on res. The values of
+// the dependencies of the field are passed to the resolver in its context.
+func (r *Request) callResolver(ctx context.Context, f *fieldToExec, field *resolvable.Field, res reflect.Value, args reflect.Value, required map[string]interface{}, path *pathSegment) (reflect.Value, *errors.QueryError) {
+	if !field.UseMethodResolver() {
+		// TODO extract out unwrapping ptr logic to a common place
+		if res.Kind() == reflect.Ptr {
+			res = res.Elem()
+		}
+		return res.FieldByIndex(field.FieldIndex), nil
+	}
+
+	var in []reflect.Value
+	if field.HasContext {
+		in = append(in, reflect.ValueOf(withFieldInfo(ctx, r, f, required)))
+	}
+	if field.ArgsPacker != nil {
+		in = append(in, args)
+	}
+	callOut := res.Method(field.MethodIndex).Call(in)
+	if field.HasError && !callOut[1].IsNil() {
+		return callOut[0], resolverError(callOut[1].Interface().(error), path)
+	}
+	return callOut[0], nil
+}
+
+// resolveWrapped resolves a field through the wrapper of its resolver, see
+// schema.Schema.WrapResolver.
+func (r *Request) resolveWrapped(ctx context.Context, f *fieldToExec, path *pathSegment) (reflect.Value, *errors.QueryError) {
+	var resolveErr *errors.QueryError
+	v, err := f.field.Wrapper(ctx, func(ctx context.Context) (interface{}, error) {
+		result, err := r.resolveField(ctx, f, path)
+		if err != nil {
+			resolveErr = err
+			return nil, err
+		}
+		if !result.IsValid() {
+			return nil, nil
+		}
+		return result.Interface(), nil
+	})
+	if err != nil {
+		if resolveErr != nil && err == error(resolveErr) {
+			return reflect.Value{}, resolveErr
+		}
+		return reflect.Value{}, resolverError(err, path)
+	}
+	if v == nil {
+		return reflect.Zero(f.field.ResultType), nil
+	}
+	result := reflect.ValueOf(v)
+	if !result.Type().AssignableTo(f.field.ResultType) {
+		err := errors.Errorf("wrapper of field %q of type %q returned %s, expected %s", f.field.Name, f.field.TypeName, result.Type(), f.field.ResultType)
+		err.Path = path.toSlice()
+		err.Locations = path.locations()
+		return reflect.Value{}, err
+	}
+	if f.field.ResultType.Kind() == reflect.Interface {
+		iface := reflect.New(f.field.ResultType).Elem()
+		iface.Set(result)
+		result = iface
+	}
+	return result, nil
+}
+
+// resolverError returns the error of a resolver as a field error, with the extensions of the error
+// if it provides any.
+func resolverError(resolverErr error, path *pathSegment) *errors.QueryError {
+	err := errors.Errorf("%s", resolverErr)
+	err.Path = path.toSlice()
+	err.Locations = path.locations()
+	err.ResolverError = resolverErr
+	if ex, ok := resolverErr.(extensionser); ok {
+		err.Extensions = ex.Extensions()
+	}
+	return err
+}
+
+// execFieldResult writes the result of a resolved field. Pre-serialized JSON is written as is and
+// marshalers write themselves, other results are written by executing the selections of the field
+// on them.
+func (r *Request) execFieldResult(ctx context.Context, f *fieldToExec, path *pathSegment, s *resolvable.Schema, result reflect.Value, out *bytes.Buffer) {
+	switch f.field.ValueExec.(type) {
+	case *resolvable.RawJSON:
+		if result.IsValid() {
+			if raw := bytes.TrimSpace(result.Bytes()); len(raw) != 0 && !bytes.Equal(raw, []byte("null")) {
+				out.Write(raw)
+				r.countBytes(len(raw))
+				return
+			}
+		}
+		// Empty and null values are handled like any other null result.
+		result = reflect.Value{}
+
+	case *resolvable.Marshaler:
+		if result.IsValid() && !((result.Kind() == reflect.Ptr || result.Kind() == reflect.Interface) && result.IsNil()) {
+			n := out.Len()
+			defer func() { r.countBytes(out.Len() - n) }()
+			if err := r.MarshalGraphQL(out, result.Interface(), selectedFields(f.sels, false)); err != nil {
+				qErr := errors.Errorf("%s", err)
+				qErr.Path = path.toSlice()
+				qErr.Locations = path.locations()
+				qErr.ResolverError = err
+				r.AddError(qErr)
+				out.Reset()
+				out.WriteString("null")
+			}
+			return
+		}
+	}
+	if f.field.NilSliceAsNull && isNilSlice(result) {
+		result = reflect.Value{}
+	}
+	typ := f.field.Type
+	if f.field.ExecType != nil {
+		typ = f.field.ExecType
+	}
+	r.execSelectionSet(ctx, f.sels, typ, path, s, result, out, f.field)
+}
+
+// isNilSlice reports whether v is a nil slice or a pointer to one.
+func isNilSlice(v reflect.Value) bool {
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v.Kind() == reflect.Slice && v.IsNil()
+}
+
+// execSelectionSet writes the result of a field or list entry. field is the field whose result or
+// list entry is written, whose directives limit its lists.
+func (r *Request) execSelectionSet(ctx context.Context, sels []selected.Selection, typ common.Type, path *pathSegment, s *resolvable.Schema, resolver reflect.Value, out *bytes.Buffer, field *selected.SchemaField) {
+	semanticNonNull := false
+	if sn, ok := typ.(*resolvable.SemanticNonNull); ok {
+		typ, semanticNonNull = sn.OfType, true
+	}
 	t, nonNull := unwrapNonNull(typ)
 
+	if r.responseTooLarge() {
+		out.WriteString("null")
+		return
+	}
+
 	// a reflect.Value of a nil interface will show up as an Invalid value
 	if resolver.Kind() == reflect.Invalid || ((resolver.Kind() == reflect.Ptr || resolver.Kind() == reflect.Interface) && resolver.IsNil()) {
 		// If a field of a non-null type resolves to null (either because the
 		// function to resolve the field returned null or because an error occurred),
-		// add an error to the "errors" list in the response.
+		// add an error to the "errors" list in the response. Semantically non-null
+		// positions are reported the same way, but their parents are not nulled.
 		if nonNull {
 			err := errors.Errorf("graphql: got nil for non-null %q", t)
 			err.Path = path.toSlice()
+			err.Locations = path.locations()
+			r.AddError(err)
+		} else if semanticNonNull {
+			err := errors.Errorf("graphql: got nil for semantically non-null %q", t)
+			err.Path = path.toSlice()
+			err.Locations = path.locations()
 			r.AddError(err)
 		}
 		out.WriteString("null")
@@ -258,6 +804,9 @@ func (r *Request) execSelectionSet(ctx context.Context, sels []selected.Selectio
 
 	switch t.(type) {
 	case *schema.Object, *schema.Interface, *schema.Union:
+		if r.EntityCache != nil && r.execEntity(ctx, sels, path, s, resolver, out) {
+			return
+		}
 		r.execSelections(ctx, sels, path, s, resolver, out, false)
 		return
 	}
@@ -270,32 +819,67 @@ func (r *Request) execSelectionSet(ctx context.Context, sels []selected.Selectio
 
 	switch t := t.(type) {
 	case *common.List:
-		r.execList(ctx, sels, t, path, s, resolver, out)
+		r.execList(ctx, sels, t, path, s, resolver, out, field)
 
 	case *schema.Scalar:
+		if k := resolver.Kind(); k == reflect.Float32 || k == reflect.Float64 {
+			if f := resolver.Float(); math.IsNaN(f) || math.IsInf(f, 0) {
+				r.writeNonFiniteFloat(t, f, nonNull || semanticNonNull, path, out)
+				return
+			}
+		}
+		if err := r.checkNumber(t, resolver); err != nil {
+			err.Path = path.toSlice()
+			err.Locations = path.locations()
+			r.AddError(err)
+			out.WriteString("null")
+			return
+		}
 		v := resolver.Interface()
+		if t.Name == "ID" {
+			if id, ok := idString(resolver); ok {
+				v = id
+			}
+		} else if r.Schema.Int64Policy != schema.Int64Default && resolvable.Is64BitInteger(resolver.Type()) {
+			v = int64Value(resolver, r.Schema.Int64Policy)
+		}
 		data, err := json.Marshal(v)
 		if err != nil {
-			panic(errors.Errorf("could not marshal %v: %s", v, err))
+			err := errors.Errorf("could not marshal %v: %s", v, err)
+			err.Path = path.toSlice()
+			err.Locations = path.locations()
+			r.AddError(err)
+			out.WriteString("null")
+			return
 		}
 		out.Write(data)
+		r.countBytes(len(data))
 
 	case *schema.Enum:
-		var stringer fmt.Stringer = resolver
-		if s, ok := resolver.Interface().(fmt.Stringer); ok {
-			stringer = s
-		}
-		name := stringer.String()
+		var name string
 		var valid bool
-		for _, v := range t.Values {
-			if v.Name == name {
-				valid = true
-				break
+		if m := r.Schema.EnumMappings[t.Name]; m != nil && resolver.Type() == m.Type {
+			name, valid = m.Names[resolver.Interface()]
+			if !valid {
+				name = fmt.Sprint(resolver.Interface())
+			}
+		} else {
+			var stringer fmt.Stringer = resolver
+			if s, ok := resolver.Interface().(fmt.Stringer); ok {
+				stringer = s
+			}
+			name = stringer.String()
+			for _, v := range t.Values {
+				if v.Name == name {
+					valid = true
+					break
+				}
 			}
 		}
 		if !valid {
 			err := errors.Errorf("Invalid value %s.\nExpected type %s, found %s.", name, t.Name, name)
 			err.Path = path.toSlice()
+			err.Locations = path.locations()
 			r.AddError(err)
 			out.WriteString("null")
 			return
@@ -303,27 +887,157 @@ func (r *Request) execSelectionSet(ctx context.Context, sels []selected.Selectio
 		out.WriteByte('"')
 		out.WriteString(name)
 		out.WriteByte('"')
+		r.countBytes(len(name) + 2)
 
 	default:
 		panic("unreachable")
 	}
 }
 
-func (r *Request) execList(ctx context.Context, sels []selected.Selection, typ *common.List, path *pathSegment, s *resolvable.Schema, resolver reflect.Value, out *bytes.Buffer) {
+// idString returns the result of an ID field resolved by an integer or an IDMarshaler as a string.
+// Other results are marshaled as JSON.
+func idString(v reflect.Value) (string, bool) {
+	if m, ok := v.Interface().(resolvable.IDMarshaler); ok {
+		return m.MarshalGraphQLID(), true
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), true
+	}
+	return "", false
+}
+
+// checkNumber returns an error if the result of an Int field is outside of the 32-bit range.
+// 64-bit integers only resolve Int fields with schema.Int64Default, as the other policies reject
+// them when the schema is built, so the policy never applies to the results of Int fields.
+func (r *Request) checkNumber(t *schema.Scalar, v reflect.Value) *errors.QueryError {
+	switch t.Name {
+	case "Int":
+		if r.Schema.LegacyNumericCoercion {
+			return nil
+		}
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if n := v.Int(); n < math.MinInt32 || n > math.MaxInt32 {
+				return errors.Errorf("Int cannot represent non 32-bit signed integer value: %d", n)
+			}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if n := v.Uint(); n > math.MaxInt32 {
+				return errors.Errorf("Int cannot represent non 32-bit signed integer value: %d", n)
+			}
+		}
+	}
+	return nil
+}
+
+// int64Value returns the value to write for a 64-bit integer resolving a Float, String or custom
+// scalar field according to a policy other than schema.Int64Default.
+func int64Value(v reflect.Value, policy schema.Int64Policy) interface{} {
+	signed := v.Kind() == reflect.Int || v.Kind() == reflect.Int64
+	if policy == schema.Int64AsFloat {
+		if signed {
+			return float64(v.Int())
+		}
+		return float64(v.Uint())
+	}
+	if signed {
+		return strconv.FormatInt(v.Int(), 10)
+	}
+	return strconv.FormatUint(v.Uint(), 10)
+}
+
+// writeNonFiniteFloat writes a NaN or infinite float result, which can not be represented in JSON,
+// according to the schema.Schema.NonFiniteFloats policy. Results of non-null and semantically
+// non-null fields always fail with an error, as neither null nor a string is a valid value there.
+func (r *Request) writeNonFiniteFloat(t *schema.Scalar, f float64, nonNull bool, path *pathSegment, out *bytes.Buffer) {
+	policy := r.Schema.NonFiniteFloats
+	if nonNull {
+		policy = schema.NonFiniteError
+	}
+	switch policy {
+	case schema.NonFiniteNull:
+		out.WriteString("null")
+	case schema.NonFiniteString:
+		switch {
+		case math.IsNaN(f):
+			out.WriteString(`"NaN"`)
+		case f > 0:
+			out.WriteString(`"Infinity"`)
+		default:
+			out.WriteString(`"-Infinity"`)
+		}
+	default:
+		err := errors.Errorf("%s cannot represent non numeric value: %v", t.Name, f)
+		err.Path = path.toSlice()
+		err.Locations = path.locations()
+		r.AddError(err)
+		out.WriteString("null")
+	}
+}
+
+func (r *Request) execList(ctx context.Context, sels []selected.Selection, typ *common.List, path *pathSegment, s *resolvable.Schema, resolver reflect.Value, out *bytes.Buffer, field *selected.SchemaField) {
 	l := resolver.Len()
+	maxLength := field.MaxListLength
+	if maxLength == 0 {
+		maxLength = r.MaxListLength
+	}
+	if maxLength > 0 && l > maxLength {
+		err := errors.Errorf("list of %d entries exceeds the limit of %d entries", l, maxLength)
+		err.Path = path.toSlice()
+		err.Locations = path.locations()
+		err.Extensions = map[string]interface{}{"code": "LIST_TOO_LONG", "limit": maxLength}
+		r.AddError(err)
+		out.WriteString("null")
+		return
+	}
+	r.countBytes(l + 1)
+	listConcurrency := field.ListConcurrency
+	if listConcurrency == 0 {
+		listConcurrency = r.MaxListConcurrency
+	}
+	if listConcurrency > 0 && listConcurrency < l && selected.HasAsyncSel(sels) {
+		r.execListChunked(ctx, sels, typ, path, s, resolver, out, listConcurrency, field)
+		return
+	}
+
 	entryouts := make([]bytes.Buffer, l)
 
-	if selected.HasAsyncSel(sels) {
+	if r.Workers != nil && selected.HasAsyncSel(sels) {
+		// The list entries share the workers of the request with all other selections.
+		var wg sync.WaitGroup
+		for i := 0; i < l; i++ {
+			if ctx.Err() != nil {
+				r.AddError(makeCancelledError(ctx, &pathSegment{parent: path, value: i}))
+				entryouts[i].WriteString("null")
+				continue
+			}
+			i := i
+			r.spawn(&wg, func() {
+				defer r.handlePanic(ctx)
+				r.execSelectionSet(ctx, sels, typ.OfType, &pathSegment{parent: path, value: i}, s, resolver.Index(i), &entryouts[i], field)
+			})
+		}
+		wg.Wait()
+	} else if selected.HasAsyncSel(sels) {
 		// Limit the number of concurrent goroutines spawned as it can lead to large
 		// memory spikes for large lists.
-		concurrency := cap(r.Limiter)
+		concurrency := r.MaxParallelism
 		sem := make(chan struct{}, concurrency)
 		for i := 0; i < l; i++ {
-			sem <- struct{}{}
+			// Stop dispatching list entries once the context got cancelled.
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				r.AddError(makeCancelledError(ctx, &pathSegment{parent: path, value: i}))
+				entryouts[i].WriteString("null")
+				continue
+			}
 			go func(i int) {
 				defer func() { <-sem }()
 				defer r.handlePanic(ctx)
-				r.execSelectionSet(ctx, sels, typ.OfType, &pathSegment{path, i}, s, resolver.Index(i), &entryouts[i])
+				r.execSelectionSet(ctx, sels, typ.OfType, &pathSegment{parent: path, value: i}, s, resolver.Index(i), &entryouts[i], field)
 			}(i)
 		}
 		for i := 0; i < concurrency; i++ {
@@ -331,7 +1045,7 @@ func (r *Request) execList(ctx context.Context, sels []selected.Selection, typ *
 		}
 	} else {
 		for i := 0; i < l; i++ {
-			r.execSelectionSet(ctx, sels, typ.OfType, &pathSegment{path, i}, s, resolver.Index(i), &entryouts[i])
+			r.execSelectionSet(ctx, sels, typ.OfType, &pathSegment{parent: path, value: i}, s, resolver.Index(i), &entryouts[i], field)
 		}
 	}
 
@@ -355,6 +1069,64 @@ func (r *Request) execList(ctx context.Context, sels []selected.Selection, typ *
 	out.WriteByte(']')
 }
 
+// execListChunked resolves the entries of a list in chunks of n entries, so only n goroutines and
+// buffers exist at a time, and writes each chunk before resolving the next one.
+func (r *Request) execListChunked(ctx context.Context, sels []selected.Selection, typ *common.List, path *pathSegment, s *resolvable.Schema, resolver reflect.Value, out *bytes.Buffer, n int, field *selected.SchemaField) {
+	l := resolver.Len()
+	entryouts := make([]bytes.Buffer, n)
+	_, listOfNonNull := typ.OfType.(*common.NonNull)
+	resolvedNull := false
+
+	out.WriteByte('[')
+	for start := 0; start < l; start += n {
+		end := start + n
+		if end > l {
+			end = l
+		}
+
+		var wg sync.WaitGroup
+		for i := start; i < end; i++ {
+			entryout := &entryouts[i-start]
+			entryout.Reset()
+			if ctx.Err() != nil {
+				r.AddError(makeCancelledError(ctx, &pathSegment{parent: path, value: i}))
+				entryout.WriteString("null")
+				continue
+			}
+			i := i
+			r.spawn(&wg, func() {
+				defer r.handlePanic(ctx)
+				r.execSelectionSet(ctx, sels, typ.OfType, &pathSegment{parent: path, value: i}, s, resolver.Index(i), entryout, field)
+			})
+		}
+		wg.Wait()
+
+		// The remaining entries are still resolved after the list resolved to null, so their
+		// errors are reported like those of other lists.
+		if resolvedNull {
+			continue
+		}
+		for i := start; i < end; i++ {
+			entryout := &entryouts[i-start]
+			if listOfNonNull && resolvedToNull(entryout) {
+				resolvedNull = true
+				break
+			}
+			if i > 0 {
+				out.WriteByte(',')
+			}
+			out.Write(entryout.Bytes())
+		}
+	}
+
+	if resolvedNull {
+		out.Reset()
+		out.WriteString("null")
+		return
+	}
+	out.WriteByte(']')
+}
+
 func unwrapNonNull(t common.Type) (common.Type, bool) {
 	if nn, ok := t.(*common.NonNull); ok {
 		return nn.OfType, true
@@ -365,6 +1137,13 @@ func unwrapNonNull(t common.Type) (common.Type, bool) {
 type pathSegment struct {
 	parent *pathSegment
 	value  interface{}
+
+	// loc is the location of the field in the query, which is not set for list indices.
+	loc errors.Location
+}
+
+func fieldPath(parent *pathSegment, f *selected.SchemaField) *pathSegment {
+	return &pathSegment{parent: parent, value: f.Alias, loc: f.Loc}
 }
 
 func (p *pathSegment) toSlice() []interface{} {
@@ -373,3 +1152,13 @@ func (p *pathSegment) toSlice() []interface{} {
 	}
 	return append(p.parent.toSlice(), p.value)
 }
+
+// locations returns the location of the innermost field of the path, if any.
+func (p *pathSegment) locations() []errors.Location {
+	for ; p != nil; p = p.parent {
+		if p.loc.Line != 0 {
+			return []errors.Location{p.loc}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package exec
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/graph-gophers/graphql-go/internal/common"
+	"github.com/graph-gophers/graphql-go/internal/exec/resolvable"
+	"github.com/graph-gophers/graphql-go/internal/exec/selected"
+	"github.com/graph-gophers/graphql-go/internal/schema"
+)
+
+type fieldInfoKey struct{}
+
+// FieldInfo describes the field a resolver is called for. It is stored in the context passed to
+// the resolver.
+type FieldInfo struct {
+	field    *selected.SchemaField
+	sels     []selected.Selection
+	resolver reflect.Value
+	req      *selected.Request
+	required map[string]interface{}
+}
+
+// FieldInfoFromContext returns the FieldInfo of the resolver called with ctx, or nil.
+func FieldInfoFromContext(ctx context.Context) *FieldInfo {
+	info, _ := ctx.Value(fieldInfoKey{}).(*FieldInfo)
+	return info
+}
+
+func withFieldInfo(ctx context.Context, r *Request, f *fieldToExec, required map[string]interface{}) context.Context {
+	return context.WithValue(ctx, fieldInfoKey{}, &FieldInfo{field: f.field, sels: f.sels, resolver: f.resolver, req: &r.Request, required: required})
+}
+
+// RequiredFields returns the values of the fields declared as dependencies of the field by a
+// "@dependsOn" directive, by field name.
+func (fi *FieldInfo) RequiredFields() map[string]interface{} {
+	return fi.required
+}
+
+// TypeName returns the name of the object type the field is resolved on. Fields of interfaces are
+// resolved on the object type the resolver converts to.
+func (fi *FieldInfo) TypeName() string {
+	for name, a := range fi.field.TypeAssertions {
+		if _, ok := a.Convert(fi.resolver); ok {
+			return name
+		}
+	}
+	return fi.field.TypeName
+}
+
+// SelectedField is a field selected on the result of a resolver.
+type SelectedField struct {
+	Name           string
+	Alias          string
+	Arguments      map[string]interface{}
+	TypeCondition  string
+	SelectedFields []*SelectedField
+	Skipped        bool
+}
+
+// SelectedFields returns the fields selected on the result of the resolver, once per response key
+// and type condition.
+func (fi *FieldInfo) SelectedFields() []*SelectedField {
+	return selectedFields(fi.sels, false)
+}
+
+// RequestedFields is like SelectedFields, but also returns the fields skipped by a @skip or
+// @include directive, which are marked as Skipped.
+func (fi *FieldInfo) RequestedFields() []*SelectedField {
+	return selectedFields(fi.sels, true)
+}
+
+func selectedFields(sels []selected.Selection, withSkipped bool) []*SelectedField {
+	type key struct {
+		alias, typeCondition string
+	}
+	var fields []*SelectedField
+	var children [][]selected.Selection
+	var skipped []*selected.SkippedField
+	var skippedConditions []string
+	byKey := make(map[key]int)
+
+	var collect func(sels []selected.Selection, typeCondition string)
+	collect = func(sels []selected.Selection, typeCondition string) {
+		for _, sel := range sels {
+			switch sel := sel.(type) {
+			case *selected.SchemaField:
+				k := key{sel.Alias, typeCondition}
+				i, ok := byKey[k]
+				if !ok {
+					i = len(fields)
+					byKey[k] = i
+					fields = append(fields, &SelectedField{
+						Name:          sel.Name,
+						Alias:         sel.Alias,
+						Arguments:     coercedArguments(sel),
+						TypeCondition: typeCondition,
+					})
+					children = append(children, nil)
+				}
+				children[i] = append(children[i], sel.Sels...)
+
+			case *selected.TypenameField:
+				k := key{sel.Alias, typeCondition}
+				if _, ok := byKey[k]; !ok {
+					byKey[k] = len(fields)
+					fields = append(fields, &SelectedField{Name: "__typename", Alias: sel.Alias, TypeCondition: typeCondition})
+					children = append(children, nil)
+				}
+
+			case *selected.TypeAssertion:
+				collect(sel.Sels, sel.TypeExec.(*resolvable.Object).Name)
+
+			case *selected.SkippedField:
+				if withSkipped {
+					condition := typeCondition
+					if sel.TypeCondition != "" {
+						condition = sel.TypeCondition
+					}
+					skipped = append(skipped, sel)
+					skippedConditions = append(skippedConditions, condition)
+				}
+			}
+		}
+	}
+	collect(sels, "")
+
+	for i, f := range fields {
+		if len(children[i]) != 0 {
+			f.SelectedFields = selectedFields(children[i], withSkipped)
+		}
+	}
+
+	// Skipped fields are only reported if the same response key is not also selected.
+	for i, sel := range skipped {
+		k := key{sel.Alias, skippedConditions[i]}
+		if _, ok := byKey[k]; ok {
+			continue
+		}
+		byKey[k] = len(fields)
+		f := skippedSelectedField(sel)
+		f.TypeCondition = skippedConditions[i]
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+func skippedSelectedField(sel *selected.SkippedField) *SelectedField {
+	f := &SelectedField{
+		Name:      sel.Name,
+		Alias:     sel.Alias,
+		Arguments: sel.Args,
+		Skipped:   true,
+	}
+	for _, child := range sel.Fields {
+		c := skippedSelectedField(child)
+		c.TypeCondition = child.TypeCondition
+		f.SelectedFields = append(f.SelectedFields, c)
+	}
+	return f
+}
+
+// coercedArguments returns the arguments of the field with variables replaced by their values and
+// default values for the omitted arguments.
+func coercedArguments(sel *selected.SchemaField) map[string]interface{} {
+	if len(sel.Field.Args) == 0 {
+		return nil
+	}
+	args := make(map[string]interface{}, len(sel.Field.Args))
+	for _, def := range sel.Field.Args {
+		if v, ok := sel.Args[def.Name.Name]; ok && v != nil {
+			args[def.Name.Name] = v
+		} else if def.Default != nil {
+			args[def.Name.Name] = def.Default.Value(nil)
+		} else if ok {
+			args[def.Name.Name] = nil
+		}
+	}
+	return args
+}
+
+// Directive is a directive applied to the field being resolved.
+type Directive struct {
+	Name       string
+	Arguments  map[string]interface{}
+	Definition bool
+}
+
+// Directives returns the directives applied to the field in the query, followed by those applied
+// to its definition in the schema.
+func (fi *FieldInfo) Directives() []*Directive {
+	var directives []*Directive
+	for _, d := range fi.field.Directives {
+		directives = append(directives, &Directive{
+			Name:      d.Name.Name,
+			Arguments: directiveArguments(fi.req.Schema.Directives[d.Name.Name], d, fi.req.Vars),
+		})
+	}
+	for _, d := range fi.field.Field.Field.Directives {
+		directives = append(directives, &Directive{
+			Name:       d.Name.Name,
+			Arguments:  directiveArguments(fi.req.Schema.Directives[d.Name.Name], d, nil),
+			Definition: true,
+		})
+	}
+	return directives
+}
+
+// directiveArguments returns the arguments of the directive with variables replaced by their values
+// and default values for the omitted arguments.
+func directiveArguments(decl *schema.DirectiveDecl, d *common.Directive, vars map[string]interface{}) map[string]interface{} {
+	args := make(map[string]interface{}, len(d.Args))
+	for _, arg := range d.Args {
+		args[arg.Name.Name] = arg.Value.Value(vars)
+	}
+	if decl != nil {
+		for _, def := range decl.Args {
+			if v, ok := args[def.Name.Name]; (!ok || v == nil) && def.Default != nil {
+				args[def.Name.Name] = def.Default.Value(nil)
+			}
+		}
+	}
+	return args
+}
@@ -0,0 +1,232 @@
+package packer
+
+import (
+	"fmt"
+	"regexp"
+	"unicode/utf8"
+
+	"github.com/graph-gophers/graphql-go/internal/common"
+	"github.com/graph-gophers/graphql-go/internal/schema"
+)
+
+// ConstraintError is the error of a value violating an input validation directive, i.e.
+// "@length(min: Int, max: Int)" on strings and lists, "@range(min: Float, max: Float)" on numbers
+// and "@pattern(regex: String!)" on strings. The entries of a list are checked by @range and
+// @pattern individually.
+type ConstraintError struct {
+	// Directive is the name of the violated directive, e.g. "length".
+	Directive string
+
+	// Message describes the violation, e.g. "must have at most 10 characters, got 12".
+	Message string
+}
+
+func (err *ConstraintError) Error() string {
+	return err.Message
+}
+
+// ValidatorError is the error of a value rejected by the validator of an input object, see
+// schema.Schema.InputValidators.
+type ValidatorError struct {
+	Err error
+}
+
+func (err *ValidatorError) Error() string {
+	return err.Err.Error()
+}
+
+// constraint checks a value against an input validation directive. It returns the violation and
+// the index of the violating entry if a list entry violates it, or -1.
+type constraint func(value interface{}) (int, *ConstraintError)
+
+// makeConstraints reads the input validation directives of an argument or input field.
+func makeConstraints(v *common.InputValue) ([]constraint, error) {
+	var constraints []constraint
+	t, _ := unwrapNonNull(v.Type)
+	if d := v.Directives.Get("length"); d != nil {
+		b, err := constraintBounds(d)
+		if err == nil && (b.min != nil && *b.min < 0 || b.max != nil && *b.max < 0) {
+			err = fmt.Errorf("min and max must not be negative")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("directive @length on %q: %s", v.Name.Name, err)
+		}
+		if !isList(t) && !isScalar(t, "String", "ID") {
+			return nil, fmt.Errorf("directive @length on %q: type must be a string or a list", v.Name.Name)
+		}
+		constraints = append(constraints, lengthConstraint(b))
+	}
+	if d := v.Directives.Get("range"); d != nil {
+		b, err := constraintBounds(d)
+		if err != nil {
+			return nil, fmt.Errorf("directive @range on %q: %s", v.Name.Name, err)
+		}
+		if !isScalar(elemType(t), "Int", "Float") {
+			return nil, fmt.Errorf("directive @range on %q: type must be Int or Float, or a list of them", v.Name.Name)
+		}
+		constraints = append(constraints, eachEntry(rangeConstraint(b)))
+	}
+	if d := v.Directives.Get("pattern"); d != nil {
+		var regex string
+		if lit, ok := d.Args.Get("regex"); ok && lit != nil {
+			regex, _ = lit.Value(nil).(string)
+		}
+		if regex == "" {
+			return nil, fmt.Errorf("directive @pattern on %q requires a regex", v.Name.Name)
+		}
+		re, err := regexp.Compile(regex)
+		if err != nil {
+			return nil, fmt.Errorf("directive @pattern on %q: %s", v.Name.Name, err)
+		}
+		if !isScalar(elemType(t), "String", "ID") {
+			return nil, fmt.Errorf("directive @pattern on %q: type must be a string, or a list of them", v.Name.Name)
+		}
+		constraints = append(constraints, eachEntry(patternConstraint(re)))
+	}
+	return constraints, nil
+}
+
+// bounds are the min and max arguments of @length and @range. A missing bound is nil.
+type bounds struct {
+	min, max *float64
+}
+
+func constraintBounds(d *common.Directive) (bounds, error) {
+	var b bounds
+	for _, bound := range []struct {
+		name string
+		v    **float64
+	}{{"min", &b.min}, {"max", &b.max}} {
+		lit, ok := d.Args.Get(bound.name)
+		if !ok || lit == nil {
+			continue
+		}
+		n, ok := numberValue(lit.Value(nil))
+		if !ok {
+			return b, fmt.Errorf("%s must be a number", bound.name)
+		}
+		*bound.v = &n
+	}
+	if b.min == nil && b.max == nil {
+		return b, fmt.Errorf("min or max is required")
+	}
+	if b.min != nil && b.max != nil && *b.min > *b.max {
+		return b, fmt.Errorf("min must not exceed max")
+	}
+	return b, nil
+}
+
+func lengthConstraint(b bounds) constraint {
+	return func(value interface{}) (int, *ConstraintError) {
+		var n int
+		unit := "characters"
+		switch value := value.(type) {
+		case string:
+			n = utf8.RuneCountInString(value)
+		case []interface{}:
+			n = len(value)
+			unit = "entries"
+		default:
+			n = 1 // a single value is coerced to a list
+			unit = "entries"
+		}
+		if b.min != nil && float64(n) < *b.min {
+			return -1, &ConstraintError{Directive: "length", Message: fmt.Sprintf("must have at least %v %s, got %d", *b.min, unit, n)}
+		}
+		if b.max != nil && float64(n) > *b.max {
+			return -1, &ConstraintError{Directive: "length", Message: fmt.Sprintf("must have at most %v %s, got %d", *b.max, unit, n)}
+		}
+		return -1, nil
+	}
+}
+
+func rangeConstraint(b bounds) func(value interface{}) *ConstraintError {
+	return func(value interface{}) *ConstraintError {
+		n, ok := numberValue(value)
+		if !ok {
+			return nil // rejected by the packer
+		}
+		if b.min != nil && n < *b.min || b.max != nil && n > *b.max {
+			return &ConstraintError{Directive: "range", Message: fmt.Sprintf("must be %s, got %v", b, n)}
+		}
+		return nil
+	}
+}
+
+func (b bounds) String() string {
+	switch {
+	case b.min == nil:
+		return fmt.Sprintf("at most %v", *b.max)
+	case b.max == nil:
+		return fmt.Sprintf("at least %v", *b.min)
+	default:
+		return fmt.Sprintf("between %v and %v", *b.min, *b.max)
+	}
+}
+
+func patternConstraint(re *regexp.Regexp) func(value interface{}) *ConstraintError {
+	return func(value interface{}) *ConstraintError {
+		s, ok := value.(string)
+		if ok && !re.MatchString(s) {
+			return &ConstraintError{Directive: "pattern", Message: fmt.Sprintf("must match the pattern %q", re.String())}
+		}
+		return nil
+	}
+}
+
+// eachEntry applies check to a value, or to every non-null entry if the value is a list.
+func eachEntry(check func(value interface{}) *ConstraintError) constraint {
+	return func(value interface{}) (int, *ConstraintError) {
+		list, ok := value.([]interface{})
+		if !ok {
+			return -1, check(value)
+		}
+		for i, entry := range list {
+			if entry == nil {
+				continue
+			}
+			if err := check(entry); err != nil {
+				return i, err
+			}
+		}
+		return -1, nil
+	}
+}
+
+func numberValue(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case int32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+func isList(t common.Type) bool {
+	_, ok := t.(*common.List)
+	return ok
+}
+
+// elemType returns the type of the entries of a list type, or t itself.
+func elemType(t common.Type) common.Type {
+	if l, ok := t.(*common.List); ok {
+		t, _ = unwrapNonNull(l.OfType)
+	}
+	return t
+}
+
+func isScalar(t common.Type, names ...string) bool {
+	s, ok := t.(*schema.Scalar)
+	if !ok {
+		return false
+	}
+	for _, name := range names {
+		if s.Name == name {
+			return true
+		}
+	}
+	return false
+}
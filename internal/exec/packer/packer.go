@@ -1,9 +1,11 @@
 package packer
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"reflect"
+	"strconv"
 	"strings"
 
 	"github.com/graph-gophers/graphql-go/errors"
@@ -12,12 +14,35 @@ import (
 )
 
 type packer interface {
-	Pack(value interface{}) (reflect.Value, error)
+	Pack(ctx context.Context, value interface{}) (reflect.Value, error)
 }
 
 type Builder struct {
 	packerMap     map[typePair]*packerMapEntry
 	structPackers []*StructPacker
+
+	// TruncateFloats converts Float values passed for Int inputs by truncating them, see
+	// schema.Schema.LegacyNumericCoercion.
+	TruncateFloats bool
+
+	// OneOfConstructors convert the members of @oneOf input objects unpacked into Go interfaces,
+	// see schema.Schema.OneOfConstructors.
+	OneOfConstructors map[string]map[string]reflect.Value
+
+	// EnumMappings map the values of enums to Go values, see schema.Schema.EnumMappings.
+	EnumMappings map[string]*schema.EnumMapping
+
+	// InputValidators validate the unpacked values of input objects, see
+	// schema.Schema.InputValidators.
+	InputValidators map[string]reflect.Value
+
+	// OriginalName returns the declared name of a renamed type, which is passed to the
+	// ImplementsGraphQLType method of custom scalars. It may be nil.
+	OriginalName func(name string) string
+
+	// FieldNameMapper maps the names of the fields of argument and input structs to GraphQL names,
+	// see schema.Schema.FieldNameMapper.
+	FieldNameMapper func(goName string) string
 }
 
 type typePair struct {
@@ -47,7 +72,7 @@ func (b *Builder) Finish() error {
 		p.defaultStruct = reflect.New(p.structType).Elem()
 		for _, f := range p.fields {
 			if defaultVal := f.field.Default; defaultVal != nil {
-				v, err := f.fieldPacker.Pack(defaultVal.Value(nil))
+				v, err := f.fieldPacker.Pack(context.WithValue(context.Background(), defaultValueKey{}, true), defaultVal.Value(nil))
 				if err != nil {
 					return err
 				}
@@ -78,14 +103,14 @@ func (b *Builder) assignPacker(target *packer, schemaType common.Type, reflectTy
 func (b *Builder) makePacker(schemaType common.Type, reflectType reflect.Type) (packer, error) {
 	t, nonNull := unwrapNonNull(schemaType)
 	if !nonNull {
-		if reflectType.Kind() != reflect.Ptr {
+		if reflectType.Kind() != reflect.Ptr && !isOneOfInterface(t, reflectType) {
 			return nil, fmt.Errorf("%s is not a pointer", reflectType)
 		}
-		elemType := reflectType.Elem()
-		addPtr := true
-		if _, ok := t.(*schema.InputObject); ok {
-			elemType = reflectType // keep pointer for input objects
-			addPtr = false
+		elemType := reflectType
+		addPtr := false
+		if _, ok := t.(*schema.InputObject); !ok {
+			elemType = reflectType.Elem() // keep pointer for input objects, or the interface of @oneOf inputs
+			addPtr = true
 		}
 		elem, err := b.makeNonNullPacker(t, elemType)
 		if err != nil {
@@ -103,7 +128,11 @@ func (b *Builder) makePacker(schemaType common.Type, reflectType reflect.Type) (
 
 func (b *Builder) makeNonNullPacker(schemaType common.Type, reflectType reflect.Type) (packer, error) {
 	if u, ok := reflect.New(reflectType).Interface().(Unmarshaler); ok {
-		if !u.ImplementsGraphQLType(schemaType.String()) {
+		name := schemaType.String()
+		if b.OriginalName != nil {
+			name = b.OriginalName(name)
+		}
+		if !u.ImplementsGraphQLType(name) {
 			return nil, fmt.Errorf("can not unmarshal %s into %s", schemaType, reflectType)
 		}
 		return &unmarshalerPacker{
@@ -113,11 +142,18 @@ func (b *Builder) makeNonNullPacker(schemaType common.Type, reflectType reflect.
 
 	switch t := schemaType.(type) {
 	case *schema.Scalar:
+		if t.Name == "ID" {
+			return makeIDPacker(reflectType)
+		}
 		return &ValuePacker{
-			ValueType: reflectType,
+			ValueType:      reflectType,
+			TruncateFloats: b.TruncateFloats,
 		}, nil
 
 	case *schema.Enum:
+		if m := b.EnumMappings[t.Name]; m != nil && reflectType == m.Type {
+			return &enumPacker{Values: m.Values}, nil
+		}
 		if reflectType.Kind() != reflect.String {
 			return nil, fmt.Errorf("wrong type, expected %s", reflect.String)
 		}
@@ -126,10 +162,18 @@ func (b *Builder) makeNonNullPacker(schemaType common.Type, reflectType reflect.
 		}, nil
 
 	case *schema.InputObject:
+		if isOneOfInterface(t, reflectType) {
+			return b.makeOneOfPacker(t, reflectType)
+		}
 		e, err := b.MakeStructPacker(t.Values, reflectType)
 		if err != nil {
 			return nil, err
 		}
+		if validator, ok := b.InputValidators[t.Name]; ok {
+			if err := e.setValidator(t.Name, validator); err != nil {
+				return nil, err
+			}
+		}
 		return e, nil
 
 	case *common.List:
@@ -167,10 +211,13 @@ func (b *Builder) MakeStructPacker(values common.InputValueList, typ reflect.Typ
 	for _, v := range values {
 		fe := &structPackerField{field: v}
 		fx := func(n string) bool {
-			return strings.EqualFold(stripUnderscore(n), stripUnderscore(v.Name.Name))
+			return MatchName(b.FieldNameMapper, n, v.Name.Name)
 		}
 
-		sf, ok := structType.FieldByNameFunc(fx)
+		sf, ok := taggedField(b.FieldNameMapper, structType, v.Name.Name)
+		if !ok {
+			sf, ok = structType.FieldByNameFunc(fx)
+		}
 		if !ok {
 			return nil, fmt.Errorf("%s does not define field %q (hint: missing `args struct { ... }` wrapper for field arguments, or missing field on input struct)", typ, v.Name.Name)
 		}
@@ -188,6 +235,11 @@ func (b *Builder) MakeStructPacker(values common.InputValueList, typ reflect.Typ
 		if err := b.assignPacker(&fe.fieldPacker, ft, sf.Type); err != nil {
 			return nil, fmt.Errorf("field %q: %s", sf.Name, err)
 		}
+		constraints, err := makeConstraints(v)
+		if err != nil {
+			return nil, err
+		}
+		fe.constraints = constraints
 
 		fields = append(fields, fe)
 	}
@@ -206,15 +258,42 @@ type StructPacker struct {
 	usePtr        bool
 	defaultStruct reflect.Value
 	fields        []*structPackerField
+
+	// validator is called with the unpacked value, see schema.Schema.InputValidators.
+	validator    reflect.Value
+	validatorPtr bool
+}
+
+// defaultValueKey marks the context of packing the default values of fields, which are not passed
+// to validators as the schema is parsed before any operation.
+type defaultValueKey struct{}
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// setValidator checks that the validator of the input object takes the Go type of the input,
+// either as a pointer or as a value.
+func (p *StructPacker) setValidator(typeName string, validator reflect.Value) error {
+	vt := validator.Type()
+	if vt.Kind() != reflect.Func || vt.NumIn() != 2 || vt.In(0) != contextType || vt.NumOut() != 1 || vt.Out(0) != errorType ||
+		vt.In(1) != p.structType && vt.In(1) != reflect.PtrTo(p.structType) {
+		return fmt.Errorf("input validator of %q must be a func(context.Context, *%s) error, got %s", typeName, p.structType, vt)
+	}
+	p.validator = validator
+	p.validatorPtr = vt.In(1).Kind() == reflect.Ptr
+	return nil
 }
 
 type structPackerField struct {
 	field       *common.InputValue
 	fieldIndex  []int
 	fieldPacker packer
+	constraints []constraint
 }
 
-func (p *StructPacker) Pack(value interface{}) (reflect.Value, error) {
+func (p *StructPacker) Pack(ctx context.Context, value interface{}) (reflect.Value, error) {
 	if value == nil {
 		return reflect.Value{}, errors.Errorf("got null for non-null")
 	}
@@ -224,25 +303,141 @@ func (p *StructPacker) Pack(value interface{}) (reflect.Value, error) {
 	v.Elem().Set(p.defaultStruct)
 	for _, f := range p.fields {
 		if value, ok := values[f.field.Name.Name]; ok {
-			packed, err := f.fieldPacker.Pack(value)
+			if value != nil {
+				for _, check := range f.constraints {
+					if i, err := check(value); err != nil {
+						fe := &FieldError{Err: err}
+						if i != -1 {
+							fe.Path = []interface{}{i}
+						}
+						return reflect.Value{}, fe.within(f.field.Name.Name)
+					}
+				}
+			}
+			packed, err := f.fieldPacker.Pack(ctx, value)
 			if err != nil {
-				return reflect.Value{}, err
+				fe, ok := err.(*FieldError)
+				if !ok {
+					fe = &FieldError{Err: err}
+				}
+				return reflect.Value{}, fe.within(f.field.Name.Name)
 			}
 			v.Elem().FieldByIndex(f.fieldIndex).Set(packed)
 		}
 	}
+	if p.validator.IsValid() && ctx.Value(defaultValueKey{}) == nil {
+		arg := v
+		if !p.validatorPtr {
+			arg = v.Elem()
+		}
+		out := p.validator.Call([]reflect.Value{reflect.ValueOf(ctx), arg})
+		if err, _ := out[0].Interface().(error); err != nil {
+			return reflect.Value{}, &FieldError{Err: &ValidatorError{Err: err}}
+		}
+	}
 	if !p.usePtr {
 		return v.Elem(), nil
 	}
 	return v, nil
 }
 
+// isOneOfInterface reports whether the @oneOf input object t is unpacked into a Go interface.
+func isOneOfInterface(t common.Type, reflectType reflect.Type) bool {
+	in, ok := t.(*schema.InputObject)
+	return ok && in.OneOf() && reflectType.Kind() == reflect.Interface
+}
+
+func (b *Builder) makeOneOfPacker(t *schema.InputObject, reflectType reflect.Type) (*oneOfPacker, error) {
+	p := &oneOfPacker{
+		typeName: t.Name,
+		members:  make(map[string]*oneOfMember),
+	}
+	for _, v := range t.Values {
+		constructor, ok := b.OneOfConstructors[t.Name][v.Name.Name]
+		if !ok {
+			return nil, fmt.Errorf("no constructor registered for field %q of oneOf input %q", v.Name.Name, t.Name)
+		}
+		ct := constructor.Type()
+		if ct.Kind() != reflect.Func || ct.NumIn() != 1 || ct.NumOut() != 1 || !ct.Out(0).AssignableTo(reflectType) {
+			return nil, fmt.Errorf("constructor for field %q of oneOf input %q must be a func with one parameter returning %s, got %s", v.Name.Name, t.Name, reflectType, ct)
+		}
+		m := &oneOfMember{constructor: constructor}
+		memberType, _ := unwrapNonNull(v.Type)
+		if err := b.assignPacker(&m.packer, &common.NonNull{OfType: memberType}, ct.In(0)); err != nil {
+			return nil, fmt.Errorf("field %q: %s", v.Name.Name, err)
+		}
+		p.members[v.Name.Name] = m
+	}
+	return p, nil
+}
+
+// oneOfPacker unpacks a @oneOf input object into a Go interface. The value of the given field is
+// passed to the constructor registered for it.
+type oneOfPacker struct {
+	typeName string
+	members  map[string]*oneOfMember
+}
+
+type oneOfMember struct {
+	constructor reflect.Value
+	packer      packer
+}
+
+func (p *oneOfPacker) Pack(ctx context.Context, value interface{}) (reflect.Value, error) {
+	if value == nil {
+		return reflect.Value{}, errors.Errorf("got null for non-null")
+	}
+
+	var name string
+	var memberValue interface{}
+	for n, v := range value.(map[string]interface{}) {
+		if v == nil {
+			continue
+		}
+		if name != "" {
+			return reflect.Value{}, errors.Errorf("oneOf input %q has more than one field", p.typeName)
+		}
+		name, memberValue = n, v
+	}
+	m, ok := p.members[name]
+	if !ok {
+		return reflect.Value{}, errors.Errorf("oneOf input %q requires exactly one non-null field", p.typeName)
+	}
+	packed, err := m.packer.Pack(ctx, memberValue)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return m.constructor.Call([]reflect.Value{packed})[0], nil
+}
+
+// FieldError is returned by StructPacker if a field can not be packed. Name is the field closest
+// to the root of the input, i.e. the argument if the StructPacker packs the arguments of a field,
+// so the error can be reported at the location of its value in the query. Path is the path of the
+// invalid value from the root of the input, starting with Name, with the names of the input fields
+// and the indexes of the list entries, e.g. ["input", "items", 3, "price"].
+type FieldError struct {
+	Name string
+	Path []interface{}
+	Err  error
+}
+
+func (err *FieldError) Error() string {
+	return err.Err.Error()
+}
+
+// within prepends the name of the field containing the invalid value to the path.
+func (err *FieldError) within(name string) *FieldError {
+	err.Name = name
+	err.Path = append([]interface{}{name}, err.Path...)
+	return err
+}
+
 type listPacker struct {
 	sliceType reflect.Type
 	elem      packer
 }
 
-func (e *listPacker) Pack(value interface{}) (reflect.Value, error) {
+func (e *listPacker) Pack(ctx context.Context, value interface{}) (reflect.Value, error) {
 	list, ok := value.([]interface{})
 	if !ok {
 		list = []interface{}{value}
@@ -250,9 +445,14 @@ func (e *listPacker) Pack(value interface{}) (reflect.Value, error) {
 
 	v := reflect.MakeSlice(e.sliceType, len(list), len(list))
 	for i := range list {
-		packed, err := e.elem.Pack(list[i])
+		packed, err := e.elem.Pack(ctx, list[i])
 		if err != nil {
-			return reflect.Value{}, err
+			fe, ok := err.(*FieldError)
+			if !ok {
+				fe = &FieldError{Err: err}
+			}
+			fe.Path = append([]interface{}{i}, fe.Path...)
+			return reflect.Value{}, fe
 		}
 		v.Index(i).Set(packed)
 	}
@@ -265,12 +465,12 @@ type nullPacker struct {
 	addPtr     bool
 }
 
-func (p *nullPacker) Pack(value interface{}) (reflect.Value, error) {
+func (p *nullPacker) Pack(ctx context.Context, value interface{}) (reflect.Value, error) {
 	if value == nil {
 		return reflect.Zero(p.valueType), nil
 	}
 
-	v, err := p.elemPacker.Pack(value)
+	v, err := p.elemPacker.Pack(ctx, value)
 	if err != nil {
 		return reflect.Value{}, err
 	}
@@ -286,25 +486,98 @@ func (p *nullPacker) Pack(value interface{}) (reflect.Value, error) {
 
 type ValuePacker struct {
 	ValueType reflect.Type
+
+	// TruncateFloats converts Float values into Int values by truncating them instead of rejecting
+	// values with a fractional part.
+	TruncateFloats bool
 }
 
-func (p *ValuePacker) Pack(value interface{}) (reflect.Value, error) {
+func (p *ValuePacker) Pack(ctx context.Context, value interface{}) (reflect.Value, error) {
 	if value == nil {
 		return reflect.Value{}, errors.Errorf("got null for non-null")
 	}
 
-	coerced, err := unmarshalInput(p.ValueType, value)
+	coerced, err := unmarshalInput(p.ValueType, value, p.TruncateFloats)
 	if err != nil {
 		return reflect.Value{}, fmt.Errorf("could not unmarshal %#v (%T) into %s: %s", value, value, p.ValueType, err)
 	}
 	return reflect.ValueOf(coerced), nil
 }
 
+func makeIDPacker(reflectType reflect.Type) (packer, error) {
+	switch reflectType.Kind() {
+	case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &idPacker{ValueType: reflectType}, nil
+	}
+	return nil, fmt.Errorf("can not unmarshal ID into %s, expected a string or integer type", reflectType)
+}
+
+// idPacker unpacks ID values, which may be given as strings or integers, into string and integer
+// types.
+type idPacker struct {
+	ValueType reflect.Type
+}
+
+func (p *idPacker) Pack(ctx context.Context, value interface{}) (reflect.Value, error) {
+	if value == nil {
+		return reflect.Value{}, errors.Errorf("got null for non-null")
+	}
+
+	var s string
+	switch value := value.(type) {
+	case string:
+		s = value
+	case int32:
+		s = strconv.Itoa(int(value))
+	case float64:
+		s = strconv.FormatFloat(value, 'f', -1, 64)
+	default:
+		return reflect.Value{}, fmt.Errorf("wrong type for ID: %T", value)
+	}
+
+	v := reflect.New(p.ValueType).Elem()
+	switch p.ValueType.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, p.ValueType.Bits())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("could not unmarshal ID %q into %s", s, p.ValueType)
+		}
+		v.SetInt(n)
+	default:
+		n, err := strconv.ParseUint(s, 10, p.ValueType.Bits())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("could not unmarshal ID %q into %s", s, p.ValueType)
+		}
+		v.SetUint(n)
+	}
+	return v, nil
+}
+
+// enumPacker unpacks enum values into the Go values they are mapped to.
+type enumPacker struct {
+	Values map[string]reflect.Value
+}
+
+func (p *enumPacker) Pack(ctx context.Context, value interface{}) (reflect.Value, error) {
+	if value == nil {
+		return reflect.Value{}, errors.Errorf("got null for non-null")
+	}
+	name, _ := value.(string)
+	v, ok := p.Values[name]
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("no Go value for enum value %v", value)
+	}
+	return v, nil
+}
+
 type unmarshalerPacker struct {
 	ValueType reflect.Type
 }
 
-func (p *unmarshalerPacker) Pack(value interface{}) (reflect.Value, error) {
+func (p *unmarshalerPacker) Pack(ctx context.Context, value interface{}) (reflect.Value, error) {
 	if value == nil {
 		return reflect.Value{}, errors.Errorf("got null for non-null")
 	}
@@ -316,12 +589,17 @@ func (p *unmarshalerPacker) Pack(value interface{}) (reflect.Value, error) {
 	return v.Elem(), nil
 }
 
+// Unmarshaler is implemented by custom scalars. UnmarshalGraphQL receives literals of the query in
+// a normalized form: Int values as int32, Float values as float64, strings and enum values as
+// string, booleans as bool, lists as []interface{}, input objects as map[string]interface{} and
+// null as nil, where nested variables are replaced by their values. Values of variables are passed
+// as decoded from JSON.
 type Unmarshaler interface {
 	ImplementsGraphQLType(name string) bool
 	UnmarshalGraphQL(input interface{}) error
 }
 
-func unmarshalInput(typ reflect.Type, input interface{}) (interface{}, error) {
+func unmarshalInput(typ reflect.Type, input interface{}, truncateFloats bool) (interface{}, error) {
 	if reflect.TypeOf(input) == typ {
 		return input, nil
 	}
@@ -336,7 +614,7 @@ func unmarshalInput(typ reflect.Type, input interface{}) (interface{}, error) {
 			return int32(input), nil
 		case float64:
 			coerced := int32(input)
-			if input < math.MinInt32 || input > math.MaxInt32 || float64(coerced) != input {
+			if input < math.MinInt32 || input > math.MaxInt32 || (float64(coerced) != input && !truncateFloats) {
 				return nil, fmt.Errorf("not a 32-bit integer")
 			}
 			return coerced, nil
@@ -366,6 +644,68 @@ func unwrapNonNull(t common.Type) (common.Type, bool) {
 	return t, false
 }
 
+// FieldName returns the GraphQL name of a struct field, which is the name of its "graphql" tag,
+// e.g. `graphql:"login,nonnull"`, or else its Go name.
+func FieldName(sf reflect.StructField) string {
+	if tag, ok := sf.Tag.Lookup("graphql"); ok {
+		if name := strings.Split(tag, ",")[0]; name != "" && name != "-" {
+			return name
+		}
+	}
+	return sf.Name
+}
+
+// IgnoredField reports whether the struct field is tagged `graphql:"-"`, which excludes it from
+// resolving fields.
+func IgnoredField(sf reflect.StructField) bool {
+	tag, ok := sf.Tag.Lookup("graphql")
+	return ok && strings.Split(tag, ",")[0] == "-"
+}
+
+// HasTagOption reports whether the "graphql" tag of the struct field has the option, e.g. "nilnull"
+// in `graphql:"posts,nilnull"`.
+func HasTagOption(sf reflect.StructField, option string) bool {
+	tag, ok := sf.Tag.Lookup("graphql")
+	if !ok {
+		return false
+	}
+	for _, opt := range strings.Split(tag, ",")[1:] {
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchName reports whether a Go name binds a GraphQL name. Without a mapper, the names are compared
+// ignoring case and underscores.
+func MatchName(mapper func(goName string) string, goName, name string) bool {
+	if mapper != nil {
+		return mapper(goName) == name
+	}
+	return strings.EqualFold(stripUnderscore(goName), stripUnderscore(name))
+}
+
+// MatchFieldName reports whether a struct field binds a GraphQL name. The name of a "graphql" tag
+// is not mapped.
+func MatchFieldName(mapper func(goName string) string, sf reflect.StructField, name string) bool {
+	if tagName := FieldName(sf); tagName != sf.Name {
+		return MatchName(nil, tagName, name)
+	}
+	return MatchName(mapper, sf.Name, name)
+}
+
+// taggedField returns the field of the struct whose "graphql" tag names the GraphQL field.
+func taggedField(mapper func(goName string) string, t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.Name != FieldName(sf) && MatchFieldName(mapper, sf, name) {
+			return sf, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
 func stripUnderscore(s string) string {
 	return strings.Replace(s, "_", "", -1)
 }
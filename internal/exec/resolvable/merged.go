@@ -0,0 +1,114 @@
+package resolvable
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/graph-gophers/graphql-go/internal/schema"
+)
+
+// Merged is a root resolver combined from several resolvers. Each field of the root operation
+// types is resolved by exactly one of them.
+type Merged struct {
+	Resolvers []reflect.Value
+}
+
+// MergedType is the type of a merged root resolver.
+var MergedType = reflect.TypeOf(&Merged{})
+
+// NewMerged returns a root resolver combined from the given resolvers.
+func NewMerged(resolvers []interface{}) *Merged {
+	m := &Merged{}
+	for _, r := range resolvers {
+		m.Resolvers = append(m.Resolvers, reflect.ValueOf(r))
+	}
+	return m
+}
+
+// Resolver returns the resolver of the field, which is the part of a merged resolver the field
+// was bound to.
+func (f *Field) Resolver(resolver reflect.Value) reflect.Value {
+	if resolver.Type() == MergedType {
+		return resolver.Interface().(*Merged).Resolvers[f.Part]
+	}
+	return resolver
+}
+
+func (b *execBuilder) makeMergedObjectExec(typeName string, fields schema.FieldList) (*Object, error) {
+	if b.merged == nil {
+		return nil, fmt.Errorf("merged resolvers can only be used as the root resolver")
+	}
+
+	var parts []reflect.Type
+	for _, r := range b.merged.Resolvers {
+		parts = append(parts, r.Type())
+	}
+
+	Fields := make(map[string]*Field)
+	for _, f := range fields {
+		// Hoisted fields are resolved by the resolver of the first field they are hoisted through.
+		name := f.Name
+		if len(f.Via) != 0 {
+			name = f.Via[0].Name
+		}
+		part := -1
+		var methodIndex int
+		var fieldIndex []int
+		for i, t := range parts {
+			mi, fi, err := b.findResolver(typeName, t, name)
+			if err != nil {
+				return nil, err
+			}
+			if mi == -1 && len(fi) == 0 {
+				continue
+			}
+			if part != -1 {
+				return nil, fmt.Errorf("%s and %s both resolve field %q of %q", parts[part], t, name, typeName)
+			}
+			part, methodIndex, fieldIndex = i, mi, fi
+		}
+		if part == -1 {
+			names := make([]string, len(parts))
+			for i, t := range parts {
+				names[i] = t.String()
+			}
+			return nil, fmt.Errorf("none of the resolvers %s resolves field %q of %q", strings.Join(names, ", "), name, typeName)
+		}
+
+		t := parts[part]
+		if len(f.Via) != 0 {
+			fe, err := b.makeHoistedFieldExec(typeName, f, t)
+			if err != nil {
+				return nil, err
+			}
+			fe.Part = part
+			Fields[f.Name] = fe
+			continue
+		}
+		var m reflect.Method
+		var sf reflect.StructField
+		if methodIndex != -1 {
+			m = t.Method(methodIndex)
+		} else {
+			sf = unwrapPtr(t).FieldByIndex(fieldIndex)
+		}
+		fe, err := b.makeFieldExec(typeName, f, m, sf, methodIndex, fieldIndex, t.Kind() != reflect.Interface)
+		if err != nil {
+			return nil, fmt.Errorf("%s\n\tused by (%s).%s", err, t, m.Name)
+		}
+		// Dependencies must be resolved by the same resolver as the field.
+		if fe.Requires, err = b.makeRequiredFieldExecs(typeName, f, fields, t); err != nil {
+			return nil, err
+		}
+		fe.Part = part
+		Fields[f.Name] = fe
+	}
+
+	return &Object{
+		Name:         typeName,
+		Fields:       Fields,
+		ResolverType: MergedType,
+		Parts:        parts,
+	}, nil
+}
@@ -21,6 +21,8 @@ type Meta struct {
 func newMeta(s *schema.Schema) *Meta {
 	var err error
 	b := newBuilder(s)
+	b.fieldNameMapper = nil
+	b.packerBuilder.FieldNameMapper = nil
 
 	metaSchema := s.Types["__Schema"].(*schema.Object)
 	so, err := b.makeObjectExec(metaSchema.Name, metaSchema.Fields, nil, false, reflect.TypeOf(&introspection.Schema{}))
@@ -2,9 +2,11 @@ package resolvable
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/graph-gophers/graphql-go/internal/common"
 	"github.com/graph-gophers/graphql-go/internal/exec/packer"
@@ -28,6 +30,10 @@ type Object struct {
 	Name           string
 	Fields         map[string]*Field
 	TypeAssertions map[string]*TypeAssertion
+	ResolverType   reflect.Type
+
+	// Parts are the types of the resolvers of a merged root resolver.
+	Parts []reflect.Type
 }
 
 type Field struct {
@@ -40,6 +46,88 @@ type Field struct {
 	ArgsPacker  *packer.StructPacker
 	ValueExec   Resolvable
 	TraceLabel  string
+	Serial      bool
+	Deadline    time.Duration
+	Retry       *schema.RetryPolicy
+	Redaction   *Redaction
+
+	// TypeAssertions are those of the interface declaring the field. They determine the concrete
+	// object type of a resolver.
+	TypeAssertions map[string]*TypeAssertion
+
+	// Part is the index of the resolver resolving the field in a merged root resolver.
+	Part int
+
+	// OnError is read from an "@onError(action: NULL | PROPAGATE | DROP)" directive and decides
+	// how a resolver error affects the response.
+	OnError ErrorAction
+
+	// ListConcurrency is read from a "@listConcurrency(max: Int!)" directive and limits the number
+	// of list entries of the field resolved concurrently, or is zero if the field has no such
+	// directive.
+	ListConcurrency int
+
+	// MaxListLength is read from a "@maxListLength(max: Int!)" directive and limits the number of
+	// entries of the lists of the field, or is zero if the field has no such directive.
+	MaxListLength int
+
+	// NilSliceAsNull writes a nil slice resolved for the nullable list field as null instead of an
+	// empty list. It is read from a "@nilSlice(as: NilSlice!)" directive, whose argument is one of
+	// the enum values NULL and EMPTY, or from a "nilnull" or "nilempty" option of the "graphql" tag of
+	// a struct field resolver, and defaults to schema.Schema.NilSlicesAsNull.
+	NilSliceAsNull bool
+
+	// ExecType is the type of the field with the positions marked by a "@semanticNonNull" directive
+	// wrapped by SemanticNonNull, or nil if the field has no such directive.
+	ExecType common.Type
+
+	// ResultType is the Go type of the results of the resolver.
+	ResultType reflect.Type
+
+	// Wrapper wraps the resolver of the field, see schema.Schema.WrapResolver.
+	Wrapper schema.ResolverWrapper
+
+	// Via resolve the intermediate values of a hoisted field, see schema.Schema.HoistField. The
+	// field is resolved on the result of the last of them.
+	Via []*Field
+
+	// Requires are read from a "@dependsOn(fields: [String!]!)" directive and resolve the sibling
+	// fields whose values the resolver of the field needs.
+	Requires []*Field
+}
+
+// ErrorAction decides how a resolver error of a field affects the response.
+type ErrorAction int
+
+const (
+	// OnErrorDefault nulls the field, and its parent if the field is non-null.
+	OnErrorDefault ErrorAction = iota
+
+	// OnErrorNull nulls only the field, even if it is non-null.
+	OnErrorNull
+
+	// OnErrorPropagate nulls the parent of the field, even if the field is nullable.
+	OnErrorPropagate
+
+	// OnErrorDrop omits the field from the response.
+	OnErrorDrop
+)
+
+// SemanticNonNull wraps a position of a field type that is nullable, but only resolves to null in
+// case of an error. A null value without error is reported as an error, without nulling the parent.
+type SemanticNonNull struct {
+	OfType common.Type
+}
+
+func (t *SemanticNonNull) Kind() string   { return t.OfType.Kind() }
+func (t *SemanticNonNull) String() string { return t.OfType.String() }
+
+// Redaction is read from a "@redact(when: String!, placeholder: String)" directive on the field
+// definition. The field is not resolved if the predicate named When reports true, and resolves to
+// the Placeholder instead, which is invalid for null.
+type Redaction struct {
+	When        string
+	Placeholder reflect.Value
 }
 
 func (f *Field) UseMethodResolver() bool {
@@ -49,6 +137,21 @@ func (f *Field) UseMethodResolver() bool {
 type TypeAssertion struct {
 	MethodIndex int
 	TypeExec    Resolvable
+
+	// FieldIndex is the index of the pointer field holding the member of a union resolved by a
+	// sum-type struct, or nil if the resolver converts to the member with the method.
+	FieldIndex []int
+}
+
+// Convert converts the resolver of an interface or union to the resolver of the member type and
+// reports whether it is of that type.
+func (a *TypeAssertion) Convert(resolver reflect.Value) (reflect.Value, bool) {
+	if a.FieldIndex != nil {
+		v := reflect.Indirect(resolver).FieldByIndex(a.FieldIndex)
+		return v, !v.IsNil()
+	}
+	out := resolver.Method(a.MethodIndex).Call(nil)
+	return out[0], out[1].Bool()
 }
 
 type List struct {
@@ -57,9 +160,19 @@ type List struct {
 
 type Scalar struct{}
 
-func (*Object) isResolvable() {}
-func (*List) isResolvable()   {}
-func (*Scalar) isResolvable() {}
+// RawJSON resolves a field to pre-serialized JSON, which is written to the response without
+// executing the selections of the field.
+type RawJSON struct{}
+
+// Marshaler resolves a field to a value implementing the schema's MarshalerType, which writes
+// itself to the response.
+type Marshaler struct{}
+
+func (*Object) isResolvable()    {}
+func (*List) isResolvable()      {}
+func (*Scalar) isResolvable()    {}
+func (*RawJSON) isResolvable()   {}
+func (*Marshaler) isResolvable() {}
 
 func ApplyResolver(s *schema.Schema, resolver interface{}) (*Schema, error) {
 	if resolver == nil {
@@ -67,6 +180,7 @@ func ApplyResolver(s *schema.Schema, resolver interface{}) (*Schema, error) {
 	}
 
 	b := newBuilder(s)
+	b.merged, _ = resolver.(*Merged)
 
 	var query, mutation, subscription Resolvable
 
@@ -106,6 +220,11 @@ type execBuilder struct {
 	schema        *schema.Schema
 	resMap        map[typePair]*resMapEntry
 	packerBuilder *packer.Builder
+	merged        *Merged
+
+	// fieldNameMapper is the schema.Schema.FieldNameMapper, which does not apply to the
+	// introspection resolvers.
+	fieldNameMapper func(goName string) string
 }
 
 type typePair struct {
@@ -119,10 +238,18 @@ type resMapEntry struct {
 }
 
 func newBuilder(s *schema.Schema) *execBuilder {
+	packerBuilder := packer.NewBuilder()
+	packerBuilder.TruncateFloats = s.LegacyNumericCoercion
+	packerBuilder.OneOfConstructors = s.OneOfConstructors
+	packerBuilder.EnumMappings = s.EnumMappings
+	packerBuilder.InputValidators = s.InputValidators
+	packerBuilder.OriginalName = s.OriginalName
+	packerBuilder.FieldNameMapper = s.FieldNameMapper
 	return &execBuilder{
-		schema:        s,
-		resMap:        make(map[typePair]*resMapEntry),
-		packerBuilder: packer.NewBuilder(),
+		schema:          s,
+		resMap:          make(map[typePair]*resMapEntry),
+		packerBuilder:   packerBuilder,
+		fieldNameMapper: s.FieldNameMapper,
 	}
 }
 
@@ -153,6 +280,10 @@ func (b *execBuilder) assignExec(target *Resolvable, t common.Type, resolverType
 }
 
 func (b *execBuilder) makeExec(t common.Type, resolverType reflect.Type) (Resolvable, error) {
+	if b.isRawJSON(resolverType) || b.isMarshaler(resolverType) {
+		return nil, fmt.Errorf("%s can only be used as the result of a field", resolverType)
+	}
+
 	var nonNull bool
 	t, nonNull = unwrapNonNull(t)
 
@@ -167,7 +298,8 @@ func (b *execBuilder) makeExec(t common.Type, resolverType reflect.Type) (Resolv
 		return b.makeObjectExec(t.Name, nil, t.PossibleTypes, nonNull, resolverType)
 	}
 
-	if !nonNull {
+	// Nullable lists may be resolved by slices as well as pointers to slices.
+	if !nonNull && !(isList(t) && resolverType.Kind() == reflect.Slice) {
 		if resolverType.Kind() != reflect.Ptr {
 			return nil, fmt.Errorf("%s is not a pointer", resolverType)
 		}
@@ -176,9 +308,18 @@ func (b *execBuilder) makeExec(t common.Type, resolverType reflect.Type) (Resolv
 
 	switch t := t.(type) {
 	case *schema.Scalar:
-		return makeScalarExec(t, resolverType)
+		if b.schema.StrictResolverTypes && !isMarshalable(resolverType) {
+			return nil, fmt.Errorf("%s can not be used as %s, its values can not be marshaled to JSON", resolverType, t.Name)
+		}
+		return makeScalarExec(t, b.schema.OriginalName(t.Name), resolverType, b.schema.Int64Policy)
 
 	case *schema.Enum:
+		if m := b.schema.EnumMappings[t.Name]; m != nil && resolverType == m.Type {
+			return &Scalar{}, nil
+		}
+		if b.schema.StrictResolverTypes && resolverType.Kind() != reflect.String && !resolverType.Implements(stringerType) {
+			return nil, fmt.Errorf("%s can not be used as enum %s, it is neither a string nor a fmt.Stringer", resolverType, t.Name)
+		}
 		return &Scalar{}, nil
 
 	case *common.List:
@@ -196,7 +337,105 @@ func (b *execBuilder) makeExec(t common.Type, resolverType reflect.Type) (Resolv
 	}
 }
 
-func makeScalarExec(t *schema.Scalar, resolverType reflect.Type) (Resolvable, error) {
+// IDMarshaler is implemented by results of ID fields that are neither strings nor integers.
+type IDMarshaler interface {
+	MarshalGraphQLID() string
+}
+
+// IDMarshalerType is the type of IDMarshaler.
+var IDMarshalerType = reflect.TypeOf((*IDMarshaler)(nil)).Elem()
+
+var (
+	int32Type   = reflect.TypeOf(int32(0))
+	float32Type = reflect.TypeOf(float32(0))
+
+	// integerTypes can be used for Int fields in addition to int32.
+	integerTypes = map[reflect.Type]bool{
+		reflect.TypeOf(int(0)):    true,
+		reflect.TypeOf(int8(0)):   true,
+		reflect.TypeOf(int16(0)):  true,
+		reflect.TypeOf(int64(0)):  true,
+		reflect.TypeOf(uint(0)):   true,
+		reflect.TypeOf(uint8(0)):  true,
+		reflect.TypeOf(uint16(0)): true,
+		reflect.TypeOf(uint32(0)): true,
+		reflect.TypeOf(uint64(0)): true,
+	}
+)
+
+var (
+	stringerType      = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// isMarshalable reports whether the values of t can be marshaled with encoding/json.
+func isMarshalable(t reflect.Type) bool {
+	return marshalable(t, make(map[reflect.Type]bool))
+}
+
+func marshalable(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] || t.Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(jsonMarshalerType) {
+		return true
+	}
+	seen[t] = true
+	switch t.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Complex64, reflect.Complex128, reflect.UnsafePointer:
+		return false
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return marshalable(t.Elem(), seen)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if f := t.Field(i); f.PkgPath == "" && !marshalable(f.Type, seen) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// expectedGoType describes the Go type resolving t, e.g. "[][]int32" for "[[Int!]!]!".
+func expectedGoType(t common.Type) string {
+	nonNull := false
+	if nn, ok := t.(*common.NonNull); ok {
+		t, nonNull = nn.OfType, true
+	}
+	var s string
+	switch t := t.(type) {
+	case *common.List:
+		s = "[]" + expectedGoType(t.OfType)
+	case *schema.Scalar:
+		switch t.Name {
+		case "Int":
+			s = "int32"
+		case "Float":
+			s = "float64"
+		case "String":
+			s = "string"
+		case "Boolean":
+			s = "bool"
+		case "ID":
+			s = "graphql.ID"
+		default:
+			s = fmt.Sprintf("<type implementing %s>", t.Name)
+		}
+	case *schema.Enum:
+		s = "string"
+	default:
+		// Resolvers of objects, interfaces and unions are pointers, no matter if they may be null.
+		return fmt.Sprintf("<resolver of %s>", t)
+	}
+	if !nonNull {
+		s = "*" + s
+	}
+	return s
+}
+
+// makeScalarExec checks that the resolver type can be used for the scalar, whose name is passed to
+// custom scalars as it was declared, before the scalar was renamed.
+func makeScalarExec(t *schema.Scalar, name string, resolverType reflect.Type, int64Policy schema.Int64Policy) (Resolvable, error) {
+	if Is64BitInteger(resolverType) && t.Name != "ID" && int64Policy != schema.Int64Default {
+		return makeInt64ScalarExec(t, resolverType, int64Policy)
+	}
 	implementsType := false
 	switch r := reflect.New(resolverType).Interface().(type) {
 	case *int32:
@@ -208,7 +447,17 @@ func makeScalarExec(t *schema.Scalar, resolverType reflect.Type) (Resolvable, er
 	case *bool:
 		implementsType = t.Name == "Boolean"
 	case packer.Unmarshaler:
-		implementsType = r.ImplementsGraphQLType(t.Name)
+		implementsType = r.ImplementsGraphQLType(name)
+	}
+	switch {
+	case t.Name == "Int" && integerTypes[resolverType]:
+		// Results outside of the 32-bit range are rejected when the field is resolved.
+		implementsType = true
+	case t.Name == "ID" && (resolverType.Kind() == reflect.String || resolverType == int32Type || integerTypes[resolverType] || resolverType.Implements(IDMarshalerType)):
+		// IDs are written to the response as strings.
+		implementsType = true
+	case t.Name == "Float" && (resolverType == float32Type || resolverType == int32Type || integerTypes[resolverType]):
+		implementsType = true
 	}
 	if !implementsType {
 		return nil, fmt.Errorf("can not use %s as %s", resolverType, t.Name)
@@ -216,46 +465,68 @@ func makeScalarExec(t *schema.Scalar, resolverType reflect.Type) (Resolvable, er
 	return &Scalar{}, nil
 }
 
+// makeInt64ScalarExec makes the exec of a scalar resolved by a 64-bit integer type according to the
+// policy.
+func makeInt64ScalarExec(t *schema.Scalar, resolverType reflect.Type, policy schema.Int64Policy) (Resolvable, error) {
+	allowed := ""
+	switch policy {
+	case schema.Int64Reject:
+		allowed = "ID"
+	case schema.Int64AsFloat:
+		if t.Name == "Float" {
+			return &Scalar{}, nil
+		}
+		allowed = "Float and ID"
+	case schema.Int64AsString:
+		if t.Name != "Int" && t.Name != "Float" && t.Name != "Boolean" {
+			return &Scalar{}, nil
+		}
+		allowed = "String, ID and custom scalar"
+	case schema.Int64BigInt:
+		if t.Name == "BigInt" {
+			return &Scalar{}, nil
+		}
+		allowed = "BigInt and ID"
+	}
+	return nil, fmt.Errorf("can not use %s as %s, 64-bit integers may only resolve %s fields", resolverType, t.Name, allowed)
+}
+
+// Is64BitInteger reports whether values of the type are 64-bit integers, which lose precision as
+// JSON numbers in JavaScript. Types marshaling themselves to JSON are not.
+func Is64BitInteger(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		return !t.Implements(jsonMarshalerType)
+	}
+	return false
+}
+
 func (b *execBuilder) makeObjectExec(typeName string, fields schema.FieldList, possibleTypes []*schema.Object,
 	nonNull bool, resolverType reflect.Type) (*Object, error) {
+	if resolverType == MergedType {
+		return b.makeMergedObjectExec(typeName, fields)
+	}
+
 	if !nonNull {
 		if resolverType.Kind() != reflect.Ptr && resolverType.Kind() != reflect.Interface {
 			return nil, fmt.Errorf("%s is not a pointer or interface", resolverType)
 		}
 	}
 
-	methodHasReceiver := resolverType.Kind() != reflect.Interface
-
 	Fields := make(map[string]*Field)
-	rt := unwrapPtr(resolverType)
-	fieldsCount := fieldCount(rt, map[string]int{})
 	for _, f := range fields {
-		var fieldIndex []int
-		methodIndex := findMethod(resolverType, f.Name)
-		if b.schema.UseFieldResolvers && methodIndex == -1 {
-			if fieldsCount[strings.ToLower(stripUnderscore(f.Name))] > 1 {
-				return nil, fmt.Errorf("%s does not resolve %q: ambiguous field %q", resolverType, typeName, f.Name)
-			}
-			fieldIndex = findField(rt, f.Name, []int{})
-		}
-		if methodIndex == -1 && len(fieldIndex) == 0 {
-			hint := ""
-			if findMethod(reflect.PtrTo(resolverType), f.Name) != -1 {
-				hint = " (hint: the method exists on the pointer type)"
-			}
-			return nil, fmt.Errorf("%s does not resolve %q: missing method for field %q%s", resolverType, typeName, f.Name, hint)
-		}
-
-		var m reflect.Method
-		var sf reflect.StructField
-		if methodIndex != -1 {
-			m = resolverType.Method(methodIndex)
+		var fe *Field
+		var err error
+		if len(f.Via) != 0 {
+			fe, err = b.makeHoistedFieldExec(typeName, f, resolverType)
 		} else {
-			sf = rt.FieldByIndex(fieldIndex)
+			fe, err = b.makeResolverFieldExec(typeName, f, f.Name, resolverType)
+			if err == nil {
+				fe.Requires, err = b.makeRequiredFieldExecs(typeName, f, fields, resolverType)
+			}
 		}
-		fe, err := b.makeFieldExec(typeName, f, m, sf, methodIndex, fieldIndex, methodHasReceiver)
 		if err != nil {
-			return nil, fmt.Errorf("%s\n\tused by (%s).%s", err, resolverType, m.Name)
+			return nil, err
 		}
 		Fields[f.Name] = fe
 	}
@@ -266,12 +537,23 @@ func (b *execBuilder) makeObjectExec(typeName string, fields schema.FieldList, p
 	typeAssertions := make(map[string]*TypeAssertion)
 	if !b.schema.UseFieldResolvers || resolverType.Kind() != reflect.Interface {
 		for _, impl := range possibleTypes {
-			methodIndex := findMethod(resolverType, "To"+impl.Name)
+			method := "To" + b.schema.OriginalName(impl.Name)
+			methodIndex := findMethod(resolverType, method, nil)
+			if methodIndex == -1 && fields == nil {
+				if fieldIndex := findMemberField(resolverType, b.schema.OriginalName(impl.Name)); fieldIndex != nil {
+					a := &TypeAssertion{MethodIndex: -1, FieldIndex: fieldIndex}
+					if err := b.assignExec(&a.TypeExec, impl, unwrapPtr(resolverType).FieldByIndex(fieldIndex).Type); err != nil {
+						return nil, err
+					}
+					typeAssertions[impl.Name] = a
+					continue
+				}
+			}
 			if methodIndex == -1 {
-				return nil, fmt.Errorf("%s does not resolve %q: missing method %q to convert to %q", resolverType, typeName, "To"+impl.Name, impl.Name)
+				return nil, fmt.Errorf("%s does not resolve %q: missing method %q to convert to %q", resolverType, typeName, method, impl.Name)
 			}
 			if resolverType.Method(methodIndex).Type.NumOut() != 2 {
-				return nil, fmt.Errorf("%s does not resolve %q: method %q should return a value and a bool indicating success", resolverType, typeName, "To"+impl.Name)
+				return nil, fmt.Errorf("%s does not resolve %q: method %q should return a value and a bool indicating success", resolverType, typeName, method)
 			}
 			a := &TypeAssertion{
 				MethodIndex: methodIndex,
@@ -281,15 +563,132 @@ func (b *execBuilder) makeObjectExec(typeName string, fields schema.FieldList, p
 			}
 			typeAssertions[impl.Name] = a
 		}
+		if len(typeAssertions) != 0 {
+			for _, fe := range Fields {
+				fe.TypeAssertions = typeAssertions
+			}
+		}
 	}
 
 	return &Object{
 		Name:           typeName,
 		Fields:         Fields,
 		TypeAssertions: typeAssertions,
+		ResolverType:   resolverType,
 	}, nil
 }
 
+// findMemberField returns the index of the pointer field of a sum-type struct holding the member of
+// a union with the given type name, or nil.
+func findMemberField(resolverType reflect.Type, typeName string) []int {
+	rt := unwrapPtr(resolverType)
+	if rt.Kind() != reflect.Struct {
+		return nil
+	}
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if sf.PkgPath == "" && sf.Type.Kind() == reflect.Ptr && !packer.IgnoredField(sf) && packer.MatchFieldName(nil, sf, typeName) {
+			return []int{i}
+		}
+	}
+	return nil
+}
+
+// makeResolverFieldExec makes the exec of a field resolved by the method or struct field with the
+// given name of the resolver type.
+func (b *execBuilder) makeResolverFieldExec(typeName string, f *schema.Field, name string, resolverType reflect.Type) (*Field, error) {
+	rt := unwrapPtr(resolverType)
+	methodIndex, fieldIndex, err := b.findResolver(typeName, resolverType, name)
+	if err != nil {
+		return nil, err
+	}
+	if methodIndex == -1 && len(fieldIndex) == 0 {
+		return nil, b.missingResolverError(typeName, resolverType, name)
+	}
+
+	var m reflect.Method
+	var sf reflect.StructField
+	if methodIndex != -1 {
+		m = resolverType.Method(methodIndex)
+	} else {
+		sf = rt.FieldByIndex(fieldIndex)
+	}
+	fe, err := b.makeFieldExec(typeName, f, m, sf, methodIndex, fieldIndex, resolverType.Kind() != reflect.Interface)
+	if err != nil {
+		return nil, fmt.Errorf("%s\n\tused by (%s).%s", err, resolverType, m.Name)
+	}
+	return fe, nil
+}
+
+// makeHoistedFieldExec makes the exec of a hoisted field, which is resolved through the fields of
+// its Via starting at the resolver type of the type declaring it.
+func (b *execBuilder) makeHoistedFieldExec(typeName string, f *schema.Field, resolverType reflect.Type) (*Field, error) {
+	var via []*Field
+	viaTypeName := typeName
+	for _, vf := range f.Via {
+		fe, err := b.makeResolverFieldExec(viaTypeName, vf, vf.Name, resolverType)
+		if err != nil {
+			return nil, err
+		}
+		via = append(via, fe)
+		resolverType = fe.ResultType
+		t, _ := unwrapNonNull(vf.Type)
+		viaTypeName = t.String()
+	}
+	fe, err := b.makeResolverFieldExec(viaTypeName, f, f.ResolverName, resolverType)
+	if err != nil {
+		return nil, err
+	}
+	// The dependencies of the field are its siblings in the type it is hoisted from.
+	if nested, ok := b.schema.Types[viaTypeName].(*schema.Object); ok {
+		if fe.Requires, err = b.makeRequiredFieldExecs(viaTypeName, f, nested.Fields, resolverType); err != nil {
+			return nil, err
+		}
+	}
+	fe.Via = via
+	fe.TypeName = typeName
+	fe.TraceLabel = fmt.Sprintf("GraphQL field: %s.%s", typeName, f.Name)
+	return fe, nil
+}
+
+// makeRequiredFieldExecs makes the execs of the sibling fields named by a "@dependsOn(fields:
+// [String!]!)" directive on the field definition. Dependencies can not have arguments or
+// dependencies of their own, and can not be hoisted.
+func (b *execBuilder) makeRequiredFieldExecs(typeName string, f *schema.Field, siblings schema.FieldList, resolverType reflect.Type) ([]*Field, error) {
+	d := f.Directives.Get("dependsOn")
+	if d == nil {
+		return nil, nil
+	}
+	lit, ok := d.Args.Get("fields")
+	if !ok || lit == nil {
+		return nil, fmt.Errorf("directive @dependsOn on field %q requires fields", f.Name)
+	}
+	names, _ := lit.Value(nil).([]interface{})
+	var requires []*Field
+	for _, n := range names {
+		name, _ := n.(string)
+		dep := siblings.Get(name)
+		switch {
+		case dep == nil:
+			return nil, fmt.Errorf("directive @dependsOn on field %q: field %q is not defined on type %q", f.Name, name, typeName)
+		case dep.Name == f.Name:
+			return nil, fmt.Errorf("directive @dependsOn on field %q: field can not depend on itself", f.Name)
+		case len(dep.Args) != 0:
+			return nil, fmt.Errorf("directive @dependsOn on field %q: field %q has arguments", f.Name, name)
+		case dep.Directives.Get("dependsOn") != nil:
+			return nil, fmt.Errorf("directive @dependsOn on field %q: field %q has dependencies itself", f.Name, name)
+		case len(dep.Via) != 0:
+			return nil, fmt.Errorf("directive @dependsOn on field %q: field %q is hoisted", f.Name, name)
+		}
+		fe, err := b.makeResolverFieldExec(typeName, dep, dep.Name, resolverType)
+		if err != nil {
+			return nil, err
+		}
+		requires = append(requires, fe)
+	}
+	return requires, nil
+}
+
 var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
 var errorType = reflect.TypeOf((*error)(nil)).Elem()
 
@@ -348,6 +747,42 @@ func (b *execBuilder) makeFieldExec(typeName string, f *schema.Field, m reflect.
 		}
 	}
 
+	deadline, err := fieldDeadline(f)
+	if err != nil {
+		return nil, err
+	}
+	retry, err := b.fieldRetry(typeName, f)
+	if err != nil {
+		return nil, err
+	}
+	redaction, err := fieldRedaction(f)
+	if err != nil {
+		return nil, err
+	}
+	onError, err := fieldErrorAction(f)
+	if err != nil {
+		return nil, err
+	}
+	listConcurrency, err := fieldListConcurrency(f)
+	if err != nil {
+		return nil, err
+	}
+	maxListLength, err := fieldMaxListLength(f)
+	if err != nil {
+		return nil, err
+	}
+	nilSliceAsNull, err := b.fieldNilSliceAsNull(f, sf)
+	if err != nil {
+		return nil, err
+	}
+	var execType common.Type
+	if f.Directives.Get("semanticNonNull") != nil {
+		execType, err = schema.SemanticNonNullType(f, func(t common.Type) common.Type { return &SemanticNonNull{OfType: t} })
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	fe := &Field{
 		Field:       *f,
 		TypeName:    typeName,
@@ -357,46 +792,346 @@ func (b *execBuilder) makeFieldExec(typeName string, f *schema.Field, m reflect.
 		ArgsPacker:  argsPacker,
 		HasError:    hasError,
 		TraceLabel:  fmt.Sprintf("GraphQL field: %s.%s", typeName, f.Name),
+		Serial:      f.Directives.Get("serial") != nil,
+		Deadline:    deadline,
+		Retry:       retry,
+		Redaction:   redaction,
+		OnError:     onError,
+		ExecType:    execType,
+
+		ListConcurrency: listConcurrency,
+		MaxListLength:   maxListLength,
+		NilSliceAsNull:  nilSliceAsNull,
 	}
 
 	var out reflect.Type
+	subscription := false
 	if methodIndex != -1 {
 		out = m.Type.Out(0)
 		sub, ok := b.schema.EntryPoints["subscription"]
 		if ok && typeName == sub.TypeName() && out.Kind() == reflect.Chan {
 			out = m.Type.Out(0).Elem()
+			subscription = true
 		}
 	} else {
 		out = sf.Type
 	}
+	fe.ResultType = out
+	// Subscription fields are resolved once per subscription and are not wrapped.
+	if b.schema.WrapResolver != nil && !subscription && !strings.HasPrefix(typeName, "__") {
+		fe.Wrapper = b.schema.WrapResolver(typeName, f.Name)
+	}
+	if b.isRawJSON(out) {
+		fe.ValueExec = &RawJSON{}
+		return fe, nil
+	}
+	if b.isMarshaler(out) {
+		fe.ValueExec = &Marshaler{}
+		return fe, nil
+	}
 	if err := b.assignExec(&fe.ValueExec, f.Type, out); err != nil {
+		if b.schema.StrictResolverTypes {
+			return nil, fmt.Errorf("result type %s does not match %s, expected %s: %s", out, f.Type, expectedGoType(f.Type), err)
+		}
 		return nil, err
 	}
 
 	return fe, nil
 }
 
-func findMethod(t reflect.Type, name string) int {
+func (b *execBuilder) isRawJSON(t reflect.Type) bool {
+	for _, raw := range b.schema.RawJSONTypes {
+		if t == raw {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *execBuilder) isMarshaler(t reflect.Type) bool {
+	return b.schema.MarshalerType != nil && t.Implements(b.schema.MarshalerType)
+}
+
+// fieldDeadline reads the timeout of a "@deadline(timeout: String!)" directive on the field
+// definition, e.g. `@deadline(timeout: "250ms")`.
+func fieldDeadline(f *schema.Field) (time.Duration, error) {
+	d := f.Directives.Get("deadline")
+	if d == nil {
+		return 0, nil
+	}
+	lit, ok := d.Args.Get("timeout")
+	if !ok || lit == nil {
+		return 0, fmt.Errorf("directive @deadline on field %q requires a timeout", f.Name)
+	}
+	v, ok := lit.Value(nil).(string)
+	if !ok {
+		return 0, fmt.Errorf("directive @deadline on field %q: timeout must be a string", f.Name)
+	}
+	timeout, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("directive @deadline on field %q: %s", f.Name, err)
+	}
+	if timeout <= 0 {
+		return 0, fmt.Errorf("directive @deadline on field %q: timeout must be positive", f.Name)
+	}
+	return timeout, nil
+}
+
+// fieldRetry returns the retry policy of the field, which is either set by graphql.RetryField or
+// read from a "@retry(attempts: Int!, backoff: String)" directive on the field definition, e.g.
+// `@retry(attempts: 3, backoff: "100ms")`. The backoff of the directive doubles with every retry.
+func (b *execBuilder) fieldRetry(typeName string, f *schema.Field) (*schema.RetryPolicy, error) {
+	if p, ok := b.schema.RetryPolicies[typeName+"."+f.Name]; ok {
+		return p, nil
+	}
+	d := f.Directives.Get("retry")
+	if d == nil {
+		return nil, nil
+	}
+	lit, ok := d.Args.Get("attempts")
+	if !ok || lit == nil {
+		return nil, fmt.Errorf("directive @retry on field %q requires attempts", f.Name)
+	}
+	attempts, ok := lit.Value(nil).(int32)
+	if !ok || attempts <= 0 {
+		return nil, fmt.Errorf("directive @retry on field %q: attempts must be a positive integer", f.Name)
+	}
+	p := &schema.RetryPolicy{MaxAttempts: int(attempts)}
+	if lit, ok := d.Args.Get("backoff"); ok && lit != nil {
+		v, ok := lit.Value(nil).(string)
+		if !ok {
+			return nil, fmt.Errorf("directive @retry on field %q: backoff must be a string", f.Name)
+		}
+		backoff, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("directive @retry on field %q: %s", f.Name, err)
+		}
+		if backoff < 0 {
+			return nil, fmt.Errorf("directive @retry on field %q: backoff must not be negative", f.Name)
+		}
+		p.Backoff = func(retry int) time.Duration {
+			return backoff << uint(retry-1)
+		}
+	}
+	return p, nil
+}
+
+// fieldListConcurrency reads the maximum of a "@listConcurrency(max: Int!)" directive on the field
+// definition, which must be a list field.
+func fieldListConcurrency(f *schema.Field) (int, error) {
+	d := f.Directives.Get("listConcurrency")
+	if d == nil {
+		return 0, nil
+	}
+	if t, _ := unwrapNonNull(f.Type); !isList(t) {
+		return 0, fmt.Errorf("directive @listConcurrency on field %q: field must be a list", f.Name)
+	}
+	lit, ok := d.Args.Get("max")
+	if !ok || lit == nil {
+		return 0, fmt.Errorf("directive @listConcurrency on field %q requires a max", f.Name)
+	}
+	max, ok := lit.Value(nil).(int32)
+	if !ok || max <= 0 {
+		return 0, fmt.Errorf("directive @listConcurrency on field %q: max must be a positive integer", f.Name)
+	}
+	return int(max), nil
+}
+
+// fieldMaxListLength reads the maximum of a "@maxListLength(max: Int!)" directive on the field
+// definition, if any.
+func fieldMaxListLength(f *schema.Field) (int, error) {
+	d := f.Directives.Get("maxListLength")
+	if d == nil {
+		return 0, nil
+	}
+	if t, _ := unwrapNonNull(f.Type); !isList(t) {
+		return 0, fmt.Errorf("directive @maxListLength on field %q: field must be a list", f.Name)
+	}
+	lit, ok := d.Args.Get("max")
+	if !ok || lit == nil {
+		return 0, fmt.Errorf("directive @maxListLength on field %q requires a max", f.Name)
+	}
+	max, ok := lit.Value(nil).(int32)
+	if !ok || max <= 0 {
+		return 0, fmt.Errorf("directive @maxListLength on field %q: max must be a positive integer", f.Name)
+	}
+	return int(max), nil
+}
+
+// fieldNilSliceAsNull reads whether a nil slice resolved for the field is written as null from a
+// "@nilSlice(as: NilSlice!)" directive on the field definition or the "graphql" tag of the struct
+// field resolving it, if any.
+func (b *execBuilder) fieldNilSliceAsNull(f *schema.Field, sf reflect.StructField) (bool, error) {
+	t, nonNull := unwrapNonNull(f.Type)
+	nullableList := !nonNull && isList(t)
+	if d := f.Directives.Get("nilSlice"); d != nil {
+		if !nullableList {
+			return false, fmt.Errorf("directive @nilSlice on field %q: field must be a nullable list", f.Name)
+		}
+		lit, ok := d.Args.Get("as")
+		if !ok || lit == nil {
+			return false, fmt.Errorf("directive @nilSlice on field %q requires an as argument", f.Name)
+		}
+		switch as, _ := lit.Value(nil).(string); as {
+		case "NULL":
+			return true, nil
+		case "EMPTY":
+			return false, nil
+		default:
+			return false, fmt.Errorf("directive @nilSlice on field %q: invalid value %s", f.Name, lit)
+		}
+	}
+	switch {
+	case !nullableList:
+		return false, nil
+	case packer.HasTagOption(sf, "nilnull"):
+		return true, nil
+	case packer.HasTagOption(sf, "nilempty"):
+		return false, nil
+	}
+	return b.schema.NilSlicesAsNull, nil
+}
+
+// fieldErrorAction reads an "@onError(action: OnErrorAction!)" directive on the field definition,
+// whose argument is one of the enum values NULL, PROPAGATE and DROP.
+func fieldErrorAction(f *schema.Field) (ErrorAction, error) {
+	d := f.Directives.Get("onError")
+	if d == nil {
+		return OnErrorDefault, nil
+	}
+	lit, ok := d.Args.Get("action")
+	if !ok || lit == nil {
+		return 0, fmt.Errorf("directive @onError on field %q requires an action", f.Name)
+	}
+	switch action, _ := lit.Value(nil).(string); action {
+	case "NULL":
+		return OnErrorNull, nil
+	case "PROPAGATE":
+		return OnErrorPropagate, nil
+	case "DROP":
+		return OnErrorDrop, nil
+	default:
+		return 0, fmt.Errorf("directive @onError on field %q: invalid action %s", f.Name, lit)
+	}
+}
+
+// fieldRedaction reads a "@redact(when: String!, placeholder: String)" directive on the field
+// definition. A placeholder is only supported on String and ID fields and is required if the field
+// is non-null.
+func fieldRedaction(f *schema.Field) (*Redaction, error) {
+	d := f.Directives.Get("redact")
+	if d == nil {
+		return nil, nil
+	}
+	lit, ok := d.Args.Get("when")
+	if !ok || lit == nil {
+		return nil, fmt.Errorf("directive @redact on field %q requires a predicate", f.Name)
+	}
+	when, ok := lit.Value(nil).(string)
+	if !ok {
+		return nil, fmt.Errorf("directive @redact on field %q: when must be a string", f.Name)
+	}
+	r := &Redaction{When: when}
+
+	t, nonNull := f.Type, false
+	if nn, ok := t.(*common.NonNull); ok {
+		t, nonNull = nn.OfType, true
+	}
+	if lit, ok := d.Args.Get("placeholder"); ok && lit != nil {
+		placeholder, ok := lit.Value(nil).(string)
+		if !ok {
+			return nil, fmt.Errorf("directive @redact on field %q: placeholder must be a string", f.Name)
+		}
+		if name := t.String(); name != "String" && name != "ID" {
+			return nil, fmt.Errorf("directive @redact on field %q: a placeholder is only supported on String and ID fields", f.Name)
+		}
+		r.Placeholder = reflect.ValueOf(placeholder)
+	} else if nonNull {
+		return nil, fmt.Errorf("directive @redact on non-null field %q requires a placeholder", f.Name)
+	}
+	return r, nil
+}
+
+// findMethod returns the index of the method binding the GraphQL name, see
+// schema.Schema.FieldNameMapper, or -1.
+func findMethod(t reflect.Type, name string, mapper func(goName string) string) int {
 	for i := 0; i < t.NumMethod(); i++ {
-		if strings.EqualFold(stripUnderscore(name), stripUnderscore(t.Method(i).Name)) {
+		if packer.MatchName(mapper, t.Method(i).Name, name) {
 			return i
 		}
 	}
 	return -1
 }
 
-func findField(t reflect.Type, name string, index []int) []int {
+var methodResolversType = reflect.TypeOf((*interface{ PreferMethodResolvers() })(nil)).Elem()
+
+// findResolver returns the index of the method or of the struct field of the resolver type
+// resolving the field with the given name, or -1 and nil. Struct fields are only considered with
+// UseFieldResolvers, and take precedence over methods with PreferFieldResolvers unless the resolver
+// type implements PreferMethodResolvers.
+func (b *execBuilder) findResolver(typeName string, resolverType reflect.Type, name string) (int, []int, error) {
+	mapper := b.fieldNameMapper
+	rt := unwrapPtr(resolverType)
+	methodIndex := findMethod(resolverType, name, mapper)
+	if !b.schema.UseFieldResolvers || rt.Kind() != reflect.Struct {
+		return methodIndex, nil, nil
+	}
+	preferFields := b.schema.PreferFieldResolvers && !resolverType.Implements(methodResolversType)
+	if methodIndex != -1 && !preferFields {
+		return methodIndex, nil, nil
+	}
+	fieldIndex := findField(rt, name, []int{}, mapper)
+	if len(fieldIndex) == 0 {
+		return methodIndex, nil, nil
+	}
+	if fieldCount(rt, map[string]int{}, mapper)[strings.ToLower(stripUnderscore(name))] > 1 {
+		return -1, nil, fmt.Errorf("%s does not resolve %q: ambiguous field %q", resolverType, typeName, name)
+	}
+	return -1, fieldIndex, nil
+}
+
+// missingResolverError returns the error of a field not resolved by the resolver type, with a hint
+// at the likely cause.
+func (b *execBuilder) missingResolverError(typeName string, resolverType reflect.Type, name string) error {
+	mapper := b.fieldNameMapper
+	rt := unwrapPtr(resolverType)
+	missing := "method"
+	if b.schema.UseFieldResolvers && rt.Kind() == reflect.Struct {
+		missing = "method or struct field"
+	}
+	hint := ""
+	switch {
+	case findMethod(reflect.PtrTo(resolverType), name, mapper) != -1:
+		hint = " (hint: the method exists on the pointer type)"
+	case rt.Kind() == reflect.Struct:
+		for i := 0; i < rt.NumField(); i++ {
+			sf := rt.Field(i)
+			if sf.PkgPath != "" || !packer.MatchName(mapper, sf.Name, name) {
+				continue
+			}
+			if packer.IgnoredField(sf) {
+				hint = fmt.Sprintf(" (hint: struct field %s is excluded by its graphql tag)", sf.Name)
+			} else if !b.schema.UseFieldResolvers {
+				hint = fmt.Sprintf(" (hint: struct field %s resolves it with UseFieldResolvers)", sf.Name)
+			}
+			break
+		}
+	}
+	return fmt.Errorf("%s does not resolve %q: missing %s for field %q%s", resolverType, typeName, missing, name, hint)
+}
+
+func findField(t reflect.Type, name string, index []int, mapper func(goName string) string) []int {
 	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
 
 		if field.Type.Kind() == reflect.Struct && field.Anonymous {
-			newIndex := findField(field.Type, name, []int{i})
+			newIndex := findField(field.Type, name, []int{i}, mapper)
 			if len(newIndex) > 1 {
 				return append(index, newIndex...)
 			}
 		}
 
-		if strings.EqualFold(stripUnderscore(name), stripUnderscore(field.Name)) {
+		if !packer.IgnoredField(field) && packer.MatchFieldName(mapper, field, name) {
 			return append(index, i)
 		}
 	}
@@ -405,18 +1140,18 @@ func findField(t reflect.Type, name string, index []int) []int {
 }
 
 // fieldCount helps resolve ambiguity when more than one embedded struct contains fields with the same name.
-func fieldCount(t reflect.Type, count map[string]int) map[string]int {
+func fieldCount(t reflect.Type, count map[string]int, mapper func(goName string) string) map[string]int {
 	if t.Kind() != reflect.Struct {
 		return nil
 	}
 
 	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
-		fieldName := strings.ToLower(stripUnderscore(field.Name))
+		fieldName := fieldKey(field, mapper)
 
 		if field.Type.Kind() == reflect.Struct && field.Anonymous {
-			count = fieldCount(field.Type, count)
-		} else {
+			count = fieldCount(field.Type, count, mapper)
+		} else if !packer.IgnoredField(field) {
 			if _, ok := count[fieldName]; !ok {
 				count[fieldName] = 0
 			}
@@ -427,6 +1162,20 @@ func fieldCount(t reflect.Type, count map[string]int) map[string]int {
 	return count
 }
 
+// fieldKey returns the key of the GraphQL name bound by the struct field in the counts of
+// fieldCount.
+func fieldKey(field reflect.StructField, mapper func(goName string) string) string {
+	if name := packer.FieldName(field); name != field.Name || mapper == nil {
+		return strings.ToLower(stripUnderscore(name))
+	}
+	return strings.ToLower(stripUnderscore(mapper(field.Name)))
+}
+
+func isList(t common.Type) bool {
+	_, ok := t.(*common.List)
+	return ok
+}
+
 func unwrapNonNull(t common.Type) (common.Type, bool) {
 	if nn, ok := t.(*common.NonNull); ok {
 		return nn.OfType, true
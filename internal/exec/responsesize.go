@@ -0,0 +1,39 @@
+package exec
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/graph-gophers/graphql-go/errors"
+)
+
+// responseSize counts the bytes of the values and keys written to the response of a request with
+// MaxResponseBytes.
+type responseSize struct {
+	written  int64
+	exceeded int32
+	cancel   context.CancelFunc
+}
+
+// countBytes adds n written bytes to the response size. Once the size exceeds MaxResponseBytes, the
+// execution is cancelled, so no further resolvers are called.
+func (r *Request) countBytes(n int) {
+	if r.size == nil {
+		return
+	}
+	if atomic.AddInt64(&r.size.written, int64(n)) > r.MaxResponseBytes && atomic.CompareAndSwapInt32(&r.size.exceeded, 0, 1) {
+		r.size.cancel()
+	}
+}
+
+// responseTooLarge reports whether the response exceeded MaxResponseBytes. The remaining values are
+// not written then, as the response is discarded.
+func (r *Request) responseTooLarge() bool {
+	return r.size != nil && atomic.LoadInt32(&r.size.exceeded) == 1
+}
+
+func makeResponseTooLargeError(limit int64) *errors.QueryError {
+	err := errors.Errorf("response exceeds the limit of %d bytes", limit)
+	err.Extensions = map[string]interface{}{"code": "RESPONSE_TOO_LARGE", "limit": limit}
+	return err
+}
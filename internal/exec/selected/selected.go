@@ -1,6 +1,7 @@
 package selected
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"sync"
@@ -21,6 +22,10 @@ type Request struct {
 	Mu                   sync.Mutex
 	Errs                 []*errors.QueryError
 	DisableIntrospection bool
+
+	// ctx is the context of the operation, which is passed to the input validators called when
+	// the arguments are unpacked.
+	ctx context.Context
 }
 
 func (r *Request) AddError(err *errors.QueryError) {
@@ -29,7 +34,8 @@ func (r *Request) AddError(err *errors.QueryError) {
 	r.Mu.Unlock()
 }
 
-func ApplyOperation(r *Request, s *resolvable.Schema, op *query.Operation) []Selection {
+func ApplyOperation(ctx context.Context, r *Request, s *resolvable.Schema, op *query.Operation) []Selection {
+	r.ctx = ctx
 	var obj *resolvable.Object
 	switch op.Type {
 	case query.Query:
@@ -54,6 +60,12 @@ type SchemaField struct {
 	Sels        []Selection
 	Async       bool
 	FixedResult reflect.Value
+
+	// Loc is the location of the field in the query, which is reported with its errors.
+	Loc errors.Location
+
+	// Directives are those applied to the field in the query.
+	Directives common.DirectiveList
 }
 
 type TypeAssertion struct {
@@ -66,9 +78,30 @@ type TypenameField struct {
 	Alias string
 }
 
+// SkippedField is a field of the query that is skipped by a @skip or @include directive, either
+// directly or by a fragment it belongs to. It is not executed, but reported by the lookahead API
+// and to tracers.
+type SkippedField struct {
+	// TypeName is the name of the type the field is selected on. It is empty for the fields
+	// selected on a skipped field.
+	TypeName string
+	Name     string
+	Alias    string
+
+	// Args are the arguments given in the query, with variables replaced by their values.
+	Args map[string]interface{}
+
+	// TypeCondition is the type condition of the skipped fragment the field belongs to, if any.
+	TypeCondition string
+
+	// Fields are the fields selected on the field.
+	Fields []*SkippedField
+}
+
 func (*SchemaField) isSelection()   {}
 func (*TypeAssertion) isSelection() {}
 func (*TypenameField) isSelection() {}
+func (*SkippedField) isSelection()  {}
 
 func applySelectionSet(r *Request, s *resolvable.Schema, e *resolvable.Object, sels []query.Selection) (flattenedSels []Selection) {
 	for _, sel := range sels {
@@ -76,6 +109,7 @@ func applySelectionSet(r *Request, s *resolvable.Schema, e *resolvable.Object, s
 		case *query.Field:
 			field := sel
 			if skipByDirective(r, field.Directives) {
+				flattenedSels = append(flattenedSels, skippedField(r, e.Name, "", field))
 				continue
 			}
 
@@ -93,6 +127,7 @@ func applySelectionSet(r *Request, s *resolvable.Schema, e *resolvable.Object, s
 					flattenedSels = append(flattenedSels, &SchemaField{
 						Field:       s.Meta.FieldSchema,
 						Alias:       field.Alias.Name,
+						Loc:         field.Alias.Loc,
 						Sels:        applySelectionSet(r, s, s.Meta.Schema, field.Selections),
 						Async:       true,
 						FixedResult: reflect.ValueOf(introspection.WrapSchema(r.Schema)),
@@ -102,7 +137,7 @@ func applySelectionSet(r *Request, s *resolvable.Schema, e *resolvable.Object, s
 			case "__type":
 				if !r.DisableIntrospection {
 					p := packer.ValuePacker{ValueType: reflect.TypeOf("")}
-					v, err := p.Pack(field.Arguments.MustGet("name").Value(r.Vars))
+					v, err := p.Pack(r.ctx, field.Arguments.MustGet("name").Value(r.Vars))
 					if err != nil {
 						r.AddError(errors.Errorf("%s", err))
 						return nil
@@ -116,6 +151,7 @@ func applySelectionSet(r *Request, s *resolvable.Schema, e *resolvable.Object, s
 					flattenedSels = append(flattenedSels, &SchemaField{
 						Field:       s.Meta.FieldType,
 						Alias:       field.Alias.Name,
+						Loc:         field.Alias.Loc,
 						Sels:        applySelectionSet(r, s, s.Meta.Type, field.Selections),
 						Async:       true,
 						FixedResult: reflect.ValueOf(introspection.WrapType(t)),
@@ -132,28 +168,39 @@ func applySelectionSet(r *Request, s *resolvable.Schema, e *resolvable.Object, s
 					for _, arg := range field.Arguments {
 						args[arg.Name.Name] = arg.Value.Value(r.Vars)
 					}
+					if p := r.Schema.Pagination; p != nil && p.Cap {
+						capPageSize(&fe.Field, args, p)
+					}
 					var err error
-					packedArgs, err = fe.ArgsPacker.Pack(args)
+					packedArgs, err = fe.ArgsPacker.Pack(r.ctx, args)
 					if err != nil {
-						r.AddError(errors.Errorf("%s", err))
+						r.AddError(argumentError(field, err))
 						return
 					}
 				}
 
-				fieldSels := applyField(r, s, fe.ValueExec, field.Selections)
+				var fieldSels []Selection
+				if _, ok := fe.ValueExec.(*resolvable.Marshaler); ok {
+					fieldSels = applyUnresolvedSelectionSet(r, fe.Type, field.Selections)
+				} else {
+					fieldSels = applyField(r, s, fe.ValueExec, field.Selections)
+				}
 				flattenedSels = append(flattenedSels, &SchemaField{
 					Field:      *fe,
 					Alias:      field.Alias.Name,
+					Loc:        field.Alias.Loc,
 					Args:       args,
 					PackedArgs: packedArgs,
 					Sels:       fieldSels,
-					Async:      fe.HasContext || fe.ArgsPacker != nil || fe.HasError || HasAsyncSel(fieldSels),
+					Async:      fe.HasContext || fe.ArgsPacker != nil || fe.HasError || fe.Wrapper != nil || len(fe.Via) != 0 || HasAsyncSel(fieldSels),
+					Directives: field.Directives,
 				})
 			}
 
 		case *query.InlineFragment:
 			frag := sel
 			if skipByDirective(r, frag.Directives) {
+				flattenedSels = append(flattenedSels, skippedSelections(r, e.Name, frag.On.Name, frag.Selections)...)
 				continue
 			}
 			flattenedSels = append(flattenedSels, applyFragment(r, s, e, &frag.Fragment)...)
@@ -161,6 +208,8 @@ func applySelectionSet(r *Request, s *resolvable.Schema, e *resolvable.Object, s
 		case *query.FragmentSpread:
 			spread := sel
 			if skipByDirective(r, spread.Directives) {
+				frag := r.Doc.Fragments.Get(spread.Name.Name)
+				flattenedSels = append(flattenedSels, skippedSelections(r, e.Name, frag.On.Name, frag.Selections)...)
 				continue
 			}
 			flattenedSels = append(flattenedSels, applyFragment(r, s, e, &r.Doc.Fragments.Get(spread.Name.Name).Fragment)...)
@@ -216,17 +265,147 @@ func applyField(r *Request, s *resolvable.Schema, e resolvable.Resolvable, sels
 		return applySelectionSet(r, s, e, sels)
 	case *resolvable.List:
 		return applyField(r, s, e.Elem, sels)
-	case *resolvable.Scalar:
+	case *resolvable.Scalar, *resolvable.RawJSON, *resolvable.Marshaler:
 		return nil
 	default:
 		panic("unreachable")
 	}
 }
 
+// applyUnresolvedSelectionSet applies the selections to a type without resolvers, for values
+// that serialize themselves. The selected fields are only described, they can not be executed.
+func applyUnresolvedSelectionSet(r *Request, t common.Type, sels []query.Selection) (flattenedSels []Selection) {
+	for {
+		switch u := t.(type) {
+		case *common.NonNull:
+			t = u.OfType
+			continue
+		case *common.List:
+			t = u.OfType
+			continue
+		}
+		break
+	}
+
+	var fields schema.FieldList
+	switch t := t.(type) {
+	case *schema.Object:
+		fields = t.Fields
+	case *schema.Interface:
+		fields = t.Fields
+	case *schema.Union:
+	default:
+		return nil
+	}
+
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *query.Field:
+			if skipByDirective(r, sel.Directives) {
+				flattenedSels = append(flattenedSels, skippedField(r, t.String(), "", sel))
+				continue
+			}
+			if sel.Name.Name == "__typename" {
+				flattenedSels = append(flattenedSels, &TypenameField{
+					Object: resolvable.Object{Name: t.String()},
+					Alias:  sel.Alias.Name,
+				})
+				continue
+			}
+			f := fields.Get(sel.Name.Name)
+			if f == nil {
+				continue
+			}
+			var args map[string]interface{}
+			if len(sel.Arguments) != 0 {
+				args = make(map[string]interface{})
+				for _, arg := range sel.Arguments {
+					args[arg.Name.Name] = arg.Value.Value(r.Vars)
+				}
+			}
+			flattenedSels = append(flattenedSels, &SchemaField{
+				Field: resolvable.Field{Field: *f, TypeName: t.String()},
+				Alias: sel.Alias.Name,
+				Args:  args,
+				Sels:  applyUnresolvedSelectionSet(r, f.Type, sel.Selections),
+			})
+
+		case *query.InlineFragment:
+			if skipByDirective(r, sel.Directives) {
+				flattenedSels = append(flattenedSels, skippedSelections(r, t.String(), sel.On.Name, sel.Selections)...)
+				continue
+			}
+			flattenedSels = append(flattenedSels, applyUnresolvedFragment(r, t, &sel.Fragment)...)
+
+		case *query.FragmentSpread:
+			frag := r.Doc.Fragments.Get(sel.Name.Name)
+			if skipByDirective(r, sel.Directives) {
+				flattenedSels = append(flattenedSels, skippedSelections(r, t.String(), frag.On.Name, frag.Selections)...)
+				continue
+			}
+			flattenedSels = append(flattenedSels, applyUnresolvedFragment(r, t, &frag.Fragment)...)
+		}
+	}
+	return
+}
+
+func applyUnresolvedFragment(r *Request, t common.Type, frag *query.Fragment) []Selection {
+	on := r.Schema.Resolve(frag.On.Name)
+	if obj, ok := on.(*schema.Object); ok && frag.On.Name != t.String() {
+		// A fragment on an object type narrows the selection of an interface or union.
+		return []Selection{&TypeAssertion{
+			TypeAssertion: resolvable.TypeAssertion{TypeExec: &resolvable.Object{Name: obj.Name}},
+			Sels:          applyUnresolvedSelectionSet(r, obj, frag.Selections),
+		}}
+	}
+	return applyUnresolvedSelectionSet(r, t, frag.Selections)
+}
+
+// argumentError reports an error packing the arguments of the field at the location of the value
+// of the failed argument.
+//
+// Violations of input validation directives and errors of input validators name the invalid value
+// by its JSON Pointer within the arguments, e.g. "/input/items/3/price", which is also the
+// "pointer" extension of the error.
+func argumentError(field *query.Field, err error) *errors.QueryError {
+	qErr := errors.Errorf("%s", err)
+	fe, ok := err.(*packer.FieldError)
+	if !ok {
+		return qErr
+	}
+	if lit, ok := field.Arguments.Get(fe.Name); ok {
+		qErr.Locations = []errors.Location{lit.Location()}
+	}
+	switch err := fe.Err.(type) {
+	case *packer.ConstraintError:
+		invalidArgument(qErr, fe)
+		qErr.Extensions["directive"] = err.Directive
+	case *packer.ValidatorError:
+		invalidArgument(qErr, fe)
+		qErr.ResolverError = err.Err
+	}
+	return qErr
+}
+
+// invalidArgument reports a value rejected by an input validation directive or an input validator
+// with the JSON Pointer of the value.
+func invalidArgument(qErr *errors.QueryError, fe *packer.FieldError) {
+	pointer := common.JSONPointer(fe.Path...)
+	subject := fmt.Sprintf("Argument %q", fe.Name)
+	if len(fe.Path) > 1 {
+		subject += fmt.Sprintf(" at %q", pointer)
+	}
+	qErr.Message = fmt.Sprintf("%s has invalid value: %s", subject, fe.Err)
+	qErr.Extensions = map[string]interface{}{
+		"code":    "INVALID_ARGUMENT",
+		"pointer": pointer,
+	}
+}
+
 func skipByDirective(r *Request, directives common.DirectiveList) bool {
 	if d := directives.Get("skip"); d != nil {
 		p := packer.ValuePacker{ValueType: reflect.TypeOf(false)}
-		v, err := p.Pack(d.Args.MustGet("if").Value(r.Vars))
+		v, err := p.Pack(r.ctx, d.Args.MustGet("if").Value(r.Vars))
 		if err != nil {
 			r.AddError(errors.Errorf("%s", err))
 		}
@@ -237,7 +416,7 @@ func skipByDirective(r *Request, directives common.DirectiveList) bool {
 
 	if d := directives.Get("include"); d != nil {
 		p := packer.ValuePacker{ValueType: reflect.TypeOf(false)}
-		v, err := p.Pack(d.Args.MustGet("if").Value(r.Vars))
+		v, err := p.Pack(r.ctx, d.Args.MustGet("if").Value(r.Vars))
 		if err != nil {
 			r.AddError(errors.Errorf("%s", err))
 		}
@@ -249,6 +428,55 @@ func skipByDirective(r *Request, directives common.DirectiveList) bool {
 	return false
 }
 
+func skippedSelections(r *Request, typeName, typeCondition string, sels []query.Selection) []Selection {
+	fields := skippedFields(r, typeName, typeCondition, sels)
+	result := make([]Selection, len(fields))
+	for i, f := range fields {
+		result[i] = f
+	}
+	return result
+}
+
+// skippedFields returns the fields of a skipped selection set. The fields of fragments are
+// flattened and marked with the type condition of the fragment.
+func skippedFields(r *Request, typeName, typeCondition string, sels []query.Selection) []*SkippedField {
+	var fields []*SkippedField
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *query.Field:
+			fields = append(fields, skippedField(r, typeName, typeCondition, sel))
+		case *query.InlineFragment:
+			condition := typeCondition
+			if sel.On.Name != "" {
+				condition = sel.On.Name
+			}
+			fields = append(fields, skippedFields(r, typeName, condition, sel.Selections)...)
+		case *query.FragmentSpread:
+			frag := r.Doc.Fragments.Get(sel.Name.Name)
+			fields = append(fields, skippedFields(r, typeName, frag.On.Name, frag.Selections)...)
+		}
+	}
+	return fields
+}
+
+func skippedField(r *Request, typeName, typeCondition string, field *query.Field) *SkippedField {
+	var args map[string]interface{}
+	if len(field.Arguments) != 0 {
+		args = make(map[string]interface{}, len(field.Arguments))
+		for _, arg := range field.Arguments {
+			args[arg.Name.Name] = arg.Value.Value(r.Vars)
+		}
+	}
+	return &SkippedField{
+		TypeName:      typeName,
+		Name:          field.Name.Name,
+		Alias:         field.Alias.Name,
+		Args:          args,
+		TypeCondition: typeCondition,
+		Fields:        skippedFields(r, "", "", field.Selections),
+	}
+}
+
 func HasAsyncSel(sels []Selection) bool {
 	for _, sel := range sels {
 		switch sel := sel.(type) {
@@ -260,7 +488,7 @@ func HasAsyncSel(sels []Selection) bool {
 			if HasAsyncSel(sel.Sels) {
 				return true
 			}
-		case *TypenameField:
+		case *TypenameField, *SkippedField:
 			// sync
 		default:
 			panic("unreachable")
@@ -268,3 +496,56 @@ func HasAsyncSel(sels []Selection) bool {
 	}
 	return false
 }
+
+// capPageSize caps the values of the pagination arguments of a list field to the maximum page size.
+// If none of them is given, the first one declared by the field is set to the maximum.
+func capPageSize(f *schema.Field, args map[string]interface{}, p *schema.Pagination) {
+	t := f.Type
+	if nn, ok := t.(*common.NonNull); ok {
+		t = nn.OfType
+	}
+	if _, ok := t.(*common.List); !ok {
+		return
+	}
+
+	max := int32(p.MaxPageSize)
+	first := ""
+	paginated := false
+	for _, name := range p.Arguments {
+		decl := f.Args.Get(name)
+		if decl == nil {
+			continue
+		}
+		if first == "" {
+			first = name
+		}
+		value, ok := args[name]
+		if !ok && decl.Default != nil {
+			value = decl.Default.Value(nil)
+		}
+		if value == nil {
+			continue
+		}
+		paginated = true
+		if pageSize(value) > float64(max) {
+			args[name] = max
+		}
+	}
+	if !paginated && first != "" {
+		args[first] = max
+	}
+}
+
+func pageSize(v interface{}) float64 {
+	switch v := v.(type) {
+	case int32:
+		return float64(v)
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	}
+	return 0
+}
@@ -27,7 +27,7 @@ func (r *Request) Subscribe(ctx context.Context, s *resolvable.Schema, op *query
 	func() {
 		defer r.handlePanic(ctx)
 
-		sels := selected.ApplyOperation(&r.Request, s, op)
+		sels := selected.ApplyOperation(ctx, &r.Request, s, op)
 		var fields []*fieldToExec
 		collectFieldsToResolve(sels, s, s.Resolver, &fields, make(map[string]*fieldToExec))
 
@@ -40,12 +40,12 @@ func (r *Request) Subscribe(ctx context.Context, s *resolvable.Schema, op *query
 
 		var in []reflect.Value
 		if f.field.HasContext {
-			in = append(in, reflect.ValueOf(ctx))
+			in = append(in, reflect.ValueOf(withFieldInfo(ctx, r, f, nil)))
 		}
 		if f.field.ArgsPacker != nil {
 			in = append(in, f.field.PackedArgs)
 		}
-		callOut := f.resolver.Method(f.field.MethodIndex).Call(in)
+		callOut := f.field.Resolver(f.resolver).Method(f.field.MethodIndex).Call(in)
 		result = callOut[0]
 
 		if f.field.HasError && !callOut[1].IsNil() {
@@ -54,6 +54,7 @@ func (r *Request) Subscribe(ctx context.Context, s *resolvable.Schema, op *query
 				err = resolverErr
 			case error:
 				err = errors.Errorf("%s", resolverErr)
+				err.Locations = []errors.Location{f.field.Loc}
 				err.ResolverError = resolverErr
 			default:
 				panic(fmt.Errorf("can only deal with *QueryError and error types, got %T", resolverErr))
@@ -120,9 +121,17 @@ func (r *Request) Subscribe(ctx context.Context, s *resolvable.Schema, op *query
 						Vars:   r.Request.Vars,
 						Schema: r.Request.Schema,
 					},
-					Limiter: r.Limiter,
-					Tracer:  r.Tracer,
-					Logger:  r.Logger,
+					Limiter:        r.Limiter,
+					MaxParallelism: r.MaxParallelism,
+					FieldCost:      r.FieldCost,
+					Workers:        r.Workers,
+					Tracer:         r.Tracer,
+					Logger:         r.Logger,
+
+					MaxListConcurrency: r.MaxListConcurrency,
+					MaxListLength:      r.MaxListLength,
+					TraceSkippedFields: r.TraceSkippedFields,
+					FieldOrder:         r.FieldOrder,
 				}
 				var out bytes.Buffer
 				func() {
@@ -139,7 +148,7 @@ func (r *Request) Subscribe(ctx context.Context, s *resolvable.Schema, op *query
 						defer subR.handlePanic(subCtx)
 
 						var buf bytes.Buffer
-						subR.execSelectionSet(subCtx, f.sels, f.field.Type, &pathSegment{nil, f.field.Alias}, s, resp, &buf)
+						subR.execFieldResult(subCtx, f, fieldPath(nil, f.field), s, resp, &buf)
 
 						propagateChildError := false
 						if _, nonNullChild := f.field.Type.(*common.NonNull); nonNullChild && resolvedToNull(&buf) {
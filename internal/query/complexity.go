@@ -0,0 +1,102 @@
+package query
+
+// Complexity estimates the cost of executing op as the number of fields it selects, with fragments
+// expanded. Fields inside lists are counted once, @skip and @include are not evaluated.
+func Complexity(doc *Document, op *Operation) int {
+	return complexity(doc, op.Selections, make(map[string]bool))
+}
+
+func complexity(doc *Document, sels []Selection, visited map[string]bool) int {
+	n := 0
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *Field:
+			n += 1 + complexity(doc, sel.Selections, visited)
+		case *InlineFragment:
+			n += complexity(doc, sel.Selections, visited)
+		case *FragmentSpread:
+			frag := doc.Fragments.Get(sel.Name.Name)
+			if frag == nil || visited[frag.Name.Name] {
+				continue
+			}
+			visited[frag.Name.Name] = true
+			n += complexity(doc, frag.Selections, visited)
+			delete(visited, frag.Name.Name)
+		}
+	}
+	return n
+}
+
+// Stats describes the shape of an operation.
+type Stats struct {
+	// Fragments is the number of distinct named fragments spread by the operation.
+	Fragments int
+
+	// MaxDepth is the maximum nesting depth of the fields, with fragments expanded. The fields
+	// of the operation have depth 1.
+	MaxDepth int
+
+	// Fields is the number of fields written in the operation and the fragments it spreads, with
+	// each fragment counted once.
+	Fields int
+
+	// Aliases is the number of those fields with an alias.
+	Aliases int
+}
+
+// OperationStats returns the Stats of op. Like Complexity, it does not evaluate @skip and @include.
+func OperationStats(doc *Document, op *Operation) Stats {
+	var stats Stats
+	fragments := make(map[string]bool)
+	var count func(sels []Selection)
+	count = func(sels []Selection) {
+		for _, sel := range sels {
+			switch sel := sel.(type) {
+			case *Field:
+				stats.Fields++
+				if sel.Alias.Name != sel.Name.Name {
+					stats.Aliases++
+				}
+				count(sel.Selections)
+			case *InlineFragment:
+				count(sel.Selections)
+			case *FragmentSpread:
+				frag := doc.Fragments.Get(sel.Name.Name)
+				if frag == nil || fragments[frag.Name.Name] {
+					continue
+				}
+				fragments[frag.Name.Name] = true
+				count(frag.Selections)
+			}
+		}
+	}
+	count(op.Selections)
+	stats.Fragments = len(fragments)
+	stats.MaxDepth = depth(doc, op.Selections, make(map[string]bool))
+	return stats
+}
+
+func depth(doc *Document, sels []Selection, visited map[string]bool) int {
+	max := 0
+	for _, sel := range sels {
+		d := 0
+		switch sel := sel.(type) {
+		case *Field:
+			d = 1 + depth(doc, sel.Selections, visited)
+		case *InlineFragment:
+			d = depth(doc, sel.Selections, visited)
+		case *FragmentSpread:
+			frag := doc.Fragments.Get(sel.Name.Name)
+			if frag == nil || visited[frag.Name.Name] {
+				continue
+			}
+			visited[frag.Name.Name] = true
+			d = depth(doc, frag.Selections, visited)
+			delete(visited, frag.Name.Name)
+		}
+		if d > max {
+			max = d
+		}
+	}
+	return max
+}
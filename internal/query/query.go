@@ -2,6 +2,7 @@ package query
 
 import (
 	"fmt"
+	"strings"
 	"text/scanner"
 
 	"github.com/graph-gophers/graphql-go/errors"
@@ -93,8 +94,26 @@ func (Field) isSelection()          {}
 func (InlineFragment) isSelection() {}
 func (FragmentSpread) isSelection() {}
 
+// Limits restricts the size of the documents accepted by ParseWithLimits. Zero values disable a
+// limit.
+type Limits struct {
+	MaxBytes  int
+	MaxTokens int
+}
+
 func Parse(queryString string) (*Document, *errors.QueryError) {
+	return ParseWithLimits(queryString, Limits{})
+}
+
+// ParseWithLimits parses the document like Parse, but rejects it as soon as one of the limits is
+// exceeded, before the rest of the document is parsed.
+func ParseWithLimits(queryString string, limits Limits) (*Document, *errors.QueryError) {
+	if limits.MaxBytes > 0 && len(queryString) > limits.MaxBytes {
+		return nil, errors.Errorf("document exceeds the maximum size of %d bytes", limits.MaxBytes)
+	}
+
 	l := common.NewLexer(queryString, false)
+	l.SetMaxTokens(limits.MaxTokens)
 
 	var doc *Document
 	err := l.CatchSyntaxError(func() { doc = parseDocument(l) })
@@ -105,6 +124,40 @@ func Parse(queryString string) (*Document, *errors.QueryError) {
 	return doc, nil
 }
 
+// Normalize returns the tokens of a query document without insignificant whitespace, commas and
+// comments, so documents that only differ in those normalize to the same string. Tokens are only
+// separated by a space where they would otherwise merge, e.g. between two names.
+func Normalize(queryString string) (string, *errors.QueryError) {
+	l := common.NewLexer(queryString, false)
+	var b strings.Builder
+	var prev rune
+	err := l.CatchSyntaxError(func() {
+		l.ConsumeWhitespace()
+		for l.Peek() != scanner.EOF {
+			tok := l.Peek()
+			switch tok {
+			case scanner.Ident, scanner.Int, scanner.Float, scanner.String:
+				if isWord(prev) && isWord(tok) {
+					b.WriteByte(' ')
+				}
+				b.WriteString(l.ConsumeLiteral().Text)
+			default:
+				b.WriteRune(tok)
+				l.ConsumeToken(tok)
+			}
+			prev = tok
+		}
+	})
+	if err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func isWord(tok rune) bool {
+	return tok == scanner.Ident || tok == scanner.Int || tok == scanner.Float
+}
+
 func parseDocument(l *common.Lexer) *Document {
 	d := &Document{}
 	l.ConsumeWhitespace()
@@ -1,7 +1,15 @@
 package schema
 
+var meta *Schema
+
 func init() {
-	_ = newMeta()
+	meta = newMeta()
+}
+
+// IsBuiltinDirective reports whether the directive is predefined by every schema, like @skip.
+func IsBuiltinDirective(name string) bool {
+	_, ok := meta.Directives[name]
+	return ok
 }
 
 // newMeta initializes an instance of the meta Schema.
@@ -51,7 +59,29 @@ var metaSrc = `
 		# for how to access supported similar data. Formatted in
 		# [Markdown](https://daringfireball.net/projects/markdown/).
 		reason: String = "No longer supported"
-	) on FIELD_DEFINITION | ENUM_VALUE
+	) on FIELD_DEFINITION | ARGUMENT_DEFINITION | INPUT_FIELD_DEFINITION | ENUM_VALUE
+
+	# Limits the number of characters of a string or the number of entries of a list.
+	directive @length(
+		# The minimum length, if any.
+		min: Int
+		# The maximum length, if any.
+		max: Int
+	) on ARGUMENT_DEFINITION | INPUT_FIELD_DEFINITION
+
+	# Limits a number, or every number of a list.
+	directive @range(
+		# The minimum value, if any.
+		min: Float
+		# The maximum value, if any.
+		max: Float
+	) on ARGUMENT_DEFINITION | INPUT_FIELD_DEFINITION
+
+	# Requires a string, or every string of a list, to match a Go regular expression.
+	directive @pattern(
+		# The regular expression, e.g. "^[a-z]+$".
+		regex: String!
+	) on ARGUMENT_DEFINITION | INPUT_FIELD_DEFINITION
 
 	# A Directive provides a way to describe alternate runtime execution and type validation behavior in a GraphQL document.
 	#
@@ -64,6 +94,7 @@ var metaSrc = `
 		description: String
 		locations: [__DirectiveLocation!]!
 		args: [__InputValue!]!
+		isRepeatable: Boolean!
 	}
 
 	# A Directive can be adjacent to many parts of the GraphQL language, a
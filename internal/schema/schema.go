@@ -2,7 +2,9 @@ package schema
 
 import (
 	"fmt"
+	"reflect"
 	"text/scanner"
+	"time"
 
 	"github.com/graph-gophers/graphql-go/errors"
 	"github.com/graph-gophers/graphql-go/internal/common"
@@ -41,15 +43,117 @@ type Schema struct {
 	// http://facebook.github.io/graphql/draft/#sec-Type-System.Directives
 	Directives map[string]*DirectiveDecl
 
+	// SchemaDirectives are the directives applied to the schema definition and its extensions,
+	// e.g. `extend schema @link(url: "https://specs.apollo.dev/federation/v2.3")`.
+	//
+	// https://spec.graphql.org/October2021/#sec-Schema
+	SchemaDirectives common.DirectiveList
+
 	UseFieldResolvers bool
 
+	// PreferFieldResolvers resolves fields by struct fields rather than methods of the same name,
+	// except for resolver types with a PreferMethodResolvers method. It implies UseFieldResolvers.
+	PreferFieldResolvers bool
+
+	// NilSlicesAsNull writes nil slices resolved for nullable list fields as null instead of an
+	// empty list, unless overridden by a "@nilSlice(as: NilSlice!)" directive or a struct field tag.
+	NilSlicesAsNull bool
+
+	// FieldNameMapper, if set, maps the names of resolver methods and struct fields to the GraphQL
+	// names they bind, which must be equal. Otherwise names are compared ignoring case and
+	// underscores. The name of a "graphql" struct tag is never mapped.
+	FieldNameMapper func(goName string) string
+
+	// CommentDescriptions uses the comments preceding a definition as its description if it has no
+	// string description, when the schema is parsed with string descriptions.
+	CommentDescriptions bool
+
+	// StrictResolverTypes checks the result types of resolvers more thoroughly when the resolvers
+	// are attached to the schema.
+	StrictResolverTypes bool
+
+	// RawJSONTypes are the resolver result types whose values are written to the response as is.
+	RawJSONTypes []reflect.Type
+
+	// MarshalerType is the interface implemented by resolver results that write themselves to the
+	// response.
+	MarshalerType reflect.Type
+
+	// LegacyNumericCoercion truncates Float values passed for Int inputs and writes Int results
+	// outside of the 32-bit range as is, instead of rejecting them.
+	LegacyNumericCoercion bool
+
+	// Int64Policy decides which fields results of 64-bit integer types may resolve and how they are
+	// written.
+	Int64Policy Int64Policy
+
+	// NonFiniteFloats decides how NaN and infinite float results are written.
+	NonFiniteFloats NonFiniteFloatPolicy
+
+	// SemanticNonNullIntrospection reports the positions of field types marked by a
+	// "@semanticNonNull" directive as non-null in introspection.
+	SemanticNonNullIntrospection bool
+
+	// Pagination, if set, requires list fields declaring pagination arguments to be selected with
+	// a page size of at most its maximum.
+	Pagination *Pagination
+
+	// OneOfConstructors map the name of a @oneOf input object and the name of its fields to a func
+	// converting the value of the field to a member of the Go interface the input is unpacked into.
+	OneOfConstructors map[string]map[string]reflect.Value
+
+	// InputValidators map the names of input objects to a func(context.Context, *T) error called
+	// with the unpacked values of the input object, see graphql.WithInputValidator.
+	InputValidators map[string]reflect.Value
+
+	// EnumMappings map the names of enums to the Go values of their values, see
+	// graphql.EnumValues.
+	EnumMappings map[string]*EnumMapping
+
+	// WrapResolver, if set, returns the wrapper of the resolver of a field, or nil if the resolver
+	// is not wrapped.
+	WrapResolver func(typeName, fieldName string) ResolverWrapper
+
+	// RetryPolicies map the coordinates of fields, e.g. "Query.user", to the retry policies of
+	// their resolvers. They take precedence over @retry directives.
+	RetryPolicies map[string]*RetryPolicy
+
 	entryPointNames map[string]string
+	originalNames   map[string]string
 	objects         []*Object
 	unions          []*Union
 	enums           []*Enum
 	extensions      []*Extension
 }
 
+// Pagination configures the enforcement of pagination arguments on list fields.
+type Pagination struct {
+	// MaxPageSize is the maximum value of a pagination argument.
+	MaxPageSize int
+
+	// Arguments are the names of the pagination arguments, e.g. "first" and "last". List fields
+	// declaring none of them are not paginated.
+	Arguments []string
+
+	// Cap caps the page size to MaxPageSize instead of rejecting queries exceeding it or omitting
+	// the pagination arguments.
+	Cap bool
+}
+
+// RetryPolicy retries the resolver of a field that returned an error.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of calls of the resolver, including the first one.
+	MaxAttempts int
+
+	// Backoff returns the delay before the given retry, starting at 1. A nil Backoff retries
+	// immediately.
+	Backoff func(retry int) time.Duration
+
+	// RetryOn reports whether the error of a resolver is retried. A nil RetryOn retries all
+	// errors.
+	RetryOn func(err error) bool
+}
+
 // Resolve a named type in the schema by its name.
 func (s *Schema) Resolve(name string) common.Type {
 	return s.Types[name]
@@ -106,6 +210,13 @@ type Interface struct {
 	Fields        FieldList // NOTE: the spec refers to this as `FieldsDefinition`.
 	Desc          string
 	Directives    common.DirectiveList
+
+	// Interfaces are the interfaces the interface implements.
+	//
+	// https://spec.graphql.org/October2021/#sec-Interfaces.Interfaces-Implementing-Interfaces
+	Interfaces []*Interface
+
+	interfaceNames []string
 }
 
 // Union types represent objects that could be one of a list of GraphQL object types, but provides no
@@ -200,6 +311,10 @@ type DirectiveDecl struct {
 	Desc string
 	Locs []string
 	Args common.InputValueList
+
+	// Repeatable reports whether the directive is declared "repeatable", so that it may be applied
+	// to a location more than once.
+	Repeatable bool
 }
 
 func (*Scalar) Kind() string      { return "SCALAR" }
@@ -209,6 +324,11 @@ func (*Union) Kind() string       { return "UNION" }
 func (*Enum) Kind() string        { return "ENUM" }
 func (*InputObject) Kind() string { return "INPUT_OBJECT" }
 
+// OneOf reports whether the input object is annotated with a "@oneOf" directive, which requires
+// exactly one of its fields to be given with a non-null value. The directive has to be declared
+// in the schema with "directive @oneOf on INPUT_OBJECT".
+func (t *InputObject) OneOf() bool { return t.Directives.Get("oneOf") != nil }
+
 func (t *Scalar) String() string      { return t.Name }
 func (t *Object) String() string      { return t.Name }
 func (t *Interface) String() string   { return t.Name }
@@ -238,6 +358,15 @@ type Field struct {
 	Type       common.Type
 	Directives common.DirectiveList
 	Desc       string
+
+	// IntrospectionType is the type reported by introspection if it differs from Type, see
+	// Schema.SemanticNonNullIntrospection.
+	IntrospectionType common.Type
+
+	// Via are the fields a hoisted field is resolved through, see Schema.HoistField. The field is
+	// resolved by the method or struct field named ResolverName of the result of the last of them.
+	Via          []*Field
+	ResolverName string
 }
 
 // New initializes an instance of Schema.
@@ -257,9 +386,64 @@ func New() *Schema {
 	return s
 }
 
+// EnumMapping maps the values of an enum to Go values of one type.
+type EnumMapping struct {
+	// Type is the Go type of the values.
+	Type reflect.Type
+
+	// Values are the Go values by the names of the enum values.
+	Values map[string]reflect.Value
+
+	// Names are the names of the enum values by their Go values.
+	Names map[interface{}]string
+}
+
+// Int64Policy decides how results of the 64-bit integer types int, int64, uint, uint64 and
+// uintptr are handled, see graphql.Int64Results.
+type Int64Policy int
+
+const (
+	// Int64Default allows 64-bit integers to resolve Int fields if they are in the 32-bit range, and
+	// Float and ID fields.
+	Int64Default Int64Policy = iota
+
+	// Int64Reject only allows 64-bit integers to resolve ID fields.
+	Int64Reject
+
+	// Int64AsFloat only allows 64-bit integers to resolve Float and ID fields, and writes them as
+	// floating point numbers.
+	Int64AsFloat
+
+	// Int64AsString allows 64-bit integers to resolve ID, String and custom scalar fields, and
+	// writes them as strings.
+	Int64AsString
+
+	// Int64BigInt only allows 64-bit integers to resolve ID fields and fields of a "BigInt" scalar,
+	// and writes them as strings.
+	Int64BigInt
+)
+
+// NonFiniteFloatPolicy decides how NaN and infinite float results, which can not be represented in
+// JSON, are written, see graphql.NonFiniteFloats.
+type NonFiniteFloatPolicy int
+
+const (
+	// NonFiniteError writes null and adds an error with the path of the field.
+	NonFiniteError NonFiniteFloatPolicy = iota
+
+	// NonFiniteNull writes null without an error.
+	NonFiniteNull
+
+	// NonFiniteString writes the strings "NaN", "Infinity" and "-Infinity".
+	NonFiniteString
+)
+
 // Parse the schema string.
 func (s *Schema) Parse(schemaString string, useStringDescriptions bool) error {
 	l := common.NewLexer(schemaString, useStringDescriptions)
+	if s.CommentDescriptions {
+		l.UseCommentDescriptions()
+	}
 
 	err := l.CatchSyntaxError(func() { parseSchema(s, l) })
 	if err != nil {
@@ -311,6 +495,10 @@ func (s *Schema) Parse(schemaString string, useStringDescriptions bool) error {
 		s.EntryPoints[key] = t
 	}
 
+	if err := resolveDirectives(s, s.SchemaDirectives, "SCHEMA"); err != nil {
+		return err
+	}
+
 	for _, obj := range s.objects {
 		obj.Interfaces = make([]*Interface, len(obj.interfaceNames))
 		if err := resolveDirectives(s, obj.Directives, "OBJECT"); err != nil {
@@ -321,20 +509,11 @@ func (s *Schema) Parse(schemaString string, useStringDescriptions bool) error {
 				return err
 			}
 		}
-		for i, intfName := range obj.interfaceNames {
-			t, ok := s.Types[intfName]
-			if !ok {
-				return errors.Errorf("interface %q not found", intfName)
-			}
-			intf, ok := t.(*Interface)
-			if !ok {
-				return errors.Errorf("type %q is not an interface", intfName)
-			}
-			for _, f := range intf.Fields.Names() {
-				if obj.Fields.Get(f) == nil {
-					return errors.Errorf("interface %q expects field %q but %q does not provide it", intfName, f, obj.Name)
-				}
-			}
+		interfaces, err := resolveInterfaces(s, obj.Name, obj.interfaceNames, obj.Fields)
+		if err != nil {
+			return err
+		}
+		for i, intf := range interfaces {
 			obj.Interfaces[i] = intf
 			intf.PossibleTypes = append(intf.PossibleTypes, obj)
 		}
@@ -423,6 +602,15 @@ func mergeExtensions(s *Schema) error {
 			}
 			og.Fields = append(og.Fields, e.Fields...)
 
+			for _, en := range e.interfaceNames {
+				for _, on := range og.interfaceNames {
+					if on == en {
+						return fmt.Errorf("interface %q implemented in the extension is already implemented in %q", on, og.Name)
+					}
+				}
+			}
+			og.interfaceNames = append(og.interfaceNames, e.interfaceNames...)
+
 		case *Union:
 			e := ext.Type.(*Union)
 
@@ -468,14 +656,67 @@ func resolveNamedType(s *Schema, t NamedType) error {
 				return err
 			}
 		}
+		interfaces, err := resolveInterfaces(s, t.Name, t.interfaceNames, t.Fields)
+		if err != nil {
+			return err
+		}
+		t.Interfaces = interfaces
 	case *InputObject:
 		if err := resolveInputObject(s, t.Values); err != nil {
 			return err
 		}
+		if t.OneOf() {
+			if err := resolveDirectives(s, t.Directives, "INPUT_OBJECT"); err != nil {
+				return err
+			}
+			for _, v := range t.Values {
+				if _, ok := v.Type.(*common.NonNull); ok || v.Default != nil {
+					return errors.Errorf("field %q of oneOf input %q must be nullable and have no default value", v.Name.Name, t.Name)
+				}
+			}
+		}
 	}
 	return nil
 }
 
+// resolveInterfaces resolves the interfaces implemented by an object or interface type, which has
+// to provide their fields and to implement the interfaces they implement as well.
+func resolveInterfaces(s *Schema, typeName string, interfaceNames []string, fields FieldList) ([]*Interface, error) {
+	interfaces := make([]*Interface, len(interfaceNames))
+	declared := make(map[string]bool, len(interfaceNames))
+	for i, intfName := range interfaceNames {
+		t, ok := s.Types[intfName]
+		if !ok {
+			return nil, errors.Errorf("interface %q not found", intfName)
+		}
+		intf, ok := t.(*Interface)
+		if !ok {
+			return nil, errors.Errorf("type %q is not an interface", intfName)
+		}
+		if intfName == typeName {
+			return nil, errors.Errorf("interface %q can not implement itself", typeName)
+		}
+		for _, f := range intf.Fields.Names() {
+			if fields.Get(f) == nil {
+				return nil, errors.Errorf("interface %q expects field %q but %q does not provide it", intfName, f, typeName)
+			}
+		}
+		interfaces[i] = intf
+		declared[intfName] = true
+	}
+	for _, intf := range interfaces {
+		for _, name := range intf.interfaceNames {
+			if name == typeName {
+				return nil, errors.Errorf("interface %q can not implement itself through %q", typeName, intf.Name)
+			}
+			if !declared[name] {
+				return nil, errors.Errorf("%q must implement %q, which is implemented by %q", typeName, name, intf.Name)
+			}
+		}
+	}
+	return interfaces, nil
+}
+
 func resolveField(s *Schema, f *Field) error {
 	t, err := common.ResolveType(f.Type, s.Resolve)
 	if err != nil {
@@ -485,9 +726,81 @@ func resolveField(s *Schema, f *Field) error {
 	if err := resolveDirectives(s, f.Directives, "FIELD_DEFINITION"); err != nil {
 		return err
 	}
+	if s.SemanticNonNullIntrospection {
+		t, err := SemanticNonNullType(f, func(t common.Type) common.Type { return &common.NonNull{OfType: t} })
+		if err != nil {
+			return err
+		}
+		if t != f.Type {
+			f.IntrospectionType = t
+		}
+	} else if _, err := SemanticNonNullType(f, nil); err != nil {
+		return err
+	}
 	return resolveInputObject(s, f.Args)
 }
 
+// SemanticNonNullType returns the type of the field with the positions listed by its
+// "@semanticNonNull(levels: [Int] = [0])" directive wrapped by wrap. Level 0 is the field itself,
+// level 1 the elements of a list, and so on. Such positions are nullable only to limit the impact
+// of errors; they never resolve to null otherwise. The type is returned as is if the field has no
+// such directive. wrap may be nil to only check the levels.
+func SemanticNonNullType(f *Field, wrap func(common.Type) common.Type) (common.Type, error) {
+	d := f.Directives.Get("semanticNonNull")
+	if d == nil {
+		return f.Type, nil
+	}
+	marked := map[int]bool{0: true}
+	if lit, ok := d.Args.Get("levels"); ok && lit != nil {
+		marked = make(map[int]bool)
+		list, _ := lit.Value(nil).([]interface{})
+		for _, l := range list {
+			if l, ok := l.(int32); ok {
+				marked[int(l)] = true
+			}
+		}
+	}
+
+	remaining := len(marked)
+	var walk func(t common.Type, level int) (common.Type, error)
+	walk = func(t common.Type, level int) (common.Type, error) {
+		if marked[level] {
+			if _, ok := t.(*common.NonNull); ok {
+				return nil, errors.Errorf("level %d of field %q marked by @semanticNonNull is already non-null", level, f.Name)
+			}
+			remaining--
+		}
+		inner, nonNull := t, false
+		if nn, ok := t.(*common.NonNull); ok {
+			inner, nonNull = nn.OfType, true
+		}
+		if list, ok := inner.(*common.List); ok && remaining != 0 {
+			elem, err := walk(list.OfType, level+1)
+			if err != nil {
+				return nil, err
+			}
+			inner = &common.List{OfType: elem}
+			if nonNull {
+				t = &common.NonNull{OfType: inner}
+			} else {
+				t = inner
+			}
+		}
+		if marked[level] && wrap != nil {
+			t = wrap(t)
+		}
+		return t, nil
+	}
+	t, err := walk(f.Type, 0)
+	if err != nil {
+		return nil, err
+	}
+	if remaining != 0 {
+		return nil, errors.Errorf("field %q marked by @semanticNonNull has fewer levels than listed", f.Name)
+	}
+	return t, nil
+}
+
 func resolveDirectives(s *Schema, directives common.DirectiveList, loc string) error {
 	for _, d := range directives {
 		dirName := d.Name.Name
@@ -538,14 +851,7 @@ func parseSchema(s *Schema, l *common.Lexer) {
 		switch x := l.ConsumeIdent(); x {
 
 		case "schema":
-			l.ConsumeToken('{')
-			for l.Peek() != '}' {
-				name := l.ConsumeIdent()
-				l.ConsumeToken(':')
-				typ := l.ConsumeIdent()
-				s.entryPointNames[name] = typ
-			}
-			l.ConsumeToken('}')
+			parseSchemaDef(s, l, false)
 
 		case "type":
 			obj := parseObjectDef(l)
@@ -634,6 +940,17 @@ func parseObjectDef(l *common.Lexer) *Object {
 func parseInterfaceDef(l *common.Lexer) *Interface {
 	i := &Interface{Name: l.ConsumeIdent()}
 
+	if l.Peek() == scanner.Ident {
+		l.ConsumeKeyword("implements")
+		if l.Peek() == '&' {
+			l.ConsumeToken('&')
+		}
+		i.interfaceNames = append(i.interfaceNames, l.ConsumeIdent())
+		for l.Peek() == '&' {
+			l.ConsumeToken('&')
+			i.interfaceNames = append(i.interfaceNames, l.ConsumeIdent())
+		}
+	}
 	i.Directives = common.ParseDirectives(l)
 	l.ConsumeToken('{')
 	i.Fields = parseFieldsDef(l)
@@ -686,6 +1003,31 @@ func parseEnumDef(l *common.Lexer) *Enum {
 	return enum
 }
 
+// parseSchemaDef parses the directives and root operation types of a schema definition. The root
+// operation types may be omitted in an extension.
+func parseSchemaDef(s *Schema, l *common.Lexer, extension bool) {
+	s.SchemaDirectives = append(s.SchemaDirectives, common.ParseDirectives(l)...)
+	if extension && l.Peek() != '{' {
+		return
+	}
+	l.ConsumeToken('{')
+	for l.Peek() != '}' {
+		name := l.ConsumeIdent()
+		switch name {
+		case "query", "mutation", "subscription":
+		default:
+			l.SyntaxError(fmt.Sprintf(`unexpected %q, expecting "query", "mutation" or "subscription"`, name))
+		}
+		if _, ok := s.entryPointNames[name]; ok && !extension {
+			l.SyntaxError(fmt.Sprintf("root operation %q is defined more than once", name))
+		}
+		l.ConsumeToken(':')
+		typ := l.ConsumeIdent()
+		s.entryPointNames[name] = typ
+	}
+	l.ConsumeToken('}')
+}
+
 func parseDirectiveDef(l *common.Lexer) *DirectiveDecl {
 	l.ConsumeToken('@')
 	d := &DirectiveDecl{Name: l.ConsumeIdent()}
@@ -699,6 +1041,11 @@ func parseDirectiveDef(l *common.Lexer) *DirectiveDecl {
 		l.ConsumeToken(')')
 	}
 
+	if l.PeekKeyword("repeatable") {
+		l.ConsumeKeyword("repeatable")
+		d.Repeatable = true
+	}
+
 	l.ConsumeKeyword("on")
 
 	for {
@@ -715,14 +1062,7 @@ func parseDirectiveDef(l *common.Lexer) *DirectiveDecl {
 func parseExtension(s *Schema, l *common.Lexer) {
 	switch x := l.ConsumeIdent(); x {
 	case "schema":
-		l.ConsumeToken('{')
-		for l.Peek() != '}' {
-			name := l.ConsumeIdent()
-			l.ConsumeToken(':')
-			typ := l.ConsumeIdent()
-			s.entryPointNames[name] = typ
-		}
-		l.ConsumeToken('}')
+		parseSchemaDef(s, l, true)
 
 	case "type":
 		obj := parseObjectDef(l)
@@ -34,6 +34,26 @@ func TestParse(t *testing.T) {
 				return nil
 			},
 		},
+		{
+			name: "Parses interface implementing interfaces",
+			sdl: `
+			interface Entity { id: ID! }
+			interface Named { name: String! }
+			interface Node implements Entity & Named { id: ID! name: String! }
+			type User implements Node & Entity & Named { id: ID! name: String! }
+			`,
+			validateSchema: func(s *schema.Schema) error {
+				node := s.Types["Node"].(*schema.Interface)
+				if len(node.Interfaces) != 2 || node.Interfaces[0].Name != "Entity" || node.Interfaces[1].Name != "Named" {
+					return fmt.Errorf("invalid interfaces of %q: %v", node.Name, node.Interfaces)
+				}
+				entity := s.Types["Entity"].(*schema.Interface)
+				if len(entity.PossibleTypes) != 1 || entity.PossibleTypes[0].Name != "User" {
+					return fmt.Errorf("invalid possible types of %q: %v", entity.Name, entity.PossibleTypes)
+				}
+				return nil
+			},
+		},
 		{
 			name: "Parses implementing type without providing required fields",
 			sdl: `
@@ -0,0 +1,196 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/graph-gophers/graphql-go/internal/common"
+)
+
+// ResolverWrapper wraps the resolution of a field. It calls next to resolve the field with the
+// resolver of the schema and returns the result, which must be nil or have the result type of
+// the resolver.
+type ResolverWrapper func(ctx context.Context, next func(ctx context.Context) (interface{}, error)) (interface{}, error)
+
+// RenameType renames a type. The references to the type are resolved already and follow the new
+// name, while resolvers are still bound with the original name, e.g. by the "To" methods of
+// interface resolvers.
+func (s *Schema) RenameType(name string, newName string) error {
+	if name == newName {
+		return nil
+	}
+	t, ok := s.Types[name]
+	if !ok {
+		return fmt.Errorf("type %q is not defined", name)
+	}
+	if IsBuiltinType(name) {
+		return fmt.Errorf("built-in type %q can not be renamed", name)
+	}
+	if !isName(newName) {
+		return fmt.Errorf("can not rename type %q to %q, the name is invalid", name, newName)
+	}
+	if _, ok := s.Types[newName]; ok {
+		return fmt.Errorf("can not rename type %q to %q, the type is already defined", name, newName)
+	}
+
+	switch t := t.(type) {
+	case *Scalar:
+		t.Name = newName
+	case *Object:
+		t.Name = newName
+	case *Interface:
+		t.Name = newName
+	case *Union:
+		t.Name = newName
+	case *Enum:
+		t.Name = newName
+	case *InputObject:
+		t.Name = newName
+	}
+	delete(s.Types, name)
+	s.Types[newName] = t
+	for op, typeName := range s.entryPointNames {
+		if typeName == name {
+			s.entryPointNames[op] = newName
+		}
+	}
+	if c, ok := s.OneOfConstructors[name]; ok {
+		delete(s.OneOfConstructors, name)
+		s.OneOfConstructors[newName] = c
+	}
+
+	if s.originalNames == nil {
+		s.originalNames = make(map[string]string)
+	}
+	original := s.OriginalName(name)
+	delete(s.originalNames, name)
+	s.originalNames[newName] = original
+	return nil
+}
+
+// OriginalName returns the name a type was declared with in the schema, before it was renamed.
+func (s *Schema) OriginalName(name string) string {
+	if original, ok := s.originalNames[name]; ok {
+		return original
+	}
+	return name
+}
+
+// RemoveField removes a field of an object or interface type. Fields of interfaces can not be
+// removed from the objects implementing them.
+func (s *Schema) RemoveField(typeName string, fieldName string) error {
+	var fields *FieldList
+	switch t := s.Types[typeName].(type) {
+	case *Object:
+		for _, intf := range t.Interfaces {
+			if intf.Fields.Get(fieldName) != nil {
+				return fmt.Errorf("field %q of type %q is required by interface %q", fieldName, typeName, intf.Name)
+			}
+		}
+		fields = &t.Fields
+	case *Interface:
+		fields = &t.Fields
+	default:
+		return fmt.Errorf("type %q is not an object or interface", typeName)
+	}
+	for i, f := range *fields {
+		if f.Name == fieldName {
+			*fields = append((*fields)[:i:i], (*fields)[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("field %q is not defined on type %q", fieldName, typeName)
+}
+
+// HoistField adds a field named newName to an object type, which resolves to the field at the end
+// of the path of fields below the type, e.g. "viewer", "account". The fields of the path but the
+// last must be of object types and can not have arguments. The new field is nullable if any of
+// them is. The nested field is kept.
+func (s *Schema) HoistField(typeName string, path []string, newName string) error {
+	t, ok := s.Types[typeName].(*Object)
+	if !ok {
+		return fmt.Errorf("type %q is not an object", typeName)
+	}
+	if s.entryPointNames["subscription"] == typeName {
+		return fmt.Errorf("can not hoist fields to subscription type %q", typeName)
+	}
+	if len(path) < 2 {
+		return fmt.Errorf("can not hoist field %q to type %q, the path must have at least two fields", strings.Join(path, "."), typeName)
+	}
+	if t.Fields.Get(newName) != nil {
+		return fmt.Errorf("can not hoist field %q to type %q, field %q is already defined", strings.Join(path, "."), typeName, newName)
+	}
+
+	var via []*Field
+	nullable := false
+	cur := t
+	for _, name := range path[:len(path)-1] {
+		f := cur.Fields.Get(name)
+		if f == nil {
+			return fmt.Errorf("field %q is not defined on type %q", name, cur.Name)
+		}
+		if len(f.Args) != 0 {
+			return fmt.Errorf("can not hoist through field %q of type %q, it has arguments", name, cur.Name)
+		}
+		typ := f.Type
+		if nn, ok := typ.(*common.NonNull); ok {
+			typ = nn.OfType
+		} else {
+			nullable = true
+		}
+		obj, ok := typ.(*Object)
+		if !ok {
+			return fmt.Errorf("can not hoist through field %q of type %q, its type %s is not an object", name, cur.Name, f.Type)
+		}
+		via = append(via, f)
+		cur = obj
+	}
+	last := cur.Fields.Get(path[len(path)-1])
+	if last == nil {
+		return fmt.Errorf("field %q is not defined on type %q", path[len(path)-1], cur.Name)
+	}
+
+	hoisted := *last
+	hoisted.Name = newName
+	hoisted.Via = append(via, last.Via...)
+	hoisted.ResolverName = last.Name
+	if last.ResolverName != "" {
+		hoisted.ResolverName = last.ResolverName
+	}
+	if nullable {
+		if nn, ok := hoisted.Type.(*common.NonNull); ok {
+			hoisted.Type = nn.OfType
+		}
+		if nn, ok := hoisted.IntrospectionType.(*common.NonNull); ok {
+			hoisted.IntrospectionType = nn.OfType
+		}
+	}
+	t.Fields = append(t.Fields, &hoisted)
+	return nil
+}
+
+// IsBuiltinType reports whether a type is a built-in scalar or an introspection type.
+func IsBuiltinType(name string) bool {
+	switch name {
+	case "Int", "Float", "String", "Boolean", "ID":
+		return true
+	}
+	return strings.HasPrefix(name, "__")
+}
+
+// isName reports whether s is a valid GraphQL name.
+func isName(s string) bool {
+	if s == "" || strings.HasPrefix(s, "__") {
+		return false
+	}
+	for i, c := range s {
+		switch {
+		case c == '_', 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+		case '0' <= c && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,337 @@
+package validation
+
+import (
+	"github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/internal/common"
+	"github.com/graph-gophers/graphql-go/internal/query"
+)
+
+// Limits restricts the documents accepted by ValidateWithLimits. Zero values disable a limit.
+type Limits struct {
+	// MaxDepth is the maximum field nesting depth of an operation.
+	MaxDepth int
+
+	// MaxFragmentDepth is the maximum nesting depth of fragment spreads, e.g. 2 if a fragment
+	// spread by an operation spreads another fragment.
+	MaxFragmentDepth int
+
+	// MaxInputDepth is the maximum nesting depth of the lists and input objects of argument values,
+	// default values and variable values.
+	MaxInputDepth int
+
+	// MaxIntrospectionDepth is the maximum field nesting depth of the __schema and __type fields of
+	// an operation, counting them as depth 1.
+	MaxIntrospectionDepth int
+
+	// MaxOfTypeDepth is the maximum number of nested ofType fields within the __schema and __type
+	// fields of an operation.
+	MaxOfTypeDepth int
+}
+
+// validateFragmentDepth reports the fragment spreads of the operations whose fragments spread
+// fragments deeper than the limit. Every fragment is visited once and spreads within a cycle are
+// ignored, as cycles are reported by NoFragmentCycles. Returns whether the limit was exceeded.
+func validateFragmentDepth(c *context) bool {
+	if c.limits.MaxFragmentDepth == 0 {
+		return false
+	}
+
+	fd := &fragmentDepths{
+		doc:      c.doc,
+		depths:   make(map[*query.FragmentDecl]int),
+		deepest:  make(map[*query.FragmentDecl]*query.FragmentSpread),
+		visiting: make(map[*query.FragmentDecl]bool),
+	}
+	exceeded := false
+	for _, op := range c.doc.Operations {
+		for _, spread := range fd.spreads(op.Selections, nil) {
+			depth := fd.depth(spread)
+			if depth <= c.limits.MaxFragmentDepth {
+				continue
+			}
+			exceeded = true
+
+			// The locations are the spreads up to the first one exceeding the limit.
+			var locs []errors.Location
+			for s := spread; s != nil && len(locs) <= c.limits.MaxFragmentDepth; s = fd.deepest[c.doc.Fragments.Get(s.Name.Name)] {
+				locs = append(locs, s.Loc)
+			}
+			c.addErrMultiLoc(locs, "MaxFragmentDepthExceeded", "Fragment spread %q has nesting depth %d that exceeds max fragment depth %d", spread.Name.Name, depth, c.limits.MaxFragmentDepth)
+		}
+	}
+	return exceeded
+}
+
+type fragmentDepths struct {
+	doc      *query.Document
+	depths   map[*query.FragmentDecl]int
+	deepest  map[*query.FragmentDecl]*query.FragmentSpread
+	visiting map[*query.FragmentDecl]bool
+}
+
+// depth returns the nesting depth of the fragment spread, which is 1 if the fragment spreads no
+// other fragments.
+func (fd *fragmentDepths) depth(spread *query.FragmentSpread) int {
+	frag := fd.doc.Fragments.Get(spread.Name.Name)
+	if frag == nil || fd.visiting[frag] {
+		return 0
+	}
+	if depth, ok := fd.depths[frag]; ok {
+		return depth
+	}
+
+	fd.visiting[frag] = true
+	max := 0
+	for _, s := range fd.spreads(frag.Selections, nil) {
+		if depth := fd.depth(s); depth > max {
+			max = depth
+			fd.deepest[frag] = s
+		}
+	}
+	delete(fd.visiting, frag)
+
+	fd.depths[frag] = max + 1
+	return max + 1
+}
+
+// spreads appends the fragment spreads of the selections to spreads, without entering fragments.
+func (fd *fragmentDepths) spreads(sels []query.Selection, spreads []*query.FragmentSpread) []*query.FragmentSpread {
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *query.Field:
+			spreads = fd.spreads(sel.Selections, spreads)
+		case *query.InlineFragment:
+			spreads = fd.spreads(sel.Selections, spreads)
+		case *query.FragmentSpread:
+			spreads = append(spreads, sel)
+		}
+	}
+	return spreads
+}
+
+// validateInputDepth reports the values of the document and the variable values nested deeper
+// than the limit. Returns whether the limit was exceeded.
+func validateInputDepth(c *context) bool {
+	if c.limits.MaxInputDepth == 0 {
+		return false
+	}
+
+	errCount := len(c.errs)
+	for _, op := range c.doc.Operations {
+		validateDirectivesInputDepth(c, op.Directives)
+		executed := c.operationName == "" || op.Name.Name == c.operationName
+		for _, v := range op.Vars {
+			validateDirectivesInputDepth(c, v.Directives)
+			if v.Default != nil {
+				validateLiteralInputDepth(c, v.Default)
+			}
+			if val, ok := c.variables[v.Name.Name]; ok && executed {
+				if depth := valueDepth(val, c.limits.MaxInputDepth); depth > c.limits.MaxInputDepth {
+					c.addErr(v.Loc, "MaxInputDepthExceeded", "Variable %q has a value nested deeper than max input depth %d", "$"+v.Name.Name, c.limits.MaxInputDepth)
+				}
+			}
+		}
+		validateSelectionsInputDepth(c, op.Selections)
+	}
+	for _, frag := range c.doc.Fragments {
+		validateDirectivesInputDepth(c, frag.Directives)
+		validateSelectionsInputDepth(c, frag.Selections)
+	}
+	return len(c.errs) > errCount
+}
+
+func validateSelectionsInputDepth(c *context, sels []query.Selection) {
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *query.Field:
+			for _, arg := range sel.Arguments {
+				validateLiteralInputDepth(c, arg.Value)
+			}
+			validateDirectivesInputDepth(c, sel.Directives)
+			validateSelectionsInputDepth(c, sel.Selections)
+		case *query.InlineFragment:
+			validateDirectivesInputDepth(c, sel.Directives)
+			validateSelectionsInputDepth(c, sel.Selections)
+		case *query.FragmentSpread:
+			validateDirectivesInputDepth(c, sel.Directives)
+		}
+	}
+}
+
+func validateDirectivesInputDepth(c *context, directives common.DirectiveList) {
+	for _, d := range directives {
+		for _, arg := range d.Args {
+			validateLiteralInputDepth(c, arg.Value)
+		}
+	}
+}
+
+func validateLiteralInputDepth(c *context, l common.Literal) {
+	if lit := tooDeepLiteral(l, 0, c.limits.MaxInputDepth); lit != nil {
+		c.addErr(lit.Location(), "MaxInputDepthExceeded", "Value is nested deeper than max input depth %d", c.limits.MaxInputDepth)
+	}
+}
+
+// tooDeepLiteral returns the first list or input object within l at a depth greater than max. It
+// does not descend further, so the recursion is bounded by max.
+func tooDeepLiteral(l common.Literal, depth int, max int) common.Literal {
+	var children []common.Literal
+	switch l := l.(type) {
+	case *common.ListLit:
+		children = l.Entries
+	case *common.ObjectLit:
+		for _, f := range l.Fields {
+			children = append(children, f.Value)
+		}
+	default:
+		return nil
+	}
+
+	depth++
+	if depth > max {
+		return l
+	}
+	for _, child := range children {
+		if lit := tooDeepLiteral(child, depth, max); lit != nil {
+			return lit
+		}
+	}
+	return nil
+}
+
+// valueDepth returns the nesting depth of the lists and objects of a variable value, but at most
+// max+1.
+func valueDepth(val interface{}, max int) int {
+	var depth int
+	switch val := val.(type) {
+	case []interface{}:
+		if max == 0 {
+			return 1
+		}
+		for _, elem := range val {
+			if d := valueDepth(elem, max-1); d > depth {
+				depth = d
+			}
+		}
+	case map[string]interface{}:
+		if max == 0 {
+			return 1
+		}
+		for _, elem := range val {
+			if d := valueDepth(elem, max-1); d > depth {
+				depth = d
+			}
+		}
+	default:
+		return 0
+	}
+	return depth + 1
+}
+
+// validateIntrospectionLimits reports the __schema and __type fields of the operations whose
+// selections are nested deeper than MaxIntrospectionDepth or nest more ofType fields than
+// MaxOfTypeDepth. The shape of every fragment is computed once, so documents spreading fragments
+// many times do not multiply the work. Returns whether a limit was exceeded.
+func validateIntrospectionLimits(c *context) bool {
+	if c.limits.MaxIntrospectionDepth == 0 && c.limits.MaxOfTypeDepth == 0 {
+		return false
+	}
+
+	w := &shapeWalker{
+		doc:      c.doc,
+		shapes:   make(map[*query.FragmentDecl]selectionShape),
+		visiting: make(map[*query.FragmentDecl]bool),
+	}
+	exceeded := false
+	for _, op := range c.doc.Operations {
+		if op.Type != query.Query {
+			continue
+		}
+		for _, f := range rootFields(c.doc, op.Selections, make(map[*query.FragmentDecl]bool), nil) {
+			if f.Name.Name != "__schema" && f.Name.Name != "__type" {
+				continue
+			}
+			shape := w.shape(f.Selections)
+			if max := c.limits.MaxIntrospectionDepth; max != 0 && shape.depth+1 > max {
+				exceeded = true
+				c.addErr(f.Alias.Loc, "MaxIntrospectionDepthExceeded", "Introspection field %q has depth %d that exceeds max introspection depth %d", f.Name.Name, shape.depth+1, max)
+			}
+			if max := c.limits.MaxOfTypeDepth; max != 0 && shape.ofType > max {
+				exceeded = true
+				c.addErr(f.Alias.Loc, "MaxOfTypeDepthExceeded", "Introspection field %q nests %d ofType fields, which exceeds max ofType depth %d", f.Name.Name, shape.ofType, max)
+			}
+		}
+	}
+	return exceeded
+}
+
+// rootFields appends the fields of the selections to fields, including the fields of fragments.
+func rootFields(doc *query.Document, sels []query.Selection, visited map[*query.FragmentDecl]bool, fields []*query.Field) []*query.Field {
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *query.Field:
+			fields = append(fields, sel)
+		case *query.InlineFragment:
+			fields = rootFields(doc, sel.Selections, visited, fields)
+		case *query.FragmentSpread:
+			frag := doc.Fragments.Get(sel.Name.Name)
+			if frag == nil || visited[frag] {
+				continue
+			}
+			visited[frag] = true
+			fields = rootFields(doc, frag.Selections, visited, fields)
+		}
+	}
+	return fields
+}
+
+// selectionShape is the field nesting depth of a selection set and the largest number of ofType
+// fields nested within it.
+type selectionShape struct {
+	depth  int
+	ofType int
+}
+
+type shapeWalker struct {
+	doc      *query.Document
+	shapes   map[*query.FragmentDecl]selectionShape
+	visiting map[*query.FragmentDecl]bool
+}
+
+// shape returns the shape of the selections. Fragments within a cycle are ignored, as cycles are
+// reported by NoFragmentCycles.
+func (w *shapeWalker) shape(sels []query.Selection) selectionShape {
+	var s selectionShape
+	for _, sel := range sels {
+		var child selectionShape
+		switch sel := sel.(type) {
+		case *query.Field:
+			child = w.shape(sel.Selections)
+			child.depth++
+			if sel.Name.Name == "ofType" {
+				child.ofType++
+			}
+		case *query.InlineFragment:
+			child = w.shape(sel.Selections)
+		case *query.FragmentSpread:
+			frag := w.doc.Fragments.Get(sel.Name.Name)
+			if frag == nil || w.visiting[frag] {
+				continue
+			}
+			var ok bool
+			if child, ok = w.shapes[frag]; !ok {
+				w.visiting[frag] = true
+				child = w.shape(frag.Selections)
+				delete(w.visiting, frag)
+				w.shapes[frag] = child
+			}
+		}
+		if child.depth > s.depth {
+			s.depth = child.depth
+		}
+		if child.ofType > s.ofType {
+			s.ofType = child.ofType
+		}
+	}
+	return s
+}
@@ -0,0 +1,121 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/graph-gophers/graphql-go/internal/query"
+	"github.com/graph-gophers/graphql-go/internal/schema"
+)
+
+func TestMaxFragmentDepth(t *testing.T) {
+	s := schema.New()
+	if err := s.Parse(simpleSchema, false); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every fragment spreads the next one twice, so expanding the spreads takes 2^n steps.
+	const n = 64
+	var b strings.Builder
+	b.WriteString("{ characters { ...f0 } }\n")
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, "fragment f%d on Character { id ...f%d ...f%d }\n", i, i+1, i+1)
+	}
+	fmt.Fprintf(&b, "fragment f%d on Character { name }\n", n)
+
+	doc, qErr := query.Parse(b.String())
+	if qErr != nil {
+		t.Fatal(qErr)
+	}
+
+	for _, tc := range []struct {
+		name  string
+		limit int
+		errs  int
+	}{
+		{name: "off", limit: 0, errs: 0},
+		{name: "maxFragmentDepth", limit: n + 1, errs: 0},
+		{name: "maxFragmentDepth-1", limit: n, errs: 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// The max depth is set so the document is not validated further than the fragment depth.
+			errs := ValidateWithLimits(s, doc, nil, Limits{MaxDepth: 1, MaxFragmentDepth: tc.limit})
+			if tc.errs == 0 {
+				for _, err := range errs {
+					if err.Rule == "MaxFragmentDepthExceeded" {
+						t.Errorf("unexpected error: %v", err)
+					}
+				}
+				return
+			}
+			if len(errs) != tc.errs || errs[0].Rule != "MaxFragmentDepthExceeded" {
+				t.Fatalf("expected %d MaxFragmentDepthExceeded errors, got %v", tc.errs, errs)
+			}
+			if len(errs[0].Locations) != tc.limit+1 {
+				t.Errorf("expected %d locations, got %d", tc.limit+1, len(errs[0].Locations))
+			}
+		})
+	}
+}
+
+func TestValueDepth(t *testing.T) {
+	for _, tc := range []struct {
+		val   interface{}
+		max   int
+		depth int
+	}{
+		{val: "scalar", max: 1, depth: 0},
+		{val: []interface{}{1, 2}, max: 1, depth: 1},
+		{val: map[string]interface{}{"a": []interface{}{1}}, max: 2, depth: 2},
+		{val: []interface{}{[]interface{}{[]interface{}{[]interface{}{1}}}}, max: 1, depth: 2},
+	} {
+		if depth := valueDepth(tc.val, tc.max); depth != tc.depth {
+			t.Errorf("valueDepth(%v, %d) = %d, want %d", tc.val, tc.max, depth, tc.depth)
+		}
+	}
+}
+
+func TestIntrospectionLimits(t *testing.T) {
+	s := schema.New()
+	if err := s.Parse(simpleSchema, false); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every fragment spreads the next one twice, so the shape of each is only computed once.
+	const n = 64
+	var b strings.Builder
+	b.WriteString("{ __schema { types { ...f0 } } }\n")
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, "fragment f%d on __Type { fields { type { ...f%d } } ofType { ...f%d } }\n", i, i+1, i+1)
+	}
+	fmt.Fprintf(&b, "fragment f%d on __Type { name }\n", n)
+
+	doc, qErr := query.Parse(b.String())
+	if qErr != nil {
+		t.Fatal(qErr)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		limits Limits
+		rules  []string
+	}{
+		{name: "depth", limits: Limits{MaxIntrospectionDepth: 2*n + 3}},
+		{name: "depth-1", limits: Limits{MaxIntrospectionDepth: 2*n + 2}, rules: []string{"MaxIntrospectionDepthExceeded"}},
+		{name: "ofType", limits: Limits{MaxOfTypeDepth: n}},
+		{name: "ofType-1", limits: Limits{MaxOfTypeDepth: n - 1}, rules: []string{"MaxOfTypeDepthExceeded"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var rules []string
+			for _, err := range ValidateWithLimits(s, doc, nil, tc.limits) {
+				if strings.HasPrefix(err.Rule, "Max") {
+					rules = append(rules, err.Rule)
+				}
+			}
+			if fmt.Sprint(rules) != fmt.Sprint(tc.rules) {
+				t.Errorf("got errors %v, want %v", rules, tc.rules)
+			}
+		})
+	}
+}
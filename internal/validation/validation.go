@@ -31,7 +31,10 @@ type context struct {
 	usedVars         map[*query.Operation]varSet
 	fieldMap         map[*query.Field]fieldInfo
 	overlapValidated map[selectionPair]struct{}
-	maxDepth         int
+	limits           Limits
+	variables        map[string]interface{}
+	operationName    string
+	withoutVariables bool
 }
 
 func (c *context) addErr(loc errors.Location, rule string, format string, a ...interface{}) {
@@ -51,7 +54,7 @@ type opContext struct {
 	ops []*query.Operation
 }
 
-func newContext(s *schema.Schema, doc *query.Document, maxDepth int) *context {
+func newContext(s *schema.Schema, doc *query.Document, limits Limits) *context {
 	return &context{
 		schema:           s,
 		doc:              doc,
@@ -59,12 +62,104 @@ func newContext(s *schema.Schema, doc *query.Document, maxDepth int) *context {
 		usedVars:         make(map[*query.Operation]varSet),
 		fieldMap:         make(map[*query.Field]fieldInfo),
 		overlapValidated: make(map[selectionPair]struct{}),
-		maxDepth:         maxDepth,
+		limits:           limits,
 	}
 }
 
 func Validate(s *schema.Schema, doc *query.Document, variables map[string]interface{}, maxDepth int) []*errors.QueryError {
-	c := newContext(s, doc, maxDepth)
+	return ValidateWithLimits(s, doc, variables, Limits{MaxDepth: maxDepth})
+}
+
+// ValidateWithLimits validates the document like Validate. If the fragment depth, input depth or
+// an introspection limit is exceeded, it returns the errors without validating the rest of the
+// document.
+func ValidateWithLimits(s *schema.Schema, doc *query.Document, variables map[string]interface{}, limits Limits) []*errors.QueryError {
+	return ValidateOperation(s, doc, "", variables, limits)
+}
+
+// ValidateOperation validates the document like ValidateWithLimits, but the variables are the
+// values of the operation with the given name, so the variables of the other operations of the
+// document are not validated. An empty name validates the variables of all operations.
+func ValidateOperation(s *schema.Schema, doc *query.Document, operationName string, variables map[string]interface{}, limits Limits) []*errors.QueryError {
+	c := newContext(s, doc, limits)
+	c.variables = variables
+	c.operationName = operationName
+	return validate(c)
+}
+
+// ValidateDocument validates the document like ValidateWithLimits, but without the values of the
+// variables, e.g. a stored document whose operations are executed later.
+func ValidateDocument(s *schema.Schema, doc *query.Document, limits Limits) []*errors.QueryError {
+	c := newContext(s, doc, limits)
+	c.withoutVariables = true
+	return validate(c)
+}
+
+// ValidateVariables validates the values of the variables of an operation of a document that was
+// validated with ValidateDocument, i.e. only the rules depending on the values of the variables.
+func ValidateVariables(s *schema.Schema, doc *query.Document, op *query.Operation, variables map[string]interface{}, limits Limits) []*errors.QueryError {
+	c := newContext(s, doc, limits)
+	c.variables = variables
+	opc := &opContext{c, []*query.Operation{op}}
+	for _, v := range op.Vars {
+		val, ok := variables[v.Name.Name]
+		if ok && limits.MaxInputDepth != 0 && valueDepth(val, limits.MaxInputDepth) > limits.MaxInputDepth {
+			c.addErr(v.Loc, "MaxInputDepthExceeded", "Variable %q has a value nested deeper than max input depth %d", "$"+v.Name.Name, limits.MaxInputDepth)
+			return c.errs
+		}
+	}
+	for _, v := range op.Vars {
+		if t := resolveType(c, v.Type); t != nil {
+			validateValue(opc, v, nil, variables[v.Name.Name], t)
+		}
+	}
+	if s.Pagination != nil && !s.Pagination.Cap {
+		entryPoint := s.EntryPoints[strings.ToLower(string(op.Type))]
+		validateVariablePagination(opc, op.Selections, entryPoint, make(map[string]bool))
+	}
+	return c.errs
+}
+
+// validateVariablePagination checks the pagination arguments of the fields of a validated
+// selection set, whose values may be variables.
+func validateVariablePagination(c *opContext, sels []query.Selection, t schema.NamedType, visited map[string]bool) {
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *query.Field:
+			f := fields(t).Get(sel.Name.Name)
+			if f == nil {
+				continue // a meta field
+			}
+			validatePagination(c, sel, f)
+			if sel.Selections != nil {
+				validateVariablePagination(c, sel.Selections, unwrapType(f.Type), visited)
+			}
+		case *query.InlineFragment:
+			fragTyp := t
+			if sel.On.Name != "" {
+				fragTyp = c.schema.Types[sel.On.Name]
+			}
+			validateVariablePagination(c, sel.Selections, fragTyp, visited)
+		case *query.FragmentSpread:
+			if visited[sel.Name.Name] {
+				continue
+			}
+			visited[sel.Name.Name] = true
+			frag := c.doc.Fragments.Get(sel.Name.Name)
+			validateVariablePagination(c, frag.Selections, c.schema.Types[frag.On.Name], visited)
+		}
+	}
+}
+
+func validate(c *context) []*errors.QueryError {
+	s, doc, variables := c.schema, c.doc, c.variables
+
+	// These limits are checked first, as the rules below recurse into fragments and values.
+	fragmentDepthExceeded := validateFragmentDepth(c)
+	inputDepthExceeded := validateInputDepth(c)
+	if introspectionExceeded := validateIntrospectionLimits(c); fragmentDepthExceeded || inputDepthExceeded || introspectionExceeded {
+		return c.errs
+	}
 
 	opNames := make(nameSet)
 	fragUsedBy := make(map[*query.FragmentDecl][]*query.Operation)
@@ -74,7 +169,7 @@ func Validate(s *schema.Schema, doc *query.Document, variables map[string]interf
 
 		// Check if max depth is exceeded, if it's set. If max depth is exceeded,
 		// don't continue to validate the document and exit early.
-		if validateMaxDepth(opc, op.Selections, 1) {
+		if validateMaxDepth(opc, op.Selections, make(map[*query.FragmentDecl]struct{}), 1) {
 			return c.errs
 		}
 
@@ -95,7 +190,9 @@ func Validate(s *schema.Schema, doc *query.Document, variables map[string]interf
 			if !canBeInput(t) {
 				c.addErr(v.TypeLoc, "VariablesAreInputTypes", "Variable %q cannot be non-input type %q.", "$"+v.Name.Name, t)
 			}
-			validateValue(opc, v, variables[v.Name.Name], t)
+			if opc.knowsVariables() {
+				validateValue(opc, v, nil, variables[v.Name.Name], t)
+			}
 
 			if v.Default != nil {
 				validateLiteral(opc, v.Default)
@@ -179,14 +276,16 @@ func Validate(s *schema.Schema, doc *query.Document, variables map[string]interf
 	return c.errs
 }
 
-func validateValue(c *opContext, v *common.InputValue, val interface{}, t common.Type) {
+// validateValue validates the value of the variable v at the given path within the value, e.g.
+// ["items", "3", "price"] for $input.items[3].price.
+func validateValue(c *opContext, v *common.InputValue, path []string, val interface{}, t common.Type) {
 	switch t := t.(type) {
 	case *common.NonNull:
 		if val == nil {
-			c.addErr(v.Loc, "VariablesOfCorrectType", "Variable \"%s\" has invalid value null.\nExpected type \"%s\", found null.", v.Name.Name, t)
+			c.addVariableErr(v, path, t, "%s has invalid value null.\nExpected type \"%s\", found null.", variableSubject(v, path), t)
 			return
 		}
-		validateValue(c, v, val, t.OfType)
+		validateValue(c, v, path, val, t.OfType)
 	case *common.List:
 		if val == nil {
 			return
@@ -194,11 +293,23 @@ func validateValue(c *opContext, v *common.InputValue, val interface{}, t common
 		vv, ok := val.([]interface{})
 		if !ok {
 			// Input coercion rules allow single items without wrapping array
-			validateValue(c, v, val, t.OfType)
+			validateValue(c, v, path, val, t.OfType)
+			return
+		}
+		for i, elem := range vv {
+			validateValue(c, v, append(path[:len(path):len(path)], strconv.Itoa(i)), elem, t.OfType)
+		}
+	case *schema.Scalar:
+		if val == nil || (t.Name != "Int" && t.Name != "Float") {
 			return
 		}
-		for _, elem := range vv {
-			validateValue(c, v, elem, t.OfType)
+		f, ok := numberValue(val)
+		if !ok {
+			c.addVariableErr(v, path, t, "%s has invalid type %T.\nExpected type \"%s\", found %v.", variableSubject(v, path), val, t, val)
+			return
+		}
+		if t.Name == "Int" && (f < math.MinInt32 || f > math.MaxInt32 || (f != math.Trunc(f) && !c.schema.LegacyNumericCoercion)) {
+			c.addVariableErr(v, path, t, "%s has invalid value %v.\nExpected type \"%s\", found %v.", variableSubject(v, path), val, t, val)
 		}
 	case *schema.Enum:
 		if val == nil {
@@ -206,7 +317,7 @@ func validateValue(c *opContext, v *common.InputValue, val interface{}, t common
 		}
 		e, ok := val.(string)
 		if !ok {
-			c.addErr(v.Loc, "VariablesOfCorrectType", "Variable \"%s\" has invalid type %T.\nExpected type \"%s\", found %v.", v.Name.Name, val, t, val)
+			c.addVariableErr(v, path, t, "%s has invalid type %T.\nExpected type \"%s\", found %v.", variableSubject(v, path), val, t, val)
 			return
 		}
 		for _, option := range t.Values {
@@ -214,28 +325,68 @@ func validateValue(c *opContext, v *common.InputValue, val interface{}, t common
 				return
 			}
 		}
-		c.addErr(v.Loc, "VariablesOfCorrectType", "Variable \"%s\" has invalid value %s.\nExpected type \"%s\", found %s.", v.Name.Name, e, t, e)
+		c.addVariableErr(v, path, t, "%s has invalid value %s.\nExpected type \"%s\", found %s.", variableSubject(v, path), e, t, e)
 	case *schema.InputObject:
 		if val == nil {
 			return
 		}
 		in, ok := val.(map[string]interface{})
 		if !ok {
-			c.addErr(v.Loc, "VariablesOfCorrectType", "Variable \"%s\" has invalid type %T.\nExpected type \"%s\", found %s.", v.Name.Name, val, t, val)
+			c.addVariableErr(v, path, t, "%s has invalid type %T.\nExpected type \"%s\", found %s.", variableSubject(v, path), val, t, val)
 			return
 		}
 		for _, f := range t.Values {
 			fieldVal := in[f.Name.Name]
-			validateValue(c, f, fieldVal, f.Type)
+			validateValue(c, v, append(path[:len(path):len(path)], f.Name.Name), fieldVal, f.Type)
+		}
+		if t.OneOf() {
+			given := 0
+			for _, f := range t.Values {
+				if in[f.Name.Name] != nil {
+					given++
+				}
+			}
+			if given != 1 || len(in) != 1 {
+				c.addVariableErr(v, path, t, "%s has invalid value.\nExpected exactly one non-null field of oneOf input \"%s\".", variableSubject(v, path), t)
+			}
 		}
 	}
 }
 
+// variableSubject names the variable v, and the path of the invalid value within it if the value
+// is nested.
+func variableSubject(v *common.InputValue, path []string) string {
+	if len(path) == 0 {
+		return fmt.Sprintf("Variable \"%s\"", v.Name.Name)
+	}
+	return fmt.Sprintf("Variable \"%s\" at %q", v.Name.Name, variablePointer(v, path))
+}
+
+// addVariableErr reports an invalid value of the variable v. The "pointer" extension locates the
+// value in the variables of the request as a JSON Pointer, e.g. "/input/items/3/price", and the
+// "expectedType" extension is the type expected at that location.
+func (c *opContext) addVariableErr(v *common.InputValue, path []string, expected common.Type, format string, a ...interface{}) {
+	c.addErr(v.Loc, "VariablesOfCorrectType", format, a...)
+	c.errs[len(c.errs)-1].Extensions = map[string]interface{}{
+		"pointer":      variablePointer(v, path),
+		"expectedType": expected.String(),
+	}
+}
+
+func variablePointer(v *common.InputValue, path []string) string {
+	tokens := []interface{}{v.Name.Name}
+	for _, token := range path {
+		tokens = append(tokens, token)
+	}
+	return common.JSONPointer(tokens...)
+}
+
 // validates the query doesn't go deeper than maxDepth (if set). Returns whether
-// or not query validated max depth to avoid excessive recursion.
-func validateMaxDepth(c *opContext, sels []query.Selection, depth int) bool {
+// or not query validated max depth to avoid excessive recursion. Fragments already
+// on the spread path are skipped, as their cycles are reported by NoFragmentCycles.
+func validateMaxDepth(c *opContext, sels []query.Selection, visited map[*query.FragmentDecl]struct{}, depth int) bool {
 	// maxDepth checking is turned off when maxDepth is 0
-	if c.maxDepth == 0 {
+	if c.limits.MaxDepth == 0 {
 		return false
 	}
 
@@ -244,16 +395,16 @@ func validateMaxDepth(c *opContext, sels []query.Selection, depth int) bool {
 	for _, sel := range sels {
 		switch sel := sel.(type) {
 		case *query.Field:
-			if depth > c.maxDepth {
+			if depth > c.limits.MaxDepth {
 				exceededMaxDepth = true
-				c.addErr(sel.Alias.Loc, "MaxDepthExceeded", "Field %q has depth %d that exceeds max depth %d", sel.Name.Name, depth, c.maxDepth)
+				c.addErr(sel.Alias.Loc, "MaxDepthExceeded", "Field %q has depth %d that exceeds max depth %d", sel.Name.Name, depth, c.limits.MaxDepth)
 				continue
 			}
-			exceededMaxDepth = exceededMaxDepth || validateMaxDepth(c, sel.Selections, depth+1)
+			exceededMaxDepth = exceededMaxDepth || validateMaxDepth(c, sel.Selections, visited, depth+1)
 		case *query.InlineFragment:
 			// Depth is not checked because inline fragments resolve to other fields which are checked.
 			// Depth is not incremented because inline fragments have the same depth as neighboring fields
-			exceededMaxDepth = exceededMaxDepth || validateMaxDepth(c, sel.Selections, depth)
+			exceededMaxDepth = exceededMaxDepth || validateMaxDepth(c, sel.Selections, visited, depth)
 		case *query.FragmentSpread:
 			// Depth is not checked because fragments resolve to other fields which are checked.
 			frag := c.doc.Fragments.Get(sel.Name.Name)
@@ -262,8 +413,13 @@ func validateMaxDepth(c *opContext, sels []query.Selection, depth int) bool {
 				c.addErr(sel.Loc, "MaxDepthEvaluationError", "Unknown fragment %q. Unable to evaluate depth.", sel.Name.Name)
 				continue
 			}
+			if _, ok := visited[frag]; ok {
+				continue
+			}
+			visited[frag] = struct{}{}
 			// Depth is not incremented because fragments have the same depth as surrounding fields
-			exceededMaxDepth = exceededMaxDepth || validateMaxDepth(c, frag.Selections, depth)
+			exceededMaxDepth = exceededMaxDepth || validateMaxDepth(c, frag.Selections, visited, depth)
+			delete(visited, frag)
 		}
 	}
 
@@ -328,6 +484,10 @@ func validateSelection(c *opContext, sel query.Selection, t schema.NamedType) {
 			)
 		}
 
+		if f != nil && c.schema.Pagination != nil && !c.schema.Pagination.Cap {
+			validatePagination(c, sel, f)
+		}
+
 		var ft common.Type
 		if f != nil {
 			ft = f.Type
@@ -376,6 +536,88 @@ func validateSelection(c *opContext, sel query.Selection, t schema.NamedType) {
 	}
 }
 
+// validatePagination checks that a list field declaring pagination arguments is selected with one
+// of them and that their values do not exceed the maximum page size of the schema. Values of
+// variables are only checked if the variables are known.
+func validatePagination(c *opContext, sel *query.Field, f *schema.Field) {
+	t := f.Type
+	if nn, ok := t.(*common.NonNull); ok {
+		t = nn.OfType
+	}
+	if _, ok := t.(*common.List); !ok {
+		return
+	}
+
+	p := c.schema.Pagination
+	var declared []string
+	paginated := false
+	for _, name := range p.Arguments {
+		decl := f.Args.Get(name)
+		if decl == nil {
+			continue
+		}
+		declared = append(declared, name)
+
+		var value interface{}
+		loc := sel.Alias.Loc
+		if arg, ok := sel.Arguments.Get(name); ok {
+			loc = arg.Location()
+			if v, ok := arg.(*common.Variable); ok {
+				if !c.knowsVariables() {
+					paginated = true
+					continue
+				}
+				value = c.variableValue(v.Name)
+			} else {
+				value = arg.Value(nil)
+			}
+		} else if decl.Default != nil {
+			value = decl.Default.Value(nil)
+		}
+		if value == nil {
+			continue
+		}
+		paginated = true
+		if n, ok := numberValue(value); ok && n > float64(p.MaxPageSize) {
+			c.addErr(loc, "Pagination", "Argument %q of field %q must not exceed %d, got %v.", name, sel.Name.Name, p.MaxPageSize, value)
+		}
+	}
+	if len(declared) != 0 && !paginated {
+		c.addErr(sel.Alias.Loc, "Pagination", "Field %q must be paginated with one of the arguments %s.", sel.Name.Name, strings.Join(declared, ", "))
+	}
+}
+
+// knowsVariables reports whether the values of the variables of the operations are known, which is
+// the case unless the document is validated without them or another operation is executed.
+func (c *opContext) knowsVariables() bool {
+	if c.withoutVariables {
+		return false
+	}
+	if c.operationName == "" {
+		return true
+	}
+	for _, op := range c.ops {
+		if op.Name.Name == c.operationName {
+			return true
+		}
+	}
+	return false
+}
+
+// variableValue returns the value of the variable of the given name, or its default value if the
+// variables do not contain it.
+func (c *opContext) variableValue(name string) interface{} {
+	if v, ok := c.variables[name]; ok {
+		return v
+	}
+	for _, op := range c.ops {
+		if decl := op.Vars.Get(name); decl != nil && decl.Default != nil {
+			return decl.Default.Value(nil)
+		}
+	}
+	return nil
+}
+
 func compatible(a, b common.Type) bool {
 	for _, pta := range possibleTypes(a) {
 		for _, ptb := range possibleTypes(b) {
@@ -774,6 +1016,11 @@ func validateValueType(c *opContext, v common.Literal, t common.Type) (bool, str
 
 	switch t := t.(type) {
 	case *schema.Scalar, *schema.Enum:
+		if s, ok := t.(*schema.Scalar); ok && !isBuiltinScalar(s.Name) {
+			// Custom scalars accept literals of any kind, which are checked by their UnmarshalGraphQL
+			// method.
+			return true, ""
+		}
 		if lit, ok := v.(*common.BasicLit); ok {
 			if validateBasicLit(lit, t) {
 				return true, ""
@@ -821,6 +1068,14 @@ func validateValueType(c *opContext, v common.Literal, t common.Type) (bool, str
 				}
 			}
 		}
+		if t.OneOf() {
+			if len(v.Fields) != 1 {
+				return false, fmt.Sprintf("Expected exactly one field of oneOf input %q, found %d.", t, len(v.Fields))
+			}
+			if _, ok := v.Fields[0].Value.(*common.NullLit); ok {
+				return false, fmt.Sprintf("In field %q: Expected a non-null value for oneOf input %q.", v.Fields[0].Name.Name, t)
+			}
+		}
 		return true, ""
 	}
 
@@ -912,6 +1167,31 @@ func isLeaf(t common.Type) bool {
 	}
 }
 
+// numberValue returns the value of a number decoded from JSON or passed by a Go caller.
+func numberValue(val interface{}) (float64, bool) {
+	switch val := val.(type) {
+	case float64:
+		return val, true
+	case float32:
+		return float64(val), true
+	case int:
+		return float64(val), true
+	case int32:
+		return float64(val), true
+	case int64:
+		return float64(val), true
+	}
+	return 0, false
+}
+
+func isBuiltinScalar(name string) bool {
+	switch name {
+	case "Int", "Float", "String", "Boolean", "ID":
+		return true
+	}
+	return false
+}
+
 func isNull(lit interface{}) bool {
 	_, ok := lit.(*common.NullLit)
 	return ok
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 
 	"github.com/graph-gophers/graphql-go/internal/exec/resolvable"
+	"github.com/graph-gophers/graphql-go/internal/query"
 	"github.com/graph-gophers/graphql-go/introspection"
 )
 
@@ -13,15 +14,36 @@ func (s *Schema) Inspect() *introspection.Schema {
 	return introspection.WrapSchema(s.schema)
 }
 
-// ToJSON encodes the schema in a JSON format used by tools like Relay.
+// SchemaDirectives returns the directives applied to the schema definition and its extensions,
+// e.g. the @link directives of a federated schema declared with `extend schema @link(url: "...")`.
+func (s *Schema) SchemaDirectives() []*Directive {
+	var directives []*Directive
+	for _, d := range s.Inspect().AppliedDirectives() {
+		directives = append(directives, &Directive{
+			Name:       d.Name(),
+			Arguments:  d.Arguments(),
+			Definition: true,
+		})
+	}
+	return directives
+}
+
+// ToJSON encodes the schema in a JSON format used by tools like Relay. The limits of the schema on
+// the operations of clients do not apply to its introspection query.
 func (s *Schema) ToJSON() ([]byte, error) {
-	result := s.exec(context.Background(), introspectionQuery, "", nil, &resolvable.Schema{
+	// The introspection query is not a document of a client, so it is parsed without the limits of
+	// the schema.
+	doc, qErr := query.Parse(introspectionQuery)
+	if qErr != nil {
+		panic(qErr)
+	}
+	result := s.execDocument(context.Background(), introspectionQuery, doc, false, "", nil, &resolvable.Schema{
 		Meta:   s.res.Meta,
 		Query:  &resolvable.Object{},
 		Schema: *s.schema,
 	})
 	if len(result.Errors) != 0 {
-		panic(result.Errors[0])
+		return nil, result.Errors[0]
 	}
 	return json.MarshalIndent(result.Data, "", "\t")
 }
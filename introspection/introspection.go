@@ -44,6 +44,16 @@ func (r *Schema) Directives() []*Directive {
 	return l
 }
 
+// AppliedDirectives returns the directives applied to the schema definition and its extensions,
+// e.g. @link.
+func (r *Schema) AppliedDirectives() []*AppliedDirective {
+	l := make([]*AppliedDirective, len(r.schema.SchemaDirectives))
+	for i, d := range r.schema.SchemaDirectives {
+		l[i] = &AppliedDirective{d}
+	}
+	return l
+}
+
 func (r *Schema) QueryType() *Type {
 	t, ok := r.schema.EntryPoints["query"]
 	if !ok {
@@ -121,13 +131,18 @@ func (r *Type) Fields(args *struct{ IncludeDeprecated bool }) *[]*Field {
 }
 
 func (r *Type) Interfaces() *[]*Type {
-	t, ok := r.typ.(*schema.Object)
-	if !ok {
+	var interfaces []*schema.Interface
+	switch t := r.typ.(type) {
+	case *schema.Object:
+		interfaces = t.Interfaces
+	case *schema.Interface:
+		interfaces = t.Interfaces
+	default:
 		return nil
 	}
 
-	l := make([]*Type, len(t.Interfaces))
-	for i, intf := range t.Interfaces {
+	l := make([]*Type, len(interfaces))
+	for i, intf := range interfaces {
 		l[i] = &Type{intf}
 	}
 	return &l
@@ -214,6 +229,9 @@ func (r *Field) Args() []*InputValue {
 }
 
 func (r *Field) Type() *Type {
+	if r.field.IntrospectionType != nil {
+		return &Type{r.field.IntrospectionType}
+	}
 	return &Type{r.field.Type}
 }
 
@@ -304,6 +322,11 @@ func (r *Directive) Locations() []string {
 	return r.directive.Locs
 }
 
+// IsRepeatable reports whether the directive may be applied to a location more than once.
+func (r *Directive) IsRepeatable() bool {
+	return r.directive.Repeatable
+}
+
 func (r *Directive) Args() []*InputValue {
 	l := make([]*InputValue, len(r.directive.Args))
 	for i, v := range r.directive.Args {
@@ -311,3 +334,26 @@ func (r *Directive) Args() []*InputValue {
 	}
 	return l
 }
+
+// AppliedDirective is a directive applied in the schema definition.
+type AppliedDirective struct {
+	directive *common.Directive
+}
+
+func (r *AppliedDirective) Name() string {
+	return r.directive.Name.Name
+}
+
+// Arguments returns the values of the arguments of the directive, including the default values of
+// omitted arguments, by name.
+func (r *AppliedDirective) Arguments() map[string]interface{} {
+	args := make(map[string]interface{}, len(r.directive.Args))
+	for _, arg := range r.directive.Args {
+		if arg.Value != nil {
+			args[arg.Name.Name] = arg.Value.Value(nil)
+		} else {
+			args[arg.Name.Name] = nil
+		}
+	}
+	return args
+}
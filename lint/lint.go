@@ -0,0 +1,136 @@
+// Package lint checks a schema against a set of configurable style rules, e.g. naming conventions,
+// missing descriptions or unused types.
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/introspection"
+)
+
+// Problem is a violation of a rule.
+type Problem struct {
+	// Rule is the name of the violated rule.
+	Rule string
+
+	// Location is the schema coordinate of the problem, e.g. "Query.hero" or "Episode.JEDI".
+	Location string
+
+	Message string
+}
+
+func (p *Problem) String() string {
+	return fmt.Sprintf("%s: %s (%s)", p.Location, p.Message, p.Rule)
+}
+
+// Rule is a single check of the linter.
+type Rule struct {
+	Name        string
+	Description string
+
+	// Default reports whether the rule is enabled if the configuration does not mention it.
+	Default bool
+
+	Check func(s *introspection.Schema, report Reporter)
+}
+
+// Reporter records a problem at the given location.
+type Reporter func(location string, format string, args ...interface{})
+
+// Config enables or disables rules by their name. Rules that are not mentioned use their default.
+type Config struct {
+	Rules map[string]bool
+}
+
+// Enabled reports whether the rule is enabled by the configuration.
+func (c *Config) Enabled(r *Rule) bool {
+	if c != nil {
+		if enabled, ok := c.Rules[r.Name]; ok {
+			return enabled
+		}
+	}
+	return r.Default
+}
+
+// Lint checks the schema with all rules enabled by cfg, which may be nil to use the defaults.
+// Problems are sorted by location and rule.
+func Lint(s *graphql.Schema, cfg *Config) ([]*Problem, error) {
+	if cfg != nil {
+		for name := range cfg.Rules {
+			if Lookup(name) == nil {
+				return nil, fmt.Errorf("unknown lint rule %q", name)
+			}
+		}
+	}
+
+	schema := s.Inspect()
+	var problems []*Problem
+	for _, r := range Rules {
+		if !cfg.Enabled(r) {
+			continue
+		}
+		rule := r.Name
+		r.Check(schema, func(location string, format string, args ...interface{}) {
+			problems = append(problems, &Problem{Rule: rule, Location: location, Message: fmt.Sprintf(format, args...)})
+		})
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].Location != problems[j].Location {
+			return problems[i].Location < problems[j].Location
+		}
+		return problems[i].Rule < problems[j].Rule
+	})
+	return problems, nil
+}
+
+// Lookup returns the rule with the given name, or nil.
+func Lookup(name string) *Rule {
+	for _, r := range Rules {
+		if r.Name == name {
+			return r
+		}
+	}
+	return nil
+}
+
+var includeDeprecated = &struct{ IncludeDeprecated bool }{true}
+
+// userTypes returns the types of the schema, without the introspection types and the predeclared
+// scalars.
+func userTypes(s *introspection.Schema) []*introspection.Type {
+	var types []*introspection.Type
+	for _, t := range s.Types() {
+		name := *t.Name()
+		if strings.HasPrefix(name, "__") || builtinScalars[name] {
+			continue
+		}
+		types = append(types, t)
+	}
+	return types
+}
+
+var builtinScalars = map[string]bool{
+	"Int":     true,
+	"Float":   true,
+	"String":  true,
+	"Boolean": true,
+	"ID":      true,
+}
+
+func fields(t *introspection.Type) []*introspection.Field {
+	if f := t.Fields(includeDeprecated); f != nil {
+		return *f
+	}
+	return nil
+}
+
+func namedType(t *introspection.Type) *introspection.Type {
+	for t.OfType() != nil {
+		t = t.OfType()
+	}
+	return t
+}
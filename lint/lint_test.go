@@ -0,0 +1,92 @@
+package lint_test
+
+import (
+	"reflect"
+	"testing"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/lint"
+)
+
+const schemaString = `
+	schema {
+		query: Query
+	}
+
+	# The query type.
+	type Query {
+		user_by_id(ID: ID!): User
+		friends(first: Int): FriendsConnection!
+		users: [User!]!
+	}
+
+	# A user.
+	type User {
+		name: String!
+		status: Status!
+	}
+
+	# The status of a user.
+	enum Status {
+		ACTIVE
+		inactive
+	}
+
+	# A page of friends.
+	type FriendsConnection {
+		edges: [User!]!
+	}
+
+	type Orphan {
+		name: String
+	}
+`
+
+func problems(t *testing.T, cfg *lint.Config) []string {
+	t.Helper()
+	l, err := lint.Lint(graphql.MustParseSchema(schemaString, nil), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s []string
+	for _, p := range l {
+		s = append(s, p.String())
+	}
+	return s
+}
+
+func TestLint(t *testing.T) {
+	want := []string{
+		`FriendsConnection: connection type has no pageInfo field of type PageInfo! (relay-connection-types)`,
+		`Orphan: type is not reachable from the root operation types (no-unused-types)`,
+		`Orphan: type has no description (types-have-descriptions)`,
+		`Query.friends: connection field has neither first and after, nor last and before arguments (relay-connection-arguments)`,
+		`Query.user_by_id: field name is not camelCase (field-names-camel-case)`,
+		`Query.user_by_id(ID:): argument name is not camelCase (field-names-camel-case)`,
+		`Status.inactive: enum value is not ALL_CAPS (enum-values-all-caps)`,
+	}
+	if got := problems(t, nil); !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected problems:\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestLint_config(t *testing.T) {
+	got := problems(t, &lint.Config{Rules: map[string]bool{
+		"type-names-pascal-case":     false,
+		"field-names-camel-case":     false,
+		"enum-values-all-caps":       false,
+		"types-have-descriptions":    false,
+		"no-unused-types":            false,
+		"relay-connection-types":     false,
+		"relay-connection-arguments": false,
+		"lists-are-connections":      true,
+	}})
+	want := []string{`Query.users: list of objects is not paginated with a connection (lists-are-connections)`}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected problems:\ngot:  %q\nwant: %q", got, want)
+	}
+
+	if _, err := lint.Lint(graphql.MustParseSchema(schemaString, nil), &lint.Config{Rules: map[string]bool{"unknown": true}}); err == nil {
+		t.Error("expected an error for an unknown rule")
+	}
+}
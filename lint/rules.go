@@ -0,0 +1,255 @@
+package lint
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/graph-gophers/graphql-go/introspection"
+)
+
+// Rules are all rules known to the linter.
+var Rules = []*Rule{
+	{
+		Name:        "type-names-pascal-case",
+		Description: "Type names are written in PascalCase.",
+		Default:     true,
+		Check:       checkTypeNames,
+	},
+	{
+		Name:        "field-names-camel-case",
+		Description: "Field, argument and input field names are written in camelCase.",
+		Default:     true,
+		Check:       checkFieldNames,
+	},
+	{
+		Name:        "enum-values-all-caps",
+		Description: "Enum values are written in ALL_CAPS.",
+		Default:     true,
+		Check:       checkEnumValues,
+	},
+	{
+		Name:        "types-have-descriptions",
+		Description: "Every type has a description.",
+		Default:     true,
+		Check:       checkTypeDescriptions,
+	},
+	{
+		Name:        "fields-have-descriptions",
+		Description: "Every field has a description.",
+		Default:     false,
+		Check:       checkFieldDescriptions,
+	},
+	{
+		Name:        "no-unused-types",
+		Description: "Every type is reachable from the root operation types.",
+		Default:     true,
+		Check:       checkUnusedTypes,
+	},
+	{
+		Name:        "relay-connection-types",
+		Description: "Types named *Connection have the edges and pageInfo fields of a Relay connection.",
+		Default:     true,
+		Check:       checkConnectionTypes,
+	},
+	{
+		Name:        "relay-connection-arguments",
+		Description: "Fields returning a connection accept the first and after, or last and before arguments.",
+		Default:     true,
+		Check:       checkConnectionArguments,
+	},
+	{
+		Name:        "lists-are-connections",
+		Description: "Fields returning a list of objects are paginated with a connection.",
+		Default:     false,
+		Check:       checkListFields,
+	},
+}
+
+var (
+	pascalCase = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*$`)
+	camelCase  = regexp.MustCompile(`^[a-z][A-Za-z0-9]*$`)
+	allCaps    = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+)
+
+func checkTypeNames(s *introspection.Schema, report Reporter) {
+	for _, t := range userTypes(s) {
+		if !pascalCase.MatchString(*t.Name()) {
+			report(*t.Name(), "type name is not PascalCase")
+		}
+	}
+}
+
+func checkFieldNames(s *introspection.Schema, report Reporter) {
+	for _, t := range userTypes(s) {
+		for _, f := range fields(t) {
+			loc := *t.Name() + "." + f.Name()
+			if !camelCase.MatchString(f.Name()) {
+				report(loc, "field name is not camelCase")
+			}
+			for _, arg := range f.Args() {
+				if !camelCase.MatchString(arg.Name()) {
+					report(loc+"("+arg.Name()+":)", "argument name is not camelCase")
+				}
+			}
+		}
+		if t.InputFields() != nil {
+			for _, v := range *t.InputFields() {
+				if !camelCase.MatchString(v.Name()) {
+					report(*t.Name()+"."+v.Name(), "input field name is not camelCase")
+				}
+			}
+		}
+	}
+}
+
+func checkEnumValues(s *introspection.Schema, report Reporter) {
+	for _, t := range userTypes(s) {
+		if t.Kind() != "ENUM" {
+			continue
+		}
+		for _, v := range *t.EnumValues(includeDeprecated) {
+			if !allCaps.MatchString(v.Name()) {
+				report(*t.Name()+"."+v.Name(), "enum value is not ALL_CAPS")
+			}
+		}
+	}
+}
+
+func checkTypeDescriptions(s *introspection.Schema, report Reporter) {
+	for _, t := range userTypes(s) {
+		if isEmpty(t.Description()) {
+			report(*t.Name(), "type has no description")
+		}
+	}
+}
+
+func checkFieldDescriptions(s *introspection.Schema, report Reporter) {
+	for _, t := range userTypes(s) {
+		for _, f := range fields(t) {
+			if isEmpty(f.Description()) {
+				report(*t.Name()+"."+f.Name(), "field has no description")
+			}
+		}
+		if t.InputFields() != nil {
+			for _, v := range *t.InputFields() {
+				if isEmpty(v.Description()) {
+					report(*t.Name()+"."+v.Name(), "input field has no description")
+				}
+			}
+		}
+	}
+}
+
+func isEmpty(desc *string) bool {
+	return desc == nil || strings.TrimSpace(*desc) == ""
+}
+
+func checkUnusedTypes(s *introspection.Schema, report Reporter) {
+	used := make(map[string]bool)
+	var visit func(t *introspection.Type)
+	visit = func(t *introspection.Type) {
+		if t == nil {
+			return
+		}
+		t = namedType(t)
+		if used[*t.Name()] {
+			return
+		}
+		used[*t.Name()] = true
+		for _, f := range fields(t) {
+			visit(f.Type())
+			for _, arg := range f.Args() {
+				visit(arg.Type())
+			}
+		}
+		for _, l := range []*[]*introspection.Type{t.Interfaces(), t.PossibleTypes()} {
+			if l != nil {
+				for _, t := range *l {
+					visit(t)
+				}
+			}
+		}
+		if t.InputFields() != nil {
+			for _, v := range *t.InputFields() {
+				visit(v.Type())
+			}
+		}
+	}
+	visit(s.QueryType())
+	visit(s.MutationType())
+	visit(s.SubscriptionType())
+	for _, d := range s.Directives() {
+		for _, arg := range d.Args() {
+			visit(arg.Type())
+		}
+	}
+
+	for _, t := range userTypes(s) {
+		if !used[*t.Name()] {
+			report(*t.Name(), "type is not reachable from the root operation types")
+		}
+	}
+}
+
+func isConnection(t *introspection.Type) bool {
+	return t.Kind() == "OBJECT" && strings.HasSuffix(*t.Name(), "Connection")
+}
+
+func checkConnectionTypes(s *introspection.Schema, report Reporter) {
+	for _, t := range userTypes(s) {
+		if !isConnection(t) {
+			continue
+		}
+		has := make(map[string]*introspection.Field)
+		for _, f := range fields(t) {
+			has[f.Name()] = f
+		}
+		if f := has["edges"]; f == nil || !isList(f.Type()) {
+			report(*t.Name(), "connection type has no edges list")
+		}
+		if f := has["pageInfo"]; f == nil || f.Type().Kind() != "NON_NULL" || *namedType(f.Type()).Name() != "PageInfo" {
+			report(*t.Name(), "connection type has no pageInfo field of type PageInfo!")
+		}
+	}
+}
+
+func checkConnectionArguments(s *introspection.Schema, report Reporter) {
+	for _, t := range userTypes(s) {
+		for _, f := range fields(t) {
+			if !isConnection(namedType(f.Type())) {
+				continue
+			}
+			args := make(map[string]bool)
+			for _, arg := range f.Args() {
+				args[arg.Name()] = true
+			}
+			if !(args["first"] && args["after"]) && !(args["last"] && args["before"]) {
+				report(*t.Name()+"."+f.Name(), "connection field has neither first and after, nor last and before arguments")
+			}
+		}
+	}
+}
+
+func checkListFields(s *introspection.Schema, report Reporter) {
+	for _, t := range userTypes(s) {
+		if isConnection(t) || strings.HasSuffix(*t.Name(), "Edge") {
+			continue
+		}
+		for _, f := range fields(t) {
+			if !isList(f.Type()) {
+				continue
+			}
+			switch namedType(f.Type()).Kind() {
+			case "OBJECT", "INTERFACE", "UNION":
+				report(*t.Name()+"."+f.Name(), "list of objects is not paginated with a connection")
+			}
+		}
+	}
+}
+
+func isList(t *introspection.Type) bool {
+	if t.Kind() == "NON_NULL" {
+		t = t.OfType()
+	}
+	return t.Kind() == "LIST"
+}
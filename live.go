@@ -0,0 +1,229 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/graph-gophers/graphql-go/internal/exec"
+	"github.com/graph-gophers/graphql-go/internal/exec/resolvable"
+	"github.com/graph-gophers/graphql-go/internal/query"
+)
+
+// LiveQueryStore notifies live queries about changed data. Resolvers register the invalidation keys
+// of the data they read with RegisterInvalidationKeys, e.g. "User:42", and the store reports when
+// any of them is invalidated.
+//
+// Live queries are experimental.
+type LiveQueryStore interface {
+	// Watch returns a channel that receives a value when any of the keys is invalidated. The store
+	// stops using the channel when ctx is done.
+	Watch(ctx context.Context, keys []string) <-chan struct{}
+}
+
+// LiveQueries enables queries with the @live directive in Subscribe: instead of a single result,
+// the response channel receives a new result whenever an invalidation key registered by the
+// resolvers of the previous execution is invalidated in the store, until the context is done. A
+// result is only sent if it differs from the previous one. The channel is closed after the first
+// result if the resolvers register no keys. Exec executes live queries once like other queries.
+//
+// The directive has to be declared in the schema:
+//
+//	directive @live on QUERY
+//
+// Live queries are experimental.
+func LiveQueries(store LiveQueryStore) SchemaOpt {
+	return func(s *Schema) {
+		s.liveQueryStore = store
+	}
+}
+
+// RegisterInvalidationKeys registers keys identifying the data read by a resolver, e.g. "User:42",
+// with the live query executing the resolver. The query is executed again when any of the keys is
+// invalidated. Register the keys before reading the data, so that no invalidation is missed. It
+// does nothing if the resolver is not executed by a live query.
+func RegisterInvalidationKeys(ctx context.Context, keys ...string) {
+	if c, ok := ctx.Value(liveKeysKey{}).(*liveKeys); ok {
+		c.add(keys)
+	}
+}
+
+type liveKeysKey struct{}
+
+// liveKeys watches the invalidation keys registered during an execution of a live query from the
+// moment they are registered.
+type liveKeys struct {
+	ctx         context.Context
+	store       LiveQueryStore
+	invalidated chan struct{}
+
+	mu   sync.Mutex
+	keys map[string]bool
+}
+
+func newLiveKeys(ctx context.Context, store LiveQueryStore) *liveKeys {
+	return &liveKeys{
+		ctx:         ctx,
+		store:       store,
+		invalidated: make(chan struct{}, 1),
+		keys:        make(map[string]bool),
+	}
+}
+
+func (c *liveKeys) add(keys []string) {
+	c.mu.Lock()
+	var added []string
+	for _, key := range keys {
+		if !c.keys[key] {
+			c.keys[key] = true
+			added = append(added, key)
+		}
+	}
+	c.mu.Unlock()
+	if len(added) == 0 {
+		return
+	}
+
+	invalidated := c.store.Watch(c.ctx, added)
+	go func() {
+		select {
+		case <-invalidated:
+			select {
+			case c.invalidated <- struct{}{}:
+			default:
+			}
+		case <-c.ctx.Done():
+		}
+	}()
+}
+
+func (c *liveKeys) empty() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.keys) == 0
+}
+
+// validateLiveQueries checks that the @live directive is declared if live queries are enabled.
+func (s *Schema) validateLiveQueries() error {
+	if s.liveQueryStore == nil {
+		return nil
+	}
+	d, ok := s.schema.Directives["live"]
+	if !ok || len(d.Args) != 0 || len(d.Locs) != 1 || d.Locs[0] != "QUERY" {
+		return fmt.Errorf(`LiveQueries requires the declaration "directive @live on QUERY"`)
+	}
+	return nil
+}
+
+// isLiveQuery reports whether op is a query with the @live directive and live queries are enabled.
+func (s *Schema) isLiveQuery(op *query.Operation) bool {
+	return s.liveQueryStore != nil && op.Type == query.Query && op.Directives.Get("live") != nil
+}
+
+// liveQuery executes op and executes it again whenever an invalidation key registered by the
+// previous execution is invalidated, until ctx is done. The keys are watched until the next
+// execution starts.
+func (s *Schema) liveQuery(ctx context.Context, r *exec.Request, res *resolvable.Schema, op *query.Operation) <-chan interface{} {
+	c := make(chan interface{})
+	go func() {
+		defer close(c)
+		var last []byte
+		for {
+			watchCtx, cancel := context.WithCancel(ctx)
+			keys := newLiveKeys(watchCtx, s.liveQueryStore)
+			r.Errs = nil // the errors of the previous execution were sent with its result
+			data, errs := r.Execute(context.WithValue(ctx, liveKeysKey{}, keys), res, op)
+			if ctx.Err() != nil {
+				cancel()
+				return
+			}
+			resp := &Response{Data: data, Errors: errs}
+			if b, err := json.Marshal(resp); err != nil || !bytes.Equal(b, last) {
+				last = b
+				select {
+				case c <- resp:
+				case <-ctx.Done():
+					cancel()
+					return
+				}
+			}
+
+			if keys.empty() {
+				cancel()
+				return
+			}
+			select {
+			case <-keys.invalidated:
+				cancel()
+			case <-ctx.Done():
+				cancel()
+				return
+			}
+		}
+	}()
+	return c
+}
+
+// LiveQueryHub is an in-memory LiveQueryStore. Invalidations are not shared between processes.
+type LiveQueryHub struct {
+	mu       sync.Mutex
+	watchers map[string]map[*liveWatcher]bool
+}
+
+type liveWatcher struct {
+	keys []string
+	c    chan struct{}
+}
+
+// NewLiveQueryHub returns an empty LiveQueryHub.
+func NewLiveQueryHub() *LiveQueryHub {
+	return &LiveQueryHub{watchers: make(map[string]map[*liveWatcher]bool)}
+}
+
+// Watch implements LiveQueryStore.
+func (h *LiveQueryHub) Watch(ctx context.Context, keys []string) <-chan struct{} {
+	w := &liveWatcher{keys: keys, c: make(chan struct{}, 1)}
+	h.mu.Lock()
+	for _, key := range keys {
+		if h.watchers[key] == nil {
+			h.watchers[key] = make(map[*liveWatcher]bool)
+		}
+		h.watchers[key][w] = true
+	}
+	h.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		h.mu.Lock()
+		h.remove(w)
+		h.mu.Unlock()
+	}()
+	return w.c
+}
+
+// Invalidate notifies the live queries that registered any of the keys, which then execute again.
+func (h *LiveQueryHub) Invalidate(keys ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, key := range keys {
+		for w := range h.watchers[key] {
+			h.remove(w)
+			select {
+			case w.c <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// remove stops notifying w. The caller must hold h.mu.
+func (h *LiveQueryHub) remove(w *liveWatcher) {
+	for _, key := range w.keys {
+		delete(h.watchers[key], w)
+		if len(h.watchers[key]) == 0 {
+			delete(h.watchers, key)
+		}
+	}
+}
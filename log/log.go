@@ -11,6 +11,28 @@ type Logger interface {
 	LogPanic(ctx context.Context, value interface{})
 }
 
+// Panic describes a panic that occurred during query execution.
+type Panic struct {
+	// Value is the recovered value.
+	Value interface{}
+
+	// Stack is the stack trace of the panicking goroutine.
+	Stack []byte
+
+	// Path is the path of the field whose resolver panicked, or nil if the panic did not occur in
+	// a resolver.
+	Path []interface{}
+
+	// OperationName is the name of the executed operation, if any.
+	OperationName string
+}
+
+// PanicLogger is implemented by loggers that log panics with their stack traces. It is used
+// instead of LogPanic if the Logger implements it.
+type PanicLogger interface {
+	LogPanicInfo(ctx context.Context, p *Panic)
+}
+
 // DefaultLogger is the default logger used to log panics that occur during query execution
 type DefaultLogger struct{}
 
@@ -21,3 +43,17 @@ func (l *DefaultLogger) LogPanic(ctx context.Context, value interface{}) {
 	buf = buf[:runtime.Stack(buf, false)]
 	log.Printf("graphql: panic occurred: %v\n%s\ncontext: %v", value, buf, ctx)
 }
+
+// LogPanicInfo logs a recovered panic with its stack trace, the path of the field and the name of
+// the operation.
+func (l *DefaultLogger) LogPanicInfo(ctx context.Context, p *Panic) {
+	log.Printf("graphql: panic occurred: %v\npath: %v\noperation: %q\n%s\ncontext: %v", p.Value, p.Path, p.OperationName, p.Stack, ctx)
+}
+
+// CaptureStack returns the stack trace of the calling goroutine. Called in a deferred function
+// that recovered a panic, it includes the frames leading to the panic.
+func CaptureStack() []byte {
+	const size = 64 << 10
+	buf := make([]byte, size)
+	return buf[:runtime.Stack(buf, false)]
+}
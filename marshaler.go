@@ -0,0 +1,31 @@
+package graphql
+
+import (
+	"io"
+	"reflect"
+
+	"github.com/graph-gophers/graphql-go/internal/exec"
+)
+
+// Marshaler is implemented by output types that write their own JSON representation to the
+// response, e.g. to serialize large numeric matrices without reflection. A resolver result
+// implementing Marshaler is not executed: MarshalGraphQL is called instead and has to write valid
+// JSON matching the selections of the field. If it returns an error, the field resolves to null and
+// the error is added to the response. Only the results of fields are checked for the interface, not
+// the elements of lists.
+type Marshaler interface {
+	MarshalGraphQL(w io.Writer, sels SelectionInfo) error
+}
+
+// SelectionInfo describes the selections a Marshaler has to write.
+type SelectionInfo struct {
+	// Fields are the fields selected on the value, see SelectedFieldsFromContext. They are empty
+	// for scalars, enums and lists of them.
+	Fields []*SelectedField
+}
+
+var marshalerType = reflect.TypeOf((*Marshaler)(nil)).Elem()
+
+func marshalGraphQL(w io.Writer, v interface{}, fields []*exec.SelectedField) error {
+	return v.(Marshaler).MarshalGraphQL(w, SelectionInfo{Fields: convertSelectedFields(fields)})
+}
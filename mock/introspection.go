@@ -0,0 +1,112 @@
+package mock
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/graph-gophers/graphql-go/internal/query"
+	"github.com/graph-gophers/graphql-go/introspection"
+)
+
+// meta resolves the introspection field with the given name on the query type.
+func (r *request) meta(name string, f *query.Field, sels []query.Selection, path []interface{}) {
+	switch name {
+	case "__schema":
+		r.metaValue(r.server.types["__Schema"], reflect.ValueOf(r.server.schema.Inspect()), sels, path)
+	case "__type":
+		var t *introspection.Type
+		if v, ok := f.Arguments.Get("name"); ok {
+			if name, ok := v.Value(r.vars).(string); ok {
+				t = r.server.types[name]
+			}
+		}
+		r.metaValue(r.server.types["__Type"], reflect.ValueOf(t), sels, path)
+	}
+}
+
+// metaValue encodes a value of the introspection package by calling the methods matching the
+// selected fields, the same way resolvers are called.
+func (r *request) metaValue(t *introspection.Type, v reflect.Value, sels []query.Selection, path []interface{}) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			r.out.WriteString("null")
+			return
+		}
+		if v.Type().Elem().Kind() == reflect.Struct {
+			break
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Slice:
+		r.out.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				r.out.WriteByte(',')
+			}
+			r.metaValue(t, v.Index(i), sels, append(path[:len(path):len(path)], i))
+		}
+		r.out.WriteByte(']')
+		return
+
+	case reflect.Ptr:
+		// An object of the introspection package, resolved below.
+
+	default:
+		r.value(v.Interface(), path)
+		return
+	}
+
+	t = namedType(t)
+	var fields []*responseField
+	r.collect(t, sels, &fields, make(map[string]*responseField), make(map[string]bool))
+
+	r.out.WriteByte('{')
+	for i, f := range fields {
+		if i > 0 {
+			r.out.WriteByte(',')
+		}
+		r.key(f.key)
+
+		name := f.fields[0].Name.Name
+		if name == "__typename" {
+			r.value(*t.Name(), nil)
+			continue
+		}
+		m := v.MethodByName(strings.ToUpper(name[:1]) + name[1:])
+		var in []reflect.Value
+		if m.Type().NumIn() == 1 {
+			in = append(in, r.metaArgs(m.Type().In(0), f.fields[0]))
+		}
+		var subSels []query.Selection
+		for _, qf := range f.fields {
+			subSels = append(subSels, qf.Selections...)
+		}
+		r.metaValue(fieldType(t, name), m.Call(in)[0], subSels, append(path[:len(path):len(path)], f.key))
+	}
+	r.out.WriteByte('}')
+}
+
+// metaArgs packs the arguments of f into the argument struct of an introspection method, e.g.
+// *struct{ IncludeDeprecated bool }.
+func (r *request) metaArgs(typ reflect.Type, f *query.Field) reflect.Value {
+	args := reflect.New(typ.Elem())
+	for i := 0; i < typ.Elem().NumField(); i++ {
+		sf := typ.Elem().Field(i)
+		name := strings.ToLower(sf.Name[:1]) + sf.Name[1:]
+		if lit, ok := f.Arguments.Get(name); ok {
+			if v := reflect.ValueOf(lit.Value(r.vars)); v.IsValid() && v.Type().ConvertibleTo(sf.Type) {
+				args.Elem().Field(i).Set(v.Convert(sf.Type))
+			}
+		}
+	}
+	return args
+}
+
+func namedType(t *introspection.Type) *introspection.Type {
+	for t.OfType() != nil {
+		t = t.OfType()
+	}
+	return t
+}
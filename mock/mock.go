@@ -0,0 +1,422 @@
+// Package mock executes queries against a schema without any resolvers. Every field is resolved
+// with deterministic fake data, so clients can be developed against a schema before it is
+// implemented.
+//
+// The data of a field only depends on its path in the response, so the same query always returns
+// the same result.
+package mock
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strconv"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/internal/common"
+	"github.com/graph-gophers/graphql-go/internal/query"
+	"github.com/graph-gophers/graphql-go/introspection"
+)
+
+// Generator returns the mock value of a scalar or enum at the given path of the response. The
+// seed is derived from the path. The value is encoded with encoding/json; nil results in null.
+type Generator func(path []interface{}, seed uint64) interface{}
+
+// Server resolves queries with mock data. It implements http.Handler with the same request format
+// as relay.Handler.
+type Server struct {
+	schema  *graphql.Schema
+	types   map[string]*introspection.Type
+	query   *introspection.Type
+	mut     *introspection.Type
+	scalars map[string]Generator
+	fields  map[string]Generator
+
+	listLength int
+	schemaOpts []graphql.SchemaOpt
+}
+
+// Option configures a mock Server.
+type Option func(*Server)
+
+// Scalar sets the generator of all values of the named scalar or enum type.
+func Scalar(name string, g Generator) Option {
+	return func(s *Server) {
+		s.scalars[name] = g
+	}
+}
+
+// Field sets the generator of a field with a scalar or enum type, which takes precedence over the
+// generator of the type.
+func Field(typeName, fieldName string, g Generator) Option {
+	return func(s *Server) {
+		s.fields[typeName+"."+fieldName] = g
+	}
+}
+
+// ListLength sets the number of items of every list. The default is 2.
+func ListLength(n int) Option {
+	return func(s *Server) {
+		s.listLength = n
+	}
+}
+
+// SchemaOptions sets the options used to parse the schema, e.g. graphql.UseStringDescriptions.
+func SchemaOptions(opts ...graphql.SchemaOpt) Option {
+	return func(s *Server) {
+		s.schemaOpts = append(s.schemaOpts, opts...)
+	}
+}
+
+// New parses the schema and returns a Server that resolves it with mock data.
+func New(schemaString string, opts ...Option) (*Server, error) {
+	s := &Server{
+		types:      make(map[string]*introspection.Type),
+		scalars:    make(map[string]Generator),
+		fields:     make(map[string]Generator),
+		listLength: 2,
+	}
+	for k, g := range defaultScalars {
+		s.scalars[k] = g
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	schema, err := graphql.ParseSchema(schemaString, nil, s.schemaOpts...)
+	if err != nil {
+		return nil, err
+	}
+	s.schema = schema
+	is := schema.Inspect()
+	for _, t := range is.Types() {
+		s.types[*t.Name()] = t
+	}
+	s.query = is.QueryType()
+	s.mut = is.MutationType()
+	return s, nil
+}
+
+// MustNew calls New and panics on error.
+func MustNew(schemaString string, opts ...Option) *Server {
+	s, err := New(schemaString, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// Schema returns the parsed schema.
+func (s *Server) Schema() *graphql.Schema {
+	return s.schema
+}
+
+var defaultScalars = map[string]Generator{
+	"Int": func(path []interface{}, seed uint64) interface{} {
+		return int32(seed % 1000)
+	},
+	"Float": func(path []interface{}, seed uint64) interface{} {
+		return float64(seed%100000) / 100
+	},
+	"String": func(path []interface{}, seed uint64) interface{} {
+		return fmt.Sprintf("%s %d", lastFieldName(path), seed%1000)
+	},
+	"Boolean": func(path []interface{}, seed uint64) interface{} {
+		return seed%2 == 0
+	},
+	"ID": func(path []interface{}, seed uint64) interface{} {
+		return strconv.FormatUint(seed, 36)
+	},
+	"Time": func(path []interface{}, seed uint64) interface{} {
+		return time.Unix(int64(seed%(1<<31)), 0).UTC().Format(time.RFC3339)
+	},
+}
+
+func lastFieldName(path []interface{}) string {
+	for i := len(path) - 1; i >= 0; i-- {
+		if name, ok := path[i].(string); ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// Exec validates the query and resolves it with mock data. Subscriptions are not supported.
+func (s *Server) Exec(ctx context.Context, queryString string, operationName string, variables map[string]interface{}) *graphql.Response {
+	if variables == nil {
+		variables = make(map[string]interface{})
+	}
+	if errs := s.schema.ValidateWithVariables(queryString, variables); len(errs) != 0 {
+		return &graphql.Response{Errors: errs}
+	}
+	doc, qErr := query.Parse(queryString)
+	if qErr != nil {
+		return &graphql.Response{Errors: []*errors.QueryError{qErr}}
+	}
+
+	op, err := getOperation(doc, operationName)
+	if err != nil {
+		return &graphql.Response{Errors: []*errors.QueryError{err}}
+	}
+	var root *introspection.Type
+	switch op.Type {
+	case query.Query:
+		root = s.query
+	case query.Mutation:
+		root = s.mut
+	default:
+		return &graphql.Response{Errors: []*errors.QueryError{errors.Errorf("%s operations are not supported by the mock server", op.Type)}}
+	}
+
+	r := &request{server: s, doc: doc, vars: variables}
+	r.object(root, op.Selections, nil)
+	return &graphql.Response{Data: r.out.Bytes(), Errors: r.errs}
+}
+
+func getOperation(doc *query.Document, operationName string) (*query.Operation, *errors.QueryError) {
+	if len(doc.Operations) == 0 {
+		return nil, errors.Errorf("no operations in query document")
+	}
+	if operationName == "" {
+		if len(doc.Operations) > 1 {
+			return nil, errors.Errorf("more than one operation in query document and no operation name given")
+		}
+		return doc.Operations[0], nil
+	}
+	op := doc.Operations.Get(operationName)
+	if op == nil {
+		return nil, errors.Errorf("no operation with name %q", operationName)
+	}
+	return op, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Query         string                 `json:"query"`
+		OperationName string                 `json:"operationName"`
+		Variables     map[string]interface{} `json:"variables"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response := s.Exec(r.Context(), params.Query, params.OperationName, params.Variables)
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(responseJSON)
+}
+
+type request struct {
+	server *Server
+	doc    *query.Document
+	vars   map[string]interface{}
+	out    bytes.Buffer
+	errs   []*errors.QueryError
+}
+
+// responseField is a field of the response with all the query fields merged into it.
+type responseField struct {
+	key    string
+	fields []*query.Field
+}
+
+func (r *request) object(t *introspection.Type, sels []query.Selection, path []interface{}) {
+	var fields []*responseField
+	r.collect(t, sels, &fields, make(map[string]*responseField), make(map[string]bool))
+
+	r.out.WriteByte('{')
+	for i, f := range fields {
+		if i > 0 {
+			r.out.WriteByte(',')
+		}
+		r.key(f.key)
+
+		name := f.fields[0].Name.Name
+		fieldPath := append(path[:len(path):len(path)], f.key)
+		var subSels []query.Selection
+		for _, qf := range f.fields {
+			subSels = append(subSels, qf.Selections...)
+		}
+		switch {
+		case name == "__typename":
+			r.value(*t.Name(), nil)
+		case name == "__schema" || name == "__type":
+			r.meta(name, f.fields[0], subSels, fieldPath)
+		default:
+			r.output(fieldType(t, name), subSels, fieldPath, r.server.fields[*t.Name()+"."+name])
+		}
+	}
+	r.out.WriteByte('}')
+}
+
+// collect groups the selected fields by their response key, in the order of their first
+// occurrence.
+func (r *request) collect(t *introspection.Type, sels []query.Selection, fields *[]*responseField, byKey map[string]*responseField, visited map[string]bool) {
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *query.Field:
+			if r.skip(sel.Directives) {
+				continue
+			}
+			key := sel.Alias.Name
+			f, ok := byKey[key]
+			if !ok {
+				f = &responseField{key: key}
+				byKey[key] = f
+				*fields = append(*fields, f)
+			}
+			f.fields = append(f.fields, sel)
+
+		case *query.InlineFragment:
+			if r.skip(sel.Directives) || !r.applies(t, sel.On.Name) {
+				continue
+			}
+			r.collect(t, sel.Selections, fields, byKey, visited)
+
+		case *query.FragmentSpread:
+			if r.skip(sel.Directives) || visited[sel.Name.Name] {
+				continue
+			}
+			visited[sel.Name.Name] = true
+			frag := r.doc.Fragments.Get(sel.Name.Name)
+			if frag == nil || !r.applies(t, frag.On.Name) {
+				continue
+			}
+			r.collect(t, frag.Selections, fields, byKey, visited)
+		}
+	}
+}
+
+// applies reports whether a fragment on the named type applies to the object type t.
+func (r *request) applies(t *introspection.Type, on string) bool {
+	if on == "" || on == *t.Name() {
+		return true
+	}
+	cond := r.server.types[on]
+	if cond == nil || cond.PossibleTypes() == nil {
+		return false
+	}
+	for _, pt := range *cond.PossibleTypes() {
+		if *pt.Name() == *t.Name() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *request) skip(directives common.DirectiveList) bool {
+	if d := directives.Get("skip"); d != nil {
+		if v, ok := d.Args.Get("if"); ok && v.Value(r.vars) == true {
+			return true
+		}
+	}
+	if d := directives.Get("include"); d != nil {
+		if v, ok := d.Args.Get("if"); ok && v.Value(r.vars) == false {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *request) output(t *introspection.Type, sels []query.Selection, path []interface{}, g Generator) {
+	if t.Kind() == "NON_NULL" {
+		t = t.OfType()
+	}
+	seed := pathSeed(path)
+
+	switch t.Kind() {
+	case "LIST":
+		r.out.WriteByte('[')
+		for i := 0; i < r.server.listLength; i++ {
+			if i > 0 {
+				r.out.WriteByte(',')
+			}
+			r.output(t.OfType(), sels, append(path[:len(path):len(path)], i), g)
+		}
+		r.out.WriteByte(']')
+
+	case "OBJECT":
+		r.object(t, sels, path)
+
+	case "INTERFACE", "UNION":
+		// The concrete type is chosen by the path, so it is stable across requests.
+		possible := *t.PossibleTypes()
+		if len(possible) == 0 {
+			r.out.WriteString("null")
+			return
+		}
+		r.object(r.server.types[*possible[seed%uint64(len(possible))].Name()], sels, path)
+
+	case "ENUM":
+		if g == nil {
+			g = r.server.scalars[*t.Name()]
+		}
+		if g != nil {
+			r.value(g(path, seed), path)
+			return
+		}
+		values := *t.EnumValues(&struct{ IncludeDeprecated bool }{false})
+		if len(values) == 0 {
+			r.out.WriteString("null")
+			return
+		}
+		r.value(values[seed%uint64(len(values))].Name(), path)
+
+	default:
+		if g == nil {
+			g = r.server.scalars[*t.Name()]
+		}
+		if g == nil {
+			// Custom scalars default to strings.
+			g = defaultScalars["String"]
+		}
+		r.value(g(path, seed), path)
+	}
+}
+
+func (r *request) key(key string) {
+	b, _ := json.Marshal(key)
+	r.out.Write(b)
+	r.out.WriteByte(':')
+}
+
+func (r *request) value(v interface{}, path []interface{}) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		qErr := errors.Errorf("can not encode mock value: %s", err)
+		qErr.Path = path
+		r.errs = append(r.errs, qErr)
+		r.out.WriteString("null")
+		return
+	}
+	r.out.Write(b)
+}
+
+// pathSeed hashes the path of a value in the response.
+func pathSeed(path []interface{}) uint64 {
+	h := fnv.New64a()
+	for _, p := range path {
+		fmt.Fprintf(h, "%v.", p)
+	}
+	return h.Sum64()
+}
+
+func fieldType(t *introspection.Type, name string) *introspection.Type {
+	for _, f := range *t.Fields(&struct{ IncludeDeprecated bool }{true}) {
+		if f.Name() == name {
+			return f.Type()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package mock_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/graph-gophers/graphql-go/example/starwars"
+	"github.com/graph-gophers/graphql-go/mock"
+)
+
+const heroQuery = `
+	query($episode: Episode!, $withFriends: Boolean!) {
+		hero(episode: $episode) {
+			__typename
+			id
+			name
+			friends @include(if: $withFriends) {
+				name
+			}
+			... on Droid {
+				primaryFunction
+			}
+			... on Human {
+				height
+			}
+		}
+	}
+`
+
+func exec(t *testing.T, s *mock.Server, query string, variables map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	res := s.Exec(context.Background(), query, "", variables)
+	if len(res.Errors) != 0 {
+		t.Fatalf("unexpected errors: %v", res.Errors)
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal(res.Data, &data); err != nil {
+		t.Fatalf("invalid JSON: %s: %s", err, res.Data)
+	}
+	return data
+}
+
+func TestExec(t *testing.T) {
+	s := mock.MustNew(starwars.Schema)
+	vars := map[string]interface{}{"episode": "JEDI", "withFriends": true}
+
+	first := exec(t, s, heroQuery, vars)
+	if second := exec(t, s, heroQuery, vars); !reflect.DeepEqual(first, second) {
+		t.Errorf("mock data is not deterministic:\n%v\n%v", first, second)
+	}
+
+	hero := first["hero"].(map[string]interface{})
+	switch hero["__typename"] {
+	case "Droid":
+		if _, ok := hero["primaryFunction"].(string); !ok {
+			t.Errorf("missing primaryFunction of droid: %v", hero)
+		}
+	case "Human":
+		if _, ok := hero["height"].(float64); !ok {
+			t.Errorf("missing height of human: %v", hero)
+		}
+	default:
+		t.Errorf("unexpected type %v", hero["__typename"])
+	}
+	if friends, ok := hero["friends"].([]interface{}); !ok || len(friends) != 2 {
+		t.Errorf("expected 2 friends, got %v", hero["friends"])
+	}
+
+	vars["withFriends"] = false
+	hero = exec(t, s, heroQuery, vars)["hero"].(map[string]interface{})
+	if _, ok := hero["friends"]; ok {
+		t.Errorf("friends were not skipped: %v", hero)
+	}
+}
+
+func TestGenerators(t *testing.T) {
+	s := mock.MustNew(starwars.Schema,
+		mock.ListLength(3),
+		mock.Scalar("ID", func(path []interface{}, seed uint64) interface{} {
+			return "fixed"
+		}),
+		mock.Field("Review", "stars", func(path []interface{}, seed uint64) interface{} {
+			return 5
+		}),
+	)
+
+	data := exec(t, s, `{ reviews(episode: JEDI) { stars } search(text: "a") { ... on Starship { id } ... on Human { id } ... on Droid { id } } }`, nil)
+	reviews := data["reviews"].([]interface{})
+	if len(reviews) != 3 {
+		t.Fatalf("expected 3 reviews, got %d", len(reviews))
+	}
+	for _, r := range reviews {
+		if stars := r.(map[string]interface{})["stars"]; stars != float64(5) {
+			t.Errorf("unexpected stars %v", stars)
+		}
+	}
+	for _, r := range data["search"].([]interface{}) {
+		if id := r.(map[string]interface{})["id"]; id != "fixed" {
+			t.Errorf("unexpected id %v", id)
+		}
+	}
+}
+
+func TestIntrospection(t *testing.T) {
+	s := mock.MustNew(starwars.Schema)
+	data := exec(t, s, `{ __type(name: "Episode") { kind enumValues { name } } }`, nil)
+
+	want := map[string]interface{}{
+		"__type": map[string]interface{}{
+			"kind": "ENUM",
+			"enumValues": []interface{}{
+				map[string]interface{}{"name": "NEWHOPE"},
+				map[string]interface{}{"name": "EMPIRE"},
+				map[string]interface{}{"name": "JEDI"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(data, want) {
+		t.Errorf("got %v, want %v", data, want)
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	srv := httptest.NewServer(mock.MustNew(starwars.Schema))
+	defer srv.Close()
+
+	resp, err := srv.Client().Post(srv.URL, "application/json", strings.NewReader(`{"query": "{ hero { unknown } }"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var res struct {
+		Errors []struct{ Message string }
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Errors) != 1 || !strings.Contains(res.Errors[0].Message, "unknown") {
+		t.Errorf("expected a validation error, got %+v", res.Errors)
+	}
+}
@@ -0,0 +1,73 @@
+package graphql
+
+import (
+	"strings"
+	"unicode"
+)
+
+// FieldNameMapping binds resolver methods, resolver struct fields and the fields of argument and
+// input structs to the GraphQL fields whose names equal their Go names mapped by mapper, e.g.
+// CamelCaseNames or SnakeCaseNames. By default names are compared ignoring case and underscores,
+// so that FullName binds both fullName and full_name. The name of a "graphql" struct tag, e.g.
+// `graphql:"login"`, overrides the mapping of a single struct field.
+func FieldNameMapping(mapper func(goName string) string) SchemaOpt {
+	return func(s *Schema) {
+		s.schema.FieldNameMapper = mapper
+	}
+}
+
+// CamelCaseNames maps Go names to lower camel case names, e.g. "FullName" to "fullName", "ID" to
+// "id" and "HTMLBody" to "htmlBody".
+func CamelCaseNames(goName string) string {
+	words := nameWords(goName)
+	for i, w := range words {
+		if i == 0 {
+			words[i] = strings.ToLower(w)
+		} else {
+			words[i] = strings.ToUpper(w[:1]) + strings.ToLower(w[1:])
+		}
+	}
+	return strings.Join(words, "")
+}
+
+// SnakeCaseNames maps Go names to snake case names, e.g. "FullName" to "full_name" and "UserID"
+// to "user_id".
+func SnakeCaseNames(goName string) string {
+	words := nameWords(goName)
+	for i, w := range words {
+		words[i] = strings.ToLower(w)
+	}
+	return strings.Join(words, "_")
+}
+
+// nameWords splits a Go name into words at underscores and case changes, keeping initialisms
+// like "HTML" in "HTMLBody" together.
+func nameWords(name string) []string {
+	var words []string
+	runes := []rune(name)
+	start := 0
+	for i := 1; i <= len(runes); i++ {
+		split := i == len(runes) || runes[i] == '_'
+		if !split {
+			prev, cur := runes[i-1], runes[i]
+			next := rune(0)
+			if i+1 < len(runes) {
+				next = runes[i+1]
+			}
+			split = (unicode.IsLower(prev) || unicode.IsDigit(prev)) && unicode.IsUpper(cur) ||
+				unicode.IsUpper(prev) && unicode.IsUpper(cur) && unicode.IsLower(next)
+		}
+		if !split {
+			continue
+		}
+		if i > start {
+			words = append(words, string(runes[start:i]))
+		}
+		if i < len(runes) && runes[i] == '_' {
+			start = i + 1
+		} else {
+			start = i
+		}
+	}
+	return words
+}
@@ -0,0 +1,195 @@
+package graphql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/internal/query"
+	"github.com/graph-gophers/graphql-go/requestcontext"
+)
+
+// OperationInfo describes the operation of a request. It is computed after the document was
+// validated and before it is executed, and is shared by all hooks of the request: it is passed to
+// the RateLimiter and stored in the context given to tracers, loggers and resolvers, from which it
+// can be retrieved with OperationInfoFromContext.
+type OperationInfo struct {
+	// Name is the name of the executed operation, which is empty for anonymous operations.
+	Name string
+
+	// Type is "query", "mutation" or "subscription".
+	Type string
+
+	// DocumentHash is the hex encoded SHA-256 hash of the query document.
+	DocumentHash string
+
+	// Complexity estimates the cost of the operation as the number of fields it selects, with
+	// fragments expanded.
+	Complexity int
+
+	// VariablesSize is the size of the JSON encoded variables in bytes.
+	VariablesSize int
+
+	// Stats describes the shape of the operation.
+	Stats DocumentStats
+
+	// Client identifies the application that sent the request, see WithClientInfo.
+	Client ClientInfo
+
+	// Extensions is the "extensions" member of the request, see WithRequestExtensions. It must not
+	// be modified.
+	Extensions map[string]interface{}
+
+	// Deprecations are the deprecated schema members used by the operation, in the order of their
+	// coordinates. They are only looked up if the EnforceDeprecations option is set.
+	Deprecations []DeprecatedUsage
+
+	// warnDeprecations is set if the deprecations are added to the extensions of the response.
+	warnDeprecations bool
+}
+
+// DocumentStats describes the shape of an operation, e.g. to log it before enforcing new limits.
+// Fragments are expanded and @skip and @include are not evaluated.
+type DocumentStats struct {
+	// Fragments is the number of distinct named fragments spread by the operation.
+	Fragments int `json:"fragments"`
+
+	// MaxDepth is the maximum nesting depth of the fields. The fields of the operation have depth 1.
+	MaxDepth int `json:"maxDepth"`
+
+	// Fields is the number of fields written in the operation and the fragments it spreads, with
+	// each fragment counted once.
+	Fields int `json:"fields"`
+
+	// Aliases is the number of those fields with an alias.
+	Aliases int `json:"aliases"`
+}
+
+// DocumentStatsExtension adds the DocumentStats and the Complexity of each executed operation to
+// the "documentStats" extension of the response.
+func DocumentStatsExtension() SchemaOpt {
+	return func(s *Schema) {
+		s.documentStatsExtension = true
+	}
+}
+
+// documentStatsExtension returns the response extensions reporting the statistics of the
+// operation executed with ctx.
+func documentStatsExtension(ctx context.Context) map[string]interface{} {
+	info := OperationInfoFromContext(ctx)
+	if info == nil {
+		return nil
+	}
+	return map[string]interface{}{
+		"documentStats": map[string]interface{}{
+			"fragments":  info.Stats.Fragments,
+			"maxDepth":   info.Stats.MaxDepth,
+			"fields":     info.Stats.Fields,
+			"aliases":    info.Stats.Aliases,
+			"complexity": info.Complexity,
+		},
+	}
+}
+
+// ClientInfo identifies the client application of a request, e.g. from the
+// "apollographql-client-name" and "apollographql-client-version" headers.
+type ClientInfo = requestcontext.ClientInfo
+
+// WithClientInfo returns a context that carries the client of the request. It is copied to the
+// OperationInfo of all operations executed with the context. relay.Handler sets it from the
+// "apollographql-client-name" and "apollographql-client-version" headers.
+func WithClientInfo(ctx context.Context, client ClientInfo) context.Context {
+	return requestcontext.WithClient(ctx, client)
+}
+
+// WithRequestExtensions returns a context that carries the "extensions" member of the request, e.g.
+// the "persistedQuery" of automatic persisted queries or a tracing ID set by the client. It is
+// copied to the OperationInfo of all operations executed with the context. relay.Handler sets it
+// from the request body.
+func WithRequestExtensions(ctx context.Context, extensions map[string]interface{}) context.Context {
+	return requestcontext.WithExtensions(ctx, extensions)
+}
+
+type operationInfoKey struct{}
+
+// OperationInfoFromContext returns the OperationInfo of the operation being executed, or nil if the
+// context does not belong to an operation.
+func OperationInfoFromContext(ctx context.Context) *OperationInfo {
+	info, _ := ctx.Value(operationInfoKey{}).(*OperationInfo)
+	return info
+}
+
+func newOperationInfo(ctx context.Context, queryString string, doc *query.Document, op *query.Operation, variables map[string]interface{}) *OperationInfo {
+	hash := sha256.Sum256([]byte(queryString))
+	info := &OperationInfo{
+		Name:         op.Name.Name,
+		Type:         strings.ToLower(string(op.Type)),
+		DocumentHash: hex.EncodeToString(hash[:]),
+		Complexity:   query.Complexity(doc, op),
+	}
+	stats := query.OperationStats(doc, op)
+	info.Stats = DocumentStats{
+		Fragments: stats.Fragments,
+		MaxDepth:  stats.MaxDepth,
+		Fields:    stats.Fields,
+		Aliases:   stats.Aliases,
+	}
+	if len(variables) != 0 {
+		if b, err := json.Marshal(variables); err == nil {
+			info.VariablesSize = len(b)
+		}
+	}
+	info.Client, _ = requestcontext.Client(ctx)
+	info.Extensions = requestcontext.Extensions(ctx)
+	return info
+}
+
+// RateLimiter decides whether an operation may be executed. It is consulted after validation and
+// before any resolver is called. The context of the request can be used to identify the client,
+// e.g. by an API key stored in it by the HTTP handler.
+type RateLimiter interface {
+	// Allow returns a non-nil error to reject the operation. The error is returned to the client
+	// as the only error of the response. A *errors.QueryError is returned as is, e.g. to set
+	// extensions like a retry delay.
+	Allow(ctx context.Context, op *OperationInfo) error
+}
+
+// RateLimit consults the given RateLimiter before executing any operation.
+func RateLimit(limiter RateLimiter) SchemaOpt {
+	return func(s *Schema) {
+		s.rateLimiter = limiter
+	}
+}
+
+// startOperation stores the OperationInfo and the request data of the requestcontext package in
+// the context, records the usage of the operation, applies the policy of its client and consults
+// the rate limiter and the complexity budget of the schema, if any.
+func (s *Schema) startOperation(ctx context.Context, queryString string, doc *query.Document, op *query.Operation, variables map[string]interface{}) (context.Context, *errors.QueryError) {
+	info := newOperationInfo(ctx, queryString, doc, op, variables)
+	ctx = context.WithValue(ctx, operationInfoKey{}, info)
+	ctx = requestcontext.WithOperationName(ctx, info.Name)
+	ctx = requestcontext.WithQuery(ctx, queryString)
+	ctx = requestcontext.WithVariables(ctx, variables)
+	if _, ok := requestcontext.StartTime(ctx); !ok {
+		ctx = requestcontext.WithStartTime(ctx, time.Now())
+	}
+	if err := s.checkUsage(ctx, info, doc, op, variables); err != nil {
+		return ctx, err
+	}
+	if err := s.checkClientPolicy(info); err != nil {
+		return ctx, err
+	}
+	if s.rateLimiter != nil {
+		if err := s.rateLimiter.Allow(ctx, info); err != nil {
+			if qErr, ok := err.(*errors.QueryError); ok {
+				return ctx, qErr
+			}
+			return ctx, &errors.QueryError{Message: err.Error(), ResolverError: err}
+		}
+	}
+	return ctx, s.spendBudget(ctx, info)
+}
@@ -0,0 +1,68 @@
+// Package parser parses GraphQL query documents with the grammar used by the server, e.g. to
+// check documents in editors or CI before they are sent. The syntax tree is shared with the
+// server, so a parsed Document can be validated with the validator package.
+package parser
+
+import (
+	"github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/internal/common"
+	"github.com/graph-gophers/graphql-go/internal/query"
+)
+
+// The nodes of the syntax tree of a query document.
+type (
+	Document       = query.Document
+	Operation      = query.Operation
+	OperationType  = query.OperationType
+	FragmentDecl   = query.FragmentDecl
+	Fragment       = query.Fragment
+	Selection      = query.Selection
+	Field          = query.Field
+	InlineFragment = query.InlineFragment
+	FragmentSpread = query.FragmentSpread
+
+	Ident          = common.Ident
+	Directive      = common.Directive
+	Argument       = common.Argument
+	InputValue     = common.InputValue
+	Type           = common.Type
+	TypeName       = common.TypeName
+	List           = common.List
+	NonNull        = common.NonNull
+	Literal        = common.Literal
+	BasicLit       = common.BasicLit
+	ListLit        = common.ListLit
+	ObjectLit      = common.ObjectLit
+	ObjectLitField = common.ObjectLitField
+	NullLit        = common.NullLit
+	Variable       = common.Variable
+)
+
+// The types of operations.
+const (
+	Query        = query.Query
+	Mutation     = query.Mutation
+	Subscription = query.Subscription
+)
+
+// Limits restricts the size of the documents accepted by ParseWithLimits, like the MaxQueryBytes
+// and MaxTokens options of a schema. Zero values disable a limit.
+type Limits = query.Limits
+
+// Parse parses a query document. It returns a syntax error with its location if the document is
+// invalid.
+func Parse(queryString string) (*Document, *errors.QueryError) {
+	return query.Parse(queryString)
+}
+
+// ParseWithLimits parses the document like Parse, but rejects it as soon as one of the limits is
+// exceeded.
+func ParseWithLimits(queryString string, limits Limits) (*Document, *errors.QueryError) {
+	return query.ParseWithLimits(queryString, limits)
+}
+
+// Normalize returns the tokens of a query document without insignificant whitespace, commas and
+// comments, like the documents hashed by the CacheKey method of a schema.
+func Normalize(queryString string) (string, *errors.QueryError) {
+	return query.Normalize(queryString)
+}
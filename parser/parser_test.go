@@ -0,0 +1,59 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/parser"
+)
+
+func TestParse(t *testing.T) {
+	doc, err := parser.Parse(`
+		query Hero($episode: Episode = JEDI) {
+			hero(episode: $episode) {
+				name
+				...friends
+			}
+		}
+		fragment friends on Character { friends { name } }
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	op := doc.Operations.Get("Hero")
+	if op == nil || op.Type != parser.Query {
+		t.Fatalf("expected query operation Hero, got %+v", doc.Operations)
+	}
+	if len(op.Vars) != 1 || op.Vars[0].Name.Name != "episode" {
+		t.Errorf("expected variable episode, got %+v", op.Vars)
+	}
+	hero, ok := op.Selections[0].(*parser.Field)
+	if !ok || hero.Name.Name != "hero" {
+		t.Fatalf("expected field hero, got %+v", op.Selections[0])
+	}
+	if _, ok := hero.Arguments[0].Value.(*parser.Variable); !ok {
+		t.Errorf("expected variable argument, got %T", hero.Arguments[0].Value)
+	}
+	if spread, ok := hero.Selections[1].(*parser.FragmentSpread); !ok || doc.Fragments.Get(spread.Name.Name) == nil {
+		t.Errorf("expected spread of fragment friends, got %+v", hero.Selections[1])
+	}
+}
+
+func TestParse_syntaxError(t *testing.T) {
+	_, err := parser.Parse(`{ hero { name }`)
+	if err == nil {
+		t.Fatal("expected syntax error")
+	}
+	want := []errors.Location{{Line: 1, Column: 16}}
+	if len(err.Locations) != 1 || err.Locations[0] != want[0] {
+		t.Errorf("expected locations %v, got %v", want, err.Locations)
+	}
+}
+
+func TestParseWithLimits(t *testing.T) {
+	_, err := parser.ParseWithLimits(`{ hero { name } }`, parser.Limits{MaxTokens: 5})
+	if err == nil || err.Message != "document exceeds the maximum of 5 tokens" {
+		t.Errorf("expected token limit error, got %v", err)
+	}
+}
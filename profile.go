@@ -0,0 +1,52 @@
+package graphql
+
+import (
+	"context"
+	"runtime/pprof"
+	rtrace "runtime/trace"
+
+	"github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/introspection"
+	"github.com/graph-gophers/graphql-go/trace"
+)
+
+// ProfileOperations attributes the cost of operations executed by Exec in profiles: the
+// goroutines executing an operation, including the ones resolving fields in parallel, carry the
+// pprof labels "graphql.operation" with the name of the operation and "graphql.client" with the
+// name of the client, see OperationInfo.Client, and every operation is a runtime/trace task with a
+// region per resolved field named by its schema coordinate, e.g. "User.friends". Regions are only
+// recorded while an execution trace is running. The labels are added by a tracer that is combined
+// with the one set by Tracer.
+func ProfileOperations() SchemaOpt {
+	return func(s *Schema) {
+		s.profileOperations = true
+	}
+}
+
+// profilingTracer sets the pprof labels and runtime/trace regions of ProfileOperations.
+type profilingTracer struct{}
+
+func (profilingTracer) TraceQuery(ctx context.Context, queryString string, operationName string, variables map[string]interface{}, varTypes map[string]*introspection.Type) (context.Context, trace.TraceQueryFinishFunc) {
+	var client string
+	if info := OperationInfoFromContext(ctx); info != nil {
+		client = info.Client.Name
+	}
+	parent := ctx
+	ctx = pprof.WithLabels(ctx, pprof.Labels("graphql.operation", operationName, "graphql.client", client))
+	pprof.SetGoroutineLabels(ctx)
+	ctx, task := rtrace.NewTask(ctx, "graphql: "+operationName)
+	return ctx, func([]*errors.QueryError) {
+		task.End()
+		pprof.SetGoroutineLabels(parent)
+	}
+}
+
+func (profilingTracer) TraceField(ctx context.Context, label, typeName, fieldName string, trivial bool, args map[string]interface{}) (context.Context, trace.TraceFieldFinishFunc) {
+	if !rtrace.IsEnabled() {
+		return ctx, func(*errors.QueryError) {}
+	}
+	region := rtrace.StartRegion(ctx, typeName+"."+fieldName)
+	return ctx, func(*errors.QueryError) {
+		region.End()
+	}
+}
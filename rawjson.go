@@ -0,0 +1,15 @@
+package graphql
+
+// RawJSON is a pre-serialized JSON value. A resolver may return RawJSON for a field of any output
+// type to skip the execution of the field's selections: the value is written to the response as
+// is. It has to be valid JSON and its shape has to match the selections of the field, including
+// aliases, since neither is checked. An empty RawJSON is handled like null.
+type RawJSON []byte
+
+// MarshalJSON returns r as the JSON encoding of r.
+func (r RawJSON) MarshalJSON() ([]byte, error) {
+	if len(r) == 0 {
+		return []byte("null"), nil
+	}
+	return r, nil
+}
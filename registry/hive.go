@@ -0,0 +1,183 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/graph-gophers/graphql-go/client"
+)
+
+// Default endpoints of GraphQL Hive.
+const (
+	DefaultHiveEndpoint      = "https://app.graphql-hive.com/graphql"
+	DefaultHiveUsageEndpoint = "https://app.graphql-hive.com/usage"
+)
+
+// Hive is the registry of GraphQL Hive.
+type Hive struct {
+	// Token is a registry access token of the target.
+	Token string
+
+	// Endpoint defaults to DefaultHiveEndpoint, UsageEndpoint to DefaultHiveUsageEndpoint.
+	Endpoint      string
+	UsageEndpoint string
+
+	// HTTPClient defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+var _ Registry = (*Hive)(nil)
+
+const schemaPublishMutation = `mutation schemaPublish($input: SchemaPublishInput!) {
+	schemaPublish(input: $input) {
+		__typename
+		... on SchemaPublishError {
+			errors {
+				nodes {
+					message
+				}
+			}
+		}
+		... on SchemaPublishMissingServiceError {
+			message
+		}
+		... on SchemaPublishMissingUrlError {
+			message
+		}
+	}
+}`
+
+// PublishSchema publishes the schema with the schemaPublish mutation of the Hive API.
+func (h *Hive) PublishSchema(ctx context.Context, s *Schema) error {
+	input := map[string]interface{}{
+		"sdl":    s.SDL,
+		"author": s.Author,
+		"commit": s.Commit,
+	}
+	if s.Service != "" {
+		input["service"] = s.Service
+	}
+	if s.URL != "" {
+		input["url"] = s.URL
+	}
+	if len(s.Metadata) != 0 {
+		metadata, err := json.Marshal(s.Metadata)
+		if err != nil {
+			return err
+		}
+		input["metadata"] = string(metadata)
+	}
+
+	endpoint := h.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultHiveEndpoint
+	}
+	c := &client.Client{
+		Endpoint:   endpoint,
+		HTTPClient: h.HTTPClient,
+		Header:     http.Header{"Authorization": {"Bearer " + h.Token}},
+	}
+	var result struct {
+		SchemaPublish struct {
+			Typename string `json:"__typename"`
+			Message  string `json:"message"`
+			Errors   struct {
+				Nodes []struct {
+					Message string `json:"message"`
+				} `json:"nodes"`
+			} `json:"errors"`
+		} `json:"schemaPublish"`
+	}
+	if err := c.Execute(ctx, schemaPublishMutation, "schemaPublish", map[string]interface{}{"input": input}, &result); err != nil {
+		return fmt.Errorf("hive: publishing schema: %s", err)
+	}
+
+	res := result.SchemaPublish
+	if !strings.HasSuffix(res.Typename, "Error") {
+		return nil
+	}
+	msgs := []string{res.Message}
+	if res.Message == "" {
+		msgs = msgs[:0]
+		for _, n := range res.Errors.Nodes {
+			msgs = append(msgs, n.Message)
+		}
+	}
+	return fmt.Errorf("hive: schema rejected (%s): %s", res.Typename, strings.Join(msgs, "; "))
+}
+
+type hiveReport struct {
+	Size       int                           `json:"size"`
+	Map        map[string]*hiveOperationInfo `json:"map"`
+	Operations []*hiveOperation              `json:"operations"`
+}
+
+type hiveOperationInfo struct {
+	Operation     string   `json:"operation"`
+	OperationName string   `json:"operationName,omitempty"`
+	Fields        []string `json:"fields"`
+}
+
+type hiveOperation struct {
+	OperationMapKey string `json:"operationMapKey"`
+	Timestamp       int64  `json:"timestamp"`
+	Execution       struct {
+		OK          bool  `json:"ok"`
+		Duration    int64 `json:"duration"`
+		ErrorsTotal int   `json:"errorsTotal"`
+	} `json:"execution"`
+	Metadata *hiveMetadata `json:"metadata,omitempty"`
+}
+
+type hiveMetadata struct {
+	Client struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	} `json:"client"`
+}
+
+// ReportUsage sends the operations to the usage endpoint of Hive. Operations with the same
+// document, name and fields share an entry of the operation map.
+func (h *Hive) ReportUsage(ctx context.Context, ops []*Operation) error {
+	report := &hiveReport{
+		Size: len(ops),
+		Map:  make(map[string]*hiveOperationInfo),
+	}
+	for _, op := range ops {
+		key := op.Hash + "/" + op.Name + "/" + strings.Join(op.Fields, ",")
+		if _, ok := report.Map[key]; !ok {
+			report.Map[key] = &hiveOperationInfo{
+				Operation:     op.Query,
+				OperationName: op.Name,
+				Fields:        op.Fields,
+			}
+		}
+		o := &hiveOperation{
+			OperationMapKey: key,
+			Timestamp:       op.Timestamp.UnixNano() / 1e6,
+		}
+		o.Execution.OK = op.Errors == 0
+		o.Execution.Duration = op.Duration.Nanoseconds()
+		o.Execution.ErrorsTotal = op.Errors
+		if op.Client.Name != "" {
+			o.Metadata = &hiveMetadata{}
+			o.Metadata.Client.Name = op.Client.Name
+			o.Metadata.Client.Version = op.Client.Version
+		}
+		report.Operations = append(report.Operations, o)
+	}
+
+	body, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	endpoint := h.UsageEndpoint
+	if endpoint == "" {
+		endpoint = DefaultHiveUsageEndpoint
+	}
+	return post(ctx, h.HTTPClient, endpoint, http.Header{"Authorization": {"Bearer " + h.Token}}, bytes.NewReader(body))
+}
@@ -0,0 +1,76 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// HTTP is a generic registry that accepts JSON documents. The schema is sent as a Schema, usage
+// reports as an object with the operations in its "operations" member.
+type HTTP struct {
+	// SchemaURL receives the published schema, UsageURL the usage reports. Usage reports are not
+	// sent if UsageURL is empty.
+	SchemaURL string
+	UsageURL  string
+
+	// Header is added to every request, e.g. for authentication.
+	Header http.Header
+
+	// HTTPClient defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+var _ Registry = (*HTTP)(nil)
+
+// PublishSchema posts the schema to SchemaURL.
+func (h *HTTP) PublishSchema(ctx context.Context, s *Schema) error {
+	body, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return post(ctx, h.HTTPClient, h.SchemaURL, h.Header, bytes.NewReader(body))
+}
+
+// ReportUsage posts the operations to UsageURL.
+func (h *HTTP) ReportUsage(ctx context.Context, ops []*Operation) error {
+	if h.UsageURL == "" {
+		return nil
+	}
+	body, err := json.Marshal(struct {
+		Operations []*Operation `json:"operations"`
+	}{ops})
+	if err != nil {
+		return err
+	}
+	return post(ctx, h.HTTPClient, h.UsageURL, h.Header, bytes.NewReader(body))
+}
+
+func post(ctx context.Context, httpClient *http.Client, url string, header http.Header, body io.Reader) error {
+	req, err := http.NewRequest(http.MethodPost, url, body)
+	if err != nil {
+		return err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("registry: %s responded with status %s: %s", url, resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
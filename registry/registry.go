@@ -0,0 +1,311 @@
+// Package registry publishes a schema and reports its usage to a schema registry, e.g. GraphQL Hive.
+//
+// The Agent publishes the schema when it is started and collects the executed operations as a
+// trace.Tracer, which are reported in batches:
+//
+//	agent, err := registry.New(&registry.Hive{Token: os.Getenv("HIVE_TOKEN")}, schemaString,
+//		registry.Service("products", "http://products:8080/query"),
+//		registry.Commit(os.Getenv("GIT_COMMIT")),
+//	)
+//	schema := graphql.MustParseSchema(schemaString, resolver, graphql.Tracer(agent))
+//	if err := agent.Start(ctx); err != nil {
+//		log.Fatal(err)
+//	}
+//	defer agent.Stop(context.Background())
+package registry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"sync"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/introspection"
+	"github.com/graph-gophers/graphql-go/trace"
+)
+
+// Registry is the API of a schema registry.
+type Registry interface {
+	// PublishSchema publishes a new version of the schema.
+	PublishSchema(ctx context.Context, s *Schema) error
+
+	// ReportUsage reports a batch of executed operations.
+	ReportUsage(ctx context.Context, ops []*Operation) error
+}
+
+// Schema is a version of the schema published to the registry.
+type Schema struct {
+	SDL string `json:"sdl"`
+
+	// Service and URL identify the service in a federated graph.
+	Service string `json:"service,omitempty"`
+	URL     string `json:"url,omitempty"`
+
+	Author string `json:"author,omitempty"`
+	Commit string `json:"commit,omitempty"`
+
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// Operation is an executed operation reported to the registry.
+type Operation struct {
+	Name string `json:"name,omitempty"`
+
+	// Query is the query document, Hash is its hex encoded SHA-256 hash.
+	Query string `json:"query"`
+	Hash  string `json:"hash"`
+
+	Timestamp time.Time     `json:"timestamp"`
+	Duration  time.Duration `json:"duration"`
+
+	// Errors is the number of errors of the response.
+	Errors int `json:"errors"`
+
+	// Fields are the schema coordinates of the resolved fields, e.g. "Query.hero", in sorted order.
+	Fields []string `json:"fields"`
+
+	Client graphql.ClientInfo `json:"client"`
+}
+
+// Agent publishes a schema to a Registry and reports the operations it traces. It implements
+// trace.Tracer.
+type Agent struct {
+	registry Registry
+	schema   Schema
+
+	interval     time.Duration
+	maxBatchSize int
+	reportUsage  bool
+	tracer       trace.Tracer
+	onError      func(error)
+
+	mu  sync.Mutex
+	ops []*Operation
+
+	full chan struct{}
+	stop chan struct{}
+	done chan struct{}
+}
+
+var _ trace.Tracer = (*Agent)(nil)
+
+// Option configures an Agent.
+type Option func(*Agent)
+
+// Service sets the name and URL of the service in a federated graph.
+func Service(name, url string) Option {
+	return func(a *Agent) {
+		a.schema.Service = name
+		a.schema.URL = url
+	}
+}
+
+// Author sets the author of the published schema.
+func Author(author string) Option {
+	return func(a *Agent) {
+		a.schema.Author = author
+	}
+}
+
+// Commit sets the version control commit of the published schema.
+func Commit(commit string) Option {
+	return func(a *Agent) {
+		a.schema.Commit = commit
+	}
+}
+
+// Metadata adds a key-value pair to the metadata of the published schema.
+func Metadata(key, value string) Option {
+	return func(a *Agent) {
+		if a.schema.Metadata == nil {
+			a.schema.Metadata = make(map[string]string)
+		}
+		a.schema.Metadata[key] = value
+	}
+}
+
+// Interval is the time between two usage reports. It defaults to 10 seconds.
+func Interval(d time.Duration) Option {
+	return func(a *Agent) {
+		a.interval = d
+	}
+}
+
+// MaxBatchSize sends a usage report as soon as n operations were collected, before the interval
+// has passed. It defaults to 1000.
+func MaxBatchSize(n int) Option {
+	return func(a *Agent) {
+		a.maxBatchSize = n
+	}
+}
+
+// DisableUsageReporting only publishes the schema. Operations are not collected.
+func DisableUsageReporting() Option {
+	return func(a *Agent) {
+		a.reportUsage = false
+	}
+}
+
+// Tracer is called for every query and field as well, so the Agent can be combined with another
+// tracer. It defaults to trace.NoopTracer.
+func Tracer(tracer trace.Tracer) Option {
+	return func(a *Agent) {
+		a.tracer = tracer
+	}
+}
+
+// OnError is called with the errors of usage reports sent in the background. Errors are ignored by
+// default.
+func OnError(f func(error)) Option {
+	return func(a *Agent) {
+		a.onError = f
+	}
+}
+
+// New returns an Agent that publishes the given schema to the registry. The schema is parsed to
+// validate it.
+func New(r Registry, sdl string, opts ...Option) (*Agent, error) {
+	if _, err := graphql.ParseSchema(sdl, nil); err != nil {
+		return nil, err
+	}
+	a := &Agent{
+		registry:     r,
+		schema:       Schema{SDL: sdl},
+		interval:     10 * time.Second,
+		maxBatchSize: 1000,
+		reportUsage:  true,
+		tracer:       trace.NoopTracer{},
+		full:         make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a, nil
+}
+
+// Start publishes the schema and starts sending usage reports in the background until Stop is
+// called.
+func (a *Agent) Start(ctx context.Context) error {
+	schema := a.schema
+	if err := a.registry.PublishSchema(ctx, &schema); err != nil {
+		return err
+	}
+	if !a.reportUsage {
+		return nil
+	}
+
+	a.stop = make(chan struct{})
+	a.done = make(chan struct{})
+	go func() {
+		defer close(a.done)
+		ticker := time.NewTicker(a.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+			case <-a.full:
+			case <-a.stop:
+				return
+			}
+			if err := a.Flush(context.Background()); err != nil && a.onError != nil {
+				a.onError(err)
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop stops the usage reports started by Start and reports the remaining operations.
+func (a *Agent) Stop(ctx context.Context) error {
+	if a.stop != nil {
+		close(a.stop)
+		<-a.done
+		a.stop = nil
+	}
+	return a.Flush(ctx)
+}
+
+// Flush reports the operations collected since the last report. Nothing is sent if no operation was
+// executed.
+func (a *Agent) Flush(ctx context.Context) error {
+	a.mu.Lock()
+	ops := a.ops
+	a.ops = nil
+	a.mu.Unlock()
+
+	if len(ops) == 0 {
+		return nil
+	}
+	return a.registry.ReportUsage(ctx, ops)
+}
+
+type requestKey struct{}
+
+// request collects the fields resolved by a single request.
+type request struct {
+	mu     sync.Mutex
+	fields map[string]bool
+}
+
+func (a *Agent) TraceQuery(ctx context.Context, queryString string, operationName string, variables map[string]interface{}, varTypes map[string]*introspection.Type) (context.Context, trace.TraceQueryFinishFunc) {
+	ctx, finish := a.tracer.TraceQuery(ctx, queryString, operationName, variables, varTypes)
+	if !a.reportUsage {
+		return ctx, finish
+	}
+
+	req := &request{fields: make(map[string]bool)}
+	ctx = context.WithValue(ctx, requestKey{}, req)
+	op := &Operation{
+		Name:      operationName,
+		Query:     queryString,
+		Timestamp: time.Now(),
+	}
+	if info := graphql.OperationInfoFromContext(ctx); info != nil {
+		op.Name = info.Name
+		op.Hash = info.DocumentHash
+		op.Client = info.Client
+	} else {
+		hash := sha256.Sum256([]byte(queryString))
+		op.Hash = hex.EncodeToString(hash[:])
+	}
+
+	return ctx, func(errs []*errors.QueryError) {
+		finish(errs)
+		op.Duration = time.Since(op.Timestamp)
+		op.Errors = len(errs)
+		req.mu.Lock()
+		for f := range req.fields {
+			op.Fields = append(op.Fields, f)
+		}
+		req.mu.Unlock()
+		sort.Strings(op.Fields)
+		a.add(op)
+	}
+}
+
+func (a *Agent) TraceField(ctx context.Context, label, typeName, fieldName string, trivial bool, args map[string]interface{}) (context.Context, trace.TraceFieldFinishFunc) {
+	if req, ok := ctx.Value(requestKey{}).(*request); ok {
+		req.mu.Lock()
+		req.fields[typeName+"."+fieldName] = true
+		req.mu.Unlock()
+	}
+	return a.tracer.TraceField(ctx, label, typeName, fieldName, trivial, args)
+}
+
+func (a *Agent) add(op *Operation) {
+	a.mu.Lock()
+	a.ops = append(a.ops, op)
+	full := len(a.ops) >= a.maxBatchSize
+	a.mu.Unlock()
+
+	if full {
+		select {
+		case a.full <- struct{}{}:
+		default:
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package registry_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/example/starwars"
+	"github.com/graph-gophers/graphql-go/registry"
+)
+
+func TestAgent(t *testing.T) {
+	var published registry.Schema
+	var usage struct {
+		Operations []*registry.Operation
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		var err error
+		switch r.URL.Path {
+		case "/schema":
+			err = json.NewDecoder(r.Body).Decode(&published)
+		case "/usage":
+			err = json.NewDecoder(r.Body).Decode(&usage)
+		}
+		if err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	agent, err := registry.New(&registry.HTTP{
+		SchemaURL: server.URL + "/schema",
+		UsageURL:  server.URL + "/usage",
+		Header:    http.Header{"Authorization": {"secret"}},
+	}, starwars.Schema, registry.Service("starwars", "http://starwars/query"), registry.Metadata("region", "eu"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := agent.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if published.SDL != starwars.Schema || published.Service != "starwars" || published.Metadata["region"] != "eu" {
+		t.Fatalf("unexpected schema: %+v", published)
+	}
+
+	schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}, graphql.Tracer(agent))
+	ctx := graphql.WithClientInfo(context.Background(), graphql.ClientInfo{Name: "web", Version: "1.0"})
+	schema.Exec(ctx, `query Hero { hero { name } }`, "", nil)
+	schema.Exec(ctx, `{ hero { name friends { name } } }`, "", nil)
+	if err := agent.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(usage.Operations) != 2 {
+		t.Fatalf("expected 2 operations, got %d", len(usage.Operations))
+	}
+	op := usage.Operations[0]
+	if op.Name != "Hero" || len(op.Hash) != 64 || op.Client.Name != "web" || op.Errors != 0 {
+		t.Fatalf("unexpected operation: %+v", op)
+	}
+	if want := []string{"Character.friends", "Character.name", "Query.hero"}; !reflect.DeepEqual(usage.Operations[1].Fields, want) {
+		t.Fatalf("got fields %v, want %v", usage.Operations[1].Fields, want)
+	}
+}
+
+func TestHive(t *testing.T) {
+	var report struct {
+		Size       int
+		Map        map[string]struct{ Fields []string }
+		Operations []struct {
+			OperationMapKey string
+			Execution       struct{ OK bool }
+		}
+	}
+	var typename string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/graphql":
+			var req struct {
+				Query     string
+				Variables struct{ Input map[string]interface{} }
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			if !strings.Contains(req.Query, "schemaPublish") || req.Variables.Input["sdl"] != starwars.Schema {
+				t.Errorf("unexpected request: %+v", req)
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{
+					"schemaPublish": map[string]interface{}{
+						"__typename": typename,
+						"errors":     map[string]interface{}{"nodes": []interface{}{map[string]interface{}{"message": "breaking change"}}},
+					},
+				},
+			})
+		case "/usage":
+			json.NewDecoder(r.Body).Decode(&report)
+		}
+	}))
+	defer server.Close()
+
+	hive := &registry.Hive{Token: "token", Endpoint: server.URL + "/graphql", UsageEndpoint: server.URL + "/usage"}
+	agent, err := registry.New(hive, starwars.Schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	typename = "SchemaPublishError"
+	if err := agent.Start(context.Background()); err == nil || !strings.Contains(err.Error(), "breaking change") {
+		t.Fatalf("expected rejected schema, got %v", err)
+	}
+	typename = "SchemaPublishSuccess"
+	if err := agent.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}, graphql.Tracer(agent))
+	for i := 0; i < 2; i++ {
+		schema.Exec(context.Background(), `{ hero { name } }`, "", nil)
+	}
+	if err := agent.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Size != 2 || len(report.Map) != 1 || len(report.Operations) != 2 || !report.Operations[0].Execution.OK {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if fields := report.Map[report.Operations[0].OperationMapKey].Fields; len(fields) != 2 {
+		t.Fatalf("unexpected fields: %v", fields)
+	}
+}
@@ -0,0 +1,141 @@
+package relay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// Health serves the liveness and readiness probes of a GraphQL server, e.g. for Kubernetes.
+type Health struct {
+	// Schema returns the schema currently served, or nil while no schema could be built, e.g. if
+	// the schema is replaced at runtime. The server is not ready without a schema.
+	Schema func() *graphql.Schema
+
+	// Probe is a query executed by the readiness probe to verify that the schema executes, e.g.
+	// "{ __typename }". The server is not ready if it fails. Empty skips the query.
+	Probe string
+
+	// Checks are run by the readiness probe in parallel, e.g. to ping the databases used by the
+	// resolvers. The server is not ready if any of them fails.
+	Checks map[string]func(ctx context.Context) error
+
+	// Timeout limits the duration of the readiness probe. It defaults to 5 seconds.
+	Timeout time.Duration
+}
+
+// healthStatus is the JSON body of a probe response.
+type healthStatus struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// Register registers the liveness probe at "/healthz" and the readiness probe at "/readyz".
+func (h *Health) Register(mux *http.ServeMux) {
+	mux.Handle("/healthz", h.Liveness())
+	mux.Handle("/readyz", h.Readiness())
+}
+
+// Liveness returns a handler that responds with status 200 OK as long as the process serves
+// requests.
+func (h *Health) Liveness() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeHealth(w, &healthStatus{Status: "ok"})
+	})
+}
+
+// Readiness returns a handler that responds with status 200 OK if a schema is served, the probe
+// query succeeds and all checks pass, and with status 503 Service Unavailable otherwise. The body
+// lists the result of every check.
+func (h *Health) Readiness() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		timeout := h.Timeout
+		if timeout == 0 {
+			timeout = 5 * time.Second
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+		writeHealth(w, h.check(ctx))
+	})
+}
+
+func (h *Health) check(ctx context.Context) *healthStatus {
+	checks := make(map[string]func(ctx context.Context) error, len(h.Checks)+1)
+	for name, check := range h.Checks {
+		checks[name] = check
+	}
+	checks["schema"] = h.checkSchema
+
+	status := &healthStatus{Status: "ok", Checks: make(map[string]string, len(checks))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check func(ctx context.Context) error) {
+			defer wg.Done()
+			result := "ok"
+			if err := runCheck(ctx, check); err != nil {
+				result = err.Error()
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			status.Checks[name] = result
+			if result != "ok" {
+				status.Status = "unavailable"
+			}
+		}(name, check)
+	}
+	wg.Wait()
+	return status
+}
+
+// runCheck runs the check until it returns or ctx is done.
+func runCheck(ctx context.Context, check func(ctx context.Context) error) error {
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if v := recover(); v != nil {
+				done <- fmt.Errorf("panic: %v", v)
+			}
+		}()
+		done <- check(ctx)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (h *Health) checkSchema(ctx context.Context) error {
+	var s *graphql.Schema
+	if h.Schema != nil {
+		s = h.Schema()
+	}
+	if s == nil {
+		return fmt.Errorf("no schema")
+	}
+	if h.Probe == "" {
+		return nil
+	}
+	resp := s.Exec(ctx, h.Probe, "", nil)
+	if len(resp.Errors) != 0 {
+		return fmt.Errorf("probe failed: %s", resp.Errors[0].Message)
+	}
+	return nil
+}
+
+func writeHealth(w http.ResponseWriter, status *healthStatus) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if status.Status != "ok" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(status)
+}
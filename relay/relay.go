@@ -1,14 +1,22 @@
 package relay
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	graphql "github.com/graph-gophers/graphql-go"
+	qerrors "github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/requestcontext"
 )
 
 func MarshalID(kind string, spec interface{}) graphql.ID {
@@ -43,28 +51,338 @@ func UnmarshalSpec(id graphql.ID, v interface{}) error {
 	return json.Unmarshal([]byte(s[i+1:]), v)
 }
 
+// ErrCodePayloadTooLarge is the "code" extension of the error returned by Handler when the request
+// body or its variables exceed the configured limits.
+const ErrCodePayloadTooLarge = "PAYLOAD_TOO_LARGE"
+
 type Handler struct {
 	Schema *graphql.Schema
+
+	// MaxBodyBytes limits the size of the request body. The body is rejected as soon as the limit is
+	// exceeded, without reading the rest of it. Zero disables the limit.
+	MaxBodyBytes int64
+
+	// MaxVariablesBytes limits the size of the JSON encoded variables. They are rejected as soon
+	// as the limit is exceeded, without being read in full. Zero disables the limit.
+	MaxVariablesBytes int64
+
+	// ETag sets the ETag header of responses to the quoted graphql.ResponseHash of the response, or
+	// to the hash of its encoding by one of the Serializers. If the If-None-Match header of a request
+	// matches it, the response is answered with status 304 Not Modified and no body.
+	ETag bool
+
+	// ClientIdentity identifies the client application of a request, which is available as
+	// graphql.OperationInfo.Client and selects its graphql.ClientPolicy. Requests for which it
+	// returns a ClientInfo without a name are anonymous. It defaults to ApolloClientIdentity.
+	ClientIdentity func(r *http.Request) graphql.ClientInfo
+
+	// Serializers are the wire formats offered in addition to JSON, e.g. MessagePack and CBOR. The
+	// format is selected by the Accept header of the request; JSON is used if the header prefers no
+	// other format.
+	Serializers []Serializer
 }
 
-func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	var params struct {
-		Query         string                 `json:"query"`
-		OperationName string                 `json:"operationName"`
-		Variables     map[string]interface{} `json:"variables"`
+// ApolloClientIdentity identifies the client by the "apollographql-client-name" and
+// "apollographql-client-version" headers of the request.
+func ApolloClientIdentity(r *http.Request) graphql.ClientInfo {
+	return graphql.ClientInfo{
+		Name:    r.Header.Get("apollographql-client-name"),
+		Version: r.Header.Get("apollographql-client-version"),
 	}
-	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+}
+
+type params struct {
+	Query         string
+	OperationName string
+	Variables     map[string]interface{}
+	Extensions    map[string]interface{}
+}
+
+// payloadTooLargeError is returned when the request exceeds a limit of the Handler.
+type payloadTooLargeError string
+
+func (err payloadTooLargeError) Error() string {
+	return string(err)
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	params, err := h.decodeParams(r.Body)
+	if err != nil {
+		if err, ok := err.(payloadTooLargeError); ok {
+			writeError(w, http.StatusRequestEntityTooLarge, &qerrors.QueryError{
+				Message:    err.Error(),
+				Extensions: map[string]interface{}{"code": ErrCodePayloadTooLarge},
+			})
+			return
+		}
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	response := h.Schema.Exec(r.Context(), params.Query, params.OperationName, params.Variables)
-	responseJSON, err := json.Marshal(response)
+	ctx := requestcontext.WithStartTime(r.Context(), start)
+	identify := h.ClientIdentity
+	if identify == nil {
+		identify = ApolloClientIdentity
+	}
+	if client := identify(r); client.Name != "" {
+		ctx = graphql.WithClientInfo(ctx, client)
+	}
+	if locale := preferredLocale(r.Header.Get("Accept-Language")); locale != "" {
+		ctx = requestcontext.WithLocale(ctx, locale)
+	}
+	if params.Extensions != nil {
+		ctx = graphql.WithRequestExtensions(ctx, params.Extensions)
+	}
+
+	response := h.Schema.Exec(ctx, params.Query, params.OperationName, params.Variables)
+	if len(response.Errors) == 1 && response.Errors[0].Code() == graphql.ErrCodeShuttingDown {
+		// Make the client retry with another server.
+		w.Header().Set("Connection", "close")
+		writeError(w, http.StatusServiceUnavailable, response.Errors[0])
+		return
+	}
+	contentType := "application/json"
+	var responseJSON []byte
+	if s := h.serializer(r.Header.Get("Accept")); s != nil {
+		contentType = s.ContentType()
+		responseJSON, err = s.Serialize(response)
+	} else {
+		responseJSON, err = json.Marshal(response)
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	w.Header().Set("Content-Type", contentType)
+	if len(h.Serializers) != 0 {
+		w.Header().Add("Vary", "Accept")
+	}
+	if h.ETag {
+		hash := sha256.Sum256(responseJSON)
+		etag := `"` + hex.EncodeToString(hash[:]) + `"`
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	w.Write(responseJSON)
+}
+
+func writeError(w http.ResponseWriter, status int, err *qerrors.QueryError) {
+	responseJSON, _ := json.Marshal(&graphql.Response{Errors: []*qerrors.QueryError{err}})
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
 	w.Write(responseJSON)
 }
+
+// decodeParams decodes the request body one member at a time, so the limits are checked before
+// the whole body is read or the variables are decoded.
+func (h *Handler) decodeParams(body io.Reader) (*params, error) {
+	var lr *limitedReader
+	if h.MaxBodyBytes > 0 {
+		lr = &limitedReader{r: body, remaining: h.MaxBodyBytes}
+		body = lr
+	}
+	cr := &countingReader{r: body, limit: -1}
+	p, err := h.decodeMembers(json.NewDecoder(cr), cr)
+	if lr != nil && lr.remaining < 0 {
+		return nil, payloadTooLargeError(fmt.Sprintf("request body exceeds the maximum size of %d bytes", h.MaxBodyBytes))
+	}
+	return p, err
+}
+
+// decodeMembers decodes the members of the request. Like encoding/json decoding into a struct,
+// the names of the members are matched case-insensitively.
+func (h *Handler) decodeMembers(dec *json.Decoder, cr *countingReader) (*params, error) {
+	p := &params{}
+	if tok, err := dec.Token(); err != nil {
+		return nil, err
+	} else if tok != json.Delim('{') {
+		return nil, errors.New("request body is not a JSON object")
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		name, _ := tok.(string)
+		switch {
+		case strings.EqualFold(name, "query"):
+			err = dec.Decode(&p.Query)
+		case strings.EqualFold(name, "operationName"):
+			err = dec.Decode(&p.OperationName)
+		case strings.EqualFold(name, "variables"):
+			p.Variables, err = h.decodeVariables(dec, cr)
+		case strings.EqualFold(name, "extensions"):
+			err = dec.Decode(&p.Extensions)
+		default:
+			var ignored json.RawMessage
+			err = dec.Decode(&ignored)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// decodeVariables decodes the variables one at a time. The body is not read further than
+// MaxVariablesBytes past the start of the variables, so oversized variables are rejected without
+// reading them in full.
+func (h *Handler) decodeVariables(dec *json.Decoder, cr *countingReader) (map[string]interface{}, error) {
+	if h.MaxVariablesBytes <= 0 {
+		var variables map[string]interface{}
+		err := dec.Decode(&variables)
+		return variables, err
+	}
+	tooLarge := payloadTooLargeError(fmt.Sprintf("variables exceed the maximum size of %d bytes", h.MaxVariablesBytes))
+
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if tok == nil {
+		return nil, nil
+	}
+	if tok != json.Delim('{') {
+		return nil, errors.New("variables are not a JSON object")
+	}
+	start := cr.offset(dec) - 1
+	cr.limit = start + h.MaxVariablesBytes
+	defer func() { cr.limit = -1 }()
+
+	variables := make(map[string]interface{})
+	for dec.More() {
+		name, err := dec.Token()
+		if err != nil {
+			return nil, cr.checkLimit(err, tooLarge)
+		}
+		var v interface{}
+		if err := dec.Decode(&v); err != nil {
+			return nil, cr.checkLimit(err, tooLarge)
+		}
+		variables[name.(string)] = v
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, cr.checkLimit(err, tooLarge)
+	}
+	if cr.offset(dec)-start > h.MaxVariablesBytes {
+		return nil, tooLarge
+	}
+	return variables, nil
+}
+
+// countingReader counts the bytes read by a json.Decoder. While limit is not negative, it reads
+// at most one byte past the offset limit and fails any read after that.
+type countingReader struct {
+	r        io.Reader
+	read     int64
+	limit    int64
+	exceeded bool
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	if c.limit >= 0 && c.read > c.limit {
+		c.exceeded = true
+		return 0, io.ErrUnexpectedEOF
+	}
+	if c.limit >= 0 && int64(len(p)) > c.limit+1-c.read {
+		p = p[:c.limit+1-c.read]
+	}
+	n, err := c.r.Read(p)
+	c.read += int64(n)
+	return n, err
+}
+
+// offset returns the offset in the body up to which dec has decoded.
+func (c *countingReader) offset(dec *json.Decoder) int64 {
+	return c.read - int64(dec.Buffered().(*bytes.Reader).Len())
+}
+
+// checkLimit returns tooLarge instead of err if the decoder failed because of the limit.
+func (c *countingReader) checkLimit(err error, tooLarge error) error {
+	if c.exceeded {
+		return tooLarge
+	}
+	return err
+}
+
+// limitedReader reads at most one byte more than remaining, so exceeding the limit can be
+// distinguished from a body of exactly the maximum size.
+type limitedReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n, io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+// preferredLocale returns the language tag with the highest quality in an Accept-Language header.
+// Wildcards are ignored.
+func preferredLocale(header string) string {
+	var locale string
+	best := 0.0
+	for _, v := range qualityValues(header) {
+		if v.value == "*" || v.q <= best {
+			continue
+		}
+		locale, best = v.value, v.q
+	}
+	return locale
+}
+
+// qualityValue is an entry of an Accept or Accept-Language header with its quality.
+type qualityValue struct {
+	value string
+	q     float64
+}
+
+// qualityValues parses the entries of a header with quality values, e.g. "en;q=0.8, de". Entries
+// with an invalid quality are skipped.
+func qualityValues(header string) []qualityValue {
+	var values []qualityValue
+	for _, part := range strings.Split(header, ",") {
+		value := strings.TrimSpace(part)
+		q := 1.0
+		if i := strings.Index(value, ";"); i >= 0 {
+			valid := true
+			for _, param := range strings.Split(value[i+1:], ";") {
+				param = strings.TrimSpace(param)
+				if strings.HasPrefix(param, "q=") {
+					v, err := strconv.ParseFloat(param[2:], 64)
+					if err != nil {
+						valid = false
+					}
+					q = v
+				}
+			}
+			value = strings.TrimSpace(value[:i])
+			if !valid {
+				continue
+			}
+		}
+		if value == "" {
+			continue
+		}
+		values = append(values, qualityValue{value: value, q: q})
+	}
+	return values
+}
@@ -1,13 +1,20 @@
 package relay_test
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/graph-gophers/graphql-go"
 	"github.com/graph-gophers/graphql-go/example/starwars"
 	"github.com/graph-gophers/graphql-go/relay"
+	"github.com/graph-gophers/graphql-go/requestcontext"
 )
 
 var starwarsSchema = graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{})
@@ -34,3 +41,425 @@ func TestServeHTTP(t *testing.T) {
 		t.Fatalf("Invalid response. Expected [%s], but instead got [%s]", expectedResponse, actualResponse)
 	}
 }
+
+func TestServeHTTP_limits(t *testing.T) {
+	body := `{"query":"query($episode: Episode) { hero(episode: $episode) { name } }", "variables": {"episode": "EMPIRE"}}`
+
+	for _, test := range []struct {
+		name    string
+		handler relay.Handler
+		status  int
+		message string
+	}{
+		{
+			name:    "within limits",
+			handler: relay.Handler{Schema: starwarsSchema, MaxBodyBytes: int64(len(body)), MaxVariablesBytes: 21},
+			status:  http.StatusOK,
+		},
+		{
+			name:    "body too large",
+			handler: relay.Handler{Schema: starwarsSchema, MaxBodyBytes: int64(len(body)) - 1},
+			status:  http.StatusRequestEntityTooLarge,
+			message: fmt.Sprintf("request body exceeds the maximum size of %d bytes", len(body)-1),
+		},
+		{
+			name:    "variables too large",
+			handler: relay.Handler{Schema: starwarsSchema, MaxVariablesBytes: 20},
+			status:  http.StatusRequestEntityTooLarge,
+			message: "variables exceed the maximum size of 20 bytes",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			test.handler.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(body)))
+
+			if w.Code != test.status {
+				t.Fatalf("Expected status code %d, got %d: %s", test.status, w.Code, w.Body)
+			}
+			if test.message == "" {
+				return
+			}
+			var response struct {
+				Errors []struct {
+					Message    string
+					Extensions map[string]interface{}
+				}
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatal(err)
+			}
+			if len(response.Errors) != 1 || response.Errors[0].Message != test.message || response.Errors[0].Extensions["code"] != relay.ErrCodePayloadTooLarge {
+				t.Fatalf("Invalid response: %s", w.Body)
+			}
+		})
+	}
+}
+
+func TestServeHTTP_memberNamesIgnoreCase(t *testing.T) {
+	body := `{"Query":"query($episode: Episode) { hero(episode: $episode) { name } }", "VARIABLES": {"episode": "EMPIRE"}}`
+	for _, h := range []relay.Handler{
+		{Schema: starwarsSchema},
+		{Schema: starwarsSchema, MaxVariablesBytes: 100},
+	} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(body)))
+
+		expectedResponse := `{"data":{"hero":{"name":"Luke Skywalker"}}}`
+		if w.Code != http.StatusOK || w.Body.String() != expectedResponse {
+			t.Fatalf("Invalid response. Expected [%s], but instead got %d [%s]", expectedResponse, w.Code, w.Body)
+		}
+	}
+}
+
+// countingBody is a request body that counts the bytes read from it.
+type countingBody struct {
+	r    *strings.Reader
+	read int
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	b.read += n
+	return n, err
+}
+
+func TestServeHTTP_variablesRejectedEarly(t *testing.T) {
+	body := &countingBody{r: strings.NewReader(`{"query":"{ hero { name } }", "variables": {"padding": "` + strings.Repeat("x", 1<<20) + `"}}`)}
+	h := relay.Handler{Schema: starwarsSchema, MaxVariablesBytes: 100}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/", body))
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusRequestEntityTooLarge, w.Code, w.Body)
+	}
+	if body.read > 64<<10 {
+		t.Fatalf("Expected the variables not to be read in full, but %d bytes were read", body.read)
+	}
+}
+
+type requestContextResolver struct {
+	ctx context.Context
+}
+
+func (r *requestContextResolver) Hello(ctx context.Context) string {
+	r.ctx = ctx
+	return "Hello world!"
+}
+
+func TestServeHTTP_requestContext(t *testing.T) {
+	resolver := &requestContextResolver{}
+	h := relay.Handler{Schema: graphql.MustParseSchema(`type Query { hello: String! }`, resolver)}
+
+	before := time.Now()
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/query", strings.NewReader(`{"query":"query Greeting { hello }","extensions":{"traceId":"abc"}}`))
+	r.Header.Set("apollographql-client-name", "web")
+	r.Header.Set("apollographql-client-version", "1.2.3")
+	r.Header.Set("Accept-Language", "en;q=0.8, de-CH, *;q=0.5")
+	h.ServeHTTP(w, r)
+
+	if w.Code != 200 {
+		t.Fatalf("Expected status code 200, got %d.", w.Code)
+	}
+	if start, ok := requestcontext.StartTime(resolver.ctx); !ok || start.Before(before) {
+		t.Errorf("unexpected start time %v", start)
+	}
+	if client, _ := requestcontext.Client(resolver.ctx); client != (requestcontext.ClientInfo{Name: "web", Version: "1.2.3"}) {
+		t.Errorf("unexpected client %+v", client)
+	}
+	if name := requestcontext.OperationName(resolver.ctx); name != "Greeting" {
+		t.Errorf("unexpected operation name %q", name)
+	}
+	if locale := requestcontext.Locale(resolver.ctx); locale != "de-CH" {
+		t.Errorf("unexpected locale %q", locale)
+	}
+	if ext := requestcontext.Extensions(resolver.ctx); !reflect.DeepEqual(ext, map[string]interface{}{"traceId": "abc"}) {
+		t.Errorf("unexpected extensions %v", ext)
+	}
+}
+
+func TestServeHTTP_clientIdentity(t *testing.T) {
+	resolver := &requestContextResolver{}
+	h := relay.Handler{
+		Schema: graphql.MustParseSchema(`type Query { hello: String! }`, resolver),
+		ClientIdentity: func(r *http.Request) graphql.ClientInfo {
+			return graphql.ClientInfo{Name: r.Header.Get("X-Client")}
+		},
+	}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/query", strings.NewReader(`{"query":"{ hello }"}`))
+	r.Header.Set("X-Client", "ios")
+	r.Header.Set("apollographql-client-name", "web")
+	h.ServeHTTP(w, r)
+
+	if w.Code != 200 {
+		t.Fatalf("Expected status code 200, got %d.", w.Code)
+	}
+	if client, _ := requestcontext.Client(resolver.ctx); client != (requestcontext.ClientInfo{Name: "ios"}) {
+		t.Errorf("unexpected client %+v", client)
+	}
+}
+
+func TestServeHTTP_etag(t *testing.T) {
+	body := `{"query":"{ hero { name } }"}`
+	h := relay.Handler{Schema: starwarsSchema, ETag: true}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(body)))
+	resp := &graphql.Response{Data: json.RawMessage(`{"hero":{"name":"R2-D2"}}`)}
+	hash, err := graphql.ResponseHash(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || etag != `"`+hash+`"` {
+		t.Fatalf("unexpected status %d and ETag %q", w.Code, etag)
+	}
+
+	w = httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	r.Header.Set("If-None-Match", etag)
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Fatalf("unexpected status %d and body %q", w.Code, w.Body.String())
+	}
+}
+
+func TestServeHTTP_serializers(t *testing.T) {
+	h := relay.Handler{Schema: starwarsSchema, Serializers: []relay.Serializer{relay.MessagePack, relay.CBOR}}
+
+	for _, test := range []struct {
+		accept      string
+		contentType string
+		body        string
+	}{
+		{"", "application/json", `{"data":{"hero":{"name":"R2-D2"}}}`},
+		{"application/msgpack", "application/msgpack", "\x81\xa4data\x81\xa4hero\x81\xa4name\xa5R2-D2"},
+		{"application/cbor", "application/cbor", "\xa1\x64data\xa1\x64hero\xa1\x64name\x65R2-D2"},
+		{"application/json, application/cbor", "application/json", `{"data":{"hero":{"name":"R2-D2"}}}`},
+		{"application/msgpack, application/json", "application/json", `{"data":{"hero":{"name":"R2-D2"}}}`},
+		{"application/msgpack, application/cbor", "application/msgpack", "\x81\xa4data\x81\xa4hero\x81\xa4name\xa5R2-D2"},
+		{"application/json;q=0.5, application/cbor", "application/cbor", "\xa1\x64data\xa1\x64hero\xa1\x64name\x65R2-D2"},
+		{"application/*", "application/json", `{"data":{"hero":{"name":"R2-D2"}}}`},
+		{"text/html, */*;q=0.1", "application/json", `{"data":{"hero":{"name":"R2-D2"}}}`},
+		{"application/msgpack;q=0", "application/json", `{"data":{"hero":{"name":"R2-D2"}}}`},
+	} {
+		t.Run(test.accept, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "/", strings.NewReader(`{"query":"{ hero { name } }"}`))
+			r.Header.Set("Accept", test.accept)
+			h.ServeHTTP(w, r)
+
+			if contentType := w.Header().Get("Content-Type"); contentType != test.contentType {
+				t.Fatalf("expected content type %q, got %q", test.contentType, contentType)
+			}
+			if w.Header().Get("Vary") != "Accept" {
+				t.Fatalf("expected Vary header Accept, got %q", w.Header().Get("Vary"))
+			}
+			if got := w.Body.String(); got != test.body {
+				t.Fatalf("expected body %q, got %q", test.body, got)
+			}
+		})
+	}
+}
+
+func TestSerializers(t *testing.T) {
+	resp := &graphql.Response{Data: json.RawMessage(`{"n":-1,"i":300,"f":1.5,"b":true,"z":null,"l":[-40]}`)}
+
+	for _, test := range []struct {
+		serializer relay.Serializer
+		want       string
+	}{
+		{relay.MessagePack, "\x81\xa4data\x86\xa1n\xff\xa1i\xd1\x01\x2c\xa1f\xcb\x3f\xf8\x00\x00\x00\x00\x00\x00\xa1b\xc3\xa1z\xc0\xa1l\x91\xd0\xd8"},
+		{relay.CBOR, "\xa1\x64data\xa6\x61n\x20\x61i\x19\x01\x2c\x61f\xfb\x3f\xf8\x00\x00\x00\x00\x00\x00\x61b\xf5\x61z\xf6\x61l\x81\x38\x27"},
+	} {
+		t.Run(test.serializer.ContentType(), func(t *testing.T) {
+			got, err := test.serializer.Serialize(resp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.want {
+				t.Fatalf("expected %x, got %x", test.want, got)
+			}
+		})
+	}
+}
+
+func TestSerializers_transcoding(t *testing.T) {
+	// 24 entries need a 16-bit size in MessagePack and a one byte argument in CBOR.
+	list := "[" + strings.Repeat("0,", 23) + "0]"
+	zeros := strings.Repeat("\x00", 24)
+
+	for _, test := range []struct {
+		name       string
+		data       string
+		serializer relay.Serializer
+		want       string
+	}{
+		{"large containers", `{"l":` + list + `,"m":{"l":` + list + `}}`, relay.MessagePack, "\x81\xa4data\x82\xa1l\xdc\x00\x18" + zeros + "\xa1m\x81\xa1l\xdc\x00\x18" + zeros},
+		{"large containers", `{"l":` + list + `,"m":{"l":` + list + `}}`, relay.CBOR, "\xa1\x64data\xa2\x61l\x98\x18" + zeros + "\x61m\xa1\x61l\x98\x18" + zeros},
+		{"escaped string", `{"s":"a\"\u00e9","e":{},"f":-2.5e3}`, relay.MessagePack, "\x81\xa4data\x83\xa1s\xa4a\"\xc3\xa9\xa1e\x80\xa1f\xcb\xc0\xa3\x88\x00\x00\x00\x00\x00"},
+		{"escaped string", `{"s":"a\"\u00e9","e":{},"f":-2.5e3}`, relay.CBOR, "\xa1\x64data\xa3\x61s\x64a\"\xc3\xa9\x61e\xa0\x61f\xfb\xc0\xa3\x88\x00\x00\x00\x00\x00"},
+	} {
+		t.Run(test.name+" "+test.serializer.ContentType(), func(t *testing.T) {
+			got, err := test.serializer.Serialize(&graphql.Response{Data: json.RawMessage(test.data)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.want {
+				t.Fatalf("expected %x, got %x", test.want, got)
+			}
+		})
+	}
+}
+
+func BenchmarkSerializers(b *testing.B) {
+	resp := starwarsSchema.Exec(context.Background(), `{ hero { name friends { name appearsIn friends { name } } } }`, "", nil)
+
+	b.Run("application/json", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := json.Marshal(resp); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	for _, s := range []relay.Serializer{relay.MessagePack, relay.CBOR} {
+		s := s
+		b.Run(s.ContentType(), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := s.Serialize(resp); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+type versionedResolver struct{}
+
+func (versionedResolver) Name() string     { return "Alice Liddell" }
+func (versionedResolver) FullName() string { return "Alice Pleasance Liddell" }
+
+func TestVersionRouter(t *testing.T) {
+	router, err := relay.NewVersionRouter(relay.Handler{MaxBodyBytes: 1 << 10}, &versionedResolver{}, map[string]string{
+		"v1": `type Query { name: String! }`,
+		"v2": `type Query { fullName: String! }`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	router.Select = relay.PathVersion("/graphql/")
+	router.Default = "v2"
+
+	for _, tt := range []struct {
+		path       string
+		query      string
+		wantStatus int
+		wantBody   string
+	}{
+		{"/graphql/v1", "{ name }", 200, `{"data":{"name":"Alice Liddell"}}`},
+		{"/graphql/v1", "{ fullName }", 200, `{"errors":[{"message":"Cannot query field \"fullName\" on type \"Query\".","locations":[{"line":1,"column":3}]}]}`},
+		{"/graphql/v2/", "{ fullName }", 200, `{"data":{"fullName":"Alice Pleasance Liddell"}}`},
+		{"/graphql", "{ fullName }", 200, `{"data":{"fullName":"Alice Pleasance Liddell"}}`},
+		{"/graphql/v3", "{ name }", 404, `{"errors":[{"message":"unknown schema version \"v3\", expected one of v1, v2","extensions":{"code":"UNKNOWN_SCHEMA_VERSION"}}]}`},
+	} {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", tt.path, strings.NewReader(fmt.Sprintf(`{"query":%q}`, tt.query)))
+		router.ServeHTTP(w, r)
+
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s %s: expected status code %d, got %d", tt.path, tt.query, tt.wantStatus, w.Code)
+		}
+		if body := w.Body.String(); body != tt.wantBody {
+			t.Errorf("%s %s: unexpected response %s", tt.path, tt.query, body)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/query", strings.NewReader(strings.Repeat(" ", 2<<10)))
+	router.ServeHTTP(w, r)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected status code 413, got %d", w.Code)
+	}
+}
+
+func TestHeaderVersion(t *testing.T) {
+	router := &relay.VersionRouter{}
+	router.Register("2024-01", graphql.MustParseSchema(`type Query { name: String! }`, &versionedResolver{}))
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/query", strings.NewReader(`{"query":"{ name }"}`))
+	r.Header.Set("GraphQL-Schema-Version", "2024-01")
+	router.ServeHTTP(w, r)
+	if body := w.Body.String(); body != `{"data":{"name":"Alice Liddell"}}` {
+		t.Errorf("unexpected response %s", body)
+	}
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("POST", "/query", strings.NewReader(`{"query":"{ name }"}`))
+	router.ServeHTTP(w, r)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status code 404, got %d", w.Code)
+	}
+}
+
+func TestHealth(t *testing.T) {
+	var schema *graphql.Schema
+	dbErr := fmt.Errorf("connection refused")
+	h := &relay.Health{
+		Schema: func() *graphql.Schema { return schema },
+		Probe:  "{ __typename }",
+		Checks: map[string]func(ctx context.Context) error{
+			"db": func(ctx context.Context) error { return dbErr },
+		},
+	}
+	mux := http.NewServeMux()
+	h.Register(mux)
+
+	probe := func(path string) (int, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		var body map[string]interface{}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		return w.Code, body
+	}
+
+	if code, _ := probe("/healthz"); code != http.StatusOK {
+		t.Errorf("liveness: got status %d", code)
+	}
+	code, body := probe("/readyz")
+	want := map[string]interface{}{"status": "unavailable", "checks": map[string]interface{}{"schema": "no schema", "db": "connection refused"}}
+	if code != http.StatusServiceUnavailable || !reflect.DeepEqual(body, want) {
+		t.Errorf("readiness without schema: got %d %v", code, body)
+	}
+
+	schema, dbErr = starwarsSchema, nil
+	code, body = probe("/readyz")
+	want = map[string]interface{}{"status": "ok", "checks": map[string]interface{}{"schema": "ok", "db": "ok"}}
+	if code != http.StatusOK || !reflect.DeepEqual(body, want) {
+		t.Errorf("readiness: got %d %v", code, body)
+	}
+}
+
+func TestServeHTTP_shutdown(t *testing.T) {
+	schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{})
+	if err := schema.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", strings.NewReader(`{"query":"{ hero { name } }"}`))
+	(&relay.Handler{Schema: schema}).ServeHTTP(w, r)
+
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Connection") != "close" {
+		t.Fatalf("got status %d, headers %v", w.Code, w.Header())
+	}
+	if want := `{"errors":[{"message":"schema is shutting down","extensions":{"code":"SHUTTING_DOWN"}}]}`; w.Body.String() != want {
+		t.Errorf("got %s, want %s", w.Body, want)
+	}
+}
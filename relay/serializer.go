@@ -0,0 +1,410 @@
+package relay
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// Serializer encodes responses in a wire format other than JSON, e.g. for internal clients that
+// prefer a binary format. Handler selects it by the Accept header of the request.
+type Serializer interface {
+	// ContentType is the media type of the format, e.g. "application/msgpack".
+	ContentType() string
+
+	// Serialize encodes the response.
+	Serialize(resp *graphql.Response) ([]byte, error)
+}
+
+// MessagePack encodes responses as MessagePack with the media type "application/msgpack".
+// CBOR encodes responses as CBOR with the media type "application/cbor". Both transcode the JSON
+// written by the executor in a single pass over its tokens, so the resolvers are not called again
+// and no Go values are reflected or decoded. Object members keep their order, integers are encoded
+// as integers and other numbers as 64-bit floats.
+var (
+	MessagePack Serializer = transcoder{"application/msgpack", msgpackFormat{}}
+	CBOR        Serializer = transcoder{"application/cbor", cborFormat{}}
+)
+
+// serializer returns the serializer preferred by the Accept header of a request, or nil for JSON,
+// which is preferred if it is acceptable with the same quality.
+func (h *Handler) serializer(accept string) Serializer {
+	if len(h.Serializers) == 0 || accept == "" {
+		return nil
+	}
+	var preferred Serializer
+	best, jsonQ := 0.0, 0.0
+	for _, v := range qualityValues(accept) {
+		if mediaTypeMatches(v.value, "application/json") {
+			if v.q > jsonQ {
+				jsonQ = v.q
+			}
+			continue
+		}
+		if v.q <= best {
+			continue
+		}
+		for _, s := range h.Serializers {
+			if mediaTypeMatches(v.value, s.ContentType()) {
+				preferred, best = s, v.q
+				break
+			}
+		}
+	}
+	if jsonQ >= best {
+		return nil
+	}
+	return preferred
+}
+
+// mediaTypeMatches reports whether a media range of an Accept header, e.g. "application/*",
+// matches the media type.
+func mediaTypeMatches(mediaRange, mediaType string) bool {
+	mediaRange = strings.ToLower(mediaRange)
+	if mediaRange == "*/*" || mediaRange == mediaType {
+		return true
+	}
+	return strings.HasSuffix(mediaRange, "/*") && strings.HasPrefix(mediaType, mediaRange[:len(mediaRange)-1])
+}
+
+// transcoder is a Serializer converting the JSON encoding of responses.
+type transcoder struct {
+	contentType string
+	format      format
+}
+
+func (t transcoder) ContentType() string {
+	return t.contentType
+}
+
+func (t transcoder) Serialize(resp *graphql.Response) ([]byte, error) {
+	responseJSON, err := json.Marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+	e := &encoder{format: t.format}
+	if err := e.transcode(responseJSON); err != nil {
+		return nil, err
+	}
+	return e.bytes(), nil
+}
+
+// format writes JSON values in a binary format.
+type format interface {
+	appendNull(b []byte) []byte
+	appendBool(b []byte, v bool) []byte
+	appendString(b []byte, s []byte) []byte
+	appendInt(b []byte, i int64) []byte
+	appendFloat(b []byte, f float64) []byte
+
+	// appendHead appends the head of an array or map with n entries.
+	appendHead(b []byte, isMap bool, n int) []byte
+}
+
+// maxHeadSize is the size of the largest head of an array or map with up to math.MaxUint32
+// entries in both formats.
+const maxHeadSize = 5
+
+// encoder writes JSON in a binary format in a single pass over its tokens. Both formats write the
+// number of entries of an array or map before them, so space for the largest head is reserved when
+// it is opened, the head is written when it is closed and the unused space is removed at the end.
+type encoder struct {
+	format format
+	buf    []byte
+	open   []container
+	heads  []head
+}
+
+// container is an array or map that is being written.
+type container struct {
+	pos     int
+	isMap   bool
+	n       int
+	nextKey bool
+}
+
+// head is a head written into the space reserved at pos.
+type head struct {
+	pos  int
+	size int
+}
+
+// transcode writes the JSON encoded by encoding/json, which is valid and has no insignificant
+// whitespace.
+func (e *encoder) transcode(data []byte) error {
+	for i := 0; i < len(data); {
+		switch c := data[i]; c {
+		case ',', ':':
+			i++
+		case '{', '[':
+			e.startValue()
+			e.open = append(e.open, container{pos: len(e.buf), isMap: c == '{', nextKey: true})
+			e.buf = append(e.buf, make([]byte, maxHeadSize)...)
+			i++
+		case '}', ']':
+			if err := e.closeContainer(); err != nil {
+				return err
+			}
+			i++
+		case '"':
+			end := stringEnd(data, i)
+			if end < 0 {
+				return fmt.Errorf("unterminated string at offset %d", i)
+			}
+			s := data[i+1 : end-1]
+			if bytes.IndexByte(s, '\\') >= 0 {
+				var unquoted string
+				if err := json.Unmarshal(data[i:end], &unquoted); err != nil {
+					return err
+				}
+				s = []byte(unquoted)
+			}
+			e.startValue()
+			e.buf = e.format.appendString(e.buf, s)
+			i = end
+		case 't', 'f', 'n':
+			e.startValue()
+			switch {
+			case bytes.HasPrefix(data[i:], []byte("true")):
+				e.buf = e.format.appendBool(e.buf, true)
+				i += len("true")
+			case bytes.HasPrefix(data[i:], []byte("false")):
+				e.buf = e.format.appendBool(e.buf, false)
+				i += len("false")
+			case bytes.HasPrefix(data[i:], []byte("null")):
+				e.buf = e.format.appendNull(e.buf)
+				i += len("null")
+			default:
+				return fmt.Errorf("invalid literal at offset %d", i)
+			}
+		default:
+			end := i
+			for end < len(data) && strings.IndexByte("+-0123456789.eE", data[end]) >= 0 {
+				end++
+			}
+			if end == i {
+				return fmt.Errorf("invalid character %q at offset %d", c, i)
+			}
+			e.startValue()
+			if err := e.writeNumber(string(data[i:end])); err != nil {
+				return err
+			}
+			i = end
+		}
+	}
+	if len(e.open) != 0 {
+		return fmt.Errorf("unexpected end of JSON")
+	}
+	return nil
+}
+
+// stringEnd returns the offset after the string starting with the quote at i, or -1.
+func stringEnd(data []byte, i int) int {
+	for j := i + 1; j < len(data); j++ {
+		switch data[j] {
+		case '\\':
+			j++
+		case '"':
+			return j + 1
+		}
+	}
+	return -1
+}
+
+// startValue counts a value or key in the enclosing container. Maps count their keys, arrays their
+// elements.
+func (e *encoder) startValue() {
+	if len(e.open) == 0 {
+		return
+	}
+	c := &e.open[len(e.open)-1]
+	if !c.isMap || c.nextKey {
+		c.n++
+	}
+	if c.isMap {
+		c.nextKey = !c.nextKey
+	}
+}
+
+func (e *encoder) closeContainer() error {
+	if len(e.open) == 0 {
+		return fmt.Errorf("unexpected end of container")
+	}
+	c := e.open[len(e.open)-1]
+	e.open = e.open[:len(e.open)-1]
+	if c.n > math.MaxUint32 {
+		return fmt.Errorf("too many entries: %d", c.n)
+	}
+	var b [maxHeadSize]byte
+	h := e.format.appendHead(b[:0], c.isMap, c.n)
+	copy(e.buf[c.pos:], h)
+	e.heads = append(e.heads, head{c.pos, len(h)})
+	return nil
+}
+
+// writeNumber writes integers as integers and other numbers as 64-bit floats.
+func (e *encoder) writeNumber(s string) error {
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		e.buf = e.format.appendInt(e.buf, i)
+		return nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return err
+	}
+	e.buf = e.format.appendFloat(e.buf, f)
+	return nil
+}
+
+// bytes removes the unused space reserved for heads in place and returns the encoding.
+func (e *encoder) bytes() []byte {
+	sort.Slice(e.heads, func(i, j int) bool { return e.heads[i].pos < e.heads[j].pos })
+	w, r := 0, 0
+	for _, h := range e.heads {
+		w += copy(e.buf[w:], e.buf[r:h.pos+h.size])
+		r = h.pos + maxHeadSize
+	}
+	w += copy(e.buf[w:], e.buf[r:])
+	return e.buf[:w]
+}
+
+type msgpackFormat struct{}
+
+func (msgpackFormat) appendNull(b []byte) []byte {
+	return append(b, 0xc0)
+}
+
+func (msgpackFormat) appendBool(b []byte, v bool) []byte {
+	if v {
+		return append(b, 0xc3)
+	}
+	return append(b, 0xc2)
+}
+
+func (msgpackFormat) appendString(b []byte, s []byte) []byte {
+	n := len(s)
+	switch {
+	case n < 32:
+		b = append(b, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		b = append(b, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		b = appendUint(b, 0xda, uint64(n), 2)
+	default:
+		b = appendUint(b, 0xdb, uint64(n), 4)
+	}
+	return append(b, s...)
+}
+
+func (msgpackFormat) appendInt(b []byte, i int64) []byte {
+	switch {
+	case i >= 0 && i <= 127:
+		return append(b, byte(i))
+	case i < 0 && i >= -32:
+		return append(b, byte(int8(i)))
+	case i >= math.MinInt8 && i <= math.MaxInt8:
+		return append(b, 0xd0, byte(int8(i)))
+	case i >= math.MinInt16 && i <= math.MaxInt16:
+		return appendUint(b, 0xd1, uint64(uint16(int16(i))), 2)
+	case i >= math.MinInt32 && i <= math.MaxInt32:
+		return appendUint(b, 0xd2, uint64(uint32(int32(i))), 4)
+	default:
+		return appendUint(b, 0xd3, uint64(i), 8)
+	}
+}
+
+func (msgpackFormat) appendFloat(b []byte, f float64) []byte {
+	return appendUint(b, 0xcb, math.Float64bits(f), 8)
+}
+
+// appendHead writes the size of an array or map with the fix format or the 16 or 32 bit format,
+// whose code follows the 16 bit one.
+func (msgpackFormat) appendHead(b []byte, isMap bool, n int) []byte {
+	fix, code16 := byte(0x90), byte(0xdc)
+	if isMap {
+		fix, code16 = 0x80, 0xde
+	}
+	switch {
+	case n < 16:
+		return append(b, fix|byte(n))
+	case n <= math.MaxUint16:
+		return appendUint(b, code16, uint64(n), 2)
+	default:
+		return appendUint(b, code16+1, uint64(n), 4)
+	}
+}
+
+// The major types of CBOR.
+const (
+	cborUint   = 0
+	cborNegint = 1
+	cborText   = 3
+	cborArray  = 4
+	cborMap    = 5
+)
+
+type cborFormat struct{}
+
+func (cborFormat) appendNull(b []byte) []byte {
+	return append(b, 0xf6)
+}
+
+func (cborFormat) appendBool(b []byte, v bool) []byte {
+	if v {
+		return append(b, 0xf5)
+	}
+	return append(b, 0xf4)
+}
+
+func (cborFormat) appendString(b []byte, s []byte) []byte {
+	return append(appendCBORHead(b, cborText, uint64(len(s))), s...)
+}
+
+func (cborFormat) appendInt(b []byte, i int64) []byte {
+	if i >= 0 {
+		return appendCBORHead(b, cborUint, uint64(i))
+	}
+	return appendCBORHead(b, cborNegint, uint64(-1-i))
+}
+
+func (cborFormat) appendFloat(b []byte, f float64) []byte {
+	return appendUint(b, 0xfb, math.Float64bits(f), 8)
+}
+
+func (cborFormat) appendHead(b []byte, isMap bool, n int) []byte {
+	if isMap {
+		return appendCBORHead(b, cborMap, uint64(n))
+	}
+	return appendCBORHead(b, cborArray, uint64(n))
+}
+
+// appendCBORHead writes the initial byte of a data item of the major type with the argument n.
+func appendCBORHead(b []byte, major byte, n uint64) []byte {
+	major <<= 5
+	switch {
+	case n < 24:
+		return append(b, major|byte(n))
+	case n <= math.MaxUint8:
+		return append(b, major|24, byte(n))
+	case n <= math.MaxUint16:
+		return appendUint(b, major|25, n, 2)
+	case n <= math.MaxUint32:
+		return appendUint(b, major|26, n, 4)
+	default:
+		return appendUint(b, major|27, n, 8)
+	}
+}
+
+// appendUint writes the code followed by n in big-endian order with the given number of bytes.
+func appendUint(b []byte, code byte, n uint64, size int) []byte {
+	var u [8]byte
+	binary.BigEndian.PutUint64(u[:], n)
+	return append(append(b, code), u[8-size:]...)
+}
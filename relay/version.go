@@ -0,0 +1,117 @@
+package relay
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	qerrors "github.com/graph-gophers/graphql-go/errors"
+)
+
+// ErrCodeUnknownVersion is the "code" extension of the error returned by VersionRouter for
+// requests selecting a version that is not registered.
+const ErrCodeUnknownVersion = "UNKNOWN_SCHEMA_VERSION"
+
+// VersionSelector returns the schema version requested by r, or "" for the default version.
+type VersionSelector func(r *http.Request) string
+
+// HeaderVersion selects the version by the value of the header, e.g. "GraphQL-Schema-Version".
+func HeaderVersion(header string) VersionSelector {
+	return func(r *http.Request) string {
+		return r.Header.Get(header)
+	}
+}
+
+// PathVersion selects the version by the path segment following the prefix, e.g. "v1" for
+// "/graphql/v1" with the prefix "/graphql/".
+func PathVersion(prefix string) VersionSelector {
+	return func(r *http.Request) string {
+		if !strings.HasPrefix(r.URL.Path, prefix) {
+			return ""
+		}
+		version := strings.TrimPrefix(r.URL.Path, prefix)
+		if i := strings.IndexByte(version, '/'); i != -1 {
+			version = version[:i]
+		}
+		return version
+	}
+}
+
+// VersionRouter serves several versions of a schema under version labels, e.g. to keep serving
+// mobile clients pinned to an older version. Each request is passed to the handler of the version
+// it selects. The zero value serves no versions.
+type VersionRouter struct {
+	// Select selects the version of a request. It defaults to HeaderVersion("GraphQL-Schema-Version").
+	Select VersionSelector
+
+	// Default is the version of requests not selecting one. Such requests are rejected if it is
+	// empty.
+	Default string
+
+	handlers map[string]http.Handler
+}
+
+// NewVersionRouter parses the schema of each version with the same resolver, so that resolver
+// instances are shared across versions, and serves each with a Handler copied from handler. The
+// resolver must resolve the fields of all versions, e.g. with the union of their methods.
+func NewVersionRouter(handler Handler, resolver interface{}, schemas map[string]string, opts ...graphql.SchemaOpt) (*VersionRouter, error) {
+	router := &VersionRouter{}
+	for version, schemaString := range schemas {
+		s, err := graphql.ParseSchema(schemaString, resolver, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("schema version %q: %s", version, err)
+		}
+		h := handler
+		h.Schema = s
+		router.Handle(version, &h)
+	}
+	return router, nil
+}
+
+// Register serves the schema under the version with a Handler without limits.
+func (v *VersionRouter) Register(version string, schema *graphql.Schema) {
+	v.Handle(version, &Handler{Schema: schema})
+}
+
+// Handle serves the version with h, which replaces the handler previously registered for the
+// version.
+func (v *VersionRouter) Handle(version string, h http.Handler) {
+	if v.handlers == nil {
+		v.handlers = make(map[string]http.Handler)
+	}
+	v.handlers[version] = h
+}
+
+// Versions returns the registered versions in lexical order.
+func (v *VersionRouter) Versions() []string {
+	versions := make([]string, 0, len(v.handlers))
+	for version := range v.handlers {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+func (v *VersionRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	selectVersion := v.Select
+	if selectVersion == nil {
+		selectVersion = HeaderVersion("GraphQL-Schema-Version")
+	}
+	version := selectVersion(r)
+	if version == "" {
+		version = v.Default
+	}
+	h, ok := v.handlers[version]
+	if !ok {
+		writeError(w, http.StatusNotFound, &qerrors.QueryError{
+			Message:    fmt.Sprintf("unknown schema version %q, expected one of %s", version, strings.Join(v.Versions(), ", ")),
+			Extensions: map[string]interface{}{"code": ErrCodeUnknownVersion},
+		})
+		return
+	}
+	h.ServeHTTP(w, r)
+}
+
+var _ http.Handler = (*VersionRouter)(nil)
@@ -0,0 +1,106 @@
+// Package requestcontext provides typed accessors for the data of a GraphQL request that is stored
+// in its context. The values are set by relay.Handler and by the schema when an operation is
+// executed, so resolvers, tracers and loggers can read them without defining their own keys.
+package requestcontext
+
+import (
+	"context"
+	"time"
+)
+
+type operationNameKey struct{}
+type queryKey struct{}
+type variablesKey struct{}
+type startTimeKey struct{}
+type clientKey struct{}
+type localeKey struct{}
+type extensionsKey struct{}
+
+// ClientInfo identifies the client application of a request, e.g. from the
+// "apollographql-client-name" and "apollographql-client-version" headers.
+type ClientInfo struct {
+	Name    string
+	Version string
+}
+
+// WithOperationName returns a context that carries the name of the executed operation.
+func WithOperationName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, operationNameKey{}, name)
+}
+
+// OperationName returns the name of the executed operation, which is empty for anonymous
+// operations or if the context does not belong to a request.
+func OperationName(ctx context.Context) string {
+	name, _ := ctx.Value(operationNameKey{}).(string)
+	return name
+}
+
+// WithQuery returns a context that carries the query document of the request.
+func WithQuery(ctx context.Context, query string) context.Context {
+	return context.WithValue(ctx, queryKey{}, query)
+}
+
+// Query returns the query document of the request as sent by the client.
+func Query(ctx context.Context) string {
+	query, _ := ctx.Value(queryKey{}).(string)
+	return query
+}
+
+// WithVariables returns a context that carries the variables of the request.
+func WithVariables(ctx context.Context, variables map[string]interface{}) context.Context {
+	return context.WithValue(ctx, variablesKey{}, variables)
+}
+
+// Variables returns the variables of the request. They must not be modified.
+func Variables(ctx context.Context) map[string]interface{} {
+	variables, _ := ctx.Value(variablesKey{}).(map[string]interface{})
+	return variables
+}
+
+// WithStartTime returns a context that carries the time the request was received.
+func WithStartTime(ctx context.Context, t time.Time) context.Context {
+	return context.WithValue(ctx, startTimeKey{}, t)
+}
+
+// StartTime returns the time the request was received. The second result is false if it is not
+// stored in the context.
+func StartTime(ctx context.Context) (time.Time, bool) {
+	t, ok := ctx.Value(startTimeKey{}).(time.Time)
+	return t, ok
+}
+
+// WithClient returns a context that carries the client of the request.
+func WithClient(ctx context.Context, client ClientInfo) context.Context {
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+// Client returns the client of the request. The second result is false if the client did not
+// identify itself.
+func Client(ctx context.Context) (ClientInfo, bool) {
+	client, ok := ctx.Value(clientKey{}).(ClientInfo)
+	return client, ok
+}
+
+// WithLocale returns a context that carries the preferred locale of the client, e.g. "de-CH".
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// Locale returns the preferred locale of the client, which is empty if it is unknown.
+func Locale(ctx context.Context) string {
+	locale, _ := ctx.Value(localeKey{}).(string)
+	return locale
+}
+
+// WithExtensions returns a context that carries the "extensions" member of the request, e.g. the
+// "persistedQuery" of automatic persisted queries or a tracing ID set by the client.
+func WithExtensions(ctx context.Context, extensions map[string]interface{}) context.Context {
+	return context.WithValue(ctx, extensionsKey{}, extensions)
+}
+
+// Extensions returns the "extensions" member of the request, or nil if the request has none. They
+// must not be modified.
+func Extensions(ctx context.Context) map[string]interface{} {
+	extensions, _ := ctx.Value(extensionsKey{}).(map[string]interface{})
+	return extensions
+}
@@ -0,0 +1,174 @@
+package graphql
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/graph-gophers/graphql-go/internal/exec/resolvable"
+)
+
+// ResolverReport lists how the fields reachable from the root operation types are bound to Go
+// resolvers, see Schema.CheckResolvers.
+type ResolverReport struct {
+	// Bindings are sorted by type, field and resolver. A field is listed once per Go type
+	// resolving its parent type.
+	Bindings []*ResolverBinding
+}
+
+// ResolverBinding describes the Go method or struct field resolving a GraphQL field.
+type ResolverBinding struct {
+	// Type and Field name the GraphQL field, e.g. "Query" and "hero".
+	Type  string
+	Field string
+
+	// Resolver is the Go type of the resolver, e.g. "*starwars.Resolver".
+	Resolver string
+
+	// Method is the name of the resolving method, or of the struct field if StructField is set.
+	Method      string
+	StructField bool
+
+	// Result is the Go type of the resolved value.
+	Result string
+
+	HasContext bool
+	HasArgs    bool
+	HasError   bool
+
+	// Async reports whether the field is resolved in its own goroutine, which is the case if the
+	// method takes a context or arguments or returns an error. Other fields are only resolved
+	// asynchronously if they select asynchronous fields themselves.
+	Async bool
+
+	// Serial reports whether the query root field is resolved serially, see SerialQueryFields.
+	Serial bool
+}
+
+// String returns the signature of the binding, e.g.
+// "Query.hero: (*starwars.Resolver).Hero(args) *starwars.characterResolver async".
+func (b *ResolverBinding) String() string {
+	var params []string
+	if b.HasContext {
+		params = append(params, "ctx")
+	}
+	if b.HasArgs {
+		params = append(params, "args")
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s.%s: (%s).%s", b.Type, b.Field, b.Resolver, b.Method)
+	if !b.StructField {
+		fmt.Fprintf(&sb, "(%s)", strings.Join(params, ", "))
+	}
+	if b.HasError {
+		fmt.Fprintf(&sb, " (%s, error)", b.Result)
+	} else {
+		fmt.Fprintf(&sb, " %s", b.Result)
+	}
+	if b.Async {
+		sb.WriteString(" async")
+	}
+	if b.Serial {
+		sb.WriteString(" serial")
+	}
+	return sb.String()
+}
+
+// String returns one line per binding, which is suitable to be checked in and reviewed.
+func (r *ResolverReport) String() string {
+	var sb strings.Builder
+	for _, b := range r.Bindings {
+		sb.WriteString(b.String())
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// CheckResolvers returns a report of the Go methods and struct fields resolving the fields of the
+// schema, e.g. to review the resolver coverage or to catch accidental changes between synchronous
+// and asynchronous resolvers. It returns an error if the schema has no resolver.
+func (s *Schema) CheckResolvers() (*ResolverReport, error) {
+	if s.res.Resolver == (reflect.Value{}) {
+		return nil, fmt.Errorf("schema created without resolver, can not check resolvers")
+	}
+
+	report := &ResolverReport{}
+	visited := make(map[*resolvable.Object]bool)
+	// The same resolver type may be used for several objects, e.g. for nullable and non-null types.
+	reported := make(map[string]bool)
+	var visit func(r resolvable.Resolvable)
+	visit = func(r resolvable.Resolvable) {
+		switch r := r.(type) {
+		case *resolvable.List:
+			visit(r.Elem)
+		case *resolvable.Object:
+			if visited[r] {
+				return
+			}
+			visited[r] = true
+			for _, f := range r.Fields {
+				b := newResolverBinding(r, f)
+				if key := b.Type + "." + b.Field + " " + b.Resolver; !reported[key] {
+					reported[key] = true
+					report.Bindings = append(report.Bindings, b)
+				}
+				visit(f.ValueExec)
+			}
+			for _, a := range r.TypeAssertions {
+				visit(a.TypeExec)
+			}
+		}
+	}
+	visit(s.res.Query)
+	visit(s.res.Mutation)
+	visit(s.res.Subscription)
+
+	sort.Slice(report.Bindings, func(i, j int) bool {
+		a, b := report.Bindings[i], report.Bindings[j]
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		if a.Field != b.Field {
+			return a.Field < b.Field
+		}
+		return a.Resolver < b.Resolver
+	})
+	return report, nil
+}
+
+func newResolverBinding(obj *resolvable.Object, f *resolvable.Field) *ResolverBinding {
+	resolverType := obj.ResolverType
+	if obj.Parts != nil {
+		resolverType = obj.Parts[f.Part]
+	}
+	if len(f.Via) != 0 {
+		// Hoisted fields are resolved on the result of the fields they are hoisted through.
+		resolverType = f.Via[len(f.Via)-1].ResultType
+	}
+	b := &ResolverBinding{
+		Type:       obj.Name,
+		Field:      f.Name,
+		Resolver:   resolverType.String(),
+		HasContext: f.HasContext,
+		HasArgs:    f.ArgsPacker != nil,
+		HasError:   f.HasError,
+		Async:      f.HasContext || f.ArgsPacker != nil || f.HasError || f.Wrapper != nil || len(f.Via) != 0,
+		Serial:     f.Serial,
+	}
+	if f.UseMethodResolver() {
+		m := resolverType.Method(f.MethodIndex)
+		b.Method = m.Name
+		b.Result = m.Type.Out(0).String()
+	} else {
+		t := resolverType
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		sf := t.FieldByIndex(f.FieldIndex)
+		b.Method = sf.Name
+		b.StructField = true
+		b.Result = sf.Type.String()
+	}
+	return b
+}
@@ -0,0 +1,30 @@
+package graphql
+
+import "context"
+
+// ResponseTransformer rewrites the final response of an operation before it is returned to the
+// transport, e.g. to strip nulls from the data, add debug information to the extensions or convert
+// the casing of keys. It receives the response after the errors were presented, see
+// AllowErrorExtensions and LocalizeErrors, and returns the response to send. The data must not be
+// modified in place, as it may be shared by deduplicated requests; a transformer replaces it
+// instead.
+type ResponseTransformer func(ctx context.Context, resp *Response) *Response
+
+// TransformResponses applies the transformers in the given order to every response returned by
+// Exec and to every response of a subscription returned by Subscribe, so they apply the same way
+// to all transports, e.g. relay.Handler and the WebSocket handlers.
+func TransformResponses(transformers ...ResponseTransformer) SchemaOpt {
+	return func(s *Schema) {
+		s.responseTransformers = append(s.responseTransformers, transformers...)
+	}
+}
+
+// presentResponse prepares a response for the client with presentErrors and the
+// ResponseTransformers.
+func (s *Schema) presentResponse(ctx context.Context, resp *Response) *Response {
+	resp.Errors = s.presentErrors(ctx, resp.Errors)
+	for _, transform := range s.responseTransformers {
+		resp = transform(ctx, resp)
+	}
+	return resp
+}
@@ -0,0 +1,110 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/graph-gophers/graphql-go/errors"
+)
+
+// Result is the result of ExecTyped. Unlike Response, which is meant to be encoded to JSON, it
+// gives structured access to the data and errors, e.g. for callers executing the schema in
+// process.
+type Result struct {
+	// Errors are the errors of the response. Their paths can be inspected with TypedPath.
+	Errors []*errors.QueryError
+
+	Extensions map[string]interface{}
+
+	data json.RawMessage
+}
+
+// ExecTyped executes the given query like Exec and returns its Result.
+func (s *Schema) ExecTyped(ctx context.Context, queryString string, operationName string, variables map[string]interface{}) *Result {
+	return NewResult(s.Exec(ctx, queryString, operationName, variables))
+}
+
+// ExecToValue executes the given query like Exec and returns its data as a map, e.g. for tests and
+// callers embedding the schema. Lists are returned as []interface{}, numbers as json.Number and
+// the other values as decoded by encoding/json. The data is nil if the query could not be
+// executed.
+func (s *Schema) ExecToValue(ctx context.Context, queryString string, operationName string, variables map[string]interface{}) (map[string]interface{}, []*errors.QueryError) {
+	resp := s.Exec(ctx, queryString, operationName, variables)
+	if len(resp.Data) == 0 {
+		return nil, resp.Errors
+	}
+	var data map[string]interface{}
+	d := json.NewDecoder(bytes.NewReader(resp.Data))
+	d.UseNumber()
+	if err := d.Decode(&data); err != nil {
+		qErr := errors.Errorf("could not decode data: %s", err)
+		qErr.ResolverError = err
+		return nil, append(resp.Errors, qErr)
+	}
+	return data, resp.Errors
+}
+
+// NewResult returns the Result of a Response.
+func NewResult(resp *Response) *Result {
+	return &Result{
+		Errors:     resp.Errors,
+		Extensions: resp.Extensions,
+		data:       resp.Data,
+	}
+}
+
+// Data unmarshals the data of the result into v. It leaves v unchanged if the result has no data,
+// e.g. because the query was invalid.
+func (r *Result) Data(v interface{}) error {
+	return r.Response().UnmarshalData(v)
+}
+
+// RawData returns the data of the result as JSON, which is empty if the result has no data.
+func (r *Result) RawData() json.RawMessage {
+	return r.data
+}
+
+// Err returns the errors of the result as an errors.List, or nil if there are none.
+func (r *Result) Err() error {
+	if len(r.Errors) == 0 {
+		return nil
+	}
+	return errors.List(r.Errors)
+}
+
+// ErrorsAt returns the errors whose path starts with the given response keys and list indices,
+// e.g. ErrorsAt("hero", "friends") for the errors of all friends of the hero.
+func (r *Result) ErrorsAt(path ...interface{}) []*errors.QueryError {
+	var errs []*errors.QueryError
+	for _, err := range r.Errors {
+		segments := err.TypedPath()
+		if len(segments) < len(path) {
+			continue
+		}
+		matches := true
+		for i, p := range path {
+			switch p := p.(type) {
+			case string:
+				matches = matches && segments[i].Field == p
+			case int:
+				matches = matches && segments[i].IsIndex() && segments[i].Index == p
+			default:
+				matches = false
+			}
+		}
+		if matches {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// Response returns the result as a Response, which can be encoded to JSON.
+func (r *Result) Response() *Response {
+	return &Response{
+		Errors:     r.Errors,
+		Data:       r.data,
+		Extensions: r.Extensions,
+	}
+}
@@ -0,0 +1,97 @@
+package graphql
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/graph-gophers/graphql-go/internal/schema"
+)
+
+// RetryPolicy retries the resolver of a field that returned an error, see RetryField.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of calls of the resolver, including the first one.
+	MaxAttempts int
+
+	// Backoff returns the delay before the given retry, starting at 1. A nil Backoff retries
+	// immediately.
+	Backoff func(retry int) time.Duration
+
+	// RetryOn reports whether the error returned by the resolver is retried. A nil RetryOn retries
+	// all errors.
+	RetryOn func(err error) bool
+}
+
+// RetryField retries the resolver of the field with the given schema coordinate, e.g.
+// "Query.user", according to the policy, so that flaky downstream calls are retried without
+// wrapping the resolver. Retries happen within the deadline of the field and stop when the context
+// of the request is done. Panics are not retried.
+//
+// Retry policies may also be declared in the schema with a @retry directive, whose backoff doubles
+// with every retry:
+//
+//	directive @retry(attempts: Int!, backoff: String) on FIELD_DEFINITION
+//
+//	type Query {
+//		user(id: ID!): User @retry(attempts: 3, backoff: "100ms")
+//	}
+//
+// A policy set with RetryField takes precedence over the directive. Fields of the mutation root
+// type are rejected, since retrying them would repeat their side effects.
+func RetryField(coordinate string, policy RetryPolicy) SchemaOpt {
+	return func(s *Schema) {
+		if s.retryPolicies == nil {
+			s.retryPolicies = make(map[string]RetryPolicy)
+		}
+		s.retryPolicies[coordinate] = policy
+	}
+}
+
+// applyRetryPolicies checks the coordinates of the retry policies and passes the policies to the
+// resolvers. Fields of the mutation root type are not retried, since their side effects would be
+// repeated.
+func (s *Schema) applyRetryPolicies() error {
+	if mutation, ok := s.schema.EntryPoints["mutation"].(*schema.Object); ok {
+		for _, f := range mutation.Fields {
+			if f.Directives.Get("retry") != nil {
+				return fmt.Errorf("directive @retry on field %q: fields of the mutation root type are not retried", f.Name)
+			}
+		}
+	}
+
+	coordinates := make([]string, 0, len(s.retryPolicies))
+	for coordinate := range s.retryPolicies {
+		coordinates = append(coordinates, coordinate)
+	}
+	sort.Strings(coordinates)
+
+	for _, coordinate := range coordinates {
+		c, err := ParseSchemaCoordinate(coordinate)
+		if err != nil {
+			return err
+		}
+		m, err := s.ResolveCoordinate(c)
+		if err != nil {
+			return fmt.Errorf("retry policy of %q: %s", coordinate, err)
+		}
+		if m.Field == nil || c.Argument != "" {
+			return fmt.Errorf("retry policy of %q: not a field", coordinate)
+		}
+		if mutation := s.schema.EntryPoints["mutation"]; mutation != nil && mutation.TypeName() == c.Type {
+			return fmt.Errorf("retry policy of %q: fields of the mutation root type are not retried", coordinate)
+		}
+		p := s.retryPolicies[coordinate]
+		if p.MaxAttempts <= 0 {
+			return fmt.Errorf("retry policy of %q: MaxAttempts must be positive", coordinate)
+		}
+		if s.schema.RetryPolicies == nil {
+			s.schema.RetryPolicies = make(map[string]*schema.RetryPolicy)
+		}
+		s.schema.RetryPolicies[coordinate] = &schema.RetryPolicy{
+			MaxAttempts: p.MaxAttempts,
+			Backoff:     p.Backoff,
+			RetryOn:     p.RetryOn,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,271 @@
+package graphql
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/graph-gophers/graphql-go/internal/common"
+	"github.com/graph-gophers/graphql-go/internal/schema"
+)
+
+// UseCommentDescriptions uses both string descriptions and, for definitions without a string
+// description, the # comments preceding them as descriptions, e.g. while migrating a schema
+// documented with comments. It implies UseStringDescriptions.
+func UseCommentDescriptions() SchemaOpt {
+	return func(s *Schema) {
+		s.useStringDescriptions = true
+		s.schema.CommentDescriptions = true
+	}
+}
+
+// SDLOptions configures the schema definition written by Schema.SDLWithOptions.
+type SDLOptions struct {
+	// CommentDescriptions writes descriptions as # comments, which are parsed as descriptions
+	// without UseStringDescriptions, instead of block strings.
+	CommentDescriptions bool
+}
+
+// SDL returns the schema definition of the schema, with the descriptions written as block strings
+// so that they are preserved when it is parsed with UseStringDescriptions. Schema transforms and
+// type extensions are applied, and the built-in scalars and directives are omitted.
+func (s *Schema) SDL() string {
+	return s.SDLWithOptions(SDLOptions{})
+}
+
+// SDLWithOptions returns the schema definition of the schema like SDL, configured by opts.
+func (s *Schema) SDLWithOptions(opts SDLOptions) string {
+	p := &sdlPrinter{opts: opts}
+	p.schemaDefinition(s.schema)
+
+	directives := make([]string, 0, len(s.schema.Directives))
+	for name := range s.schema.Directives {
+		if !schema.IsBuiltinDirective(name) {
+			directives = append(directives, name)
+		}
+	}
+	sort.Strings(directives)
+	for _, name := range directives {
+		p.directiveDefinition(s.schema.Directives[name])
+	}
+
+	for _, name := range typeNames(s.schema) {
+		if !schema.IsBuiltinType(name) {
+			p.typeDefinition(s.schema.Types[name])
+		}
+	}
+	return strings.TrimSuffix(p.b.String(), "\n")
+}
+
+type sdlPrinter struct {
+	opts SDLOptions
+	b    strings.Builder
+}
+
+// schemaDefinition writes the schema definition if the root operation types are not named after
+// the operations or directives are applied to it.
+func (p *sdlPrinter) schemaDefinition(s *schema.Schema) {
+	operations := []string{"query", "mutation", "subscription"}
+	names := map[string]string{"query": "Query", "mutation": "Mutation", "subscription": "Subscription"}
+	conventional := true
+	for _, op := range operations {
+		if t, ok := s.EntryPoints[op]; ok && t.TypeName() != names[op] {
+			conventional = false
+		}
+	}
+	if conventional && len(s.SchemaDirectives) == 0 {
+		return
+	}
+	p.b.WriteString("schema")
+	p.directives(s.SchemaDirectives)
+	p.b.WriteString(" {\n")
+	for _, op := range operations {
+		if t, ok := s.EntryPoints[op]; ok {
+			p.b.WriteString("\t" + op + ": " + t.TypeName() + "\n")
+		}
+	}
+	p.b.WriteString("}\n\n")
+}
+
+func (p *sdlPrinter) directiveDefinition(d *schema.DirectiveDecl) {
+	p.description("", d.Desc)
+	p.b.WriteString("directive @" + d.Name)
+	p.arguments("", d.Args)
+	if d.Repeatable {
+		p.b.WriteString(" repeatable")
+	}
+	p.b.WriteString(" on " + strings.Join(d.Locs, " | ") + "\n\n")
+}
+
+func (p *sdlPrinter) typeDefinition(t schema.NamedType) {
+	p.description("", t.Description())
+	switch t := t.(type) {
+	case *schema.Scalar:
+		p.b.WriteString("scalar " + t.Name)
+		p.directives(t.Directives)
+		p.b.WriteString("\n")
+
+	case *schema.Object:
+		p.b.WriteString("type " + t.Name)
+		p.interfaces(t.Interfaces)
+		p.directives(t.Directives)
+		p.fields(t.Fields)
+
+	case *schema.Interface:
+		p.b.WriteString("interface " + t.Name)
+		p.interfaces(t.Interfaces)
+		p.directives(t.Directives)
+		p.fields(t.Fields)
+
+	case *schema.Union:
+		p.b.WriteString("union " + t.Name)
+		p.directives(t.Directives)
+		names := make([]string, len(t.PossibleTypes))
+		for i, pt := range t.PossibleTypes {
+			names[i] = pt.Name
+		}
+		p.b.WriteString(" = " + strings.Join(names, " | ") + "\n")
+
+	case *schema.Enum:
+		p.b.WriteString("enum " + t.Name)
+		p.directives(t.Directives)
+		p.b.WriteString(" {\n")
+		for _, v := range t.Values {
+			p.description("\t", v.Desc)
+			p.b.WriteString("\t" + v.Name)
+			p.directives(v.Directives)
+			p.b.WriteString("\n")
+		}
+		p.b.WriteString("}\n")
+
+	case *schema.InputObject:
+		p.b.WriteString("input " + t.Name)
+		p.directives(t.Directives)
+		p.b.WriteString(" {\n")
+		for _, v := range t.Values {
+			p.description("\t", v.Desc)
+			p.b.WriteString("\t")
+			p.inputValue(v)
+			p.b.WriteString("\n")
+		}
+		p.b.WriteString("}\n")
+	}
+	p.b.WriteString("\n")
+}
+
+func (p *sdlPrinter) interfaces(interfaces []*schema.Interface) {
+	if len(interfaces) == 0 {
+		return
+	}
+	names := make([]string, len(interfaces))
+	for i, intf := range interfaces {
+		names[i] = intf.Name
+	}
+	p.b.WriteString(" implements " + strings.Join(names, " & "))
+}
+
+func (p *sdlPrinter) fields(fields schema.FieldList) {
+	p.b.WriteString(" {\n")
+	for _, f := range fields {
+		p.description("\t", f.Desc)
+		p.b.WriteString("\t" + f.Name)
+		p.arguments("\t", f.Args)
+		p.b.WriteString(": " + f.Type.String())
+		p.directives(f.Directives)
+		p.b.WriteString("\n")
+	}
+	p.b.WriteString("}\n")
+}
+
+// arguments writes the arguments on one line, or one per line if any of them has a description.
+func (p *sdlPrinter) arguments(indent string, args common.InputValueList) {
+	if len(args) == 0 {
+		return
+	}
+	multiline := false
+	for _, arg := range args {
+		if arg.Desc != "" {
+			multiline = true
+		}
+	}
+	if !multiline {
+		p.b.WriteString("(")
+		for i, arg := range args {
+			if i > 0 {
+				p.b.WriteString(", ")
+			}
+			p.inputValue(arg)
+		}
+		p.b.WriteString(")")
+		return
+	}
+	p.b.WriteString("(\n")
+	for _, arg := range args {
+		p.description(indent+"\t", arg.Desc)
+		p.b.WriteString(indent + "\t")
+		p.inputValue(arg)
+		p.b.WriteString("\n")
+	}
+	p.b.WriteString(indent + ")")
+}
+
+func (p *sdlPrinter) inputValue(v *common.InputValue) {
+	p.b.WriteString(v.Name.Name + ": " + v.Type.String())
+	if v.Default != nil {
+		p.b.WriteString(" = " + v.Default.String())
+	}
+	p.directives(v.Directives)
+}
+
+func (p *sdlPrinter) directives(directives common.DirectiveList) {
+	for _, d := range directives {
+		p.b.WriteString(" @" + d.Name.Name)
+		var args []string
+		for _, arg := range d.Args {
+			// Omitted arguments without a default value have no value.
+			if arg.Value != nil {
+				args = append(args, arg.Name.Name+": "+arg.Value.String())
+			}
+		}
+		if len(args) != 0 {
+			p.b.WriteString("(" + strings.Join(args, ", ") + ")")
+		}
+	}
+}
+
+// description writes a description as a block string or as comments.
+func (p *sdlPrinter) description(indent string, desc string) {
+	if desc == "" {
+		return
+	}
+	lines := strings.Split(desc, "\n")
+	if p.opts.CommentDescriptions {
+		for _, line := range lines {
+			if line == "" {
+				p.b.WriteString(indent + "#\n")
+				continue
+			}
+			p.b.WriteString(indent + "# " + line + "\n")
+		}
+		return
+	}
+
+	// Block strings can not contain """ and lose surrounding whitespace and a trailing quote.
+	if strings.Contains(desc, `"""`) || strings.HasSuffix(desc, `"`) || strings.TrimSpace(desc) != desc {
+		p.b.WriteString(indent + strconv.Quote(desc) + "\n")
+		return
+	}
+	if len(lines) == 1 {
+		p.b.WriteString(indent + `"""` + desc + `"""` + "\n")
+		return
+	}
+	p.b.WriteString(indent + `"""` + "\n")
+	for _, line := range lines {
+		if line == "" {
+			p.b.WriteString("\n")
+			continue
+		}
+		p.b.WriteString(indent + line + "\n")
+	}
+	p.b.WriteString(indent + `"""` + "\n")
+}
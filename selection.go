@@ -0,0 +1,141 @@
+package graphql
+
+import (
+	"context"
+
+	"github.com/graph-gophers/graphql-go/internal/exec"
+)
+
+// SelectedField is a field selected on the result of a resolver. Together with the fields selected
+// on its own result, it describes the whole subtree of the query below the resolver.
+type SelectedField struct {
+	Name string
+
+	// Alias is the response key of the field, which is its name if no alias is given.
+	Alias string
+
+	// Arguments are the arguments of the field, with variables replaced by their values and
+	// default values for omitted arguments.
+	Arguments map[string]interface{}
+
+	// TypeCondition is the object type a fragment selecting the field applies to, if the field is
+	// only resolved for results of this type. It is empty if the field is resolved for any result.
+	TypeCondition string
+
+	// SelectedFields are the fields selected on the result of this field, which are empty for
+	// scalars and enums.
+	SelectedFields []*SelectedField
+
+	// Skipped reports whether the field is skipped by a @skip or @include directive. Skipped
+	// fields are only returned by RequestedFieldsFromContext.
+	Skipped bool
+}
+
+// SelectedFieldsFromContext returns the fields selected on the result of the resolver called with
+// ctx, so the resolver can look ahead and e.g. only fetch the requested columns or join the data
+// of the whole subtree. Fields of fragments are included, also those on object types the result
+// may not have, which are marked with their TypeCondition. A field is listed once per alias and
+// type condition. It returns nil if ctx was not passed to a resolver.
+func SelectedFieldsFromContext(ctx context.Context) []*SelectedField {
+	info := exec.FieldInfoFromContext(ctx)
+	if info == nil {
+		return nil
+	}
+	return convertSelectedFields(info.SelectedFields())
+}
+
+// RequestedFieldsFromContext is like SelectedFieldsFromContext, but also returns the fields that
+// are skipped by a @skip or @include directive, either directly or by their fragment, so e.g. cache
+// keys can be built from the whole requested shape. Skipped fields are marked as Skipped and their
+// arguments are those given in the query.
+func RequestedFieldsFromContext(ctx context.Context) []*SelectedField {
+	info := exec.FieldInfoFromContext(ctx)
+	if info == nil {
+		return nil
+	}
+	return convertSelectedFields(info.RequestedFields())
+}
+
+// RequiredFieldsFromContext returns the values of the sibling fields the field resolved with ctx
+// depends on, by field name. Dependencies are declared with a directive on the field definition,
+// which has to be declared in the schema:
+//
+//	directive @dependsOn(fields: [String!]!) on FIELD_DEFINITION
+//
+//	type User {
+//		firstName: String!
+//		lastName: String!
+//		fullName: String! @dependsOn(fields: ["firstName", "lastName"])
+//	}
+//
+// The resolvers of the dependencies are called before the resolver of the field, also if they are
+// not selected, and their results are passed as returned, e.g. as string. It returns nil if the
+// field has no dependencies or ctx was not passed to a resolver.
+func RequiredFieldsFromContext(ctx context.Context) map[string]interface{} {
+	info := exec.FieldInfoFromContext(ctx)
+	if info == nil {
+		return nil
+	}
+	return info.RequiredFields()
+}
+
+func convertSelectedFields(fields []*exec.SelectedField) []*SelectedField {
+	result := make([]*SelectedField, len(fields))
+	for i, f := range fields {
+		result[i] = &SelectedField{
+			Name:          f.Name,
+			Alias:         f.Alias,
+			Arguments:     f.Arguments,
+			TypeCondition: f.TypeCondition,
+			Skipped:       f.Skipped,
+		}
+		if len(f.SelectedFields) != 0 {
+			result[i].SelectedFields = convertSelectedFields(f.SelectedFields)
+		}
+	}
+	return result
+}
+
+// TypeNameFromContext returns the name of the object type whose field is resolved with ctx, i.e.
+// the __typename of the parent object. Resolvers of interface fields get the object type their
+// resolver converts to. It returns "" if ctx was not passed to a resolver.
+func TypeNameFromContext(ctx context.Context) string {
+	info := exec.FieldInfoFromContext(ctx)
+	if info == nil {
+		return ""
+	}
+	return info.TypeName()
+}
+
+// Directive is a directive applied to the field being resolved, see DirectivesFromContext.
+type Directive struct {
+	Name string
+
+	// Arguments are the arguments of the directive, with variables replaced by their values and
+	// default values for omitted arguments.
+	Arguments map[string]interface{}
+
+	// Definition reports whether the directive is applied to the definition of the field in the
+	// schema. Otherwise it is applied to the field in the query.
+	Definition bool
+}
+
+// DirectivesFromContext returns the directives applied to the field resolved with ctx, first those
+// of the field in the query and then those of its definition in the schema. Resolvers can use them
+// to implement custom directives like @lowercase or @currency(format: String), which have to be
+// declared in the schema. It returns nil if ctx was not passed to a resolver.
+func DirectivesFromContext(ctx context.Context) []*Directive {
+	info := exec.FieldInfoFromContext(ctx)
+	if info == nil {
+		return nil
+	}
+	var directives []*Directive
+	for _, d := range info.Directives() {
+		directives = append(directives, &Directive{
+			Name:       d.Name,
+			Arguments:  d.Arguments,
+			Definition: d.Definition,
+		})
+	}
+	return directives
+}
@@ -0,0 +1,153 @@
+package sentry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Event is an event in the format of the Sentry store endpoint.
+type Event struct {
+	EventID     string                 `json:"event_id"`
+	Timestamp   string                 `json:"timestamp"`
+	Level       string                 `json:"level"`
+	Platform    string                 `json:"platform"`
+	Logger      string                 `json:"logger"`
+	Transaction string                 `json:"transaction,omitempty"`
+	Environment string                 `json:"environment,omitempty"`
+	Release     string                 `json:"release,omitempty"`
+	ServerName  string                 `json:"server_name,omitempty"`
+	Exception   []*Exception           `json:"exception,omitempty"`
+	Tags        map[string]string      `json:"tags,omitempty"`
+	Extra       map[string]interface{} `json:"extra,omitempty"`
+}
+
+// Exception is the error or panic of an event.
+type Exception struct {
+	Type       string      `json:"type"`
+	Value      string      `json:"value"`
+	Stacktrace *Stacktrace `json:"stacktrace,omitempty"`
+}
+
+// Stacktrace lists the frames of a stack trace, the innermost frame last.
+type Stacktrace struct {
+	Frames []*Frame `json:"frames"`
+}
+
+// Frame is a function call of a stack trace.
+type Frame struct {
+	Function string `json:"function"`
+	Module   string `json:"module,omitempty"`
+	Filename string `json:"filename"`
+	AbsPath  string `json:"abs_path"`
+	Lineno   int    `json:"lineno"`
+}
+
+// HTTPTransport sends events to the store endpoint of a Sentry project.
+type HTTPTransport struct {
+	endpoint   string
+	auth       string
+	httpClient *http.Client
+}
+
+// NewHTTPTransport returns a transport for the DSN of a Sentry project. The httpClient defaults to
+// http.DefaultClient.
+func NewHTTPTransport(dsn string, httpClient *http.Client) (*HTTPTransport, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("sentry: invalid DSN: %s", err)
+	}
+	project := path.Base(u.Path)
+	if u.User == nil || u.User.Username() == "" || u.Host == "" || project == "." || project == "/" {
+		return nil, fmt.Errorf("sentry: invalid DSN %q: want scheme://key@host/project", dsn)
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	auth := "Sentry sentry_version=7, sentry_client=graphql-go-sentry/1.0, sentry_key=" + u.User.Username()
+	if secret, ok := u.User.Password(); ok {
+		auth += ", sentry_secret=" + secret
+	}
+	endpoint := url.URL{
+		Scheme: u.Scheme,
+		Host:   u.Host,
+		Path:   path.Join(path.Dir(u.Path), "api", project, "store") + "/",
+	}
+	return &HTTPTransport{endpoint: endpoint.String(), auth: auth, httpClient: httpClient}, nil
+}
+
+// Send posts the event to the store endpoint.
+func (t *HTTPTransport) Send(ctx context.Context, event *Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, t.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", t.auth)
+	resp, err := t.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("sentry: %s responded with status %s: %s", t.endpoint, resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// parseStack parses a stack trace in the format of runtime.Stack. Frames of the recovery of the
+// panic are dropped, so the innermost frame is the one that panicked.
+func parseStack(stack []byte) *Stacktrace {
+	lines := strings.Split(strings.TrimSpace(string(stack)), "\n")
+	if len(lines) != 0 && strings.HasPrefix(lines[0], "goroutine ") {
+		lines = lines[1:]
+	}
+	var frames []*Frame
+	for i := 0; i+1 < len(lines); i += 2 {
+		function := strings.TrimPrefix(lines[i], "created by ")
+		if p := strings.LastIndex(function, "("); p > 0 && !strings.HasPrefix(lines[i], "created by ") {
+			function = function[:p]
+		}
+		location := strings.TrimSpace(lines[i+1])
+		if p := strings.LastIndex(location, " +0x"); p >= 0 {
+			location = location[:p]
+		}
+		file, line := location, 0
+		if p := strings.LastIndex(location, ":"); p >= 0 {
+			file = location[:p]
+			line, _ = strconv.Atoi(location[p+1:])
+		}
+		if function == "panic" || function == "runtime.gopanic" {
+			// Everything above is the recovery of the panic.
+			frames = frames[:0]
+			continue
+		}
+		f := &Frame{Function: function, Filename: path.Base(file), AbsPath: file, Lineno: line}
+		// The package path ends at the first dot after the last slash.
+		slash := strings.LastIndex(function, "/")
+		if dot := strings.Index(function[slash+1:], "."); dot >= 0 {
+			f.Module = function[:slash+1+dot]
+			f.Function = function[slash+1+dot+1:]
+		}
+		frames = append(frames, f)
+	}
+	if len(frames) == 0 {
+		return nil
+	}
+	for i, j := 0, len(frames)-1; i < j; i, j = i+1, j-1 {
+		frames[i], frames[j] = frames[j], frames[i]
+	}
+	return &Stacktrace{Frames: frames}
+}
@@ -0,0 +1,286 @@
+// Package sentry reports the errors returned by resolvers and the panics of resolvers to Sentry,
+// with the operation name, the path of the field and the variables of the request.
+//
+// The Reporter is a trace.Tracer that reports the errors of resolvers when an operation finishes,
+// and a log.Logger that reports panics with their stack traces:
+//
+//	reporter, err := sentry.NewReporter(sentry.Config{
+//		DSN:         os.Getenv("SENTRY_DSN"),
+//		Environment: "production",
+//	})
+//	schema := graphql.MustParseSchema(schemaString, resolver,
+//		graphql.Tracer(reporter),
+//		graphql.Logger(reporter),
+//	)
+//	defer reporter.Flush(context.Background())
+//
+// Events are sent to other services, e.g. Bugsnag, by setting Config.Transport.
+package sentry
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/introspection"
+	"github.com/graph-gophers/graphql-go/log"
+	"github.com/graph-gophers/graphql-go/requestcontext"
+	"github.com/graph-gophers/graphql-go/trace"
+)
+
+// Config configures a Reporter.
+type Config struct {
+	// DSN is the client key of the Sentry project, e.g. "https://key@o1.ingest.sentry.io/42". It is
+	// required unless Transport is set.
+	DSN string
+
+	// Environment, Release and ServerName are added to every event. ServerName defaults to the
+	// host name.
+	Environment string
+	Release     string
+	ServerName  string
+
+	// HTTPClient defaults to http.DefaultClient.
+	HTTPClient *http.Client
+
+	// Transport sends the events. It defaults to sending them to the store endpoint of the DSN.
+	Transport Transport
+
+	// Scrub filters the variables of an operation before they are added to an event. It is called
+	// for every member of the variables, including the members of nested objects, and returns the
+	// value to report. It defaults to DefaultScrub.
+	Scrub func(name string, value interface{}) interface{}
+
+	// ReportError decides whether an error of an operation is reported. It defaults to reporting
+	// the errors returned by resolvers, see errors.QueryError.ResolverError. Panics are always
+	// reported.
+	ReportError func(err *errors.QueryError) bool
+
+	// Tracer is called for every query and field as well, so the Reporter can be combined with
+	// another tracer. It defaults to trace.NoopTracer.
+	Tracer trace.Tracer
+
+	// Logger logs panics as well. It defaults to log.DefaultLogger.
+	Logger log.Logger
+
+	// OnError is called with the errors of sending events. Errors are ignored by default.
+	OnError func(error)
+}
+
+// Transport sends events to an error reporting service.
+type Transport interface {
+	Send(ctx context.Context, event *Event) error
+}
+
+// Reporter reports errors and panics of operations. It implements trace.Tracer and log.Logger.
+type Reporter struct {
+	cfg     Config
+	pending sync.WaitGroup
+}
+
+var (
+	_ trace.Tracer    = (*Reporter)(nil)
+	_ log.PanicLogger = (*Reporter)(nil)
+)
+
+// NewReporter returns a Reporter for the given configuration. The DSN is parsed to validate it.
+func NewReporter(cfg Config) (*Reporter, error) {
+	if cfg.Transport == nil {
+		t, err := NewHTTPTransport(cfg.DSN, cfg.HTTPClient)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Transport = t
+	}
+	if cfg.ServerName == "" {
+		cfg.ServerName, _ = os.Hostname()
+	}
+	if cfg.Scrub == nil {
+		cfg.Scrub = DefaultScrub
+	}
+	if cfg.ReportError == nil {
+		cfg.ReportError = func(err *errors.QueryError) bool { return err.ResolverError != nil }
+	}
+	if cfg.Tracer == nil {
+		cfg.Tracer = trace.NoopTracer{}
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = &log.DefaultLogger{}
+	}
+	return &Reporter{cfg: cfg}, nil
+}
+
+var sensitive = regexp.MustCompile(`(?i)passw|secret|token|auth|credential|api_?key|card|ssn|cvv`)
+
+// DefaultScrub replaces the values of variables whose names suggest sensitive data, e.g.
+// "password" or "apiKey", by "[Filtered]".
+func DefaultScrub(name string, value interface{}) interface{} {
+	if sensitive.MatchString(name) {
+		return "[Filtered]"
+	}
+	return value
+}
+
+type operationKey struct{}
+
+// operation is the GraphQL context of the events of an operation.
+type operation struct {
+	name      string
+	query     string
+	variables map[string]interface{}
+}
+
+func (r *Reporter) TraceQuery(ctx context.Context, queryString string, operationName string, variables map[string]interface{}, varTypes map[string]*introspection.Type) (context.Context, trace.TraceQueryFinishFunc) {
+	ctx, finish := r.cfg.Tracer.TraceQuery(ctx, queryString, operationName, variables, varTypes)
+	op := &operation{
+		name:      operationName,
+		query:     queryString,
+		variables: r.scrubObject(variables),
+	}
+	ctx = context.WithValue(ctx, operationKey{}, op)
+	return ctx, func(errs []*errors.QueryError) {
+		finish(errs)
+		for _, err := range errs {
+			if r.cfg.ReportError(err) {
+				r.send(r.errorEvent(op, err))
+			}
+		}
+	}
+}
+
+func (r *Reporter) TraceField(ctx context.Context, label, typeName, fieldName string, trivial bool, args map[string]interface{}) (context.Context, trace.TraceFieldFinishFunc) {
+	return r.cfg.Tracer.TraceField(ctx, label, typeName, fieldName, trivial, args)
+}
+
+// LogPanic reports a panic without a stack trace.
+func (r *Reporter) LogPanic(ctx context.Context, value interface{}) {
+	r.LogPanicInfo(ctx, &log.Panic{Value: value, OperationName: requestcontext.OperationName(ctx)})
+}
+
+// LogPanicInfo reports a panic with its stack trace.
+func (r *Reporter) LogPanicInfo(ctx context.Context, p *log.Panic) {
+	if l, ok := r.cfg.Logger.(log.PanicLogger); ok {
+		l.LogPanicInfo(ctx, p)
+	} else {
+		r.cfg.Logger.LogPanic(ctx, p.Value)
+	}
+
+	op, _ := ctx.Value(operationKey{}).(*operation)
+	if op == nil {
+		op = &operation{name: p.OperationName}
+	}
+	e := r.newEvent(op, "fatal", p.Path)
+	e.Exception = []*Exception{{
+		Type:       "panic",
+		Value:      fmt.Sprint(p.Value),
+		Stacktrace: parseStack(p.Stack),
+	}}
+	r.send(e)
+}
+
+func (r *Reporter) errorEvent(op *operation, err *errors.QueryError) *Event {
+	e := r.newEvent(op, "error", err.Path)
+	typ := "error"
+	if err.ResolverError != nil {
+		typ = fmt.Sprintf("%T", err.ResolverError)
+	}
+	e.Exception = []*Exception{{Type: typ, Value: err.Message}}
+	if code := err.Code(); code != "" {
+		e.Tags["graphql.code"] = code
+	}
+	return e
+}
+
+func (r *Reporter) newEvent(op *operation, level string, path []interface{}) *Event {
+	e := &Event{
+		EventID:     newEventID(),
+		Timestamp:   time.Now().UTC().Format(time.RFC3339Nano),
+		Level:       level,
+		Platform:    "go",
+		Logger:      "graphql",
+		Environment: r.cfg.Environment,
+		Release:     r.cfg.Release,
+		ServerName:  r.cfg.ServerName,
+		Tags:        make(map[string]string),
+		Extra:       make(map[string]interface{}),
+	}
+	if op.name != "" {
+		e.Tags["graphql.operation"] = op.name
+		e.Transaction = op.name
+	}
+	if path != nil {
+		e.Extra["graphql.path"] = path
+	}
+	if op.query != "" {
+		e.Extra["graphql.query"] = op.query
+	}
+	if len(op.variables) != 0 {
+		e.Extra["graphql.variables"] = op.variables
+	}
+	return e
+}
+
+func (r *Reporter) scrubObject(obj map[string]interface{}) map[string]interface{} {
+	if obj == nil {
+		return nil
+	}
+	scrubbed := make(map[string]interface{}, len(obj))
+	for name, value := range obj {
+		scrubbed[name] = r.scrubValue(r.cfg.Scrub(name, value))
+	}
+	return scrubbed
+}
+
+func (r *Reporter) scrubValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		return r.scrubObject(value)
+	case []interface{}:
+		list := make([]interface{}, len(value))
+		for i, v := range value {
+			list[i] = r.scrubValue(v)
+		}
+		return list
+	default:
+		return value
+	}
+}
+
+// send sends the event in the background, so that reporting does not delay the response.
+func (r *Reporter) send(e *Event) {
+	r.pending.Add(1)
+	go func() {
+		defer r.pending.Done()
+		if err := r.cfg.Transport.Send(context.Background(), e); err != nil && r.cfg.OnError != nil {
+			r.cfg.OnError(err)
+		}
+	}()
+}
+
+// Flush waits until all events are sent or ctx is done.
+func (r *Reporter) Flush(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		r.pending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func newEventID() string {
+	var id [16]byte
+	rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
@@ -0,0 +1,132 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	qerrors "github.com/graph-gophers/graphql-go/errors"
+)
+
+// ErrCodeShuttingDown is the "code" extension of the error returned by Exec after Shutdown was
+// called.
+const ErrCodeShuttingDown = "SHUTTING_DOWN"
+
+// ErrShuttingDown is returned by Subscribe after Shutdown was called.
+var ErrShuttingDown = errors.New("graphql: schema is shutting down")
+
+// shutdownState tracks the operations in flight for Shutdown.
+type shutdownState struct {
+	mu            sync.Mutex
+	closing       bool
+	next          int
+	operations    map[int]context.CancelFunc
+	subscriptions map[int]context.CancelFunc
+	inflight      sync.WaitGroup
+}
+
+// Shutdown gracefully stops the schema: Exec and Subscribe reject new operations, open
+// subscriptions are completed by closing their response channels, so that transports send their
+// complete messages, and Shutdown waits for the other operations in flight to finish. If ctx is
+// done first, the remaining operations are cancelled like by the cancellation of their contexts
+// and Shutdown returns the error of ctx after they returned.
+func (s *Schema) Shutdown(ctx context.Context) error {
+	sd := &s.shutdown
+	sd.mu.Lock()
+	sd.closing = true
+	for _, cancel := range sd.subscriptions {
+		cancel()
+	}
+	sd.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		sd.inflight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		sd.mu.Lock()
+		for _, cancel := range sd.operations {
+			cancel()
+		}
+		sd.mu.Unlock()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// beginOperation registers an operation with Shutdown. The returned context is cancelled by
+// Shutdown, immediately for subscriptions and after the grace period for other operations. The
+// returned function must be called when the operation finished. It reports false if the schema is
+// shutting down.
+func (s *Schema) beginOperation(ctx context.Context, subscription bool) (context.Context, func(), bool) {
+	sd := &s.shutdown
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+	if sd.closing {
+		return ctx, nil, false
+	}
+	if sd.operations == nil {
+		sd.operations = make(map[int]context.CancelFunc)
+		sd.subscriptions = make(map[int]context.CancelFunc)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	id := sd.next
+	sd.next++
+	active := sd.operations
+	if subscription {
+		active = sd.subscriptions
+	}
+	active[id] = cancel
+	if !subscription {
+		sd.inflight.Add(1)
+	}
+	return ctx, func() {
+		sd.mu.Lock()
+		delete(active, id)
+		sd.mu.Unlock()
+		cancel()
+		if !subscription {
+			sd.inflight.Done()
+		}
+	}, true
+}
+
+func shuttingDownResponse() *Response {
+	return &Response{Errors: []*qerrors.QueryError{{
+		Message:    "schema is shutting down",
+		Extensions: map[string]interface{}{"code": ErrCodeShuttingDown},
+	}}}
+}
+
+// finishSubscription calls done after the responses channel is closed, or after ctx is done if the
+// responses are no longer received.
+func finishSubscription(ctx context.Context, responses <-chan interface{}, done func()) <-chan interface{} {
+	c := make(chan interface{})
+	go func() {
+		defer done()
+		defer close(c)
+		forwardResponses(ctx, responses, c, func(resp interface{}) interface{} { return resp })
+	}()
+	return c
+}
+
+// forwardResponses sends the responses to c after applying f, until the responses channel is closed
+// or ctx is done while c is not received from. The remaining responses are then discarded, so that
+// their sender is not blocked.
+func forwardResponses(ctx context.Context, responses <-chan interface{}, c chan<- interface{}, f func(interface{}) interface{}) {
+	for resp := range responses {
+		select {
+		case c <- f(resp):
+		case <-ctx.Done():
+			go func() {
+				for range responses {
+				}
+			}()
+			return
+		}
+	}
+}
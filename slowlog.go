@@ -0,0 +1,175 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	stdlog "log"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/introspection"
+	"github.com/graph-gophers/graphql-go/trace"
+)
+
+// SlowOperationLog configures LogSlowOperations.
+type SlowOperationLog struct {
+	// Threshold is the duration of the execution above which an operation is logged.
+	Threshold time.Duration
+
+	// SampleRate is the fraction of operations that are measured, between 0 and 1. Zero measures
+	// all operations.
+	SampleRate float64
+
+	// MaxFields is the number of slowest fields included in a log entry. It defaults to 5.
+	MaxFields int
+
+	// Log is called with every slow operation. It defaults to logging the operation as a JSON
+	// object with the standard logger.
+	Log func(ctx context.Context, op *SlowOperation)
+}
+
+// SlowOperation describes an operation whose execution exceeded the threshold of
+// LogSlowOperations.
+type SlowOperation struct {
+	OperationName string
+	Query         string
+	Duration      time.Duration
+	Errors        int
+
+	// Fields are the slowest fields of the operation, the slowest first.
+	Fields []SlowField
+}
+
+// SlowField is the timing of a field of a slow operation.
+type SlowField struct {
+	// Coordinate is the schema coordinate of the field, e.g. "User.friends".
+	Coordinate string
+
+	// Count is the number of times the field was resolved, Max the duration of the slowest and
+	// Total the sum of the durations of all of them.
+	Count int
+	Max   time.Duration
+	Total time.Duration
+}
+
+// LogSlowOperations logs the operations whose execution takes longer than the threshold, with the
+// durations of their slowest fields, so slow operations can be found without tracing every
+// request. The fields are measured with a tracer that is combined with the one set by Tracer.
+func LogSlowOperations(cfg SlowOperationLog) SchemaOpt {
+	return func(s *Schema) {
+		if cfg.MaxFields == 0 {
+			cfg.MaxFields = 5
+		}
+		if cfg.Log == nil {
+			cfg.Log = logSlowOperation
+		}
+		s.slowOperationLog = &cfg
+	}
+}
+
+func logSlowOperation(ctx context.Context, op *SlowOperation) {
+	fields := make([]map[string]interface{}, len(op.Fields))
+	for i, f := range op.Fields {
+		fields[i] = map[string]interface{}{
+			"field":   f.Coordinate,
+			"count":   f.Count,
+			"maxMs":   durationMillis(f.Max),
+			"totalMs": durationMillis(f.Total),
+		}
+	}
+	entry, _ := json.Marshal(map[string]interface{}{
+		"operationName": op.OperationName,
+		"query":         op.Query,
+		"durationMs":    durationMillis(op.Duration),
+		"errors":        op.Errors,
+		"slowestFields": fields,
+	})
+	stdlog.Printf("graphql: slow operation: %s", entry)
+}
+
+func durationMillis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// slowOperationTracer measures the fields of sampled operations.
+type slowOperationTracer struct {
+	cfg *SlowOperationLog
+}
+
+type slowOperationKey struct{}
+
+// slowOperation collects the durations of the fields of an operation.
+type slowOperation struct {
+	mu     sync.Mutex
+	fields map[string]*SlowField
+}
+
+func (t slowOperationTracer) TraceQuery(ctx context.Context, queryString string, operationName string, variables map[string]interface{}, varTypes map[string]*introspection.Type) (context.Context, trace.TraceQueryFinishFunc) {
+	if t.cfg.SampleRate != 0 && rand.Float64() >= t.cfg.SampleRate {
+		return ctx, func([]*errors.QueryError) {}
+	}
+	op := &slowOperation{fields: make(map[string]*SlowField)}
+	ctx = context.WithValue(ctx, slowOperationKey{}, op)
+	start := time.Now()
+	return ctx, func(errs []*errors.QueryError) {
+		d := time.Since(start)
+		if d <= t.cfg.Threshold {
+			return
+		}
+		t.cfg.Log(ctx, &SlowOperation{
+			OperationName: operationName,
+			Query:         queryString,
+			Duration:      d,
+			Errors:        len(errs),
+			Fields:        op.slowest(t.cfg.MaxFields),
+		})
+	}
+}
+
+func (t slowOperationTracer) TraceField(ctx context.Context, label, typeName, fieldName string, trivial bool, args map[string]interface{}) (context.Context, trace.TraceFieldFinishFunc) {
+	op, ok := ctx.Value(slowOperationKey{}).(*slowOperation)
+	if !ok {
+		return ctx, func(*errors.QueryError) {}
+	}
+	start := time.Now()
+	return ctx, func(*errors.QueryError) {
+		op.add(typeName+"."+fieldName, time.Since(start))
+	}
+}
+
+func (op *slowOperation) add(coordinate string, d time.Duration) {
+	op.mu.Lock()
+	defer op.mu.Unlock()
+	f, ok := op.fields[coordinate]
+	if !ok {
+		f = &SlowField{Coordinate: coordinate}
+		op.fields[coordinate] = f
+	}
+	f.Count++
+	f.Total += d
+	if d > f.Max {
+		f.Max = d
+	}
+}
+
+func (op *slowOperation) slowest(n int) []SlowField {
+	op.mu.Lock()
+	defer op.mu.Unlock()
+	fields := make([]SlowField, 0, len(op.fields))
+	for _, f := range op.fields {
+		fields = append(fields, *f)
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		if fields[i].Max != fields[j].Max {
+			return fields[i].Max > fields[j].Max
+		}
+		return fields[i].Coordinate < fields[j].Coordinate
+	})
+	if len(fields) > n {
+		fields = fields[:n]
+	}
+	return fields
+}
@@ -0,0 +1,167 @@
+package graphql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/internal/common"
+	"github.com/graph-gophers/graphql-go/internal/query"
+	"github.com/graph-gophers/graphql-go/internal/schema"
+)
+
+// SourcePosition locates a field of a response in the query document and in the schema.
+type SourcePosition struct {
+	// Path is the path of the field in the response, e.g. ["orders", 3, "total"].
+	Path []interface{} `json:"path"`
+
+	// Locations are the locations of the selections of the field in the query document. A field
+	// has several locations if it is selected more than once, e.g. by fragments.
+	Locations []errors.Location `json:"locations"`
+
+	// Coordinate is the schema coordinate of the field, e.g. "Order.total".
+	Coordinate string `json:"coordinate"`
+}
+
+// SourceMapExtension adds the SourcePosition of the field of each error with a path to the
+// "sourceMap" extension of the response. It is meant for debugging, e.g. of generated queries.
+func SourceMapExtension() SchemaOpt {
+	return func(s *Schema) {
+		s.sourceMapExtension = true
+	}
+}
+
+// SourcePosition maps the path of a field in the response of an operation to the positions of its
+// selections in the query document and to its schema coordinate. List indices of the path are
+// ignored, and may be of any integer or float type, e.g. as decoded from JSON.
+func (s *Schema) SourcePosition(queryString string, operationName string, path []interface{}) (*SourcePosition, error) {
+	doc, qErr := s.parseQuery(queryString)
+	if qErr != nil {
+		return nil, qErr
+	}
+	op, err := getOperation(doc, operationName)
+	if err != nil {
+		return nil, err
+	}
+	return s.sourcePosition(doc, op, path)
+}
+
+func (s *Schema) sourcePosition(doc *query.Document, op *query.Operation, path []interface{}) (*SourcePosition, error) {
+	t := s.schema.EntryPoints[strings.ToLower(string(op.Type))]
+	if t == nil {
+		return nil, fmt.Errorf("schema does not support %s operations", strings.ToLower(string(op.Type)))
+	}
+
+	pos := &SourcePosition{Path: path}
+	sels := []selectionsOf{{op.Selections, t}}
+	for i, seg := range path {
+		key, ok := seg.(string)
+		if !ok {
+			continue
+		}
+
+		var fields []selectedField
+		for _, so := range sels {
+			fields = collectFields(s.schema, doc, so.sels, so.typ, key, fields, make(map[string]bool))
+		}
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("no field %q at path %v", key, path[:i+1])
+		}
+
+		pos.Locations = pos.Locations[:0]
+		sels = sels[:0]
+		for _, f := range fields {
+			pos.Locations = append(pos.Locations, f.field.Alias.Loc)
+			if f.def != nil {
+				sels = append(sels, selectionsOf{f.field.Selections, namedType(f.def.Type)})
+			}
+		}
+		pos.Coordinate = fields[0].parent.TypeName() + "." + fields[0].field.Name.Name
+	}
+	if pos.Coordinate == "" {
+		return nil, fmt.Errorf("path %v does not contain a field", path)
+	}
+	return pos, nil
+}
+
+type selectionsOf struct {
+	sels []query.Selection
+	typ  schema.NamedType
+}
+
+type selectedField struct {
+	field  *query.Field
+	parent schema.NamedType
+	def    *schema.Field
+}
+
+// collectFields appends the fields of the selections with the response key, including those of
+// fragments, with the type they are selected on.
+func collectFields(s *schema.Schema, doc *query.Document, sels []query.Selection, t schema.NamedType, key string, fields []selectedField, visited map[string]bool) []selectedField {
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *query.Field:
+			if sel.Alias.Name != key {
+				continue
+			}
+			f := selectedField{field: sel, parent: t}
+			switch t := t.(type) {
+			case *schema.Object:
+				f.def = t.Fields.Get(sel.Name.Name)
+			case *schema.Interface:
+				f.def = t.Fields.Get(sel.Name.Name)
+			}
+			fields = append(fields, f)
+		case *query.InlineFragment:
+			fields = collectFields(s, doc, sel.Selections, fragmentType(s, sel.On, t), key, fields, visited)
+		case *query.FragmentSpread:
+			frag := doc.Fragments.Get(sel.Name.Name)
+			if frag == nil || visited[frag.Name.Name] {
+				continue
+			}
+			visited[frag.Name.Name] = true
+			fields = collectFields(s, doc, frag.Selections, fragmentType(s, frag.On, t), key, fields, visited)
+		}
+	}
+	return fields
+}
+
+// fragmentType returns the type condition of a fragment, or t if it has none.
+func fragmentType(s *schema.Schema, on common.TypeName, t schema.NamedType) schema.NamedType {
+	if on.Name == "" {
+		return t
+	}
+	if typ, ok := s.Types[on.Name]; ok {
+		return typ
+	}
+	return t
+}
+
+func namedType(t common.Type) schema.NamedType {
+	for {
+		switch typ := t.(type) {
+		case *common.NonNull:
+			t = typ.OfType
+		case *common.List:
+			t = typ.OfType
+		case schema.NamedType:
+			return typ
+		default:
+			return nil
+		}
+	}
+}
+
+// errorSourcePositions returns the source positions of the fields of the errors with a path.
+func (s *Schema) errorSourcePositions(doc *query.Document, op *query.Operation, errs []*errors.QueryError) []*SourcePosition {
+	var positions []*SourcePosition
+	for _, err := range errs {
+		if len(err.Path) == 0 {
+			continue
+		}
+		if pos, posErr := s.sourcePosition(doc, op, err.Path); posErr == nil {
+			positions = append(positions, pos)
+		}
+	}
+	return positions
+}
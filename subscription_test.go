@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"reflect"
 	"testing"
 	"time"
 
@@ -153,7 +154,7 @@ func TestSchemaSubscribe(t *testing.T) {
 					Data: json.RawMessage(`
 						null
 					`),
-					Errors: []*qerrors.QueryError{qerrors.Errorf("%s", resolverErr)},
+					Errors: []*qerrors.QueryError{{Message: resolverErr.Error(), Locations: []qerrors.Location{{Line: 4, Column: 7}}}},
 				},
 				{
 					Data: json.RawMessage(`
@@ -211,7 +212,7 @@ func TestSchemaSubscribe(t *testing.T) {
 					Data: json.RawMessage(`
 						null
 					`),
-					Errors: []*qerrors.QueryError{qerrors.Errorf("%s", resolverErr)},
+					Errors: []*qerrors.QueryError{{Message: resolverErr.Error(), Locations: []qerrors.Location{{Line: 3, Column: 6}}}},
 				},
 			},
 		},
@@ -240,7 +241,7 @@ func TestSchemaSubscribe(t *testing.T) {
 							}
 						}
 					`),
-					Errors: []*qerrors.QueryError{qerrors.Errorf("%s", resolverErr)},
+					Errors: []*qerrors.QueryError{{Message: resolverErr.Error(), Locations: []qerrors.Location{{Line: 4, Column: 7}}}},
 				},
 			},
 		},
@@ -263,7 +264,7 @@ func TestSchemaSubscribe(t *testing.T) {
 							"helloSaidNullable": null
 						}
 					`),
-					Errors: []*qerrors.QueryError{qerrors.Errorf("%s", resolverErr)},
+					Errors: []*qerrors.QueryError{{Message: resolverErr.Error(), Locations: []qerrors.Location{{Line: 3, Column: 6}}}},
 				},
 			},
 		},
@@ -573,3 +574,53 @@ func TestSchemaSubscribe_PanicInResolver(t *testing.T) {
 		},
 	})
 }
+
+func TestTransformResponses(t *testing.T) {
+	var order []string
+	addExtension := func(name string) graphql.ResponseTransformer {
+		return func(ctx context.Context, resp *graphql.Response) *graphql.Response {
+			order = append(order, name)
+			if resp.Extensions == nil {
+				resp.Extensions = make(map[string]interface{})
+			}
+			resp.Extensions[name] = len(resp.Errors)
+			return resp
+		}
+	}
+	replaceData := func(ctx context.Context, resp *graphql.Response) *graphql.Response {
+		if string(resp.Data) == `{"hello":"Hello world!"}` {
+			resp = &graphql.Response{Data: json.RawMessage(`{"hello":"Hi!"}`), Extensions: resp.Extensions}
+		}
+		return resp
+	}
+	s := graphql.MustParseSchema(schema, &rootResolver{
+		helloResolver: &helloResolver{},
+		helloSaidResolver: &helloSaidResolver{
+			upstream: closedUpstream(
+				&helloSaidEventResolver{msg: "Hello world!"},
+				&helloSaidEventResolver{err: resolverErr},
+			),
+		},
+	}, graphql.TransformResponses(addExtension("first"), addExtension("second"), replaceData))
+
+	resp := s.Exec(context.Background(), `{ hello }`, "", nil)
+	if got, _ := json.Marshal(resp); string(got) != `{"data":{"hello":"Hi!"},"extensions":{"first":0,"second":0}}` {
+		t.Errorf("unexpected response %s", got)
+	}
+	if want := []string{"first", "second"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("transformers applied in order %v, want %v", order, want)
+	}
+
+	c, err := s.Subscribe(context.Background(), `subscription { helloSaid { msg } }`, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for resp := range c {
+		b, _ := json.Marshal(resp.(*graphql.Response).Extensions)
+		got = append(got, string(b))
+	}
+	if want := []string{`{"first":0,"second":0}`, `{"first":1,"second":1}`}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got extensions %v, want %v", got, want)
+	}
+}
@@ -11,12 +11,12 @@ import (
 	"github.com/graph-gophers/graphql-go/internal/exec/resolvable"
 	"github.com/graph-gophers/graphql-go/internal/exec/selected"
 	"github.com/graph-gophers/graphql-go/internal/query"
-	"github.com/graph-gophers/graphql-go/internal/validation"
 	"github.com/graph-gophers/graphql-go/introspection"
 )
 
-// Subscribe returns a response channel for the given subscription with the schema's
-// resolver. It returns an error if the schema was created without a resolver.
+// Subscribe returns a response channel for the given subscription, or live query (see
+// LiveQueries), with the schema's resolver. It returns an error if the schema was created without
+// a resolver.
 // If the context gets cancelled, the response channel will be closed and no
 // further resolvers will be called. The context error will be returned as soon
 // as possible (not immediately).
@@ -24,20 +24,32 @@ func (s *Schema) Subscribe(ctx context.Context, queryString string, operationNam
 	if s.res.Resolver == (reflect.Value{}) {
 		return nil, errors.New("schema created without resolver, can not subscribe")
 	}
-	if _, ok := s.schema.EntryPoints["subscription"]; !ok {
+	if _, ok := s.schema.EntryPoints["subscription"]; !ok && s.liveQueryStore == nil {
 		return nil, errors.New("no subscriptions are offered by the schema")
 	}
-	return s.subscribe(ctx, queryString, operationName, variables, s.res), nil
+	ctx, done, ok := s.beginOperation(ctx, true)
+	if !ok {
+		return nil, ErrShuttingDown
+	}
+	return finishSubscription(ctx, s.presentResponses(ctx, s.subscribe(ctx, queryString, operationName, variables, s.res)), done), nil
 }
 
 func (s *Schema) subscribe(ctx context.Context, queryString string, operationName string, variables map[string]interface{}, res *resolvable.Schema) <-chan interface{} {
-	doc, qErr := query.Parse(queryString)
+	doc, qErr := s.parseQuery(queryString)
+	if qErr != nil {
+		return sendAndReturnClosed(&Response{Errors: []*qerrors.QueryError{qErr}})
+	}
+
+	if variables == nil {
+		variables = make(map[string]interface{})
+	}
+	variables, qErr = s.transformVariables(ctx, doc, operationName, variables)
 	if qErr != nil {
 		return sendAndReturnClosed(&Response{Errors: []*qerrors.QueryError{qErr}})
 	}
 
 	validationFinish := s.validationTracer.TraceValidation()
-	errs := validation.Validate(s.schema, doc, variables, s.maxDepth)
+	errs := s.validate(doc, operationName, variables)
 	validationFinish(errs)
 	if len(errs) != 0 {
 		return sendAndReturnClosed(&Response{Errors: errs})
@@ -48,16 +60,39 @@ func (s *Schema) subscribe(ctx context.Context, queryString string, operationNam
 		return sendAndReturnClosed(&Response{Errors: []*qerrors.QueryError{qerrors.Errorf("%s", err)}})
 	}
 
+	ctx, qErr = s.startOperation(ctx, queryString, doc, op, variables)
+	if qErr != nil {
+		return sendAndReturnClosed(&Response{Errors: []*qerrors.QueryError{qErr}})
+	}
+	res, qErr = s.requestResolver(ctx)
+	if qErr != nil {
+		return sendAndReturnClosed(&Response{Errors: []*qerrors.QueryError{qErr}})
+	}
+
 	r := &exec.Request{
 		Request: selected.Request{
 			Doc:    doc,
 			Vars:   variables,
 			Schema: s.schema,
 		},
-		Limiter:                  make(chan struct{}, s.maxParallelism),
+		Limiter:                  s.requestLimiter(),
+		EntityCache:              s.requestEntityCache(op),
+		CircuitBreaker:           s.circuitBreaker,
+		ReportPanic:              s.reportPanic,
+		PanicStacks:              s.panicStackTraces,
+		MaxParallelism:           s.maxParallelism,
+		FieldCost:                s.fieldCost(),
+		Workers:                  s.requestWorkers(),
+		MaxListConcurrency:       s.maxListConcurrency,
+		MaxListLength:            s.maxListLength,
+		TraceSkippedFields:       s.traceSkippedFields,
+		FieldOrder:               exec.FieldOrder(s.fieldOrder),
 		Tracer:                   s.tracer,
 		Logger:                   s.logger,
 		SubscribeResolverTimeout: s.subscribeResolverTimeout,
+		Timeout:                  s.requestTimeout,
+		RedactionPredicates:      s.redactionPredicates,
+		MarshalGraphQL:           marshalGraphQL,
 	}
 	varTypes := make(map[string]*introspection.Type)
 	for _, v := range op.Vars {
@@ -68,6 +103,9 @@ func (s *Schema) subscribe(ctx context.Context, queryString string, operationNam
 		varTypes[v.Name.Name] = introspection.WrapType(t)
 	}
 
+	if s.isLiveQuery(op) {
+		return s.liveQuery(ctx, r, res, op)
+	}
 	if op.Type == query.Query || op.Type == query.Mutation {
 		data, errs := r.Execute(ctx, res, op)
 		return sendAndReturnClosed(&Response{Data: data, Errors: errs})
@@ -0,0 +1,69 @@
+package trace
+
+import (
+	"context"
+
+	"github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/introspection"
+)
+
+// Multi returns a tracer that calls all of the given tracers, e.g. to report spans and collect
+// metrics at the same time. The context returned by each tracer is passed to the next one and the
+// finish functions are called in reverse order. Skipped fields are reported to the tracers
+// implementing SkippedFieldTracer.
+func Multi(tracers ...Tracer) Tracer {
+	return multiTracer(tracers)
+}
+
+type multiTracer []Tracer
+
+func (m multiTracer) TraceQuery(ctx context.Context, queryString string, operationName string, variables map[string]interface{}, varTypes map[string]*introspection.Type) (context.Context, TraceQueryFinishFunc) {
+	finishes := make([]TraceQueryFinishFunc, len(m))
+	for i, t := range m {
+		ctx, finishes[i] = t.TraceQuery(ctx, queryString, operationName, variables, varTypes)
+	}
+	return ctx, func(errs []*errors.QueryError) {
+		for i := len(finishes) - 1; i >= 0; i-- {
+			finishes[i](errs)
+		}
+	}
+}
+
+func (m multiTracer) TraceField(ctx context.Context, label, typeName, fieldName string, trivial bool, args map[string]interface{}) (context.Context, TraceFieldFinishFunc) {
+	finishes := make([]TraceFieldFinishFunc, len(m))
+	for i, t := range m {
+		ctx, finishes[i] = t.TraceField(ctx, label, typeName, fieldName, trivial, args)
+	}
+	return ctx, func(err *errors.QueryError) {
+		for i := len(finishes) - 1; i >= 0; i-- {
+			finishes[i](err)
+		}
+	}
+}
+
+func (m multiTracer) TraceSkippedField(ctx context.Context, typeName, fieldName string, args map[string]interface{}) {
+	for _, t := range m {
+		if st, ok := t.(SkippedFieldTracer); ok {
+			st.TraceSkippedField(ctx, typeName, fieldName, args)
+		}
+	}
+}
+
+// MultiValidation returns a validation tracer that calls all of the given validation tracers.
+func MultiValidation(tracers ...ValidationTracer) ValidationTracer {
+	return multiValidationTracer(tracers)
+}
+
+type multiValidationTracer []ValidationTracer
+
+func (m multiValidationTracer) TraceValidation() TraceValidationFinishFunc {
+	finishes := make([]TraceValidationFinishFunc, len(m))
+	for i, t := range m {
+		finishes[i] = t.TraceValidation()
+	}
+	return func(errs []*errors.QueryError) {
+		for i := len(finishes) - 1; i >= 0; i-- {
+			finishes[i](errs)
+		}
+	}
+}
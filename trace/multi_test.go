@@ -0,0 +1,60 @@
+package trace_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/introspection"
+	"github.com/graph-gophers/graphql-go/trace"
+)
+
+type recordingTracer struct {
+	name   string
+	events *[]string
+}
+
+type tracerKey struct{}
+
+func (t recordingTracer) TraceQuery(ctx context.Context, queryString string, operationName string, variables map[string]interface{}, varTypes map[string]*introspection.Type) (context.Context, trace.TraceQueryFinishFunc) {
+	parent, _ := ctx.Value(tracerKey{}).(string)
+	*t.events = append(*t.events, "start "+t.name+" in "+parent)
+	return context.WithValue(ctx, tracerKey{}, t.name), func(errs []*errors.QueryError) {
+		*t.events = append(*t.events, "finish "+t.name)
+	}
+}
+
+func (t recordingTracer) TraceField(ctx context.Context, label, typeName, fieldName string, trivial bool, args map[string]interface{}) (context.Context, trace.TraceFieldFinishFunc) {
+	*t.events = append(*t.events, "field "+t.name+" "+label)
+	return ctx, func(err *errors.QueryError) {
+		*t.events = append(*t.events, "field finish "+t.name)
+	}
+}
+
+func TestMulti(t *testing.T) {
+	var events []string
+	tracer := trace.Multi(recordingTracer{"a", &events}, recordingTracer{"b", &events})
+
+	ctx, finish := tracer.TraceQuery(context.Background(), "{ hello }", "", nil, nil)
+	if name := ctx.Value(tracerKey{}); name != "b" {
+		t.Errorf("unexpected context of tracer %v", name)
+	}
+	_, finishField := tracer.TraceField(ctx, "Query.hello", "Query", "hello", false, nil)
+	finishField(nil)
+	finish(nil)
+
+	want := []string{
+		"start a in ",
+		"start b in a",
+		"field a Query.hello",
+		"field b Query.hello",
+		"field finish b",
+		"field finish a",
+		"finish b",
+		"finish a",
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("unexpected events %q", events)
+	}
+}
@@ -19,6 +19,14 @@ type Tracer interface {
 	TraceField(ctx context.Context, label, typeName, fieldName string, trivial bool, args map[string]interface{}) (context.Context, TraceFieldFinishFunc)
 }
 
+// SkippedFieldTracer is implemented by tracers that trace the fields skipped by a @skip or
+// @include directive. It is only used if the schema is created with the TraceSkippedFields option.
+type SkippedFieldTracer interface {
+	// TraceSkippedField is called once per skipped field of an executed selection set. typeName
+	// is the type the field is selected on.
+	TraceSkippedField(ctx context.Context, typeName, fieldName string, args map[string]interface{})
+}
+
 type OpenTracingTracer struct{}
 
 func (OpenTracingTracer) TraceQuery(ctx context.Context, queryString string, operationName string, variables map[string]interface{}, varTypes map[string]*introspection.Type) (context.Context, TraceQueryFinishFunc) {
@@ -0,0 +1,287 @@
+package graphql
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/graph-gophers/graphql-go/internal/schema"
+	"github.com/graph-gophers/graphql-go/introspection"
+)
+
+// Visitor holds the callbacks of Walk, which may be nil. The Enter callbacks of types, fields and
+// directives return whether to visit their children.
+type Visitor struct {
+	EnterType func(t *introspection.Type) bool
+	LeaveType func(t *introspection.Type)
+
+	EnterField func(t *introspection.Type, f *introspection.Field) bool
+	LeaveField func(t *introspection.Type, f *introspection.Field)
+
+	// EnterArgument and LeaveArgument are called for the arguments of fields and directives and
+	// for the fields of input objects.
+	EnterArgument func(arg *introspection.InputValue)
+	LeaveArgument func(arg *introspection.InputValue)
+
+	EnterDirective func(d *introspection.Directive) bool
+	LeaveDirective func(d *introspection.Directive)
+}
+
+// Walk visits the types of the schema sorted by name, with their fields and arguments, followed by
+// the directive declarations. Introspection types are not visited.
+func (s *Schema) Walk(v *Visitor) {
+	inspected := s.Inspect()
+	includeDeprecated := &struct{ IncludeDeprecated bool }{true}
+	for _, t := range inspected.Types() {
+		if strings.HasPrefix(*t.Name(), "__") {
+			continue
+		}
+		if v.EnterType != nil && !v.EnterType(t) {
+			continue
+		}
+		if fields := t.Fields(includeDeprecated); fields != nil {
+			for _, f := range *fields {
+				if v.EnterField != nil && !v.EnterField(t, f) {
+					continue
+				}
+				walkArguments(v, f.Args())
+				if v.LeaveField != nil {
+					v.LeaveField(t, f)
+				}
+			}
+		}
+		if inputFields := t.InputFields(); inputFields != nil {
+			walkArguments(v, *inputFields)
+		}
+		if v.LeaveType != nil {
+			v.LeaveType(t)
+		}
+	}
+	for _, d := range inspected.Directives() {
+		if v.EnterDirective != nil && !v.EnterDirective(d) {
+			continue
+		}
+		walkArguments(v, d.Args())
+		if v.LeaveDirective != nil {
+			v.LeaveDirective(d)
+		}
+	}
+}
+
+func walkArguments(v *Visitor, args []*introspection.InputValue) {
+	for _, arg := range args {
+		if v.EnterArgument != nil {
+			v.EnterArgument(arg)
+		}
+		if v.LeaveArgument != nil {
+			v.LeaveArgument(arg)
+		}
+	}
+}
+
+// ResolverFunc resolves a field, see ResolverWrapper.
+type ResolverFunc func(ctx context.Context) (interface{}, error)
+
+// ResolverWrapper wraps the resolver of a field, e.g. to check permissions or to forward the field
+// to another service. It calls next to resolve the field with the bound resolver, or returns a
+// value of the same Go type, or nil.
+type ResolverWrapper func(ctx context.Context, next ResolverFunc) (interface{}, error)
+
+// Transformer changes the schema before the resolver is attached. Its funcs may be nil. Types are
+// renamed first, and RemoveField and WrapResolver receive the new names.
+type Transformer struct {
+	// RenameType returns the new name of a type, or the name itself to keep it. Built-in scalars
+	// can not be renamed. Resolvers are still bound with the original names, e.g. by their "To"
+	// methods and the ImplementsGraphQLType method of custom scalars.
+	RenameType func(name string) string
+
+	// RemoveField reports whether to remove a field of an object or interface type. The fields of
+	// interfaces must be removed from the interface as well as from its implementations.
+	RemoveField func(typeName, fieldName string) bool
+
+	// WrapResolver returns the wrapper of the resolver of a field, or nil to keep the resolver. It
+	// is called with the names of the transformed schema when the resolver is attached. The
+	// resolvers of subscription fields are not wrapped.
+	WrapResolver func(typeName, fieldName string) ResolverWrapper
+
+	// apply is the transformation of the predefined transformers, e.g. PrefixTypes. It is applied
+	// after the funcs above.
+	apply func(s *schema.Schema) error
+}
+
+// PrefixTypes prefixes the names of all types but the built-in scalars and the root operation
+// types, e.g. PrefixTypes("Billing") renames User to BillingUser.
+func PrefixTypes(prefix string) *Transformer {
+	return &Transformer{apply: func(s *schema.Schema) error {
+		roots := make(map[string]bool)
+		for _, t := range s.EntryPoints {
+			roots[t.TypeName()] = true
+		}
+		return renameTypes(s, func(name string) string {
+			if roots[name] {
+				return name
+			}
+			return prefix + name
+		})
+	}}
+}
+
+// RenameType renames a single type.
+func RenameType(name, newName string) *Transformer {
+	return &Transformer{apply: func(s *schema.Schema) error {
+		return s.RenameType(name, newName)
+	}}
+}
+
+// FilterRootFields removes the fields of the root operation types for which keep returns false.
+// The operation is "query", "mutation" or "subscription".
+func FilterRootFields(keep func(operation, fieldName string) bool) *Transformer {
+	return &Transformer{apply: func(s *schema.Schema) error {
+		for _, op := range []string{"query", "mutation", "subscription"} {
+			t, ok := s.EntryPoints[op].(*schema.Object)
+			if !ok {
+				continue
+			}
+			for _, name := range t.Fields.Names() {
+				if keep(op, name) {
+					continue
+				}
+				if err := s.RemoveField(t.Name, name); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}}
+}
+
+// HoistField adds a field named newName to an object type, which resolves to the field at the end
+// of the path of fields below the type, e.g. HoistField("Query", []string{"viewer", "account"},
+// "account"). The fields of the path but the last must be of object types without arguments, and
+// the new field is nullable if any of them is. The hoisted field keeps its arguments and is not
+// removed from its type.
+func HoistField(typeName string, path []string, newName string) *Transformer {
+	return &Transformer{apply: func(s *schema.Schema) error {
+		return s.HoistField(typeName, path, newName)
+	}}
+}
+
+// Transform applies the transformers to the schema in order, e.g. to avoid name collisions of
+// merged schemas. ParseSchema returns an error if a transformation is invalid.
+func Transform(transformers ...*Transformer) SchemaOpt {
+	return func(s *Schema) {
+		s.transformers = append(s.transformers, transformers...)
+	}
+}
+
+func (s *Schema) applyTransformers() error {
+	for _, t := range s.transformers {
+		if t.RenameType != nil {
+			if err := renameTypes(s.schema, t.RenameType); err != nil {
+				return err
+			}
+		}
+		if t.RemoveField != nil {
+			if err := removeFields(s.schema, t.RemoveField); err != nil {
+				return err
+			}
+		}
+		if t.WrapResolver != nil {
+			s.wrapResolvers(t.WrapResolver)
+		}
+		if t.apply != nil {
+			if err := t.apply(s.schema); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func renameTypes(s *schema.Schema, rename func(name string) string) error {
+	for _, name := range typeNames(s) {
+		if schema.IsBuiltinType(name) {
+			continue
+		}
+		if err := s.RenameType(name, rename(name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func removeFields(s *schema.Schema, remove func(typeName, fieldName string) bool) error {
+	// Fields are removed from interfaces first, so they can be removed from their implementations.
+	var objects []*schema.Object
+	for _, name := range typeNames(s) {
+		switch t := s.Types[name].(type) {
+		case *schema.Interface:
+			if err := removeTypeFields(s, t.Name, t.Fields, remove); err != nil {
+				return err
+			}
+		case *schema.Object:
+			if !schema.IsBuiltinType(t.Name) {
+				objects = append(objects, t)
+			}
+		}
+	}
+	for _, t := range objects {
+		if err := removeTypeFields(s, t.Name, t.Fields, remove); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func removeTypeFields(s *schema.Schema, typeName string, fields schema.FieldList, remove func(typeName, fieldName string) bool) error {
+	for _, name := range fields.Names() {
+		if remove(typeName, name) {
+			if err := s.RemoveField(typeName, name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// wrapResolvers adds the wrappers returned by wrap to those of earlier transformers, which wrap
+// the new ones.
+func (s *Schema) wrapResolvers(wrap func(typeName, fieldName string) ResolverWrapper) {
+	outer := s.schema.WrapResolver
+	s.schema.WrapResolver = func(typeName, fieldName string) schema.ResolverWrapper {
+		inner := toSchemaWrapper(wrap(typeName, fieldName))
+		if outer == nil {
+			return inner
+		}
+		o := outer(typeName, fieldName)
+		if o == nil {
+			return inner
+		}
+		if inner == nil {
+			return o
+		}
+		return func(ctx context.Context, next func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+			return o(ctx, func(ctx context.Context) (interface{}, error) {
+				return inner(ctx, next)
+			})
+		}
+	}
+}
+
+func toSchemaWrapper(w ResolverWrapper) schema.ResolverWrapper {
+	if w == nil {
+		return nil
+	}
+	return func(ctx context.Context, next func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+		return w(ctx, next)
+	}
+}
+
+func typeNames(s *schema.Schema) []string {
+	names := make([]string, 0, len(s.Types))
+	for name := range s.Types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,249 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/internal/common"
+	"github.com/graph-gophers/graphql-go/internal/query"
+	"github.com/graph-gophers/graphql-go/internal/schema"
+)
+
+// UsageSink receives the schema coordinates referenced by an operation, see FieldUsage.
+type UsageSink interface {
+	// RecordUsage is called with the number of references of each schema coordinate. It is called
+	// synchronously before the operation is executed and must not modify usage.
+	RecordUsage(ctx context.Context, op *OperationInfo, usage map[string]int)
+}
+
+// UsageFunc is a UsageSink calling the function.
+type UsageFunc func(ctx context.Context, op *OperationInfo, usage map[string]int)
+
+// RecordUsage calls f.
+func (f UsageFunc) RecordUsage(ctx context.Context, op *OperationInfo, usage map[string]int) {
+	f(ctx, op, usage)
+}
+
+// FieldUsage reports the schema coordinates referenced by every operation to the sinks after it
+// was validated, whether or not it is executed. They are the coordinates of the selected fields,
+// e.g. "User.friends", the given arguments, e.g. "User.friends(first:)", and the enum values and
+// input fields of the argument values and variables, e.g. "Episode.JEDI" and "ReviewInput.stars",
+// as well as the directives of the query, e.g. "@include(if:)". Fields are counted once per
+// selection, with the fields of named fragments counted once per operation. The counts can be
+// used to find unused fields before removing them.
+func FieldUsage(sinks ...UsageSink) SchemaOpt {
+	return func(s *Schema) {
+		s.usageSinks = append(s.usageSinks, sinks...)
+	}
+}
+
+// UsageSnapshot is a UsageSink counting the references of each schema coordinate in memory. The
+// zero value is ready to use.
+type UsageSnapshot struct {
+	mu     sync.Mutex
+	counts map[string]int64
+}
+
+// RecordUsage adds the counts of an operation.
+func (u *UsageSnapshot) RecordUsage(ctx context.Context, op *OperationInfo, usage map[string]int) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.counts == nil {
+		u.counts = make(map[string]int64)
+	}
+	for coordinate, n := range usage {
+		u.counts[coordinate] += int64(n)
+	}
+}
+
+// Snapshot returns a copy of the counts recorded so far.
+func (u *UsageSnapshot) Snapshot() map[string]int64 {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	counts := make(map[string]int64, len(u.counts))
+	for coordinate, n := range u.counts {
+		counts[coordinate] = n
+	}
+	return counts
+}
+
+// Reset returns the counts recorded so far and starts counting from zero.
+func (u *UsageSnapshot) Reset() map[string]int64 {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	counts := u.counts
+	u.counts = nil
+	if counts == nil {
+		counts = make(map[string]int64)
+	}
+	return counts
+}
+
+// StatsdUsage is a UsageSink sending the counts of each operation as StatsD counters, e.g.
+// "graphql.usage.User.friends.first:1|c" for "User.friends(first:)". Writer is typically a UDP
+// connection to the StatsD agent, write errors are ignored.
+type StatsdUsage struct {
+	Writer io.Writer
+
+	// Prefix is prepended to the metric names, e.g. "graphql.usage.".
+	Prefix string
+}
+
+var statsdNameReplacer = strings.NewReplacer("(", ".", ":)", "", "@", "directive.")
+
+// RecordUsage writes the counters of an operation in a single packet.
+func (u *StatsdUsage) RecordUsage(ctx context.Context, op *OperationInfo, usage map[string]int) {
+	coordinates := make([]string, 0, len(usage))
+	for coordinate := range usage {
+		coordinates = append(coordinates, coordinate)
+	}
+	sort.Strings(coordinates)
+
+	var b bytes.Buffer
+	for _, coordinate := range coordinates {
+		fmt.Fprintf(&b, "%s%s:%d|c\n", u.Prefix, statsdNameReplacer.Replace(coordinate), usage[coordinate])
+	}
+	u.Writer.Write(b.Bytes())
+}
+
+// checkUsage reports the usage of an operation to the sinks of the schema and applies its
+// deprecation policies.
+func (s *Schema) checkUsage(ctx context.Context, info *OperationInfo, doc *query.Document, op *query.Operation, variables map[string]interface{}) *errors.QueryError {
+	if len(s.usageSinks) == 0 && len(s.deprecationPolicies) == 0 {
+		return nil
+	}
+	usage := operationUsage(s.schema, doc, op, variables)
+	for _, sink := range s.usageSinks {
+		sink.RecordUsage(ctx, info, usage)
+	}
+	if len(s.deprecationPolicies) == 0 {
+		return nil
+	}
+	return s.checkDeprecations(info, usage)
+}
+
+// operationUsage counts the schema coordinates referenced by a validated operation.
+func operationUsage(s *schema.Schema, doc *query.Document, op *query.Operation, variables map[string]interface{}) map[string]int {
+	u := &usageCounter{
+		schema:    s,
+		doc:       doc,
+		variables: variables,
+		usage:     make(map[string]int),
+		visited:   make(map[string]bool),
+	}
+	u.directives(op.Directives)
+	if t := s.EntryPoints[strings.ToLower(string(op.Type))]; t != nil {
+		u.selections(op.Selections, t)
+	}
+	return u.usage
+}
+
+type usageCounter struct {
+	schema    *schema.Schema
+	doc       *query.Document
+	variables map[string]interface{}
+	usage     map[string]int
+	visited   map[string]bool
+}
+
+func (u *usageCounter) selections(sels []query.Selection, t schema.NamedType) {
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *query.Field:
+			u.directives(sel.Directives)
+			var fields schema.FieldList
+			switch t := t.(type) {
+			case *schema.Object:
+				fields = t.Fields
+			case *schema.Interface:
+				fields = t.Fields
+			}
+			f := fields.Get(sel.Name.Name)
+			if f == nil {
+				// Meta fields like __typename are not part of the schema.
+				continue
+			}
+			coordinate := t.TypeName() + "." + f.Name
+			u.usage[coordinate]++
+			for _, arg := range sel.Arguments {
+				if decl := f.Args.Get(arg.Name.Name); decl != nil {
+					u.usage[coordinate+"("+arg.Name.Name+":)"]++
+					u.value(arg.Value.Value(u.variables), decl.Type)
+				}
+			}
+			if sel.Selections != nil {
+				u.selections(sel.Selections, namedType(f.Type))
+			}
+
+		case *query.InlineFragment:
+			u.directives(sel.Directives)
+			u.selections(sel.Selections, fragmentType(u.schema, sel.On, t))
+
+		case *query.FragmentSpread:
+			u.directives(sel.Directives)
+			frag := u.doc.Fragments.Get(sel.Name.Name)
+			if frag == nil || u.visited[frag.Name.Name] {
+				continue
+			}
+			u.visited[frag.Name.Name] = true
+			u.directives(frag.Directives)
+			u.selections(frag.Selections, fragmentType(u.schema, frag.On, t))
+		}
+	}
+}
+
+func (u *usageCounter) directives(directives common.DirectiveList) {
+	for _, d := range directives {
+		decl := u.schema.Directives[d.Name.Name]
+		if decl == nil {
+			continue
+		}
+		u.usage["@"+d.Name.Name]++
+		for _, arg := range d.Args {
+			if argDecl := decl.Args.Get(arg.Name.Name); argDecl != nil {
+				u.usage["@"+d.Name.Name+"("+arg.Name.Name+":)"]++
+				u.value(arg.Value.Value(u.variables), argDecl.Type)
+			}
+		}
+	}
+}
+
+// value counts the enum values and input fields of an input value of type t.
+func (u *usageCounter) value(v interface{}, t common.Type) {
+	if v == nil {
+		return
+	}
+	switch t := t.(type) {
+	case *common.NonNull:
+		u.value(v, t.OfType)
+	case *common.List:
+		if l, ok := v.([]interface{}); ok {
+			for _, elem := range l {
+				u.value(elem, t.OfType)
+			}
+			return
+		}
+		u.value(v, t.OfType)
+	case *schema.Enum:
+		if name, ok := v.(string); ok {
+			u.usage[t.Name+"."+name]++
+		}
+	case *schema.InputObject:
+		fields, ok := v.(map[string]interface{})
+		if !ok {
+			return
+		}
+		for _, f := range t.Values {
+			if fv, ok := fields[f.Name.Name]; ok {
+				u.usage[t.Name+"."+f.Name.Name]++
+				u.value(fv, f.Type)
+			}
+		}
+	}
+}
@@ -0,0 +1,16 @@
+// Package validator validates GraphQL query documents against a schema with the rules enforced by
+// the server, e.g. to check stored operations in CI or in a gateway before they are forwarded.
+package validator
+
+import (
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/parser"
+)
+
+// Validate validates a parsed document with the schema, including the depth limits configured by
+// the options of the schema, e.g. MaxDepth. If variables is nil, the values of the variables are
+// not validated. The Rule of an error is the name of the violated rule, e.g. "FieldsOnCorrectType".
+func Validate(s *graphql.Schema, doc *parser.Document, variables map[string]interface{}) []*errors.QueryError {
+	return s.ValidateDocument(doc, variables)
+}
@@ -0,0 +1,51 @@
+package validator_test
+
+import (
+	"testing"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/example/starwars"
+	"github.com/graph-gophers/graphql-go/parser"
+	"github.com/graph-gophers/graphql-go/validator"
+)
+
+func TestValidate(t *testing.T) {
+	schema := graphql.MustParseSchema(starwars.Schema, nil, graphql.MaxDepth(2))
+
+	for _, tc := range []struct {
+		name  string
+		query string
+		rules []string
+	}{
+		{
+			name:  "valid",
+			query: `{ hero { name } }`,
+		},
+		{
+			name:  "unknown field",
+			query: `{ hero { height(unit: FOOT) } }`,
+			rules: []string{"FieldsOnCorrectType"},
+		},
+		{
+			name:  "max depth",
+			query: `{ hero { friends { name } } }`,
+			rules: []string{"MaxDepthExceeded"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			doc, err := parser.Parse(tc.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			errs := validator.Validate(schema, doc, nil)
+			if len(errs) != len(tc.rules) {
+				t.Fatalf("expected %d errors, got %v", len(tc.rules), errs)
+			}
+			for i, err := range errs {
+				if err.Rule != tc.rules[i] {
+					t.Errorf("expected rule %q, got %q", tc.rules[i], err.Rule)
+				}
+			}
+		})
+	}
+}
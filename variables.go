@@ -0,0 +1,78 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/internal/query"
+)
+
+// VariablesHook transforms the variables of a request after they were decoded from JSON and before
+// they are validated and coerced to the types of the operation, e.g. to decode opaque cursors or to
+// inject defaults per tenant. It receives the name of the requested operation, which is empty if the
+// request does not name one, and returns the variables to use. It may modify the given map.
+//
+// An error rejects the request. Return a *VariableError to report the variable that caused it.
+type VariablesHook func(ctx context.Context, operationName string, variables map[string]interface{}) (map[string]interface{}, error)
+
+// VariableError reports an invalid variable from a VariablesHook.
+type VariableError struct {
+	// Name is the name of the variable, without the "$".
+	Name string
+
+	// Err describes why the value of the variable is invalid.
+	Err error
+}
+
+func (e *VariableError) Error() string {
+	return fmt.Sprintf("variable %q: %s", e.Name, e.Err)
+}
+
+func (e *VariableError) Unwrap() error {
+	return e.Err
+}
+
+// TransformVariables calls the hook with the variables of every query, mutation and subscription
+// request before the variables are validated. Errors of the hook are returned as request errors and
+// the operation is not executed.
+func TransformVariables(hook VariablesHook) SchemaOpt {
+	return func(s *Schema) {
+		s.variablesHook = hook
+	}
+}
+
+// transformVariables applies the VariablesHook, if any, to the variables of a request.
+func (s *Schema) transformVariables(ctx context.Context, doc *query.Document, operationName string, variables map[string]interface{}) (map[string]interface{}, *errors.QueryError) {
+	if s.variablesHook == nil {
+		return variables, nil
+	}
+	variables, err := s.variablesHook(ctx, operationName, variables)
+	if err != nil {
+		return nil, variablesError(doc, operationName, err)
+	}
+	if variables == nil {
+		variables = make(map[string]interface{})
+	}
+	return variables, nil
+}
+
+// variablesError converts an error of a VariablesHook to a request error. A *VariableError is located
+// at the definition of the variable in the operation.
+func variablesError(doc *query.Document, operationName string, err error) *errors.QueryError {
+	varErr, ok := err.(*VariableError)
+	if !ok {
+		qErr := errors.Errorf("could not process variables: %s", err)
+		qErr.ResolverError = err
+		return qErr
+	}
+	qErr := errors.Errorf("Variable \"%s\" has invalid value: %s", varErr.Name, varErr.Err)
+	qErr.ResolverError = err
+	qErr.Extensions = map[string]interface{}{"variable": varErr.Name}
+	if op, opErr := getOperation(doc, operationName); opErr == nil {
+		if v := op.Vars.Get(varErr.Name); v != nil {
+			qErr.Locations = []errors.Location{v.Loc}
+		}
+	}
+	return qErr
+}